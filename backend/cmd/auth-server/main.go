@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 
+	"backend/internal/admin"
 	"backend/internal/auth"
 	"backend/internal/database"
+	"backend/internal/ids"
 	"backend/internal/repository"
 	"github.com/gin-gonic/gin"
 )
@@ -29,12 +32,31 @@ func main() {
 	}
 	defer db.Close()
 
+	// Generate IDs with the configured strategy, UUIDv7 by default
+	idGenerator, err := ids.FromEnv()
+	if err != nil {
+		log.Fatalf("Invalid ID_STRATEGY: %v", err)
+	}
+	ids.SetDefault(idGenerator)
+
 	// Create repository manager
 	repos := repository.NewManager(db)
 
 	// Create authentication manager
 	authConfig := auth.NewConfig()
 	authManager := auth.NewManager(authConfig, repos.User)
+	authManager.AuthService.EnableRefreshTokenStore(repos.RefreshToken)
+
+	// Sign with the newest rotated key and accept every key still in use
+	if err := authManager.JWTService.EnableKeyStore(context.Background(), repos.JWTKey); err != nil {
+		log.Fatalf("Failed to load JWT signing keys: %v", err)
+	}
+
+	// In production, refuse to start unless tokens and cookies stay on TLS
+	serverConfig := admin.ConfigFromEnv()
+	if err := serverConfig.CheckTransport(authConfig.TokenCookieSecure); err != nil {
+		log.Fatalf("Insecure transport configuration: %v", err)
+	}
 
 	// Create Gin router
 	r := gin.Default()
@@ -43,7 +65,7 @@ func main() {
 	r.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-CSRF-Token")
 		
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -67,6 +89,7 @@ func main() {
 			return
 		}
 
+		authManager.Middleware.WriteAuthCookies(c.Writer, response)
 		c.JSON(http.StatusCreated, response)
 	})
 
@@ -83,12 +106,36 @@ func main() {
 			return
 		}
 
+		authManager.Middleware.WriteAuthCookies(c.Writer, response)
 		c.JSON(http.StatusOK, response)
 	})
 
-	r.POST("/auth/refresh", authManager.Middleware.RequiredAuth(), func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		token, err := auth.ExtractTokenFromHeader(authHeader)
+	// Exchange a refresh token, from the body or the refresh cookie, for new
+	// tokens; without one, renew the access token the request carries
+	r.POST("/auth/refresh", authManager.Middleware.OptionalAuth(), func(c *gin.Context) {
+		if err := auth.CheckCSRF(c.Request.Context()); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		if refreshToken, ok := refreshTokenFromRequest(c, authManager.Middleware); ok {
+			response, err := authManager.AuthService.RefreshWithRefreshToken(c.Request.Context(), refreshToken)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				return
+			}
+
+			authManager.Middleware.WriteAuthCookies(c.Writer, response)
+			c.JSON(http.StatusOK, response)
+			return
+		}
+
+		if _, ok := auth.GetUserFromContext(c.Request.Context()); !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "A refresh token or access token is required"})
+			return
+		}
+
+		token, err := authManager.Middleware.TokenFromRequest(c.Request)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token"})
 			return
@@ -100,9 +147,33 @@ func main() {
 			return
 		}
 
+		authManager.Middleware.WriteTokenCookie(c.Writer, response.Token, response.ExpiresAt)
 		c.JSON(http.StatusOK, response)
 	})
 
+	// Revoke the session of a refresh token, from the body or the refresh
+	// cookie, and clear the auth cookies
+	r.POST("/auth/logout", authManager.Middleware.OptionalAuth(), func(c *gin.Context) {
+		if err := auth.CheckCSRF(c.Request.Context()); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		refreshToken, ok := refreshTokenFromRequest(c, authManager.Middleware)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A refresh token is required"})
+			return
+		}
+
+		if err := authManager.AuthService.Logout(c.Request.Context(), refreshToken); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		authManager.Middleware.ClearAuthCookies(c.Writer)
+		c.Status(http.StatusNoContent)
+	})
+
 	// Protected routes
 	protected := r.Group("/api")
 	protected.Use(authManager.Middleware.RequiredAuth())
@@ -144,16 +215,32 @@ func main() {
 		})
 	})
 
-	log.Println("🔐 Authentication server ready at http://localhost:8080")
+	log.Printf("🔐 Authentication server ready at http://localhost:%s", serverConfig.Port)
 	log.Println("📋 Available endpoints:")
 	log.Println("   POST /auth/register - Register new user")
 	log.Println("   POST /auth/login    - Login user")
 	log.Println("   POST /auth/refresh  - Refresh token")
+	log.Println("   POST /auth/logout   - Revoke a refresh token's session")
 	log.Println("   GET  /api/me        - Get current user (protected)")
 	log.Println("   GET  /public        - Public endpoint with optional auth")
 	log.Println("   GET  /health        - Health check")
 
-	r.Run(":8080")
+	log.Fatal(admin.ServePublic(serverConfig, r))
+}
+
+// refreshTokenRequest is the optional body of /auth/refresh and /auth/logout
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// refreshTokenFromRequest returns the refresh token in the request body or,
+// in cookie mode, the refresh cookie
+func refreshTokenFromRequest(c *gin.Context, middleware *auth.AuthMiddleware) (string, bool) {
+	var req refreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err == nil && req.RefreshToken != "" {
+		return req.RefreshToken, true
+	}
+	return middleware.RefreshTokenFromRequest(c.Request)
 }
 
 func runMockDemo() {
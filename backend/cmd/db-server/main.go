@@ -7,8 +7,8 @@ import (
 
 	"backend/internal/database"
 	"backend/internal/graph/model"
+	"backend/internal/ids"
 	"backend/internal/repository"
-	"github.com/google/uuid"
 )
 
 func main() {
@@ -32,13 +32,20 @@ func main() {
 	}
 	defer db.Close()
 
+	// Generate IDs with the configured strategy, UUIDv7 by default
+	idGenerator, err := ids.FromEnv()
+	if err != nil {
+		log.Fatalf("Invalid ID_STRATEGY: %v", err)
+	}
+	ids.SetDefault(idGenerator)
+
 	// Create repository manager
 	repos := repository.NewManager(db)
 
 	// Example: Create a user
 	ctx := context.Background()
 	user := &model.User{
-		ID:           uuid.New(),
+		ID:           ids.New(),
 		Email:        "demo@example.com",
 		Name:         "Demo User",
 		PasswordHash: "hashed_password_here",
@@ -64,7 +71,7 @@ func main() {
 
 	// Example: Create a post
 	post := &model.Post{
-		ID:        uuid.New(),
+		ID:        ids.New(),
 		Title:     "My First Post",
 		Content:   "This is the content of my first post using the new database layer!",
 		AuthorID:  user.ID,
@@ -92,7 +99,7 @@ func main() {
 
 	// Example: Create a comment
 	comment := &model.Comment{
-		ID:        uuid.New(),
+		ID:        ids.New(),
 		Content:   "Great post! Thanks for sharing.",
 		AuthorID:  user.ID,
 		PostID:    post.ID,
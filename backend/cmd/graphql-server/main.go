@@ -1,13 +1,26 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"time"
 
 	"backend/graph"
+	"backend/internal/admin"
 	"backend/internal/auth"
+	"backend/internal/cache"
+	"backend/internal/complexity"
 	"backend/internal/database"
+	"backend/internal/dataloader"
+	"backend/internal/graph/errors"
+	"backend/internal/graph/validation"
+	"backend/internal/ids"
+	"backend/internal/logging"
+	apiheaders "backend/internal/playground"
 	"backend/internal/repository"
+	"backend/internal/security"
 	"backend/internal/subscription"
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/handler/transport"
@@ -16,6 +29,9 @@ import (
 	"github.com/gorilla/websocket"
 )
 
+// postCacheTTL is how long posts stay in the Redis cache
+const postCacheTTL = 5 * time.Minute
+
 func main() {
 	log.Println("🚀 Starting GraphQL server with authentication...")
 
@@ -35,20 +51,66 @@ func main() {
 	}
 	defer db.Close()
 
+	// Generate IDs with the configured strategy, UUIDv7 by default
+	idGenerator, err := ids.FromEnv()
+	if err != nil {
+		log.Fatalf("Invalid ID_STRATEGY: %v", err)
+	}
+	ids.SetDefault(idGenerator)
+
 	// Create repository manager
 	repos := repository.NewManager(db)
 
+	// With REDIS_URL set, cache posts in Redis. The circuit breaker fails
+	// cache calls fast while Redis is down, so requests go straight to the
+	// database instead of waiting on each cache timeout.
+	postRepo := repos.Post
+	var redisCache *cache.RedisCache
+	if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+		redisCache, err = cache.NewRedisCacheFromURL(redisURL)
+		if err != nil {
+			log.Fatalf("Failed to configure Redis: %v", err)
+		}
+		defer redisCache.Close()
+
+		var postCache cache.Cache = cache.NewCircuitBreakerCache(redisCache, cache.DefaultBreakerConfig())
+
+		// With CACHE_STALE_TTL, keep copies of cached posts past their TTL
+		// to serve while the database is unreachable
+		if staleTTL := cache.StaleTTLFromEnv(); staleTTL > 0 {
+			postCache = cache.NewStaleCache(postCache, staleTTL)
+		}
+		postRepo = cache.NewCachedPostRepository(repos.Post, postCache, postCacheTTL)
+
+		// Load the newest published posts into the cache in the background,
+		// bounded by CACHE_WARMUP_POSTS and CACHE_WARMUP_TIMEOUT
+		warmer := cache.NewWarmer(repos.Post, repos.User, postCache, postCacheTTL, cache.WarmupConfigFromEnv())
+		go func() {
+			count, err := warmer.Warm(context.Background())
+			if err != nil {
+				log.Printf("⚠️  Cache warm-up failed: %v", err)
+				return
+			}
+			log.Printf("🔥 Cache warm-up wrote %d entries", count)
+		}()
+	}
+
 	// Create authentication manager
 	authConfig := auth.NewConfig()
 	authManager := auth.NewManager(authConfig, repos.User)
 
+	// Sign with the newest rotated key and accept every key still in use
+	if err := authManager.JWTService.EnableKeyStore(context.Background(), repos.JWTKey); err != nil {
+		log.Fatalf("Failed to load JWT signing keys: %v", err)
+	}
+
 	// Create subscription manager
 	subManager := subscription.NewManager()
 
 	// Create GraphQL resolver with dependencies
 	graphqlResolver := &graph.Resolver{
 		UserRepo:    repos.User,
-		PostRepo:    repos.Post,
+		PostRepo:    postRepo,
 		CommentRepo: repos.Comment,
 		AuthManager: authManager,
 		SubManager:  subManager,
@@ -59,57 +121,140 @@ func main() {
 		Resolvers: graphqlResolver,
 	}))
 
-	// Add WebSocket transport for subscriptions
+	// Write logs to LOG_OUTPUT: stdout by default, or a rotated file
+	logOutput, err := logging.OutputConfigFromEnv().Open()
+	if err != nil {
+		log.Fatalf("Failed to open log output: %v", err)
+	}
+	if file, ok := logOutput.(*logging.RotatingFile); ok {
+		defer file.Close()
+	}
+
+	// Mask internal error details from clients per GRAPHQL_ERROR_MODE
+	logger := logging.NewLogger(logging.Config{Level: logging.LevelInfo, Service: "graphql-server", Format: "json", Output: logOutput})
+	srv.SetErrorPresenter(errors.NewErrorHandlerWithMode(logger, errors.ErrorModeFromEnv()).Presenter())
+
+	// Log GraphQL operations, sampling fast successful ones per
+	// LOG_SAMPLE_RATE; errors and slow operations are always logged
+	srv.Use(logging.GraphQLMiddlewareWithSampling(logger, logging.SamplingConfigFromEnv()))
+
+	// Log resolver panics with their operation, request ID and stack, and
+	// answer with a generic internal error
+	srv.SetRecoverFunc(logging.RecoveryLogger(logger))
+
+	// Flag responses built from stale cached posts with "stale": true
+	srv.Use(cache.NewStaleResponseMarker())
+
+	// Restrict schema introspection per GRAPHQL_INTROSPECTION
+	srv.Use(security.NewIntrospectionGuard(security.IntrospectionModeFromEnv()))
+
+	// Require the double-submit CSRF token on mutations authenticated by cookie
+	srv.Use(auth.NewCSRFGuard())
+
+	// Deny anonymous callers every mutation not listed in ANONYMOUS_MUTATIONS
+	srv.Use(security.NewAnonymousMutationGuard(func(ctx context.Context) bool {
+		_, ok := auth.GetUserFromContext(ctx)
+		return ok
+	}, security.AnonymousMutationsFromEnv()))
+
+	// Reject oversized queries and variables before parsing
+	requestSizeConfig := security.RequestSizeConfigFromEnv()
+	srv.Use(security.NewRequestSizeLimiter(requestSizeConfig))
+
+	// Limit query complexity and depth, with a smaller budget for anonymous
+	// callers, and record refused operations in the audit_logs table
+	auditLogger := security.NewAuditLoggerWithStore(repos.AuditLog)
+	complexityConfig := complexity.ConfigFromEnv()
+	complexityConfig.Authenticated = func(ctx context.Context) bool {
+		_, ok := auth.GetUserFromContext(ctx)
+		return ok
+	}
+	complexityConfig.OnReject = func(ctx context.Context, rejection complexity.Rejection) {
+		auditLogger.LogComplexityRejection(ctx, auditUser(ctx), rejection)
+	}
+	analyzer := complexity.NewAnalyzer(complexityConfig)
+	srv.Use(analyzer.ComplexityMiddleware())
+
+	// With Redis, limit request rates per client IP, user and operation
+	// type, and charge each operation's complexity to a rolling per-client
+	// budget. RATE_LIMIT_EXEMPT_IPS and RATE_LIMIT_EXEMPT_API_KEYS name the
+	// trusted clients that bypass every rate limit. Both audit what they refuse.
+	if redisCache != nil {
+		rateLimitConfig := security.DefaultRateLimitConfig()
+		rateLimitConfig.ExemptIPs, rateLimitConfig.ExemptAPIKeys = security.RateLimitExemptionsFromEnv()
+		rateLimiter := security.NewRateLimiter(redisCache.Client(), rateLimitConfig)
+		rateLimiter.SetAuditLogger(auditLogger)
+		srv.Use(rateLimiter)
+
+		queryBudget := security.NewQueryBudget(redisCache.Client(), analyzer, security.DefaultQueryBudgetConfig())
+		queryBudget.SetAuditLogger(auditLogger)
+		srv.Use(queryBudget)
+	}
+
+	// Enforce @length and @pattern directives on arguments and input fields
+	srv.Use(validation.NewInputConstraints())
+
+	// With GRAPHQL_DEDUPLICATE_QUERIES, concurrent identical queries from the
+	// same viewer share one execution
+	if security.QueryDedupEnabledFromEnv() {
+		srv.Use(security.NewQueryDeduplicator(func(ctx context.Context) string {
+			if user, ok := auth.GetUserFromContext(ctx); ok {
+				return user.ID.String()
+			}
+			return ""
+		}))
+	}
+
+	// Add WebSocket transport for subscriptions, authenticating connections
+	// with the token in their connection_init payload. Upgrades can also carry
+	// the token cookie, so browsers must come from CORS_ALLOWED_ORIGINS or,
+	// when none are listed, from this server's own origin.
 	srv.AddTransport(&transport.Websocket{
 		Upgrader: websocket.Upgrader{
-			CheckOrigin: func(r *http.Request) bool {
-				// Allow all origins for development
-				// In production, you should validate the origin
-				return true
-			},
+			CheckOrigin: apiheaders.WebSocketOriginCheck(publicCORSConfig()),
 		},
+		InitFunc: authManager.Middleware.WebsocketInit,
 	})
 
-	// Create Gin router
-	r := gin.Default()
-
-	// Enable CORS
-	r.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-		
-		c.Next()
-	})
-
-	// Apply optional authentication middleware to GraphQL endpoint
-	// This allows both authenticated and anonymous access
-	r.Use(authManager.Middleware.OptionalAuth())
+	// Serve only the GraphQL API, playground and a liveness probe publicly.
+	// In production, refuse to start unless tokens and cookies stay on TLS.
+	serverConfig := admin.ConfigFromEnv()
+	if err := serverConfig.CheckTransport(authConfig.TokenCookieSecure); err != nil {
+		log.Fatalf("Insecure transport configuration: %v", err)
+	}
+	// Accept batched operations up to GRAPHQL_MAX_BATCH_SIZE, with complexity
+	// limited across the whole batch, and refuse bodies over
+	// GRAPHQL_MAX_BODY_BYTES before either is decoded
+	batched := security.LimitRequestBody(security.BatchHandler(srv, security.BatchConfigFromEnv()), requestSizeConfig)
 
-	// GraphQL endpoint
-	r.POST("/graphql", gin.WrapH(srv))
-	r.GET("/graphql", gin.WrapH(srv))
+	// Give each request its own DataLoaders, reading posts through the cache
+	loaderRepos := *repos
+	loaderRepos.Post = postRepo
+	batched = dataloader.Middleware(&loaderRepos)(batched)
 
-	// GraphQL Playground
-	r.GET("/playground", gin.WrapH(playground.Handler("GraphQL playground", "/graphql")))
+	r := newPublicRouter(batched, authManager.Middleware.OptionalAuth(), logger, publicCORSConfig(), serverConfig.TrustedProxies)
 
-	// Health check
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":  "ok",
-			"service": "graphql-server",
-			"message": "GraphQL server with authentication is running",
+	// Serve metrics, detailed readiness and, with ADMIN_PPROF, pprof on the
+	// internal admin port
+	if serverConfig.AdminAddr() != "" {
+		adminMux := admin.NewMux(admin.Options{
+			Metrics: map[string]func() interface{}{
+				"subscriptions": func() interface{} { return subManager.Metrics() },
+			},
+			Checks: map[string]admin.Check{"database": db.Health},
+			Pprof:  serverConfig.Pprof,
 		})
-	})
+		go func() {
+			log.Printf("🔒 Admin endpoints on %s (pprof enabled: %t)", serverConfig.AdminAddr(), serverConfig.Pprof)
+			if err := admin.NewServer(serverConfig, adminMux).ListenAndServe(); err != nil {
+				log.Printf("⚠️  Admin server stopped: %v", err)
+			}
+		}()
+	}
 
-	log.Println("🎮 GraphQL server ready at http://localhost:8080/graphql")
-	log.Println("🎯 GraphQL playground available at http://localhost:8080/playground")
-	log.Println("❤️  Health check at http://localhost:8080/health")
+	log.Printf("🎮 GraphQL server ready at http://localhost:%s/graphql", serverConfig.Port)
+	log.Printf("🎯 GraphQL playground available at http://localhost:%s/playground", serverConfig.Port)
+	log.Printf("❤️  Health check at http://localhost:%s/health", serverConfig.Port)
 	log.Println("")
 	log.Println("📋 Available GraphQL operations:")
 	log.Println("   Queries:")
@@ -134,7 +279,76 @@ func main() {
 	log.Println("")
 	log.Printf("📡 WebSocket subscriptions enabled with %d active subscribers", subManager.GetSubscriberCount())
 
-	r.Run(":8080")
+	log.Fatal(admin.ServePublic(serverConfig, r))
+}
+
+// publicCORSConfig returns the CORS_ALLOWED_ORIGINS config, letting browsers
+// send the CSRF header that cookie-authenticated mutations need
+func publicCORSConfig() apiheaders.CORSConfig {
+	config := apiheaders.CORSConfigFromEnv()
+	config.AllowedHeaders = append(config.AllowedHeaders, "X-CSRF-Token")
+	return config
+}
+
+// apiSecurityHeaders returns the security headers for the public port, with a
+// policy that keeps GraphQL responses from loading or being framed
+func apiSecurityHeaders() gin.HandlerFunc {
+	config := apiheaders.DefaultSecurityHeadersConfig()
+	config.ContentSecurityPolicy = apiheaders.GraphQLContentSecurityPolicy
+	return apiheaders.GinMiddleware(apiheaders.SecurityHeaders(config))
+}
+
+// newPublicRouter builds the router for the public port. It carries the
+// GraphQL endpoint, the playground and a bare liveness probe; metrics,
+// readiness details and pprof live on the admin mux. Client IPs come from
+// X-Forwarded-For only on requests from trustedProxies.
+func newPublicRouter(srv http.Handler, authMiddleware gin.HandlerFunc, logger *logging.Logger, cors apiheaders.CORSConfig, trustedProxies []string) *gin.Engine {
+	r := gin.Default()
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		log.Printf("⚠️  Ignoring invalid TRUSTED_PROXIES, forwarded client IPs will not be trusted: %v", err)
+		_ = r.SetTrustedProxies(nil)
+	}
+
+	// Allow the configured browser origins and set security headers
+	r.Use(apiheaders.GinMiddleware(apiheaders.CORS(cors)))
+	r.Use(apiSecurityHeaders())
+
+	// Tag each request with an ID for logs and error responses
+	r.Use(logging.GinMiddleware(logger))
+
+	// Record the client IP and user agent for audit entries
+	r.Use(security.ClientInfoMiddleware())
+
+	// Let the rate limiter warn clients nearing a limit with X-RateLimit-Warning
+	r.Use(security.RateLimitWarningMiddleware())
+
+	// Apply optional authentication middleware to GraphQL endpoint
+	// This allows both authenticated and anonymous access
+	r.Use(authMiddleware)
+
+	// GraphQL endpoint
+	r.POST("/graphql", gin.WrapH(srv))
+	r.GET("/graphql", gin.WrapH(srv))
+
+	// GraphQL Playground
+	r.GET("/playground", gin.WrapH(playground.Handler("GraphQL playground", "/graphql")))
+
+	// Health check
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "ok",
+			"service": "graphql-server",
+			"message": "GraphQL server with authentication is running",
+		})
+	})
+
+	return r
+}
+
+// auditUser returns the authenticated user in ctx as an audit log user, or
+// nil for anonymous callers
+func auditUser(ctx context.Context) *security.User {
+	return security.GetUserFromContext(ctx)
 }
 
 func runMockDemo() {
@@ -154,32 +368,23 @@ func runMockDemo() {
 	srv := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{
 		Resolvers: mockResolver,
 	}))
+	logger := logging.NewLogger(logging.Config{Level: logging.LevelInfo, Service: "graphql-server", Format: "json"})
+	srv.SetRecoverFunc(logging.RecoveryLogger(logger))
+	srv.Use(validation.NewInputConstraints())
 
-	// Add WebSocket transport for subscriptions
+	// Add WebSocket transport for subscriptions, from the same origins as the server
 	srv.AddTransport(&transport.Websocket{
 		Upgrader: websocket.Upgrader{
-			CheckOrigin: func(r *http.Request) bool {
-				return true
-			},
+			CheckOrigin: apiheaders.WebSocketOriginCheck(publicCORSConfig()),
 		},
 	})
 
 	// Create Gin router for demo
 	r := gin.Default()
 
-	// Enable CORS
-	r.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-		
-		c.Next()
-	})
+	// Allow the configured browser origins and set security headers
+	r.Use(apiheaders.GinMiddleware(apiheaders.CORS(publicCORSConfig())))
+	r.Use(apiSecurityHeaders())
 
 	// GraphQL endpoint
 	r.POST("/graphql", gin.WrapH(srv))
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"backend/graph"
+	"backend/internal/admin"
+	"backend/internal/graph/validation"
+	"backend/internal/logging"
+	apiheaders "backend/internal/playground"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPublicRouter_OmitsAdminEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	graphqlHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	logger := logging.NewLogger(logging.Config{Level: logging.LevelError, Output: io.Discard})
+	r := newPublicRouter(graphqlHandler, func(c *gin.Context) { c.Next() }, logger, apiheaders.DefaultCORSConfig(), nil)
+
+	for _, route := range r.Routes() {
+		for _, path := range admin.Paths {
+			assert.False(t, strings.HasPrefix(route.Path, strings.TrimSuffix(path, "/")),
+				"%s %s is an admin endpoint on the public router", route.Method, route.Path)
+		}
+	}
+
+	for _, path := range append([]string{"/debug/pprof/cmdline"}, admin.Paths...) {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code, path)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "subscriptions")
+}
+
+func TestNewPublicRouter_SetsSecurityHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	graphqlHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	})
+	logger := logging.NewLogger(logging.Config{Level: logging.LevelError, Output: io.Discard})
+	cors := apiheaders.DefaultCORSConfig()
+	cors.AllowedOrigins = []string{"https://app.example.com"}
+	cors.AllowCredentials = true
+	r := newPublicRouter(graphqlHandler, func(c *gin.Context) { c.Next() }, logger, cors, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{ __typename }"}`))
+	req.Header.Set("Origin", "https://app.example.com")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "DENY", rec.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "strict-origin-when-cross-origin", rec.Header().Get("Referrer-Policy"))
+	assert.Equal(t, apiheaders.GraphQLContentSecurityPolicy, rec.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestNewPublicRouter_Preflight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	graphqlHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("preflight requests must not reach the GraphQL handler")
+	})
+	logger := logging.NewLogger(logging.Config{Level: logging.LevelError, Output: io.Discard})
+	r := newPublicRouter(graphqlHandler, func(c *gin.Context) { c.Next() }, logger, publicCORSConfig(), nil)
+
+	req := httptest.NewRequest(http.MethodOptions, "/graphql", nil)
+	req.Header.Set("Origin", "https://elsewhere.example.com")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), "X-CSRF-Token")
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestNewPublicRouter_TrustsForwardedForOnlyFromTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	graphqlHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	logger := logging.NewLogger(logging.Config{Level: logging.LevelError, Output: io.Discard})
+
+	clientIP := func(trustedProxies []string) string {
+		var seen string
+		recordIP := func(c *gin.Context) {
+			seen = c.ClientIP()
+			c.Next()
+		}
+		r := newPublicRouter(graphqlHandler, recordIP, logger, apiheaders.DefaultCORSConfig(), trustedProxies)
+
+		req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{ __typename }"}`))
+		req.RemoteAddr = "10.0.0.5:40000"
+		req.Header.Set("X-Forwarded-For", "203.0.113.9")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		return seen
+	}
+
+	assert.Equal(t, "10.0.0.5", clientIP(nil), "spoofed X-Forwarded-For must be ignored")
+	assert.Equal(t, "203.0.113.9", clientIP([]string{"10.0.0.0/8"}))
+	assert.Equal(t, "10.0.0.5", clientIP([]string{"not-a-proxy"}))
+}
+
+func TestSchema_EnforcesInputConstraints(t *testing.T) {
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{}}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(validation.NewInputConstraints())
+
+	for message, query := range map[string]string{
+		"title must be at least 3 characters long":    `mutation { createPost(input: {title: "Hi", content: "Long enough content", tags: []}) { id } }`,
+		"tags has an invalid format":                  `mutation { createPost(input: {title: "Hello", content: "Long enough content", tags: ["no spaces"]}) { id } }`,
+		"password must be at least 8 characters long": `mutation { register(email: "a@example.com", password: "short", name: "Alice") { token } }`,
+	} {
+		body := strings.NewReader(`{"query": ` + strconv.Quote(query) + `}`)
+		req := httptest.NewRequest(http.MethodPost, "/graphql", body)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+
+		assert.Contains(t, rec.Body.String(), message)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+
+	"backend/internal/auth"
+	"backend/internal/database"
+	"backend/internal/ids"
+	"backend/internal/repository"
+	"backend/internal/userimport"
+)
+
+func main() {
+	var (
+		file      = flag.String("file", "", "CSV file of email,name rows; - reads stdin")
+		batchSize = flag.Int("batch-size", userimport.DefaultBatchSize, "Users created per transaction")
+	)
+	flag.Parse()
+
+	if *file == "" {
+		log.Println("Usage:")
+		log.Println("  go run cmd/import-users/main.go -file users.csv [-batch-size=100]")
+		log.Println("")
+		log.Println("The CSV has an email and a name column, with an optional email,name header.")
+		log.Println("Each created user is mailed a password setup token valid for PASSWORD_SETUP_TOKEN_TTL.")
+		os.Exit(2)
+	}
+
+	input, err := openInput(*file)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", *file, err)
+	}
+	defer input.Close()
+
+	rows, rejected, err := userimport.ParseCSV(input)
+	if err != nil {
+		log.Fatalf("Failed to parse %s: %v", *file, err)
+	}
+
+	db, err := database.NewConnection(database.NewConfig())
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// Generate IDs with the configured strategy, UUIDv7 by default
+	idGenerator, err := ids.FromEnv()
+	if err != nil {
+		log.Fatalf("Invalid ID_STRATEGY: %v", err)
+	}
+	ids.SetDefault(idGenerator)
+
+	repos := repository.NewManager(db)
+	authConfig := auth.NewConfig()
+	importer := &userimport.Importer{
+		Users:     repos.PasswordSetup,
+		Mailer:    auth.NewMailer(authConfig),
+		TokenTTL:  authConfig.PasswordSetupTTL,
+		BatchSize: *batchSize,
+	}
+
+	summary := &userimport.Summary{Results: rejected}
+	importer.Import(context.Background(), rows, summary)
+
+	printSummary(os.Stdout, summary)
+	if summary.Count(userimport.StatusFailed) > 0 {
+		db.Close()
+		os.Exit(1)
+	}
+}
+
+// openInput opens the CSV file, or stdin for "-"
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// printSummary writes the totals followed by every row that was not created
+func printSummary(w io.Writer, summary *userimport.Summary) {
+	results := append([]userimport.Result(nil), summary.Results...)
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Line < results[j].Line })
+
+	for _, result := range results {
+		if result.Status != userimport.StatusCreated {
+			fmt.Fprintf(w, "line %d %s %s: %s\n", result.Line, result.Email, result.Status, result.Reason)
+		}
+	}
+	fmt.Fprintf(w, "created: %d, skipped: %d, failed: %d\n",
+		summary.Count(userimport.StatusCreated),
+		summary.Count(userimport.StatusSkipped),
+		summary.Count(userimport.StatusFailed),
+	)
+}
@@ -3,8 +3,6 @@ package main
 import (
 	"flag"
 	"log"
-	"os"
-	"strconv"
 
 	"backend/internal/database"
 )
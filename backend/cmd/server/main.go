@@ -8,8 +8,8 @@ import (
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/gin-gonic/gin"
-	"backend/internal/graph/generated"
-	"backend/internal/graph/resolver"
+	"backend/graph"
+	"backend/internal/logging"
 )
 
 const defaultPort = "8080"
@@ -21,10 +21,14 @@ func main() {
 	}
 
 	// Initialize resolver
-	resolver := &resolver.Resolver{}
+	resolver := &graph.Resolver{}
 
 	// Create GraphQL server
-	srv := handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: resolver}))
+	srv := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{Resolvers: resolver}))
+
+	// Log resolver panics with context and answer with a generic internal error
+	logger := logging.NewLogger(logging.Config{Level: logging.LevelInfo, Service: "server", Format: "json"})
+	srv.SetRecoverFunc(logging.RecoveryLogger(logger))
 
 	// Create Gin router
 	r := gin.Default()
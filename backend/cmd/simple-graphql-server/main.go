@@ -1,13 +1,23 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
 
+	"backend/internal/admin"
 	"backend/internal/auth"
+	"backend/internal/cache"
 	"backend/internal/database"
+	"backend/internal/dataloader"
 	"backend/internal/graph/resolver"
+	"backend/internal/graph/validation"
+	"backend/internal/ids"
+	apiheaders "backend/internal/playground"
 	"backend/internal/repository"
+	"backend/internal/security"
 	"github.com/gin-gonic/gin"
 )
 
@@ -30,41 +40,113 @@ func main() {
 	}
 	defer db.Close()
 
-	// Create repository manager
-	repos := repository.NewManager(db)
+	// Generate IDs with the configured strategy, UUIDv7 by default
+	idGenerator, err := ids.FromEnv()
+	if err != nil {
+		log.Fatalf("Invalid ID_STRATEGY: %v", err)
+	}
+	ids.SetDefault(idGenerator)
+
+	// Create repository manager; the post repository enforces the same tag
+	// limits as the GraphQL validator
+	tagLimits := validation.PostTagLimitsFromEnv()
+	repos := repository.NewManagerWithTagLimits(db, tagLimits)
 
 	// Create authentication manager
 	authConfig := auth.NewConfig()
 	authManager := auth.NewManager(authConfig, repos.User)
+	authManager.AuthService.EnablePasswordHistory(repos.PasswordHistory, authConfig.PasswordHistory)
+	authManager.AuthService.EnablePasswordSetup(repos.PasswordSetup)
+	authManager.AuthService.EnableRefreshTokenStore(repos.RefreshToken)
+
+	// Sign with the newest rotated key and accept every key still in use
+	if err := authManager.JWTService.EnableKeyStore(context.Background(), repos.JWTKey); err != nil {
+		log.Fatalf("Failed to load JWT signing keys: %v", err)
+	}
+
+	// In production, refuse to start unless tokens and cookies stay on TLS
+	serverConfig := admin.ConfigFromEnv()
+	if err := serverConfig.CheckTransport(authConfig.TokenCookieSecure); err != nil {
+		log.Fatalf("Insecure transport configuration: %v", err)
+	}
+
+	// Record moderation, impersonation and email changes in the audit_logs table
+	auditLogger := security.NewAuditLoggerWithStore(repos.AuditLog)
+
+	// Create email change service
+	emailChangeService := auth.NewEmailChangeService(repos.User, repos.EmailChange, authManager.AuthService.Mailer(), authConfig.EmailChangeTTL)
+	emailChangeService.SetAuditLogger(auditLogger)
+
+	// With REDIS_URL set, verification resends, comment cooldowns and
+	// impersonation cooldowns are shared by every server instance. Without
+	// it, resends are counted by this process and the cooldowns are off.
+	var resendCounter auth.RateCounter
+	var cooldownStore resolver.CooldownStore
+	if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+		redisCache, err := cache.NewRedisCacheFromURL(redisURL)
+		if err != nil {
+			log.Fatalf("Failed to configure Redis: %v", err)
+		}
+		defer redisCache.Close()
+		resendCounter = redisCache
+		cooldownStore = redisCache
+	}
+
+	// Create email verification service
+	emailVerificationService := auth.NewEmailVerificationService(repos.User, repos.EmailVerification, authManager.AuthService.Mailer(), resendCounter, authConfig.EmailVerificationTTL, authConfig.VerificationResendLimit)
+
+	// Load page size and filter limits
+	paginationPolicy := validation.PaginationPolicyFromEnv()
+	maxFilterTags := validation.MaxFilterTagsFromEnv()
+	maxCommentDepth := validation.MaxCommentDepthFromEnv()
+	maxPopularTags := validation.MaxPopularTagsFromEnv()
+	
+	// Optionally join post authors into the posts listing query
+	joinPostAuthors, _ := strconv.ParseBool(os.Getenv("POSTS_JOIN_AUTHORS"))
 
 	// Create GraphQL resolver with dependencies
 	graphqlResolver := &resolver.Resolver{
-		UserRepo:    repos.User,
-		PostRepo:    repos.Post,
-		CommentRepo: repos.Comment,
-		AuthManager: authManager,
+		UserRepo:                 repos.User,
+		PostRepo:                 repos.Post,
+		CommentRepo:              repos.Comment,
+		ReactionRepo:             repos.Reaction,
+		ReportRepo:               repos.Report,
+		AuthManager:              authManager,
+		EmailChangeService:       emailChangeService,
+		EmailVerificationService: emailVerificationService,
+		AuditLogger:              auditLogger,
+		Pagination:               &paginationPolicy,
+		MaxFilterTags:            maxFilterTags,
+		MaxCommentDepth:          maxCommentDepth,
+		MaxPopularTags:           maxPopularTags,
+		TagLimits:                &tagLimits,
+		JoinPostAuthors:          joinPostAuthors,
+		CommentCooldown:          &resolver.CommentCooldown{Store: cooldownStore, Period: resolver.CommentCooldownFromEnv()},
+		StatsCache:               &resolver.StatsCache{TTL: resolver.DefaultStatsTTL},
+		PostEditWindow:           resolver.PostEditWindowFromEnv(),
+		Impersonation:            &resolver.Impersonation{Store: cooldownStore, Cooldown: resolver.DefaultImpersonationCooldown},
+		SubscriptionVisibility:   &resolver.PostVisibilityCache{TTL: resolver.DefaultSubscriptionVisibilityTTL},
 	}
 
 	// Create Gin router
 	r := gin.Default()
 
-	// Enable CORS
-	r.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-		
-		c.Next()
-	})
+	// Allow the CORS_ALLOWED_ORIGINS browser origins and set security headers
+	// suited to GraphQL responses
+	r.Use(apiheaders.GinMiddleware(apiheaders.CORS(apiheaders.CORSConfigFromEnv())))
+	securityHeaders := apiheaders.DefaultSecurityHeadersConfig()
+	securityHeaders.ContentSecurityPolicy = apiheaders.GraphQLContentSecurityPolicy
+	r.Use(apiheaders.GinMiddleware(apiheaders.SecurityHeaders(securityHeaders)))
+
+	// Record the client IP and user agent for audit entries
+	r.Use(security.ClientInfoMiddleware())
 
 	// Apply optional authentication middleware
 	r.Use(authManager.Middleware.OptionalAuth())
 
+	// Batch and cache loads per request with DataLoaders
+	r.Use(apiheaders.GinMiddleware(dataloader.Middleware(repos)))
+
 	// Simple GraphQL-like endpoint for testing resolvers
 	r.POST("/graphql", func(c *gin.Context) {
 		var request map[string]interface{}
@@ -108,8 +190,8 @@ func main() {
 		})
 	})
 
-	log.Println("🎮 Simple GraphQL server ready at http://localhost:8080/graphql")
-	log.Println("❤️  Health check at http://localhost:8080/health")
+	log.Printf("🎮 Simple GraphQL server ready at http://localhost:%s/graphql", serverConfig.Port)
+	log.Printf("❤️  Health check at http://localhost:%s/health", serverConfig.Port)
 	log.Println("")
 	log.Println("📋 GraphQL resolvers are implemented and tested:")
 	log.Println("   ✅ Query resolvers (me, user, posts, post, searchPosts)")
@@ -119,7 +201,7 @@ func main() {
 	log.Println("   ✅ Database integration")
 	log.Println("   ✅ Comprehensive test coverage")
 
-	r.Run(":8080")
+	log.Fatal(admin.ServePublic(serverConfig, r))
 }
 
 func runMockDemo() {
@@ -180,5 +180,5 @@ func main() {
 	log.Println("🎮 GraphQL playground available at http://localhost:8080/playground")
 	log.Println("❤️  Health check at http://localhost:8080/health")
 	
-	r.Run(":8080")
+	log.Fatal(r.Run(":8080"))
 }
\ No newline at end of file
@@ -0,0 +1,220 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/envconfig"
+)
+
+// Paths lists the endpoints served only on the internal admin port; the
+// pprof endpoints are mounted only when enabled
+var Paths = []string{"/metrics", "/ready", "/debug/pprof/"}
+
+// DefaultAdminHost is the interface the admin mux listens on unless
+// ADMIN_HOST names another, so it is only reachable from the same host
+const DefaultAdminHost = "127.0.0.1"
+
+// Config holds the listen ports for the public API and the internal admin mux
+type Config struct {
+	// Port serves the public GraphQL API
+	Port string
+	// AdminPort serves metrics, readiness and pprof; empty disables them
+	AdminPort string
+	// AdminHost is the interface AdminPort listens on; empty uses
+	// DefaultAdminHost
+	AdminHost string
+	// Pprof mounts the net/http/pprof endpoints on the admin mux
+	Pprof bool
+	// Production enables the transport security checks in CheckTransport
+	Production bool
+	// TLSCertFile and TLSKeyFile serve the public API over TLS when both are set
+	TLSCertFile string
+	TLSKeyFile  string
+	// TrustedProxy declares that a trusted proxy terminates TLS in front of
+	// the public port
+	TrustedProxy bool
+	// TrustedProxies lists the proxy addresses or CIDR ranges whose
+	// X-Forwarded-For header is believed; with none, a request's client IP
+	// is the address it connected from
+	TrustedProxies []string
+}
+
+// DefaultConfig returns the default ports with the admin port disabled
+func DefaultConfig() Config {
+	return Config{Port: "8080"}
+}
+
+// ConfigFromEnv returns the default config overridden by PORT, ADMIN_PORT,
+// ADMIN_HOST, ADMIN_PPROF, APP_ENV, TLS_CERT_FILE, TLS_KEY_FILE, BEHIND_TRUSTED_PROXY and
+// TRUSTED_PROXIES.
+// Profiling stays off unless ADMIN_PPROF parses as true.
+func ConfigFromEnv() Config {
+	config := DefaultConfig()
+	if port := os.Getenv("PORT"); port != "" {
+		config.Port = port
+	}
+	config.AdminPort = os.Getenv("ADMIN_PORT")
+	config.AdminHost = strings.TrimSpace(os.Getenv("ADMIN_HOST"))
+	if enabled, err := strconv.ParseBool(os.Getenv("ADMIN_PPROF")); err == nil {
+		config.Pprof = enabled
+	}
+	config.Production = strings.EqualFold(strings.TrimSpace(os.Getenv("APP_ENV")), "production")
+	config.TLSCertFile = os.Getenv("TLS_CERT_FILE")
+	config.TLSKeyFile = os.Getenv("TLS_KEY_FILE")
+	if trusted, err := strconv.ParseBool(os.Getenv("BEHIND_TRUSTED_PROXY")); err == nil {
+		config.TrustedProxy = trusted
+	}
+	config.TrustedProxies = envconfig.List("TRUSTED_PROXIES")
+	return config
+}
+
+// TLSEnabled reports whether the public API is served over TLS
+func (c Config) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
+
+// CheckTransport reports a configuration that would expose tokens over plain
+// HTTP. A certificate without its key is always an error; in production the
+// public API must be served over TLS or behind a trusted proxy, and cookies
+// must be marked Secure.
+func (c Config) CheckTransport(secureCookies bool) error {
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if !c.Production {
+		return nil
+	}
+	if !c.TLSEnabled() && !c.TrustedProxy {
+		return fmt.Errorf("production requires TLS_CERT_FILE and TLS_KEY_FILE, or BEHIND_TRUSTED_PROXY when a proxy terminates TLS")
+	}
+	if !secureCookies {
+		return fmt.Errorf("production requires Secure cookies; AUTH_COOKIE_SECURE cannot be false")
+	}
+	return nil
+}
+
+// PublicAddr returns the listen address for the public API
+func (c Config) PublicAddr() string {
+	return ":" + c.Port
+}
+
+// AdminAddr returns the listen address for the admin mux, or "" when disabled
+func (c Config) AdminAddr() string {
+	if c.AdminPort == "" {
+		return ""
+	}
+	host := c.AdminHost
+	if host == "" {
+		host = DefaultAdminHost
+	}
+	return net.JoinHostPort(host, c.AdminPort)
+}
+
+// Check reports whether a dependency is ready to serve traffic
+type Check func(ctx context.Context) error
+
+// Options supplies the data the admin endpoints report
+type Options struct {
+	// Metrics maps a section name to a function returning its current values
+	Metrics map[string]func() interface{}
+	// Checks maps a dependency name to its readiness check
+	Checks map[string]Check
+	// ReadyTimeout bounds the readiness checks of one request; zero uses
+	// defaultReadyTimeout
+	ReadyTimeout time.Duration
+	// Pprof mounts the net/http/pprof endpoints under /debug/pprof/
+	Pprof bool
+}
+
+// defaultReadyTimeout bounds readiness checks when Options leaves it unset
+const defaultReadyTimeout = 2 * time.Second
+
+// NewMux returns the internal handler serving metrics, detailed readiness
+// and, when enabled, pprof. It must only be bound to a port that is not
+// publicly reachable.
+func NewMux(opts Options) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		metrics := make(map[string]interface{}, len(opts.Metrics))
+		for name, snapshot := range opts.Metrics {
+			metrics[name] = snapshot()
+		}
+		writeJSON(w, http.StatusOK, metrics)
+	})
+
+	mux.HandleFunc("GET /ready", func(w http.ResponseWriter, r *http.Request) {
+		timeout := opts.ReadyTimeout
+		if timeout <= 0 {
+			timeout = defaultReadyTimeout
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		status := http.StatusOK
+		checks := make(map[string]string, len(opts.Checks))
+		for name, check := range opts.Checks {
+			if err := check(ctx); err != nil {
+				status = http.StatusServiceUnavailable
+				checks[name] = err.Error()
+				continue
+			}
+			checks[name] = "ok"
+		}
+
+		state := "ready"
+		if status != http.StatusOK {
+			state = "unavailable"
+		}
+		writeJSON(w, status, map[string]interface{}{"status": state, "checks": checks})
+	})
+
+	if opts.Pprof {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+
+	return mux
+}
+
+// NewServer returns an HTTP server for the admin mux on the configured port
+func NewServer(config Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              config.AdminAddr(),
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
+
+// ServePublic serves handler on the public port, over TLS when a certificate
+// and key are configured. Like http.ListenAndServe, it only returns an error.
+func ServePublic(config Config, handler http.Handler) error {
+	server := &http.Server{
+		Addr:              config.PublicAddr(),
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	if config.TLSEnabled() {
+		return server.ListenAndServeTLS(config.TLSCertFile, config.TLSKeyFile)
+	}
+	return server.ListenAndServe()
+}
+
+// writeJSON writes value as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
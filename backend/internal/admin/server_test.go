@@ -0,0 +1,196 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serve(t *testing.T, handler http.Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestNewMux_ServesInternalEndpoints(t *testing.T) {
+	mux := NewMux(Options{
+		Metrics: map[string]func() interface{}{
+			"subscriptions": func() interface{} { return map[string]int{"active": 3} },
+		},
+		Checks: map[string]Check{"database": func(ctx context.Context) error { return nil }},
+		Pprof:  true,
+	})
+
+	metrics := serve(t, mux, "/metrics")
+	require.Equal(t, http.StatusOK, metrics.Code)
+	assert.JSONEq(t, `{"subscriptions":{"active":3}}`, metrics.Body.String())
+
+	ready := serve(t, mux, "/ready")
+	require.Equal(t, http.StatusOK, ready.Code)
+	assert.JSONEq(t, `{"status":"ready","checks":{"database":"ok"}}`, ready.Body.String())
+
+	assert.Equal(t, http.StatusOK, serve(t, mux, "/debug/pprof/").Code)
+	assert.Equal(t, http.StatusOK, serve(t, mux, "/debug/pprof/cmdline").Code)
+	assert.Equal(t, http.StatusNotFound, serve(t, mux, "/graphql").Code)
+}
+
+func TestNewMux_PprofOnlyWhenEnabled(t *testing.T) {
+	paths := []string{
+		"/debug/pprof/",
+		"/debug/pprof/heap",
+		"/debug/pprof/cmdline",
+		"/debug/pprof/symbol",
+	}
+
+	enabled := NewMux(Options{Pprof: true})
+	disabled := NewMux(Options{})
+
+	for _, path := range paths {
+		assert.Equal(t, http.StatusOK, serve(t, enabled, path).Code, path)
+		assert.Equal(t, http.StatusNotFound, serve(t, disabled, path).Code, path)
+	}
+	assert.Equal(t, http.StatusOK, serve(t, disabled, "/metrics").Code)
+}
+
+func TestNewMux_ReadyReportsFailingCheck(t *testing.T) {
+	mux := NewMux(Options{
+		Checks: map[string]Check{
+			"database": func(ctx context.Context) error { return fmt.Errorf("connection refused") },
+			"cache":    func(ctx context.Context) error { return nil },
+		},
+	})
+
+	rec := serve(t, mux, "/ready")
+
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	var body struct {
+		Status string            `json:"status"`
+		Checks map[string]string `json:"checks"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "unavailable", body.Status)
+	assert.Equal(t, map[string]string{"database": "connection refused", "cache": "ok"}, body.Checks)
+}
+
+func TestNewMux_ReadyBoundsChecks(t *testing.T) {
+	mux := NewMux(Options{
+		Checks: map[string]Check{
+			"database": func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		},
+		ReadyTimeout: time.Nanosecond,
+	})
+
+	rec := serve(t, mux, "/ready")
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "deadline exceeded")
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("PORT", "")
+		t.Setenv("ADMIN_PORT", "")
+		t.Setenv("ADMIN_HOST", "")
+		t.Setenv("ADMIN_PPROF", "")
+		t.Setenv("APP_ENV", "")
+		t.Setenv("TLS_CERT_FILE", "")
+		t.Setenv("TLS_KEY_FILE", "")
+		t.Setenv("BEHIND_TRUSTED_PROXY", "")
+		t.Setenv("TRUSTED_PROXIES", "")
+
+		config := ConfigFromEnv()
+
+		assert.Equal(t, ":8080", config.PublicAddr())
+		assert.Empty(t, config.AdminAddr(), "admin port is opt-in")
+		assert.False(t, config.Pprof, "profiling is opt-in")
+		assert.False(t, config.Production)
+		assert.False(t, config.TLSEnabled())
+		assert.False(t, config.TrustedProxy)
+		assert.Empty(t, config.TrustedProxies, "forwarded headers are ignored by default")
+	})
+
+	t.Run("admin port listens on loopback by default", func(t *testing.T) {
+		t.Setenv("ADMIN_PORT", "9090")
+		t.Setenv("ADMIN_HOST", "")
+
+		assert.Equal(t, "127.0.0.1:9090", ConfigFromEnv().AdminAddr())
+	})
+
+	t.Run("unparseable pprof flag stays off", func(t *testing.T) {
+		t.Setenv("ADMIN_PPROF", "sure")
+
+		assert.False(t, ConfigFromEnv().Pprof)
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		t.Setenv("PORT", "3000")
+		t.Setenv("ADMIN_PORT", "9090")
+		t.Setenv("ADMIN_HOST", "0.0.0.0")
+		t.Setenv("ADMIN_PPROF", "true")
+		t.Setenv("APP_ENV", "Production")
+		t.Setenv("TLS_CERT_FILE", "/etc/tls/cert.pem")
+		t.Setenv("TLS_KEY_FILE", "/etc/tls/key.pem")
+		t.Setenv("BEHIND_TRUSTED_PROXY", "true")
+		t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.4,")
+
+		config := ConfigFromEnv()
+
+		assert.True(t, config.Pprof)
+		assert.True(t, config.Production)
+		assert.True(t, config.TLSEnabled())
+		assert.True(t, config.TrustedProxy)
+		assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.4"}, config.TrustedProxies)
+		assert.Equal(t, ":3000", config.PublicAddr())
+		assert.Equal(t, "0.0.0.0:9090", config.AdminAddr())
+		assert.Equal(t, "0.0.0.0:9090", NewServer(config, NewMux(Options{})).Addr)
+	})
+}
+
+func TestConfig_CheckTransport(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        Config
+		secureCookies bool
+		expectedError string
+	}{
+		{name: "development over plain HTTP", config: Config{}, secureCookies: false},
+		{name: "certificate without key", config: Config{TLSCertFile: "cert.pem"}, secureCookies: true, expectedError: "must be set together"},
+		{name: "key without certificate", config: Config{Production: true, TLSKeyFile: "key.pem"}, secureCookies: true, expectedError: "must be set together"},
+		{name: "production over plain HTTP", config: Config{Production: true}, secureCookies: true, expectedError: "production requires TLS_CERT_FILE"},
+		{name: "production with TLS", config: Config{Production: true, TLSCertFile: "cert.pem", TLSKeyFile: "key.pem"}, secureCookies: true},
+		{name: "production behind trusted proxy", config: Config{Production: true, TrustedProxy: true}, secureCookies: true},
+		{name: "production with insecure cookies", config: Config{Production: true, TrustedProxy: true}, secureCookies: false, expectedError: "AUTH_COOKIE_SECURE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.CheckTransport(tt.secureCookies)
+
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+		})
+	}
+}
+
+func TestServePublic_ReportsTLSFailure(t *testing.T) {
+	config := Config{Port: "0", TLSCertFile: "missing-cert.pem", TLSKeyFile: "missing-key.pem"}
+
+	err := ServePublic(config, http.NotFoundHandler())
+
+	assert.ErrorContains(t, err, "missing-cert.pem")
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"log"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcrypt cost calibration modes
+const (
+	// CalibrationOff skips the startup measurement
+	CalibrationOff = "off"
+	// CalibrationWarn logs a warning when the configured cost hashes too fast
+	CalibrationWarn = "warn"
+	// CalibrationRaise also raises the cost until hashing takes the target
+	// time, up to the configured maximum
+	CalibrationRaise = "raise"
+)
+
+// MaxCalibratedBCryptCost is the highest cost calibration ever raises to,
+// whatever maximum is configured. Each step doubles the hashing time, and
+// cost 16 already takes seconds per login on typical hardware.
+const MaxCalibratedBCryptCost = 16
+
+// CostCalibration measures how long one bcrypt hash takes at a cost, so
+// operators learn when hardware has made their cost too cheap
+type CostCalibration struct {
+	// Mode is CalibrationWarn or CalibrationRaise
+	Mode string
+	// Target is the least time one hash should take
+	Target time.Duration
+	// MaxCost bounds raising; it is lowered to MaxCalibratedBCryptCost
+	MaxCost int
+
+	// measure hashes once at cost and returns how long it took
+	measure func(cost int) time.Duration
+}
+
+// CalibrationResult is the outcome of a calibration
+type CalibrationResult struct {
+	// Cost is the cost to hash with: the configured one, or a raised one
+	Cost int
+	// Measured is how long one hash at Cost took
+	Measured time.Duration
+	// BelowTarget is set when hashing at Cost is still faster than the target
+	BelowTarget bool
+}
+
+// NewCostCalibration creates a calibration timing real bcrypt hashes
+func NewCostCalibration(mode string, target time.Duration, maxCost int) *CostCalibration {
+	return &CostCalibration{Mode: mode, Target: target, MaxCost: maxCost, measure: measureBCrypt}
+}
+
+// Calibrate measures hashing at cost. In raise mode it then tries higher
+// costs until one reaches the target or the maximum is hit. The cost is
+// never lowered, and a cost above the maximum is left as it is.
+func (c *CostCalibration) Calibrate(cost int) CalibrationResult {
+	maxCost := min(c.MaxCost, MaxCalibratedBCryptCost)
+
+	measured := c.measure(cost)
+	for c.Mode == CalibrationRaise && measured < c.Target && cost < maxCost {
+		cost++
+		measured = c.measure(cost)
+	}
+
+	return CalibrationResult{Cost: cost, Measured: measured, BelowTarget: measured < c.Target}
+}
+
+// calibrateBCryptCost returns the cost to hash passwords with, logging how
+// the configured cost compares to the target
+func calibrateBCryptCost(config *Config) int {
+	if config.BCryptCalibration == "" || config.BCryptCalibration == CalibrationOff {
+		return config.BCryptCost
+	}
+
+	calibration := NewCostCalibration(config.BCryptCalibration, config.BCryptTargetDuration, config.BCryptMaxCost)
+	result := calibration.Calibrate(config.BCryptCost)
+	if result.Cost != config.BCryptCost {
+		log.Printf("Raised bcrypt cost from %d to %d: one hash now takes %v (target %v)",
+			config.BCryptCost, result.Cost, result.Measured, config.BCryptTargetDuration)
+	}
+	if result.BelowTarget {
+		log.Printf("WARNING: bcrypt cost %d hashes in %v, below the %v target; raise BCRYPT_COST",
+			result.Cost, result.Measured, config.BCryptTargetDuration)
+	}
+	return result.Cost
+}
+
+// measureBCrypt times one bcrypt hash at cost
+func measureBCrypt(cost int) time.Duration {
+	start := time.Now()
+	_, _ = bcrypt.GenerateFromPassword([]byte("bcrypt cost calibration"), cost)
+	return time.Since(start)
+}
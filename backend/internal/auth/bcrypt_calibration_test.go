@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCalibration times hashes from a table instead of running bcrypt,
+// recording which costs were measured
+func fakeCalibration(mode string, maxCost int, timings map[int]time.Duration) (*CostCalibration, *[]int) {
+	var measured []int
+	calibration := &CostCalibration{
+		Mode:    mode,
+		Target:  50 * time.Millisecond,
+		MaxCost: maxCost,
+		measure: func(cost int) time.Duration {
+			measured = append(measured, cost)
+			return timings[cost]
+		},
+	}
+	return calibration, &measured
+}
+
+func TestCostCalibration_Calibrate(t *testing.T) {
+	// Every cost step doubles the hashing time
+	timings := map[int]time.Duration{
+		10: 10 * time.Millisecond,
+		11: 20 * time.Millisecond,
+		12: 40 * time.Millisecond,
+		13: 80 * time.Millisecond,
+		14: 160 * time.Millisecond,
+		15: 320 * time.Millisecond,
+		16: 640 * time.Millisecond,
+		17: 1280 * time.Millisecond,
+	}
+
+	t.Run("warn mode keeps a cost that is too fast", func(t *testing.T) {
+		calibration, measured := fakeCalibration(CalibrationWarn, 14, timings)
+
+		result := calibration.Calibrate(10)
+
+		assert.Equal(t, CalibrationResult{Cost: 10, Measured: 10 * time.Millisecond, BelowTarget: true}, result)
+		assert.Equal(t, []int{10}, *measured)
+	})
+
+	t.Run("raise mode stops at the first cost reaching the target", func(t *testing.T) {
+		calibration, measured := fakeCalibration(CalibrationRaise, 14, timings)
+
+		result := calibration.Calibrate(10)
+
+		assert.Equal(t, CalibrationResult{Cost: 13, Measured: 80 * time.Millisecond}, result)
+		assert.Equal(t, []int{10, 11, 12, 13}, *measured)
+	})
+
+	t.Run("raise mode never exceeds the configured maximum", func(t *testing.T) {
+		calibration, _ := fakeCalibration(CalibrationRaise, 11, timings)
+
+		result := calibration.Calibrate(10)
+
+		assert.Equal(t, CalibrationResult{Cost: 11, Measured: 20 * time.Millisecond, BelowTarget: true}, result)
+	})
+
+	t.Run("raise mode never exceeds the safe maximum", func(t *testing.T) {
+		slow := map[int]time.Duration{}
+		for cost := 4; cost <= 31; cost++ {
+			slow[cost] = time.Millisecond
+		}
+		calibration, measured := fakeCalibration(CalibrationRaise, 31, slow)
+
+		result := calibration.Calibrate(15)
+
+		assert.Equal(t, MaxCalibratedBCryptCost, result.Cost)
+		assert.True(t, result.BelowTarget)
+		assert.Equal(t, []int{15, 16}, *measured)
+	})
+
+	t.Run("a cost already slow enough is kept", func(t *testing.T) {
+		calibration, measured := fakeCalibration(CalibrationRaise, 14, timings)
+
+		result := calibration.Calibrate(13)
+
+		assert.Equal(t, CalibrationResult{Cost: 13, Measured: 80 * time.Millisecond}, result)
+		assert.Equal(t, []int{13}, *measured)
+	})
+
+	t.Run("a cost above the maximum is never lowered", func(t *testing.T) {
+		calibration, _ := fakeCalibration(CalibrationRaise, 14, timings)
+
+		result := calibration.Calibrate(17)
+
+		assert.Equal(t, 17, result.Cost)
+	})
+}
+
+func TestCalibrateBCryptCost_OffByDefault(t *testing.T) {
+	config := &Config{BCryptCost: 12, BCryptCalibration: CalibrationOff, BCryptTargetDuration: time.Hour, BCryptMaxCost: 14}
+
+	// With calibration on this would hash at cost 12, taking far too long
+	assert.Equal(t, 12, calibrateBCryptCost(config))
+}
+
+func TestNewConfig_BCryptCalibration(t *testing.T) {
+	config := NewConfig()
+	assert.Equal(t, CalibrationOff, config.BCryptCalibration)
+	assert.Equal(t, 50*time.Millisecond, config.BCryptTargetDuration)
+	assert.Equal(t, 14, config.BCryptMaxCost)
+
+	t.Setenv("BCRYPT_CALIBRATION", CalibrationRaise)
+	t.Setenv("BCRYPT_TARGET_DURATION", "100ms")
+	t.Setenv("BCRYPT_MAX_COST", "15")
+	config = NewConfig()
+	assert.Equal(t, CalibrationRaise, config.BCryptCalibration)
+	assert.Equal(t, 100*time.Millisecond, config.BCryptTargetDuration)
+	assert.Equal(t, 15, config.BCryptMaxCost)
+}
@@ -1,26 +1,95 @@
 package auth
 
 import (
+	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Config holds authentication configuration
 type Config struct {
-	JWTSecret       string
-	TokenDuration   time.Duration
-	BCryptCost      int
-	RefreshWindow   time.Duration
+	JWTSecret      string
+	JWTIssuer      string
+	JWTAudiences   []string
+	TokenDuration  time.Duration
+	BCryptCost     int
+	RefreshWindow  time.Duration
+	EmailChangeTTL time.Duration
+	// RefreshTokenDuration is how long the refresh tokens issued at login and registration last
+	RefreshTokenDuration time.Duration
+	// JWTKeyEncryptionKey encrypts rotated signing keys in the database;
+	// empty uses JWTSecret
+	JWTKeyEncryptionKey string
+	// BCryptCalibration is CalibrationOff, CalibrationWarn or CalibrationRaise;
+	// calibration times a hash at startup against BCryptTargetDuration and,
+	// when raising, raises BCryptCost up to BCryptMaxCost
+	BCryptCalibration    string
+	BCryptTargetDuration time.Duration
+	BCryptMaxCost        int
+	// EmailVerificationTTL is how long a verification token stays valid
+	EmailVerificationTTL time.Duration
+	// PasswordSetupTTL is how long a password setup token for an imported user stays valid
+	PasswordSetupTTL time.Duration
+	// VerificationResendLimit is how many verification emails a user or address may request per hour
+	VerificationResendLimit int
+	// PasswordHistory is how many recent passwords cannot be reused; 0 disables the check
+	PasswordHistory int
+	// PasswordPeppers are mixed into passwords before bcrypt; the first hashes
+	// new passwords and the rest verify older hashes. Empty disables peppering.
+	PasswordPeppers []PasswordPepper
+	// TokenMode is TokenModeHeader or TokenModeCookie
+	TokenMode string
+	// Token cookie settings, used in cookie mode; SameSite is strict, lax or none
+	TokenCookieName     string
+	TokenCookieDomain   string
+	TokenCookieSecure   bool
+	TokenCookieSameSite string
+	// RefreshCookieName names the HttpOnly cookie carrying the refresh token in cookie mode
+	RefreshCookieName string
+	// CSRFCookieName names the script-readable cookie that mutations must repeat in the X-CSRF-Token header
+	CSRFCookieName string
+	// SMTP settings; mail is only logged when SMTPHost is empty
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
 }
 
 // NewConfig creates a new authentication configuration from environment variables
 func NewConfig() *Config {
 	return &Config{
-		JWTSecret:     getEnv("JWT_SECRET", "your-super-secret-jwt-key-change-in-production"),
-		TokenDuration: getDurationEnv("JWT_TOKEN_DURATION", 24*time.Hour),
-		BCryptCost:    getIntEnv("BCRYPT_COST", 12),
-		RefreshWindow: getDurationEnv("JWT_REFRESH_WINDOW", 2*time.Hour),
+		JWTSecret:               getEnv("JWT_SECRET", "your-super-secret-jwt-key-change-in-production"),
+		JWTKeyEncryptionKey:     getEnv("JWT_KEY_ENCRYPTION_KEY", ""),
+		JWTIssuer:               getEnv("JWT_ISSUER", DefaultIssuer),
+		JWTAudiences:            getListEnv("JWT_AUDIENCE", []string{DefaultIssuer}),
+		TokenDuration:           getDurationEnv("JWT_TOKEN_DURATION", 24*time.Hour),
+		BCryptCost:              getIntEnv("BCRYPT_COST", 12),
+		BCryptCalibration:       getEnv("BCRYPT_CALIBRATION", CalibrationOff),
+		BCryptTargetDuration:    getDurationEnv("BCRYPT_TARGET_DURATION", 50*time.Millisecond),
+		BCryptMaxCost:           getIntEnv("BCRYPT_MAX_COST", 14),
+		RefreshWindow:           getDurationEnv("JWT_REFRESH_WINDOW", 2*time.Hour),
+		RefreshTokenDuration:    getDurationEnv("JWT_REFRESH_TOKEN_DURATION", DefaultRefreshTokenDuration),
+		EmailChangeTTL:          getDurationEnv("EMAIL_CHANGE_TOKEN_TTL", 24*time.Hour),
+		EmailVerificationTTL:    getDurationEnv("EMAIL_VERIFICATION_TOKEN_TTL", 24*time.Hour),
+		PasswordSetupTTL:        getDurationEnv("PASSWORD_SETUP_TOKEN_TTL", 7*24*time.Hour),
+		VerificationResendLimit: getIntEnv("EMAIL_VERIFICATION_RESEND_LIMIT", 3),
+		PasswordHistory:         getIntEnv("PASSWORD_HISTORY_SIZE", 0),
+		PasswordPeppers:         getPepperEnv("PASSWORD_PEPPERS"),
+		TokenMode:               getEnv("AUTH_TOKEN_MODE", TokenModeHeader),
+		TokenCookieName:         getEnv("AUTH_COOKIE_NAME", DefaultTokenCookieName),
+		TokenCookieDomain:       getEnv("AUTH_COOKIE_DOMAIN", ""),
+		TokenCookieSecure:       getBoolEnv("AUTH_COOKIE_SECURE", true),
+		TokenCookieSameSite:     getEnv("AUTH_COOKIE_SAMESITE", "lax"),
+		RefreshCookieName:       getEnv("AUTH_REFRESH_COOKIE_NAME", DefaultRefreshCookieName),
+		CSRFCookieName:          getEnv("AUTH_CSRF_COOKIE_NAME", DefaultCSRFCookieName),
+		SMTPHost:                getEnv("SMTP_HOST", ""),
+		SMTPPort:                getIntEnv("SMTP_PORT", 587),
+		SMTPUsername:            getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:            getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:                getEnv("SMTP_FROM", "no-reply@localhost"),
 	}
 }
 
@@ -42,6 +111,51 @@ func getIntEnv(key string, fallback int) int {
 	return fallback
 }
 
+// getBoolEnv gets a boolean environment variable with a fallback value
+func getBoolEnv(key string, fallback bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return fallback
+}
+
+// getListEnv gets a comma-separated environment variable with a fallback value
+func getListEnv(key string, fallback []string) []string {
+	if value := os.Getenv(key); value != "" {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		if len(items) > 0 {
+			return items
+		}
+	}
+	return fallback
+}
+
+// getPepperEnv reads comma-separated version:secret pairs, newest first.
+// Malformed entries are skipped rather than hashing with an unintended pepper.
+func getPepperEnv(key string) []PasswordPepper {
+	var peppers []PasswordPepper
+	seen := make(map[string]bool)
+	for i, item := range getListEnv(key, nil) {
+		version, secret, ok := strings.Cut(item, ":")
+		version = strings.TrimSpace(version)
+		if !ok || version == "" || secret == "" || strings.Contains(version, "$") || seen[version] {
+			// Never log the entry itself, it may hold the secret
+			log.Printf("Ignoring invalid %s entry %d", key, i+1)
+			continue
+		}
+		seen[version] = true
+		peppers = append(peppers, PasswordPepper{Version: version, Secret: secret})
+	}
+	return peppers
+}
+
 // getDurationEnv gets a duration environment variable with a fallback value
 func getDurationEnv(key string, fallback time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
@@ -50,4 +164,4 @@ func getDurationEnv(key string, fallback time.Duration) time.Duration {
 		}
 	}
 	return fallback
-}
\ No newline at end of file
+}
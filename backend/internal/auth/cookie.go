@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Token transport modes
+const (
+	// TokenModeHeader returns tokens in the response body only; requests carry them in the Authorization header
+	TokenModeHeader = "header"
+	// TokenModeCookie also sets tokens as an HttpOnly cookie, which is read when the Authorization header is absent
+	TokenModeCookie = "cookie"
+)
+
+// DefaultTokenCookieName is the name of the access token cookie
+const DefaultTokenCookieName = "access_token"
+
+// DefaultRefreshCookieName is the name of the refresh token cookie
+const DefaultRefreshCookieName = "refresh_token"
+
+// TokenCookie describes the HttpOnly cookies that carry the access and
+// refresh tokens and the CSRF cookie issued alongside them
+type TokenCookie struct {
+	Name        string
+	RefreshName string
+	CSRFName    string
+	Domain      string
+	Secure      bool
+	SameSite    http.SameSite
+}
+
+// NewTokenCookie returns the token cookie described by config, or nil when
+// tokens travel in the Authorization header only
+func NewTokenCookie(config *Config) *TokenCookie {
+	if config.TokenMode != TokenModeCookie {
+		return nil
+	}
+
+	name := config.TokenCookieName
+	if name == "" {
+		name = DefaultTokenCookieName
+	}
+	refreshName := config.RefreshCookieName
+	if refreshName == "" {
+		refreshName = DefaultRefreshCookieName
+	}
+	csrfName := config.CSRFCookieName
+	if csrfName == "" {
+		csrfName = DefaultCSRFCookieName
+	}
+	return &TokenCookie{
+		Name:        name,
+		RefreshName: refreshName,
+		CSRFName:    csrfName,
+		Domain:      config.TokenCookieDomain,
+		Secure:      config.TokenCookieSecure,
+		SameSite:    parseSameSite(config.TokenCookieSameSite),
+	}
+}
+
+// Cookie builds the cookie carrying token until expiresAt
+func (c *TokenCookie) Cookie(token string, expiresAt time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     c.Name,
+		Value:    token,
+		Path:     "/",
+		Domain:   c.Domain,
+		Expires:  expiresAt,
+		MaxAge:   int(time.Until(expiresAt).Seconds()),
+		HttpOnly: true,
+		Secure:   c.Secure,
+		SameSite: c.SameSite,
+	}
+}
+
+// RefreshCookie builds the cookie carrying refreshToken until expiresAt
+func (c *TokenCookie) RefreshCookie(refreshToken string, expiresAt time.Time) *http.Cookie {
+	cookie := c.Cookie(refreshToken, expiresAt)
+	cookie.Name = c.RefreshName
+	return cookie
+}
+
+// setAuthCookies sets the cookies for response on w and clears its refresh
+// token, which then only travels in the cookie
+func (c *TokenCookie) setAuthCookies(w http.ResponseWriter, response *AuthResponse) {
+	c.setCookies(w, response.Token, response.ExpiresAt)
+	if response.RefreshToken != "" && response.RefreshExpiresAt != nil {
+		http.SetCookie(w, c.RefreshCookie(response.RefreshToken, *response.RefreshExpiresAt))
+		response.RefreshToken = ""
+	}
+}
+
+// clearCookies expires the token, refresh and CSRF cookies on w
+func (c *TokenCookie) clearCookies(w http.ResponseWriter) {
+	for _, name := range []string{c.Name, c.RefreshName, c.CSRFName} {
+		cookie := c.Cookie("", time.Unix(0, 0))
+		cookie.Name = name
+		cookie.MaxAge = -1
+		http.SetCookie(w, cookie)
+	}
+}
+
+// refreshToken returns the refresh token cookie of r
+func (c *TokenCookie) refreshToken(r *http.Request) (string, bool) {
+	cookie, err := r.Cookie(c.RefreshName)
+	if err != nil || cookie.Value == "" {
+		return "", false
+	}
+	return cookie.Value, true
+}
+
+// parseSameSite maps a SameSite setting to its cookie mode, defaulting to lax
+func parseSameSite(value string) http.SameSite {
+	switch strings.ToLower(value) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// tokenCookieKey is the context key for the response writer of a cookie-mode request
+const tokenCookieKey ContextKey = "token_cookie"
+
+// tokenCookieWriter sets the token cookie on the response of the current
+// request and reads the refresh cookie sent with it
+type tokenCookieWriter struct {
+	cookie *TokenCookie
+	w      http.ResponseWriter
+	r      *http.Request
+}
+
+// withTokenCookieWriter lets resolvers further down the request set the token
+// cookie on w and read the refresh cookie of r
+func withTokenCookieWriter(ctx context.Context, cookie *TokenCookie, w http.ResponseWriter, r *http.Request) context.Context {
+	return context.WithValue(ctx, tokenCookieKey, &tokenCookieWriter{cookie: cookie, w: w, r: r})
+}
+
+// SetTokenCookie sets the token cookie, and a fresh CSRF cookie, on the
+// response of the request behind ctx. It reports false, and does nothing,
+// unless the auth middleware runs in cookie mode.
+func SetTokenCookie(ctx context.Context, token string, expiresAt time.Time) bool {
+	writer, ok := ctx.Value(tokenCookieKey).(*tokenCookieWriter)
+	if !ok {
+		return false
+	}
+	writer.cookie.setCookies(writer.w, token, expiresAt)
+	return true
+}
+
+// SetAuthCookies sets the token, refresh and CSRF cookies for response on
+// the response of the request behind ctx, and clears response.RefreshToken
+// so the refresh token is not also returned in the body. It reports false,
+// and does nothing, unless the auth middleware runs in cookie mode.
+func SetAuthCookies(ctx context.Context, response *AuthResponse) bool {
+	writer, ok := ctx.Value(tokenCookieKey).(*tokenCookieWriter)
+	if !ok {
+		return false
+	}
+	writer.cookie.setAuthCookies(writer.w, response)
+	return true
+}
+
+// ClearAuthCookies expires the token, refresh and CSRF cookies on the
+// response of the request behind ctx. It reports false, and does nothing,
+// unless the auth middleware runs in cookie mode.
+func ClearAuthCookies(ctx context.Context) bool {
+	writer, ok := ctx.Value(tokenCookieKey).(*tokenCookieWriter)
+	if !ok {
+		return false
+	}
+	writer.cookie.clearCookies(writer.w)
+	return true
+}
+
+// RefreshTokenFromCookie returns the refresh token cookie sent with the
+// request behind ctx, in cookie mode
+func RefreshTokenFromCookie(ctx context.Context) (string, bool) {
+	writer, ok := ctx.Value(tokenCookieKey).(*tokenCookieWriter)
+	if !ok || writer.r == nil {
+		return "", false
+	}
+	return writer.cookie.refreshToken(writer.r)
+}
@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend/internal/graph/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cookieConfig() *Config {
+	return &Config{
+		TokenMode:           TokenModeCookie,
+		TokenCookieName:     "session",
+		TokenCookieDomain:   "example.com",
+		TokenCookieSecure:   true,
+		TokenCookieSameSite: "strict",
+	}
+}
+
+// newCookieRouter serves /whoami behind handler, reporting the authenticated user's email
+func newCookieRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/whoami", handler, func(c *gin.Context) {
+		user, ok := GetUserFromContext(c.Request.Context())
+		if !ok {
+			c.String(http.StatusOK, "anonymous")
+			return
+		}
+		c.String(http.StatusOK, user.Email)
+	})
+	return r
+}
+
+func newCookieMiddleware(t *testing.T, config *Config) (*AuthMiddleware, string) {
+	user := &model.User{ID: uuid.New(), Email: "cookie@example.com", Name: "Cookie"}
+	jwtService := NewJWTService("test-secret-key", time.Hour)
+	token, _, err := jwtService.GenerateToken(user)
+	require.NoError(t, err)
+
+	middleware := NewAuthMiddleware(jwtService, newMemoryUserRepo(user))
+	middleware.UseTokenCookie(NewTokenCookie(config))
+	return middleware, token
+}
+
+func TestAuthMiddleware_AuthenticatesFromCookie(t *testing.T) {
+	middleware, token := newCookieMiddleware(t, cookieConfig())
+
+	for name, handler := range map[string]gin.HandlerFunc{
+		"optional": middleware.OptionalAuth(),
+		"required": middleware.RequiredAuth(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			req.AddCookie(&http.Cookie{Name: "session", Value: token})
+			rec := httptest.NewRecorder()
+			newCookieRouter(handler).ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "cookie@example.com", rec.Body.String())
+		})
+	}
+}
+
+func TestAuthMiddleware_StoresToken(t *testing.T) {
+	middleware, token := newCookieMiddleware(t, cookieConfig())
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/token", middleware.OptionalAuth(), func(c *gin.Context) {
+		stored, _ := GetTokenFromContext(c.Request.Context())
+		c.String(http.StatusOK, stored)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/token", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, token, rec.Body.String())
+}
+
+func TestAuthMiddleware_HeaderModeIgnoresCookie(t *testing.T) {
+	middleware, token := newCookieMiddleware(t, &Config{TokenMode: TokenModeHeader})
+
+	req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	req.AddCookie(&http.Cookie{Name: DefaultTokenCookieName, Value: token})
+
+	rec := httptest.NewRecorder()
+	newCookieRouter(middleware.OptionalAuth()).ServeHTTP(rec, req)
+	assert.Equal(t, "anonymous", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	newCookieRouter(middleware.RequiredAuth()).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestAuthMiddleware_HeaderTakesPrecedenceOverCookie(t *testing.T) {
+	middleware, token := newCookieMiddleware(t, cookieConfig())
+
+	req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.AddCookie(&http.Cookie{Name: "session", Value: "not-a-token"})
+
+	got, err := middleware.TokenFromRequest(req)
+	require.NoError(t, err)
+	assert.Equal(t, token, got)
+}
+
+func TestNewTokenCookie(t *testing.T) {
+	assert.Nil(t, NewTokenCookie(&Config{TokenMode: TokenModeHeader}))
+
+	cookie := NewTokenCookie(&Config{TokenMode: TokenModeCookie})
+	require.NotNil(t, cookie)
+	assert.Equal(t, DefaultTokenCookieName, cookie.Name)
+	assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+}
+
+func TestSetTokenCookie_Attributes(t *testing.T) {
+	middleware, _ := newCookieMiddleware(t, cookieConfig())
+	expiresAt := time.Now().Add(time.Hour)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/login", middleware.OptionalAuth(), func(c *gin.Context) {
+		assert.True(t, SetTokenCookie(c.Request.Context(), "issued-token", expiresAt))
+		c.Status(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/login", nil))
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 2)
+	cookie := cookies[0]
+	assert.Equal(t, "session", cookie.Name)
+	assert.Equal(t, "issued-token", cookie.Value)
+	assert.Equal(t, "/", cookie.Path)
+	assert.Equal(t, "example.com", cookie.Domain)
+	assert.True(t, cookie.HttpOnly)
+	assert.True(t, cookie.Secure)
+	assert.Equal(t, http.SameSiteStrictMode, cookie.SameSite)
+	assert.Positive(t, cookie.MaxAge)
+	assert.WithinDuration(t, expiresAt, cookie.Expires, time.Second)
+
+	// The CSRF cookie must be readable by scripts so they can echo it in the header
+	csrf := cookies[1]
+	assert.Equal(t, DefaultCSRFCookieName, csrf.Name)
+	assert.NotEmpty(t, csrf.Value)
+	assert.False(t, csrf.HttpOnly)
+	assert.True(t, csrf.Secure)
+	assert.Equal(t, http.SameSiteStrictMode, csrf.SameSite)
+}
+
+func TestSetTokenCookie_HeaderModeIsNoop(t *testing.T) {
+	middleware, _ := newCookieMiddleware(t, &Config{TokenMode: TokenModeHeader})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/login", middleware.OptionalAuth(), func(c *gin.Context) {
+		assert.False(t, SetTokenCookie(c.Request.Context(), "issued-token", time.Now().Add(time.Hour)))
+		c.Status(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/login", nil))
+	assert.Empty(t, rec.Result().Cookies())
+}
+
+func TestAuthMiddleware_ClearAuthCookies(t *testing.T) {
+	middleware := NewAuthMiddleware(NewJWTService("test-secret-key", time.Hour), newMemoryUserRepo())
+	middleware.UseTokenCookie(NewTokenCookie(cookieConfig()))
+	rec := httptest.NewRecorder()
+
+	middleware.ClearAuthCookies(rec)
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 3)
+	names := []string{}
+	for _, cookie := range cookies {
+		names = append(names, cookie.Name)
+		assert.Empty(t, cookie.Value)
+		assert.Equal(t, -1, cookie.MaxAge)
+	}
+	assert.ElementsMatch(t, []string{"session", DefaultRefreshCookieName, DefaultCSRFCookieName}, names)
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// runGuarded sends req through the auth middleware and the CSRF guard as an
+// operation of the given type, returning the guard's error code or "" when it passes
+func runGuarded(t *testing.T, middleware *AuthMiddleware, req *http.Request, operation ast.Operation) string {
+	t.Helper()
+	var code string
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/graphql", middleware.OptionalAuth(), func(c *gin.Context) {
+		_, ok := GetUserFromContext(c.Request.Context())
+		require.True(t, ok, "request should be authenticated")
+
+		rc := &graphql.OperationContext{Operation: &ast.OperationDefinition{Operation: operation}}
+		if err := NewCSRFGuard().MutateOperationContext(c.Request.Context(), rc); err != nil {
+			code, _ = err.Extensions["code"].(string)
+		}
+		c.Status(http.StatusOK)
+	})
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	return code
+}
+
+// cookieRequest is a request authenticated by the token cookie, carrying the
+// given CSRF cookie and header values when non-empty
+func cookieRequest(token, csrfCookie, csrfHeader string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: token})
+	if csrfCookie != "" {
+		req.AddCookie(&http.Cookie{Name: DefaultCSRFCookieName, Value: csrfCookie})
+	}
+	if csrfHeader != "" {
+		req.Header.Set(CSRFHeader, csrfHeader)
+	}
+	return req
+}
+
+func TestCSRFGuard_CookieMutation(t *testing.T) {
+	middleware, token := newCookieMiddleware(t, cookieConfig())
+
+	tests := []struct {
+		name       string
+		csrfCookie string
+		csrfHeader string
+		rejected   bool
+	}{
+		{name: "matching token", csrfCookie: "abc123", csrfHeader: "abc123"},
+		{name: "no header", csrfCookie: "abc123", rejected: true},
+		{name: "no cookie", csrfHeader: "abc123", rejected: true},
+		{name: "mismatched token", csrfCookie: "abc123", csrfHeader: "xyz789", rejected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := runGuarded(t, middleware, cookieRequest(token, tt.csrfCookie, tt.csrfHeader), ast.Mutation)
+			if tt.rejected {
+				assert.Equal(t, "CSRF_TOKEN_INVALID", code)
+			} else {
+				assert.Empty(t, code)
+			}
+		})
+	}
+}
+
+func TestCSRFGuard_QueriesAreExempt(t *testing.T) {
+	middleware, token := newCookieMiddleware(t, cookieConfig())
+
+	assert.Empty(t, runGuarded(t, middleware, cookieRequest(token, "", ""), ast.Query))
+}
+
+func TestCSRFGuard_HeaderAuthIsExempt(t *testing.T) {
+	middleware, token := newCookieMiddleware(t, cookieConfig())
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	assert.Empty(t, runGuarded(t, middleware, req, ast.Mutation))
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"backend/internal/clock"
+	"backend/internal/graph/model"
+	"backend/internal/graph/validation"
+	"backend/internal/ids"
+	"backend/internal/repository"
+	"backend/internal/security"
+)
+
+var (
+	// ErrEmailTaken is returned when the requested email belongs to another account
+	ErrEmailTaken = errors.New("email address is already in use")
+	// ErrEmailUnchanged is returned when the requested email is the current one
+	ErrEmailUnchanged = errors.New("new email matches the current email")
+	// ErrInvalidEmailChangeToken is returned for unknown or expired confirmation tokens
+	ErrInvalidEmailChangeToken = errors.New("invalid or expired email change token")
+)
+
+// EmailChangeService handles confirmed changes of a user's email address.
+// The current email stays authoritative until the token sent to the new
+// address is confirmed.
+type EmailChangeService struct {
+	userRepo    repository.UserRepository
+	changeRepo  repository.EmailChangeRepository
+	mailer      Mailer
+	auditLogger *security.AuditLogger
+	tokenTTL    time.Duration
+	now         clock.Clock
+	newID       ids.Generator
+}
+
+// NewEmailChangeService creates a new email change service
+func NewEmailChangeService(userRepo repository.UserRepository, changeRepo repository.EmailChangeRepository, mailer Mailer, tokenTTL time.Duration) *EmailChangeService {
+	return &EmailChangeService{
+		userRepo:    userRepo,
+		changeRepo:  changeRepo,
+		mailer:      mailer,
+		auditLogger: security.NewAuditLogger(),
+		tokenTTL:    tokenTTL,
+		now:         clock.Now,
+		newID:       ids.New,
+	}
+}
+
+// SetAuditLogger records confirmed email changes in logger instead of printing them
+func (s *EmailChangeService) SetAuditLogger(logger *security.AuditLogger) {
+	s.auditLogger = logger
+}
+
+// RequestEmailChange records a pending change and mails a confirmation token to the new address
+func (s *EmailChangeService) RequestEmailChange(ctx context.Context, user *model.User, newEmail string) error {
+	newEmail = validation.NormalizeEmail(newEmail)
+
+	if newEmail == validation.NormalizeEmail(user.Email) {
+		return ErrEmailUnchanged
+	}
+
+	taken, err := s.userRepo.ExistsByEmail(ctx, newEmail)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrEmailTaken
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	// Only the latest request for a user can be confirmed
+	if err := s.changeRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	now := s.now()
+	req := &model.EmailChangeRequest{
+		ID:        s.newID(),
+		UserID:    user.ID,
+		NewEmail:  newEmail,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(s.tokenTTL),
+		CreatedAt: now,
+	}
+
+	if err := s.changeRepo.Create(ctx, req); err != nil {
+		return err
+	}
+
+	data := map[string]string{
+		"Token":     token,
+		"ExpiresAt": req.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+	if err := s.mailer.Send(ctx, newEmail, TemplateEmailChange, data); err != nil {
+		return fmt.Errorf("failed to send confirmation email: %w", err)
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange applies the pending change identified by token
+func (s *EmailChangeService) ConfirmEmailChange(ctx context.Context, token string) (*model.User, error) {
+	req, err := s.changeRepo.GetByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, ErrInvalidEmailChangeToken
+	}
+
+	if s.now().After(req.ExpiresAt) {
+		if err := s.changeRepo.DeleteByUserID(ctx, req.UserID); err != nil {
+			log.Printf("Failed to delete expired email change request for %s: %v", req.UserID, err)
+		}
+		return nil, ErrInvalidEmailChangeToken
+	}
+
+	// The address may have been claimed since the request was made
+	taken, err := s.userRepo.ExistsByEmail(ctx, req.NewEmail)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrEmailTaken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	oldEmail := user.Email
+
+	auditUser := &security.User{ID: user.ID.String(), Email: oldEmail}
+	if err := s.userRepo.UpdateEmail(ctx, user.ID, req.NewEmail); err != nil {
+		s.auditLogger.LogAccess(ctx, auditUser, "change_email", "user", user.ID.String(), false, err)
+		return nil, fmt.Errorf("failed to update email: %w", err)
+	}
+	s.auditLogger.LogAccess(ctx, auditUser, "change_email", "user", user.ID.String(), true, nil)
+
+	if err := s.changeRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		log.Printf("Failed to delete email change requests for %s: %v", user.ID, err)
+	}
+
+	user.Email = req.NewEmail
+	user.UpdatedAt = s.now()
+	return user, nil
+}
@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"backend/internal/graph/model"
+	"backend/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryUserRepo is an in-memory UserRepository for the email change flow
+type memoryUserRepo struct {
+	repository.UserRepository
+	users map[uuid.UUID]*model.User
+}
+
+func newMemoryUserRepo(users ...*model.User) *memoryUserRepo {
+	repo := &memoryUserRepo{users: make(map[uuid.UUID]*model.User)}
+	for _, user := range users {
+		copied := *user
+		repo.users[user.ID] = &copied
+	}
+	return repo
+}
+
+func (r *memoryUserRepo) Create(ctx context.Context, user *model.User) error {
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+func (r *memoryUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, fmt.Errorf("user not found")
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *memoryUserRepo) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, fmt.Errorf("user not found")
+}
+
+func (r *memoryUserRepo) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryUserRepo) UpdateEmail(ctx context.Context, id uuid.UUID, email string) error {
+	user, ok := r.users[id]
+	if !ok {
+		return fmt.Errorf("user not found")
+	}
+	user.Email = email
+	return nil
+}
+
+func (r *memoryUserRepo) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	user, ok := r.users[id]
+	if !ok {
+		return fmt.Errorf("user not found")
+	}
+	user.PasswordHash = passwordHash
+	return nil
+}
+
+func (r *memoryUserRepo) RecordLogin(ctx context.Context, id uuid.UUID, at time.Time, ip string) error {
+	user, ok := r.users[id]
+	if !ok {
+		return fmt.Errorf("user not found")
+	}
+	user.LastLoginAt = &at
+	user.LastLoginIP = &ip
+	return nil
+}
+
+func (r *memoryUserRepo) Update(ctx context.Context, user *model.User) error {
+	if _, ok := r.users[user.ID]; !ok {
+		return fmt.Errorf("user not found")
+	}
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+// memoryEmailChangeRepo is an in-memory EmailChangeRepository
+type memoryEmailChangeRepo struct {
+	requests map[string]*model.EmailChangeRequest
+}
+
+func newMemoryEmailChangeRepo() *memoryEmailChangeRepo {
+	return &memoryEmailChangeRepo{requests: make(map[string]*model.EmailChangeRequest)}
+}
+
+func (r *memoryEmailChangeRepo) Create(ctx context.Context, req *model.EmailChangeRequest) error {
+	r.requests[req.TokenHash] = req
+	return nil
+}
+
+func (r *memoryEmailChangeRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*model.EmailChangeRequest, error) {
+	req, ok := r.requests[tokenHash]
+	if !ok {
+		return nil, fmt.Errorf("email change request not found")
+	}
+	return req, nil
+}
+
+func (r *memoryEmailChangeRepo) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	for hash, req := range r.requests {
+		if req.UserID == userID {
+			delete(r.requests, hash)
+		}
+	}
+	return nil
+}
+
+// recordingMailer captures sent messages
+type recordingMailer struct {
+	to        []string
+	templates []string
+	data      []map[string]string
+}
+
+func (m *recordingMailer) Send(ctx context.Context, to, template string, data map[string]string) error {
+	m.to = append(m.to, to)
+	m.templates = append(m.templates, template)
+	m.data = append(m.data, data)
+	return nil
+}
+
+// lastToken returns the confirmation token from the most recent message
+func (m *recordingMailer) lastToken(t *testing.T) string {
+	require.NotEmpty(t, m.data)
+	token := m.data[len(m.data)-1]["Token"]
+	require.NotEmpty(t, token)
+	return token
+}
+
+func setupEmailChange(users ...*model.User) (*EmailChangeService, *memoryUserRepo, *memoryEmailChangeRepo, *recordingMailer) {
+	userRepo := newMemoryUserRepo(users...)
+	changeRepo := newMemoryEmailChangeRepo()
+	mailer := &recordingMailer{}
+	return NewEmailChangeService(userRepo, changeRepo, mailer, time.Hour), userRepo, changeRepo, mailer
+}
+
+func TestEmailChangeService_RequestRejectsTakenEmail(t *testing.T) {
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com"}
+	bob := &model.User{ID: uuid.New(), Email: "bob@example.com"}
+	service, _, changeRepo, mailer := setupEmailChange(alice, bob)
+
+	err := service.RequestEmailChange(context.Background(), alice, "  Bob@Example.com ")
+
+	assert.ErrorIs(t, err, ErrEmailTaken)
+	assert.Empty(t, changeRepo.requests)
+	assert.Empty(t, mailer.to)
+}
+
+func TestEmailChangeService_RequestRejectsCurrentEmail(t *testing.T) {
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com"}
+	service, _, _, mailer := setupEmailChange(alice)
+
+	err := service.RequestEmailChange(context.Background(), alice, "ALICE@example.com")
+
+	assert.ErrorIs(t, err, ErrEmailUnchanged)
+	assert.Empty(t, mailer.to)
+}
+
+func TestEmailChangeService_ConfirmAppliesChange(t *testing.T) {
+	ctx := context.Background()
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com"}
+	service, userRepo, changeRepo, mailer := setupEmailChange(alice)
+
+	require.NoError(t, service.RequestEmailChange(ctx, alice, "Alice.New@Example.com"))
+	assert.Equal(t, []string{"alice.new@example.com"}, mailer.to)
+	assert.Equal(t, []string{TemplateEmailChange}, mailer.templates)
+
+	// The old email stays authoritative until confirmation
+	assert.Equal(t, "alice@example.com", userRepo.users[alice.ID].Email)
+
+	user, err := service.ConfirmEmailChange(ctx, mailer.lastToken(t))
+
+	require.NoError(t, err)
+	assert.Equal(t, "alice.new@example.com", user.Email)
+	assert.Equal(t, "alice.new@example.com", userRepo.users[alice.ID].Email)
+	assert.Empty(t, changeRepo.requests)
+}
+
+func TestEmailChangeService_ConfirmRejectsUnknownToken(t *testing.T) {
+	service, _, _, _ := setupEmailChange()
+
+	_, err := service.ConfirmEmailChange(context.Background(), "not-a-token")
+
+	assert.ErrorIs(t, err, ErrInvalidEmailChangeToken)
+}
+
+func TestEmailChangeService_ConfirmRejectsExpiredToken(t *testing.T) {
+	ctx := context.Background()
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com"}
+	service, userRepo, changeRepo, mailer := setupEmailChange(alice)
+	service.tokenTTL = -time.Minute
+
+	require.NoError(t, service.RequestEmailChange(ctx, alice, "alice.new@example.com"))
+
+	_, err := service.ConfirmEmailChange(ctx, mailer.lastToken(t))
+
+	assert.ErrorIs(t, err, ErrInvalidEmailChangeToken)
+	assert.Equal(t, "alice@example.com", userRepo.users[alice.ID].Email)
+	assert.Empty(t, changeRepo.requests)
+}
+
+func TestEmailChangeService_ConfirmRejectsEmailClaimedSinceRequest(t *testing.T) {
+	ctx := context.Background()
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com"}
+	bob := &model.User{ID: uuid.New(), Email: "bob@example.com"}
+	service, userRepo, _, mailer := setupEmailChange(alice, bob)
+
+	require.NoError(t, service.RequestEmailChange(ctx, alice, "shared@example.com"))
+	require.NoError(t, userRepo.UpdateEmail(ctx, bob.ID, "shared@example.com"))
+
+	_, err := service.ConfirmEmailChange(ctx, mailer.lastToken(t))
+
+	assert.ErrorIs(t, err, ErrEmailTaken)
+	assert.Equal(t, "alice@example.com", userRepo.users[alice.ID].Email)
+}
+
+func TestEmailChangeService_NewRequestSupersedesOld(t *testing.T) {
+	ctx := context.Background()
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com"}
+	service, _, _, mailer := setupEmailChange(alice)
+
+	require.NoError(t, service.RequestEmailChange(ctx, alice, "first@example.com"))
+	firstToken := mailer.lastToken(t)
+	require.NoError(t, service.RequestEmailChange(ctx, alice, "second@example.com"))
+
+	_, err := service.ConfirmEmailChange(ctx, firstToken)
+	assert.ErrorIs(t, err, ErrInvalidEmailChangeToken)
+
+	user, err := service.ConfirmEmailChange(ctx, mailer.lastToken(t))
+	require.NoError(t, err)
+	assert.Equal(t, "second@example.com", user.Email)
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Email template names
+const (
+	TemplateEmailChange       = "email_change"
+	TemplateEmailVerification = "email_verification"
+	TemplatePasswordReset     = "password_reset"
+	TemplatePasswordSetup     = "password_setup"
+)
+
+// emailTemplate is a subject and plain-text body rendered with text/template
+type emailTemplate struct {
+	subject string
+	body    *template.Template
+}
+
+// emailTemplates holds every template a Mailer can send.
+// Missing data keys are errors so a token can never be silently dropped.
+var emailTemplates = map[string]emailTemplate{
+	TemplateEmailChange: newEmailTemplate("Confirm your new email address",
+		"Use this token to confirm your new email address: {{.Token}}\nIt expires at {{.ExpiresAt}}."),
+	TemplateEmailVerification: newEmailTemplate("Verify your email address",
+		"Use this token to verify your email address: {{.Token}}\nIt expires at {{.ExpiresAt}}."),
+	TemplatePasswordReset: newEmailTemplate("Reset your password",
+		"Use this token to reset your password: {{.Token}}\nIt expires at {{.ExpiresAt}}.\nIf you did not request a reset, you can ignore this email."),
+	TemplatePasswordSetup: newEmailTemplate("Set up your password",
+		"An account has been created for you. Use this token to choose your password: {{.Token}}\nIt expires at {{.ExpiresAt}}."),
+}
+
+func newEmailTemplate(subject, body string) emailTemplate {
+	return emailTemplate{
+		subject: subject,
+		body:    template.Must(template.New(subject).Option("missingkey=error").Parse(body)),
+	}
+}
+
+// RenderEmail renders the named template and returns its subject and body
+func RenderEmail(name string, data map[string]string) (string, string, error) {
+	tmpl, ok := emailTemplates[name]
+	if !ok {
+		return "", "", fmt.Errorf("unknown email template: %s", name)
+	}
+
+	var body strings.Builder
+	if err := tmpl.body.Execute(&body, data); err != nil {
+		return "", "", fmt.Errorf("failed to render email template %s: %w", name, err)
+	}
+
+	return tmpl.subject, body.String(), nil
+}
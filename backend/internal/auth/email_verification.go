@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"backend/internal/clock"
+	"backend/internal/graph/model"
+	"backend/internal/graph/validation"
+	"backend/internal/ids"
+	"backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrAlreadyVerified is returned when a verified user asks for another verification email
+	ErrAlreadyVerified = errors.New("email address is already verified")
+	// ErrResendLimited is returned when too many verification emails were requested
+	ErrResendLimited = errors.New("too many verification emails requested, try again later")
+	// ErrInvalidVerificationToken is returned for unknown, expired or used verification tokens
+	ErrInvalidVerificationToken = repository.ErrInvalidEmailVerificationToken
+)
+
+// resendWindow is the period over which verification resends are counted
+const resendWindow = time.Hour
+
+// RateCounter counts events per key within an expiring window.
+// cache.RedisCache satisfies it.
+type RateCounter interface {
+	IncrementWithTTL(ctx context.Context, key string, ttl time.Duration) (int64, error)
+}
+
+// memoryRateCounter is a RateCounter for servers without Redis. Its counts
+// are kept by this process only, so each server instance limits on its own.
+type memoryRateCounter struct {
+	mu      sync.Mutex
+	now     clock.Clock
+	windows map[string]rateWindow
+}
+
+// rateWindow is a count that restarts at expires
+type rateWindow struct {
+	count   int64
+	expires time.Time
+}
+
+// IncrementWithTTL counts an event for key, starting a new window of ttl
+// when the previous one has ended
+func (c *memoryRateCounter) IncrementWithTTL(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	// Drop ended windows so addresses that are never seen again don't pile up
+	for k, window := range c.windows {
+		if !now.Before(window.expires) {
+			delete(c.windows, k)
+		}
+	}
+
+	window, ok := c.windows[key]
+	if !ok {
+		window = rateWindow{expires: now.Add(ttl)}
+	}
+	window.count++
+	c.windows[key] = window
+	return window.count, nil
+}
+
+// EmailVerificationService issues email verification tokens
+type EmailVerificationService struct {
+	userRepo         repository.UserRepository
+	verificationRepo repository.EmailVerificationRepository
+	mailer           Mailer
+	counter          RateCounter
+	tokenTTL         time.Duration
+	resendLimit      int
+	now              clock.Clock
+	newID            ids.Generator
+}
+
+// NewEmailVerificationService creates a new email verification service.
+// resendLimit is the number of verification emails allowed per hour for a
+// user or address. Resends are counted in this process when counter is nil.
+func NewEmailVerificationService(userRepo repository.UserRepository, verificationRepo repository.EmailVerificationRepository, mailer Mailer, counter RateCounter, tokenTTL time.Duration, resendLimit int) *EmailVerificationService {
+	if counter == nil {
+		counter = &memoryRateCounter{now: clock.Now, windows: make(map[string]rateWindow)}
+	}
+	return &EmailVerificationService{
+		userRepo:         userRepo,
+		verificationRepo: verificationRepo,
+		mailer:           mailer,
+		counter:          counter,
+		tokenTTL:         tokenTTL,
+		resendLimit:      resendLimit,
+		now:              clock.Now,
+		newID:            ids.New,
+	}
+}
+
+// ResendForUser mails a fresh verification token to an authenticated user
+func (s *EmailVerificationService) ResendForUser(ctx context.Context, user *model.User) error {
+	verified, err := s.verificationRepo.IsVerified(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if verified {
+		return ErrAlreadyVerified
+	}
+
+	if err := s.checkResendLimit(ctx, "user:"+user.ID.String()); err != nil {
+		return err
+	}
+
+	return s.issueToken(ctx, user.ID, user.Email)
+}
+
+// ResendForEmail mails a fresh verification token to the account registered
+// with email. The outcome does not reveal whether such an account exists or
+// is already verified; only the rate limit is reported.
+func (s *EmailVerificationService) ResendForEmail(ctx context.Context, email string) error {
+	email = validation.NormalizeEmail(email)
+
+	// Count before the lookup so unknown addresses are limited the same way
+	if err := s.checkResendLimit(ctx, "email:"+email); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil || user == nil {
+		return nil
+	}
+
+	verified, err := s.verificationRepo.IsVerified(ctx, user.ID)
+	if err != nil {
+		log.Printf("Failed to check email verification for %s: %v", user.ID, err)
+		return nil
+	}
+	if verified {
+		return nil
+	}
+
+	if err := s.issueToken(ctx, user.ID, user.Email); err != nil {
+		log.Printf("Failed to resend verification email for %s: %v", user.ID, err)
+	}
+	return nil
+}
+
+// Verify marks the email address of the user holding token as verified and
+// returns the user. Each token works once.
+func (s *EmailVerificationService) Verify(ctx context.Context, token string) (*model.User, error) {
+	userID, err := s.verificationRepo.Verify(ctx, hashToken(token), s.now())
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidEmailVerificationToken) {
+			return nil, ErrInvalidVerificationToken
+		}
+		return nil, fmt.Errorf("failed to verify email address: %w", err)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	return user, nil
+}
+
+// checkResendLimit counts a resend against key and rejects it once the hourly
+// limit is exceeded. Counter failures reject the request rather than allow
+// unlimited mail.
+func (s *EmailVerificationService) checkResendLimit(ctx context.Context, key string) error {
+	count, err := s.counter.IncrementWithTTL(ctx, "email_verification_resend:"+key, resendWindow)
+	if err != nil {
+		return fmt.Errorf("failed to check resend limit: %w", err)
+	}
+	if count > int64(s.resendLimit) {
+		return ErrResendLimited
+	}
+	return nil
+}
+
+// issueToken replaces any outstanding token for the user and mails the new one
+func (s *EmailVerificationService) issueToken(ctx context.Context, userID uuid.UUID, email string) error {
+	token, err := generateToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	// Only the latest token for a user can be used
+	if err := s.verificationRepo.DeleteByUserID(ctx, userID); err != nil {
+		return err
+	}
+
+	now := s.now()
+	record := &model.EmailVerificationToken{
+		ID:        s.newID(),
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(s.tokenTTL),
+		CreatedAt: now,
+	}
+
+	if err := s.verificationRepo.Create(ctx, record); err != nil {
+		return err
+	}
+
+	data := map[string]string{
+		"Token":     token,
+		"ExpiresAt": record.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+	if err := s.mailer.Send(ctx, email, TemplateEmailVerification, data); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	return nil
+}
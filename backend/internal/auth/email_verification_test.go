@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/graph/model"
+	"backend/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCounter is an in-memory RateCounter that never expires keys
+type memoryCounter struct {
+	counts map[string]int64
+}
+
+func (c *memoryCounter) IncrementWithTTL(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	c.counts[key]++
+	return c.counts[key], nil
+}
+
+// memoryEmailVerificationRepo is an in-memory EmailVerificationRepository
+type memoryEmailVerificationRepo struct {
+	tokens   map[uuid.UUID][]*model.EmailVerificationToken
+	verified map[uuid.UUID]bool
+}
+
+func newMemoryEmailVerificationRepo() *memoryEmailVerificationRepo {
+	return &memoryEmailVerificationRepo{
+		tokens:   make(map[uuid.UUID][]*model.EmailVerificationToken),
+		verified: make(map[uuid.UUID]bool),
+	}
+}
+
+func (r *memoryEmailVerificationRepo) Create(ctx context.Context, token *model.EmailVerificationToken) error {
+	r.tokens[token.UserID] = append(r.tokens[token.UserID], token)
+	return nil
+}
+
+func (r *memoryEmailVerificationRepo) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	delete(r.tokens, userID)
+	return nil
+}
+
+func (r *memoryEmailVerificationRepo) IsVerified(ctx context.Context, userID uuid.UUID) (bool, error) {
+	return r.verified[userID], nil
+}
+
+func (r *memoryEmailVerificationRepo) Verify(ctx context.Context, tokenHash string, now time.Time) (uuid.UUID, error) {
+	for userID, tokens := range r.tokens {
+		for _, token := range tokens {
+			if token.TokenHash == tokenHash && now.Before(token.ExpiresAt) {
+				delete(r.tokens, userID)
+				r.verified[userID] = true
+				return userID, nil
+			}
+		}
+	}
+	return uuid.Nil, repository.ErrInvalidEmailVerificationToken
+}
+
+func setupEmailVerification(users ...*model.User) (*EmailVerificationService, *memoryEmailVerificationRepo, *recordingMailer) {
+	verificationRepo := newMemoryEmailVerificationRepo()
+	mailer := &recordingMailer{}
+	counter := &memoryCounter{counts: make(map[string]int64)}
+	service := NewEmailVerificationService(newMemoryUserRepo(users...), verificationRepo, mailer, counter, time.Hour, 2)
+	return service, verificationRepo, mailer
+}
+
+func TestEmailVerificationService_ResendForUserSendsToken(t *testing.T) {
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com"}
+	service, verificationRepo, mailer := setupEmailVerification(alice)
+
+	require.NoError(t, service.ResendForUser(context.Background(), alice))
+
+	assert.Equal(t, []string{"alice@example.com"}, mailer.to)
+	assert.Equal(t, []string{TemplateEmailVerification}, mailer.templates)
+	require.Len(t, verificationRepo.tokens[alice.ID], 1)
+	assert.Equal(t, hashToken(mailer.lastToken(t)), verificationRepo.tokens[alice.ID][0].TokenHash)
+}
+
+func TestEmailVerificationService_ResendReplacesOutstandingToken(t *testing.T) {
+	ctx := context.Background()
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com"}
+	service, verificationRepo, mailer := setupEmailVerification(alice)
+
+	require.NoError(t, service.ResendForUser(ctx, alice))
+	require.NoError(t, service.ResendForUser(ctx, alice))
+
+	require.Len(t, verificationRepo.tokens[alice.ID], 1)
+	assert.Equal(t, hashToken(mailer.lastToken(t)), verificationRepo.tokens[alice.ID][0].TokenHash)
+}
+
+func TestEmailVerificationService_ResendForUserRejectsVerified(t *testing.T) {
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com"}
+	service, verificationRepo, mailer := setupEmailVerification(alice)
+	verificationRepo.verified[alice.ID] = true
+
+	err := service.ResendForUser(context.Background(), alice)
+
+	assert.ErrorIs(t, err, ErrAlreadyVerified)
+	assert.Empty(t, mailer.to)
+}
+
+func TestEmailVerificationService_ResendForUserEnforcesLimit(t *testing.T) {
+	ctx := context.Background()
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com"}
+	service, _, mailer := setupEmailVerification(alice)
+
+	require.NoError(t, service.ResendForUser(ctx, alice))
+	require.NoError(t, service.ResendForUser(ctx, alice))
+
+	err := service.ResendForUser(ctx, alice)
+
+	assert.ErrorIs(t, err, ErrResendLimited)
+	assert.Len(t, mailer.to, 2)
+}
+
+func TestEmailVerificationService_ResendForEmailIsEnumerationSafe(t *testing.T) {
+	ctx := context.Background()
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com"}
+	service, verificationRepo, mailer := setupEmailVerification(alice)
+	verificationRepo.verified[alice.ID] = true
+
+	// Unknown and already-verified addresses look like a successful send
+	assert.NoError(t, service.ResendForEmail(ctx, "nobody@example.com"))
+	assert.NoError(t, service.ResendForEmail(ctx, "alice@example.com"))
+	assert.Empty(t, mailer.to)
+}
+
+func TestEmailVerificationService_ResendForEmailSendsToUnverified(t *testing.T) {
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com"}
+	service, _, mailer := setupEmailVerification(alice)
+
+	require.NoError(t, service.ResendForEmail(context.Background(), " Alice@Example.com "))
+
+	assert.Equal(t, []string{"alice@example.com"}, mailer.to)
+}
+
+func TestEmailVerificationService_ResendForEmailLimitsUnknownAddresses(t *testing.T) {
+	ctx := context.Background()
+	service, _, _ := setupEmailVerification()
+
+	require.NoError(t, service.ResendForEmail(ctx, "nobody@example.com"))
+	require.NoError(t, service.ResendForEmail(ctx, "NOBODY@example.com"))
+
+	err := service.ResendForEmail(ctx, "nobody@example.com")
+
+	assert.ErrorIs(t, err, ErrResendLimited)
+}
+
+func TestEmailVerificationService_VerifyConsumesToken(t *testing.T) {
+	ctx := context.Background()
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com"}
+	service, verificationRepo, mailer := setupEmailVerification(alice)
+	require.NoError(t, service.ResendForUser(ctx, alice))
+	token := mailer.lastToken(t)
+
+	user, err := service.Verify(ctx, token)
+
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, user.ID)
+	assert.True(t, verificationRepo.verified[alice.ID])
+	assert.Empty(t, verificationRepo.tokens[alice.ID])
+
+	_, err = service.Verify(ctx, token)
+	assert.ErrorIs(t, err, ErrInvalidVerificationToken)
+}
+
+func TestEmailVerificationService_CountsResendsWithoutCounter(t *testing.T) {
+	ctx := context.Background()
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com"}
+	service := NewEmailVerificationService(newMemoryUserRepo(alice), newMemoryEmailVerificationRepo(), &recordingMailer{}, nil, time.Hour, 1)
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	service.counter.(*memoryRateCounter).now = func() time.Time { return now }
+
+	require.NoError(t, service.ResendForUser(ctx, alice))
+	assert.ErrorIs(t, service.ResendForUser(ctx, alice), ErrResendLimited)
+
+	now = now.Add(resendWindow)
+	assert.NoError(t, service.ResendForUser(ctx, alice), "the limit did not reset after the window")
+}
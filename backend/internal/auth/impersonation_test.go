@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/graph/model"
+	"backend/internal/security"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateImpersonationToken_Claims(t *testing.T) {
+	jwtService := NewJWTService("test-secret-key", 24*time.Hour)
+	adminID := uuid.New()
+	user := &model.User{ID: uuid.New(), Email: "user@example.com", Name: "User"}
+
+	token, expiresAt, err := jwtService.GenerateImpersonationToken(user, adminID, 10*time.Minute)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), expiresAt, 2*time.Second)
+
+	claims, err := jwtService.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+	assert.Equal(t, user.ID.String(), claims.Subject)
+	require.NotNil(t, claims.ImpersonatorID)
+	assert.Equal(t, adminID, *claims.ImpersonatorID)
+
+	// The admin's id travels in its own claim next to the user's
+	payload, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[1])
+	require.NoError(t, err)
+	var raw map[string]interface{}
+	require.NoError(t, json.Unmarshal(payload, &raw))
+	assert.Equal(t, user.ID.String(), raw["user_id"])
+	assert.Equal(t, adminID.String(), raw["impersonator_id"])
+
+	// Ordinary tokens carry no impersonator
+	ordinary, _, err := jwtService.GenerateToken(user)
+	require.NoError(t, err)
+	payload, err = base64.RawURLEncoding.DecodeString(strings.Split(ordinary, ".")[1])
+	require.NoError(t, err)
+	assert.NotContains(t, string(payload), "impersonator_id")
+}
+
+func TestGenerateImpersonationToken_TimeLimit(t *testing.T) {
+	user := &model.User{ID: uuid.New()}
+
+	_, expiresAt, err := NewJWTService("test-secret-key", 24*time.Hour).GenerateImpersonationToken(user, uuid.New(), 12*time.Hour)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(MaxImpersonationDuration), expiresAt, 2*time.Second)
+
+	// Never outlives an ordinary token either
+	_, expiresAt, err = NewJWTService("test-secret-key", 5*time.Minute).GenerateImpersonationToken(user, uuid.New(), 30*time.Minute)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), expiresAt, 2*time.Second)
+
+	_, _, err = NewJWTService("test-secret-key", time.Hour).GenerateImpersonationToken(user, user.ID, time.Minute)
+	assert.ErrorContains(t, err, "cannot impersonate yourself")
+}
+
+func TestRefreshToken_RejectsImpersonationTokens(t *testing.T) {
+	jwtService := NewJWTService("test-secret-key", time.Hour)
+	user := &model.User{ID: uuid.New()}
+	token, _, err := jwtService.GenerateImpersonationToken(user, uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	_, _, err = jwtService.RefreshToken(token, user)
+
+	assert.ErrorContains(t, err, "cannot be refreshed")
+}
+
+func TestAuthMiddleware_ImpersonationContext(t *testing.T) {
+	jwtService := NewJWTService("test-secret-key", time.Hour)
+	adminID := uuid.New()
+	user := &model.User{ID: uuid.New(), Email: "user@example.com"}
+	token, _, err := jwtService.GenerateImpersonationToken(user, adminID, time.Minute)
+	require.NoError(t, err)
+	middleware := NewAuthMiddleware(jwtService, newMemoryUserRepo(user))
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/whoami", middleware.RequiredAuth(), func(c *gin.Context) {
+		ctx := c.Request.Context()
+		current, _ := GetUserFromContext(ctx)
+		impersonator, _ := GetImpersonatorFromContext(ctx)
+		c.JSON(http.StatusOK, gin.H{
+			"email":        current.Email,
+			"impersonator": impersonator.String(),
+			"audited":      security.ImpersonatorFromContext(ctx),
+		})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "user@example.com", body["email"])
+	assert.Equal(t, adminID.String(), body["impersonator"])
+	assert.Equal(t, adminID.String(), body["audited"])
+	assert.Contains(t, logged.String(), "IMPERSONATION: admin="+adminID.String()+" user="+user.ID.String())
+}
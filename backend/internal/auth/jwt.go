@@ -1,7 +1,13 @@
 package auth
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"log"
+	"sync"
 	"time"
 
 	"backend/internal/graph/model"
@@ -14,23 +20,323 @@ type JWTClaims struct {
 	UserID uuid.UUID `json:"user_id"`
 	Email  string    `json:"email"`
 	Name   string    `json:"name"`
+	// ImpersonatorID is the admin acting as UserID; nil for ordinary tokens
+	ImpersonatorID *uuid.UUID `json:"impersonator_id,omitempty"`
+	// TokenType is TokenTypeRefresh for refresh tokens and empty for access tokens
+	TokenType string `json:"typ,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// JWTService handles JWT token operations
+// DefaultIssuer is the issuer and audience used when none is configured
+const DefaultIssuer = "graphql-typescript-go"
+
+// TokenTypeRefresh marks refresh tokens, which only obtain new tokens and
+// never authenticate requests
+const TokenTypeRefresh = "refresh"
+
+// DefaultRefreshTokenDuration is how long refresh tokens last when none is configured
+const DefaultRefreshTokenDuration = 30 * 24 * time.Hour
+
+// keyReloadInterval is the least time between two loads of the stored keys
+// prompted by tokens naming an unknown key
+const keyReloadInterval = 10 * time.Second
+
+// JWTKeyStore keeps rotated signing keys so every server instance, and the
+// next start, signs and verifies with them. repository.JWTKeyRepository
+// satisfies it.
+type JWTKeyStore interface {
+	Create(ctx context.Context, key *model.JWTSigningKey) error
+	ListSince(ctx context.Context, since time.Time) ([]*model.JWTSigningKey, error)
+}
+
+// jwtKey is a signing secret, when it became current and when it stopped
+// being the current key. The configured secret has no creation time.
+type jwtKey struct {
+	secret    []byte
+	createdAt time.Time
+	retiredAt time.Time
+}
+
+// JWTService handles JWT token operations. Tokens carry the id of the key
+// that signed them in their kid header, so a new key can be made current
+// while tokens signed with retired keys stay valid until they expire.
 type JWTService struct {
-	secretKey     []byte
-	tokenDuration time.Duration
+	mu           sync.RWMutex
+	keys         map[string]*jwtKey
+	currentKeyID string
+	// legacyKeyID verifies tokens issued before tokens carried a kid
+	legacyKeyID     string
+	tokenDuration   time.Duration
+	refreshDuration time.Duration
+	issuer          string
+	audiences       []string
+	// keyStore holds rotated keys, encrypted by keySealer; without one they
+	// live in this process only
+	keyStore         JWTKeyStore
+	keySealer        *jwtKeySealer
+	keyEncryptionKey []byte
+	lastReloadAt     time.Time
 }
 
-// NewJWTService creates a new JWT service
+// NewJWTService creates a new JWT service using the default issuer and audience
 func NewJWTService(secretKey string, tokenDuration time.Duration) *JWTService {
+	return NewJWTServiceWithIssuer(secretKey, tokenDuration, DefaultIssuer, []string{DefaultIssuer})
+}
+
+// NewJWTServiceWithIssuer creates a new JWT service that issues tokens for the
+// first audience and accepts tokens for any of the given audiences
+func NewJWTServiceWithIssuer(secretKey string, tokenDuration time.Duration, issuer string, audiences []string) *JWTService {
+	keyID := secretKeyID([]byte(secretKey))
 	return &JWTService{
-		secretKey:     []byte(secretKey),
-		tokenDuration: tokenDuration,
+		keys:            map[string]*jwtKey{keyID: {secret: []byte(secretKey)}},
+		currentKeyID:    keyID,
+		legacyKeyID:     keyID,
+		tokenDuration:   tokenDuration,
+		refreshDuration: DefaultRefreshTokenDuration,
+		issuer:          issuer,
+		audiences:       audiences,
+	}
+}
+
+// SetRefreshTokenDuration sets how long refresh tokens last; a duration
+// that is not positive keeps the current one
+func (j *JWTService) SetRefreshTokenDuration(duration time.Duration) {
+	if duration > 0 {
+		j.refreshDuration = duration
 	}
 }
 
+// secretKeyID derives a stable key id from a secret, so every instance
+// configured with the same secret agrees on its id
+func secretKeyID(secret []byte) string {
+	sum := sha256.Sum256(secret)
+	return hex.EncodeToString(sum[:8])
+}
+
+// CurrentKeyID returns the id of the key new tokens are signed with
+func (j *JWTService) CurrentKeyID() string {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.currentKeyID
+}
+
+// AddKey registers a signing key under keyID and makes it current. The
+// previous key keeps validating tokens until they expire; keys retired for
+// longer than the token duration are dropped.
+func (j *JWTService) AddKey(keyID string, secret []byte) error {
+	if keyID == "" {
+		return fmt.Errorf("key id is required")
+	}
+	if len(secret) == 0 {
+		return fmt.Errorf("key secret is required")
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, exists := j.keys[keyID]; exists {
+		return fmt.Errorf("key %q already exists", keyID)
+	}
+
+	j.addKeyLocked(keyID, secret, time.Now())
+	return nil
+}
+
+// addKeyLocked makes secret the current key as of createdAt and drops the
+// keys no valid token can name. A key older than the current one, stored by
+// another instance in the meantime, is kept only for verification.
+func (j *JWTService) addKeyLocked(keyID string, secret []byte, createdAt time.Time) {
+	current := j.keys[j.currentKeyID]
+	if createdAt.Before(current.createdAt) {
+		j.keys[keyID] = &jwtKey{secret: secret, createdAt: createdAt, retiredAt: current.createdAt}
+	} else {
+		current.retiredAt = createdAt
+		j.keys[keyID] = &jwtKey{secret: secret, createdAt: createdAt}
+		j.currentKeyID = keyID
+	}
+
+	// Keep retired keys as long as any token they signed may be valid
+	now := time.Now()
+	for id, key := range j.keys {
+		if !key.retiredAt.IsZero() && now.Sub(key.retiredAt) > j.keyLifetime() {
+			delete(j.keys, id)
+		}
+	}
+}
+
+// keyLifetime is how long tokens signed with a key may stay valid after it
+// is retired
+func (j *JWTService) keyLifetime() time.Duration {
+	return max(j.tokenDuration, j.refreshDuration)
+}
+
+// RotateKey generates a random signing key, makes it current and returns its
+// id. With a key store the key is stored first, so it is not used unless
+// the other instances and later starts can verify it too.
+func (j *JWTService) RotateKey(ctx context.Context) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate key id: %w", err)
+	}
+	keyID := hex.EncodeToString(id)
+
+	j.mu.RLock()
+	store := j.keyStore
+	j.mu.RUnlock()
+	if store == nil {
+		if err := j.AddKey(keyID, secret); err != nil {
+			return "", err
+		}
+		return keyID, nil
+	}
+
+	j.mu.RLock()
+	sealed, err := j.keySealer.seal(keyID, secret)
+	j.mu.RUnlock()
+	if err != nil {
+		return "", err
+	}
+	key := &model.JWTSigningKey{ID: keyID, Secret: sealed, CreatedAt: time.Now()}
+	if err := store.Create(ctx, key); err != nil {
+		return "", fmt.Errorf("failed to store key: %w", err)
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, exists := j.keys[keyID]; !exists {
+		j.addKeyLocked(keyID, secret, key.CreatedAt)
+	}
+	return keyID, nil
+}
+
+// SetKeyEncryptionKey sets the secret rotated keys are encrypted with in the
+// key store, before EnableKeyStore. Without one, or with an empty one, they
+// are encrypted with the configured secret.
+func (j *JWTService) SetKeyEncryptionKey(secret string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.keyEncryptionKey = []byte(secret)
+}
+
+// EnableKeyStore keeps rotated keys in store and loads the ones stored so
+// far, so the newest stored key signs. Tokens naming a key this instance
+// does not know yet, rotated by another instance, make it load them again.
+// Stored secrets are encrypted, and a key that cannot be decrypted fails the
+// load.
+func (j *JWTService) EnableKeyStore(ctx context.Context, store JWTKeyStore) error {
+	j.mu.Lock()
+	encryptionKey := j.keyEncryptionKey
+	if len(encryptionKey) == 0 {
+		encryptionKey = j.keys[j.legacyKeyID].secret
+	}
+	sealer, err := newJWTKeySealer(encryptionKey)
+	if err != nil {
+		j.mu.Unlock()
+		return err
+	}
+	j.keySealer = sealer
+	j.keyStore = store
+	j.mu.Unlock()
+	return j.loadKeys(ctx)
+}
+
+// loadKeys adds the stored keys that may still verify a token
+func (j *JWTService) loadKeys(ctx context.Context) error {
+	j.mu.RLock()
+	store, sealer := j.keyStore, j.keySealer
+	since := time.Now().Add(-j.keyLifetime())
+	j.mu.RUnlock()
+
+	stored, err := store.ListSince(ctx, since)
+	if err != nil {
+		return fmt.Errorf("failed to load JWT signing keys: %w", err)
+	}
+
+	secrets := make([][]byte, len(stored))
+	for i, key := range stored {
+		if secrets[i], err = sealer.open(key.ID, key.Secret); err != nil {
+			return fmt.Errorf("failed to load JWT signing keys: %w", err)
+		}
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for i, key := range stored {
+		if _, exists := j.keys[key.ID]; !exists {
+			j.addKeyLocked(key.ID, secrets[i], key.CreatedAt)
+		}
+	}
+	return nil
+}
+
+// reloadKeysFor loads the stored keys again when keyID is unknown, at most
+// once per keyReloadInterval so made-up key ids cannot flood the store. It
+// reports whether keyID is known afterwards.
+func (j *JWTService) reloadKeysFor(keyID string) bool {
+	j.mu.Lock()
+	if j.keyStore == nil || time.Since(j.lastReloadAt) < keyReloadInterval {
+		j.mu.Unlock()
+		return false
+	}
+	j.lastReloadAt = time.Now()
+	j.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := j.loadKeys(ctx); err != nil {
+		log.Printf("Failed to reload JWT signing keys: %v", err)
+		return false
+	}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	_, ok := j.keys[keyID]
+	return ok
+}
+
+// signingKey returns the current key id and secret
+func (j *JWTService) signingKey() (string, []byte) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.currentKeyID, j.keys[j.currentKeyID].secret
+}
+
+// verificationKey returns the secret for the key a token names
+func (j *JWTService) verificationKey(token *jwt.Token) (interface{}, error) {
+	keyID, _ := token.Header["kid"].(string)
+	if secret, ok := j.keySecret(keyID); ok {
+		return secret, nil
+	}
+
+	// The key may have been rotated by another instance
+	if j.reloadKeysFor(keyID) {
+		if secret, ok := j.keySecret(keyID); ok {
+			return secret, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key id %q", keyID)
+}
+
+// keySecret returns the secret of a known key; an empty id names the key of
+// tokens issued before tokens carried a kid
+func (j *JWTService) keySecret(keyID string) ([]byte, bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	if keyID == "" {
+		keyID = j.legacyKeyID
+	}
+	key, ok := j.keys[keyID]
+	if !ok {
+		return nil, false
+	}
+	return key.secret, true
+}
+
 // GenerateToken generates a new JWT token for a user
 func (j *JWTService) GenerateToken(user *model.User) (string, time.Time, error) {
 	expirationTime := time.Now().Add(j.tokenDuration)
@@ -43,28 +349,101 @@ func (j *JWTService) GenerateToken(user *model.User) (string, time.Time, error)
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "graphql-typescript-go",
+			Issuer:    j.issuer,
 			Subject:   user.ID.String(),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(j.secretKey)
+	tokenString, err := j.sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expirationTime, nil
+}
+
+// GenerateRefreshToken generates a refresh token for a user. It lasts longer
+// than access tokens and is only accepted by ValidateRefreshToken.
+func (j *JWTService) GenerateRefreshToken(user *model.User) (string, time.Time, error) {
+	return j.GenerateRefreshTokenWithID(user, uuid.NewString())
+}
+
+// GenerateRefreshTokenWithID generates a refresh token for a user carrying
+// tokenID as its jti claim, by which the issued token is stored and revoked
+func (j *JWTService) GenerateRefreshTokenWithID(user *model.User, tokenID string) (string, time.Time, error) {
+	now := time.Now()
+	expirationTime := now.Add(j.refreshDuration)
+
+	claims := &JWTClaims{
+		UserID:    user.ID,
+		TokenType: TokenTypeRefresh,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    j.issuer,
+			Subject:   user.ID.String(),
+		},
+	}
+
+	tokenString, err := j.sign(claims)
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+		return "", time.Time{}, err
 	}
 
 	return tokenString, expirationTime, nil
 }
 
-// ValidateToken validates a JWT token and returns the claims
+// sign addresses claims to the issuing audience and signs them with the current key
+func (j *JWTService) sign(claims *JWTClaims) (string, error) {
+	if len(j.audiences) > 0 {
+		claims.Audience = jwt.ClaimStrings{j.audiences[0]}
+	}
+
+	keyID, secret := j.signingKey()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = keyID
+	tokenString, err := token.SignedString(secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return tokenString, nil
+}
+
+// ValidateToken validates an access token and returns the claims. Refresh
+// tokens are rejected.
 func (j *JWTService) ValidateToken(tokenString string) (*JWTClaims, error) {
+	claims, err := j.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != "" {
+		return nil, fmt.Errorf("invalid token type")
+	}
+	return claims, nil
+}
+
+// ValidateRefreshToken validates a refresh token and returns the claims
+func (j *JWTService) ValidateRefreshToken(tokenString string) (*JWTClaims, error) {
+	claims, err := j.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != TokenTypeRefresh {
+		return nil, fmt.Errorf("invalid token type")
+	}
+	return claims, nil
+}
+
+// parseToken verifies a token of any type and returns its claims
+func (j *JWTService) parseToken(tokenString string) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		// Validate the signing method
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return j.secretKey, nil
+		return j.verificationKey(token)
 	})
 
 	if err != nil {
@@ -81,9 +460,30 @@ func (j *JWTService) ValidateToken(tokenString string) (*JWTClaims, error) {
 		return nil, fmt.Errorf("token is expired")
 	}
 
+	// Reject tokens minted for another service sharing the secret
+	if claims.Issuer != j.issuer {
+		return nil, fmt.Errorf("invalid token issuer")
+	}
+
+	if len(j.audiences) > 0 && !j.acceptsAudience(claims.Audience) {
+		return nil, fmt.Errorf("invalid token audience")
+	}
+
 	return claims, nil
 }
 
+// acceptsAudience reports whether any of the token audiences is accepted
+func (j *JWTService) acceptsAudience(audience jwt.ClaimStrings) bool {
+	for _, aud := range audience {
+		for _, accepted := range j.audiences {
+			if aud == accepted {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // RefreshToken generates a new token if the current one is valid but close to expiry
 func (j *JWTService) RefreshToken(tokenString string, user *model.User) (string, time.Time, error) {
 	claims, err := j.ValidateToken(tokenString)
@@ -96,6 +496,11 @@ func (j *JWTService) RefreshToken(tokenString string, user *model.User) (string,
 		return "", time.Time{}, fmt.Errorf("token does not belong to user")
 	}
 
+	// Impersonation ends when its token expires
+	if claims.ImpersonatorID != nil {
+		return "", time.Time{}, fmt.Errorf("impersonation tokens cannot be refreshed")
+	}
+
 	// Generate new token
 	return j.GenerateToken(user)
 }
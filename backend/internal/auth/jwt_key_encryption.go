@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+)
+
+// jwtKeySealer encrypts rotated signing secrets before they are stored, so
+// reading the jwt_signing_keys table or a backup of it is not enough to sign
+// tokens. Each secret is bound to its key id, so stored secrets cannot be
+// swapped between keys.
+type jwtKeySealer struct {
+	aead cipher.AEAD
+}
+
+// newJWTKeySealer derives an AES-256-GCM key from secret
+func newJWTKeySealer(secret []byte) (*jwtKeySealer, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("key encryption key is required")
+	}
+
+	// Derive a dedicated key, so the encryption key may equal JWT_SECRET
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("jwt signing key encryption"))
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key cipher: %w", err)
+	}
+
+	return &jwtKeySealer{aead: aead}, nil
+}
+
+// seal encrypts the secret of keyID, returning the nonce followed by the ciphertext
+func (s *jwtKeySealer) seal(keyID string, secret []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return s.aead.Seal(nonce, nonce, secret, []byte(keyID)), nil
+}
+
+// open decrypts a secret sealed for keyID
+func (s *jwtKeySealer) open(keyID string, sealed []byte) ([]byte, error) {
+	if len(sealed) < s.aead.NonceSize() {
+		return nil, fmt.Errorf("sealed secret of key %q is too short", keyID)
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	secret, err := s.aead.Open(nil, nonce, ciphertext, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt key %q; is the key encryption key the one it was stored with?", keyID)
+	}
+	return secret, nil
+}
@@ -1,12 +1,16 @@
 package auth
 
 import (
+	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"backend/internal/graph/model"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestJWTService_GenerateToken(t *testing.T) {
@@ -99,6 +103,267 @@ func TestJWTService_RefreshToken(t *testing.T) {
 	assert.True(t, expiresAt.After(time.Now()))
 }
 
+func TestJWTService_IssuerAndAudienceRoundTrip(t *testing.T) {
+	jwtService := NewJWTServiceWithIssuer("test-secret-key", time.Hour, "auth.example.com", []string{"api.example.com", "admin.example.com"})
+
+	user := &model.User{
+		ID:    uuid.New(),
+		Email: "test@example.com",
+		Name:  "Test User",
+	}
+
+	token, _, err := jwtService.GenerateToken(user)
+	assert.NoError(t, err)
+
+	claims, err := jwtService.ValidateToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "auth.example.com", claims.Issuer)
+	assert.Equal(t, jwt.ClaimStrings{"api.example.com"}, claims.Audience)
+}
+
+func TestJWTService_ValidateToken_AcceptsAnyConfiguredAudience(t *testing.T) {
+	issuer := NewJWTServiceWithIssuer("test-secret-key", time.Hour, "auth.example.com", []string{"admin.example.com"})
+	validator := NewJWTServiceWithIssuer("test-secret-key", time.Hour, "auth.example.com", []string{"api.example.com", "admin.example.com"})
+
+	token, _, err := issuer.GenerateToken(&model.User{ID: uuid.New()})
+	assert.NoError(t, err)
+
+	_, err = validator.ValidateToken(token)
+	assert.NoError(t, err)
+}
+
+func TestJWTService_ValidateToken_WrongIssuerOrAudience(t *testing.T) {
+	validator := NewJWTServiceWithIssuer("test-secret-key", time.Hour, "auth.example.com", []string{"api.example.com"})
+
+	tests := []struct {
+		name     string
+		issuer   *JWTService
+		errorMsg string
+	}{
+		{
+			name:     "Wrong issuer",
+			issuer:   NewJWTServiceWithIssuer("test-secret-key", time.Hour, "other.example.com", []string{"api.example.com"}),
+			errorMsg: "invalid token issuer",
+		},
+		{
+			name:     "Wrong audience",
+			issuer:   NewJWTServiceWithIssuer("test-secret-key", time.Hour, "auth.example.com", []string{"other.example.com"}),
+			errorMsg: "invalid token audience",
+		},
+		{
+			name:     "Missing audience",
+			issuer:   NewJWTServiceWithIssuer("test-secret-key", time.Hour, "auth.example.com", nil),
+			errorMsg: "invalid token audience",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _, err := tt.issuer.GenerateToken(&model.User{ID: uuid.New()})
+			assert.NoError(t, err)
+
+			_, err = validator.ValidateToken(token)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+// tokenKeyID returns the kid header of a signed token
+func tokenKeyID(t *testing.T, token string) string {
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &JWTClaims{})
+	assert.NoError(t, err)
+	keyID, _ := parsed.Header["kid"].(string)
+	return keyID
+}
+
+func TestJWTService_GenerateToken_SignsWithCurrentKey(t *testing.T) {
+	jwtService := NewJWTService("test-secret-key", time.Hour)
+
+	token, _, err := jwtService.GenerateToken(&model.User{ID: uuid.New()})
+	assert.NoError(t, err)
+	assert.Equal(t, jwtService.CurrentKeyID(), tokenKeyID(t, token))
+
+	assert.NoError(t, jwtService.AddKey("key-2", []byte("second-secret")))
+	token, _, err = jwtService.GenerateToken(&model.User{ID: uuid.New()})
+	assert.NoError(t, err)
+	assert.Equal(t, "key-2", tokenKeyID(t, token))
+
+	_, err = jwtService.ValidateToken(token)
+	assert.NoError(t, err)
+}
+
+func TestJWTService_ValidateToken_RetiredKey(t *testing.T) {
+	jwtService := NewJWTService("test-secret-key", time.Hour)
+	user := &model.User{ID: uuid.New()}
+
+	oldToken, _, err := jwtService.GenerateToken(user)
+	assert.NoError(t, err)
+
+	keyID, err := jwtService.RotateKey(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, keyID, jwtService.CurrentKeyID())
+
+	claims, err := jwtService.ValidateToken(oldToken)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+}
+
+func TestJWTService_ValidateToken_UnknownKeyID(t *testing.T) {
+	jwtService := NewJWTService("test-secret-key", time.Hour)
+
+	claims := &JWTClaims{
+		UserID: uuid.New(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			Issuer:    DefaultIssuer,
+			Audience:  jwt.ClaimStrings{DefaultIssuer},
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = "unknown"
+	signed, err := token.SignedString([]byte("test-secret-key"))
+	assert.NoError(t, err)
+
+	_, err = jwtService.ValidateToken(signed)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown key id")
+}
+
+func TestJWTService_ValidateToken_LegacyTokenWithoutKeyID(t *testing.T) {
+	jwtService := NewJWTService("test-secret-key", time.Hour)
+
+	claims := &JWTClaims{
+		UserID: uuid.New(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			Issuer:    DefaultIssuer,
+			Audience:  jwt.ClaimStrings{DefaultIssuer},
+		},
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret-key"))
+	assert.NoError(t, err)
+
+	_, err = jwtService.RotateKey(context.Background())
+	assert.NoError(t, err)
+
+	_, err = jwtService.ValidateToken(signed)
+	assert.NoError(t, err)
+}
+
+// memoryJWTKeyStore is a JWTKeyStore shared by the services in a test, like
+// the table shared by server instances
+type memoryJWTKeyStore struct {
+	keys  []*model.JWTSigningKey
+	lists int
+	err   error
+}
+
+func (s *memoryJWTKeyStore) Create(ctx context.Context, key *model.JWTSigningKey) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.keys = append(s.keys, key)
+	return nil
+}
+
+func (s *memoryJWTKeyStore) ListSince(ctx context.Context, since time.Time) ([]*model.JWTSigningKey, error) {
+	s.lists++
+	return s.keys, nil
+}
+
+func TestJWTService_RotatedKeysSurviveRestart(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryJWTKeyStore{}
+	jwtService := NewJWTService("test-secret-key", time.Hour)
+	require.NoError(t, jwtService.EnableKeyStore(ctx, store))
+
+	keyID, err := jwtService.RotateKey(ctx)
+	require.NoError(t, err)
+	require.Len(t, store.keys, 1)
+	token, _, err := jwtService.GenerateToken(&model.User{ID: uuid.New()})
+	require.NoError(t, err)
+
+	// A restarted instance signs with the rotated key and accepts its tokens
+	restarted := NewJWTService("test-secret-key", time.Hour)
+	require.NoError(t, restarted.EnableKeyStore(ctx, store))
+	assert.Equal(t, keyID, restarted.CurrentKeyID())
+	_, err = restarted.ValidateToken(token)
+	assert.NoError(t, err)
+}
+
+func TestJWTService_LoadsKeysRotatedByAnotherInstance(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryJWTKeyStore{}
+	first := NewJWTService("test-secret-key", time.Hour)
+	second := NewJWTService("test-secret-key", time.Hour)
+	require.NoError(t, first.EnableKeyStore(ctx, store))
+	require.NoError(t, second.EnableKeyStore(ctx, store))
+
+	keyID, err := first.RotateKey(ctx)
+	require.NoError(t, err)
+	token, _, err := first.GenerateToken(&model.User{ID: uuid.New()})
+	require.NoError(t, err)
+
+	_, err = second.ValidateToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, keyID, second.CurrentKeyID())
+}
+
+func TestJWTService_UnknownKeyIDsReloadAtMostOncePerInterval(t *testing.T) {
+	store := &memoryJWTKeyStore{}
+	jwtService := NewJWTService("test-secret-key", time.Hour)
+	require.NoError(t, jwtService.EnableKeyStore(context.Background(), store))
+
+	assert.False(t, jwtService.reloadKeysFor("made-up-1"))
+	assert.False(t, jwtService.reloadKeysFor("made-up-2"))
+
+	assert.Equal(t, 2, store.lists, "the second unknown key id loaded the keys again")
+}
+
+func TestJWTService_RotateKeyKeepsCurrentKeyWhenStoreFails(t *testing.T) {
+	jwtService := NewJWTService("test-secret-key", time.Hour)
+	require.NoError(t, jwtService.EnableKeyStore(context.Background(), &memoryJWTKeyStore{err: fmt.Errorf("database unavailable")}))
+	current := jwtService.CurrentKeyID()
+
+	_, err := jwtService.RotateKey(context.Background())
+
+	assert.Error(t, err)
+	assert.Equal(t, current, jwtService.CurrentKeyID())
+}
+
+func TestJWTService_StoresRotatedKeysEncrypted(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryJWTKeyStore{}
+	jwtService := NewJWTService("test-secret-key", time.Hour)
+	jwtService.SetKeyEncryptionKey("test-key-encryption-key")
+	require.NoError(t, jwtService.EnableKeyStore(ctx, store))
+
+	keyID, err := jwtService.RotateKey(ctx)
+	require.NoError(t, err)
+	require.Len(t, store.keys, 1)
+	secret := jwtService.keys[keyID].secret
+	assert.NotContains(t, string(store.keys[0].Secret), string(secret), "the stored secret is readable")
+
+	// Instances with the same encryption key read it back
+	restarted := NewJWTService("test-secret-key", time.Hour)
+	restarted.SetKeyEncryptionKey("test-key-encryption-key")
+	require.NoError(t, restarted.EnableKeyStore(ctx, store))
+	assert.Equal(t, secret, restarted.keys[keyID].secret)
+
+	// Any other key, including the JWT secret fallback, fails the load
+	other := NewJWTService("test-secret-key", time.Hour)
+	assert.Error(t, other.EnableKeyStore(ctx, store))
+}
+
+func TestJWTService_AddKey_RejectsDuplicateID(t *testing.T) {
+	jwtService := NewJWTService("test-secret-key", time.Hour)
+
+	err := jwtService.AddKey(jwtService.CurrentKeyID(), []byte("another-secret"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+}
+
 func TestExtractTokenFromHeader(t *testing.T) {
 	tests := []struct {
 		name        string
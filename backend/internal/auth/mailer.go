@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/smtp"
+	"strings"
+)
+
+// Mailer delivers transactional email such as confirmation links.
+// Implementations render the named template with data before sending.
+type Mailer interface {
+	Send(ctx context.Context, to, template string, data map[string]string) error
+}
+
+// NewMailer returns an SMTP mailer when SMTP is configured and a log-only mailer otherwise
+func NewMailer(config *Config) Mailer {
+	if config.SMTPHost == "" {
+		return NewLogMailer()
+	}
+	return NewSMTPMailer(SMTPConfig{
+		Host:     config.SMTPHost,
+		Port:     config.SMTPPort,
+		Username: config.SMTPUsername,
+		Password: config.SMTPPassword,
+		From:     config.SMTPFrom,
+	})
+}
+
+// LogMailer is a Mailer that writes messages to the log instead of sending them.
+// Tokens end up in the log, so it is only meant for development.
+type LogMailer struct{}
+
+// NewLogMailer creates a new log-only mailer
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// Send renders the message and logs it
+func (m *LogMailer) Send(ctx context.Context, to, template string, data map[string]string) error {
+	subject, body, err := RenderEmail(template, data)
+	if err != nil {
+		return err
+	}
+	log.Printf("MAIL: to=%s subject=%q body=%q", to, subject, body)
+	return nil
+}
+
+// SMTPConfig holds SMTP connection settings
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPMailer is a Mailer that delivers messages through an SMTP server
+type SMTPMailer struct {
+	config   SMTPConfig
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPMailer creates a new SMTP mailer
+func NewSMTPMailer(config SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{
+		config:   config,
+		sendMail: smtp.SendMail,
+	}
+}
+
+// Send renders the message and delivers it
+func (m *SMTPMailer) Send(ctx context.Context, to, template string, data map[string]string) error {
+	subject, body, err := RenderEmail(template, data)
+	if err != nil {
+		return err
+	}
+
+	// Reject header injection through the recipient
+	if strings.ContainsAny(to, "\r\n") {
+		return fmt.Errorf("invalid recipient address")
+	}
+
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+
+	msg := buildMessage(m.config.From, to, subject, body)
+	addr := fmt.Sprintf("%s:%d", m.config.Host, m.config.Port)
+	if err := m.sendMail(addr, auth, m.config.From, []string{to}, msg); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// buildMessage formats a plain-text RFC 5322 message
+func buildMessage(from, to, subject, body string) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + to + "\r\n")
+	b.WriteString("Subject: " + subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(b.String())
+}
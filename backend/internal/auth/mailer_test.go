@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderEmail_IncludesToken(t *testing.T) {
+	for _, name := range []string{TemplateEmailChange, TemplateEmailVerification, TemplatePasswordReset, TemplatePasswordSetup} {
+		subject, body, err := RenderEmail(name, map[string]string{
+			"Token":     "tok-123",
+			"ExpiresAt": "2030-01-01T00:00:00Z",
+		})
+
+		require.NoError(t, err, name)
+		assert.NotEmpty(t, subject, name)
+		assert.Contains(t, body, "tok-123", name)
+		assert.Contains(t, body, "2030-01-01T00:00:00Z", name)
+	}
+}
+
+func TestRenderEmail_MissingDataFails(t *testing.T) {
+	_, _, err := RenderEmail(TemplatePasswordReset, map[string]string{"ExpiresAt": "soon"})
+	assert.Error(t, err)
+}
+
+func TestRenderEmail_UnknownTemplate(t *testing.T) {
+	_, _, err := RenderEmail("welcome", nil)
+	assert.EqualError(t, err, "unknown email template: welcome")
+}
+
+// capturedMail is a message handed to the SMTP transport
+type capturedMail struct {
+	addr string
+	from string
+	to   []string
+	msg  string
+}
+
+func newCapturingSMTPMailer(config SMTPConfig) (*SMTPMailer, *[]capturedMail) {
+	var sent []capturedMail
+	mailer := NewSMTPMailer(config)
+	mailer.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		sent = append(sent, capturedMail{addr: addr, from: from, to: to, msg: string(msg)})
+		return nil
+	}
+	return mailer, &sent
+}
+
+func TestSMTPMailer_SendsRenderedMessage(t *testing.T) {
+	mailer, sent := newCapturingSMTPMailer(SMTPConfig{Host: "smtp.example.com", Port: 2525, From: "no-reply@example.com"})
+
+	err := mailer.Send(context.Background(), "user@example.com", TemplateEmailVerification, map[string]string{
+		"Token":     "verify-456",
+		"ExpiresAt": "2030-01-01T00:00:00Z",
+	})
+
+	require.NoError(t, err)
+	require.Len(t, *sent, 1)
+	mail := (*sent)[0]
+	assert.Equal(t, "smtp.example.com:2525", mail.addr)
+	assert.Equal(t, "no-reply@example.com", mail.from)
+	assert.Equal(t, []string{"user@example.com"}, mail.to)
+	assert.True(t, strings.HasPrefix(mail.msg, "From: no-reply@example.com\r\nTo: user@example.com\r\nSubject: Verify your email address\r\n"))
+	assert.Contains(t, mail.msg, "verify-456")
+}
+
+func TestSMTPMailer_RejectsHeaderInjection(t *testing.T) {
+	mailer, sent := newCapturingSMTPMailer(SMTPConfig{Host: "smtp.example.com", Port: 25})
+
+	err := mailer.Send(context.Background(), "user@example.com\r\nBcc: evil@example.com", TemplatePasswordReset, map[string]string{
+		"Token":     "reset-789",
+		"ExpiresAt": "soon",
+	})
+
+	assert.Error(t, err)
+	assert.Empty(t, *sent)
+}
+
+func TestNewMailer_FallsBackToLogMailer(t *testing.T) {
+	assert.IsType(t, &LogMailer{}, NewMailer(&Config{}))
+	assert.IsType(t, &SMTPMailer{}, NewMailer(&Config{SMTPHost: "smtp.example.com", SMTPPort: 25}))
+}
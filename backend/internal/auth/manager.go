@@ -15,10 +15,15 @@ type Manager struct {
 
 // NewManager creates a new authentication manager with all services
 func NewManager(config *Config, userRepo repository.UserRepository) *Manager {
-	jwtService := NewJWTService(config.JWTSecret, config.TokenDuration)
-	passwordService := NewPasswordServiceWithCost(config.BCryptCost)
+	jwtService := NewJWTServiceWithIssuer(config.JWTSecret, config.TokenDuration, config.JWTIssuer, config.JWTAudiences)
+	jwtService.SetRefreshTokenDuration(config.RefreshTokenDuration)
+	jwtService.SetKeyEncryptionKey(config.JWTKeyEncryptionKey)
+	config.BCryptCost = calibrateBCryptCost(config)
+	passwordService := NewPasswordServiceWithPeppers(config.BCryptCost, config.PasswordPeppers)
 	authService := NewAuthService(jwtService, passwordService, userRepo)
+	authService.SetMailer(NewMailer(config))
 	middleware := NewAuthMiddleware(jwtService, userRepo)
+	middleware.UseTokenCookie(NewTokenCookie(config))
 
 	return &Manager{
 		Config:          config,
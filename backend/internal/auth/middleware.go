@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"backend/internal/graph/model"
 	"backend/internal/repository"
+	"backend/internal/security"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // ContextKey is the type for context keys to avoid collisions
@@ -19,12 +22,16 @@ const (
 	UserContextKey ContextKey = "user"
 	// ClaimsContextKey is the key for storing JWT claims in context
 	ClaimsContextKey ContextKey = "claims"
+	// TokenContextKey is the key for storing the raw JWT in context
+	TokenContextKey ContextKey = "token"
 )
 
 // AuthMiddleware provides authentication middleware for HTTP requests
 type AuthMiddleware struct {
 	jwtService *JWTService
 	userRepo   repository.UserRepository
+	// cookie carries the token in cookie mode; nil in header mode
+	cookie *TokenCookie
 }
 
 // NewAuthMiddleware creates a new authentication middleware
@@ -35,21 +42,137 @@ func NewAuthMiddleware(jwtService *JWTService, userRepo repository.UserRepositor
 	}
 }
 
+// UseTokenCookie switches the middleware to cookie mode, or back to header mode when cookie is nil
+func (a *AuthMiddleware) UseTokenCookie(cookie *TokenCookie) {
+	a.cookie = cookie
+}
+
+// WriteTokenCookie sets the token cookie and a fresh CSRF cookie on w; it
+// does nothing in header mode
+func (a *AuthMiddleware) WriteTokenCookie(w http.ResponseWriter, token string, expiresAt time.Time) {
+	if a.cookie != nil {
+		a.cookie.setCookies(w, token, expiresAt)
+	}
+}
+
+// WriteAuthCookies sets the token, refresh and CSRF cookies for response on
+// w and clears response.RefreshToken; it does nothing in header mode
+func (a *AuthMiddleware) WriteAuthCookies(w http.ResponseWriter, response *AuthResponse) {
+	if a.cookie != nil {
+		a.cookie.setAuthCookies(w, response)
+	}
+}
+
+// ClearAuthCookies expires the token, refresh and CSRF cookies on w; it does
+// nothing in header mode
+func (a *AuthMiddleware) ClearAuthCookies(w http.ResponseWriter) {
+	if a.cookie != nil {
+		a.cookie.clearCookies(w)
+	}
+}
+
+// RefreshTokenFromRequest returns the refresh token cookie of r in cookie mode
+func (a *AuthMiddleware) RefreshTokenFromRequest(r *http.Request) (string, bool) {
+	if a.cookie == nil {
+		return "", false
+	}
+	return a.cookie.refreshToken(r)
+}
+
+// TokenFromRequest returns the token from the Authorization header or, in
+// cookie mode when the header is absent, from the token cookie
+func (a *AuthMiddleware) TokenFromRequest(r *http.Request) (string, error) {
+	token, _, err := a.requestToken(r)
+	return token, err
+}
+
+// requestToken is TokenFromRequest, also reporting whether the token came from the cookie
+func (a *AuthMiddleware) requestToken(r *http.Request) (string, bool, error) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" && a.cookie != nil {
+		if cookie, err := r.Cookie(a.cookie.Name); err == nil && cookie.Value != "" {
+			return cookie.Value, true, nil
+		}
+	}
+	token, err := ExtractTokenFromHeader(authHeader)
+	return token, false, err
+}
+
+// withClaims adds the authenticated user, claims and token to the request
+// context, marking cookie-authenticated requests with the outcome of their
+// CSRF check. Under an impersonation token the user is the impersonated
+// one; the admin behind it is logged and recorded for audit entries.
+func (a *AuthMiddleware) withClaims(c *gin.Context, user *model.User, claims *JWTClaims, token string, fromCookie bool) {
+	ctx := authenticatedContext(c.Request.Context(), user, claims, token)
+	if fromCookie {
+		ctx = withCookieAuth(ctx, a.cookie.csrfValid(c.Request))
+	}
+	if claims.ImpersonatorID != nil {
+		LogImpersonatedRequest(*claims.ImpersonatorID, user.ID, c.Request.Method, c.Request.URL.Path)
+	}
+	c.Request = c.Request.WithContext(ctx)
+}
+
+// rolePermissions grants the security user of each request its role's permissions
+var rolePermissions = security.NewAuthorizationMiddleware()
+
+// authenticatedContext adds the authenticated user, claims and token to ctx,
+// recording the admin behind an impersonation token
+func authenticatedContext(ctx context.Context, user *model.User, claims *JWTClaims, token string) context.Context {
+	ctx = context.WithValue(ctx, UserContextKey, user)
+	ctx = security.WithUser(ctx, SecurityUser(user))
+	ctx = context.WithValue(ctx, ClaimsContextKey, claims)
+	ctx = context.WithValue(ctx, TokenContextKey, token)
+	if claims.ImpersonatorID != nil {
+		ctx = security.WithImpersonator(ctx, claims.ImpersonatorID.String())
+	}
+	return ctx
+}
+
+// SecurityUser returns the security user for an authenticated account, with
+// its stored role and that role's permissions. An account that holds a valid
+// token counts as active and verified; an unknown role falls back to user.
+func SecurityUser(user *model.User) *security.User {
+	role := security.Role(user.Role)
+	switch role {
+	case security.RoleAdmin, security.RoleModerator:
+	default:
+		role = security.RoleUser
+	}
+
+	permissions := rolePermissions.RolePermissions(role)
+	secUser := &security.User{
+		ID:          user.ID.String(),
+		Email:       user.Email,
+		Username:    user.Name,
+		Role:        role,
+		Permissions: make([]string, len(permissions)),
+		IsActive:    true,
+		IsVerified:  true,
+	}
+	for i, perm := range permissions {
+		secUser.Permissions[i] = string(perm)
+	}
+	return secUser
+}
+
+// withCookieWriter lets resolvers set the token cookie in cookie mode
+func (a *AuthMiddleware) withCookieWriter(c *gin.Context) {
+	if a.cookie != nil {
+		c.Request = c.Request.WithContext(withTokenCookieWriter(c.Request.Context(), a.cookie, c.Writer, c.Request))
+	}
+}
+
 // OptionalAuth middleware that extracts user from JWT token if present
 // Does not require authentication - continues even if no token or invalid token
 func (a *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Extract token from Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			// No token provided, continue without user context
-			c.Next()
-			return
-		}
+		a.withCookieWriter(c)
 
-		token, err := ExtractTokenFromHeader(authHeader)
+		// Extract token from Authorization header or cookie
+		token, fromCookie, err := a.requestToken(c.Request)
 		if err != nil {
-			// Invalid header format, continue without user context
+			// No token or invalid header format, continue without user context
 			c.Next()
 			return
 		}
@@ -71,9 +194,7 @@ func (a *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 		}
 
 		// Add user and claims to context
-		ctx := context.WithValue(c.Request.Context(), UserContextKey, user)
-		ctx = context.WithValue(ctx, ClaimsContextKey, claims)
-		c.Request = c.Request.WithContext(ctx)
+		a.withClaims(c, user, claims, token, fromCookie)
 
 		c.Next()
 	}
@@ -83,20 +204,17 @@ func (a *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 // Returns 401 if no token or invalid token
 func (a *AuthMiddleware) RequiredAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Extract token from Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authorization header is required",
-			})
-			c.Abort()
-			return
-		}
+		a.withCookieWriter(c)
 
-		token, err := ExtractTokenFromHeader(authHeader)
+		// Extract token from Authorization header or cookie
+		token, fromCookie, err := a.requestToken(c.Request)
 		if err != nil {
+			message := "Invalid authorization header format"
+			if c.GetHeader("Authorization") == "" {
+				message = "Authorization header is required"
+			}
 			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid authorization header format",
+				"error": message,
 			})
 			c.Abort()
 			return
@@ -123,9 +241,7 @@ func (a *AuthMiddleware) RequiredAuth() gin.HandlerFunc {
 		}
 
 		// Add user and claims to context
-		ctx := context.WithValue(c.Request.Context(), UserContextKey, user)
-		ctx = context.WithValue(ctx, ClaimsContextKey, claims)
-		c.Request = c.Request.WithContext(ctx)
+		a.withClaims(c, user, claims, token, fromCookie)
 
 		c.Next()
 	}
@@ -143,6 +259,12 @@ func GetClaimsFromContext(ctx context.Context) (*JWTClaims, bool) {
 	return claims, ok
 }
 
+// GetTokenFromContext extracts the token the request was authenticated with
+func GetTokenFromContext(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(TokenContextKey).(string)
+	return token, ok && token != ""
+}
+
 // RequireUser is a helper function for GraphQL resolvers to ensure user is authenticated
 func RequireUser(ctx context.Context) (*model.User, error) {
 	user, ok := GetUserFromContext(ctx)
@@ -159,4 +281,9 @@ func LogAuthAttempt(email string, success bool, ip string) {
 		status = "FAILED"
 	}
 	log.Printf("AUTH_ATTEMPT: email=%s status=%s ip=%s", email, status, ip)
-}
\ No newline at end of file
+}
+
+// LogImpersonatedRequest logs a request an admin made as another user
+func LogImpersonatedRequest(adminID, userID uuid.UUID, method, path string) {
+	log.Printf("IMPERSONATION: admin=%s user=%s method=%s path=%s", adminID, userID, method, path)
+}
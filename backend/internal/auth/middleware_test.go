@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/graph/model"
+	"backend/internal/security"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityUser_GrantsRolePermissions(t *testing.T) {
+	tests := []struct {
+		role         string
+		expectedRole security.Role
+		moderate     bool
+	}{
+		{role: "admin", expectedRole: security.RoleAdmin, moderate: true},
+		{role: "moderator", expectedRole: security.RoleModerator, moderate: true},
+		{role: "user", expectedRole: security.RoleUser},
+		{role: "", expectedRole: security.RoleUser},
+		{role: "superuser", expectedRole: security.RoleUser},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			user := &model.User{ID: uuid.New(), Email: "user@example.com", Name: "User", Role: tt.role}
+
+			secUser := SecurityUser(user)
+
+			assert.Equal(t, user.ID.String(), secUser.ID)
+			assert.Equal(t, tt.expectedRole, secUser.Role)
+			assert.Equal(t, tt.moderate, secUser.HasPermission(security.PermissionModerate))
+			assert.True(t, secUser.IsActive)
+			assert.True(t, secUser.IsVerified)
+		})
+	}
+}
+
+func TestAuthMiddleware_AuthenticatedRequestsCarrySecurityUser(t *testing.T) {
+	jwtService := NewJWTService("test-secret-key", time.Hour)
+	user := &model.User{ID: uuid.New(), Email: "mod@example.com", Role: "moderator"}
+	token, _, err := jwtService.GenerateToken(user)
+	require.NoError(t, err)
+	middleware := NewAuthMiddleware(jwtService, newMemoryUserRepo(user))
+
+	ctx, _, err := middleware.WebsocketInit(context.Background(), map[string]interface{}{"Authorization": "Bearer " + token})
+	require.NoError(t, err)
+
+	moderator, err := security.RequirePermission(ctx, security.PermissionModerate)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID.String(), moderator.ID)
+}
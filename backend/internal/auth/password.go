@@ -1,14 +1,40 @@
 package auth
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
+	"strings"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+// pepperedHashPrefix marks a stored hash whose password was HMACed with a
+// pepper before bcrypt; the pepper version follows it, then the bcrypt hash
+const pepperedHashPrefix = "pepper$"
+
+// PasswordPepper is a server-side secret mixed into passwords before hashing.
+// The version is stored with each hash so the pepper can be rotated.
+type PasswordPepper struct {
+	Version string
+	Secret  string
+}
+
+// PasswordHasher hashes, verifies and validates passwords
+type PasswordHasher interface {
+	HashPassword(password string) (string, error)
+	VerifyPassword(hashedPassword, password string) error
+	IsValidPassword(password string) error
+}
+
 // PasswordService handles password hashing and validation
 type PasswordService struct {
 	cost int
+	// peppers maps each known pepper version to its secret; current names
+	// the one new hashes use and is empty when peppering is off
+	peppers map[string][]byte
+	current string
 }
 
 // NewPasswordService creates a new password service
@@ -25,20 +51,74 @@ func NewPasswordServiceWithCost(cost int) *PasswordService {
 	}
 }
 
-// HashPassword hashes a plain text password
+// NewPasswordServiceWithPeppers creates a password service that peppers new
+// hashes with the first of peppers. The others are only used to verify
+// hashes made before a rotation, and hashes without a pepper still verify.
+func NewPasswordServiceWithPeppers(cost int, peppers []PasswordPepper) *PasswordService {
+	p := NewPasswordServiceWithCost(cost)
+	if len(peppers) == 0 {
+		return p
+	}
+
+	p.peppers = make(map[string][]byte, len(peppers))
+	for _, pepper := range peppers {
+		p.peppers[pepper.Version] = []byte(pepper.Secret)
+	}
+	p.current = peppers[0].Version
+	return p
+}
+
+// HashPassword hashes a plain text password, peppering it when a pepper is configured
 func (p *PasswordService) HashPassword(password string) (string, error) {
 	if password == "" {
 		return "", fmt.Errorf("password cannot be empty")
 	}
 
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), p.cost)
+	input := []byte(password)
+	if p.current != "" {
+		input = pepper(p.peppers[p.current], password)
+	}
+
+	hashedBytes, err := bcrypt.GenerateFromPassword(input, p.cost)
 	if err != nil {
 		return "", fmt.Errorf("failed to hash password: %w", err)
 	}
 
+	if p.current != "" {
+		return pepperedHashPrefix + p.current + "$" + string(hashedBytes), nil
+	}
 	return string(hashedBytes), nil
 }
 
+// pepper HMACs password with secret. The MAC is base64 encoded so bcrypt
+// sees 44 printable bytes, well within its 72 byte limit.
+func pepper(secret []byte, password string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(password))
+	encoded := make([]byte, base64.StdEncoding.EncodedLen(sha256.Size))
+	base64.StdEncoding.Encode(encoded, mac.Sum(nil))
+	return encoded
+}
+
+// compareInput splits a stored hash into its bcrypt hash and the bytes the
+// password must be turned into to match it, using the hash's pepper version
+func (p *PasswordService) compareInput(hashedPassword, password string) (string, []byte, error) {
+	rest, peppered := strings.CutPrefix(hashedPassword, pepperedHashPrefix)
+	if !peppered {
+		return hashedPassword, []byte(password), nil
+	}
+
+	version, hash, ok := strings.Cut(rest, "$")
+	if !ok {
+		return "", nil, fmt.Errorf("malformed peppered password hash")
+	}
+	secret, known := p.peppers[version]
+	if !known {
+		return "", nil, fmt.Errorf("unknown password pepper version %q", version)
+	}
+	return hash, pepper(secret, password), nil
+}
+
 // VerifyPassword verifies a plain text password against a hashed password
 func (p *PasswordService) VerifyPassword(hashedPassword, password string) error {
 	if hashedPassword == "" {
@@ -48,7 +128,12 @@ func (p *PasswordService) VerifyPassword(hashedPassword, password string) error
 		return fmt.Errorf("password cannot be empty")
 	}
 
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	hash, input, err := p.compareInput(hashedPassword, password)
+	if err != nil {
+		return fmt.Errorf("failed to verify password: %w", err)
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(hash), input)
 	if err != nil {
 		if err == bcrypt.ErrMismatchedHashAndPassword {
 			return fmt.Errorf("invalid password")
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/graph/model"
+	"backend/internal/ids"
+	"backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidPasswordSetupToken is returned for unknown, expired or used password setup tokens
+var ErrInvalidPasswordSetupToken = repository.ErrInvalidPasswordSetupToken
+
+// NewPasswordSetupToken returns a one-time password setup token for userID
+// and the record to store for it. Like the other emailed tokens, only the
+// token's hash is stored; SetPasswordWithToken redeems it.
+func NewPasswordSetupToken(userID uuid.UUID, now time.Time, ttl time.Duration) (string, *model.PasswordSetupToken, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	record := &model.PasswordSetupToken{
+		ID:        ids.New(),
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	return token, record, nil
+}
+
+// HashPasswordSetupToken returns the stored form of a password setup token
+func HashPasswordSetupToken(token string) string {
+	return hashToken(token)
+}
+
+// EnablePasswordSetup lets users created without a password, such as by an
+// import, set their first password with the token mailed to them
+func (a *AuthService) EnablePasswordSetup(repo repository.PasswordSetupRepository) {
+	a.passwordSetup = repo
+}
+
+// SetPasswordWithToken redeems a password setup token, setting the user's
+// first password, and signs them in. Each token works once.
+func (a *AuthService) SetPasswordWithToken(ctx context.Context, token, password, clientIP string) (*AuthResponse, error) {
+	if a.passwordSetup == nil {
+		return nil, ErrInvalidPasswordSetupToken
+	}
+
+	if err := a.passwordService.IsValidPassword(password); err != nil {
+		return nil, fmt.Errorf("password validation failed: %w", err)
+	}
+
+	hashedPassword, err := a.passwordService.HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	userID, err := a.passwordSetup.Redeem(ctx, HashPasswordSetupToken(token), hashedPassword, a.now())
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidPasswordSetupToken) {
+			return nil, ErrInvalidPasswordSetupToken
+		}
+		return nil, fmt.Errorf("failed to set password: %w", err)
+	}
+
+	user, err := a.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	response, err := a.issueTokens(ctx, user, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+
+	LogAuthAttempt(user.Email, true, clientIP)
+	a.recordLogin(ctx, user.ID, clientIP)
+
+	return response, nil
+}
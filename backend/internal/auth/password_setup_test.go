@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/graph/model"
+	"backend/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryPasswordSetupRepo redeems tokens against a memoryUserRepo
+type memoryPasswordSetupRepo struct {
+	repository.PasswordSetupRepository
+	users  *memoryUserRepo
+	tokens map[string]*model.PasswordSetupToken // token hash -> record
+}
+
+func (r *memoryPasswordSetupRepo) Redeem(ctx context.Context, tokenHash, passwordHash string, now time.Time) (uuid.UUID, error) {
+	record, ok := r.tokens[tokenHash]
+	if !ok || !now.Before(record.ExpiresAt) {
+		return uuid.Nil, repository.ErrInvalidPasswordSetupToken
+	}
+	delete(r.tokens, tokenHash)
+	if err := r.users.UpdatePassword(ctx, record.UserID, passwordHash); err != nil {
+		return uuid.Nil, err
+	}
+	return record.UserID, nil
+}
+
+// newPasswordSetupAuthService returns a service whose only user was imported
+// without a password, and that user's setup token
+func newPasswordSetupAuthService(t *testing.T) (*AuthService, *memoryPasswordSetupRepo, string) {
+	t.Helper()
+	user := &model.User{ID: uuid.New(), Email: "imported@example.com", Name: "Imported"}
+	users := newMemoryUserRepo(user)
+	service := NewAuthService(NewJWTService("test-secret-key", time.Hour), NewPasswordServiceWithCost(4), users)
+
+	token, record, err := NewPasswordSetupToken(user.ID, time.Now(), time.Hour)
+	require.NoError(t, err)
+	setup := &memoryPasswordSetupRepo{users: users, tokens: map[string]*model.PasswordSetupToken{record.TokenHash: record}}
+	service.EnablePasswordSetup(setup)
+
+	return service, setup, token
+}
+
+func TestAuthService_Login_RejectsUserWithoutPassword(t *testing.T) {
+	service, _, _ := newPasswordSetupAuthService(t)
+
+	_, err := service.Login(context.Background(), LoginRequest{Email: "imported@example.com", Password: ""}, "127.0.0.1")
+
+	assert.EqualError(t, err, "invalid email or password")
+}
+
+func TestAuthService_SetPasswordWithToken(t *testing.T) {
+	service, setup, token := newPasswordSetupAuthService(t)
+	ctx := context.Background()
+
+	response, err := service.SetPasswordWithToken(ctx, token, "Password123!", "127.0.0.1")
+	require.NoError(t, err)
+	assert.NotEmpty(t, response.Token)
+	assert.Equal(t, "imported@example.com", response.User.Email)
+	assert.Empty(t, setup.tokens)
+
+	_, err = service.Login(ctx, LoginRequest{Email: "imported@example.com", Password: "Password123!"}, "127.0.0.1")
+	assert.NoError(t, err)
+
+	_, err = service.SetPasswordWithToken(ctx, token, "Another123!", "127.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidPasswordSetupToken)
+}
+
+func TestAuthService_SetPasswordWithToken_RejectsInvalidInput(t *testing.T) {
+	service, setup, token := newPasswordSetupAuthService(t)
+
+	_, err := service.SetPasswordWithToken(context.Background(), "unknown", "Password123!", "127.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidPasswordSetupToken)
+
+	_, err = service.SetPasswordWithToken(context.Background(), token, "short", "127.0.0.1")
+	assert.ErrorContains(t, err, "password validation failed")
+	assert.Len(t, setup.tokens, 1, "token consumed by a rejected password")
+}
+
+func TestAuthService_SetPasswordWithToken_Disabled(t *testing.T) {
+	service := NewAuthService(NewJWTService("test-secret-key", time.Hour), NewPasswordServiceWithCost(4), newMemoryUserRepo())
+
+	_, err := service.SetPasswordWithToken(context.Background(), "token", "Password123!", "127.0.0.1")
+
+	assert.ErrorIs(t, err, ErrInvalidPasswordSetupToken)
+}
@@ -1,9 +1,12 @@
 package auth
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestPasswordService_HashPassword(t *testing.T) {
@@ -136,4 +139,64 @@ func TestPasswordService_WithCustomCost(t *testing.T) {
 	// Verify the password works
 	err = passwordService.VerifyPassword(hashedPassword, password)
 	assert.NoError(t, err)
-}
\ No newline at end of file
+}
+
+func TestPasswordService_PepperedHashing(t *testing.T) {
+	service := NewPasswordServiceWithPeppers(bcrypt.MinCost, []PasswordPepper{{Version: "v1", Secret: "pepper-one"}})
+
+	hash, err := service.HashPassword("testpassword123")
+
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "pepper$v1$$2a$"), hash)
+	assert.NoError(t, service.VerifyPassword(hash, "testpassword123"))
+	assert.ErrorContains(t, service.VerifyPassword(hash, "wrongpassword1"), "invalid password")
+
+	// The bcrypt hash alone, as leaked from the database, does not match the password
+	bcryptHash := strings.TrimPrefix(hash, "pepper$v1$")
+	assert.Error(t, bcrypt.CompareHashAndPassword([]byte(bcryptHash), []byte("testpassword123")))
+}
+
+func TestPasswordService_PepperVersionSelection(t *testing.T) {
+	before := NewPasswordServiceWithPeppers(bcrypt.MinCost, []PasswordPepper{{Version: "v1", Secret: "pepper-one"}})
+	oldHash, err := before.HashPassword("testpassword123")
+	require.NoError(t, err)
+
+	rotated := NewPasswordServiceWithPeppers(bcrypt.MinCost, []PasswordPepper{
+		{Version: "v2", Secret: "pepper-two"},
+		{Version: "v1", Secret: "pepper-one"},
+	})
+	newHash, err := rotated.HashPassword("testpassword123")
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(newHash, "pepper$v2$"), "new hashes use the first pepper")
+	assert.NoError(t, rotated.VerifyPassword(oldHash, "testpassword123"), "hashes from before the rotation still verify")
+	assert.NoError(t, rotated.VerifyPassword(newHash, "testpassword123"))
+
+	retired := NewPasswordServiceWithPeppers(bcrypt.MinCost, []PasswordPepper{{Version: "v2", Secret: "pepper-two"}})
+	assert.ErrorContains(t, retired.VerifyPassword(oldHash, "testpassword123"), `unknown password pepper version "v1"`)
+
+	wrongSecret := NewPasswordServiceWithPeppers(bcrypt.MinCost, []PasswordPepper{{Version: "v1", Secret: "not-the-pepper"}})
+	assert.ErrorContains(t, wrongSecret.VerifyPassword(oldHash, "testpassword123"), "invalid password")
+}
+
+func TestPasswordService_PepperKeepsUnpepperedHashes(t *testing.T) {
+	plainHash, err := NewPasswordServiceWithCost(bcrypt.MinCost).HashPassword("testpassword123")
+	require.NoError(t, err)
+
+	peppered := NewPasswordServiceWithPeppers(bcrypt.MinCost, []PasswordPepper{{Version: "v1", Secret: "pepper-one"}})
+
+	assert.NoError(t, peppered.VerifyPassword(plainHash, "testpassword123"))
+	assert.ErrorContains(t, peppered.VerifyPassword(plainHash, "wrongpassword1"), "invalid password")
+}
+
+func TestNewConfig_PasswordPeppers(t *testing.T) {
+	t.Setenv("PASSWORD_PEPPERS", "v2:new:secret, v1:old, broken, :empty, v3:, v2:duplicate")
+
+	assert.Equal(t, []PasswordPepper{
+		{Version: "v2", Secret: "new:secret"},
+		{Version: "v1", Secret: "old"},
+	}, NewConfig().PasswordPeppers)
+
+	t.Setenv("PASSWORD_PEPPERS", "")
+	assert.Empty(t, NewConfig().PasswordPeppers)
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"backend/internal/graph/model"
+	"backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrRefreshTokenRevoked is returned for refresh tokens that were revoked,
+	// already exchanged or never stored
+	ErrRefreshTokenRevoked = errors.New("refresh token has been revoked")
+	// ErrRefreshTokenReused is returned when a used refresh token is presented
+	// again; its whole family is revoked in response
+	ErrRefreshTokenReused = repository.ErrRefreshTokenReused
+)
+
+// EnableRefreshTokenStore stores every issued refresh token, by the hash of
+// its jti, so that refresh tokens rotate on use and are revoked on logout and
+// password change. Without a store refresh tokens stay valid until they expire.
+func (a *AuthService) EnableRefreshTokenStore(repo repository.RefreshTokenRepository) {
+	a.refreshTokens = repo
+}
+
+// storeRefreshToken records an issued refresh token in familyID, or in a new
+// family named after the token when familyID is uuid.Nil
+func (a *AuthService) storeRefreshToken(ctx context.Context, userID, tokenID, familyID uuid.UUID, expiresAt time.Time) error {
+	if a.refreshTokens == nil {
+		return nil
+	}
+	if familyID == uuid.Nil {
+		familyID = tokenID
+	}
+
+	record := &model.RefreshToken{
+		ID:        tokenID,
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(tokenID.String()),
+		ExpiresAt: expiresAt,
+		CreatedAt: a.now(),
+	}
+	if err := a.refreshTokens.Create(ctx, record); err != nil {
+		return fmt.Errorf("failed to store refresh token: %w", err)
+	}
+	return nil
+}
+
+// useRefreshToken marks the refresh token with claims as used and returns
+// its family. A replayed token revokes its family, since either the client
+// or whoever stole the token already holds its successor.
+func (a *AuthService) useRefreshToken(ctx context.Context, claims *JWTClaims) (uuid.UUID, error) {
+	if a.refreshTokens == nil {
+		return uuid.Nil, nil
+	}
+	if claims.ID == "" {
+		return uuid.Nil, ErrRefreshTokenRevoked
+	}
+
+	tokenHash := hashToken(claims.ID)
+	record, err := a.refreshTokens.Use(ctx, tokenHash, a.now())
+	switch {
+	case err == nil:
+		return record.FamilyID, nil
+	case errors.Is(err, repository.ErrRefreshTokenReused):
+		log.Printf("Refresh token reuse detected for user %s; revoking its token family", claims.UserID)
+		if err := a.refreshTokens.RevokeFamily(ctx, tokenHash, a.now()); err != nil {
+			return uuid.Nil, err
+		}
+		return uuid.Nil, ErrRefreshTokenReused
+	case errors.Is(err, repository.ErrRefreshTokenInvalid):
+		return uuid.Nil, ErrRefreshTokenRevoked
+	default:
+		return uuid.Nil, err
+	}
+}
+
+// Logout revokes refreshToken together with every token refreshed from it,
+// ending that session. Access tokens already issued stay valid until they expire.
+func (a *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	claims, err := a.jwtService.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return fmt.Errorf("invalid refresh token: %w", err)
+	}
+	if a.refreshTokens == nil || claims.ID == "" {
+		return nil
+	}
+
+	return a.refreshTokens.RevokeFamily(ctx, hashToken(claims.ID), a.now())
+}
+
+// revokeUserRefreshTokens revokes every refresh token of the user
+func (a *AuthService) revokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
+	if a.refreshTokens == nil {
+		return nil
+	}
+	if err := a.refreshTokens.RevokeByUserID(ctx, userID, a.now()); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/graph/model"
+	"backend/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRefreshTokenRepo is an in-memory refresh_tokens table keyed by token hash
+type memoryRefreshTokenRepo struct {
+	tokens map[string]*model.RefreshToken
+}
+
+func (r *memoryRefreshTokenRepo) Create(ctx context.Context, token *model.RefreshToken) error {
+	copied := *token
+	r.tokens[token.TokenHash] = &copied
+	return nil
+}
+
+func (r *memoryRefreshTokenRepo) Use(ctx context.Context, tokenHash string, now time.Time) (*model.RefreshToken, error) {
+	token, ok := r.tokens[tokenHash]
+	switch {
+	case !ok:
+		return nil, repository.ErrRefreshTokenInvalid
+	case token.UsedAt != nil:
+		return token, repository.ErrRefreshTokenReused
+	case token.RevokedAt != nil || !token.ExpiresAt.After(now):
+		return nil, repository.ErrRefreshTokenInvalid
+	}
+	token.UsedAt = &now
+	return token, nil
+}
+
+func (r *memoryRefreshTokenRepo) RevokeFamily(ctx context.Context, tokenHash string, at time.Time) error {
+	if token, ok := r.tokens[tokenHash]; ok {
+		for _, other := range r.tokens {
+			if other.FamilyID == token.FamilyID && other.RevokedAt == nil {
+				other.RevokedAt = &at
+			}
+		}
+	}
+	return nil
+}
+
+func (r *memoryRefreshTokenRepo) RevokeByUserID(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+// newRefreshStoreAuthService returns a service storing refresh tokens, and
+// the login response of its only user
+func newRefreshStoreAuthService(t *testing.T) (*AuthService, *memoryRefreshTokenRepo, *AuthResponse) {
+	t.Helper()
+	passwords := NewPasswordServiceWithCost(4)
+	hash, err := passwords.HashPassword("password123")
+	require.NoError(t, err)
+	user := &model.User{ID: uuid.New(), Email: "test@example.com", PasswordHash: hash}
+
+	service := NewAuthService(NewJWTService("test-secret-key", time.Hour), passwords, newMemoryUserRepo(user))
+	store := &memoryRefreshTokenRepo{tokens: map[string]*model.RefreshToken{}}
+	service.EnableRefreshTokenStore(store)
+
+	response, err := service.Login(context.Background(), LoginRequest{Email: "test@example.com", Password: "password123"}, "127.0.0.1")
+	require.NoError(t, err)
+	return service, store, response
+}
+
+func TestAuthService_Login_StoresHashedRefreshToken(t *testing.T) {
+	_, store, response := newRefreshStoreAuthService(t)
+
+	require.Len(t, store.tokens, 1)
+	for hash, record := range store.tokens {
+		assert.Equal(t, hashToken(record.ID.String()), hash)
+		assert.Equal(t, record.ID, record.FamilyID)
+		assert.NotContains(t, response.RefreshToken, hash)
+	}
+}
+
+func TestAuthService_RefreshWithRefreshToken_RotatesWithinFamily(t *testing.T) {
+	service, store, login := newRefreshStoreAuthService(t)
+	ctx := context.Background()
+
+	refreshed, err := service.RefreshWithRefreshToken(ctx, login.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, login.RefreshToken, refreshed.RefreshToken)
+
+	require.Len(t, store.tokens, 2)
+	var families []uuid.UUID
+	for _, record := range store.tokens {
+		families = append(families, record.FamilyID)
+	}
+	assert.Equal(t, families[0], families[1])
+
+	_, err = service.RefreshWithRefreshToken(ctx, refreshed.RefreshToken)
+	assert.NoError(t, err)
+}
+
+func TestAuthService_RefreshWithRefreshToken_ReuseRevokesFamily(t *testing.T) {
+	service, _, login := newRefreshStoreAuthService(t)
+	ctx := context.Background()
+
+	refreshed, err := service.RefreshWithRefreshToken(ctx, login.RefreshToken)
+	require.NoError(t, err)
+
+	_, err = service.RefreshWithRefreshToken(ctx, login.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	_, err = service.RefreshWithRefreshToken(ctx, refreshed.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenRevoked, "successor of a replayed token still works")
+}
+
+func TestAuthService_Logout_RevokesSession(t *testing.T) {
+	service, _, login := newRefreshStoreAuthService(t)
+	ctx := context.Background()
+	other, err := service.Login(ctx, LoginRequest{Email: "test@example.com", Password: "password123"}, "127.0.0.1")
+	require.NoError(t, err)
+
+	require.NoError(t, service.Logout(ctx, login.RefreshToken))
+
+	_, err = service.RefreshWithRefreshToken(ctx, login.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenRevoked)
+	_, err = service.RefreshWithRefreshToken(ctx, other.RefreshToken)
+	assert.NoError(t, err, "logout ended another session")
+}
+
+func TestAuthService_ChangePassword_RevokesRefreshTokens(t *testing.T) {
+	service, _, login := newRefreshStoreAuthService(t)
+	ctx := context.Background()
+
+	require.NoError(t, service.ChangePassword(ctx, login.User.ID, "password123", "NewPassword123!"))
+
+	_, err := service.RefreshWithRefreshToken(ctx, login.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenRevoked)
+}
+
+func TestAuthService_RefreshWithRefreshToken_RejectsTokenWithoutID(t *testing.T) {
+	service, _, login := newRefreshStoreAuthService(t)
+
+	// Refresh tokens issued before the store was enabled carry no stored jti
+	unstored, _, err := service.jwtService.GenerateRefreshTokenWithID(login.User, "")
+	require.NoError(t, err)
+
+	_, err = service.RefreshWithRefreshToken(context.Background(), unstored)
+	assert.ErrorIs(t, err, ErrRefreshTokenRevoked)
+}
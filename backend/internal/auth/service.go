@@ -2,30 +2,85 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"sync"
 	"time"
 
+	"backend/internal/clock"
 	"backend/internal/graph/model"
+	"backend/internal/graph/validation"
+	"backend/internal/ids"
 	"backend/internal/repository"
 	"github.com/google/uuid"
 )
 
+// ErrPasswordReused is returned when a new password matches a recently used one
+var ErrPasswordReused = errors.New("password was used recently")
+
+// dummyPassword is hashed once to give unknown-email logins a real bcrypt comparison
+const dummyPassword = "timing-equalization-dummy-password-1"
+
 // AuthService provides authentication operations
 type AuthService struct {
 	jwtService      *JWTService
-	passwordService *PasswordService
+	passwordService PasswordHasher
 	userRepo        repository.UserRepository
+	mailer          Mailer
+
+	dummyHashOnce sync.Once
+	dummyHash     string
+
+	// passwordHistory is nil when reuse checks are disabled
+	passwordHistory     repository.PasswordHistoryRepository
+	passwordHistorySize int
+
+	// passwordSetup is nil when password setup tokens are not redeemed
+	passwordSetup repository.PasswordSetupRepository
+
+	// refreshTokens is nil when refresh tokens are not stored, so they can
+	// be neither rotated nor revoked
+	refreshTokens repository.RefreshTokenRepository
+
+	// now stamps created and updated users
+	now clock.Clock
+	// newID generates the IDs of registered users
+	newID ids.Generator
+
+	// loginRecords tracks the background writes of login times
+	loginRecords sync.WaitGroup
 }
 
 // NewAuthService creates a new authentication service
-func NewAuthService(jwtService *JWTService, passwordService *PasswordService, userRepo repository.UserRepository) *AuthService {
+func NewAuthService(jwtService *JWTService, passwordService PasswordHasher, userRepo repository.UserRepository) *AuthService {
 	return &AuthService{
 		jwtService:      jwtService,
 		passwordService: passwordService,
 		userRepo:        userRepo,
+		mailer:          NewLogMailer(),
+		now:             clock.Now,
+		newID:           ids.New,
 	}
 }
 
+// SetMailer replaces the mailer used for verification and reset emails
+func (a *AuthService) SetMailer(mailer Mailer) {
+	a.mailer = mailer
+}
+
+// Mailer returns the mailer used by the authentication flows
+func (a *AuthService) Mailer() Mailer {
+	return a.mailer
+}
+
+// EnablePasswordHistory rejects password changes that reuse any of the user's
+// last size passwords, counting the current one. A size of 0 disables the check.
+func (a *AuthService) EnablePasswordHistory(repo repository.PasswordHistoryRepository, size int) {
+	a.passwordHistory = repo
+	a.passwordHistorySize = size
+}
+
 // LoginRequest represents a login request
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
@@ -44,13 +99,29 @@ type AuthResponse struct {
 	Token     string    `json:"token"`
 	ExpiresAt time.Time `json:"expiresAt"`
 	User      *model.User `json:"user"`
+	// RefreshToken is set at login, registration and refresh by refresh
+	// token; it is cleared once set as a cookie instead
+	RefreshToken     string     `json:"refreshToken,omitempty"`
+	RefreshExpiresAt *time.Time `json:"refreshExpiresAt,omitempty"`
 }
 
 // Login authenticates a user with email and password
 func (a *AuthService) Login(ctx context.Context, req LoginRequest, clientIP string) (*AuthResponse, error) {
+	// Emails are stored normalized, so any casing of the address logs in
+	req.Email = validation.NormalizeEmail(req.Email)
+
 	// Get user by email
 	user, err := a.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
+		// Compare against a dummy hash so an unknown email takes as long as a wrong password
+		_ = a.passwordService.VerifyPassword(a.dummyPasswordHash(), req.Password)
+		LogAuthAttempt(req.Email, false, clientIP)
+		return nil, fmt.Errorf("invalid email or password")
+	}
+
+	// Imported users have no password until they redeem their setup token
+	if user.PasswordHash == "" {
+		_ = a.passwordService.VerifyPassword(a.dummyPasswordHash(), req.Password)
 		LogAuthAttempt(req.Email, false, clientIP)
 		return nil, fmt.Errorf("invalid email or password")
 	}
@@ -61,32 +132,90 @@ func (a *AuthService) Login(ctx context.Context, req LoginRequest, clientIP stri
 		return nil, fmt.Errorf("invalid email or password")
 	}
 
-	// Generate JWT token
-	token, expiresAt, err := a.jwtService.GenerateToken(user)
+	// Generate JWT tokens
+	response, err := a.issueTokens(ctx, user, uuid.Nil)
 	if err != nil {
 		LogAuthAttempt(req.Email, false, clientIP)
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, err
 	}
 
 	LogAuthAttempt(req.Email, true, clientIP)
+	a.recordLogin(ctx, user.ID, clientIP)
+
+	return response, nil
+}
+
+// issueTokens generates an access token and a refresh token for user. The
+// refresh token joins familyID, or starts a new family when it is uuid.Nil.
+func (a *AuthService) issueTokens(ctx context.Context, user *model.User, familyID uuid.UUID) (*AuthResponse, error) {
+	token, expiresAt, err := a.jwtService.GenerateToken(user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	tokenID := a.newID()
+	refreshToken, refreshExpiresAt, err := a.jwtService.GenerateRefreshTokenWithID(user, tokenID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	if err := a.storeRefreshToken(ctx, user.ID, tokenID, familyID, refreshExpiresAt); err != nil {
+		return nil, err
+	}
 
 	return &AuthResponse{
-		Token:     token,
-		ExpiresAt: expiresAt,
-		User:      user,
+		Token:            token,
+		ExpiresAt:        expiresAt,
+		User:             user,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: &refreshExpiresAt,
 	}, nil
 }
 
+// loginRecordTimeout bounds the write of a login's time and client IP
+const loginRecordTimeout = 5 * time.Second
+
+// recordLogin stores the login time and client IP in the background, so a
+// slow or failing write never delays or fails the login itself
+func (a *AuthService) recordLogin(ctx context.Context, userID uuid.UUID, clientIP string) {
+	at := a.now()
+	a.loginRecords.Add(1)
+	go func() {
+		defer a.loginRecords.Done()
+
+		// The write outlives the request, so it must not inherit its cancellation
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loginRecordTimeout)
+		defer cancel()
+		if err := a.userRepo.RecordLogin(ctx, userID, at, clientIP); err != nil {
+			log.Printf("Failed to record login for user %s: %v", userID, err)
+		}
+	}()
+}
+
+// dummyPasswordHash returns a hash made with the configured cost, so the dummy
+// comparison costs the same as a real one
+func (a *AuthService) dummyPasswordHash() string {
+	a.dummyHashOnce.Do(func() {
+		a.dummyHash, _ = a.passwordService.HashPassword(dummyPassword)
+	})
+	return a.dummyHash
+}
+
 // Register creates a new user account
 func (a *AuthService) Register(ctx context.Context, req RegisterRequest, clientIP string) (*AuthResponse, error) {
+	req.Email = validation.NormalizeEmail(req.Email)
+	req.Name = validation.NormalizeText(req.Name)
+
 	// Validate password requirements
 	if err := a.passwordService.IsValidPassword(req.Password); err != nil {
 		return nil, fmt.Errorf("password validation failed: %w", err)
 	}
 
 	// Check if user already exists
-	existingUser, err := a.userRepo.GetByEmail(ctx, req.Email)
-	if err == nil && existingUser != nil {
+	exists, err := a.userRepo.ExistsByEmail(ctx, req.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing user: %w", err)
+	}
+	if exists {
 		return nil, fmt.Errorf("user with email %s already exists", req.Email)
 	}
 
@@ -97,32 +226,29 @@ func (a *AuthService) Register(ctx context.Context, req RegisterRequest, clientI
 	}
 
 	// Create user
+	now := a.now()
 	user := &model.User{
-		ID:           uuid.New(),
+		ID:           a.newID(),
 		Email:        req.Email,
 		Name:         req.Name,
 		PasswordHash: hashedPassword,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		CreatedAt:    now,
+		UpdatedAt:    now,
 	}
 
 	if err := a.userRepo.Create(ctx, user); err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	// Generate JWT token
-	token, expiresAt, err := a.jwtService.GenerateToken(user)
+	// Generate JWT tokens
+	response, err := a.issueTokens(ctx, user, uuid.Nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, err
 	}
 
 	LogAuthAttempt(req.Email, true, clientIP)
 
-	return &AuthResponse{
-		Token:     token,
-		ExpiresAt: expiresAt,
-		User:      user,
-	}, nil
+	return response, nil
 }
 
 // RefreshToken generates a new token for an authenticated user
@@ -152,6 +278,29 @@ func (a *AuthService) RefreshToken(ctx context.Context, currentToken string) (*A
 	}, nil
 }
 
+// RefreshWithRefreshToken exchanges a refresh token for a new access token
+// and a new refresh token. With a token store each refresh token works once:
+// the new one continues its family, and presenting a used one again revokes
+// the whole family.
+func (a *AuthService) RefreshWithRefreshToken(ctx context.Context, refreshToken string) (*AuthResponse, error) {
+	claims, err := a.jwtService.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid refresh token: %w", err)
+	}
+
+	familyID, err := a.useRefreshToken(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := a.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	return a.issueTokens(ctx, user, familyID)
+}
+
 // ChangePassword changes a user's password
 func (a *AuthService) ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error {
 	// Get user
@@ -170,6 +319,11 @@ func (a *AuthService) ChangePassword(ctx context.Context, userID uuid.UUID, curr
 		return fmt.Errorf("new password validation failed: %w", err)
 	}
 
+	// Reject recently used passwords
+	if err := a.checkPasswordHistory(ctx, user, newPassword); err != nil {
+		return err
+	}
+
 	// Hash new password
 	hashedPassword, err := a.passwordService.HashPassword(newPassword)
 	if err != nil {
@@ -177,12 +331,64 @@ func (a *AuthService) ChangePassword(ctx context.Context, userID uuid.UUID, curr
 	}
 
 	// Update user password
-	user.PasswordHash = hashedPassword
-	user.UpdatedAt = time.Now()
-
-	if err := a.userRepo.Update(ctx, user); err != nil {
+	previousHash := user.PasswordHash
+	if err := a.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
+	a.recordPasswordHistory(ctx, user.ID, previousHash)
+
+	// Sign out every session that signed in with the old password
+	if err := a.revokeUserRefreshTokens(ctx, user.ID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// historyEnabled reports whether previous passwords are kept beyond the current one
+func (a *AuthService) historyEnabled() bool {
+	return a.passwordHistory != nil && a.passwordHistorySize > 1
+}
+
+// checkPasswordHistory returns ErrPasswordReused if newPassword matches the
+// current password or one of the recent previous ones
+func (a *AuthService) checkPasswordHistory(ctx context.Context, user *model.User, newPassword string) error {
+	if a.passwordHistorySize <= 0 {
+		return nil
+	}
+
+	hashes := []string{user.PasswordHash}
+	if a.historyEnabled() {
+		recent, err := a.passwordHistory.GetRecent(ctx, user.ID, a.passwordHistorySize-1)
+		if err != nil {
+			return fmt.Errorf("failed to check password history: %w", err)
+		}
+		hashes = append(hashes, recent...)
+	}
+
+	for _, hash := range hashes {
+		if a.passwordService.VerifyPassword(hash, newPassword) == nil {
+			return ErrPasswordReused
+		}
+	}
+
 	return nil
+}
+
+// recordPasswordHistory stores the replaced hash and prunes entries beyond the limit.
+// The password has already changed, so failures are logged rather than returned.
+func (a *AuthService) recordPasswordHistory(ctx context.Context, userID uuid.UUID, previousHash string) {
+	if !a.historyEnabled() || previousHash == "" {
+		return
+	}
+
+	if err := a.passwordHistory.Add(ctx, userID, previousHash); err != nil {
+		log.Printf("Failed to record password history for %s: %v", userID, err)
+		return
+	}
+
+	if err := a.passwordHistory.Prune(ctx, userID, a.passwordHistorySize-1); err != nil {
+		log.Printf("Failed to prune password history for %s: %v", userID, err)
+	}
 }
\ No newline at end of file
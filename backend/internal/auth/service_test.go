@@ -0,0 +1,260 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"backend/internal/graph/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// spyPasswordService records the hashes passed to VerifyPassword
+type spyPasswordService struct {
+	*PasswordService
+	verified []string
+}
+
+func (s *spyPasswordService) VerifyPassword(hashedPassword, password string) error {
+	s.verified = append(s.verified, hashedPassword)
+	return s.PasswordService.VerifyPassword(hashedPassword, password)
+}
+
+func newSpyAuthService(users ...*model.User) (*AuthService, *spyPasswordService) {
+	spy := &spyPasswordService{PasswordService: NewPasswordServiceWithCost(4)}
+	jwtService := NewJWTService("test-secret-key", time.Hour)
+	return NewAuthService(jwtService, spy, newMemoryUserRepo(users...)), spy
+}
+
+func TestAuthService_Login_UnknownEmailStillComparesPassword(t *testing.T) {
+	service, spy := newSpyAuthService()
+
+	_, err := service.Login(context.Background(), LoginRequest{
+		Email:    "nobody@example.com",
+		Password: "password123",
+	}, "127.0.0.1")
+
+	assert.EqualError(t, err, "invalid email or password")
+	require.Len(t, spy.verified, 1)
+	assert.NotEmpty(t, spy.verified[0])
+}
+
+func TestAuthService_Login_UnknownEmailMatchesWrongPasswordError(t *testing.T) {
+	hash, err := NewPasswordServiceWithCost(4).HashPassword("password123")
+	require.NoError(t, err)
+	user := &model.User{ID: uuid.New(), Email: "test@example.com", PasswordHash: hash}
+	service, spy := newSpyAuthService(user)
+
+	_, unknownErr := service.Login(context.Background(), LoginRequest{Email: "nobody@example.com", Password: "wrongpass1"}, "127.0.0.1")
+	_, wrongErr := service.Login(context.Background(), LoginRequest{Email: "test@example.com", Password: "wrongpass1"}, "127.0.0.1")
+
+	assert.Equal(t, wrongErr.Error(), unknownErr.Error())
+	assert.Len(t, spy.verified, 2)
+}
+
+func TestAuthService_Login_DummyHashIsReused(t *testing.T) {
+	service, spy := newSpyAuthService()
+
+	for i := 0; i < 2; i++ {
+		_, _ = service.Login(context.Background(), LoginRequest{Email: "nobody@example.com", Password: "password123"}, "127.0.0.1")
+	}
+
+	require.Len(t, spy.verified, 2)
+	assert.Equal(t, spy.verified[0], spy.verified[1])
+}
+
+func TestAuthService_Login_RecordsLogin(t *testing.T) {
+	hash, err := NewPasswordServiceWithCost(4).HashPassword("password123")
+	require.NoError(t, err)
+	user := &model.User{ID: uuid.New(), Email: "test@example.com", PasswordHash: hash}
+	service, _ := newSpyAuthService(user)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx, cancel := context.WithCancel(context.Background())
+
+	_, err = service.Login(ctx, LoginRequest{Email: "test@example.com", Password: "password123"}, "203.0.113.7")
+	require.NoError(t, err)
+	// The request ending doesn't stop the write
+	cancel()
+	service.loginRecords.Wait()
+
+	stored, err := service.userRepo.GetByID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.LastLoginAt)
+	assert.Equal(t, now, *stored.LastLoginAt)
+	require.NotNil(t, stored.LastLoginIP)
+	assert.Equal(t, "203.0.113.7", *stored.LastLoginIP)
+}
+
+func TestAuthService_Login_FailureDoesNotRecordLogin(t *testing.T) {
+	hash, err := NewPasswordServiceWithCost(4).HashPassword("password123")
+	require.NoError(t, err)
+	user := &model.User{ID: uuid.New(), Email: "test@example.com", PasswordHash: hash}
+	service, _ := newSpyAuthService(user)
+
+	_, err = service.Login(context.Background(), LoginRequest{Email: "test@example.com", Password: "wrongpass1"}, "203.0.113.7")
+	require.Error(t, err)
+	service.loginRecords.Wait()
+
+	stored, err := service.userRepo.GetByID(context.Background(), user.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.LastLoginAt)
+	assert.Nil(t, stored.LastLoginIP)
+}
+
+// failingLoginRepo fails every login write
+type failingLoginRepo struct {
+	*memoryUserRepo
+}
+
+func (r failingLoginRepo) RecordLogin(ctx context.Context, id uuid.UUID, at time.Time, ip string) error {
+	return fmt.Errorf("connection refused")
+}
+
+func TestAuthService_Login_SucceedsWhenRecordingFails(t *testing.T) {
+	hash, err := NewPasswordServiceWithCost(4).HashPassword("password123")
+	require.NoError(t, err)
+	repo := failingLoginRepo{newMemoryUserRepo(&model.User{ID: uuid.New(), Email: "test@example.com", PasswordHash: hash})}
+	service := NewAuthService(NewJWTService("test-secret-key", time.Hour), NewPasswordServiceWithCost(4), repo)
+
+	resp, err := service.Login(context.Background(), LoginRequest{Email: "test@example.com", Password: "password123"}, "203.0.113.7")
+	service.loginRecords.Wait()
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+}
+
+func TestAuthService_Register_RejectsExistingEmail(t *testing.T) {
+	existing := &model.User{ID: uuid.New(), Email: "taken@example.com"}
+	service, _ := newSpyAuthService(existing)
+
+	_, err := service.Register(context.Background(), RegisterRequest{
+		Email:    "taken@example.com",
+		Password: "password123",
+		Name:     "Someone Else",
+	}, "127.0.0.1")
+
+	assert.EqualError(t, err, "user with email taken@example.com already exists")
+}
+
+func TestAuthService_NormalizesEmails(t *testing.T) {
+	service, _ := newSpyAuthService()
+	ctx := context.Background()
+
+	registered, err := service.Register(ctx, RegisterRequest{
+		Email:    "  New.User@Example.COM ",
+		Password: "password123",
+		Name:     " New User ",
+	}, "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "new.user@example.com", registered.User.Email)
+	assert.Equal(t, "New User", registered.User.Name)
+
+	_, err = service.Register(ctx, RegisterRequest{
+		Email:    "NEW.USER@example.com",
+		Password: "password123",
+		Name:     "Someone Else",
+	}, "127.0.0.1")
+	assert.EqualError(t, err, "user with email new.user@example.com already exists")
+
+	loggedIn, err := service.Login(ctx, LoginRequest{Email: "New.User@example.com ", Password: "password123"}, "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, registered.User.ID, loggedIn.User.ID)
+}
+
+func TestAuthService_Register_StoresUTCTimestamps(t *testing.T) {
+	serverZone := time.Local
+	time.Local = time.FixedZone("UTC-5", -5*60*60)
+	defer func() { time.Local = serverZone }()
+
+	service, _ := newSpyAuthService()
+
+	resp, err := service.Register(context.Background(), RegisterRequest{
+		Email:    "new@example.com",
+		Password: "password123",
+		Name:     "New User",
+	}, "127.0.0.1")
+	require.NoError(t, err)
+
+	assert.Equal(t, time.UTC, resp.User.CreatedAt.Location())
+	assert.Equal(t, time.UTC, resp.User.UpdatedAt.Location())
+}
+
+// memoryPasswordHistoryRepo is an in-memory PasswordHistoryRepository, oldest first
+type memoryPasswordHistoryRepo struct {
+	hashes map[uuid.UUID][]string
+}
+
+func newMemoryPasswordHistoryRepo() *memoryPasswordHistoryRepo {
+	return &memoryPasswordHistoryRepo{hashes: make(map[uuid.UUID][]string)}
+}
+
+func (r *memoryPasswordHistoryRepo) Add(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	r.hashes[userID] = append(r.hashes[userID], passwordHash)
+	return nil
+}
+
+func (r *memoryPasswordHistoryRepo) GetRecent(ctx context.Context, userID uuid.UUID, limit int) ([]string, error) {
+	hashes := r.hashes[userID]
+	recent := make([]string, 0, limit)
+	for i := len(hashes) - 1; i >= 0 && len(recent) < limit; i-- {
+		recent = append(recent, hashes[i])
+	}
+	return recent, nil
+}
+
+func (r *memoryPasswordHistoryRepo) Prune(ctx context.Context, userID uuid.UUID, keep int) error {
+	if hashes := r.hashes[userID]; len(hashes) > keep {
+		r.hashes[userID] = hashes[len(hashes)-keep:]
+	}
+	return nil
+}
+
+func newPasswordHistoryAuthService(t *testing.T, size int, password string) (*AuthService, *memoryPasswordHistoryRepo, uuid.UUID) {
+	hash, err := NewPasswordServiceWithCost(4).HashPassword(password)
+	require.NoError(t, err)
+	user := &model.User{ID: uuid.New(), Email: "test@example.com", PasswordHash: hash}
+
+	service, _ := newSpyAuthService(user)
+	history := newMemoryPasswordHistoryRepo()
+	service.EnablePasswordHistory(history, size)
+	return service, history, user.ID
+}
+
+func TestAuthService_ChangePassword_RejectsRecentPassword(t *testing.T) {
+	ctx := context.Background()
+	service, history, userID := newPasswordHistoryAuthService(t, 3, "password1")
+
+	require.NoError(t, service.ChangePassword(ctx, userID, "password1", "password2"))
+	require.NoError(t, service.ChangePassword(ctx, userID, "password2", "password3"))
+
+	assert.ErrorIs(t, service.ChangePassword(ctx, userID, "password3", "password1"), ErrPasswordReused)
+	assert.ErrorIs(t, service.ChangePassword(ctx, userID, "password3", "password3"), ErrPasswordReused)
+	assert.Len(t, history.hashes[userID], 2)
+}
+
+func TestAuthService_ChangePassword_AllowsPasswordOutsideHistory(t *testing.T) {
+	ctx := context.Background()
+	service, history, userID := newPasswordHistoryAuthService(t, 3, "password1")
+
+	require.NoError(t, service.ChangePassword(ctx, userID, "password1", "password2"))
+	require.NoError(t, service.ChangePassword(ctx, userID, "password2", "password3"))
+	require.NoError(t, service.ChangePassword(ctx, userID, "password3", "password4"))
+
+	// password1 has been pruned, leaving only password2 and password3
+	assert.Len(t, history.hashes[userID], 2)
+	assert.NoError(t, service.ChangePassword(ctx, userID, "password4", "password1"))
+}
+
+func TestAuthService_ChangePassword_HistoryDisabled(t *testing.T) {
+	ctx := context.Background()
+	service, history, userID := newPasswordHistoryAuthService(t, 0, "password1")
+
+	require.NoError(t, service.ChangePassword(ctx, userID, "password1", "password2"))
+	assert.NoError(t, service.ChangePassword(ctx, userID, "password2", "password1"))
+	assert.NoError(t, service.ChangePassword(ctx, userID, "password1", "password1"))
+	assert.Empty(t, history.hashes)
+}
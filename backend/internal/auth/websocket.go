@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+)
+
+// WebsocketInit authenticates a WebSocket connection with the bearer token in
+// its connection_init payload, since browsers can't set headers on the
+// upgrade request. Without a token the connection keeps whatever the upgrade
+// request was authenticated with, such as the token cookie, which is why the
+// upgrade's origin must be checked; an invalid token refuses the connection.
+func (a *AuthMiddleware) WebsocketInit(ctx context.Context, payload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+	authorization := payload.Authorization()
+	if authorization == "" {
+		return ctx, nil, nil
+	}
+
+	token, err := ExtractTokenFromHeader(authorization)
+	if err != nil {
+		return ctx, nil, fmt.Errorf("invalid authorization in connection_init: %w", err)
+	}
+
+	claims, err := a.jwtService.ValidateToken(token)
+	if err != nil {
+		return ctx, nil, fmt.Errorf("invalid or expired token")
+	}
+
+	user, err := a.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return ctx, nil, fmt.Errorf("user not found")
+	}
+
+	if claims.ImpersonatorID != nil {
+		LogImpersonatedRequest(*claims.ImpersonatorID, user.ID, "WEBSOCKET", "connection_init")
+	}
+	return authenticatedContext(ctx, user, claims, token), nil, nil
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/graph/model"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthMiddleware_WebsocketInit(t *testing.T) {
+	jwtService := NewJWTService("test-secret-key", time.Hour)
+	user := &model.User{ID: uuid.New(), Email: "user@example.com"}
+	token, _, err := jwtService.GenerateToken(user)
+	require.NoError(t, err)
+	middleware := NewAuthMiddleware(jwtService, newMemoryUserRepo(user))
+
+	t.Run("authenticates with the payload token", func(t *testing.T) {
+		ctx, _, err := middleware.WebsocketInit(context.Background(), transport.InitPayload{"Authorization": "Bearer " + token})
+
+		require.NoError(t, err)
+		current, ok := GetUserFromContext(ctx)
+		require.True(t, ok)
+		assert.Equal(t, user.ID, current.ID)
+	})
+
+	t.Run("connections without a token stay anonymous", func(t *testing.T) {
+		ctx, _, err := middleware.WebsocketInit(context.Background(), transport.InitPayload{})
+
+		require.NoError(t, err)
+		_, ok := GetUserFromContext(ctx)
+		assert.False(t, ok)
+	})
+
+	t.Run("invalid tokens refuse the connection", func(t *testing.T) {
+		otherService := NewJWTService("other-secret-key", time.Hour)
+		forged, _, err := otherService.GenerateToken(user)
+		require.NoError(t, err)
+		unknown, _, err := jwtService.GenerateToken(&model.User{ID: uuid.New()})
+		require.NoError(t, err)
+
+		for _, authorization := range []string{"Bearer " + forged, "Bearer " + unknown, token} {
+			_, _, err := middleware.WebsocketInit(context.Background(), transport.InitPayload{"authorization": authorization})
+			assert.Error(t, err)
+		}
+	})
+}
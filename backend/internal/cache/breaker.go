@@ -0,0 +1,202 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the cache while the breaker is open
+var ErrCircuitOpen = errors.New("cache circuit breaker is open")
+
+// breakerState is the state of a CircuitBreakerCache
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// BreakerConfig holds circuit breaker settings
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the breaker
+	FailureThreshold int
+	// Cooldown is how long the breaker stays open before probing the cache again
+	Cooldown time.Duration
+}
+
+// DefaultBreakerConfig returns sensible circuit breaker defaults
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureThreshold: 5,
+		Cooldown:         30 * time.Second,
+	}
+}
+
+// CircuitBreakerCache wraps a Cache so that an unavailable backend fails fast.
+// After FailureThreshold consecutive errors every call returns ErrCircuitOpen
+// for the cooldown; then a single probe call is let through and its outcome
+// either closes the breaker or opens it for another cooldown.
+type CircuitBreakerCache struct {
+	cache  Cache
+	config BreakerConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// NewCircuitBreakerCache creates a new circuit breaker around a cache
+func NewCircuitBreakerCache(cache Cache, config BreakerConfig) *CircuitBreakerCache {
+	return &CircuitBreakerCache{
+		cache:  cache,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// allow reports whether a call may reach the underlying cache
+func (b *CircuitBreakerCache) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.config.Cooldown {
+			return false
+		}
+		// Let exactly one probe through
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		// A probe is already in flight
+		return false
+	default:
+		return true
+	}
+}
+
+// record updates the breaker with the outcome of a call
+func (b *CircuitBreakerCache) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// A miss means the cache answered
+	if err == nil || errors.Is(err, ErrCacheMiss) {
+		if b.state != breakerClosed {
+			fmt.Printf("Cache circuit breaker closed\n")
+		}
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.config.FailureThreshold {
+		if b.state != breakerOpen {
+			fmt.Printf("Cache circuit breaker opened after %d consecutive failures: %v\n", b.failures, err)
+		}
+		b.state = breakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// do runs fn through the breaker
+func (b *CircuitBreakerCache) do(fn func() error) error {
+	if !b.allow() {
+		return ErrCircuitOpen
+	}
+	err := fn()
+	b.record(err)
+	return err
+}
+
+// Set stores a value through the breaker
+func (b *CircuitBreakerCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return b.do(func() error { return b.cache.Set(ctx, key, value, ttl) })
+}
+
+// Get retrieves a value through the breaker
+func (b *CircuitBreakerCache) Get(ctx context.Context, key string, dest interface{}) error {
+	return b.do(func() error { return b.cache.Get(ctx, key, dest) })
+}
+
+// Delete removes a key through the breaker
+func (b *CircuitBreakerCache) Delete(ctx context.Context, key string) error {
+	return b.do(func() error { return b.cache.Delete(ctx, key) })
+}
+
+// Exists checks a key through the breaker
+func (b *CircuitBreakerCache) Exists(ctx context.Context, key string) (bool, error) {
+	var exists bool
+	err := b.do(func() (err error) {
+		exists, err = b.cache.Exists(ctx, key)
+		return err
+	})
+	return exists, err
+}
+
+// DeletePattern deletes matching keys through the breaker
+func (b *CircuitBreakerCache) DeletePattern(ctx context.Context, pattern string) error {
+	return b.do(func() error { return b.cache.DeletePattern(ctx, pattern) })
+}
+
+// SetNX sets a key if absent through the breaker
+func (b *CircuitBreakerCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	var set bool
+	err := b.do(func() (err error) {
+		set, err = b.cache.SetNX(ctx, key, value, ttl)
+		return err
+	})
+	return set, err
+}
+
+// Increment increments a counter through the breaker
+func (b *CircuitBreakerCache) Increment(ctx context.Context, key string) (int64, error) {
+	var n int64
+	err := b.do(func() (err error) {
+		n, err = b.cache.Increment(ctx, key)
+		return err
+	})
+	return n, err
+}
+
+// IncrementWithTTL increments a counter with expiration through the breaker
+func (b *CircuitBreakerCache) IncrementWithTTL(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	var n int64
+	err := b.do(func() (err error) {
+		n, err = b.cache.IncrementWithTTL(ctx, key, ttl)
+		return err
+	})
+	return n, err
+}
+
+// GetMultiple retrieves several values through the breaker
+func (b *CircuitBreakerCache) GetMultiple(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	var values map[string]interface{}
+	err := b.do(func() (err error) {
+		values, err = b.cache.GetMultiple(ctx, keys)
+		return err
+	})
+	return values, err
+}
+
+// SetMultiple stores several values through the breaker
+func (b *CircuitBreakerCache) SetMultiple(ctx context.Context, values map[string]interface{}, ttl time.Duration) error {
+	return b.do(func() error { return b.cache.SetMultiple(ctx, values, ttl) })
+}
+
+// Ping checks the underlying cache directly so health checks see the real state
+func (b *CircuitBreakerCache) Ping(ctx context.Context) error {
+	return b.cache.Ping(ctx)
+}
+
+// Close closes the underlying cache
+func (b *CircuitBreakerCache) Close() error {
+	return b.cache.Close()
+}
@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend/internal/graph/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errRedisDown = errors.New("dial tcp: connection refused")
+
+// flakyCache fails every call while down and counts calls that reach it
+type flakyCache struct {
+	*memoryCache
+	down  bool
+	calls int
+}
+
+func (c *flakyCache) Get(ctx context.Context, key string, dest interface{}) error {
+	c.calls++
+	if c.down {
+		return errRedisDown
+	}
+	return c.memoryCache.Get(ctx, key, dest)
+}
+
+func (c *flakyCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	c.calls++
+	if c.down {
+		return errRedisDown
+	}
+	return c.memoryCache.Set(ctx, key, value, ttl)
+}
+
+// newTestBreaker returns a breaker over a flaky cache with a controllable clock
+func newTestBreaker(threshold int, cooldown time.Duration) (*CircuitBreakerCache, *flakyCache, *time.Time) {
+	flaky := &flakyCache{memoryCache: newMemoryCache()}
+	breaker := NewCircuitBreakerCache(flaky, BreakerConfig{FailureThreshold: threshold, Cooldown: cooldown})
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+	return breaker, flaky, &now
+}
+
+func TestCircuitBreakerCache_TripsAfterConsecutiveFailures(t *testing.T) {
+	ctx := context.Background()
+	breaker, flaky, _ := newTestBreaker(3, time.Minute)
+	flaky.down = true
+
+	var dest string
+	for i := 0; i < 3; i++ {
+		assert.ErrorIs(t, breaker.Get(ctx, "key", &dest), errRedisDown)
+	}
+	assert.Equal(t, 3, flaky.calls)
+
+	// Open: calls fail fast without reaching the cache
+	assert.ErrorIs(t, breaker.Get(ctx, "key", &dest), ErrCircuitOpen)
+	assert.ErrorIs(t, breaker.Set(ctx, "key", "value", time.Minute), ErrCircuitOpen)
+	assert.Equal(t, 3, flaky.calls)
+}
+
+func TestCircuitBreakerCache_MissesAndSuccessesResetFailures(t *testing.T) {
+	ctx := context.Background()
+	breaker, flaky, _ := newTestBreaker(2, time.Minute)
+
+	var dest string
+	flaky.down = true
+	assert.ErrorIs(t, breaker.Get(ctx, "key", &dest), errRedisDown)
+
+	flaky.down = false
+	assert.ErrorIs(t, breaker.Get(ctx, "missing", &dest), ErrCacheMiss)
+
+	flaky.down = true
+	assert.ErrorIs(t, breaker.Get(ctx, "key", &dest), errRedisDown)
+
+	// Only one consecutive failure so far; the breaker is still closed
+	assert.ErrorIs(t, breaker.Get(ctx, "key", &dest), errRedisDown)
+	assert.Equal(t, 4, flaky.calls)
+}
+
+func TestCircuitBreakerCache_HalfOpenRecovery(t *testing.T) {
+	ctx := context.Background()
+	breaker, flaky, now := newTestBreaker(2, time.Minute)
+	flaky.down = true
+
+	var dest string
+	breaker.Get(ctx, "key", &dest)
+	breaker.Get(ctx, "key", &dest)
+	require.ErrorIs(t, breaker.Get(ctx, "key", &dest), ErrCircuitOpen)
+
+	// Redis comes back, but the breaker waits out the cooldown
+	flaky.down = false
+	*now = now.Add(30 * time.Second)
+	assert.ErrorIs(t, breaker.Get(ctx, "key", &dest), ErrCircuitOpen)
+
+	// After the cooldown a probe is let through and closes the breaker
+	*now = now.Add(31 * time.Second)
+	assert.NoError(t, breaker.Set(ctx, "key", "value", time.Minute))
+	assert.NoError(t, breaker.Get(ctx, "key", &dest))
+	assert.Equal(t, "value", dest)
+	assert.Equal(t, 4, flaky.calls)
+}
+
+func TestCircuitBreakerCache_FailedProbeReopens(t *testing.T) {
+	ctx := context.Background()
+	breaker, flaky, now := newTestBreaker(2, time.Minute)
+	flaky.down = true
+
+	var dest string
+	breaker.Get(ctx, "key", &dest)
+	breaker.Get(ctx, "key", &dest)
+
+	*now = now.Add(2 * time.Minute)
+	assert.ErrorIs(t, breaker.Get(ctx, "key", &dest), errRedisDown)
+	assert.Equal(t, 3, flaky.calls)
+
+	// A single failed probe starts a new cooldown
+	assert.ErrorIs(t, breaker.Get(ctx, "key", &dest), ErrCircuitOpen)
+	*now = now.Add(59 * time.Second)
+	assert.ErrorIs(t, breaker.Get(ctx, "key", &dest), ErrCircuitOpen)
+	assert.Equal(t, 3, flaky.calls)
+}
+
+func TestCircuitBreakerCache_RepositoryFallsBackWhileOpen(t *testing.T) {
+	ctx := context.Background()
+	breaker, flaky, _ := newTestBreaker(1, time.Minute)
+	flaky.down = true
+
+	post := &model.Post{ID: uuid.New(), Title: "Served from the database", AuthorID: uuid.New()}
+	repo := NewCachedPostRepository(newStubPostRepo(post), breaker, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		got, err := repo.GetByID(ctx, post.ID)
+		require.NoError(t, err)
+		assert.Equal(t, post.Title, got.Title)
+	}
+
+	// Only the first lookup touched Redis; the rest short-circuited
+	assert.Equal(t, 1, flaky.calls)
+}
@@ -8,11 +8,22 @@ import (
 	"fmt"
 	"time"
 
-	"backend/graph/model"
+	"backend/internal/graph/model"
+	"backend/internal/logging"
 	"backend/internal/repository"
 	"github.com/google/uuid"
 )
 
+// getCached reads key into dest, recording the hit or miss in the operation stats
+func getCached(ctx context.Context, c Cache, key string, dest interface{}) bool {
+	if err := c.Get(ctx, key, dest); err != nil {
+		logging.StatsFromContext(ctx).AddCacheMisses(1)
+		return false
+	}
+	logging.StatsFromContext(ctx).AddCacheHits(1)
+	return true
+}
+
 // CachedUserRepository wraps UserRepository with caching
 type CachedUserRepository struct {
 	repo  repository.UserRepository
@@ -37,13 +48,16 @@ func (r *CachedUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*mode
 	
 	// Try to get from cache first
 	var user model.User
-	if err := r.cache.Get(ctx, key, &user); err == nil {
+	if getCached(ctx, r.cache, key, &user) {
 		return &user, nil
 	}
 	
 	// Cache miss, get from repository
 	userPtr, err := r.repo.GetByID(ctx, id)
 	if err != nil {
+		if serveStale(ctx, r.cache, key, &user, err) {
+			return &user, nil
+		}
 		return nil, err
 	}
 	
@@ -75,6 +89,7 @@ func (r *CachedUserRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([
 	cached, err := r.cache.GetMultiple(ctx, keys)
 	if err != nil {
 		// If cache fails, fall back to repository
+		logging.StatsFromContext(ctx).AddCacheMisses(len(ids))
 		return r.repo.GetByIDs(ctx, ids)
 	}
 
@@ -91,9 +106,9 @@ func (r *CachedUserRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([
 				if err := json.Unmarshal(userBytes, &user); err == nil {
 					userMap[keyToID[key]] = &user
 				}
-			} else if userMap, ok := value.(map[string]interface{}); ok {
+			} else if fields, ok := value.(map[string]interface{}); ok {
 				// Handle map from cache (JSON unmarshaled)
-				user := convertMapToUser(userMap)
+				user := convertMapToUser(fields)
 				if user != nil {
 					userMap[keyToID[key]] = user
 				}
@@ -107,6 +122,8 @@ func (r *CachedUserRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([
 			missingIDs = append(missingIDs, id)
 		}
 	}
+	logging.StatsFromContext(ctx).AddCacheHits(len(ids) - len(missingIDs))
+	logging.StatsFromContext(ctx).AddCacheMisses(len(missingIDs))
 
 	// Fetch missing users from repository
 	if len(missingIDs) > 0 {
@@ -173,6 +190,48 @@ func (r *CachedUserRepository) Update(ctx context.Context, user *model.User) err
 	return nil
 }
 
+// UpdateEmail updates a user's email and evicts the cached user
+func (r *CachedUserRepository) UpdateEmail(ctx context.Context, id uuid.UUID, email string) error {
+	if err := r.repo.UpdateEmail(ctx, id, email); err != nil {
+		return err
+	}
+	
+	key := r.keys.User(id.String())
+	if err := r.cache.Delete(ctx, key); err != nil {
+		fmt.Printf("Failed to delete cached user %s: %v\n", id, err)
+	}
+	
+	return nil
+}
+
+// UpdatePassword updates a user's password hash and evicts the cached user
+func (r *CachedUserRepository) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	if err := r.repo.UpdatePassword(ctx, id, passwordHash); err != nil {
+		return err
+	}
+	
+	key := r.keys.User(id.String())
+	if err := r.cache.Delete(ctx, key); err != nil {
+		fmt.Printf("Failed to delete cached user %s: %v\n", id, err)
+	}
+	
+	return nil
+}
+
+// RecordLogin records a login and drops the cached user, whose login time changed
+func (r *CachedUserRepository) RecordLogin(ctx context.Context, id uuid.UUID, at time.Time, ip string) error {
+	if err := r.repo.RecordLogin(ctx, id, at, ip); err != nil {
+		return err
+	}
+	
+	key := r.keys.User(id.String())
+	if err := r.cache.Delete(ctx, key); err != nil {
+		fmt.Printf("Failed to delete cached user %s: %v\n", id, err)
+	}
+	
+	return nil
+}
+
 // Delete deletes a user and removes from cache
 func (r *CachedUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	if err := r.repo.Delete(ctx, id); err != nil {
@@ -185,6 +244,13 @@ func (r *CachedUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 		fmt.Printf("Failed to delete cached user %s: %v\n", id, err)
 	}
 	
+	// The user's posts are removed by the foreign key cascade, so any
+	// cached post lists that may include them are now stale
+	invalidatePatterns(ctx, r.cache,
+		r.keys.PostsByAuthorPattern(id.String()),
+		r.keys.PostsListPattern(),
+	)
+	
 	return nil
 }
 
@@ -193,6 +259,36 @@ func (r *CachedUserRepository) GetByEmail(ctx context.Context, email string) (*m
 	return r.repo.GetByEmail(ctx, email)
 }
 
+// ExistsByIDs checks user existence (not cached so new users are seen immediately)
+func (r *CachedUserRepository) ExistsByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	return r.repo.ExistsByIDs(ctx, ids)
+}
+
+// ExistsByEmail checks email existence (not cached for security)
+func (r *CachedUserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	return r.repo.ExistsByEmail(ctx, email)
+}
+
+// ExistsByEmails checks email existence (not cached for security)
+func (r *CachedUserRepository) ExistsByEmails(ctx context.Context, emails []string) (map[string]bool, error) {
+	return r.repo.ExistsByEmails(ctx, emails)
+}
+
+// SearchUsers searches users by name, and by email when includeEmail is set (not cached)
+func (r *CachedUserRepository) SearchUsers(ctx context.Context, query string, includeEmail bool, limit int) ([]*model.User, error) {
+	return r.repo.SearchUsers(ctx, query, includeEmail, limit)
+}
+
+// Count counts users (not cached)
+func (r *CachedUserRepository) Count(ctx context.Context) (int, error) {
+	return r.repo.Count(ctx)
+}
+
+// CountCreatedSince counts users who signed up since the given time (not cached)
+func (r *CachedUserRepository) CountCreatedSince(ctx context.Context, since time.Time) (int, error) {
+	return r.repo.CountCreatedSince(ctx, since)
+}
+
 // List retrieves users with caching
 func (r *CachedUserRepository) List(ctx context.Context, limit, offset int) ([]*model.User, error) {
 	// Create a cache key based on parameters
@@ -200,13 +296,16 @@ func (r *CachedUserRepository) List(ctx context.Context, limit, offset int) ([]*
 	
 	// Try cache first
 	var users []*model.User
-	if err := r.cache.Get(ctx, key, &users); err == nil {
+	if getCached(ctx, r.cache, key, &users) {
 		return users, nil
 	}
 	
 	// Cache miss, get from repository
 	users, err := r.repo.List(ctx, limit, offset)
 	if err != nil {
+		if serveStale(ctx, r.cache, key, &users, err) {
+			return users, nil
+		}
 		return nil, err
 	}
 	
@@ -219,6 +318,346 @@ func (r *CachedUserRepository) List(ctx context.Context, limit, offset int) ([]*
 	return users, nil
 }
 
+// Reset drops every cached user and list of users
+func (r *CachedUserRepository) Reset(ctx context.Context) error {
+	return resetPatterns(ctx, r.cache, r.keys.UserPattern(), r.keys.UsersPattern())
+}
+
+// CachedPostRepository wraps PostRepository with caching
+type CachedPostRepository struct {
+	repo  repository.PostRepository
+	cache Cache
+	keys  *CacheKey
+	ttl   time.Duration
+}
+
+// NewCachedPostRepository creates a new cached post repository
+func NewCachedPostRepository(repo repository.PostRepository, cache Cache, ttl time.Duration) *CachedPostRepository {
+	return &CachedPostRepository{
+		repo:  repo,
+		cache: cache,
+		keys:  NewCacheKey("graphql"),
+		ttl:   ttl,
+	}
+}
+
+// Create creates a new post and invalidates the lists it now belongs to
+func (r *CachedPostRepository) Create(ctx context.Context, post *model.Post) error {
+	if err := r.repo.Create(ctx, post); err != nil {
+		return err
+	}
+
+	key := r.keys.Post(post.ID.String())
+	if err := r.cache.Set(ctx, key, post, r.ttl); err != nil {
+		fmt.Printf("Failed to cache new post %s: %v\n", post.ID, err)
+	}
+
+	r.invalidateLists(ctx, post.AuthorID)
+	return nil
+}
+
+// GetByID retrieves a post by ID with caching
+func (r *CachedPostRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Post, error) {
+	key := r.keys.Post(id.String())
+
+	var post model.Post
+	if getCached(ctx, r.cache, key, &post) {
+		return &post, nil
+	}
+
+	postPtr, err := r.repo.GetByID(ctx, id)
+	if err != nil {
+		if serveStale(ctx, r.cache, key, &post, err) {
+			return &post, nil
+		}
+		return nil, err
+	}
+
+	if err := r.cache.Set(ctx, key, postPtr, r.ttl); err != nil {
+		fmt.Printf("Failed to cache post %s: %v\n", id, err)
+	}
+
+	return postPtr, nil
+}
+
+// Exists checks post existence (not cached, so a deleted post is never reported)
+func (r *CachedPostRepository) Exists(ctx context.Context, id uuid.UUID) (bool, error) {
+	return r.repo.Exists(ctx, id)
+}
+
+// GetByIDs retrieves multiple posts by IDs (not cached, used by DataLoader)
+func (r *CachedPostRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Post, error) {
+	return r.repo.GetByIDs(ctx, ids)
+}
+
+// GetByAuthorID retrieves posts by author with caching
+func (r *CachedPostRepository) GetByAuthorID(ctx context.Context, authorID uuid.UUID, limit, offset int) ([]*model.Post, error) {
+	key := r.keys.PostsByAuthor(authorID.String(), limit, offset)
+
+	var posts []*model.Post
+	if getCached(ctx, r.cache, key, &posts) {
+		return posts, nil
+	}
+
+	posts, err := r.repo.GetByAuthorID(ctx, authorID, limit, offset)
+	if err != nil {
+		if serveStale(ctx, r.cache, key, &posts, err) {
+			return posts, nil
+		}
+		return nil, err
+	}
+
+	if err := r.cache.Set(ctx, key, posts, r.ttl/2); err != nil {
+		fmt.Printf("Failed to cache posts by author %s: %v\n", authorID, err)
+	}
+
+	return posts, nil
+}
+
+// GetPublishedByAuthorIDs retrieves each author's newest posts (not cached, used by DataLoader)
+func (r *CachedPostRepository) GetPublishedByAuthorIDs(ctx context.Context, authorIDs []uuid.UUID, limit int) (map[uuid.UUID][]*model.Post, error) {
+	return r.repo.GetPublishedByAuthorIDs(ctx, authorIDs, limit)
+}
+
+// Update updates a post and invalidates the lists of both the previous
+// and the current author, since moderation may have reassigned it
+func (r *CachedPostRepository) Update(ctx context.Context, post *model.Post) error {
+	previousAuthorID := post.AuthorID
+	if previous, err := r.GetByID(ctx, post.ID); err == nil {
+		previousAuthorID = previous.AuthorID
+	}
+
+	if err := r.repo.Update(ctx, post); err != nil {
+		return err
+	}
+
+	key := r.keys.Post(post.ID.String())
+	if err := r.cache.Set(ctx, key, post, r.ttl); err != nil {
+		fmt.Printf("Failed to update cached post %s: %v\n", post.ID, err)
+	}
+
+	if previousAuthorID != post.AuthorID {
+		r.invalidateLists(ctx, previousAuthorID, post.AuthorID)
+	} else {
+		r.invalidateLists(ctx, post.AuthorID)
+	}
+
+	return nil
+}
+
+// Delete deletes a post and removes it from cache
+func (r *CachedPostRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	existing, lookupErr := r.GetByID(ctx, id)
+
+	if err := r.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	key := r.keys.Post(id.String())
+	if err := r.cache.Delete(ctx, key); err != nil {
+		fmt.Printf("Failed to delete cached post %s: %v\n", id, err)
+	}
+
+	if lookupErr == nil {
+		r.invalidateLists(ctx, existing.AuthorID)
+	} else {
+		r.invalidateLists(ctx)
+	}
+
+	return nil
+}
+
+// DeletePostWithComments deletes a post and its comments and invalidates the
+// cache. A dry run changes nothing, so the cache is kept.
+func (r *CachedPostRepository) DeletePostWithComments(ctx context.Context, id uuid.UUID, dryRun bool) (int, error) {
+	if dryRun {
+		return r.repo.DeletePostWithComments(ctx, id, true)
+	}
+
+	existing, lookupErr := r.GetByID(ctx, id)
+
+	deletedComments, err := r.repo.DeletePostWithComments(ctx, id, false)
+	if err != nil {
+		return 0, err
+	}
+
+	key := r.keys.Post(id.String())
+	if err := r.cache.Delete(ctx, key); err != nil {
+		fmt.Printf("Failed to delete cached post %s: %v\n", id, err)
+	}
+
+	if lookupErr == nil {
+		r.invalidateLists(ctx, existing.AuthorID)
+	} else {
+		r.invalidateLists(ctx)
+	}
+
+	return deletedComments, nil
+}
+
+// MergeTags merges tags and drops the cached copies of every changed post.
+// A dry run changes nothing, so it leaves the cache alone.
+func (r *CachedPostRepository) MergeTags(ctx context.Context, from, into string, dryRun bool) ([]uuid.UUID, error) {
+	ids, err := r.repo.MergeTags(ctx, from, into, dryRun)
+	if err != nil || dryRun {
+		return ids, err
+	}
+
+	for _, id := range ids {
+		key := r.keys.Post(id.String())
+		if err := r.cache.Delete(ctx, key); err != nil {
+			fmt.Printf("Failed to delete cached post %s: %v\n", id, err)
+		}
+	}
+
+	if len(ids) > 0 {
+		invalidatePatterns(ctx, r.cache, r.keys.PostsListPattern(), r.keys.PostsByAuthorPattern("*"))
+	}
+
+	return ids, nil
+}
+
+// SetHidden changes a post's visibility and drops every cached copy of it
+func (r *CachedPostRepository) SetHidden(ctx context.Context, id uuid.UUID, hidden bool, reason *string) error {
+	existing, lookupErr := r.GetByID(ctx, id)
+
+	if err := r.repo.SetHidden(ctx, id, hidden, reason); err != nil {
+		return err
+	}
+
+	key := r.keys.Post(id.String())
+	if err := r.cache.Delete(ctx, key); err != nil {
+		fmt.Printf("Failed to delete cached post %s: %v\n", id, err)
+	}
+
+	if lookupErr == nil {
+		r.invalidateLists(ctx, existing.AuthorID)
+	} else {
+		r.invalidateLists(ctx)
+	}
+
+	return nil
+}
+
+// SetPublished changes a post's published state and drops every cached copy of it
+func (r *CachedPostRepository) SetPublished(ctx context.Context, id uuid.UUID, published bool, publishAt *time.Time) error {
+	existing, lookupErr := r.GetByID(ctx, id)
+
+	if err := r.repo.SetPublished(ctx, id, published, publishAt); err != nil {
+		return err
+	}
+
+	key := r.keys.Post(id.String())
+	if err := r.cache.Delete(ctx, key); err != nil {
+		fmt.Printf("Failed to delete cached post %s: %v\n", id, err)
+	}
+
+	if lookupErr == nil {
+		r.invalidateLists(ctx, existing.AuthorID)
+	} else {
+		r.invalidateLists(ctx)
+	}
+
+	return nil
+}
+
+// List retrieves posts with filters and caching
+func (r *CachedPostRepository) List(ctx context.Context, filters *repository.PostFilters, limit, offset int) ([]*model.Post, error) {
+	key := r.keys.PostsList(filtersKey(filters), limit, offset)
+
+	var posts []*model.Post
+	if getCached(ctx, r.cache, key, &posts) {
+		return posts, nil
+	}
+
+	posts, err := r.repo.List(ctx, filters, limit, offset)
+	if err != nil {
+		if serveStale(ctx, r.cache, key, &posts, err) {
+			return posts, nil
+		}
+		return nil, err
+	}
+
+	if err := r.cache.Set(ctx, key, posts, r.ttl/2); err != nil {
+		fmt.Printf("Failed to cache post list: %v\n", err)
+	}
+
+	return posts, nil
+}
+
+// ListWithAuthors lists posts with their authors joined in (not cached, since
+// the authors can change independently of the posts)
+func (r *CachedPostRepository) ListWithAuthors(ctx context.Context, filters *repository.PostFilters, limit, offset int) ([]*model.Post, error) {
+	return r.repo.ListWithAuthors(ctx, filters, limit, offset)
+}
+
+// Search searches posts (not cached, results depend on free-form input)
+func (r *CachedPostRepository) Search(ctx context.Context, query string, limit int) ([]*model.Post, error) {
+	return r.repo.Search(ctx, query, limit)
+}
+
+// Count counts posts with filters
+func (r *CachedPostRepository) Count(ctx context.Context, filters *repository.PostFilters) (int, error) {
+	return r.repo.Count(ctx, filters)
+}
+
+// CountByPublished counts published and unpublished posts
+func (r *CachedPostRepository) CountByPublished(ctx context.Context) (int, int, error) {
+	return r.repo.CountByPublished(ctx)
+}
+
+// PopularTags reads tag counts straight from the repository
+func (r *CachedPostRepository) PopularTags(ctx context.Context, limit, offset int) ([]*model.TagCount, error) {
+	return r.repo.PopularTags(ctx, limit, offset)
+}
+
+// Reset drops every cached post and list of posts
+func (r *CachedPostRepository) Reset(ctx context.Context) error {
+	return resetPatterns(ctx, r.cache, r.keys.PostPattern(), r.keys.PostsPattern())
+}
+
+// invalidateLists drops the cached post lists and the per-author lists
+// of the given authors
+func (r *CachedPostRepository) invalidateLists(ctx context.Context, authorIDs ...uuid.UUID) {
+	patterns := []string{r.keys.PostsListPattern()}
+	for _, authorID := range authorIDs {
+		patterns = append(patterns, r.keys.PostsByAuthorPattern(authorID.String()))
+	}
+	invalidatePatterns(ctx, r.cache, patterns...)
+}
+
+// invalidatePatterns deletes every key matching the given patterns,
+// logging failures rather than failing the write that triggered them
+func invalidatePatterns(ctx context.Context, cache Cache, patterns ...string) {
+	for _, pattern := range patterns {
+		if err := cache.DeletePattern(ctx, pattern); err != nil {
+			fmt.Printf("Failed to invalidate cache pattern %s: %v\n", pattern, err)
+		}
+	}
+}
+
+// resetPatterns deletes every key matching the given patterns, stopping at
+// the first failure
+func resetPatterns(ctx context.Context, cache Cache, patterns ...string) error {
+	for _, pattern := range patterns {
+		if err := cache.DeletePattern(ctx, pattern); err != nil {
+			return fmt.Errorf("failed to reset cache pattern %s: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// filtersKey builds a stable cache key fragment for a set of post filters
+func filtersKey(filters *repository.PostFilters) string {
+	if filters == nil {
+		return "none"
+	}
+
+	data, _ := json.Marshal(filters)
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
 // Helper function to convert map to User struct
 func convertMapToUser(m map[string]interface{}) *model.User {
 	user := &model.User{}
@@ -241,6 +680,10 @@ func convertMapToUser(m map[string]interface{}) *model.User {
 		user.Avatar = &avatar
 	}
 	
+	if role, ok := m["role"].(string); ok {
+		user.Role = role
+	}
+	
 	// Add other fields as needed...
 	
 	return user
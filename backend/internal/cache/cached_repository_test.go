@@ -0,0 +1,470 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"testing"
+	"time"
+
+	"backend/internal/graph/model"
+	"backend/internal/logging"
+	"backend/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCache is an in-memory Cache used to observe cached repositories
+type memoryCache struct {
+	data     map[string][]byte
+	patterns []string
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{data: make(map[string][]byte)}
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.data[key] = data
+	return nil
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string, dest interface{}) error {
+	data, ok := c.data[key]
+	if !ok {
+		return ErrCacheMiss
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (c *memoryCache) Delete(ctx context.Context, key string) error {
+	delete(c.data, key)
+	return nil
+}
+
+func (c *memoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	_, ok := c.data[key]
+	return ok, nil
+}
+
+func (c *memoryCache) DeletePattern(ctx context.Context, pattern string) error {
+	c.patterns = append(c.patterns, pattern)
+	for key := range c.data {
+		if matched, _ := path.Match(pattern, key); matched {
+			delete(c.data, key)
+		}
+	}
+	return nil
+}
+
+func (c *memoryCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	if _, ok := c.data[key]; ok {
+		return false, nil
+	}
+	return true, c.Set(ctx, key, value, ttl)
+}
+
+func (c *memoryCache) Increment(ctx context.Context, key string) (int64, error) {
+	var n int64
+	_ = c.Get(ctx, key, &n)
+	n++
+	return n, c.Set(ctx, key, n, 0)
+}
+
+func (c *memoryCache) IncrementWithTTL(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return c.Increment(ctx, key)
+}
+
+func (c *memoryCache) GetMultiple(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	for _, key := range keys {
+		var value interface{}
+		if err := c.Get(ctx, key, &value); err == nil {
+			result[key] = value
+		}
+	}
+	return result, nil
+}
+
+func (c *memoryCache) SetMultiple(ctx context.Context, values map[string]interface{}, ttl time.Duration) error {
+	for key, value := range values {
+		if err := c.Set(ctx, key, value, ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *memoryCache) Ping(ctx context.Context) error { return nil }
+func (c *memoryCache) Close() error                   { return nil }
+
+// stubPostRepo is an in-memory PostRepository; unimplemented methods panic
+type stubPostRepo struct {
+	repository.PostRepository
+	posts     map[uuid.UUID]*model.Post
+	getCalls  int
+	listCalls int
+}
+
+func newStubPostRepo(posts ...*model.Post) *stubPostRepo {
+	repo := &stubPostRepo{posts: make(map[uuid.UUID]*model.Post)}
+	for _, post := range posts {
+		copied := *post
+		repo.posts[post.ID] = &copied
+	}
+	return repo
+}
+
+func (s *stubPostRepo) Create(ctx context.Context, post *model.Post) error {
+	copied := *post
+	s.posts[post.ID] = &copied
+	return nil
+}
+
+func (s *stubPostRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Post, error) {
+	s.getCalls++
+	post, ok := s.posts[id]
+	if !ok {
+		return nil, fmt.Errorf("post not found")
+	}
+	copied := *post
+	return &copied, nil
+}
+
+func (s *stubPostRepo) Update(ctx context.Context, post *model.Post) error {
+	if _, ok := s.posts[post.ID]; !ok {
+		return fmt.Errorf("post not found")
+	}
+	copied := *post
+	s.posts[post.ID] = &copied
+	return nil
+}
+
+func (s *stubPostRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, ok := s.posts[id]; !ok {
+		return fmt.Errorf("post not found")
+	}
+	delete(s.posts, id)
+	return nil
+}
+
+func (s *stubPostRepo) List(ctx context.Context, filters *repository.PostFilters, limit, offset int) ([]*model.Post, error) {
+	s.listCalls++
+	posts := make([]*model.Post, 0, len(s.posts))
+	for _, post := range s.posts {
+		posts = append(posts, post)
+	}
+	return posts, nil
+}
+
+func (s *stubPostRepo) ListWithAuthors(ctx context.Context, filters *repository.PostFilters, limit, offset int) ([]*model.Post, error) {
+	return s.List(ctx, filters, limit, offset)
+}
+
+func (s *stubPostRepo) SetPublished(ctx context.Context, id uuid.UUID, published bool, publishAt *time.Time) error {
+	post, ok := s.posts[id]
+	if !ok {
+		return fmt.Errorf("post not found")
+	}
+	post.Published = published
+	return nil
+}
+
+func (s *stubPostRepo) MergeTags(ctx context.Context, from, into string, dryRun bool) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	for id, post := range s.posts {
+		for i, tag := range post.Tags {
+			if tag == from {
+				if !dryRun {
+					post.Tags[i] = into
+				}
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	return ids, nil
+}
+
+func (s *stubPostRepo) DeletePostWithComments(ctx context.Context, id uuid.UUID, dryRun bool) (int, error) {
+	if !dryRun {
+		delete(s.posts, id)
+	}
+	return 0, nil
+}
+
+// stubUserRepo is a UserRepository that only supports Delete
+type stubUserRepo struct {
+	repository.UserRepository
+}
+
+func (s *stubUserRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func TestCachedPostRepository_UpdateInvalidatesPreviousAndNewAuthor(t *testing.T) {
+	ctx := context.Background()
+	previousAuthor := uuid.New()
+	newAuthor := uuid.New()
+	post := &model.Post{ID: uuid.New(), Title: "Moderated", AuthorID: previousAuthor}
+
+	memCache := newMemoryCache()
+	repo := NewCachedPostRepository(newStubPostRepo(post), memCache, time.Minute)
+	keys := NewCacheKey("graphql")
+
+	reassigned := *post
+	reassigned.AuthorID = newAuthor
+	require.NoError(t, repo.Update(ctx, &reassigned))
+
+	assert.ElementsMatch(t, []string{
+		keys.PostsListPattern(),
+		keys.PostsByAuthorPattern(previousAuthor.String()),
+		keys.PostsByAuthorPattern(newAuthor.String()),
+	}, memCache.patterns)
+}
+
+func TestCachedPostRepository_DeleteInvalidatesAuthorLists(t *testing.T) {
+	ctx := context.Background()
+	authorID := uuid.New()
+	post := &model.Post{ID: uuid.New(), Title: "Doomed", AuthorID: authorID}
+
+	memCache := newMemoryCache()
+	repo := NewCachedPostRepository(newStubPostRepo(post), memCache, time.Minute)
+	keys := NewCacheKey("graphql")
+
+	memCache.data[keys.PostsByAuthor(authorID.String(), 10, 0)] = []byte("[]")
+	memCache.data[keys.PostsList("none", 20, 0)] = []byte("[]")
+
+	require.NoError(t, repo.Delete(ctx, post.ID))
+
+	assert.ElementsMatch(t, []string{
+		keys.PostsListPattern(),
+		keys.PostsByAuthorPattern(authorID.String()),
+	}, memCache.patterns)
+	assert.Empty(t, memCache.data)
+}
+
+func TestCachedPostRepository_SetPublishedDropsCachedCopies(t *testing.T) {
+	ctx := context.Background()
+	authorID := uuid.New()
+	post := &model.Post{ID: uuid.New(), Title: "Draft", AuthorID: authorID}
+
+	memCache := newMemoryCache()
+	repo := NewCachedPostRepository(newStubPostRepo(post), memCache, time.Minute)
+	keys := NewCacheKey("graphql")
+
+	// Cache the draft, then publish it
+	_, err := repo.GetByID(ctx, post.ID)
+	require.NoError(t, err)
+	require.Contains(t, memCache.data, keys.Post(post.ID.String()))
+
+	now := time.Now()
+	require.NoError(t, repo.SetPublished(ctx, post.ID, true, &now))
+
+	assert.NotContains(t, memCache.data, keys.Post(post.ID.String()))
+	assert.ElementsMatch(t, []string{
+		keys.PostsListPattern(),
+		keys.PostsByAuthorPattern(authorID.String()),
+	}, memCache.patterns)
+
+	published, err := repo.GetByID(ctx, post.ID)
+	require.NoError(t, err)
+	assert.True(t, published.Published)
+}
+
+func TestCachedPostRepository_MergeTagsDropsChangedPosts(t *testing.T) {
+	ctx := context.Background()
+	tagged := &model.Post{ID: uuid.New(), Title: "Tagged", AuthorID: uuid.New(), Tags: []string{"go-lang"}}
+	other := &model.Post{ID: uuid.New(), Title: "Other", AuthorID: uuid.New(), Tags: []string{"rust"}}
+
+	memCache := newMemoryCache()
+	repo := NewCachedPostRepository(newStubPostRepo(tagged, other), memCache, time.Minute)
+	keys := NewCacheKey("graphql")
+
+	_, err := repo.GetByID(ctx, tagged.ID)
+	require.NoError(t, err)
+	_, err = repo.GetByID(ctx, other.ID)
+	require.NoError(t, err)
+
+	ids, err := repo.MergeTags(ctx, "go-lang", "golang", false)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{tagged.ID}, ids)
+
+	assert.NotContains(t, memCache.data, keys.Post(tagged.ID.String()))
+	assert.Contains(t, memCache.data, keys.Post(other.ID.String()))
+	assert.Contains(t, memCache.patterns, keys.PostsListPattern())
+
+	merged, err := repo.GetByID(ctx, tagged.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"golang"}, merged.Tags)
+}
+
+func TestCachedPostRepository_MergeTagsDryRunKeepsCache(t *testing.T) {
+	ctx := context.Background()
+	tagged := &model.Post{ID: uuid.New(), Title: "Tagged", AuthorID: uuid.New(), Tags: []string{"go-lang"}}
+
+	memCache := newMemoryCache()
+	repo := NewCachedPostRepository(newStubPostRepo(tagged), memCache, time.Minute)
+	keys := NewCacheKey("graphql")
+
+	_, err := repo.GetByID(ctx, tagged.ID)
+	require.NoError(t, err)
+
+	ids, err := repo.MergeTags(ctx, "go-lang", "golang", true)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{tagged.ID}, ids)
+	assert.Contains(t, memCache.data, keys.Post(tagged.ID.String()))
+	assert.NotContains(t, memCache.patterns, keys.PostsListPattern())
+}
+
+func TestCachedPostRepository_GetByIDKeepsPublishAt(t *testing.T) {
+	ctx := context.Background()
+	publishAt := time.Date(2024, 3, 10, 9, 30, 0, 0, time.UTC)
+	post := &model.Post{ID: uuid.New(), Title: "Published", AuthorID: uuid.New(), Published: true, PublishAt: &publishAt}
+
+	stub := newStubPostRepo(post)
+	repo := NewCachedPostRepository(stub, newMemoryCache(), time.Minute)
+
+	_, err := repo.GetByID(ctx, post.ID)
+	require.NoError(t, err)
+	cached, err := repo.GetByID(ctx, post.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, stub.getCalls, "second read missed the cache")
+	require.NotNil(t, cached.PublishAt)
+	assert.True(t, publishAt.Equal(*cached.PublishAt))
+}
+
+func TestCachedPostRepository_DeletePostWithComments(t *testing.T) {
+	ctx := context.Background()
+	post := &model.Post{ID: uuid.New(), Title: "Doomed", AuthorID: uuid.New()}
+
+	memCache := newMemoryCache()
+	repo := NewCachedPostRepository(newStubPostRepo(post), memCache, time.Minute)
+	keys := NewCacheKey("graphql")
+
+	_, err := repo.GetByID(ctx, post.ID)
+	require.NoError(t, err)
+
+	_, err = repo.DeletePostWithComments(ctx, post.ID, true)
+	require.NoError(t, err)
+	assert.Contains(t, memCache.data, keys.Post(post.ID.String()), "dry run evicted the post")
+
+	_, err = repo.DeletePostWithComments(ctx, post.ID, false)
+	require.NoError(t, err)
+	assert.NotContains(t, memCache.data, keys.Post(post.ID.String()))
+	assert.Contains(t, memCache.patterns, keys.PostsListPattern())
+}
+
+func TestCachedPostRepository_ListRefetchedAfterWrite(t *testing.T) {
+	ctx := context.Background()
+	post := &model.Post{ID: uuid.New(), Title: "Listed", AuthorID: uuid.New()}
+
+	stub := newStubPostRepo(post)
+	repo := NewCachedPostRepository(stub, newMemoryCache(), time.Minute)
+
+	_, err := repo.List(ctx, nil, 20, 0)
+	require.NoError(t, err)
+	_, err = repo.List(ctx, nil, 20, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stub.listCalls)
+
+	updated := *post
+	updated.Title = "Listed again"
+	require.NoError(t, repo.Update(ctx, &updated))
+
+	_, err = repo.List(ctx, nil, 20, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stub.listCalls)
+}
+
+func TestCachedUserRepository_DeleteInvalidatesAuthoredPosts(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	memCache := newMemoryCache()
+	repo := NewCachedUserRepository(&stubUserRepo{}, memCache, time.Minute)
+	keys := NewCacheKey("graphql")
+
+	require.NoError(t, repo.Delete(ctx, userID))
+
+	assert.ElementsMatch(t, []string{
+		keys.PostsByAuthorPattern(userID.String()),
+		keys.PostsListPattern(),
+	}, memCache.patterns)
+}
+
+func TestCachedPostRepository_ResetRefetchesEverything(t *testing.T) {
+	ctx := context.Background()
+	post := &model.Post{ID: uuid.New(), Title: "Cached", AuthorID: uuid.New()}
+
+	stub := newStubPostRepo(post)
+	memCache := newMemoryCache()
+	repo := NewCachedPostRepository(stub, memCache, time.Minute)
+	keys := NewCacheKey("graphql")
+	require.NoError(t, memCache.Set(ctx, keys.RateLimit("client"), 1, time.Minute))
+
+	load := func() {
+		_, err := repo.GetByID(ctx, post.ID)
+		require.NoError(t, err)
+		_, err = repo.List(ctx, nil, 20, 0)
+		require.NoError(t, err)
+	}
+
+	load()
+	load()
+	assert.Equal(t, 1, stub.getCalls)
+	assert.Equal(t, 1, stub.listCalls)
+
+	require.NoError(t, repo.Reset(ctx))
+	assert.Equal(t, []string{keys.RateLimit("client")}, mapKeys(memCache.data), "only post keys are dropped")
+
+	load()
+	assert.Equal(t, 2, stub.getCalls)
+	assert.Equal(t, 2, stub.listCalls)
+}
+
+func TestCachedUserRepository_ResetDropsUsers(t *testing.T) {
+	ctx := context.Background()
+	memCache := newMemoryCache()
+	repo := NewCachedUserRepository(&stubUserRepo{}, memCache, time.Minute)
+	keys := NewCacheKey("graphql")
+
+	for _, key := range []string{keys.User(uuid.New().String()), "graphql:users:list:20:0", keys.Post(uuid.New().String())} {
+		require.NoError(t, memCache.Set(ctx, key, "cached", time.Minute))
+	}
+
+	require.NoError(t, repo.Reset(ctx))
+
+	assert.Len(t, memCache.data, 1)
+	assert.ElementsMatch(t, []string{keys.UserPattern(), keys.UsersPattern()}, memCache.patterns)
+}
+
+// mapKeys returns the keys of m
+func mapKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestCachedPostRepository_RecordsCacheHitsAndMisses(t *testing.T) {
+	post := &model.Post{ID: uuid.New(), Title: "Counted"}
+	repo := NewCachedPostRepository(newStubPostRepo(post), newMemoryCache(), time.Minute)
+	ctx, stats := logging.WithOperationStats(context.Background())
+
+	_, err := repo.GetByID(ctx, post.ID)
+	require.NoError(t, err)
+	_, err = repo.GetByID(ctx, post.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), stats.CacheMisses())
+	assert.Equal(t, int64(1), stats.CacheHits())
+}
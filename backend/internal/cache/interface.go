@@ -65,6 +65,36 @@ func (ck *CacheKey) PostsList(filters string, limit, offset int) string {
 	return fmt.Sprintf("%s:posts:list:%s:%d:%d", ck.Prefix, filters, limit, offset)
 }
 
+// PostsByAuthorPattern matches every cached page of posts by an author
+func (ck *CacheKey) PostsByAuthorPattern(authorID string) string {
+	return fmt.Sprintf("%s:posts:author:%s:*", ck.Prefix, authorID)
+}
+
+// PostsListPattern matches every cached page of the posts list
+func (ck *CacheKey) PostsListPattern() string {
+	return ck.Prefix + ":posts:list:*"
+}
+
+// UserPattern matches every cached user
+func (ck *CacheKey) UserPattern() string {
+	return ck.Prefix + ":user:*"
+}
+
+// UsersPattern matches every cached list of users
+func (ck *CacheKey) UsersPattern() string {
+	return ck.Prefix + ":users:*"
+}
+
+// PostPattern matches every cached post
+func (ck *CacheKey) PostPattern() string {
+	return ck.Prefix + ":post:*"
+}
+
+// PostsPattern matches every cached list of posts
+func (ck *CacheKey) PostsPattern() string {
+	return ck.Prefix + ":posts:*"
+}
+
 // SearchPosts generates a cache key for post search results
 func (ck *CacheKey) SearchPosts(query string, limit int) string {
 	return fmt.Sprintf("%s:posts:search:%s:%d", ck.Prefix, query, limit)
@@ -11,9 +11,16 @@ import (
 
 // RedisCache wraps Redis client with caching functionality
 type RedisCache struct {
-	client *redis.Client
+	client redis.UniversalClient
 }
 
+const (
+	// scanBatchSize is the COUNT hint passed to each SCAN call
+	scanBatchSize = 100
+	// deleteFlushPages is how many SCAN pages of DELs are pipelined per round trip
+	deleteFlushPages = 10
+)
+
 // Config holds Redis configuration
 type Config struct {
 	Host     string
@@ -46,6 +53,12 @@ func NewRedisCacheFromURL(url string) (*RedisCache, error) {
 	return &RedisCache{client: rdb}, nil
 }
 
+// Client returns the underlying Redis client, for components such as the
+// rate limiter that need commands beyond the Cache interface
+func (c *RedisCache) Client() redis.UniversalClient {
+	return c.client
+}
+
 // Ping tests the Redis connection
 func (c *RedisCache) Ping(ctx context.Context) error {
 	return c.client.Ping(ctx).Err()
@@ -88,18 +101,38 @@ func (c *RedisCache) Delete(ctx context.Context, key string) error {
 	return c.client.Del(ctx, key).Err()
 }
 
-// DeletePattern deletes all keys matching a pattern
+// DeletePattern deletes all keys matching a pattern. It walks the keyspace
+// with SCAN rather than KEYS so Redis is never blocked, and queues the
+// deletes on a pipeline that is flushed every few pages.
 func (c *RedisCache) DeletePattern(ctx context.Context, pattern string) error {
-	keys, err := c.client.Keys(ctx, pattern).Result()
-	if err != nil {
-		return fmt.Errorf("failed to get keys: %w", err)
-	}
+	pipe := c.client.Pipeline()
 
-	if len(keys) == 0 {
-		return nil
-	}
+	var cursor uint64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
-	return c.client.Del(ctx, keys...).Err()
+		keys, next, err := c.client.Scan(ctx, cursor, pattern, scanBatchSize).Result()
+		if err != nil {
+			return fmt.Errorf("failed to scan keys: %w", err)
+		}
+
+		if len(keys) > 0 {
+			pipe.Del(ctx, keys...)
+		}
+
+		if pipe.Len() > 0 && (pipe.Len() >= deleteFlushPages || next == 0) {
+			if _, err := pipe.Exec(ctx); err != nil {
+				return fmt.Errorf("failed to delete keys: %w", err)
+			}
+		}
+
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
 }
 
 // Exists checks if a key exists in Redis
@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedisClient serves SCAN in fixed-size pages over an in-memory keyspace
+type fakeRedisClient struct {
+	redis.UniversalClient
+	order     []string
+	keys      map[string]bool
+	pageSize  int
+	scanCalls int
+	execCalls int
+	onScan    func(calls int)
+}
+
+func newFakeRedisClient(pageSize int, keys ...string) *fakeRedisClient {
+	f := &fakeRedisClient{keys: make(map[string]bool), pageSize: pageSize}
+	for _, key := range keys {
+		f.order = append(f.order, key)
+		f.keys[key] = true
+	}
+	return f
+}
+
+func (f *fakeRedisClient) Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd {
+	f.scanCalls++
+	if f.onScan != nil {
+		f.onScan(f.scanCalls)
+	}
+
+	start := int(cursor)
+	end := start + f.pageSize
+	if end > len(f.order) {
+		end = len(f.order)
+	}
+
+	var page []string
+	for _, key := range f.order[start:end] {
+		if matched, _ := path.Match(match, key); matched && f.keys[key] {
+			page = append(page, key)
+		}
+	}
+
+	next := uint64(end)
+	if end >= len(f.order) {
+		next = 0
+	}
+	return redis.NewScanCmdResult(page, next, nil)
+}
+
+func (f *fakeRedisClient) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	var deleted int64
+	for _, key := range keys {
+		if f.keys[key] {
+			delete(f.keys, key)
+			deleted++
+		}
+	}
+	return redis.NewIntResult(deleted, nil)
+}
+
+func (f *fakeRedisClient) Pipeline() redis.Pipeliner {
+	return &fakePipeline{client: f}
+}
+
+// fakePipeline queues DELs and applies them to its client on Exec
+type fakePipeline struct {
+	redis.Pipeliner
+	client *fakeRedisClient
+	queued [][]string
+}
+
+func (p *fakePipeline) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	p.queued = append(p.queued, keys)
+	return redis.NewIntCmd(ctx)
+}
+
+func (p *fakePipeline) Len() int {
+	return len(p.queued)
+}
+
+func (p *fakePipeline) Exec(ctx context.Context) ([]redis.Cmder, error) {
+	p.client.execCalls++
+	for _, keys := range p.queued {
+		p.client.Del(ctx, keys...)
+	}
+	p.queued = nil
+	return nil, nil
+}
+
+func TestRedisCache_DeletePattern(t *testing.T) {
+	keys := NewCacheKey("graphql")
+
+	var all []string
+	for i := 0; i < 25; i++ {
+		all = append(all, keys.PostsList(fmt.Sprintf("f%d", i), 20, 0))
+	}
+	unrelated := []string{
+		keys.User("1"),
+		keys.Post("1"),
+		keys.PostsByAuthor("1", 10, 0),
+	}
+	all = append(all, unrelated...)
+
+	client := newFakeRedisClient(4, all...)
+	c := &RedisCache{client: client}
+
+	require.NoError(t, c.DeletePattern(context.Background(), keys.PostsListPattern()))
+
+	assert.Greater(t, client.scanCalls, 1)
+	assert.Less(t, client.execCalls, client.scanCalls)
+	assert.Len(t, client.keys, len(unrelated))
+	for _, key := range unrelated {
+		assert.True(t, client.keys[key], "expected %s to survive", key)
+	}
+}
+
+func TestRedisCache_DeletePattern_NoMatches(t *testing.T) {
+	keys := NewCacheKey("graphql")
+	client := newFakeRedisClient(4, keys.User("1"), keys.Post("1"))
+	c := &RedisCache{client: client}
+
+	require.NoError(t, c.DeletePattern(context.Background(), keys.PostsListPattern()))
+
+	assert.Len(t, client.keys, 2)
+	assert.Zero(t, client.execCalls)
+}
+
+func TestRedisCache_DeletePattern_ContextCancelled(t *testing.T) {
+	keys := NewCacheKey("graphql")
+
+	var all []string
+	for i := 0; i < 20; i++ {
+		all = append(all, keys.PostsList(fmt.Sprintf("f%d", i), 20, 0))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newFakeRedisClient(4, all...)
+	client.onScan = func(calls int) {
+		if calls == 2 {
+			cancel()
+		}
+	}
+	c := &RedisCache{client: client}
+
+	err := c.DeletePattern(ctx, keys.PostsListPattern())
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 2, client.scanCalls)
+	assert.Len(t, client.keys, len(all))
+}
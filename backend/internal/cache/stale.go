@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DefaultStaleTTL is how long stale copies are kept when none is configured
+const DefaultStaleTTL = time.Hour
+
+// staleSuffix marks the key of a stale copy. Invalidation patterns end in
+// "*", so they drop stale copies together with the fresh entries.
+const staleSuffix = ":stale"
+
+// StaleCache wraps a Cache so that every value written is also kept, for the
+// stale TTL, under a separate stale key that outlives the entry's own TTL.
+// Cached repositories serve those copies when the database can't be reached.
+// Wrap any CircuitBreakerCache with it, not the other way round, so the
+// repositories find it.
+type StaleCache struct {
+	Cache
+	staleTTL time.Duration
+}
+
+// NewStaleCache keeps stale copies of cache's values for staleTTL, or
+// DefaultStaleTTL when it is not positive
+func NewStaleCache(cache Cache, staleTTL time.Duration) *StaleCache {
+	if staleTTL <= 0 {
+		staleTTL = DefaultStaleTTL
+	}
+	return &StaleCache{Cache: cache, staleTTL: staleTTL}
+}
+
+// Set stores a value and its stale copy
+func (s *StaleCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if err := s.Cache.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	return s.Cache.Set(ctx, key+staleSuffix, value, s.staleTTL)
+}
+
+// SetMultiple stores several values and their stale copies
+func (s *StaleCache) SetMultiple(ctx context.Context, values map[string]interface{}, ttl time.Duration) error {
+	if err := s.Cache.SetMultiple(ctx, values, ttl); err != nil {
+		return err
+	}
+	stale := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		stale[key+staleSuffix] = value
+	}
+	return s.Cache.SetMultiple(ctx, stale, s.staleTTL)
+}
+
+// Delete removes a key and its stale copy
+func (s *StaleCache) Delete(ctx context.Context, key string) error {
+	if err := s.Cache.Delete(ctx, key); err != nil {
+		return err
+	}
+	return s.Cache.Delete(ctx, key+staleSuffix)
+}
+
+// GetStale reads the stale copy of key into dest
+func (s *StaleCache) GetStale(ctx context.Context, key string, dest interface{}) error {
+	return s.Cache.Get(ctx, key+staleSuffix, dest)
+}
+
+// StaleTTLFromEnv returns CACHE_STALE_TTL, how long stale copies are kept,
+// or 0 when it is unset or invalid. Stale reads are off unless it is set.
+func StaleTTLFromEnv() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("CACHE_STALE_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 0
+}
+
+// staleReader is implemented by caches that keep stale copies
+type staleReader interface {
+	GetStale(ctx context.Context, key string, dest interface{}) error
+}
+
+// serveStale reads the stale copy of key into dest after the repository
+// failed to answer a read, and marks the response as stale. It reports false
+// when the failure wasn't an outage, c keeps no stale copies or none is left.
+func serveStale(ctx context.Context, c Cache, key string, dest interface{}, repoErr error) bool {
+	stale, ok := c.(staleReader)
+	if !ok || !databaseUnavailable(repoErr) {
+		return false
+	}
+	if err := stale.GetStale(ctx, key, dest); err != nil {
+		return false
+	}
+	markStale(ctx)
+	return true
+}
+
+// databaseUnavailable reports whether err means the database couldn't be
+// reached, as opposed to an answer such as "not found" that stale data
+// must not override
+func databaseUnavailable(err error) bool {
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	return errors.As(err, &connectErr) || errors.As(err, &netErr) || pgconn.Timeout(err)
+}
+
+type staleContextKey struct{}
+
+// withStaleFlag returns a context recording whether stale data was served
+func withStaleFlag(ctx context.Context) (context.Context, *atomic.Bool) {
+	flag := &atomic.Bool{}
+	return context.WithValue(ctx, staleContextKey{}, flag), flag
+}
+
+// markStale records that stale data was served for the request in ctx
+func markStale(ctx context.Context) {
+	if flag, ok := ctx.Value(staleContextKey{}).(*atomic.Bool); ok {
+		flag.Store(true)
+	}
+}
+
+// StaleResponseMarker is a GraphQL extension that sets the "stale" response
+// extension to true when any data in the response came from a stale copy
+type StaleResponseMarker struct{}
+
+// NewStaleResponseMarker creates the extension
+func NewStaleResponseMarker() *StaleResponseMarker {
+	return &StaleResponseMarker{}
+}
+
+// ExtensionName returns the name of this extension
+func (m *StaleResponseMarker) ExtensionName() string {
+	return "StaleResponseMarker"
+}
+
+// Validate validates the schema (no-op for this extension)
+func (m *StaleResponseMarker) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse flags responses built from stale data
+func (m *StaleResponseMarker) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	ctx, stale := withStaleFlag(ctx)
+	resp := next(ctx)
+	if resp != nil && stale.Load() {
+		if resp.Extensions == nil {
+			resp.Extensions = map[string]interface{}{}
+		}
+		resp.Extensions["stale"] = true
+	}
+	return resp
+}
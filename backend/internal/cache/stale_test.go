@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"context"
+	"net"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"backend/internal/graph/model"
+	"backend/internal/repository"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errDatabaseDown is what a read returns while PostgreSQL refuses connections
+var errDatabaseDown = &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+
+// outagePostRepo is a stubPostRepo whose reads fail with err while it is set
+type outagePostRepo struct {
+	*stubPostRepo
+	err error
+}
+
+func (s *outagePostRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Post, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.stubPostRepo.GetByID(ctx, id)
+}
+
+func (s *outagePostRepo) List(ctx context.Context, filters *repository.PostFilters, limit, offset int) ([]*model.Post, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.stubPostRepo.List(ctx, filters, limit, offset)
+}
+
+// expireFresh drops every fresh entry, as their TTL passing would
+func expireFresh(memory *memoryCache) {
+	for key := range memory.data {
+		if !strings.HasSuffix(key, staleSuffix) {
+			delete(memory.data, key)
+		}
+	}
+}
+
+// respond runs read as the resolvers of one operation behind the marker
+func respond(read func(ctx context.Context)) *graphql.Response {
+	return NewStaleResponseMarker().InterceptResponse(context.Background(), func(ctx context.Context) *graphql.Response {
+		read(ctx)
+		return &graphql.Response{}
+	})
+}
+
+func TestCachedPostRepository_ServesStaleDataDuringOutage(t *testing.T) {
+	post := &model.Post{ID: uuid.New(), Title: "Cached before the outage", AuthorID: uuid.New()}
+	memory := newMemoryCache()
+	repo := &outagePostRepo{stubPostRepo: newStubPostRepo(post)}
+	cached := NewCachedPostRepository(repo, NewStaleCache(memory, time.Hour), time.Minute)
+
+	_, err := cached.GetByID(context.Background(), post.ID)
+	require.NoError(t, err)
+	_, err = cached.List(context.Background(), nil, 10, 0)
+	require.NoError(t, err)
+	expireFresh(memory)
+	repo.err = errDatabaseDown
+
+	t.Run("stale data is served and flagged", func(t *testing.T) {
+		resp := respond(func(ctx context.Context) {
+			got, err := cached.GetByID(ctx, post.ID)
+			require.NoError(t, err)
+			assert.Equal(t, post.Title, got.Title)
+
+			posts, err := cached.List(ctx, nil, 10, 0)
+			require.NoError(t, err)
+			require.Len(t, posts, 1)
+			assert.Equal(t, post.ID, posts[0].ID)
+		})
+
+		assert.Equal(t, true, resp.Extensions["stale"])
+	})
+
+	t.Run("without stale data the error is returned", func(t *testing.T) {
+		resp := respond(func(ctx context.Context) {
+			_, err := cached.GetByID(ctx, uuid.New())
+			assert.ErrorIs(t, err, errDatabaseDown)
+
+			_, err = cached.List(ctx, nil, 20, 0)
+			assert.ErrorIs(t, err, errDatabaseDown)
+		})
+
+		assert.Nil(t, resp.Extensions)
+	})
+
+	t.Run("answers other than an outage are not overridden", func(t *testing.T) {
+		repo.err = nil
+		repo.posts = map[uuid.UUID]*model.Post{}
+		t.Cleanup(func() { repo.err = errDatabaseDown })
+
+		_, err := cached.GetByID(context.Background(), post.ID)
+		assert.EqualError(t, err, "post not found")
+	})
+
+	t.Run("deleting a post drops its stale copy", func(t *testing.T) {
+		require.NoError(t, cached.cache.Delete(context.Background(), cached.keys.Post(post.ID.String())))
+
+		_, err := cached.GetByID(context.Background(), post.ID)
+		assert.ErrorIs(t, err, errDatabaseDown)
+	})
+}
+
+func TestCachedPostRepository_StaleDataIsOptIn(t *testing.T) {
+	post := &model.Post{ID: uuid.New(), Title: "Cached before the outage", AuthorID: uuid.New()}
+	memory := newMemoryCache()
+	repo := &outagePostRepo{stubPostRepo: newStubPostRepo(post)}
+	cached := NewCachedPostRepository(repo, memory, time.Minute)
+
+	_, err := cached.GetByID(context.Background(), post.ID)
+	require.NoError(t, err)
+	expireFresh(memory)
+	repo.err = errDatabaseDown
+
+	_, err = cached.GetByID(context.Background(), post.ID)
+	assert.ErrorIs(t, err, errDatabaseDown)
+	assert.Empty(t, memory.data)
+}
+
+func TestStaleTTLFromEnv(t *testing.T) {
+	assert.Zero(t, StaleTTLFromEnv())
+
+	t.Setenv("CACHE_STALE_TTL", "2h")
+	assert.Equal(t, 2*time.Hour, StaleTTLFromEnv())
+
+	t.Setenv("CACHE_STALE_TTL", "-1m")
+	assert.Zero(t, StaleTTLFromEnv())
+}
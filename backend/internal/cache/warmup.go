@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// WarmupConfig bounds the cache warm-up run at startup
+type WarmupConfig struct {
+	// RecentPosts is how many of the newest published posts to load, along
+	// with their authors; 0 disables the warm-up
+	RecentPosts int
+	// Timeout bounds the whole warm-up
+	Timeout time.Duration
+}
+
+// DefaultWarmupConfig returns the default warm-up settings
+func DefaultWarmupConfig() WarmupConfig {
+	return WarmupConfig{
+		RecentPosts: 50,
+		Timeout:     10 * time.Second,
+	}
+}
+
+// WarmupConfigFromEnv returns the default settings overridden by
+// CACHE_WARMUP_POSTS and CACHE_WARMUP_TIMEOUT
+func WarmupConfigFromEnv() WarmupConfig {
+	config := DefaultWarmupConfig()
+	if value := os.Getenv("CACHE_WARMUP_POSTS"); value != "" {
+		if posts, err := strconv.Atoi(value); err == nil && posts >= 0 {
+			config.RecentPosts = posts
+		}
+	}
+	if value := os.Getenv("CACHE_WARMUP_TIMEOUT"); value != "" {
+		if timeout, err := time.ParseDuration(value); err == nil && timeout > 0 {
+			config.Timeout = timeout
+		}
+	}
+	return config
+}
+
+// Warmer loads hot data into the cache under the keys the cached
+// repositories read, so the first requests after a deploy are cache hits
+type Warmer struct {
+	posts  repository.PostRepository
+	users  repository.UserRepository
+	cache  Cache
+	keys   *CacheKey
+	ttl    time.Duration
+	config WarmupConfig
+}
+
+// NewWarmer creates a new cache warmer
+func NewWarmer(posts repository.PostRepository, users repository.UserRepository, cache Cache, ttl time.Duration, config WarmupConfig) *Warmer {
+	return &Warmer{
+		posts:  posts,
+		users:  users,
+		cache:  cache,
+		keys:   NewCacheKey("graphql"),
+		ttl:    ttl,
+		config: config,
+	}
+}
+
+// Warm caches the most recent published posts and their authors. It stops
+// when ctx is cancelled or the configured timeout elapses, and returns the
+// number of entries written.
+func (w *Warmer) Warm(ctx context.Context) (int, error) {
+	if w.config.RecentPosts <= 0 {
+		return 0, nil
+	}
+
+	if w.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.config.Timeout)
+		defer cancel()
+	}
+
+	published := true
+	posts, err := w.posts.List(ctx, &repository.PostFilters{Published: &published}, w.config.RecentPosts, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load recent posts: %w", err)
+	}
+
+	values := make(map[string]interface{}, len(posts)*2)
+	var authorIDs []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, post := range posts {
+		values[w.keys.Post(post.ID.String())] = post
+		if !seen[post.AuthorID] {
+			seen[post.AuthorID] = true
+			authorIDs = append(authorIDs, post.AuthorID)
+		}
+	}
+
+	authors, err := w.users.GetByIDs(ctx, authorIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load post authors: %w", err)
+	}
+	for _, author := range authors {
+		if author != nil {
+			values[w.keys.User(author.ID.String())] = author
+		}
+	}
+
+	if len(values) == 0 {
+		return 0, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	if err := w.cache.SetMultiple(ctx, values, w.ttl); err != nil {
+		return 0, fmt.Errorf("failed to write warm-up entries: %w", err)
+	}
+
+	return len(values), nil
+}
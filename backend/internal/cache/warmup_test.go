@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/graph/model"
+	"backend/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubAuthorRepo is a UserRepository that only supports GetByIDs
+type stubAuthorRepo struct {
+	repository.UserRepository
+	users map[uuid.UUID]*model.User
+	calls int
+}
+
+func (s *stubAuthorRepo) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.User, error) {
+	s.calls++
+	users := []*model.User{}
+	for _, id := range ids {
+		if user, ok := s.users[id]; ok {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func TestWarmer_CachesRecentPostsAndAuthors(t *testing.T) {
+	alice := &model.User{ID: uuid.New(), Name: "Alice"}
+	bob := &model.User{ID: uuid.New(), Name: "Bob"}
+	posts := []*model.Post{
+		{ID: uuid.New(), Title: "One", AuthorID: alice.ID},
+		{ID: uuid.New(), Title: "Two", AuthorID: alice.ID},
+		{ID: uuid.New(), Title: "Three", AuthorID: bob.ID},
+	}
+	users := &stubAuthorRepo{users: map[uuid.UUID]*model.User{alice.ID: alice, bob.ID: bob}}
+	memCache := newMemoryCache()
+	keys := NewCacheKey("graphql")
+
+	warmer := NewWarmer(newStubPostRepo(posts...), users, memCache, time.Minute, DefaultWarmupConfig())
+	warmed, err := warmer.Warm(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 5, warmed)
+	assert.Equal(t, 1, users.calls)
+
+	expected := []string{keys.User(alice.ID.String()), keys.User(bob.ID.String())}
+	for _, post := range posts {
+		expected = append(expected, keys.Post(post.ID.String()))
+	}
+	cached := make([]string, 0, len(memCache.data))
+	for key := range memCache.data {
+		cached = append(cached, key)
+	}
+	assert.ElementsMatch(t, expected, cached)
+}
+
+func TestWarmer_WarmedPostIsCacheHit(t *testing.T) {
+	post := &model.Post{ID: uuid.New(), Title: "Hot", AuthorID: uuid.New()}
+	stub := newStubPostRepo(post)
+	memCache := newMemoryCache()
+
+	warmer := NewWarmer(stub, &stubAuthorRepo{}, memCache, time.Minute, DefaultWarmupConfig())
+	_, err := warmer.Warm(context.Background())
+	require.NoError(t, err)
+
+	// Remove the post from the stub so only the cache can answer
+	delete(stub.posts, post.ID)
+	cached, err := NewCachedPostRepository(stub, memCache, time.Minute).GetByID(context.Background(), post.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Hot", cached.Title)
+}
+
+func TestWarmer_DisabledWritesNothing(t *testing.T) {
+	stub := newStubPostRepo(&model.Post{ID: uuid.New(), AuthorID: uuid.New()})
+	memCache := newMemoryCache()
+
+	warmer := NewWarmer(stub, &stubAuthorRepo{}, memCache, time.Minute, WarmupConfig{RecentPosts: 0, Timeout: time.Second})
+	warmed, err := warmer.Warm(context.Background())
+
+	require.NoError(t, err)
+	assert.Zero(t, warmed)
+	assert.Zero(t, stub.listCalls)
+	assert.Empty(t, memCache.data)
+}
+
+func TestWarmer_CancelledContextWritesNothing(t *testing.T) {
+	memCache := newMemoryCache()
+	warmer := NewWarmer(newStubPostRepo(&model.Post{ID: uuid.New(), AuthorID: uuid.New()}), &stubAuthorRepo{}, memCache, time.Minute, DefaultWarmupConfig())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := warmer.Warm(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, memCache.data)
+}
@@ -0,0 +1,13 @@
+// Package clock is the time source for the timestamps the application
+// creates, so they are always in UTC whatever the server's time zone.
+package clock
+
+import "time"
+
+// Clock returns the current time. Services take one so tests can fix the time.
+type Clock func() time.Time
+
+// Now returns the current time in UTC
+func Now() time.Time {
+	return time.Now().UTC()
+}
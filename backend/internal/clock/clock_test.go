@@ -0,0 +1,19 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNow_IsUTCRegardlessOfLocalZone(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+9", 9*60*60)
+	defer func() { time.Local = local }()
+
+	now := Now()
+
+	assert.Equal(t, time.UTC, now.Location())
+	assert.WithinDuration(t, time.Now(), now, time.Second)
+}
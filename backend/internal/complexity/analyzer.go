@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"backend/internal/envconfig"
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/vektah/gqlparser/v2/ast"
 )
@@ -13,17 +14,45 @@ type Config struct {
 	MaxComplexity   int
 	MaxDepth        int
 	IntrospectionOk bool
+	// BulkFieldWeights maps field names, such as bulk mutations, to a weight
+	// charged per item passed in their list arguments
+	BulkFieldWeights map[string]int
+
+	// Limits applied when Authenticated reports no user; 0 falls back to
+	// MaxComplexity and MaxDepth
+	AnonymousMaxComplexity int
+	AnonymousMaxDepth      int
+	// Authenticated reports whether ctx carries an authenticated user. When
+	// nil every caller gets MaxComplexity and MaxDepth.
+	Authenticated func(ctx context.Context) bool
+	// OnReject, when set, is called for every operation refused for
+	// exceeding the complexity, depth or batch complexity limit
+	OnReject func(ctx context.Context, rejection Rejection)
 }
 
 // DefaultConfig returns a default complexity configuration
 func DefaultConfig() Config {
 	return Config{
-		MaxComplexity:   1000,
-		MaxDepth:        15,
-		IntrospectionOk: true,
+		MaxComplexity:          1000,
+		MaxDepth:               15,
+		IntrospectionOk:        true,
+		AnonymousMaxComplexity: 300,
+		AnonymousMaxDepth:      10,
 	}
 }
 
+// ConfigFromEnv returns the default limits overridden by
+// GRAPHQL_MAX_COMPLEXITY, GRAPHQL_MAX_DEPTH, GRAPHQL_ANONYMOUS_MAX_COMPLEXITY
+// and GRAPHQL_ANONYMOUS_MAX_DEPTH
+func ConfigFromEnv() Config {
+	config := DefaultConfig()
+	config.MaxComplexity = envconfig.PositiveInt("GRAPHQL_MAX_COMPLEXITY", config.MaxComplexity)
+	config.MaxDepth = envconfig.PositiveInt("GRAPHQL_MAX_DEPTH", config.MaxDepth)
+	config.AnonymousMaxComplexity = envconfig.PositiveInt("GRAPHQL_ANONYMOUS_MAX_COMPLEXITY", config.AnonymousMaxComplexity)
+	config.AnonymousMaxDepth = envconfig.PositiveInt("GRAPHQL_ANONYMOUS_MAX_DEPTH", config.AnonymousMaxDepth)
+	return config
+}
+
 // Analyzer provides query complexity analysis
 type Analyzer struct {
 	config Config
@@ -34,6 +63,22 @@ func NewAnalyzer(config Config) *Analyzer {
 	return &Analyzer{config: config}
 }
 
+// limits returns the maximum complexity and depth for the caller in ctx
+func (a *Analyzer) limits(ctx context.Context) (int, int) {
+	maxComplexity, maxDepth := a.config.MaxComplexity, a.config.MaxDepth
+	if a.config.Authenticated == nil || a.config.Authenticated(ctx) {
+		return maxComplexity, maxDepth
+	}
+
+	if a.config.AnonymousMaxComplexity > 0 {
+		maxComplexity = a.config.AnonymousMaxComplexity
+	}
+	if a.config.AnonymousMaxDepth > 0 {
+		maxDepth = a.config.AnonymousMaxDepth
+	}
+	return maxComplexity, maxDepth
+}
+
 // AnalyzeComplexity calculates the complexity of a GraphQL query
 func (a *Analyzer) AnalyzeComplexity(ctx context.Context, rc *graphql.OperationContext) (int, error) {
 	complexity := 0
@@ -46,8 +91,9 @@ func (a *Analyzer) AnalyzeComplexity(ctx context.Context, rc *graphql.OperationC
 		complexity += fieldComplexity
 	}
 	
-	if complexity > a.config.MaxComplexity {
-		return complexity, fmt.Errorf("query complexity %d exceeds maximum allowed complexity %d", complexity, a.config.MaxComplexity)
+	maxComplexity, _ := a.limits(ctx)
+	if complexity > maxComplexity {
+		return complexity, fmt.Errorf("query complexity %d exceeds maximum allowed complexity %d", complexity, maxComplexity)
 	}
 	
 	return complexity, nil
@@ -57,8 +103,9 @@ func (a *Analyzer) AnalyzeComplexity(ctx context.Context, rc *graphql.OperationC
 func (a *Analyzer) AnalyzeDepth(ctx context.Context, rc *graphql.OperationContext) (int, error) {
 	depth := a.calculateMaxDepth(rc.Operation.SelectionSet, 1)
 	
-	if depth > a.config.MaxDepth {
-		return depth, fmt.Errorf("query depth %d exceeds maximum allowed depth %d", depth, a.config.MaxDepth)
+	_, maxDepth := a.limits(ctx)
+	if depth > maxDepth {
+		return depth, fmt.Errorf("query depth %d exceeds maximum allowed depth %d", depth, maxDepth)
 	}
 	
 	return depth, nil
@@ -70,6 +117,15 @@ func (a *Analyzer) calculateFieldComplexity(selection ast.Selection, variables m
 	case *ast.Field:
 		// Base complexity for each field
 		complexity := 1
+
+		// Bulk fields cost their weight for every item in their list arguments
+		if weight, ok := a.config.BulkFieldWeights[sel.Name]; ok {
+			items := a.listArgumentLength(sel.Arguments, variables)
+			if items < 1 {
+				items = 1
+			}
+			complexity = weight * items
+		}
 		
 		// Add complexity based on arguments
 		for _, arg := range sel.Arguments {
@@ -145,6 +201,23 @@ func (a *Analyzer) calculateMaxDepth(selectionSet ast.SelectionSet, currentDepth
 	return maxDepth
 }
 
+// listArgumentLength returns the total number of items passed in list
+// arguments, whether written inline or supplied as variables
+func (a *Analyzer) listArgumentLength(arguments ast.ArgumentList, variables map[string]interface{}) int {
+	total := 0
+	for _, arg := range arguments {
+		switch arg.Value.Kind {
+		case ast.ListValue:
+			total += len(arg.Value.Children)
+		case ast.Variable:
+			if items, ok := variables[arg.Value.Raw].([]interface{}); ok {
+				total += len(items)
+			}
+		}
+	}
+	return total
+}
+
 // getArgumentValue extracts the actual value from an argument
 func (a *Analyzer) getArgumentValue(value *ast.Value, variables map[string]interface{}) interface{} {
 	switch value.Kind {
@@ -183,32 +256,38 @@ func (e *complexityExtension) Validate(schema graphql.ExecutableSchema) error {
 }
 
 func (e *complexityExtension) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
-	return func(ctx context.Context) *graphql.Response {
-		rc := graphql.GetOperationContext(ctx)
-		
-		// Skip introspection queries if configured
-		if !e.analyzer.config.IntrospectionOk && rc.Operation.Name == "IntrospectionQuery" {
-			return graphql.ErrorResponse(ctx, "introspection disabled")
-		}
-		
-		// Analyze complexity
-		complexity, err := e.analyzer.AnalyzeComplexity(ctx, rc)
-		if err != nil {
-			return graphql.ErrorResponse(ctx, err.Error())
-		}
-		
-		// Analyze depth
-		depth, err := e.analyzer.AnalyzeDepth(ctx, rc)
-		if err != nil {
-			return graphql.ErrorResponse(ctx, err.Error())
-		}
-		
-		// Add complexity and depth to context for logging
-		ctx = context.WithValue(ctx, "query_complexity", complexity)
-		ctx = context.WithValue(ctx, "query_depth", depth)
-		
-		return next(ctx)
+	rc := graphql.GetOperationContext(ctx)
+	
+	// Skip introspection queries if configured
+	if !e.analyzer.config.IntrospectionOk && rc.Operation.Name == "IntrospectionQuery" {
+		return graphql.OneShot(graphql.ErrorResponse(ctx, "introspection disabled"))
 	}
+	
+	// Analyze complexity
+	complexity, err := e.analyzer.AnalyzeComplexity(ctx, rc)
+	if err != nil {
+		e.reject(ctx, rc, LimitComplexity, complexity, err)
+		return graphql.OneShot(graphql.ErrorResponse(ctx, err.Error()))
+	}
+	
+	// Analyze depth
+	depth, err := e.analyzer.AnalyzeDepth(ctx, rc)
+	if err != nil {
+		e.reject(ctx, rc, LimitDepth, complexity, err)
+		return graphql.OneShot(graphql.ErrorResponse(ctx, err.Error()))
+	}
+	
+	// Count the operation against its batch, if it arrived in one
+	if err := e.analyzer.ChargeBatch(ctx, complexity); err != nil {
+		e.reject(ctx, rc, LimitBatchComplexity, complexity, err)
+		return graphql.OneShot(graphql.ErrorResponse(ctx, err.Error()))
+	}
+	
+	// Add complexity and depth to context for logging
+	ctx = context.WithValue(ctx, "query_complexity", complexity)
+	ctx = context.WithValue(ctx, "query_depth", depth)
+	
+	return next(ctx)
 }
 
 func (e *complexityExtension) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
@@ -0,0 +1,200 @@
+package complexity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+const bulkCreateMutation = `mutation ($inputs: [CreatePostInput!]!) {
+	createPosts(inputs: $inputs) { id }
+}`
+
+// bulkInputs returns n post inputs as they arrive in request variables
+func bulkInputs(n int) map[string]interface{} {
+	inputs := make([]interface{}, n)
+	for i := range inputs {
+		inputs[i] = map[string]interface{}{"title": "Post", "content": "Content"}
+	}
+	return map[string]interface{}{"inputs": inputs}
+}
+
+// complexityOf analyzes query with the given variables
+func complexityOf(t *testing.T, analyzer *Analyzer, query string, variables map[string]interface{}) (int, error) {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	require.NoError(t, err)
+	return analyzer.AnalyzeComplexity(context.Background(), &graphql.OperationContext{
+		Operation: doc.Operations[0],
+		Variables: variables,
+	})
+}
+
+func newBulkAnalyzer() *Analyzer {
+	config := DefaultConfig()
+	config.BulkFieldWeights = map[string]int{"createPosts": 5}
+	return NewAnalyzer(config)
+}
+
+func TestAnalyzer_BulkFieldScalesWithListVariable(t *testing.T) {
+	analyzer := newBulkAnalyzer()
+
+	costs := map[int]int{}
+	for _, n := range []int{1, 10, 100} {
+		cost, err := complexityOf(t, analyzer, bulkCreateMutation, bulkInputs(n))
+		require.NoError(t, err)
+		costs[n] = cost
+	}
+
+	// Each input adds the field weight; the selection is counted once
+	assert.Equal(t, 5*1+1, costs[1])
+	assert.Equal(t, 5*10+1, costs[10])
+	assert.Equal(t, 5*100+1, costs[100])
+}
+
+func TestAnalyzer_BulkFieldCountsInlineList(t *testing.T) {
+	analyzer := newBulkAnalyzer()
+
+	cost, err := complexityOf(t, analyzer, `mutation {
+		createPosts(inputs: [{title: "a", content: "b"}, {title: "c", content: "d"}, {title: "e", content: "f"}]) { id }
+	}`, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 5*3+1, cost)
+}
+
+func TestAnalyzer_LargeBulkExceedsLimit(t *testing.T) {
+	analyzer := newBulkAnalyzer()
+
+	cost, err := complexityOf(t, analyzer, bulkCreateMutation, bulkInputs(500))
+
+	assert.Equal(t, 5*500+1, cost)
+	assert.ErrorContains(t, err, "exceeds maximum allowed complexity")
+}
+
+func TestAnalyzer_EmptyBulkCostsOneItem(t *testing.T) {
+	analyzer := newBulkAnalyzer()
+
+	cost, err := complexityOf(t, analyzer, bulkCreateMutation, bulkInputs(0))
+
+	require.NoError(t, err)
+	assert.Equal(t, 5+1, cost)
+}
+
+func TestAnalyzer_UnweightedFieldIgnoresListLength(t *testing.T) {
+	analyzer := NewAnalyzer(DefaultConfig())
+
+	small, err := complexityOf(t, analyzer, bulkCreateMutation, bulkInputs(1))
+	require.NoError(t, err)
+	large, err := complexityOf(t, analyzer, bulkCreateMutation, bulkInputs(500))
+	require.NoError(t, err)
+
+	assert.Equal(t, small, large)
+}
+
+type authenticatedKey struct{}
+
+// newAudienceAnalyzer returns an analyzer that gives callers marked with
+// authenticatedKey the higher budget
+func newAudienceAnalyzer() *Analyzer {
+	config := DefaultConfig()
+	config.BulkFieldWeights = map[string]int{"createPosts": 5}
+	config.MaxComplexity = 1000
+	config.AnonymousMaxComplexity = 100
+	config.MaxDepth = 6
+	config.AnonymousMaxDepth = 3
+	config.Authenticated = func(ctx context.Context) bool {
+		return ctx.Value(authenticatedKey{}) != nil
+	}
+	return NewAnalyzer(config)
+}
+
+// operationOf parses query into an operation context with the given variables
+func operationOf(t *testing.T, query string, variables map[string]interface{}) *graphql.OperationContext {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	require.NoError(t, err)
+	return &graphql.OperationContext{Operation: doc.Operations[0], Variables: variables}
+}
+
+func TestAnalyzer_AnonymousComplexityBudget(t *testing.T) {
+	analyzer := newAudienceAnalyzer()
+	rc := operationOf(t, bulkCreateMutation, bulkInputs(50))
+	authenticated := context.WithValue(context.Background(), authenticatedKey{}, true)
+
+	_, err := analyzer.AnalyzeComplexity(context.Background(), rc)
+	assert.ErrorContains(t, err, "exceeds maximum allowed complexity 100")
+
+	cost, err := analyzer.AnalyzeComplexity(authenticated, rc)
+	require.NoError(t, err)
+	assert.Equal(t, 5*50+1, cost)
+}
+
+func TestAnalyzer_AnonymousDepthBudget(t *testing.T) {
+	analyzer := newAudienceAnalyzer()
+	rc := operationOf(t, `{ posts { edges { node { author { name } } } } }`, nil)
+	authenticated := context.WithValue(context.Background(), authenticatedKey{}, true)
+
+	_, err := analyzer.AnalyzeDepth(context.Background(), rc)
+	assert.ErrorContains(t, err, "exceeds maximum allowed depth 3")
+
+	depth, err := analyzer.AnalyzeDepth(authenticated, rc)
+	require.NoError(t, err)
+	assert.Equal(t, 5, depth)
+}
+
+func TestAnalyzer_NoAuthenticatedHookUsesMainBudget(t *testing.T) {
+	config := DefaultConfig()
+	config.BulkFieldWeights = map[string]int{"createPosts": 5}
+	analyzer := NewAnalyzer(config)
+
+	cost, err := complexityOf(t, analyzer, bulkCreateMutation, bulkInputs(100))
+
+	require.NoError(t, err)
+	assert.Greater(t, cost, config.AnonymousMaxComplexity)
+}
+
+func TestAnalyzer_ChargeBatch(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxComplexity = 10
+	analyzer := NewAnalyzer(config)
+
+	// Outside a batch each operation is only held to its own limit
+	assert.NoError(t, analyzer.ChargeBatch(context.Background(), 8))
+	assert.NoError(t, analyzer.ChargeBatch(context.Background(), 8))
+
+	ctx := WithBatch(context.Background())
+	assert.NoError(t, analyzer.ChargeBatch(ctx, 6))
+	assert.NoError(t, analyzer.ChargeBatch(ctx, 4))
+	assert.EqualError(t, analyzer.ChargeBatch(ctx, 1), "batch complexity 11 exceeds maximum allowed complexity 10")
+}
+
+func TestComplexityMiddleware_ReportsDepthRejection(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxDepth = 3
+	var rejections []Rejection
+	config.OnReject = func(ctx context.Context, rejection Rejection) {
+		rejections = append(rejections, rejection)
+	}
+	extension := NewAnalyzer(config).ComplexityMiddleware().(graphql.OperationInterceptor)
+
+	rc := operationOf(t, `query Deep { posts { edges { node { author { name } } } } }`, nil)
+	rc.OperationName = "Deep"
+	ctx := graphql.WithOperationContext(context.Background(), rc)
+	response := extension.InterceptOperation(ctx, func(ctx context.Context) graphql.ResponseHandler {
+		t.Fatal("rejected operation was executed")
+		return nil
+	})(ctx)
+
+	require.Len(t, response.Errors, 1)
+	require.Len(t, rejections, 1)
+	assert.Equal(t, "Deep", rejections[0].Operation)
+	assert.Equal(t, LimitDepth, rejections[0].Limit)
+	assert.Equal(t, 5, rejections[0].Depth)
+	assert.Equal(t, 3, rejections[0].Max)
+	assert.Positive(t, rejections[0].Complexity)
+	assert.EqualError(t, rejections[0].Err, "query depth 5 exceeds maximum allowed depth 3")
+}
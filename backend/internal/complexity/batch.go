@@ -0,0 +1,42 @@
+package complexity
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// batchKey is the context key for the complexity shared by a batched request
+type batchKey struct{}
+
+// batchTotal accumulates the complexity of the operations in one batch
+type batchTotal struct {
+	mu         sync.Mutex
+	complexity int
+}
+
+// WithBatch marks ctx as carrying the operations of one batched request, so
+// their complexity counts against a single limit rather than one each
+func WithBatch(ctx context.Context) context.Context {
+	return context.WithValue(ctx, batchKey{}, &batchTotal{})
+}
+
+// ChargeBatch adds complexity to the batch in ctx and fails once the batch as
+// a whole exceeds the caller's maximum complexity. It does nothing outside a batch.
+func (a *Analyzer) ChargeBatch(ctx context.Context, complexity int) error {
+	batch, ok := ctx.Value(batchKey{}).(*batchTotal)
+	if !ok {
+		return nil
+	}
+
+	batch.mu.Lock()
+	batch.complexity += complexity
+	total := batch.complexity
+	batch.mu.Unlock()
+
+	maxComplexity, _ := a.limits(ctx)
+	if total > maxComplexity {
+		return fmt.Errorf("batch complexity %d exceeds maximum allowed complexity %d", total, maxComplexity)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package complexity
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// Limits an operation can be rejected for
+const (
+	LimitComplexity      = "complexity"
+	LimitDepth           = "depth"
+	LimitBatchComplexity = "batch_complexity"
+)
+
+// Rejection describes an operation refused for exceeding a limit
+type Rejection struct {
+	Operation  string
+	Limit      string
+	Complexity int
+	Depth      int
+	// Max is the value of the limit that was exceeded
+	Max int
+	Err error
+}
+
+// reject reports a refused operation to the OnReject hook, if one is set
+func (e *complexityExtension) reject(ctx context.Context, rc *graphql.OperationContext, limit string, complexity int, err error) {
+	onReject := e.analyzer.config.OnReject
+	if onReject == nil {
+		return
+	}
+
+	maxComplexity, maxDepth := e.analyzer.limits(ctx)
+	rejection := Rejection{
+		Operation:  rc.OperationName,
+		Limit:      limit,
+		Complexity: complexity,
+		Depth:      e.analyzer.calculateMaxDepth(rc.Operation.SelectionSet, 1),
+		Max:        maxComplexity,
+		Err:        err,
+	}
+	if limit == LimitDepth {
+		rejection.Max = maxDepth
+	}
+	onReject(ctx, rejection)
+}
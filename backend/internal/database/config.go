@@ -13,6 +13,9 @@ type Config struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	// ReadReplicaDSN optionally points reads at a replica
+	ReadReplicaDSN string
 }
 
 // NewConfig creates a new database configuration from environment variables
@@ -24,6 +27,8 @@ func NewConfig() *Config {
 		Password: getEnv("DB_PASSWORD", "postgres"),
 		DBName:   getEnv("DB_NAME", "graphql_typescript_go"),
 		SSLMode:  getEnv("DB_SSL_MODE", "disable"),
+
+		ReadReplicaDSN: getEnv("DB_READ_REPLICA_URL", ""),
 	}
 }
 
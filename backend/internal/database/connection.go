@@ -6,18 +6,70 @@ import (
 	"log"
 	"time"
 
+	"backend/internal/logging"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// DB holds the database connection pool
+// Querier is the subset of a connection pool used by repositories
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// DB holds the database connection pools
 type DB struct {
 	Pool *pgxpool.Pool
+	// ReadPool is connected to the read replica; nil when none is configured
+	ReadPool *pgxpool.Pool
+
+	writer Querier
+	reader Querier
 }
 
-// NewConnection creates a new database connection with connection pooling
+// NewConnection creates a new database connection with connection pooling.
+// When a read replica is configured a second pool is opened for reads.
 func NewConnection(config *Config) (*DB, error) {
+	pool, err := newPool(config.ConnectionString())
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Database connected successfully to %s:%s/%s", 
+		config.Host, config.Port, config.DBName)
+
+	db := &DB{Pool: pool, writer: pool, reader: pool}
+
+	if config.ReadReplicaDSN != "" {
+		readPool, err := newPool(config.ReadReplicaDSN)
+		if err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("read replica: %w", err)
+		}
+
+		log.Println("✅ Read replica connected successfully")
+		db.ReadPool = readPool
+		db.reader = readPool
+	}
+
+	return db, nil
+}
+
+// NewDBWithQueriers creates a DB that routes writes and reads to the given
+// queriers. A nil reader falls back to the writer.
+func NewDBWithQueriers(writer, reader Querier) *DB {
+	if reader == nil {
+		reader = writer
+	}
+	return &DB{writer: writer, reader: reader}
+}
+
+// newPool opens and pings a connection pool for the given DSN
+func newPool(dsn string) (*pgxpool.Pool, error) {
 	// Configure connection pool
-	poolConfig, err := pgxpool.ParseConfig(config.ConnectionString())
+	poolConfig, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse database config: %w", err)
 	}
@@ -43,21 +95,104 @@ func NewConnection(config *Config) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	log.Printf("✅ Database connected successfully to %s:%s/%s", 
-		config.Host, config.Port, config.DBName)
+	return pool, nil
+}
+
+// Writer returns the querier for writes and reads that must see the latest data
+func (db *DB) Writer() Querier {
+	return countedQuerier{db.primary()}
+}
+
+// Reader returns the querier for read-only operations, preferring the replica
+func (db *DB) Reader() Querier {
+	if db.reader != nil {
+		return countedQuerier{db.reader}
+	}
+	if db.ReadPool != nil {
+		return countedQuerier{db.ReadPool}
+	}
+	return db.Writer()
+}
+
+// primary returns the uncounted querier for the primary
+func (db *DB) primary() Querier {
+	if db.writer != nil {
+		return db.writer
+	}
+	return db.Pool
+}
+
+// countedQuerier records each round trip in the operation stats of the
+// query's context, if any
+type countedQuerier struct {
+	Querier
+}
+
+func (q countedQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	logging.StatsFromContext(ctx).AddDBQuery()
+	return q.Querier.Exec(ctx, sql, args...)
+}
+
+func (q countedQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	logging.StatsFromContext(ctx).AddDBQuery()
+	return q.Querier.Query(ctx, sql, args...)
+}
+
+func (q countedQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	logging.StatsFromContext(ctx).AddDBQuery()
+	return q.Querier.QueryRow(ctx, sql, args...)
+}
+
+// txBeginner is implemented by queriers that can open transactions, such as a pool
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// WithTx runs fn in a transaction on the primary. The transaction commits
+// when fn returns nil and rolls back otherwise.
+func (db *DB) WithTx(ctx context.Context, fn func(tx Querier) error) error {
+	beginner, ok := db.primary().(txBeginner)
+	if !ok {
+		return fmt.Errorf("database writer does not support transactions")
+	}
 
-	return &DB{Pool: pool}, nil
+	tx, err := beginner.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	// Rollback is a no-op once the transaction has committed
+	defer tx.Rollback(ctx)
+
+	if err := fn(countedQuerier{tx}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
 }
 
-// Close closes the database connection pool
+// Close closes the database connection pools
 func (db *DB) Close() {
+	if db.ReadPool != nil {
+		db.ReadPool.Close()
+	}
 	if db.Pool != nil {
 		db.Pool.Close()
 		log.Println("🔌 Database connection closed")
 	}
 }
 
-// Health checks if the database connection is healthy
+// Health checks if the database connections are healthy
 func (db *DB) Health(ctx context.Context) error {
-	return db.Pool.Ping(ctx)
-}
\ No newline at end of file
+	if err := db.Pool.Ping(ctx); err != nil {
+		return err
+	}
+	if db.ReadPool != nil {
+		if err := db.ReadPool.Ping(ctx); err != nil {
+			return fmt.Errorf("read replica: %w", err)
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log"
 
@@ -9,8 +10,16 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
-// RunMigrations runs database migrations
+// RunMigrations runs database migrations. Instances starting together
+// take turns through an advisory lock rather than migrating concurrently.
 func RunMigrations(config *Config) error {
+	return withMigrationLock(context.Background(), config.ConnectionString(), func() error {
+		return runMigrations(config)
+	})
+}
+
+// runMigrations applies pending migrations; the caller holds the migration lock
+func runMigrations(config *Config) error {
 	// Create migration instance
 	m, err := migrate.New(
 		"file://migrations",
@@ -30,8 +39,16 @@ func RunMigrations(config *Config) error {
 	return nil
 }
 
-// RollbackMigrations rolls back database migrations
+// RollbackMigrations rolls back database migrations, holding the same
+// advisory lock as RunMigrations
 func RollbackMigrations(config *Config, steps int) error {
+	return withMigrationLock(context.Background(), config.ConnectionString(), func() error {
+		return rollbackMigrations(config, steps)
+	})
+}
+
+// rollbackMigrations rolls back steps migrations; the caller holds the migration lock
+func rollbackMigrations(config *Config, steps int) error {
 	m, err := migrate.New(
 		"file://migrations",
 		config.ConnectionString(),
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// migrationLockID is the Postgres advisory lock key held while migrating.
+// Every instance uses the same key, so only one migrates at a time.
+const migrationLockID int64 = 0x6e7563756c6f
+
+// lockConn is the connection the migration lock is held on
+type lockConn interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Close(ctx context.Context) error
+}
+
+// connectLock opens the connection the migration lock is held on
+var connectLock = func(ctx context.Context, dsn string) (lockConn, error) {
+	return pgx.Connect(ctx, dsn)
+}
+
+// withMigrationLock runs fn while holding the migration advisory lock.
+// Another instance calling it waits in pg_advisory_lock until fn returns
+// here, then sees the migrations already applied. The lock is session
+// scoped, so Postgres also releases it if this process dies mid-migration.
+func withMigrationLock(ctx context.Context, dsn string, fn func() error) error {
+	conn, err := connectLock(ctx, dsn)
+	if err != nil {
+		return fmt.Errorf("failed to connect for migration lock: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	log.Println("⏳ Waiting for migration lock...")
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		// Closing the connection releases the lock even if this fails
+		if _, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID); err != nil {
+			log.Printf("⚠️  Failed to release migration lock: %v", err)
+		}
+	}()
+
+	return fn()
+}
@@ -0,0 +1,165 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLockServer mimics Postgres session advisory locks: pg_advisory_lock
+// blocks while another connection holds the key, and closing a connection
+// releases what it holds
+type fakeLockServer struct {
+	mu     sync.Mutex
+	held   map[int64]*fakeLockConn
+	freed  *sync.Cond
+	failOn string
+}
+
+func newFakeLockServer() *fakeLockServer {
+	server := &fakeLockServer{held: map[int64]*fakeLockConn{}}
+	server.freed = sync.NewCond(&server.mu)
+	return server
+}
+
+func (s *fakeLockServer) connect(ctx context.Context, dsn string) (lockConn, error) {
+	return &fakeLockConn{server: s}, nil
+}
+
+func (s *fakeLockServer) holder(key int64) *fakeLockConn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.held[key]
+}
+
+type fakeLockConn struct {
+	server *fakeLockServer
+}
+
+func (c *fakeLockConn) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	s := c.server
+	if s.failOn != "" && strings.Contains(sql, s.failOn) {
+		return pgconn.CommandTag{}, errors.New("connection reset")
+	}
+
+	key := args[0].(int64)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case strings.Contains(sql, "pg_advisory_lock"):
+		for s.held[key] != nil && s.held[key] != c {
+			s.freed.Wait()
+		}
+		s.held[key] = c
+	case strings.Contains(sql, "pg_advisory_unlock"):
+		if s.held[key] == c {
+			delete(s.held, key)
+			s.freed.Broadcast()
+		}
+	}
+	return pgconn.NewCommandTag("SELECT 1"), nil
+}
+
+func (c *fakeLockConn) Close(ctx context.Context) error {
+	s := c.server
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, holder := range s.held {
+		if holder == c {
+			delete(s.held, key)
+		}
+	}
+	s.freed.Broadcast()
+	return nil
+}
+
+// useFakeLockServer makes withMigrationLock connect to a fake server for the test
+func useFakeLockServer(t *testing.T) *fakeLockServer {
+	server := newFakeLockServer()
+	original := connectLock
+	connectLock = server.connect
+	t.Cleanup(func() { connectLock = original })
+	return server
+}
+
+func TestWithMigrationLock_SecondCallerWaits(t *testing.T) {
+	useFakeLockServer(t)
+	ctx := context.Background()
+
+	firstEntered := make(chan struct{})
+	releaseFirst := make(chan struct{})
+	firstDone := make(chan error, 1)
+	go func() {
+		firstDone <- withMigrationLock(ctx, "", func() error {
+			close(firstEntered)
+			<-releaseFirst
+			return nil
+		})
+	}()
+	<-firstEntered
+
+	var firstFinished bool
+	var mu sync.Mutex
+	secondEntered := make(chan bool, 1)
+	secondDone := make(chan error, 1)
+	go func() {
+		secondDone <- withMigrationLock(ctx, "", func() error {
+			mu.Lock()
+			secondEntered <- firstFinished
+			mu.Unlock()
+			return nil
+		})
+	}()
+
+	select {
+	case <-secondEntered:
+		t.Fatal("second migration ran while the first held the lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	mu.Lock()
+	close(releaseFirst)
+	require.NoError(t, <-firstDone)
+	firstFinished = true
+	mu.Unlock()
+
+	select {
+	case sawFirstFinished := <-secondEntered:
+		assert.True(t, sawFirstFinished, "second migration started before the first returned")
+	case <-time.After(time.Second):
+		t.Fatal("second migration never acquired the lock")
+	}
+	require.NoError(t, <-secondDone)
+}
+
+func TestWithMigrationLock_ReleasesOnError(t *testing.T) {
+	server := useFakeLockServer(t)
+	migrationErr := errors.New("dirty database version 7")
+
+	err := withMigrationLock(context.Background(), "", func() error {
+		assert.NotNil(t, server.holder(migrationLockID), "lock not held while migrating")
+		return migrationErr
+	})
+
+	assert.ErrorIs(t, err, migrationErr)
+	assert.Nil(t, server.holder(migrationLockID))
+}
+
+func TestWithMigrationLock_LockFailureSkipsMigration(t *testing.T) {
+	server := useFakeLockServer(t)
+	server.failOn = "pg_advisory_lock"
+
+	err := withMigrationLock(context.Background(), "", func() error {
+		t.Fatal("migrated without the lock")
+		return nil
+	})
+
+	assert.ErrorContains(t, err, "failed to acquire migration lock")
+}
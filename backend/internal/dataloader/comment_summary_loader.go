@@ -0,0 +1,76 @@
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend/internal/graph/model"
+	"backend/internal/repository"
+	"github.com/google/uuid"
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+// CommentSummaryLoader batches comment counts and latest comments keyed by post ID
+type CommentSummaryLoader struct {
+	commentRepo repository.CommentRepository
+	loader      *dataloader.Loader[uuid.UUID, *model.CommentSummary]
+}
+
+// NewCommentSummaryLoader creates a new CommentSummaryLoader with DataLoader
+func NewCommentSummaryLoader(commentRepo repository.CommentRepository) *CommentSummaryLoader {
+	cl := &CommentSummaryLoader{
+		commentRepo: commentRepo,
+	}
+
+	// Create the DataLoader with batch function
+	cl.loader = dataloader.NewBatchedLoader(
+		cl.batchSummarizeComments,
+		dataloader.WithWait[uuid.UUID, *model.CommentSummary](time.Millisecond*10), // Wait 10ms to batch requests
+		dataloader.WithBatchCapacity[uuid.UUID, *model.CommentSummary](100),        // Max 100 items per batch
+	)
+
+	return cl
+}
+
+// Load loads the comment summary for a single post using DataLoader
+func (cl *CommentSummaryLoader) Load(ctx context.Context, postID uuid.UUID) (*model.CommentSummary, error) {
+	return cl.loader.Load(ctx, postID)()
+}
+
+// Clear clears the cached summary for a specific post ID
+func (cl *CommentSummaryLoader) Clear(ctx context.Context, postID uuid.UUID) {
+	cl.loader.Clear(ctx, postID)
+}
+
+// ClearAll clears all cached summaries
+func (cl *CommentSummaryLoader) ClearAll() {
+	cl.loader.ClearAll()
+}
+
+// batchSummarizeComments is the batch function that summarizes comments for many posts at once
+func (cl *CommentSummaryLoader) batchSummarizeComments(ctx context.Context, postIDs []uuid.UUID) []*dataloader.Result[*model.CommentSummary] {
+	summaries, err := cl.commentRepo.SummaryByPostIDs(ctx, postIDs)
+	if err != nil {
+		// If there's an error, return error for all requested IDs
+		results := make([]*dataloader.Result[*model.CommentSummary], len(postIDs))
+		for i := range postIDs {
+			results[i] = &dataloader.Result[*model.CommentSummary]{
+				Error: fmt.Errorf("failed to load comment summaries: %w", err),
+			}
+		}
+		return results
+	}
+
+	// Posts without comments are absent from summaries and resolve to an empty summary
+	results := make([]*dataloader.Result[*model.CommentSummary], len(postIDs))
+	for i, postID := range postIDs {
+		summary, ok := summaries[postID]
+		if !ok {
+			summary = &model.CommentSummary{}
+		}
+		results[i] = &dataloader.Result[*model.CommentSummary]{Data: summary}
+	}
+
+	return results
+}
@@ -0,0 +1,57 @@
+package dataloader
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"backend/internal/graph/model"
+	"backend/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingCommentRepo records every summary batch it receives
+type countingCommentRepo struct {
+	repository.CommentRepository
+	mu        sync.Mutex
+	summaries map[uuid.UUID]*model.CommentSummary
+	calls     [][]uuid.UUID
+}
+
+func (r *countingCommentRepo) SummaryByPostIDs(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID]*model.CommentSummary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, postIDs)
+	summaries := make(map[uuid.UUID]*model.CommentSummary)
+	for _, id := range postIDs {
+		if summary, ok := r.summaries[id]; ok {
+			summaries[id] = summary
+		}
+	}
+	return summaries, nil
+}
+
+func TestCommentSummaryLoader_BatchesPostList(t *testing.T) {
+	postIDs := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	latest := &model.Comment{ID: uuid.New(), PostID: postIDs[0], Content: "Newest"}
+	repo := &countingCommentRepo{summaries: map[uuid.UUID]*model.CommentSummary{
+		postIDs[0]: {Count: 4, Latest: latest},
+	}}
+	loader := NewCommentSummaryLoader(repo)
+	ctx := context.Background()
+
+	summaries, errs := loadConcurrently(postIDs, func(id uuid.UUID) (*model.CommentSummary, error) {
+		return loader.Load(ctx, id)
+	})
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	assert.Equal(t, &model.CommentSummary{Count: 4, Latest: latest}, summaries[0])
+	assert.Equal(t, &model.CommentSummary{}, summaries[1])
+	assert.Equal(t, &model.CommentSummary{}, summaries[2])
+	require.Len(t, repo.calls, 1)
+	assert.ElementsMatch(t, postIDs, repo.calls[0])
+}
@@ -5,9 +5,10 @@ import (
 	"fmt"
 	"net/http"
 
-	"backend/graph/model"
+	"backend/internal/graph/model"
 	"backend/internal/repository"
 	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
 )
 
 // contextKey is used for storing DataLoader in context
@@ -21,6 +22,11 @@ const (
 type Loaders struct {
 	UserLoader *UserLoader
 	PostLoader *PostLoader
+
+	ReactionCountLoader     *ReactionCountLoader
+	ReactionTypeCountLoader *ReactionTypeCountLoader
+	ViewerReactionLoader    *ViewerReactionLoader
+	CommentSummaryLoader    *CommentSummaryLoader
 }
 
 // NewLoaders creates a new set of DataLoaders
@@ -28,20 +34,57 @@ func NewLoaders(repos *repository.Manager) *Loaders {
 	return &Loaders{
 		UserLoader: NewUserLoader(repos.User),
 		PostLoader: NewPostLoader(repos.Post),
+
+		ReactionCountLoader:     NewReactionCountLoader(repos.Reaction),
+		ReactionTypeCountLoader: NewReactionTypeCountLoader(repos.Reaction),
+		ViewerReactionLoader:    NewViewerReactionLoader(repos.Reaction),
+		CommentSummaryLoader:    NewCommentSummaryLoader(repos.Comment),
+	}
+}
+
+// ClearAll empties every loader's cache so later loads go back to the repositories
+func (l *Loaders) ClearAll() {
+	if l.UserLoader != nil {
+		l.UserLoader.ClearAll()
+	}
+	if l.PostLoader != nil {
+		l.PostLoader.ClearAll()
+	}
+	if l.ReactionCountLoader != nil {
+		l.ReactionCountLoader.ClearAll()
+	}
+	if l.ReactionTypeCountLoader != nil {
+		l.ReactionTypeCountLoader.ClearAll()
+	}
+	if l.ViewerReactionLoader != nil {
+		l.ViewerReactionLoader.ClearAll()
+	}
+	if l.CommentSummaryLoader != nil {
+		l.CommentSummaryLoader.ClearAll()
 	}
 }
 
-// Middleware creates a middleware that adds DataLoaders to the context
+// Middleware creates a middleware that adds DataLoaders to the context.
+// WebSocket connections get none: they live for many operations, which
+// would be served from one cache for as long as the connection stays open.
 func Middleware(repos *repository.Manager) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			loaders := NewLoaders(repos)
-			ctx := context.WithValue(r.Context(), loadersKey, loaders)
+			if websocket.IsWebSocketUpgrade(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx := WithLoaders(r.Context(), NewLoaders(repos))
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// WithLoaders returns a context carrying the given DataLoaders
+func WithLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey, loaders)
+}
+
 // For returns the DataLoaders from the context
 func For(ctx context.Context) *Loaders {
 	loaders, ok := ctx.Value(loadersKey).(*Loaders)
@@ -0,0 +1,78 @@
+package dataloader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoaders_ClearAllRefetches(t *testing.T) {
+	ctx := context.Background()
+	postID, viewerID := uuid.New(), uuid.New()
+
+	reactions := &countingReactionRepo{counts: map[uuid.UUID]int{postID: 3}}
+	comments := &countingCommentRepo{}
+	loaders := NewLoaders(&repository.Manager{Reaction: reactions, Comment: comments})
+
+	load := func() int {
+		count, err := loaders.ReactionCountLoader.Load(ctx, postID)
+		require.NoError(t, err)
+		_, err = loaders.ViewerReactionLoader.Load(ctx, postID, viewerID)
+		require.NoError(t, err)
+		_, err = loaders.CommentSummaryLoader.Load(ctx, postID)
+		require.NoError(t, err)
+		return count
+	}
+
+	assert.Equal(t, 3, load())
+	reactions.counts[postID] = 4
+	assert.Equal(t, 3, load(), "loaded values are cached")
+	assert.Len(t, reactions.countCalls, 1)
+	assert.Len(t, reactions.viewerCalls, 1)
+	assert.Len(t, comments.calls, 1)
+
+	loaders.ClearAll()
+
+	assert.Equal(t, 4, load(), "cleared loaders go back to the repositories")
+	assert.Len(t, reactions.countCalls, 2)
+	assert.Len(t, reactions.viewerCalls, 2)
+	assert.Len(t, comments.calls, 2)
+}
+
+func TestLoaders_ClearAllSkipsMissingLoaders(t *testing.T) {
+	assert.NotPanics(t, func() { (&Loaders{}).ClearAll() })
+}
+
+func TestMiddleware_AddsLoadersPerRequest(t *testing.T) {
+	var seen []*Loaders
+	handler := Middleware(&repository.Manager{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, For(r.Context()))
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/graphql", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/graphql", nil))
+
+	require.Len(t, seen, 2)
+	require.NotNil(t, seen[0])
+	assert.NotSame(t, seen[0], seen[1], "requests share no loader cache")
+}
+
+func TestMiddleware_SkipsWebSocketUpgrades(t *testing.T) {
+	var loaders *Loaders
+	handler := Middleware(&repository.Manager{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		loaders = For(r.Context())
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/graphql", nil)
+	request.Header.Set("Connection", "Upgrade")
+	request.Header.Set("Upgrade", "websocket")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	assert.Nil(t, loaders)
+}
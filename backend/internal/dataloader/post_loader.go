@@ -5,22 +5,28 @@ import (
 	"fmt"
 	"time"
 
-	"backend/graph/model"
+	"backend/internal/graph/model"
 	"backend/internal/repository"
 	"github.com/google/uuid"
 	"github.com/graph-gophers/dataloader/v7"
 )
 
+// DefaultAuthorPostsLimit is how many of each author's newest posts LoadByAuthor returns
+const DefaultAuthorPostsLimit = 10
+
 // PostLoader wraps the Post repository with DataLoader functionality
 type PostLoader struct {
-	postRepo repository.PostRepository
-	loader   *dataloader.Loader[uuid.UUID, *model.Post]
+	postRepo     repository.PostRepository
+	loader       *dataloader.Loader[uuid.UUID, *model.Post]
+	authorLoader *dataloader.Loader[uuid.UUID, []*model.Post]
+	authorLimit  int
 }
 
 // NewPostLoader creates a new PostLoader with DataLoader
 func NewPostLoader(postRepo repository.PostRepository) *PostLoader {
 	pl := &PostLoader{
-		postRepo: postRepo,
+		postRepo:    postRepo,
+		authorLimit: DefaultAuthorPostsLimit,
 	}
 
 	// Create the DataLoader with batch function
@@ -29,28 +35,40 @@ func NewPostLoader(postRepo repository.PostRepository) *PostLoader {
 		dataloader.WithWait[uuid.UUID, *model.Post](time.Millisecond*10), // Wait 10ms to batch requests
 		dataloader.WithBatchCapacity[uuid.UUID, *model.Post](100),         // Max 100 items per batch
 	)
+	pl.authorLoader = dataloader.NewBatchedLoader(
+		pl.batchGetPostsByAuthor,
+		dataloader.WithWait[uuid.UUID, []*model.Post](time.Millisecond*10),
+		dataloader.WithBatchCapacity[uuid.UUID, []*model.Post](100),
+	)
 
 	return pl
 }
 
 // Load loads a single post by ID using DataLoader
 func (pl *PostLoader) Load(ctx context.Context, postID uuid.UUID) (*model.Post, error) {
-	return pl.loader.Load(ctx, postID)
+	return pl.loader.Load(ctx, postID)()
 }
 
 // LoadMany loads multiple posts by IDs using DataLoader
 func (pl *PostLoader) LoadMany(ctx context.Context, postIDs []uuid.UUID) ([]*model.Post, []error) {
-	return pl.loader.LoadMany(ctx, postIDs)
+	return pl.loader.LoadMany(ctx, postIDs)()
+}
+
+// LoadByAuthor loads an author's newest published posts using DataLoader, so
+// the posts of every author in a response come from one query
+func (pl *PostLoader) LoadByAuthor(ctx context.Context, authorID uuid.UUID) ([]*model.Post, error) {
+	return pl.authorLoader.Load(ctx, authorID)()
 }
 
 // Clear clears the cache for a specific post ID
-func (pl *PostLoader) Clear(postID uuid.UUID) {
-	pl.loader.Clear(postID)
+func (pl *PostLoader) Clear(ctx context.Context, postID uuid.UUID) {
+	pl.loader.Clear(ctx, postID)
 }
 
-// ClearAll clears all cached posts
+// ClearAll clears all cached posts and author post lists
 func (pl *PostLoader) ClearAll() {
 	pl.loader.ClearAll()
+	pl.authorLoader.ClearAll()
 }
 
 // batchGetPosts is the batch function that loads multiple posts at once
@@ -89,4 +107,31 @@ func (pl *PostLoader) batchGetPosts(ctx context.Context, postIDs []uuid.UUID) []
 	}
 
 	return results
-}
\ No newline at end of file
+}
+
+// batchGetPostsByAuthor is the batch function that loads the newest posts of many authors at once
+func (pl *PostLoader) batchGetPostsByAuthor(ctx context.Context, authorIDs []uuid.UUID) []*dataloader.Result[[]*model.Post] {
+	postsByAuthor, err := pl.postRepo.GetPublishedByAuthorIDs(ctx, authorIDs, pl.authorLimit)
+	if err != nil {
+		// If there's an error, return error for all requested IDs
+		results := make([]*dataloader.Result[[]*model.Post], len(authorIDs))
+		for i := range authorIDs {
+			results[i] = &dataloader.Result[[]*model.Post]{
+				Error: fmt.Errorf("failed to load posts by author: %w", err),
+			}
+		}
+		return results
+	}
+
+	// Authors without posts are absent from postsByAuthor and resolve to an empty list
+	results := make([]*dataloader.Result[[]*model.Post], len(authorIDs))
+	for i, authorID := range authorIDs {
+		posts, ok := postsByAuthor[authorID]
+		if !ok {
+			posts = []*model.Post{}
+		}
+		results[i] = &dataloader.Result[[]*model.Post]{Data: posts}
+	}
+
+	return results
+}
@@ -0,0 +1,79 @@
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"backend/internal/graph/model"
+	"backend/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// authorPostsRepo records every author batch it receives
+type authorPostsRepo struct {
+	repository.PostRepository
+	mu     sync.Mutex
+	posts  map[uuid.UUID][]*model.Post
+	calls  [][]uuid.UUID
+	limits []int
+	err    error
+}
+
+func (r *authorPostsRepo) GetPublishedByAuthorIDs(ctx context.Context, authorIDs []uuid.UUID, limit int) (map[uuid.UUID][]*model.Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, authorIDs)
+	r.limits = append(r.limits, limit)
+	if r.err != nil {
+		return nil, r.err
+	}
+	postsByAuthor := make(map[uuid.UUID][]*model.Post)
+	for _, id := range authorIDs {
+		if posts, ok := r.posts[id]; ok {
+			postsByAuthor[id] = posts
+		}
+	}
+	return postsByAuthor, nil
+}
+
+func TestPostLoader_LoadByAuthorBatchesAuthors(t *testing.T) {
+	authorIDs := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	first := &model.Post{ID: uuid.New(), AuthorID: authorIDs[0]}
+	second := &model.Post{ID: uuid.New(), AuthorID: authorIDs[0]}
+	other := &model.Post{ID: uuid.New(), AuthorID: authorIDs[1]}
+	repo := &authorPostsRepo{posts: map[uuid.UUID][]*model.Post{
+		authorIDs[0]: {first, second},
+		authorIDs[1]: {other},
+	}}
+	loader := NewPostLoader(repo)
+	ctx := context.Background()
+
+	posts, errs := loadConcurrently(authorIDs, func(id uuid.UUID) ([]*model.Post, error) {
+		return loader.LoadByAuthor(ctx, id)
+	})
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []*model.Post{first, second}, posts[0])
+	assert.Equal(t, []*model.Post{other}, posts[1])
+	assert.NotNil(t, posts[2])
+	assert.Empty(t, posts[2])
+	require.Len(t, repo.calls, 1)
+	assert.ElementsMatch(t, authorIDs, repo.calls[0])
+	assert.Equal(t, []int{DefaultAuthorPostsLimit}, repo.limits)
+}
+
+func TestPostLoader_LoadByAuthorReportsErrors(t *testing.T) {
+	repo := &authorPostsRepo{err: fmt.Errorf("connection refused")}
+	loader := NewPostLoader(repo)
+
+	_, err := loader.LoadByAuthor(context.Background(), uuid.New())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection refused")
+}
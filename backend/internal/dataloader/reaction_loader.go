@@ -0,0 +1,202 @@
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend/internal/graph/model"
+	"backend/internal/repository"
+	"github.com/google/uuid"
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+// ReactionCountLoader batches reaction counts keyed by post ID
+type ReactionCountLoader struct {
+	reactionRepo repository.ReactionRepository
+	loader       *dataloader.Loader[uuid.UUID, int]
+}
+
+// NewReactionCountLoader creates a new ReactionCountLoader with DataLoader
+func NewReactionCountLoader(reactionRepo repository.ReactionRepository) *ReactionCountLoader {
+	rl := &ReactionCountLoader{
+		reactionRepo: reactionRepo,
+	}
+
+	// Create the DataLoader with batch function
+	rl.loader = dataloader.NewBatchedLoader(
+		rl.batchCountReactions,
+		dataloader.WithWait[uuid.UUID, int](time.Millisecond*10), // Wait 10ms to batch requests
+		dataloader.WithBatchCapacity[uuid.UUID, int](100),         // Max 100 items per batch
+	)
+
+	return rl
+}
+
+// Load loads the reaction count for a single post using DataLoader
+func (rl *ReactionCountLoader) Load(ctx context.Context, postID uuid.UUID) (int, error) {
+	return rl.loader.Load(ctx, postID)()
+}
+
+// Clear clears the cached count for a specific post ID
+func (rl *ReactionCountLoader) Clear(ctx context.Context, postID uuid.UUID) {
+	rl.loader.Clear(ctx, postID)
+}
+
+// ClearAll clears all cached counts
+func (rl *ReactionCountLoader) ClearAll() {
+	rl.loader.ClearAll()
+}
+
+// batchCountReactions is the batch function that counts reactions for many posts at once
+func (rl *ReactionCountLoader) batchCountReactions(ctx context.Context, postIDs []uuid.UUID) []*dataloader.Result[int] {
+	counts, err := rl.reactionRepo.CountByPostIDs(ctx, postIDs)
+	if err != nil {
+		// If there's an error, return error for all requested IDs
+		results := make([]*dataloader.Result[int], len(postIDs))
+		for i := range postIDs {
+			results[i] = &dataloader.Result[int]{
+				Error: fmt.Errorf("failed to load reaction counts: %w", err),
+			}
+		}
+		return results
+	}
+
+	// Posts without reactions are absent from counts and resolve to zero
+	results := make([]*dataloader.Result[int], len(postIDs))
+	for i, postID := range postIDs {
+		results[i] = &dataloader.Result[int]{Data: counts[postID]}
+	}
+
+	return results
+}
+
+// ReactionTypeCountLoader batches per-type reaction counts keyed by post ID
+type ReactionTypeCountLoader struct {
+	reactionRepo repository.ReactionRepository
+	loader       *dataloader.Loader[uuid.UUID, []*model.ReactionTypeCount]
+}
+
+// NewReactionTypeCountLoader creates a new ReactionTypeCountLoader with DataLoader
+func NewReactionTypeCountLoader(reactionRepo repository.ReactionRepository) *ReactionTypeCountLoader {
+	tl := &ReactionTypeCountLoader{
+		reactionRepo: reactionRepo,
+	}
+
+	// Create the DataLoader with batch function
+	tl.loader = dataloader.NewBatchedLoader(
+		tl.batchCountReactionTypes,
+		dataloader.WithWait[uuid.UUID, []*model.ReactionTypeCount](time.Millisecond*10), // Wait 10ms to batch requests
+		dataloader.WithBatchCapacity[uuid.UUID, []*model.ReactionTypeCount](100),        // Max 100 items per batch
+	)
+
+	return tl
+}
+
+// Load loads the per-type reaction counts for a single post using DataLoader
+func (tl *ReactionTypeCountLoader) Load(ctx context.Context, postID uuid.UUID) ([]*model.ReactionTypeCount, error) {
+	return tl.loader.Load(ctx, postID)()
+}
+
+// ClearAll clears all cached counts
+func (tl *ReactionTypeCountLoader) ClearAll() {
+	tl.loader.ClearAll()
+}
+
+// batchCountReactionTypes is the batch function that counts reactions by type for many posts at once
+func (tl *ReactionTypeCountLoader) batchCountReactionTypes(ctx context.Context, postIDs []uuid.UUID) []*dataloader.Result[[]*model.ReactionTypeCount] {
+	counts, err := tl.reactionRepo.CountByTypeByPostIDs(ctx, postIDs)
+	if err != nil {
+		results := make([]*dataloader.Result[[]*model.ReactionTypeCount], len(postIDs))
+		for i := range postIDs {
+			results[i] = &dataloader.Result[[]*model.ReactionTypeCount]{
+				Error: fmt.Errorf("failed to load reaction type counts: %w", err),
+			}
+		}
+		return results
+	}
+
+	// Posts without reactions resolve to an empty list
+	results := make([]*dataloader.Result[[]*model.ReactionTypeCount], len(postIDs))
+	for i, postID := range postIDs {
+		typeCounts := counts[postID]
+		if typeCounts == nil {
+			typeCounts = []*model.ReactionTypeCount{}
+		}
+		results[i] = &dataloader.Result[[]*model.ReactionTypeCount]{Data: typeCounts}
+	}
+
+	return results
+}
+
+// ViewerReactionKey identifies whether a viewer has reacted to a post
+type ViewerReactionKey struct {
+	PostID   uuid.UUID
+	ViewerID uuid.UUID
+}
+
+// ViewerReactionLoader batches "has the viewer reacted" lookups keyed by (post ID, viewer ID)
+type ViewerReactionLoader struct {
+	reactionRepo repository.ReactionRepository
+	loader       *dataloader.Loader[ViewerReactionKey, bool]
+}
+
+// NewViewerReactionLoader creates a new ViewerReactionLoader with DataLoader
+func NewViewerReactionLoader(reactionRepo repository.ReactionRepository) *ViewerReactionLoader {
+	vl := &ViewerReactionLoader{
+		reactionRepo: reactionRepo,
+	}
+
+	// Create the DataLoader with batch function
+	vl.loader = dataloader.NewBatchedLoader(
+		vl.batchViewerReactions,
+		dataloader.WithWait[ViewerReactionKey, bool](time.Millisecond*10), // Wait 10ms to batch requests
+		dataloader.WithBatchCapacity[ViewerReactionKey, bool](100),         // Max 100 items per batch
+	)
+
+	return vl
+}
+
+// Load reports whether the viewer has reacted to the post using DataLoader
+func (vl *ViewerReactionLoader) Load(ctx context.Context, postID, viewerID uuid.UUID) (bool, error) {
+	return vl.loader.Load(ctx, ViewerReactionKey{PostID: postID, ViewerID: viewerID})()
+}
+
+// ClearAll clears all cached viewer reactions
+func (vl *ViewerReactionLoader) ClearAll() {
+	vl.loader.ClearAll()
+}
+
+// batchViewerReactions is the batch function that resolves viewer reactions with one query per viewer
+func (vl *ViewerReactionLoader) batchViewerReactions(ctx context.Context, keys []ViewerReactionKey) []*dataloader.Result[bool] {
+	// A request normally has a single viewer, so this is usually one query
+	postIDsByViewer := make(map[uuid.UUID][]uuid.UUID)
+	for _, key := range keys {
+		postIDsByViewer[key.ViewerID] = append(postIDsByViewer[key.ViewerID], key.PostID)
+	}
+
+	reacted := make(map[ViewerReactionKey]bool)
+	errs := make(map[uuid.UUID]error)
+	for viewerID, postIDs := range postIDsByViewer {
+		viewerReacted, err := vl.reactionRepo.ViewerReactedByPostIDs(ctx, viewerID, postIDs)
+		if err != nil {
+			errs[viewerID] = fmt.Errorf("failed to load viewer reactions: %w", err)
+			continue
+		}
+		for postID, ok := range viewerReacted {
+			reacted[ViewerReactionKey{PostID: postID, ViewerID: viewerID}] = ok
+		}
+	}
+
+	// Create results in the same order as requested keys
+	results := make([]*dataloader.Result[bool], len(keys))
+	for i, key := range keys {
+		if err, failed := errs[key.ViewerID]; failed {
+			results[i] = &dataloader.Result[bool]{Error: err}
+			continue
+		}
+		results[i] = &dataloader.Result[bool]{Data: reacted[key]}
+	}
+
+	return results
+}
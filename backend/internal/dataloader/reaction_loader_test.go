@@ -0,0 +1,166 @@
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"backend/internal/graph/model"
+	"backend/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingReactionRepo records every batch query it receives
+type countingReactionRepo struct {
+	repository.ReactionRepository
+	mu          sync.Mutex
+	counts      map[uuid.UUID]int
+	typeCounts  map[uuid.UUID][]*model.ReactionTypeCount
+	reacted     map[uuid.UUID]map[uuid.UUID]bool
+	countCalls  [][]uuid.UUID
+	typeCalls   [][]uuid.UUID
+	viewerCalls [][]uuid.UUID
+	err         error
+}
+
+func (r *countingReactionRepo) CountByTypeByPostIDs(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID][]*model.ReactionTypeCount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.typeCalls = append(r.typeCalls, postIDs)
+	if r.err != nil {
+		return nil, r.err
+	}
+	counts := make(map[uuid.UUID][]*model.ReactionTypeCount)
+	for _, id := range postIDs {
+		if typeCounts, ok := r.typeCounts[id]; ok {
+			counts[id] = typeCounts
+		}
+	}
+	return counts, nil
+}
+
+func (r *countingReactionRepo) CountByPostIDs(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.countCalls = append(r.countCalls, postIDs)
+	if r.err != nil {
+		return nil, r.err
+	}
+	counts := make(map[uuid.UUID]int)
+	for _, id := range postIDs {
+		if n, ok := r.counts[id]; ok {
+			counts[id] = n
+		}
+	}
+	return counts, nil
+}
+
+func (r *countingReactionRepo) ViewerReactedByPostIDs(ctx context.Context, viewerID uuid.UUID, postIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.viewerCalls = append(r.viewerCalls, postIDs)
+	reacted := make(map[uuid.UUID]bool)
+	for _, id := range postIDs {
+		if r.reacted[viewerID][id] {
+			reacted[id] = true
+		}
+	}
+	return reacted, nil
+}
+
+// loadConcurrently resolves one value per post the way sibling field resolvers would
+func loadConcurrently[V any](postIDs []uuid.UUID, load func(uuid.UUID) (V, error)) ([]V, []error) {
+	values := make([]V, len(postIDs))
+	errs := make([]error, len(postIDs))
+
+	var wg sync.WaitGroup
+	for i, id := range postIDs {
+		wg.Add(1)
+		go func(i int, id uuid.UUID) {
+			defer wg.Done()
+			values[i], errs[i] = load(id)
+		}(i, id)
+	}
+	wg.Wait()
+
+	return values, errs
+}
+
+func TestReactionCountLoader_BatchesPostList(t *testing.T) {
+	postIDs := []uuid.UUID{uuid.New(), uuid.New(), uuid.New(), uuid.New()}
+	repo := &countingReactionRepo{counts: map[uuid.UUID]int{postIDs[0]: 3, postIDs[2]: 1}}
+	loader := NewReactionCountLoader(repo)
+	ctx := context.Background()
+
+	counts, errs := loadConcurrently(postIDs, func(id uuid.UUID) (int, error) {
+		return loader.Load(ctx, id)
+	})
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []int{3, 0, 1, 0}, counts)
+	require.Len(t, repo.countCalls, 1)
+	assert.ElementsMatch(t, postIDs, repo.countCalls[0])
+}
+
+func TestReactionCountLoader_PropagatesErrors(t *testing.T) {
+	postIDs := []uuid.UUID{uuid.New(), uuid.New()}
+	repo := &countingReactionRepo{err: fmt.Errorf("connection refused")}
+	loader := NewReactionCountLoader(repo)
+	ctx := context.Background()
+
+	_, errs := loadConcurrently(postIDs, func(id uuid.UUID) (int, error) {
+		return loader.Load(ctx, id)
+	})
+
+	for _, err := range errs {
+		assert.ErrorContains(t, err, "failed to load reaction counts")
+	}
+	assert.Len(t, repo.countCalls, 1)
+}
+
+func TestReactionTypeCountLoader_BatchesPostList(t *testing.T) {
+	postIDs := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	liked := []*model.ReactionTypeCount{{Type: "heart", Count: 1}, {Type: "like", Count: 4}}
+	repo := &countingReactionRepo{typeCounts: map[uuid.UUID][]*model.ReactionTypeCount{postIDs[0]: liked}}
+	loader := NewReactionTypeCountLoader(repo)
+	ctx := context.Background()
+
+	counts, errs := loadConcurrently(postIDs, func(id uuid.UUID) ([]*model.ReactionTypeCount, error) {
+		return loader.Load(ctx, id)
+	})
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	assert.Equal(t, liked, counts[0])
+	assert.Empty(t, counts[1])
+	assert.NotNil(t, counts[2])
+	require.Len(t, repo.typeCalls, 1)
+	assert.ElementsMatch(t, postIDs, repo.typeCalls[0])
+}
+
+func TestViewerReactionLoader_BatchesPostList(t *testing.T) {
+	viewerID := uuid.New()
+	postIDs := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	repo := &countingReactionRepo{
+		reacted: map[uuid.UUID]map[uuid.UUID]bool{viewerID: {postIDs[1]: true}},
+	}
+	loader := NewViewerReactionLoader(repo)
+	ctx := context.Background()
+
+	reacted, errs := loadConcurrently(postIDs, func(id uuid.UUID) (bool, error) {
+		return loader.Load(ctx, id, viewerID)
+	})
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []bool{false, true, false}, reacted)
+	require.Len(t, repo.viewerCalls, 1)
+	assert.ElementsMatch(t, postIDs, repo.viewerCalls[0])
+}
@@ -2,15 +2,19 @@ package dataloader
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
-	"backend/graph/model"
+	"backend/internal/graph/model"
 	"backend/internal/repository"
 	"github.com/google/uuid"
 	"github.com/graph-gophers/dataloader/v7"
 )
 
+// ErrUserNotFound is the error UserLoader returns for IDs with no user
+var ErrUserNotFound = errors.New("user not found")
+
 // UserLoader wraps the User repository with DataLoader functionality
 type UserLoader struct {
 	userRepo repository.UserRepository
@@ -35,17 +39,24 @@ func NewUserLoader(userRepo repository.UserRepository) *UserLoader {
 
 // Load loads a single user by ID using DataLoader
 func (ul *UserLoader) Load(ctx context.Context, userID uuid.UUID) (*model.User, error) {
-	return ul.loader.Load(ctx, userID)
+	return ul.loader.Load(ctx, userID)()
 }
 
 // LoadMany loads multiple users by IDs using DataLoader
 func (ul *UserLoader) LoadMany(ctx context.Context, userIDs []uuid.UUID) ([]*model.User, []error) {
-	return ul.loader.LoadMany(ctx, userIDs)
+	return ul.loader.LoadMany(ctx, userIDs)()
+}
+
+// Prime caches already-fetched users so later loads for them skip the batch
+func (ul *UserLoader) Prime(ctx context.Context, users []*model.User) {
+	for _, user := range users {
+		ul.loader.Prime(ctx, user.ID, user)
+	}
 }
 
 // Clear clears the cache for a specific user ID
-func (ul *UserLoader) Clear(userID uuid.UUID) {
-	ul.loader.Clear(userID)
+func (ul *UserLoader) Clear(ctx context.Context, userID uuid.UUID) {
+	ul.loader.Clear(ctx, userID)
 }
 
 // ClearAll clears all cached users
@@ -83,7 +94,7 @@ func (ul *UserLoader) batchGetUsers(ctx context.Context, userIDs []uuid.UUID) []
 			results[i] = &dataloader.Result[*model.User]{Data: user}
 		} else {
 			results[i] = &dataloader.Result[*model.User]{
-				Error: fmt.Errorf("user not found: %s", userID),
+				Error: fmt.Errorf("%w: %s", ErrUserNotFound, userID),
 			}
 		}
 	}
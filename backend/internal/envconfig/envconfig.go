@@ -0,0 +1,44 @@
+// Package envconfig reads the settings the *FromEnv functions share. Unset or
+// invalid numbers fall back to the caller's default, so a typo never disables
+// a limit.
+package envconfig
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// NonNegativeInt returns the integer in the environment variable key, or
+// fallback when it is unset, not an integer or negative
+func NonNegativeInt(key string, fallback int) int {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil && intValue >= 0 {
+			return intValue
+		}
+	}
+	return fallback
+}
+
+// PositiveInt returns the integer in the environment variable key, or
+// fallback when it is unset, not an integer or not positive
+func PositiveInt(key string, fallback int) int {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil && intValue > 0 {
+			return intValue
+		}
+	}
+	return fallback
+}
+
+// List splits the comma-separated environment variable key, trimming
+// whitespace and dropping empty items
+func List(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
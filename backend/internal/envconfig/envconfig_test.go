@@ -0,0 +1,51 @@
+package envconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNonNegativeInt(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int
+	}{
+		{"", 7},
+		{"0", 0},
+		{"42", 42},
+		{"-1", 7},
+		{"many", 7},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("ENVCONFIG_TEST", tt.value)
+		assert.Equal(t, tt.expected, NonNegativeInt("ENVCONFIG_TEST", 7), "value %q", tt.value)
+	}
+}
+
+func TestPositiveInt(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int
+	}{
+		{"", 7},
+		{"0", 7},
+		{"42", 42},
+		{"-1", 7},
+		{"many", 7},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("ENVCONFIG_TEST", tt.value)
+		assert.Equal(t, tt.expected, PositiveInt("ENVCONFIG_TEST", 7), "value %q", tt.value)
+	}
+}
+
+func TestList(t *testing.T) {
+	t.Setenv("ENVCONFIG_TEST", " 10.0.0.1, ,10.0.0.0/8 ,")
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.0/8"}, List("ENVCONFIG_TEST"))
+
+	t.Setenv("ENVCONFIG_TEST", "")
+	assert.Empty(t, List("ENVCONFIG_TEST"))
+}
@@ -2,22 +2,84 @@ package errors
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
+	"log"
+	"os"
 	"strings"
 
 	"backend/internal/logging"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/vektah/gqlparser/v2/ast"
 	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
+// ErrorMode controls how much error detail reaches clients
+type ErrorMode string
+
+const (
+	// ErrorModeDevelopment returns internal and database error details to clients
+	ErrorModeDevelopment ErrorMode = "development"
+	// ErrorModeProduction replaces internal and database errors with a generic
+	// message carrying only the error code and request id
+	ErrorModeProduction ErrorMode = "production"
+)
+
+// maskedErrorMessage is shown to clients in place of internal error details
+const maskedErrorMessage = "Internal server error"
+
+// ErrorModeFromEnv reads GRAPHQL_ERROR_MODE, defaulting to production.
+// Development mode must be opted into explicitly; an unrecognised value
+// also selects production rather than exposing details.
+func ErrorModeFromEnv() ErrorMode {
+	value := strings.ToLower(strings.TrimSpace(os.Getenv("GRAPHQL_ERROR_MODE")))
+	switch ErrorMode(value) {
+	case "":
+		return ErrorModeProduction
+	case ErrorModeDevelopment, ErrorModeProduction:
+		return ErrorMode(value)
+	default:
+		log.Printf("Invalid GRAPHQL_ERROR_MODE %q, masking internal errors", value)
+		return ErrorModeProduction
+	}
+}
+
 // ErrorHandler handles GraphQL errors and provides consistent error formatting
 type ErrorHandler struct {
-	logger *logging.Logger
+	logger   *logging.Logger
+	mode     ErrorMode
+	messages Catalog
 }
 
-// NewErrorHandler creates a new error handler
+// NewErrorHandler creates a new error handler in production mode
 func NewErrorHandler(logger *logging.Logger) *ErrorHandler {
+	return NewErrorHandlerWithMode(logger, ErrorModeProduction)
+}
+
+// NewErrorHandlerWithMode creates a new error handler using the given mode
+func NewErrorHandlerWithMode(logger *logging.Logger, mode ErrorMode) *ErrorHandler {
 	return &ErrorHandler{
-		logger: logger,
+		logger:   logger,
+		mode:     mode,
+		messages: DefaultCatalog(),
+	}
+}
+
+// WithMessages makes the handler translate client messages from catalog
+func (h *ErrorHandler) WithMessages(catalog Catalog) *ErrorHandler {
+	h.messages = catalog
+	return h
+}
+
+// Presenter returns a gqlgen error presenter backed by HandleError
+func (h *ErrorHandler) Presenter() graphql.ErrorPresenterFunc {
+	return func(ctx context.Context, err error) *gqlerror.Error {
+		gqlErr := h.HandleError(ctx, err)
+		if gqlErr != nil && gqlErr.Path == nil && graphql.GetFieldContext(ctx) != nil {
+			gqlErr.Path = graphql.GetPath(ctx)
+		}
+		return gqlErr
 	}
 }
 
@@ -30,7 +92,7 @@ func (h *ErrorHandler) HandleError(ctx context.Context, err error) *gqlerror.Err
 	// If it's already a GraphQLError, convert it
 	if gqlErr, ok := err.(*GraphQLError); ok {
 		h.logError(ctx, gqlErr)
-		return gqlErr.ToGQLError()
+		return h.present(ctx, gqlErr)
 	}
 
 	// If it's already a gqlerror.Error, return as is
@@ -42,14 +104,65 @@ func (h *ErrorHandler) HandleError(ctx context.Context, err error) *gqlerror.Err
 	// Handle common error types
 	gqlErr := h.categorizeError(err)
 	h.logError(ctx, gqlErr)
-	return gqlErr.ToGQLError()
+	return h.present(ctx, gqlErr)
 }
 
-// categorizeError categorizes common errors into GraphQL error types
+// present converts err for the client, masking internal and database
+// errors in production mode; the full message has already been logged
+func (h *ErrorHandler) present(ctx context.Context, err *GraphQLError) *gqlerror.Error {
+	if h.mode != ErrorModeProduction || (err.Code != ErrorCodeInternal && err.Code != ErrorCodeDatabaseError) {
+		return h.localize(ctx, err).ToGQLError()
+	}
+
+	extensions := map[string]interface{}{"code": string(err.Code)}
+	if requestID := h.getRequestID(ctx); requestID != "unknown" {
+		extensions["requestId"] = requestID
+	}
+
+	var path ast.Path
+	for _, p := range err.Path {
+		path = append(path, ast.PathName(p))
+	}
+
+	return &gqlerror.Error{
+		Message:    maskedErrorMessage,
+		Path:       path,
+		Extensions: extensions,
+	}
+}
+
+// localize returns err with its message in the language the request's
+// Accept-Language header prefers. The code and message key are unchanged,
+// and errors without a message key keep their message.
+func (h *ErrorHandler) localize(ctx context.Context, err *GraphQLError) *GraphQLError {
+	if err.MessageKey == "" || h.messages == nil {
+		return err
+	}
+	message, ok := h.messages.Render(err.MessageKey, err.Params, requestLanguages(ctx)...)
+	if !ok || message == err.Message {
+		return err
+	}
+	localized := *err
+	localized.Message = message
+	return &localized
+}
+
+// categorizeError categorizes common errors into GraphQL error types.
+// Internal and database errors keep the original message for logging.
 func (h *ErrorHandler) categorizeError(err error) *GraphQLError {
 	errMsg := err.Error()
 	errMsgLower := strings.ToLower(errMsg)
 
+	// Errors reported by PostgreSQL are never matched on their text, which
+	// may mention tables, columns or values
+	var pgErr *pgconn.PgError
+	if stderrors.As(err, &pgErr) {
+		if pgErr.Code == "23505" {
+			return NewAlreadyExistsError("Resource")
+		}
+		return NewDatabaseError("Database operation failed: " + errMsg)
+	}
+
 	// Authentication errors
 	if strings.Contains(errMsgLower, "authentication required") ||
 		strings.Contains(errMsgLower, "unauthenticated") ||
@@ -91,11 +204,11 @@ func (h *ErrorHandler) categorizeError(err error) *GraphQLError {
 	if strings.Contains(errMsgLower, "database") ||
 		strings.Contains(errMsgLower, "sql") ||
 		strings.Contains(errMsgLower, "connection") {
-		return NewDatabaseError("Database operation failed")
+		return NewDatabaseError("Database operation failed: " + errMsg)
 	}
 
 	// Default to internal error
-	return NewInternalError("An unexpected error occurred")
+	return NewInternalError("An unexpected error occurred: " + errMsg)
 }
 
 // logError logs a GraphQLError
@@ -110,11 +223,11 @@ func (h *ErrorHandler) logError(ctx context.Context, err *GraphQLError) {
 	// Log based on error severity
 	switch err.Code {
 	case ErrorCodeInternal, ErrorCodeDatabaseError, ErrorCodeNetworkError:
-		h.logger.Printf("ERROR [%s] %s: %s (field: %s)", requestID, err.Code, err.Message, err.Field)
+		h.logger.Error("[%s] %s: %s (field: %s)", requestID, err.Code, err.Message, err.Field)
 	case ErrorCodeUnauthenticated, ErrorCodeUnauthorized, ErrorCodeForbidden:
-		h.logger.Printf("WARN [%s] %s: %s", requestID, err.Code, err.Message)
+		h.logger.Warn("[%s] %s: %s", requestID, err.Code, err.Message)
 	default:
-		h.logger.Printf("INFO [%s] %s: %s (field: %s)", requestID, err.Code, err.Message, err.Field)
+		h.logger.Info("[%s] %s: %s (field: %s)", requestID, err.Code, err.Message, err.Field)
 	}
 }
 
@@ -133,7 +246,7 @@ func (h *ErrorHandler) logGQLError(ctx context.Context, err *gqlerror.Error) {
 		}
 	}
 
-	h.logger.Printf("INFO [%s] %s: %s", requestID, code, err.Message)
+	h.logger.Info("[%s] %s: %s", requestID, code, err.Message)
 }
 
 // getRequestID extracts request ID from context
@@ -142,6 +255,10 @@ func (h *ErrorHandler) getRequestID(ctx context.Context) string {
 		return "unknown"
 	}
 	
+	if id := logging.GetRequestID(ctx); id != "" {
+		return id
+	}
+
 	// Try to get request ID from context
 	if id := ctx.Value("request_id"); id != nil {
 		if idStr, ok := id.(string); ok {
@@ -179,7 +296,9 @@ func WrapDatabaseError(err error, operation string) error {
 	return NewDatabaseError(fmt.Sprintf("Database %s failed", operation))
 }
 
-// WrapValidationErrors wraps multiple validation errors
+// WrapValidationErrors wraps multiple validation errors. The field and
+// message of each are listed in the "fields" extension so clients can flag
+// every invalid field.
 func WrapValidationErrors(errors []error) error {
 	if len(errors) == 0 {
 		return nil
@@ -191,14 +310,21 @@ func WrapValidationErrors(errors []error) error {
 
 	// Combine multiple validation errors
 	messages := make([]string, len(errors))
+	fields := make([]map[string]interface{}, len(errors))
 	for i, err := range errors {
 		messages[i] = err.Error()
+		fields[i] = map[string]interface{}{"message": err.Error()}
+		if gqlErr, ok := err.(*GraphQLError); ok && gqlErr.Field != "" {
+			fields[i]["field"] = gqlErr.Field
+		}
 	}
 
-	return NewValidationError(
+	combined := NewValidationError(
 		fmt.Sprintf("Multiple validation errors: %s", strings.Join(messages, "; ")),
 		"",
 	)
+	combined.Extensions = map[string]interface{}{"fields": fields}
+	return combined
 }
 
 // IsGraphQLError checks if an error is a GraphQLError
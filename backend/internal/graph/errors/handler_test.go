@@ -0,0 +1,79 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"backend/internal/logging"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+// rawDatabaseError is a driver error as repositories return it
+func rawDatabaseError() error {
+	return fmt.Errorf("failed to get user: %w", &pgconn.PgError{
+		Severity: "ERROR",
+		Code:     "42703",
+		Message:  `column "password_hash" does not exist`,
+	})
+}
+
+func TestErrorHandler_ProductionMasksDatabaseError(t *testing.T) {
+	handler := NewErrorHandlerWithMode(nil, ErrorModeProduction)
+	ctx := logging.WithRequestID(context.Background(), "req-123")
+
+	gqlErr := handler.HandleError(ctx, rawDatabaseError())
+
+	assert.Equal(t, "Internal server error", gqlErr.Message)
+	assert.Equal(t, map[string]interface{}{
+		"code":      string(ErrorCodeDatabaseError),
+		"requestId": "req-123",
+	}, gqlErr.Extensions)
+}
+
+func TestErrorHandler_DevelopmentKeepsDatabaseErrorDetail(t *testing.T) {
+	handler := NewErrorHandlerWithMode(nil, ErrorModeDevelopment)
+
+	gqlErr := handler.HandleError(context.Background(), rawDatabaseError())
+
+	assert.Contains(t, gqlErr.Message, `column "password_hash" does not exist`)
+	assert.Equal(t, string(ErrorCodeDatabaseError), gqlErr.Extensions["code"])
+}
+
+func TestErrorHandler_ProductionMasksInternalErrorMessage(t *testing.T) {
+	handler := NewErrorHandlerWithMode(nil, ErrorModeProduction)
+
+	gqlErr := handler.HandleError(context.Background(), NewInternalError("failed to sign token: key missing"))
+
+	assert.Equal(t, "Internal server error", gqlErr.Message)
+	assert.Equal(t, map[string]interface{}{"code": string(ErrorCodeInternal)}, gqlErr.Extensions)
+}
+
+func TestErrorHandler_ProductionKeepsClientErrors(t *testing.T) {
+	handler := NewErrorHandlerWithMode(nil, ErrorModeProduction)
+
+	gqlErr := handler.HandleError(context.Background(), NewValidationError("Title is required", "title"))
+
+	assert.Equal(t, "Title is required", gqlErr.Message)
+	assert.Equal(t, "title", gqlErr.Extensions["field"])
+}
+
+func TestErrorModeFromEnv(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected ErrorMode
+	}{
+		{"", ErrorModeProduction},
+		{"development", ErrorModeDevelopment},
+		{"Production", ErrorModeProduction},
+		{"verbose", ErrorModeProduction},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("GRAPHQL_ERROR_MODE", tt.value)
+			assert.Equal(t, tt.expected, ErrorModeFromEnv())
+		})
+	}
+}
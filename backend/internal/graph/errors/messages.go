@@ -0,0 +1,325 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// MessageKey identifies a client-facing message independently of its wording.
+// Keys are part of the API: clients may match on them, so never rename one.
+type MessageKey string
+
+const (
+	MsgTitleEmpty             MessageKey = "title.empty"
+	MsgTitleTooShort          MessageKey = "title.too_short"
+	MsgTitleTooLong           MessageKey = "title.too_long"
+	MsgTitleInvalidCharacters MessageKey = "title.invalid_characters"
+	MsgTitleControlCharacters MessageKey = "title.control_characters"
+
+	MsgContentEmpty             MessageKey = "content.empty"
+	MsgContentTooShort          MessageKey = "content.too_short"
+	MsgContentTooLong           MessageKey = "content.too_long"
+	MsgContentControlCharacters MessageKey = "content.control_characters"
+
+	MsgTagsTooMany          MessageKey = "tags.too_many"
+	MsgTagsTotalTooLong     MessageKey = "tags.total_too_long"
+	MsgFilterTagsTooMany    MessageKey = "tags.filter_too_many"
+	MsgTagEmpty             MessageKey = "tag.empty"
+	MsgTagTooShort          MessageKey = "tag.too_short"
+	MsgTagTooLong           MessageKey = "tag.too_long"
+	MsgTagInvalidCharacters MessageKey = "tag.invalid_characters"
+	MsgTagDuplicate         MessageKey = "tag.duplicate"
+
+	MsgNameEmpty             MessageKey = "name.empty"
+	MsgNameTooShort          MessageKey = "name.too_short"
+	MsgNameTooLong           MessageKey = "name.too_long"
+	MsgNameInvalidCharacters MessageKey = "name.invalid_characters"
+	MsgNameControlCharacters MessageKey = "name.control_characters"
+
+	MsgPasswordTooShort      MessageKey = "password.too_short"
+	MsgPasswordTooLong       MessageKey = "password.too_long"
+	MsgPasswordMissingLetter MessageKey = "password.missing_letter"
+	MsgPasswordMissingNumber MessageKey = "password.missing_number"
+
+	MsgAvatarURLTooLong       MessageKey = "avatar.url_too_long"
+	MsgAvatarURLInvalidFormat MessageKey = "avatar.url_invalid_format"
+	MsgAvatarURLNotImage      MessageKey = "avatar.url_not_image"
+
+	MsgCommentEmpty             MessageKey = "comment.empty"
+	MsgCommentTooShort          MessageKey = "comment.too_short"
+	MsgCommentTooLong           MessageKey = "comment.too_long"
+	MsgCommentControlCharacters MessageKey = "comment.control_characters"
+	MsgReplyTooDeep             MessageKey = "comment.reply_too_deep"
+
+	MsgSearchQueryEmpty             MessageKey = "search.empty"
+	MsgSearchQueryTooShort          MessageKey = "search.too_short"
+	MsgSearchQueryTooLong           MessageKey = "search.too_long"
+	MsgSearchQueryControlCharacters MessageKey = "search.control_characters"
+
+	MsgPageTooSmall  MessageKey = "pagination.page_too_small"
+	MsgPageTooLarge  MessageKey = "pagination.page_too_large"
+	MsgLimitTooSmall MessageKey = "pagination.limit_too_small"
+	MsgLimitTooLarge MessageKey = "pagination.limit_too_large"
+)
+
+// DefaultLanguage is used when the client accepts no language we translate to
+const DefaultLanguage = "en"
+
+// Catalog maps a language tag to its message templates. Templates refer to
+// parameters as {name}.
+type Catalog map[string]map[MessageKey]string
+
+// DefaultCatalog returns the built-in English, Spanish and French messages
+func DefaultCatalog() Catalog {
+	return Catalog{
+		"en": englishMessages,
+		"es": spanishMessages,
+		"fr": frenchMessages,
+	}
+}
+
+var englishMessages = map[MessageKey]string{
+	MsgTitleEmpty:             "Title cannot be empty",
+	MsgTitleTooShort:          "Title must be at least {min} characters long",
+	MsgTitleTooLong:           "Title cannot exceed {max} characters",
+	MsgTitleInvalidCharacters: "Title contains invalid characters",
+	MsgTitleControlCharacters: "Title cannot contain null bytes or control characters",
+
+	MsgContentEmpty:             "Content cannot be empty",
+	MsgContentTooShort:          "Content must be at least {min} characters long",
+	MsgContentTooLong:           "Content cannot exceed 50,000 characters",
+	MsgContentControlCharacters: "Content cannot contain null bytes or control characters other than tabs and line breaks",
+
+	MsgTagsTooMany:          "Cannot have more than {max} tags",
+	MsgTagsTotalTooLong:     "Tags cannot exceed {max} characters in total",
+	MsgFilterTagsTooMany:    "Cannot filter by more than {max} tags",
+	MsgTagEmpty:             "Tag {position} cannot be empty",
+	MsgTagTooShort:          "Tag '{tag}' must be at least {min} characters long",
+	MsgTagTooLong:           "Tag '{tag}' cannot exceed {max} characters",
+	MsgTagInvalidCharacters: "Tag '{tag}' contains invalid characters (only letters, numbers, hyphens, and underscores allowed)",
+	MsgTagDuplicate:         "Duplicate tag '{tag}'",
+
+	MsgNameEmpty:             "Name cannot be empty",
+	MsgNameTooShort:          "Name must be at least {min} characters long",
+	MsgNameTooLong:           "Name cannot exceed {max} characters",
+	MsgNameInvalidCharacters: "Name contains invalid characters",
+	MsgNameControlCharacters: "Name cannot contain null bytes or control characters",
+
+	MsgPasswordTooShort:      "Password must be at least {min} characters long",
+	MsgPasswordTooLong:       "Password cannot exceed {max} characters",
+	MsgPasswordMissingLetter: "Password must contain at least one letter",
+	MsgPasswordMissingNumber: "Password must contain at least one number",
+
+	MsgAvatarURLTooLong:       "Avatar URL cannot exceed {max} characters",
+	MsgAvatarURLInvalidFormat: "Invalid avatar URL format",
+	MsgAvatarURLNotImage:      "Avatar URL must point to an image file",
+
+	MsgCommentEmpty:             "Comment content cannot be empty",
+	MsgCommentTooShort:          "Comment must have at least {min} character",
+	MsgCommentTooLong:           "Comment cannot exceed {max} characters",
+	MsgCommentControlCharacters: "Comment cannot contain null bytes or control characters other than tabs and line breaks",
+	MsgReplyTooDeep:             "Replies cannot be nested more than {max} levels deep",
+
+	MsgSearchQueryEmpty:             "Search query cannot be empty",
+	MsgSearchQueryTooShort:          "Search query must be at least {min} characters long",
+	MsgSearchQueryTooLong:           "Search query cannot exceed {max} characters",
+	MsgSearchQueryControlCharacters: "Search query cannot contain null bytes or control characters",
+
+	MsgPageTooSmall:  "Page must be at least 1",
+	MsgPageTooLarge:  "Page cannot exceed {max}",
+	MsgLimitTooSmall: "Limit must be at least 1",
+	MsgLimitTooLarge: "Limit cannot exceed {max}",
+}
+
+var spanishMessages = map[MessageKey]string{
+	MsgTitleEmpty:             "El título no puede estar vacío",
+	MsgTitleTooShort:          "El título debe tener al menos {min} caracteres",
+	MsgTitleTooLong:           "El título no puede superar los {max} caracteres",
+	MsgTitleInvalidCharacters: "El título contiene caracteres no válidos",
+	MsgTitleControlCharacters: "El título no puede contener bytes nulos ni caracteres de control",
+
+	MsgContentEmpty:             "El contenido no puede estar vacío",
+	MsgContentTooShort:          "El contenido debe tener al menos {min} caracteres",
+	MsgContentTooLong:           "El contenido no puede superar los 50.000 caracteres",
+	MsgContentControlCharacters: "El contenido no puede contener bytes nulos ni caracteres de control salvo tabulaciones y saltos de línea",
+
+	MsgTagsTooMany:          "No puede haber más de {max} etiquetas",
+	MsgTagsTotalTooLong:     "Las etiquetas no pueden superar los {max} caracteres en total",
+	MsgFilterTagsTooMany:    "No se puede filtrar por más de {max} etiquetas",
+	MsgTagEmpty:             "La etiqueta {position} no puede estar vacía",
+	MsgTagTooShort:          "La etiqueta '{tag}' debe tener al menos {min} caracteres",
+	MsgTagTooLong:           "La etiqueta '{tag}' no puede superar los {max} caracteres",
+	MsgTagInvalidCharacters: "La etiqueta '{tag}' contiene caracteres no válidos (solo letras, números, guiones y guiones bajos)",
+	MsgTagDuplicate:         "Etiqueta duplicada '{tag}'",
+
+	MsgNameEmpty:             "El nombre no puede estar vacío",
+	MsgNameTooShort:          "El nombre debe tener al menos {min} caracteres",
+	MsgNameTooLong:           "El nombre no puede superar los {max} caracteres",
+	MsgNameInvalidCharacters: "El nombre contiene caracteres no válidos",
+	MsgNameControlCharacters: "El nombre no puede contener bytes nulos ni caracteres de control",
+
+	MsgPasswordTooShort:      "La contraseña debe tener al menos {min} caracteres",
+	MsgPasswordTooLong:       "La contraseña no puede superar los {max} caracteres",
+	MsgPasswordMissingLetter: "La contraseña debe contener al menos una letra",
+	MsgPasswordMissingNumber: "La contraseña debe contener al menos un número",
+
+	MsgAvatarURLTooLong:       "La URL del avatar no puede superar los {max} caracteres",
+	MsgAvatarURLInvalidFormat: "El formato de la URL del avatar no es válido",
+	MsgAvatarURLNotImage:      "La URL del avatar debe apuntar a una imagen",
+
+	MsgCommentEmpty:             "El comentario no puede estar vacío",
+	MsgCommentTooShort:          "El comentario debe tener al menos {min} carácter",
+	MsgCommentTooLong:           "El comentario no puede superar los {max} caracteres",
+	MsgCommentControlCharacters: "El comentario no puede contener bytes nulos ni caracteres de control salvo tabulaciones y saltos de línea",
+	MsgReplyTooDeep:             "Las respuestas no pueden anidarse más de {max} niveles",
+
+	MsgSearchQueryEmpty:             "La búsqueda no puede estar vacía",
+	MsgSearchQueryTooShort:          "La búsqueda debe tener al menos {min} caracteres",
+	MsgSearchQueryTooLong:           "La búsqueda no puede superar los {max} caracteres",
+	MsgSearchQueryControlCharacters: "La búsqueda no puede contener bytes nulos ni caracteres de control",
+
+	MsgPageTooSmall:  "La página debe ser al menos 1",
+	MsgPageTooLarge:  "La página no puede superar {max}",
+	MsgLimitTooSmall: "El límite debe ser al menos 1",
+	MsgLimitTooLarge: "El límite no puede superar {max}",
+}
+
+var frenchMessages = map[MessageKey]string{
+	MsgTitleEmpty:             "Le titre ne peut pas être vide",
+	MsgTitleTooShort:          "Le titre doit contenir au moins {min} caractères",
+	MsgTitleTooLong:           "Le titre ne peut pas dépasser {max} caractères",
+	MsgTitleInvalidCharacters: "Le titre contient des caractères non valides",
+	MsgTitleControlCharacters: "Le titre ne peut pas contenir d'octets nuls ni de caractères de contrôle",
+
+	MsgContentEmpty:             "Le contenu ne peut pas être vide",
+	MsgContentTooShort:          "Le contenu doit contenir au moins {min} caractères",
+	MsgContentTooLong:           "Le contenu ne peut pas dépasser 50 000 caractères",
+	MsgContentControlCharacters: "Le contenu ne peut pas contenir d'octets nuls ni de caractères de contrôle autres que les tabulations et les sauts de ligne",
+
+	MsgTagsTooMany:          "Impossible d'avoir plus de {max} tags",
+	MsgTagsTotalTooLong:     "Les tags ne peuvent pas dépasser {max} caractères au total",
+	MsgFilterTagsTooMany:    "Impossible de filtrer par plus de {max} tags",
+	MsgTagEmpty:             "Le tag {position} ne peut pas être vide",
+	MsgTagTooShort:          "Le tag '{tag}' doit contenir au moins {min} caractères",
+	MsgTagTooLong:           "Le tag '{tag}' ne peut pas dépasser {max} caractères",
+	MsgTagInvalidCharacters: "Le tag '{tag}' contient des caractères non valides (lettres, chiffres, tirets et tirets bas uniquement)",
+	MsgTagDuplicate:         "Tag en double '{tag}'",
+
+	MsgNameEmpty:             "Le nom ne peut pas être vide",
+	MsgNameTooShort:          "Le nom doit contenir au moins {min} caractères",
+	MsgNameTooLong:           "Le nom ne peut pas dépasser {max} caractères",
+	MsgNameInvalidCharacters: "Le nom contient des caractères non valides",
+	MsgNameControlCharacters: "Le nom ne peut pas contenir d'octets nuls ni de caractères de contrôle",
+
+	MsgPasswordTooShort:      "Le mot de passe doit contenir au moins {min} caractères",
+	MsgPasswordTooLong:       "Le mot de passe ne peut pas dépasser {max} caractères",
+	MsgPasswordMissingLetter: "Le mot de passe doit contenir au moins une lettre",
+	MsgPasswordMissingNumber: "Le mot de passe doit contenir au moins un chiffre",
+
+	MsgAvatarURLTooLong:       "L'URL de l'avatar ne peut pas dépasser {max} caractères",
+	MsgAvatarURLInvalidFormat: "Format d'URL d'avatar non valide",
+	MsgAvatarURLNotImage:      "L'URL de l'avatar doit pointer vers une image",
+
+	MsgCommentEmpty:             "Le commentaire ne peut pas être vide",
+	MsgCommentTooShort:          "Le commentaire doit contenir au moins {min} caractère",
+	MsgCommentTooLong:           "Le commentaire ne peut pas dépasser {max} caractères",
+	MsgCommentControlCharacters: "Le commentaire ne peut pas contenir d'octets nuls ni de caractères de contrôle autres que les tabulations et les sauts de ligne",
+	MsgReplyTooDeep:             "Les réponses ne peuvent pas être imbriquées sur plus de {max} niveaux",
+
+	MsgSearchQueryEmpty:             "La recherche ne peut pas être vide",
+	MsgSearchQueryTooShort:          "La recherche doit contenir au moins {min} caractères",
+	MsgSearchQueryTooLong:           "La recherche ne peut pas dépasser {max} caractères",
+	MsgSearchQueryControlCharacters: "La recherche ne peut pas contenir d'octets nuls ni de caractères de contrôle",
+
+	MsgPageTooSmall:  "La page doit être au moins 1",
+	MsgPageTooLarge:  "La page ne peut pas dépasser {max}",
+	MsgLimitTooSmall: "La limite doit être au moins 1",
+	MsgLimitTooLarge: "La limite ne peut pas dépasser {max}",
+}
+
+// Render fills in the template for key in the first language the catalog
+// has it in, falling back to DefaultLanguage. ok is false when not even the
+// default language knows the key.
+func (c Catalog) Render(key MessageKey, params map[string]interface{}, languages ...string) (message string, ok bool) {
+	for _, language := range append(languages, DefaultLanguage) {
+		if template, found := c[language][key]; found {
+			return renderTemplate(template, params), true
+		}
+	}
+	return "", false
+}
+
+// renderTemplate replaces each {name} in template with its parameter
+func renderTemplate(template string, params map[string]interface{}) string {
+	if len(params) == 0 {
+		return template
+	}
+	pairs := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		pairs = append(pairs, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// ParseAcceptLanguage returns the base languages of an Accept-Language
+// header, most preferred first. Region subtags are dropped ("fr-CA" is
+// "fr"), as are the wildcard and languages with q=0.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		language string
+		q        float64
+	}
+
+	var ranges []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if parsed, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		language, _, _ := strings.Cut(tag, "-")
+		ranges = append(ranges, weighted{language: language, q: q})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+
+	languages := make([]string, 0, len(ranges))
+	seen := make(map[string]bool, len(ranges))
+	for _, r := range ranges {
+		if !seen[r.language] {
+			seen[r.language] = true
+			languages = append(languages, r.language)
+		}
+	}
+	return languages
+}
+
+// requestLanguages returns the languages the current GraphQL request's
+// Accept-Language header asks for
+func requestLanguages(ctx context.Context) []string {
+	if ctx == nil || !graphql.HasOperationContext(ctx) {
+		return nil
+	}
+	headers := graphql.GetOperationContext(ctx).Headers
+	if headers == nil {
+		return nil
+	}
+	return ParseAcceptLanguage(headers.Get("Accept-Language"))
+}
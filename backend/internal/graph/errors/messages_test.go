@@ -0,0 +1,58 @@
+package errors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected []string
+	}{
+		{"", []string{}},
+		{"fr", []string{"fr"}},
+		{"fr-CA,fr;q=0.9,en;q=0.8", []string{"fr", "en"}},
+		{"en;q=0.5, es", []string{"es", "en"}},
+		{"de;q=0, *;q=0.1, ES-mx", []string{"es"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParseAcceptLanguage(tt.header))
+		})
+	}
+}
+
+func TestCatalog_RenderFallsBackToDefaultLanguage(t *testing.T) {
+	catalog := Catalog{
+		"en": {MsgPageTooLarge: "Page cannot exceed {max}"},
+		"es": {},
+	}
+
+	message, ok := catalog.Render(MsgPageTooLarge, map[string]interface{}{"max": 50}, "es", "fr")
+	assert.True(t, ok)
+	assert.Equal(t, "Page cannot exceed 50", message)
+
+	_, ok = catalog.Render(MsgLimitTooLarge, nil, "es")
+	assert.False(t, ok)
+}
+
+func TestErrorHandler_KeepsMessagesWithoutKey(t *testing.T) {
+	handler := NewErrorHandler(nil)
+
+	gqlErr := handler.HandleError(context.Background(), NewValidationError("Reason cannot be empty", "reason"))
+
+	assert.Equal(t, "Reason cannot be empty", gqlErr.Message)
+	assert.NotContains(t, gqlErr.Extensions, "messageKey")
+}
+
+func TestCatalog_TranslationsCoverEnglishKeys(t *testing.T) {
+	for language, messages := range DefaultCatalog() {
+		for key := range englishMessages {
+			assert.Contains(t, messages, key, "%s is missing %s", language, key)
+		}
+	}
+}
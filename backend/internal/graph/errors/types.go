@@ -42,6 +42,11 @@ type GraphQLError struct {
 	Field      string                 `json:"field,omitempty"`
 	Path       []string               `json:"path,omitempty"`
 	Extensions map[string]interface{} `json:"extensions,omitempty"`
+
+	// MessageKey and Params let the message be rendered in the client's
+	// language; Message always holds the English text
+	MessageKey MessageKey             `json:"messageKey,omitempty"`
+	Params     map[string]interface{} `json:"-"`
 }
 
 // Error implements the error interface
@@ -57,6 +62,10 @@ func (e *GraphQLError) ToGQLError() *gqlerror.Error {
 	if e.Field != "" {
 		extensions["field"] = e.Field
 	}
+
+	if e.MessageKey != "" {
+		extensions["messageKey"] = string(e.MessageKey)
+	}
 	
 	// Add any additional extensions
 	for k, v := range e.Extensions {
@@ -85,6 +94,22 @@ func NewValidationError(message, field string) *GraphQLError {
 	}
 }
 
+// NewLocalizedValidationError creates a validation error whose message can
+// be translated; params fill in the message's placeholders
+func NewLocalizedValidationError(key MessageKey, field string, params map[string]interface{}) *GraphQLError {
+	message, ok := DefaultCatalog().Render(key, params)
+	if !ok {
+		message = string(key)
+	}
+	return &GraphQLError{
+		Message:    message,
+		Code:       ErrorCodeValidation,
+		Field:      field,
+		MessageKey: key,
+		Params:     params,
+	}
+}
+
 // NewInvalidInputError creates an invalid input error
 func NewInvalidInputError(message, field string) *GraphQLError {
 	return &GraphQLError{
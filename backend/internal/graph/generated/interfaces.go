@@ -2,42 +2,93 @@ package generated
 
 import (
 	"context"
+	"time"
+
 	"backend/internal/graph/model"
 )
 
 // Minimal interfaces for testing - these would normally be generated by gqlgen
 type QueryResolver interface {
+	Node(ctx context.Context, id string) (model.Node, error)
 	Me(ctx context.Context) (*model.User, error)
+	Viewer(ctx context.Context) (*model.Viewer, error)
 	User(ctx context.Context, id string) (*model.User, error)
-	Posts(ctx context.Context, filters *model.PostFilters, pagination *model.PaginationInput) (*model.PostConnection, error)
+	Posts(ctx context.Context, filters *model.PostFilters, pagination *model.PaginationInput, sort *model.PostSort) (*model.PostConnection, error)
 	Post(ctx context.Context, id string) (*model.Post, error)
+	PostsByIds(ctx context.Context, ids []string) ([]*model.Post, error)
+	UsersByIds(ctx context.Context, ids []string) ([]*model.User, error)
+	Stats(ctx context.Context) (*model.Stats, error)
+	Reports(ctx context.Context, status *model.ReportStatus, pagination *model.PaginationInput) ([]*model.Report, error)
+	PopularTags(ctx context.Context, limit *int, offset *int) ([]*model.TagCount, error)
 	SearchPosts(ctx context.Context, query string, limit *int) ([]*model.Post, error)
+	SearchUsers(ctx context.Context, query string, limit *int) ([]*model.User, error)
 }
 
 type MutationResolver interface {
 	Login(ctx context.Context, email string, password string) (*model.AuthPayload, error)
-	Register(ctx context.Context, email string, password string, name string) (*model.AuthPayload, error)
-	RefreshToken(ctx context.Context) (*model.AuthPayload, error)
-	CreatePost(ctx context.Context, input model.CreatePostInput) (*model.Post, error)
-	UpdatePost(ctx context.Context, id string, input model.UpdatePostInput) (*model.Post, error)
-	DeletePost(ctx context.Context, id string) (bool, error)
-	AddComment(ctx context.Context, postID string, content string) (*model.Comment, error)
+	Register(ctx context.Context, email string, password string, name string, validateOnly *bool) (model.RegisterResult, error)
+	RefreshToken(ctx context.Context, refreshToken *string) (*model.AuthPayload, error)
+	Logout(ctx context.Context, refreshToken *string) (bool, error)
+	RequestEmailChange(ctx context.Context, newEmail string) (bool, error)
+	ConfirmEmailChange(ctx context.Context, token string) (*model.User, error)
+	ResendVerificationEmail(ctx context.Context, email *string) (bool, error)
+	VerifyEmail(ctx context.Context, token string) (*model.User, error)
+	SetPassword(ctx context.Context, token string, password string) (*model.AuthPayload, error)
+	CreatePost(ctx context.Context, input model.CreatePostInput, validateOnly *bool) (model.CreatePostResult, error)
+	UpdatePost(ctx context.Context, id string, input model.UpdatePostInput, validateOnly *bool) (*model.Post, error)
+	DeletePost(ctx context.Context, id string, dryRun *bool) (*model.DeletePostResult, error)
+	PublishPost(ctx context.Context, id string) (*model.Post, error)
+	UnpublishPost(ctx context.Context, id string) (*model.Post, error)
+	AddComment(ctx context.Context, postID string, content string, parentID *string) (*model.Comment, error)
 	DeleteComment(ctx context.Context, id string) (bool, error)
+	HidePost(ctx context.Context, id string, reason string) (*model.Post, error)
+	UnhidePost(ctx context.Context, id string) (*model.Post, error)
+	HideComment(ctx context.Context, id string, reason string) (*model.Comment, error)
+	UnhideComment(ctx context.Context, id string) (*model.Comment, error)
+	MergeTags(ctx context.Context, from string, into string, dryRun *bool) (int, error)
+	RotateJWTKey(ctx context.Context) (string, error)
+	ClearCache(ctx context.Context, scope model.CacheScope) (bool, error)
+	Impersonate(ctx context.Context, userID string) (*model.AuthPayload, error)
+	StopImpersonation(ctx context.Context) (bool, error)
+	ReportContent(ctx context.Context, typeArg model.ReportTargetType, id string, reason string) (*model.Report, error)
+	ResolveReport(ctx context.Context, id string, action model.ReportAction) (*model.Report, error)
 }
 
 type SubscriptionResolver interface {
 	PostAdded(ctx context.Context) (<-chan *model.Post, error)
 	PostUpdated(ctx context.Context, id string) (<-chan *model.Post, error)
 	CommentAdded(ctx context.Context, postID string) (<-chan *model.Comment, error)
+	NotificationReceived(ctx context.Context) (<-chan *model.Notification, error)
 }
 
 type CommentResolver interface {
+	ID(ctx context.Context, obj *model.Comment) (string, error)
 	Author(ctx context.Context, obj *model.Comment) (*model.User, error)
 	Post(ctx context.Context, obj *model.Comment) (*model.Post, error)
+	ParentID(ctx context.Context, obj *model.Comment) (*string, error)
 }
 
 type PostResolver interface {
+	ID(ctx context.Context, obj *model.Post) (string, error)
 	Author(ctx context.Context, obj *model.Post) (*model.User, error)
+	ReactionCount(ctx context.Context, obj *model.Post) (int, error)
+	ViewerHasReacted(ctx context.Context, obj *model.Post) (bool, error)
+	CommentSummary(ctx context.Context, obj *model.Post) (*model.CommentSummary, error)
+	Engagement(ctx context.Context, obj *model.Post) (*model.PostEngagement, error)
+}
+
+type ReportResolver interface {
+	ID(ctx context.Context, obj *model.Report) (string, error)
+	Reporter(ctx context.Context, obj *model.Report) (*model.User, error)
+	TargetID(ctx context.Context, obj *model.Report) (string, error)
+}
+
+type UserResolver interface {
+	ID(ctx context.Context, obj *model.User) (string, error)
+	Email(ctx context.Context, obj *model.User) (*string, error)
+	LastLoginAt(ctx context.Context, obj *model.User) (*time.Time, error)
+	LastLoginIP(ctx context.Context, obj *model.User) (*string, error)
+	Posts(ctx context.Context, obj *model.User) ([]*model.Post, error)
 }
 
 // Mock implementation for testing - normally generated by gqlgen
@@ -0,0 +1,47 @@
+package model
+
+// Connection is a page of nodes in the Relay connection shape. Each
+// GraphQL connection type is an alias of one instantiation, so they all
+// share the same fields and builder.
+type Connection[T any] struct {
+	Edges      []*Edge[T] `json:"edges"`
+	PageInfo   *PageInfo  `json:"pageInfo"`
+	TotalCount int        `json:"totalCount"`
+}
+
+// Edge pairs a node with the cursor that resumes the listing after it
+type Edge[T any] struct {
+	Node   *T     `json:"node"`
+	Cursor string `json:"cursor"`
+}
+
+// Page describes where a fetched slice of nodes sits in the full listing
+type Page struct {
+	HasNextPage     bool
+	HasPreviousPage bool
+	TotalCount      int
+}
+
+// NewConnection builds a connection over nodes, keeping their order and
+// giving each edge the cursor returned by cursor
+func NewConnection[T any](nodes []*T, cursor func(*T) string, page Page) *Connection[T] {
+	edges := make([]*Edge[T], len(nodes))
+	for i, node := range nodes {
+		edges[i] = &Edge[T]{Node: node, Cursor: cursor(node)}
+	}
+
+	pageInfo := &PageInfo{
+		HasNextPage:     page.HasNextPage,
+		HasPreviousPage: page.HasPreviousPage,
+	}
+	if len(edges) > 0 {
+		pageInfo.StartCursor = &edges[0].Cursor
+		pageInfo.EndCursor = &edges[len(edges)-1].Cursor
+	}
+
+	return &Connection[T]{
+		Edges:      edges,
+		PageInfo:   pageInfo,
+		TotalCount: page.TotalCount,
+	}
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConnection(t *testing.T) {
+	posts := []*Post{{Title: "first"}, {Title: "second"}, {Title: "third"}}
+	cursor := func(post *Post) string { return "cursor:" + post.Title }
+
+	conn := NewConnection(posts, cursor, Page{HasNextPage: true, HasPreviousPage: false, TotalCount: 7})
+
+	require.Len(t, conn.Edges, 3)
+	for i, edge := range conn.Edges {
+		assert.Same(t, posts[i], edge.Node, "edge %d out of order", i)
+		assert.Equal(t, "cursor:"+posts[i].Title, edge.Cursor)
+	}
+
+	assert.True(t, conn.PageInfo.HasNextPage)
+	assert.False(t, conn.PageInfo.HasPreviousPage)
+	require.NotNil(t, conn.PageInfo.StartCursor)
+	require.NotNil(t, conn.PageInfo.EndCursor)
+	assert.Equal(t, "cursor:first", *conn.PageInfo.StartCursor)
+	assert.Equal(t, "cursor:third", *conn.PageInfo.EndCursor)
+	assert.Equal(t, 7, conn.TotalCount)
+}
+
+func TestNewConnection_Empty(t *testing.T) {
+	conn := NewConnection([]*User{}, func(*User) string { return "unused" }, Page{HasPreviousPage: true})
+
+	assert.NotNil(t, conn.Edges)
+	assert.Empty(t, conn.Edges)
+	assert.False(t, conn.PageInfo.HasNextPage)
+	assert.True(t, conn.PageInfo.HasPreviousPage)
+	assert.Nil(t, conn.PageInfo.StartCursor)
+	assert.Nil(t, conn.PageInfo.EndCursor)
+	assert.Zero(t, conn.TotalCount)
+}
@@ -1,6 +1,9 @@
 package model
 
 import (
+	"fmt"
+	"io"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +18,11 @@ type User struct {
 	Avatar       *string    `json:"avatar" db:"avatar"`
 	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
 	UpdatedAt    time.Time  `json:"updatedAt" db:"updated_at"`
+	// LastLoginAt and LastLoginIP record the latest successful login; only admins see them
+	LastLoginAt *time.Time `json:"lastLoginAt" db:"last_login_at"`
+	LastLoginIP *string    `json:"lastLoginIp" db:"last_login_ip"`
+	// Role is the user's security role: admin, moderator or user
+	Role string `json:"role" db:"role"`
 }
 
 // Post represents a blog post
@@ -27,6 +35,15 @@ type Post struct {
 	Published bool      `json:"published" db:"published"`
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+	// PublishAt is when the post was first published, nil until then. It is
+	// kept when the post is unpublished, so publishing again doesn't restart
+	// the edit window, and in cached copies, so the window holds for them too.
+	PublishAt *time.Time `json:"publishAt,omitempty" db:"publish_at"`
+	// Hidden posts are only shown to their author and moderators
+	Hidden       bool    `json:"hidden" db:"hidden"`
+	HiddenReason *string `json:"hiddenReason,omitempty" db:"hidden_reason"`
+	// Author is set when the post was loaded together with its author
+	Author *User `json:"-" db:"-"`
 }
 
 // Comment represents a comment on a post
@@ -36,6 +53,143 @@ type Comment struct {
 	AuthorID  uuid.UUID `json:"authorId" db:"author_id"`
 	PostID    uuid.UUID `json:"postId" db:"post_id"`
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+	// Replies name the comment they answer; Depth counts their ancestors
+	ParentID *uuid.UUID `json:"parentId,omitempty" db:"parent_id"`
+	Depth    int        `json:"depth" db:"depth"`
+	// Hidden comments are only shown to their author and moderators
+	Hidden       bool    `json:"hidden" db:"hidden"`
+	HiddenReason *string `json:"hiddenReason,omitempty" db:"hidden_reason"`
+}
+
+// CommentSummary is the number of visible comments on a post and the newest of them
+type CommentSummary struct {
+	Count  int      `json:"count"`
+	Latest *Comment `json:"latest,omitempty"`
+}
+
+// Viewer is the authenticated user together with their role and effective permissions
+type Viewer struct {
+	User        *User    `json:"user"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
+
+// Node is implemented by types that can be refetched through the node query
+type Node interface {
+	IsNode()
+}
+
+func (User) IsNode()    {}
+func (Post) IsNode()    {}
+func (Comment) IsNode() {}
+
+// Reaction represents a user's reaction to a post
+type Reaction struct {
+	PostID    uuid.UUID `json:"postId" db:"post_id"`
+	UserID    uuid.UUID `json:"userId" db:"user_id"`
+	Type      string    `json:"type" db:"reaction"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// ReactionTypeCount is how many reactions of one type a post has
+type ReactionTypeCount struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+// TagCount is how many published posts carry a tag
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// PostEngagement gathers a post's reaction and comment figures so clients
+// can show them without a round trip per figure
+type PostEngagement struct {
+	ReactionCount    int                  `json:"reactionCount"`
+	ReactionsByType  []*ReactionTypeCount `json:"reactionsByType"`
+	ViewerHasReacted bool                 `json:"viewerHasReacted"`
+	CommentCount     int                  `json:"commentCount"`
+}
+
+// Report represents a user's report of a post or comment to moderators
+type Report struct {
+	ID         uuid.UUID        `json:"id" db:"id"`
+	ReporterID uuid.UUID        `json:"reporterId" db:"reporter_id"`
+	TargetType ReportTargetType `json:"targetType" db:"target_type"`
+	TargetID   uuid.UUID        `json:"targetId" db:"target_id"`
+	Reason     string           `json:"reason" db:"reason"`
+	Status     ReportStatus     `json:"status" db:"status"`
+	CreatedAt  time.Time        `json:"createdAt" db:"created_at"`
+	ResolvedAt *time.Time       `json:"resolvedAt" db:"resolved_at"`
+}
+
+// EmailChangeRequest represents a pending, unconfirmed change of a user's email
+type EmailChangeRequest struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	UserID    uuid.UUID `json:"userId" db:"user_id"`
+	NewEmail  string    `json:"newEmail" db:"new_email"`
+	TokenHash string    `json:"-" db:"token_hash"`
+	ExpiresAt time.Time `json:"expiresAt" db:"expires_at"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// EmailVerificationToken represents an outstanding email verification token
+type EmailVerificationToken struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	UserID    uuid.UUID `json:"userId" db:"user_id"`
+	TokenHash string    `json:"-" db:"token_hash"`
+	ExpiresAt time.Time `json:"expiresAt" db:"expires_at"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// PasswordSetupToken is a one-time token letting a user created without a
+// password set their first one
+type PasswordSetupToken struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	UserID    uuid.UUID `json:"userId" db:"user_id"`
+	TokenHash string    `json:"-" db:"token_hash"`
+	ExpiresAt time.Time `json:"expiresAt" db:"expires_at"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// RefreshToken is the stored record of an issued refresh token. Tokens
+// descending from one sign-in share a FamilyID.
+type RefreshToken struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	UserID    uuid.UUID  `json:"userId" db:"user_id"`
+	FamilyID  uuid.UUID  `json:"familyId" db:"family_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	ExpiresAt time.Time  `json:"expiresAt" db:"expires_at"`
+	UsedAt    *time.Time `json:"usedAt" db:"used_at"`
+	RevokedAt *time.Time `json:"revokedAt" db:"revoked_at"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// AuditLog is a stored audit entry. UserID is nil for anonymous callers;
+// entries outlive the users they name.
+type AuditLog struct {
+	ID         uuid.UUID              `json:"id" db:"id"`
+	UserID     *uuid.UUID             `json:"userId" db:"user_id"`
+	Action     string                 `json:"action" db:"action"`
+	Resource   string                 `json:"resource" db:"resource"`
+	ResourceID string                 `json:"resourceId" db:"resource_id"`
+	IPAddress  string                 `json:"ipAddress" db:"ip_address"`
+	UserAgent  string                 `json:"userAgent" db:"user_agent"`
+	Success    bool                   `json:"success" db:"success"`
+	Error      string                 `json:"error,omitempty" db:"error"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty" db:"metadata"`
+	CreatedAt  time.Time              `json:"createdAt" db:"created_at"`
+}
+
+// JWTSigningKey is a signing key made current by a rotation. Keys are kept
+// so every server instance, and the next start, signs and verifies with them.
+// Secret is encrypted with the key encryption key.
+type JWTSigningKey struct {
+	ID        string    `json:"id" db:"id"`
+	Secret    []byte    `json:"-" db:"secret"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 }
 
 // CreateUserInput represents input for creating a user
@@ -66,6 +220,21 @@ type UpdatePostInput struct {
 	Published *bool    `json:"published,omitempty"`
 }
 
+// PostTagLimits bounds the tags stored on a post. The GraphQL validator and
+// the post repository enforce the same limits.
+type PostTagLimits struct {
+	MaxTags      int
+	MaxTagLength int
+
+	// Most characters across all of a post's tags; 0 means no combined limit
+	MaxTotalTagLength int
+}
+
+// DefaultPostTagLimits returns the tag limits used when none are configured
+func DefaultPostTagLimits() PostTagLimits {
+	return PostTagLimits{MaxTags: 10, MaxTagLength: 30, MaxTotalTagLength: 200}
+}
+
 type PostFilters struct {
 	AuthorID   *string  `json:"authorId,omitempty"`
 	Published  *bool    `json:"published,omitempty"`
@@ -74,22 +243,287 @@ type PostFilters struct {
 }
 
 type PaginationInput struct {
-	Page  *int `json:"page,omitempty"`
-	Limit *int `json:"limit,omitempty"`
+	Page  *int    `json:"page,omitempty"`
+	Limit *int    `json:"limit,omitempty"`
+	After *string `json:"after,omitempty"`
 }
 
-// GraphQL Response Types
-type PostConnection struct {
-	Edges      []*PostEdge `json:"edges"`
-	PageInfo   *PageInfo   `json:"pageInfo"`
-	TotalCount int         `json:"totalCount"`
+// PostSort is the order posts are listed in
+type PostSort string
+
+const (
+	PostSortNewest          PostSort = "NEWEST"
+	PostSortOldest          PostSort = "OLDEST"
+	PostSortRecentlyUpdated PostSort = "RECENTLY_UPDATED"
+	PostSortTitle           PostSort = "TITLE"
+)
+
+var AllPostSort = []PostSort{
+	PostSortNewest,
+	PostSortOldest,
+	PostSortRecentlyUpdated,
+	PostSortTitle,
+}
+
+func (e PostSort) IsValid() bool {
+	switch e {
+	case PostSortNewest, PostSortOldest, PostSortRecentlyUpdated, PostSortTitle:
+		return true
+	}
+	return false
+}
+
+func (e PostSort) String() string {
+	return string(e)
 }
 
-type PostEdge struct {
-	Node   *Post  `json:"node"`
-	Cursor string `json:"cursor"`
+func (e *PostSort) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PostSort(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PostSort", str)
+	}
+	return nil
+}
+
+func (e PostSort) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// CacheScope names the caches clearCache empties
+type CacheScope string
+
+const (
+	CacheScopeUsers   CacheScope = "USERS"
+	CacheScopePosts   CacheScope = "POSTS"
+	CacheScopeLoaders CacheScope = "LOADERS"
+	CacheScopeAll     CacheScope = "ALL"
+)
+
+var AllCacheScope = []CacheScope{
+	CacheScopeUsers,
+	CacheScopePosts,
+	CacheScopeLoaders,
+	CacheScopeAll,
+}
+
+func (e CacheScope) IsValid() bool {
+	switch e {
+	case CacheScopeUsers, CacheScopePosts, CacheScopeLoaders, CacheScopeAll:
+		return true
+	}
+	return false
+}
+
+func (e CacheScope) String() string {
+	return string(e)
+}
+
+func (e *CacheScope) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CacheScope(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CacheScope", str)
+	}
+	return nil
+}
+
+func (e CacheScope) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// ReportTargetType names the kind of content a report is about
+type ReportTargetType string
+
+const (
+	ReportTargetTypePost    ReportTargetType = "POST"
+	ReportTargetTypeComment ReportTargetType = "COMMENT"
+)
+
+var AllReportTargetType = []ReportTargetType{
+	ReportTargetTypePost,
+	ReportTargetTypeComment,
 }
 
+func (e ReportTargetType) IsValid() bool {
+	switch e {
+	case ReportTargetTypePost, ReportTargetTypeComment:
+		return true
+	}
+	return false
+}
+
+func (e ReportTargetType) String() string {
+	return string(e)
+}
+
+func (e *ReportTargetType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportTargetType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportTargetType", str)
+	}
+	return nil
+}
+
+func (e ReportTargetType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// ReportStatus is where a report is in moderation
+type ReportStatus string
+
+const (
+	ReportStatusOpen      ReportStatus = "OPEN"
+	ReportStatusResolved  ReportStatus = "RESOLVED"
+	ReportStatusDismissed ReportStatus = "DISMISSED"
+)
+
+var AllReportStatus = []ReportStatus{
+	ReportStatusOpen,
+	ReportStatusResolved,
+	ReportStatusDismissed,
+}
+
+func (e ReportStatus) IsValid() bool {
+	switch e {
+	case ReportStatusOpen, ReportStatusResolved, ReportStatusDismissed:
+		return true
+	}
+	return false
+}
+
+func (e ReportStatus) String() string {
+	return string(e)
+}
+
+func (e *ReportStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportStatus", str)
+	}
+	return nil
+}
+
+func (e ReportStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// ReportAction is what a moderator does with reported content
+type ReportAction string
+
+const (
+	ReportActionHideContent ReportAction = "HIDE_CONTENT"
+	ReportActionDismiss     ReportAction = "DISMISS"
+)
+
+var AllReportAction = []ReportAction{
+	ReportActionHideContent,
+	ReportActionDismiss,
+}
+
+func (e ReportAction) IsValid() bool {
+	switch e {
+	case ReportActionHideContent, ReportActionDismiss:
+		return true
+	}
+	return false
+}
+
+func (e ReportAction) String() string {
+	return string(e)
+}
+
+func (e *ReportAction) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportAction", str)
+	}
+	return nil
+}
+
+func (e ReportAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// Notification tells one user about activity on their content
+type Notification struct {
+	// UserID is the recipient; it is never exposed
+	UserID    uuid.UUID        `json:"-"`
+	Kind      NotificationKind `json:"kind"`
+	Comment   *Comment         `json:"comment"`
+	CreatedAt time.Time        `json:"createdAt"`
+}
+
+// NotificationKind names the activity a notification is about
+type NotificationKind string
+
+const (
+	NotificationKindCommentOnPost  NotificationKind = "COMMENT_ON_POST"
+	NotificationKindReplyToComment NotificationKind = "REPLY_TO_COMMENT"
+)
+
+var AllNotificationKind = []NotificationKind{
+	NotificationKindCommentOnPost,
+	NotificationKindReplyToComment,
+}
+
+func (e NotificationKind) IsValid() bool {
+	switch e {
+	case NotificationKindCommentOnPost, NotificationKindReplyToComment:
+		return true
+	}
+	return false
+}
+
+func (e NotificationKind) String() string {
+	return string(e)
+}
+
+func (e *NotificationKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotificationKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotificationKind", str)
+	}
+	return nil
+}
+
+func (e NotificationKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// GraphQL Response Types
+type PostConnection = Connection[Post]
+
+type PostEdge = Edge[Post]
+
 type PageInfo struct {
 	HasNextPage     bool    `json:"hasNextPage"`
 	HasPreviousPage bool    `json:"hasPreviousPage"`
@@ -98,7 +532,61 @@ type PageInfo struct {
 }
 
 type AuthPayload struct {
-	Token     string    `json:"token"`
-	User      *User     `json:"user"`
-	ExpiresAt time.Time `json:"expiresAt"`
+	Token                 string     `json:"token"`
+	User                  *User      `json:"user"`
+	ExpiresAt             time.Time  `json:"expiresAt"`
+	RefreshToken          *string    `json:"refreshToken,omitempty"`
+	RefreshTokenExpiresAt *time.Time `json:"refreshTokenExpiresAt,omitempty"`
+}
+
+// RegisterResult is what register returns: the AuthPayload of the new
+// account, or with validateOnly a RegistrationPreview
+type RegisterResult interface {
+	IsRegisterResult()
+}
+
+func (AuthPayload) IsRegisterResult()         {}
+func (RegistrationPreview) IsRegisterResult() {}
+
+// RegistrationPreview is the account a validateOnly register would create,
+// with the normalized email and name
+type RegistrationPreview struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+// CreatePostResult is what createPost returns: the saved Post, or with
+// validateOnly a PostPreview
+type CreatePostResult interface {
+	IsCreatePostResult()
+}
+
+func (Post) IsCreatePostResult()        {}
+func (PostPreview) IsCreatePostResult() {}
+
+// PostPreview is the post a validateOnly createPost would save. It has no
+// ID or timestamps, which are only assigned when the post is saved.
+type PostPreview struct {
+	Title     string   `json:"title"`
+	Content   string   `json:"content"`
+	Tags      []string `json:"tags"`
+	Published bool     `json:"published"`
+}
+
+// DeletePostResult reports what deletePost removed, or with DryRun would remove
+type DeletePostResult struct {
+	Deleted      bool `json:"deleted"`
+	DryRun       bool `json:"dryRun"`
+	PostCount    int  `json:"postCount"`
+	CommentCount int  `json:"commentCount"`
+}
+
+// Stats holds the totals shown on the admin dashboard
+type Stats struct {
+	TotalUsers       int `json:"totalUsers"`
+	TotalPosts       int `json:"totalPosts"`
+	PublishedPosts   int `json:"publishedPosts"`
+	UnpublishedPosts int `json:"unpublishedPosts"`
+	TotalComments    int `json:"totalComments"`
+	NewUsersLast24h  int `json:"newUsersLast24h"`
 }
\ No newline at end of file
@@ -0,0 +1,49 @@
+package relay
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCursor is returned when a cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrCursorSortMismatch is returned when a cursor is used with a different sort than it was created under
+var ErrCursorSortMismatch = errors.New("cursor does not match the requested sort")
+
+// Cursor locates an edge within a sorted connection. Key holds the value
+// the connection was sorted by, so the cursor stays valid as rows change.
+type Cursor struct {
+	Sort string    `json:"s"`
+	Key  string    `json:"k"`
+	ID   uuid.UUID `json:"id"`
+}
+
+// EncodeCursor encodes a cursor as an opaque string
+func EncodeCursor(cursor Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// DecodeCursor decodes a cursor and checks it was created under sort
+func DecodeCursor(encoded, sort string) (Cursor, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Sort == "" || cursor.ID == uuid.Nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	if cursor.Sort != sort {
+		return Cursor{}, fmt.Errorf("%w: cursor was created for %s but %s was requested", ErrCursorSortMismatch, cursor.Sort, sort)
+	}
+
+	return cursor, nil
+}
@@ -0,0 +1,37 @@
+package relay
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	cursor := Cursor{Sort: "TITLE", Key: "Hello: world", ID: uuid.New()}
+
+	decoded, err := DecodeCursor(EncodeCursor(cursor), "TITLE")
+	require.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+}
+
+func TestCursor_RejectsOtherSort(t *testing.T) {
+	encoded := EncodeCursor(Cursor{Sort: "NEWEST", Key: "2024-01-01T00:00:00Z", ID: uuid.New()})
+
+	_, err := DecodeCursor(encoded, "TITLE")
+	assert.ErrorIs(t, err, ErrCursorSortMismatch)
+	assert.Contains(t, err.Error(), "created for NEWEST but TITLE was requested")
+}
+
+func TestCursor_RejectsMalformed(t *testing.T) {
+	for _, encoded := range []string{
+		"not base64!",
+		base64.StdEncoding.EncodeToString([]byte("cursor_123")),
+		base64.StdEncoding.EncodeToString([]byte(`{"s":"NEWEST","k":"x"}`)),
+	} {
+		_, err := DecodeCursor(encoded, "NEWEST")
+		assert.ErrorIs(t, err, ErrInvalidCursor, encoded)
+	}
+}
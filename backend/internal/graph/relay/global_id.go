@@ -0,0 +1,63 @@
+package relay
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Node type names encoded in global IDs
+const (
+	TypeUser    = "User"
+	TypePost    = "Post"
+	TypeComment = "Comment"
+	TypeReport  = "Report"
+)
+
+// ErrInvalidGlobalID is returned when a global ID cannot be decoded
+var ErrInvalidGlobalID = errors.New("invalid global ID")
+
+// ToGlobalID encodes a type name and UUID as an opaque global ID
+func ToGlobalID(typeName string, id uuid.UUID) string {
+	return base64.StdEncoding.EncodeToString([]byte(typeName + ":" + id.String()))
+}
+
+// FromGlobalID decodes a global ID into its type name and UUID
+func FromGlobalID(globalID string) (string, uuid.UUID, error) {
+	decoded, err := base64.StdEncoding.DecodeString(globalID)
+	if err != nil {
+		return "", uuid.Nil, ErrInvalidGlobalID
+	}
+
+	typeName, rawID, ok := strings.Cut(string(decoded), ":")
+	if !ok || typeName == "" {
+		return "", uuid.Nil, ErrInvalidGlobalID
+	}
+
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return "", uuid.Nil, ErrInvalidGlobalID
+	}
+
+	return typeName, id, nil
+}
+
+// ParseID accepts either a global ID of the given type or a raw UUID,
+// so arguments keep working for clients that still send raw IDs
+func ParseID(id, typeName string) (uuid.UUID, error) {
+	if raw, err := uuid.Parse(id); err == nil {
+		return raw, nil
+	}
+
+	decodedType, raw, err := FromGlobalID(id)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if decodedType != typeName {
+		return uuid.Nil, ErrInvalidGlobalID
+	}
+
+	return raw, nil
+}
@@ -0,0 +1,58 @@
+package relay
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobalID_RoundTrip(t *testing.T) {
+	for _, typeName := range []string{TypeUser, TypePost, TypeComment} {
+		id := uuid.New()
+
+		globalID := ToGlobalID(typeName, id)
+		assert.NotContains(t, globalID, id.String(), typeName)
+
+		decodedType, decodedID, err := FromGlobalID(globalID)
+		require.NoError(t, err, typeName)
+		assert.Equal(t, typeName, decodedType)
+		assert.Equal(t, id, decodedID)
+	}
+}
+
+func TestGlobalID_DistinctPerType(t *testing.T) {
+	id := uuid.New()
+	assert.NotEqual(t, ToGlobalID(TypeUser, id), ToGlobalID(TypePost, id))
+}
+
+func TestFromGlobalID_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"not base64":   "%%%",
+		"missing type": base64.StdEncoding.EncodeToString([]byte(":" + uuid.NewString())),
+		"no separator": base64.StdEncoding.EncodeToString([]byte("User")),
+		"bad uuid":     base64.StdEncoding.EncodeToString([]byte("User:123")),
+	}
+
+	for name, globalID := range cases {
+		_, _, err := FromGlobalID(globalID)
+		assert.ErrorIs(t, err, ErrInvalidGlobalID, name)
+	}
+}
+
+func TestParseID(t *testing.T) {
+	id := uuid.New()
+
+	parsed, err := ParseID(ToGlobalID(TypePost, id), TypePost)
+	require.NoError(t, err)
+	assert.Equal(t, id, parsed)
+
+	parsed, err = ParseID(id.String(), TypePost)
+	require.NoError(t, err)
+	assert.Equal(t, id, parsed)
+
+	_, err = ParseID(ToGlobalID(TypeUser, id), TypePost)
+	assert.ErrorIs(t, err, ErrInvalidGlobalID)
+}
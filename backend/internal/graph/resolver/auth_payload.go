@@ -0,0 +1,26 @@
+package resolver
+
+import (
+	"context"
+
+	"backend/internal/auth"
+	"backend/internal/graph/model"
+)
+
+// authPayload sets the auth cookies for response in cookie mode and returns
+// its payload. The refresh token is only in the payload in header mode; in
+// cookie mode it travels in its HttpOnly cookie alone.
+func authPayload(ctx context.Context, response *auth.AuthResponse) *model.AuthPayload {
+	auth.SetAuthCookies(ctx, response)
+
+	payload := &model.AuthPayload{
+		Token:                 response.Token,
+		User:                  response.User,
+		ExpiresAt:             response.ExpiresAt,
+		RefreshTokenExpiresAt: response.RefreshExpiresAt,
+	}
+	if response.RefreshToken != "" {
+		payload.RefreshToken = &response.RefreshToken
+	}
+	return payload
+}
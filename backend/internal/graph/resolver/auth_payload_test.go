@@ -0,0 +1,175 @@
+package resolver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend/internal/auth"
+	"backend/internal/graph/errors"
+	"backend/internal/graph/model"
+	"backend/internal/security"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// setupLoginResolver returns a resolver whose user repository knows one user
+// with password "password123"
+func setupLoginResolver(t *testing.T) (*mutationResolver, *model.User) {
+	resolver, mockUserRepo, _, _ := setupTestResolver()
+	hash, err := resolver.AuthManager.PasswordService.HashPassword("password123")
+	require.NoError(t, err)
+
+	user := &model.User{ID: uuid.New(), Email: "login@example.com", Name: "Login", PasswordHash: hash}
+	mockUserRepo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+	mockUserRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockUserRepo.On("RecordLogin", mock.Anything, user.ID, mock.Anything, mock.Anything).Return(nil).Maybe()
+	return &mutationResolver{resolver}, user
+}
+
+// serveInCookieMode switches the resolver's auth middleware to cookie mode
+// and runs resolve behind it for a request carrying cookies
+func serveInCookieMode(m *mutationResolver, cookies []*http.Cookie, resolve func(ctx context.Context)) *http.Response {
+	middleware := m.AuthManager.Middleware
+	middleware.UseTokenCookie(auth.NewTokenCookie(&auth.Config{TokenMode: auth.TokenModeCookie}))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/graphql", middleware.OptionalAuth(), func(c *gin.Context) {
+		resolve(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+// findCookie returns the cookie named name, or nil
+func findCookie(cookies []*http.Cookie, name string) *http.Cookie {
+	for _, cookie := range cookies {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestMutationResolver_Login_HeaderModeReturnsBothTokens(t *testing.T) {
+	m, user := setupLoginResolver(t)
+
+	result, err := m.Login(context.Background(), user.Email, "password123")
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Token)
+	require.NotNil(t, result.RefreshToken)
+	require.NotNil(t, result.RefreshTokenExpiresAt)
+	assert.True(t, result.RefreshTokenExpiresAt.After(result.ExpiresAt))
+
+	claims, err := m.AuthManager.JWTService.ValidateRefreshToken(*result.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+
+	// Each token is only accepted for its own purpose
+	_, err = m.AuthManager.JWTService.ValidateToken(*result.RefreshToken)
+	assert.Error(t, err)
+	_, err = m.AuthManager.JWTService.ValidateRefreshToken(result.Token)
+	assert.Error(t, err)
+}
+
+func TestMutationResolver_Login_RecordsClientIP(t *testing.T) {
+	resolver, mockUserRepo, _, _ := setupTestResolver()
+	hash, err := resolver.AuthManager.PasswordService.HashPassword("password123")
+	require.NoError(t, err)
+	user := &model.User{ID: uuid.New(), Email: "login@example.com", Name: "Login", PasswordHash: hash}
+	mockUserRepo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+
+	recorded := make(chan string, 1)
+	mockUserRepo.On("RecordLogin", mock.Anything, user.ID, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { recorded <- args.String(3) }).
+		Return(nil)
+	ctx := security.WithClientInfo(context.Background(), "203.0.113.7", "test-agent")
+
+	_, err = (&mutationResolver{resolver}).Login(ctx, user.Email, "password123")
+	require.NoError(t, err)
+
+	select {
+	case ip := <-recorded:
+		assert.Equal(t, "203.0.113.7", ip)
+	case <-time.After(time.Second):
+		t.Fatal("login was not recorded")
+	}
+}
+
+func TestMutationResolver_Login_CookieModeSetsRefreshCookie(t *testing.T) {
+	m, user := setupLoginResolver(t)
+
+	var result *model.AuthPayload
+	resp := serveInCookieMode(m, nil, func(ctx context.Context) {
+		var err error
+		result, err = m.Login(ctx, user.Email, "password123")
+		require.NoError(t, err)
+	})
+
+	assert.NotEmpty(t, result.Token)
+	assert.Nil(t, result.RefreshToken)
+	require.NotNil(t, result.RefreshTokenExpiresAt)
+
+	access := findCookie(resp.Cookies(), auth.DefaultTokenCookieName)
+	require.NotNil(t, access)
+	assert.Equal(t, result.Token, access.Value)
+
+	refresh := findCookie(resp.Cookies(), auth.DefaultRefreshCookieName)
+	require.NotNil(t, refresh)
+	assert.True(t, refresh.HttpOnly)
+	claims, err := m.AuthManager.JWTService.ValidateRefreshToken(refresh.Value)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+}
+
+func TestMutationResolver_RefreshToken_WithRefreshToken(t *testing.T) {
+	m, user := setupLoginResolver(t)
+	login, err := m.Login(context.Background(), user.Email, "password123")
+	require.NoError(t, err)
+
+	t.Run("given as an argument", func(t *testing.T) {
+		result, err := m.RefreshToken(context.Background(), login.RefreshToken)
+
+		require.NoError(t, err)
+		assert.Equal(t, user, result.User)
+		assert.NotNil(t, result.RefreshToken)
+		_, err = m.AuthManager.JWTService.ValidateToken(result.Token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("sent as a cookie", func(t *testing.T) {
+		var result *model.AuthPayload
+		cookie := &http.Cookie{Name: auth.DefaultRefreshCookieName, Value: *login.RefreshToken}
+		resp := serveInCookieMode(m, []*http.Cookie{cookie}, func(ctx context.Context) {
+			var err error
+			result, err = m.RefreshToken(ctx, nil)
+			require.NoError(t, err)
+		})
+
+		assert.Nil(t, result.RefreshToken)
+		assert.NotNil(t, findCookie(resp.Cookies(), auth.DefaultTokenCookieName))
+		assert.NotNil(t, findCookie(resp.Cookies(), auth.DefaultRefreshCookieName))
+	})
+
+	t.Run("an access token is not a refresh token", func(t *testing.T) {
+		result, err := m.RefreshToken(context.Background(), &login.Token)
+
+		assert.Nil(t, result)
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeUnauthenticated, err.(*errors.GraphQLError).Code)
+	})
+}
@@ -0,0 +1,57 @@
+package resolver
+
+import (
+	"fmt"
+	"strings"
+
+	"backend/internal/graph/errors"
+	"backend/internal/graph/model"
+	"backend/internal/graph/relay"
+	"github.com/google/uuid"
+)
+
+// maxBatchIDs is the most ids postsByIds and usersByIds accept in one call
+const maxBatchIDs = 100
+
+// parseBatchIDs parses the ids of a postsByIds or usersByIds call, dropping
+// repeats but keeping the order in which each id first appears
+func parseBatchIDs(ids []string, typeName string) ([]uuid.UUID, error) {
+	noun := strings.ToLower(typeName)
+	if len(ids) > maxBatchIDs {
+		return nil, errors.NewValidationError(fmt.Sprintf("Cannot request more than %d %ss", maxBatchIDs, noun), "ids")
+	}
+
+	seen := make(map[uuid.UUID]bool, len(ids))
+	parsed := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		value, err := relay.ParseID(id, typeName)
+		if err != nil {
+			return nil, errors.NewInvalidFormatError(fmt.Sprintf("Invalid %s ID format", noun), "ids")
+		}
+		if !seen[value] {
+			seen[value] = true
+			parsed = append(parsed, value)
+		}
+	}
+	return parsed, nil
+}
+
+// orderByID lines items up with ids, leaving nil where an item is missing
+func orderByID[T any](ids []uuid.UUID, items []*T, id func(*T) uuid.UUID) []*T {
+	byID := make(map[uuid.UUID]*T, len(items))
+	for _, item := range items {
+		byID[id(item)] = item
+	}
+
+	ordered := make([]*T, len(ids))
+	for i, value := range ids {
+		ordered[i] = byID[value]
+	}
+	return ordered
+}
+
+// postID keys posts for orderByID
+func postID(post *model.Post) uuid.UUID { return post.ID }
+
+// userID keys users for orderByID
+func userID(user *model.User) uuid.UUID { return user.ID }
@@ -0,0 +1,43 @@
+package resolver
+
+import (
+	"context"
+
+	"backend/internal/dataloader"
+	"backend/internal/graph/model"
+)
+
+// resettableCache is implemented by cached repositories that can drop every
+// entry they hold
+type resettableCache interface {
+	Reset(ctx context.Context) error
+}
+
+// clearCaches empties the caches in scope. Repositories that aren't cached
+// are skipped.
+func (r *Resolver) clearCaches(ctx context.Context, scope model.CacheScope) error {
+	var repos []interface{}
+	switch scope {
+	case model.CacheScopeUsers:
+		repos = append(repos, r.UserRepo)
+	case model.CacheScopePosts:
+		repos = append(repos, r.PostRepo)
+	case model.CacheScopeAll:
+		repos = append(repos, r.UserRepo, r.PostRepo)
+	}
+
+	for _, repo := range repos {
+		if cached, ok := repo.(resettableCache); ok {
+			if err := cached.Reset(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	if scope == model.CacheScopeLoaders || scope == model.CacheScopeAll {
+		if loaders := dataloader.For(ctx); loaders != nil {
+			loaders.ClearAll()
+		}
+	}
+	return nil
+}
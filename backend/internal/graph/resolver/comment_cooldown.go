@@ -0,0 +1,86 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"backend/internal/graph/errors"
+	"github.com/google/uuid"
+)
+
+// DefaultCommentCooldown is the least time between two comments by one user
+const DefaultCommentCooldown = 10 * time.Second
+
+// CooldownStore claims keys that expire on their own. cache.RedisCache
+// satisfies it, which shares cooldowns across server instances.
+type CooldownStore interface {
+	SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+	Get(ctx context.Context, key string, dest interface{}) error
+	Delete(ctx context.Context, key string) error
+}
+
+// CommentCooldown allows each user at most one comment per Period. It is
+// separate from the request rate limiter, which counts every operation.
+type CommentCooldown struct {
+	Store  CooldownStore
+	Period time.Duration
+}
+
+// CommentCooldownFromEnv returns COMMENT_COOLDOWN_SECONDS as a duration, or
+// DefaultCommentCooldown when it is unset or invalid. 0 disables the cooldown.
+func CommentCooldownFromEnv() time.Duration {
+	if value := os.Getenv("COMMENT_COOLDOWN_SECONDS"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return DefaultCommentCooldown
+}
+
+// claimCommentCooldown starts the user's comment cooldown, or rejects the
+// comment with the seconds left when one is already running. The cooldown
+// is skipped when it is not configured or its store cannot be reached.
+// Calling release ends a cooldown this call started, for comments that end
+// up not being saved.
+func (r *Resolver) claimCommentCooldown(ctx context.Context, userID uuid.UUID) (release func(), err error) {
+	release = func() {}
+	cooldown := r.CommentCooldown
+	if cooldown == nil || cooldown.Store == nil || cooldown.Period <= 0 {
+		return release, nil
+	}
+
+	key := "comment_cooldown:" + userID.String()
+	now := r.now()
+	claimed, err := cooldown.Store.SetNX(ctx, key, now.Add(cooldown.Period), cooldown.Period)
+	if err != nil {
+		log.Printf("Failed to check comment cooldown for %s: %v", userID, err)
+		return release, nil
+	}
+	if claimed {
+		release = func() {
+			if err := cooldown.Store.Delete(ctx, key); err != nil {
+				log.Printf("Failed to release comment cooldown for %s: %v", userID, err)
+			}
+		}
+		return release, nil
+	}
+
+	// The claim holds the time the running cooldown ends
+	retryAfter := int(cooldown.Period.Seconds())
+	var until time.Time
+	if err := cooldown.Store.Get(ctx, key, &until); err == nil {
+		retryAfter = int(math.Ceil(until.Sub(now).Seconds()))
+	}
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+
+	rateErr := errors.NewRateLimitError(fmt.Sprintf("You are commenting too quickly, try again in %d seconds", retryAfter))
+	rateErr.Extensions = map[string]interface{}{"retryAfter": retryAfter}
+	return release, rateErr
+}
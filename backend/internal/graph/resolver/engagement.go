@@ -0,0 +1,27 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+
+	"backend/internal/dataloader"
+	"backend/internal/graph/model"
+	"github.com/google/uuid"
+)
+
+// reactionTypeCounts returns a post's reaction counts per type, batched
+// through the request's DataLoader when available
+func (r *Resolver) reactionTypeCounts(ctx context.Context, postID uuid.UUID) ([]*model.ReactionTypeCount, error) {
+	if loaders := dataloader.For(ctx); loaders != nil {
+		return loaders.ReactionTypeCountLoader.Load(ctx, postID)
+	}
+
+	counts, err := r.ReactionRepo.CountByTypeByPostIDs(ctx, []uuid.UUID{postID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reaction counts: %w", err)
+	}
+	if typeCounts, ok := counts[postID]; ok {
+		return typeCounts, nil
+	}
+	return []*model.ReactionTypeCount{}, nil
+}
@@ -7,13 +7,34 @@ package resolver
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
+	"backend/internal/auth"
+	"backend/internal/dataloader"
+	"backend/internal/graph/errors"
 	"backend/internal/graph/generated"
 	"backend/internal/graph/model"
+	"backend/internal/graph/relay"
+	"github.com/google/uuid"
 )
 
+// ID is the resolver for the id field on Comment.
+func (r *commentResolver) ID(ctx context.Context, obj *model.Comment) (string, error) {
+	return relay.ToGlobalID(relay.TypeComment, obj.ID), nil
+}
+
 // Author is the resolver for the author field on Comment.
 func (r *commentResolver) Author(ctx context.Context, obj *model.Comment) (*model.User, error) {
+	// Batch through the request's DataLoader when available
+	if loaders := dataloader.For(ctx); loaders != nil {
+		user, err := loaders.UserLoader.Load(ctx, obj.AuthorID)
+		if err != nil {
+			return nil, authorError(err, "comment")
+		}
+		return user, nil
+	}
+
 	// Get user by AuthorID
 	user, err := r.UserRepo.GetByID(ctx, obj.AuthorID)
 	if err != nil {
@@ -24,29 +45,259 @@ func (r *commentResolver) Author(ctx context.Context, obj *model.Comment) (*mode
 
 // Post is the resolver for the post field on Comment.
 func (r *commentResolver) Post(ctx context.Context, obj *model.Comment) (*model.Post, error) {
-	// Get post by PostID
-	post, err := r.PostRepo.GetByID(ctx, obj.PostID)
+	var post *model.Post
+	var err error
+
+	// Batch through the request's DataLoader when available
+	if loaders := dataloader.For(ctx); loaders != nil {
+		post, err = loaders.PostLoader.Load(ctx, obj.PostID)
+	} else {
+		post, err = r.PostRepo.GetByID(ctx, obj.PostID)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get comment post: %w", err)
 	}
+
+	// Drafts and hidden posts look missing to viewers who can't see them
+	if post == nil || !canSeePost(ctx, post) {
+		return nil, errors.NewNotFoundError("Post")
+	}
 	return post, nil
 }
 
+// ParentID is the resolver for the parentId field on Comment.
+func (r *commentResolver) ParentID(ctx context.Context, obj *model.Comment) (*string, error) {
+	if obj.ParentID == nil {
+		return nil, nil
+	}
+	id := relay.ToGlobalID(relay.TypeComment, *obj.ParentID)
+	return &id, nil
+}
+
+// ID is the resolver for the id field on Post.
+func (r *postResolver) ID(ctx context.Context, obj *model.Post) (string, error) {
+	return relay.ToGlobalID(relay.TypePost, obj.ID), nil
+}
+
 // Author is the resolver for the author field on Post.
 func (r *postResolver) Author(ctx context.Context, obj *model.Post) (*model.User, error) {
-	// Get user by AuthorID
+	// Already loaded with the post
+	if obj.Author != nil {
+		return obj.Author, nil
+	}
+
+	// Batch through the request's DataLoader when available
+	if loaders := dataloader.For(ctx); loaders != nil {
+		user, err := loaders.UserLoader.Load(ctx, obj.AuthorID)
+		if authorMissing(err) {
+			return deletedAuthor(obj.AuthorID), nil
+		}
+		if err != nil {
+			return nil, authorError(err, "post")
+		}
+		return user, nil
+	}
+
+	// Get user by AuthorID; a deleted author is shown as a placeholder
 	user, err := r.UserRepo.GetByID(ctx, obj.AuthorID)
+	if authorMissing(err) {
+		return deletedAuthor(obj.AuthorID), nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get post author: %w", err)
 	}
 	return user, nil
 }
 
+// ReactionCount is the resolver for the reactionCount field on Post.
+func (r *postResolver) ReactionCount(ctx context.Context, obj *model.Post) (int, error) {
+	// Batch through the request's DataLoader when available
+	if loaders := dataloader.For(ctx); loaders != nil {
+		return loaders.ReactionCountLoader.Load(ctx, obj.ID)
+	}
+
+	counts, err := r.ReactionRepo.CountByPostIDs(ctx, []uuid.UUID{obj.ID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get reaction count: %w", err)
+	}
+	return counts[obj.ID], nil
+}
+
+// ViewerHasReacted is the resolver for the viewerHasReacted field on Post.
+func (r *postResolver) ViewerHasReacted(ctx context.Context, obj *model.Post) (bool, error) {
+	// Anonymous viewers have never reacted
+	user, ok := auth.GetUserFromContext(ctx)
+	if !ok || user == nil {
+		return false, nil
+	}
+
+	// Batch through the request's DataLoader when available
+	if loaders := dataloader.For(ctx); loaders != nil {
+		return loaders.ViewerReactionLoader.Load(ctx, obj.ID, user.ID)
+	}
+
+	reacted, err := r.ReactionRepo.ViewerReactedByPostIDs(ctx, user.ID, []uuid.UUID{obj.ID})
+	if err != nil {
+		return false, fmt.Errorf("failed to get viewer reaction: %w", err)
+	}
+	return reacted[obj.ID], nil
+}
+
+// CommentSummary is the resolver for the commentSummary field on Post.
+func (r *postResolver) CommentSummary(ctx context.Context, obj *model.Post) (*model.CommentSummary, error) {
+	// Batch through the request's DataLoader when available
+	if loaders := dataloader.For(ctx); loaders != nil {
+		return loaders.CommentSummaryLoader.Load(ctx, obj.ID)
+	}
+
+	summaries, err := r.CommentRepo.SummaryByPostIDs(ctx, []uuid.UUID{obj.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment summary: %w", err)
+	}
+	if summary, ok := summaries[obj.ID]; ok {
+		return summary, nil
+	}
+	return &model.CommentSummary{}, nil
+}
+
+// Engagement is the resolver for the engagement field on Post.
+func (r *postResolver) Engagement(ctx context.Context, obj *model.Post) (*model.PostEngagement, error) {
+	// Start the three loads together, so their batch windows overlap
+	// instead of being waited out one after another
+	var (
+		wg                                   sync.WaitGroup
+		typeCounts                           []*model.ReactionTypeCount
+		viewerHasReacted                     bool
+		summary                              *model.CommentSummary
+		typeCountsErr, viewerErr, summaryErr error
+	)
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		typeCounts, typeCountsErr = r.reactionTypeCounts(ctx, obj.ID)
+	}()
+	go func() {
+		defer wg.Done()
+		viewerHasReacted, viewerErr = r.ViewerHasReacted(ctx, obj)
+	}()
+	go func() {
+		defer wg.Done()
+		summary, summaryErr = r.CommentSummary(ctx, obj)
+	}()
+	wg.Wait()
+	for _, err := range []error{typeCountsErr, viewerErr, summaryErr} {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	engagement := &model.PostEngagement{
+		ReactionsByType:  typeCounts,
+		ViewerHasReacted: viewerHasReacted,
+		CommentCount:     summary.Count,
+	}
+	for _, typeCount := range typeCounts {
+		engagement.ReactionCount += typeCount.Count
+	}
+	return engagement, nil
+}
+
+// ID is the resolver for the id field on Report.
+func (r *reportResolver) ID(ctx context.Context, obj *model.Report) (string, error) {
+	return relay.ToGlobalID(relay.TypeReport, obj.ID), nil
+}
+
+// Reporter is the resolver for the reporter field on Report.
+func (r *reportResolver) Reporter(ctx context.Context, obj *model.Report) (*model.User, error) {
+	// Batch through the request's DataLoader when available
+	if loaders := dataloader.For(ctx); loaders != nil {
+		user, err := loaders.UserLoader.Load(ctx, obj.ReporterID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get reporter: %w", err)
+		}
+		return user, nil
+	}
+
+	user, err := r.UserRepo.GetByID(ctx, obj.ReporterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reporter: %w", err)
+	}
+	return user, nil
+}
+
+// TargetID is the resolver for the targetId field on Report.
+func (r *reportResolver) TargetID(ctx context.Context, obj *model.Report) (string, error) {
+	if obj.TargetType == model.ReportTargetTypeComment {
+		return relay.ToGlobalID(relay.TypeComment, obj.TargetID), nil
+	}
+	return relay.ToGlobalID(relay.TypePost, obj.TargetID), nil
+}
+
+// ID is the resolver for the id field on User.
+func (r *userResolver) ID(ctx context.Context, obj *model.User) (string, error) {
+	return relay.ToGlobalID(relay.TypeUser, obj.ID), nil
+}
+
+// Email is the resolver for the email field on User. Users found by
+// searchUsers carry no address unless the viewer is an admin; that resolves
+// to null rather than an empty string.
+func (r *userResolver) Email(ctx context.Context, obj *model.User) (*string, error) {
+	if obj.Email == "" {
+		return nil, nil
+	}
+	return &obj.Email, nil
+}
+
+// LastLoginAt is the resolver for the lastLoginAt field on User.
+func (r *userResolver) LastLoginAt(ctx context.Context, obj *model.User) (*time.Time, error) {
+	if !isAdmin(ctx) {
+		return nil, nil
+	}
+	return obj.LastLoginAt, nil
+}
+
+// LastLoginIP is the resolver for the lastLoginIp field on User.
+func (r *userResolver) LastLoginIP(ctx context.Context, obj *model.User) (*string, error) {
+	if !isAdmin(ctx) {
+		return nil, nil
+	}
+	return obj.LastLoginIP, nil
+}
+
+// Posts is the resolver for the posts field on User.
+func (r *userResolver) Posts(ctx context.Context, obj *model.User) ([]*model.Post, error) {
+	// Batch through the request's DataLoader when available
+	if loaders := dataloader.For(ctx); loaders != nil {
+		posts, err := loaders.PostLoader.LoadByAuthor(ctx, obj.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user posts: %w", err)
+		}
+		return posts, nil
+	}
+
+	postsByAuthor, err := r.PostRepo.GetPublishedByAuthorIDs(ctx, []uuid.UUID{obj.ID}, dataloader.DefaultAuthorPostsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user posts: %w", err)
+	}
+	if posts, ok := postsByAuthor[obj.ID]; ok {
+		return posts, nil
+	}
+	return []*model.Post{}, nil
+}
+
 // Comment returns generated.CommentResolver implementation.
 func (r *Resolver) Comment() generated.CommentResolver { return &commentResolver{r} }
 
 // Post returns generated.PostResolver implementation.
 func (r *Resolver) Post() generated.PostResolver { return &postResolver{r} }
 
+// Report returns generated.ReportResolver implementation.
+func (r *Resolver) Report() generated.ReportResolver { return &reportResolver{r} }
+
+// User returns generated.UserResolver implementation.
+func (r *Resolver) User() generated.UserResolver { return &userResolver{r} }
+
 type commentResolver struct{ *Resolver }
-type postResolver struct{ *Resolver }
\ No newline at end of file
+type postResolver struct{ *Resolver }
+type reportResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
\ No newline at end of file
@@ -0,0 +1,133 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"backend/internal/auth"
+	"backend/internal/graph/errors"
+	"backend/internal/graph/model"
+	"backend/internal/graph/relay"
+	"backend/internal/security"
+	"github.com/google/uuid"
+)
+
+// DefaultImpersonationCooldown is the least time between two impersonations
+// started by one admin
+const DefaultImpersonationCooldown = time.Minute
+
+// Impersonation limits how admins act as other users. Each impersonation
+// token lasts Duration, and an admin may start one per Cooldown.
+type Impersonation struct {
+	// Duration of impersonation tokens; auth.DefaultImpersonationDuration
+	// is used when zero, and auth.MaxImpersonationDuration caps it
+	Duration time.Duration
+	// Store tracks running cooldowns; impersonations are not throttled when nil
+	Store    CooldownStore
+	Cooldown time.Duration
+}
+
+// impersonationDuration returns the configured impersonation token lifetime
+func (r *Resolver) impersonationDuration() time.Duration {
+	if r.Impersonation == nil || r.Impersonation.Duration <= 0 {
+		return auth.DefaultImpersonationDuration
+	}
+	return r.Impersonation.Duration
+}
+
+// checkImpersonationCooldown starts the admin's impersonation cooldown, or
+// rejects the impersonation with the seconds left when one is running
+func (r *Resolver) checkImpersonationCooldown(ctx context.Context, adminID string) error {
+	policy := r.Impersonation
+	if policy == nil || policy.Store == nil || policy.Cooldown <= 0 {
+		return nil
+	}
+
+	key := "impersonation_cooldown:" + adminID
+	now := r.now()
+	claimed, err := policy.Store.SetNX(ctx, key, now.Add(policy.Cooldown), policy.Cooldown)
+	if err != nil {
+		log.Printf("Failed to check impersonation cooldown for %s: %v", adminID, err)
+		return nil
+	}
+	if claimed {
+		return nil
+	}
+
+	retryAfter := int(policy.Cooldown.Seconds())
+	var until time.Time
+	if err := policy.Store.Get(ctx, key, &until); err == nil {
+		retryAfter = int(math.Ceil(until.Sub(now).Seconds()))
+	}
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+
+	rateErr := errors.NewRateLimitError(fmt.Sprintf("Impersonating too often, try again in %d seconds", retryAfter))
+	rateErr.Extensions = map[string]interface{}{"retryAfter": retryAfter}
+	return rateErr
+}
+
+// startImpersonation issues the acting admin a token for the user with the
+// given ID and audits it. Impersonated requests cannot start another one.
+func (r *mutationResolver) startImpersonation(ctx context.Context, userID string) (*model.AuthPayload, error) {
+	admin, err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, impersonating := auth.GetImpersonatorFromContext(ctx); impersonating {
+		return nil, errors.NewForbiddenError("Stop the current impersonation first")
+	}
+
+	adminID, err := uuid.Parse(admin.ID)
+	if err != nil {
+		return nil, errors.NewForbiddenError("Admin role required")
+	}
+	targetID, err := relay.ParseID(userID, relay.TypeUser)
+	if err != nil {
+		return nil, errors.NewInvalidFormatError("Invalid user ID format", "userId")
+	}
+	if targetID == adminID {
+		return nil, errors.NewValidationError("You cannot impersonate yourself", "userId")
+	}
+
+	target, err := r.UserRepo.GetByID(ctx, targetID)
+	if err != nil {
+		return nil, errors.NewNotFoundError("User")
+	}
+
+	if err := r.checkImpersonationCooldown(ctx, admin.ID); err != nil {
+		r.auditLogger().LogAccess(ctx, admin, "impersonate_start", "user", targetID.String(), false, err)
+		return nil, err
+	}
+
+	token, expiresAt, err := r.AuthManager.JWTService.GenerateImpersonationToken(target, adminID, r.impersonationDuration())
+	if err != nil {
+		r.auditLogger().LogAccess(ctx, admin, "impersonate_start", "user", targetID.String(), false, err)
+		return nil, errors.NewInternalError("Impersonation token could not be issued")
+	}
+	r.auditLogger().LogAccessWithMetadata(ctx, admin, "impersonate_start", "user", targetID.String(), true, nil,
+		map[string]interface{}{"expires_at": expiresAt.Unix()})
+
+	return &model.AuthPayload{Token: token, User: target, ExpiresAt: expiresAt}, nil
+}
+
+// stopImpersonation audits the end of the impersonation the request is
+// authenticated with, attributing it to the admin behind it. The token
+// itself stays valid until it expires; clients discard it.
+func (r *mutationResolver) stopImpersonation(ctx context.Context) (bool, error) {
+	user, err := auth.RequireUser(ctx)
+	if err != nil {
+		return false, errors.NewUnauthenticatedError("Authentication required")
+	}
+	adminID, ok := auth.GetImpersonatorFromContext(ctx)
+	if !ok {
+		return false, errors.NewValidationError("This session is not an impersonation", "")
+	}
+
+	r.auditLogger().LogAccess(ctx, &security.User{ID: adminID.String(), Role: security.RoleAdmin}, "impersonate_stop", "user", user.ID.String(), true, nil)
+	return true, nil
+}
@@ -0,0 +1,122 @@
+package resolver
+
+import (
+	"context"
+	"strings"
+
+	"backend/internal/graph/errors"
+	"backend/internal/graph/model"
+	"backend/internal/graph/relay"
+	"backend/internal/graph/validation"
+)
+
+// maxHiddenReasonLength bounds the moderation note stored with hidden content
+const maxHiddenReasonLength = 500
+
+// validateHiddenReason trims and checks a moderation reason
+func validateHiddenReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return "", errors.NewValidationError("Reason cannot be empty", "reason")
+	}
+	if len(reason) > maxHiddenReasonLength {
+		return "", errors.NewValidationError("Reason cannot exceed 500 characters", "reason")
+	}
+	return reason, nil
+}
+
+// validateTagMerge trims the tags of a merge and checks them. The source tag
+// only has to be non-empty so legacy tags can still be merged away, while
+// the target must be a valid tag.
+func validateTagMerge(from, into string, limits model.PostTagLimits) (string, string, error) {
+	from = strings.TrimSpace(from)
+	into = strings.TrimSpace(into)
+	if from == "" {
+		return "", "", errors.NewValidationError("Tag to merge cannot be empty", "from")
+	}
+	if err := validation.NewValidator().WithTagLimits(limits).ValidateTags([]string{into}); err != nil {
+		return "", "", errors.NewValidationError(err.Error(), "into")
+	}
+	if from == into {
+		return "", "", errors.NewValidationError("Cannot merge a tag into itself", "into")
+	}
+	return from, into, nil
+}
+
+// moderationAction names the audit action for a visibility change
+func moderationAction(resource string, hidden bool) string {
+	if hidden {
+		return "hide_" + resource
+	}
+	return "unhide_" + resource
+}
+
+// setPostHidden applies a moderator's visibility change to a post and audits it
+func (r *mutationResolver) setPostHidden(ctx context.Context, id string, hidden bool, reason *string) (*model.Post, error) {
+	moderator, err := requireModerator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	postID, err := relay.ParseID(id, relay.TypePost)
+	if err != nil {
+		return nil, errors.NewInvalidFormatError("Invalid post ID format", "id")
+	}
+
+	post, err := r.PostRepo.GetByID(ctx, postID)
+	if err != nil {
+		return nil, errors.NewNotFoundError("Post")
+	}
+
+	action := moderationAction("post", hidden)
+	metadata := map[string]interface{}{"author_id": post.AuthorID.String()}
+	if reason != nil {
+		metadata["reason"] = *reason
+	}
+
+	if err := r.PostRepo.SetHidden(ctx, postID, hidden, reason); err != nil {
+		r.auditLogger().LogAccessWithMetadata(ctx, moderator, action, "post", postID.String(), false, err, metadata)
+		return nil, errors.WrapDatabaseError(err, "post moderation")
+	}
+	r.auditLogger().LogAccessWithMetadata(ctx, moderator, action, "post", postID.String(), true, nil, metadata)
+
+	r.invalidatePostVisibility(postID)
+
+	post.Hidden = hidden
+	post.HiddenReason = reason
+	return post, nil
+}
+
+// setCommentHidden applies a moderator's visibility change to a comment and audits it
+func (r *mutationResolver) setCommentHidden(ctx context.Context, id string, hidden bool, reason *string) (*model.Comment, error) {
+	moderator, err := requireModerator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	commentID, err := relay.ParseID(id, relay.TypeComment)
+	if err != nil {
+		return nil, errors.NewInvalidFormatError("Invalid comment ID format", "id")
+	}
+
+	comment, err := r.CommentRepo.GetByID(ctx, commentID)
+	if err != nil {
+		return nil, errors.NewNotFoundError("Comment")
+	}
+
+	action := moderationAction("comment", hidden)
+	metadata := map[string]interface{}{"author_id": comment.AuthorID.String()}
+	if reason != nil {
+		metadata["reason"] = *reason
+	}
+
+	if err := r.CommentRepo.SetHidden(ctx, commentID, hidden, reason); err != nil {
+		r.auditLogger().LogAccessWithMetadata(ctx, moderator, action, "comment", commentID.String(), false, err, metadata)
+		return nil, errors.WrapDatabaseError(err, "comment moderation")
+	}
+	r.auditLogger().LogAccessWithMetadata(ctx, moderator, action, "comment", commentID.String(), true, nil, metadata)
+
+	comment.Hidden = hidden
+	comment.HiddenReason = reason
+	return comment, nil
+}
@@ -6,14 +6,17 @@ package resolver
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
-	"time"
 
 	"backend/internal/auth"
 	"backend/internal/graph/errors"
 	"backend/internal/graph/generated"
 	"backend/internal/graph/model"
+	"backend/internal/graph/relay"
 	"backend/internal/graph/validation"
+	"backend/internal/repository"
+	"backend/internal/security"
 	"github.com/google/uuid"
 )
 
@@ -25,8 +28,8 @@ func (r *mutationResolver) Login(ctx context.Context, email string, password str
 		Password: password,
 	}
 
-	// Get client IP (this would come from HTTP context in real implementation)
-	clientIP := "127.0.0.1" // TODO: Extract from HTTP context
+	// Client IP recorded by ClientInfoMiddleware
+	clientIP := security.ClientIPFromContext(ctx)
 
 	// Authenticate user
 	authResponse, err := r.AuthManager.AuthService.Login(ctx, loginReq, clientIP)
@@ -34,93 +37,241 @@ func (r *mutationResolver) Login(ctx context.Context, email string, password str
 		return nil, fmt.Errorf("login failed: %w", err)
 	}
 
-	return &model.AuthPayload{
-		Token:     authResponse.Token,
-		User:      authResponse.User,
-		ExpiresAt: authResponse.ExpiresAt,
-	}, nil
+	return authPayload(ctx, authResponse), nil
 }
 
 // Register is the resolver for the register field.
-func (r *mutationResolver) Register(ctx context.Context, email string, password string, name string) (*model.AuthPayload, error) {
-	// Validate input
+func (r *mutationResolver) Register(ctx context.Context, email string, password string, name string, validateOnly *bool) (model.RegisterResult, error) {
+	checkOnly := validateOnly != nil && *validateOnly
+
+	// Normalize and validate input; the normalized values are what is stored
 	validator := validation.NewValidator()
-	input := model.CreateUserInput{
+	input := validation.NormalizeCreateUserInput(model.CreateUserInput{
 		Email:    email,
 		Password: password,
 		Name:     name,
-	}
+	})
 	
-	if err := validator.ValidateCreateUserInput(input); err != nil {
+	if err := validationResult(validator.CreateUserInputErrors(input), checkOnly); err != nil {
 		return nil, err
 	}
+	if checkOnly {
+		return r.validateRegistration(ctx, input)
+	}
 
 	// Create registration request
 	registerReq := auth.RegisterRequest{
-		Email:    email,
-		Password: password,
-		Name:     name,
+		Email:    input.Email,
+		Password: input.Password,
+		Name:     input.Name,
 	}
 
-	// Get client IP (this would come from HTTP context in real implementation)
-	clientIP := "127.0.0.1" // TODO: Extract from HTTP context
+	// Client IP recorded by ClientInfoMiddleware
+	clientIP := security.ClientIPFromContext(ctx)
 
 	// Register user
 	authResponse, err := r.AuthManager.AuthService.Register(ctx, registerReq, clientIP)
 	if err != nil {
 		// Handle specific registration errors
-		if err.Error() == "user with email "+email+" already exists" {
+		if err.Error() == "user with email "+input.Email+" already exists" {
 			return nil, errors.NewAlreadyExistsError("User with this email")
 		}
 		return nil, errors.NewInternalError("Registration failed")
 	}
 
-	return &model.AuthPayload{
-		Token:     authResponse.Token,
-		User:      authResponse.User,
-		ExpiresAt: authResponse.ExpiresAt,
-	}, nil
+	return authPayload(ctx, authResponse), nil
 }
 
 // RefreshToken is the resolver for the refreshToken field.
-func (r *mutationResolver) RefreshToken(ctx context.Context) (*model.AuthPayload, error) {
-	// Get current user (must be authenticated)
+func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken *string) (*model.AuthPayload, error) {
+	// Exchange a refresh token when one is given or sent as a cookie
+	if refreshToken == nil {
+		if cookie, ok := auth.RefreshTokenFromCookie(ctx); ok {
+			refreshToken = &cookie
+		}
+	}
+	if refreshToken != nil {
+		authResponse, err := r.AuthManager.AuthService.RefreshWithRefreshToken(ctx, *refreshToken)
+		if err != nil {
+			return nil, errors.NewUnauthenticatedError("Invalid or expired refresh token")
+		}
+		return authPayload(ctx, authResponse), nil
+	}
+
+	// Refresh the token the request was authenticated with, as /auth/refresh does
+	if _, err := auth.RequireUser(ctx); err != nil {
+		return nil, errors.NewUnauthenticatedError("Authentication required to refresh token")
+	}
+	token, ok := auth.GetTokenFromContext(ctx)
+	if !ok {
+		return nil, errors.NewUnauthenticatedError("Authentication required to refresh token")
+	}
+
+	authResponse, err := r.AuthManager.AuthService.RefreshToken(ctx, token)
+	if err != nil {
+		return nil, errors.NewUnauthenticatedError("Invalid or expired token")
+	}
+
+	return authPayload(ctx, authResponse), nil
+}
+
+// Logout is the resolver for the logout field.
+func (r *mutationResolver) Logout(ctx context.Context, refreshToken *string) (bool, error) {
+	if refreshToken == nil {
+		if cookie, ok := auth.RefreshTokenFromCookie(ctx); ok {
+			refreshToken = &cookie
+		}
+	}
+	if refreshToken == nil {
+		return false, errors.NewValidationError("A refresh token is required", "refreshToken")
+	}
+
+	if err := r.AuthManager.AuthService.Logout(ctx, *refreshToken); err != nil {
+		return false, errors.NewUnauthenticatedError("Invalid or expired refresh token")
+	}
+
+	auth.ClearAuthCookies(ctx)
+	return true, nil
+}
+
+// RequestEmailChange is the resolver for the requestEmailChange field.
+func (r *mutationResolver) RequestEmailChange(ctx context.Context, newEmail string) (bool, error) {
+	// Require authentication
 	user, err := auth.RequireUser(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("authentication required: %w", err)
+		return false, errors.NewUnauthenticatedError("Authentication required to change email")
 	}
 
-	// Get JWT claims to extract current token
-	_, ok := auth.GetClaimsFromContext(ctx)
-	if !ok {
-		return nil, fmt.Errorf("no token claims found")
+	// Validate input
+	validator := validation.NewValidator()
+	if err := validator.ValidateEmail(newEmail); err != nil {
+		return false, err
+	}
+
+	if err := r.EmailChangeService.RequestEmailChange(ctx, user, newEmail); err != nil {
+		switch err {
+		case auth.ErrEmailTaken:
+			return false, errors.NewAlreadyExistsError("User with this email")
+		case auth.ErrEmailUnchanged:
+			return false, errors.NewValidationError("New email must differ from the current email", "email")
+		}
+		return false, errors.NewInternalError("Email change request failed")
 	}
 
-	// Generate new token (we need to reconstruct the token string)
-	// In a real implementation, we'd store the current token or extract it from headers
-	newToken, expiresAt, err := r.AuthManager.JWTService.GenerateToken(user)
+	return true, nil
+}
+
+// ConfirmEmailChange is the resolver for the confirmEmailChange field.
+func (r *mutationResolver) ConfirmEmailChange(ctx context.Context, token string) (*model.User, error) {
+	if token == "" {
+		return nil, errors.NewValidationError("Token cannot be empty", "token")
+	}
+
+	user, err := r.EmailChangeService.ConfirmEmailChange(ctx, token)
 	if err != nil {
-		return nil, fmt.Errorf("failed to refresh token: %w", err)
+		switch err {
+		case auth.ErrInvalidEmailChangeToken:
+			return nil, errors.NewValidationError("Invalid or expired token", "token")
+		case auth.ErrEmailTaken:
+			return nil, errors.NewAlreadyExistsError("User with this email")
+		}
+		return nil, errors.NewInternalError("Email change confirmation failed")
 	}
 
-	return &model.AuthPayload{
-		Token:     newToken,
-		User:      user,
-		ExpiresAt: expiresAt,
-	}, nil
+	return user, nil
+}
+
+// ResendVerificationEmail is the resolver for the resendVerificationEmail field.
+func (r *mutationResolver) ResendVerificationEmail(ctx context.Context, email *string) (bool, error) {
+	// The signed-in user's own account takes precedence over an email argument
+	if user, err := auth.RequireUser(ctx); err == nil {
+		if err := r.EmailVerificationService.ResendForUser(ctx, user); err != nil {
+			switch err {
+			case auth.ErrAlreadyVerified:
+				return false, errors.NewConflictError("Email address is already verified")
+			case auth.ErrResendLimited:
+				return false, errors.NewRateLimitError("Too many verification emails requested, try again later")
+			}
+			return false, errors.NewInternalError("Verification email could not be sent")
+		}
+		return true, nil
+	}
+
+	if email == nil {
+		return false, errors.NewUnauthenticatedError("Authentication or an email address is required")
+	}
+
+	validator := validation.NewValidator()
+	if err := validator.ValidateEmail(*email); err != nil {
+		return false, err
+	}
+
+	// Report success whether or not the address belongs to an unverified account
+	if err := r.EmailVerificationService.ResendForEmail(ctx, *email); err != nil {
+		if err == auth.ErrResendLimited {
+			return false, errors.NewRateLimitError("Too many verification emails requested, try again later")
+		}
+		return false, errors.NewInternalError("Verification email could not be sent")
+	}
+
+	return true, nil
+}
+
+// VerifyEmail is the resolver for the verifyEmail field.
+func (r *mutationResolver) VerifyEmail(ctx context.Context, token string) (*model.User, error) {
+	if token == "" {
+		return nil, errors.NewValidationError("Token cannot be empty", "token")
+	}
+
+	user, err := r.EmailVerificationService.Verify(ctx, token)
+	if err != nil {
+		if stderrors.Is(err, auth.ErrInvalidVerificationToken) {
+			return nil, errors.NewValidationError("Invalid or expired token", "token")
+		}
+		return nil, errors.NewInternalError("Email verification failed")
+	}
+
+	return user, nil
+}
+
+// SetPassword is the resolver for the setPassword field.
+func (r *mutationResolver) SetPassword(ctx context.Context, token string, password string) (*model.AuthPayload, error) {
+	if token == "" {
+		return nil, errors.NewValidationError("Token cannot be empty", "token")
+	}
+
+	validator := validation.NewValidator()
+	if err := validator.ValidatePassword(password); err != nil {
+		return nil, err
+	}
+
+	// Client IP recorded by ClientInfoMiddleware
+	clientIP := security.ClientIPFromContext(ctx)
+
+	authResponse, err := r.AuthManager.AuthService.SetPasswordWithToken(ctx, token, password, clientIP)
+	if err != nil {
+		if stderrors.Is(err, auth.ErrInvalidPasswordSetupToken) {
+			return nil, errors.NewValidationError("Invalid or expired token", "token")
+		}
+		return nil, errors.NewInternalError("Password could not be set")
+	}
+
+	return authPayload(ctx, authResponse), nil
 }
 
 // CreatePost is the resolver for the createPost field.
-func (r *mutationResolver) CreatePost(ctx context.Context, input model.CreatePostInput) (*model.Post, error) {
+func (r *mutationResolver) CreatePost(ctx context.Context, input model.CreatePostInput, validateOnly *bool) (model.CreatePostResult, error) {
 	// Require authentication
 	user, err := auth.RequireUser(ctx)
 	if err != nil {
 		return nil, errors.NewUnauthenticatedError("Authentication required to create posts")
 	}
 
-	// Validate input
-	validator := validation.NewValidator()
-	if err := validator.ValidateCreatePostInput(input); err != nil {
+	// Normalize and validate input; the normalized values are what is stored
+	input = validation.NormalizeCreatePostInput(input)
+	validator := validation.NewValidator().WithTagLimits(r.tagLimits())
+	checkOnly := validateOnly != nil && *validateOnly
+	if err := validationResult(validator.CreatePostInputErrors(input), checkOnly); err != nil {
 		return nil, err
 	}
 
@@ -130,25 +281,43 @@ func (r *mutationResolver) CreatePost(ctx context.Context, input model.CreatePos
 		published = *input.Published
 	}
 
+	// A validateOnly request gets a preview of the post as it would be saved
+	if checkOnly {
+		return &model.PostPreview{
+			Title:     input.Title,
+			Content:   input.Content,
+			Tags:      input.Tags,
+			Published: published,
+		}, nil
+	}
+
 	// Create post
+	now := r.now()
 	post := &model.Post{
-		ID:        uuid.New(),
+		ID:        r.newID(),
 		Title:     input.Title,
 		Content:   input.Content,
 		AuthorID:  user.ID,
 		Tags:      input.Tags,
 		Published: published,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if published {
+		post.PublishAt = &now
 	}
 
 	// Save to database
 	if err := r.PostRepo.Create(ctx, post); err != nil {
+		if stderrors.Is(err, repository.ErrInvalidTags) {
+			return nil, errors.NewValidationError(err.Error(), "tags")
+		}
 		return nil, errors.WrapDatabaseError(err, "post creation")
 	}
 
-	// Publish real-time event for new post
-	if r.SubManager != nil {
+	// Only published posts reach postAdded subscribers; drafts are announced
+	// when they are published
+	if post.Published && !post.Hidden && r.SubManager != nil {
 		r.SubManager.PublishPostAdded(post)
 	}
 
@@ -156,7 +325,7 @@ func (r *mutationResolver) CreatePost(ctx context.Context, input model.CreatePos
 }
 
 // UpdatePost is the resolver for the updatePost field.
-func (r *mutationResolver) UpdatePost(ctx context.Context, id string, input model.UpdatePostInput) (*model.Post, error) {
+func (r *mutationResolver) UpdatePost(ctx context.Context, id string, input model.UpdatePostInput, validateOnly *bool) (*model.Post, error) {
 	// Require authentication
 	user, err := auth.RequireUser(ctx)
 	if err != nil {
@@ -164,7 +333,7 @@ func (r *mutationResolver) UpdatePost(ctx context.Context, id string, input mode
 	}
 
 	// Parse post ID
-	postID, err := uuid.Parse(id)
+	postID, err := relay.ParseID(id, relay.TypePost)
 	if err != nil {
 		return nil, fmt.Errorf("invalid post ID: %w", err)
 	}
@@ -175,12 +344,21 @@ func (r *mutationResolver) UpdatePost(ctx context.Context, id string, input mode
 		return nil, fmt.Errorf("post not found: %w", err)
 	}
 
-	// Check if user owns the post
-	if post.AuthorID != user.ID {
-		return nil, fmt.Errorf("unauthorized: you can only update your own posts")
+	// Only the author or a moderator may update the post
+	if !canModify(ctx, user, post.AuthorID) {
+		return nil, errors.NewForbiddenError("You can only update your own posts")
 	}
 
-	// Update fields
+	// Update fields, trimmed and validated as they are on creation
+	input = validation.NormalizeUpdatePostInput(input)
+	validator := validation.NewValidator().WithTagLimits(r.tagLimits())
+	checkOnly := validateOnly != nil && *validateOnly
+	if err := validationResult(validator.UpdatePostInputErrors(input), checkOnly); err != nil {
+		return nil, err
+	}
+	if err := r.checkPostEditWindow(ctx, post, input); err != nil {
+		return nil, err
+	}
 	if input.Title != nil {
 		post.Title = *input.Title
 	}
@@ -190,15 +368,33 @@ func (r *mutationResolver) UpdatePost(ctx context.Context, id string, input mode
 	if input.Tags != nil {
 		post.Tags = input.Tags
 	}
+	wasPublished := post.Published
 	if input.Published != nil {
 		post.Published = *input.Published
 	}
-	post.UpdatedAt = time.Now()
+	now := r.now()
+	// The first publish starts the edit window; unpublishing and publishing
+	// again keep it
+	if post.Published && post.PublishAt == nil {
+		post.PublishAt = &now
+	}
+
+	// A validateOnly request gets the post as it would be saved
+	if checkOnly {
+		return post, nil
+	}
+	post.UpdatedAt = now
 
 	// Save to database
 	if err := r.PostRepo.Update(ctx, post); err != nil {
+		if stderrors.Is(err, repository.ErrInvalidTags) {
+			return nil, errors.NewValidationError(err.Error(), "tags")
+		}
 		return nil, fmt.Errorf("failed to update post: %w", err)
 	}
+	if post.Published != wasPublished {
+		r.invalidatePostVisibility(post.ID)
+	}
 
 	// Publish real-time event for updated post
 	if r.SubManager != nil {
@@ -209,47 +405,58 @@ func (r *mutationResolver) UpdatePost(ctx context.Context, id string, input mode
 }
 
 // DeletePost is the resolver for the deletePost field.
-func (r *mutationResolver) DeletePost(ctx context.Context, id string) (bool, error) {
+func (r *mutationResolver) DeletePost(ctx context.Context, id string, dryRun *bool) (*model.DeletePostResult, error) {
 	// Require authentication
 	user, err := auth.RequireUser(ctx)
 	if err != nil {
-		return false, fmt.Errorf("authentication required: %w", err)
+		return nil, fmt.Errorf("authentication required: %w", err)
 	}
 
 	// Parse post ID
-	postID, err := uuid.Parse(id)
+	postID, err := relay.ParseID(id, relay.TypePost)
 	if err != nil {
-		return false, fmt.Errorf("invalid post ID: %w", err)
+		return nil, fmt.Errorf("invalid post ID: %w", err)
 	}
 
 	// Get existing post to check ownership
 	post, err := r.PostRepo.GetByID(ctx, postID)
 	if err != nil {
-		return false, fmt.Errorf("post not found: %w", err)
+		return nil, fmt.Errorf("post not found: %w", err)
 	}
 
-	// Check if user owns the post
-	if post.AuthorID != user.ID {
-		return false, fmt.Errorf("unauthorized: you can only delete your own posts")
+	// Only the author or a moderator may delete the post
+	if !canModify(ctx, user, post.AuthorID) {
+		return nil, errors.NewForbiddenError("You can only delete your own posts")
 	}
 
-	// Delete from database
-	if err := r.PostRepo.Delete(ctx, postID); err != nil {
-		return false, fmt.Errorf("failed to delete post: %w", err)
+	// Delete the post together with its comments; a dry run rolls back
+	preview := dryRun != nil && *dryRun
+	deletedComments, err := r.PostRepo.DeletePostWithComments(ctx, postID, preview)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete post: %w", err)
+	}
+	if !preview {
+		r.invalidatePostVisibility(postID)
 	}
 
-	return true, nil
+	return &model.DeletePostResult{
+		Deleted:      !preview,
+		DryRun:       preview,
+		PostCount:    1,
+		CommentCount: deletedComments,
+	}, nil
 }
 
 // AddComment is the resolver for the addComment field.
-func (r *mutationResolver) AddComment(ctx context.Context, postID string, content string) (*model.Comment, error) {
+func (r *mutationResolver) AddComment(ctx context.Context, postID string, content string, parentID *string) (*model.Comment, error) {
 	// Require authentication
 	user, err := auth.RequireUser(ctx)
 	if err != nil {
 		return nil, errors.NewUnauthenticatedError("Authentication required to add comments")
 	}
 
-	// Validate input
+	// Normalize and validate input; the normalized content is what is stored
+	content = validation.NormalizeText(content)
 	validator := validation.NewValidator()
 	if err := validator.ValidateCommentContent(content); err != nil {
 		return nil, err
@@ -260,28 +467,59 @@ func (r *mutationResolver) AddComment(ctx context.Context, postID string, conten
 		return nil, errors.NewValidationError("Post ID cannot be empty", "postId")
 	}
 	
-	postUUID, err := uuid.Parse(postID)
+	postUUID, err := relay.ParseID(postID, relay.TypePost)
 	if err != nil {
 		return nil, errors.NewInvalidFormatError("Invalid post ID format", "postId")
 	}
 
 	// Verify post exists
-	_, err = r.PostRepo.GetByID(ctx, postUUID)
+	exists, err := r.PostRepo.Exists(ctx, postUUID)
 	if err != nil {
+		return nil, errors.WrapDatabaseError(err, "post lookup")
+	}
+	if !exists {
 		return nil, errors.NewNotFoundError("Post")
 	}
 
+	// Replies sit one level below their parent, within the depth limit
+	var parent *model.Comment
+	var parentUUID *uuid.UUID
+	depth := 0
+	if parentID != nil {
+		parent, err = r.replyParent(ctx, *parentID, postUUID)
+		if err != nil {
+			return nil, err
+		}
+		depth = parent.Depth + 1
+		if err := validator.ValidateReplyDepth(depth, r.maxCommentDepth()); err != nil {
+			return nil, err
+		}
+		parentUUID = &parent.ID
+	}
+
+	// Throttle rapid comments from one user
+	releaseCooldown, err := r.claimCommentCooldown(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create comment
+	now := r.now()
 	comment := &model.Comment{
-		ID:        uuid.New(),
+		ID:        r.newID(),
 		Content:   content,
 		AuthorID:  user.ID,
 		PostID:    postUUID,
-		CreatedAt: time.Now(),
+		ParentID:  parentUUID,
+		Depth:     depth,
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 
 	// Save to database
 	if err := r.CommentRepo.Create(ctx, comment); err != nil {
+		// A comment that was not saved doesn't hold the user back
+		releaseCooldown()
 		return nil, errors.WrapDatabaseError(err, "comment creation")
 	}
 
@@ -289,6 +527,7 @@ func (r *mutationResolver) AddComment(ctx context.Context, postID string, conten
 	if r.SubManager != nil {
 		r.SubManager.PublishCommentAdded(comment)
 	}
+	r.notifyComment(ctx, comment, parent)
 
 	return comment, nil
 }
@@ -302,7 +541,7 @@ func (r *mutationResolver) DeleteComment(ctx context.Context, id string) (bool,
 	}
 
 	// Parse comment ID
-	commentID, err := uuid.Parse(id)
+	commentID, err := relay.ParseID(id, relay.TypeComment)
 	if err != nil {
 		return false, fmt.Errorf("invalid comment ID: %w", err)
 	}
@@ -313,9 +552,9 @@ func (r *mutationResolver) DeleteComment(ctx context.Context, id string) (bool,
 		return false, fmt.Errorf("comment not found: %w", err)
 	}
 
-	// Check if user owns the comment
-	if comment.AuthorID != user.ID {
-		return false, fmt.Errorf("unauthorized: you can only delete your own comments")
+	// Only the author or a moderator may delete the comment
+	if !canModify(ctx, user, comment.AuthorID) {
+		return false, errors.NewForbiddenError("You can only delete your own comments")
 	}
 
 	// Delete from database
@@ -326,6 +565,218 @@ func (r *mutationResolver) DeleteComment(ctx context.Context, id string) (bool,
 	return true, nil
 }
 
+// PublishPost is the resolver for the publishPost field.
+func (r *mutationResolver) PublishPost(ctx context.Context, id string) (*model.Post, error) {
+	return r.setPostPublished(ctx, id, true)
+}
+
+// UnpublishPost is the resolver for the unpublishPost field.
+func (r *mutationResolver) UnpublishPost(ctx context.Context, id string) (*model.Post, error) {
+	return r.setPostPublished(ctx, id, false)
+}
+
+// HidePost is the resolver for the hidePost field.
+func (r *mutationResolver) HidePost(ctx context.Context, id string, reason string) (*model.Post, error) {
+	reason, err := validateHiddenReason(reason)
+	if err != nil {
+		return nil, err
+	}
+	return r.setPostHidden(ctx, id, true, &reason)
+}
+
+// UnhidePost is the resolver for the unhidePost field.
+func (r *mutationResolver) UnhidePost(ctx context.Context, id string) (*model.Post, error) {
+	return r.setPostHidden(ctx, id, false, nil)
+}
+
+// HideComment is the resolver for the hideComment field.
+func (r *mutationResolver) HideComment(ctx context.Context, id string, reason string) (*model.Comment, error) {
+	reason, err := validateHiddenReason(reason)
+	if err != nil {
+		return nil, err
+	}
+	return r.setCommentHidden(ctx, id, true, &reason)
+}
+
+// UnhideComment is the resolver for the unhideComment field.
+func (r *mutationResolver) UnhideComment(ctx context.Context, id string) (*model.Comment, error) {
+	return r.setCommentHidden(ctx, id, false, nil)
+}
+
+// MergeTags is the resolver for the mergeTags field.
+func (r *mutationResolver) MergeTags(ctx context.Context, from string, into string, dryRun *bool) (int, error) {
+	moderator, err := requireModerator(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	from, into, err = validateTagMerge(from, into, r.tagLimits())
+	if err != nil {
+		return 0, err
+	}
+
+	preview := dryRun != nil && *dryRun
+	metadata := map[string]interface{}{"from": from, "into": into, "dry_run": preview}
+	postIDs, err := r.PostRepo.MergeTags(ctx, from, into, preview)
+	if err != nil {
+		r.auditLogger().LogAccessWithMetadata(ctx, moderator, "merge_tags", "tag", from, false, err, metadata)
+		if stderrors.Is(err, repository.ErrInvalidTags) {
+			return 0, errors.NewValidationError(err.Error(), "into")
+		}
+		return 0, errors.WrapDatabaseError(err, "tag merge")
+	}
+	metadata["posts"] = len(postIDs)
+	r.auditLogger().LogAccessWithMetadata(ctx, moderator, "merge_tags", "tag", from, true, nil, metadata)
+
+	return len(postIDs), nil
+}
+
+// RotateJWTKey is the resolver for the rotateJWTKey field.
+func (r *mutationResolver) RotateJWTKey(ctx context.Context) (string, error) {
+	admin, err := requireAdmin(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	keyID, err := r.AuthManager.JWTService.RotateKey(ctx)
+	if err != nil {
+		r.auditLogger().LogAccess(ctx, admin, "rotate_jwt_key", "jwt_key", "", false, err)
+		return "", errors.NewInternalError("Signing key could not be rotated")
+	}
+	r.auditLogger().LogAccess(ctx, admin, "rotate_jwt_key", "jwt_key", keyID, true, nil)
+
+	return keyID, nil
+}
+
+// ClearCache is the resolver for the clearCache field.
+func (r *mutationResolver) ClearCache(ctx context.Context, scope model.CacheScope) (bool, error) {
+	admin, err := requireAdmin(ctx)
+	if err != nil {
+		return false, err
+	}
+	if !scope.IsValid() {
+		return false, errors.NewValidationError("Invalid cache scope", "scope")
+	}
+
+	if err := r.clearCaches(ctx, scope); err != nil {
+		r.auditLogger().LogAccess(ctx, admin, "clear_cache", "cache", scope.String(), false, err)
+		return false, errors.NewInternalError("Cache could not be cleared")
+	}
+	r.auditLogger().LogAccess(ctx, admin, "clear_cache", "cache", scope.String(), true, nil)
+
+	return true, nil
+}
+
+// Impersonate is the resolver for the impersonate field.
+func (r *mutationResolver) Impersonate(ctx context.Context, userID string) (*model.AuthPayload, error) {
+	return r.startImpersonation(ctx, userID)
+}
+
+// StopImpersonation is the resolver for the stopImpersonation field.
+func (r *mutationResolver) StopImpersonation(ctx context.Context) (bool, error) {
+	return r.stopImpersonation(ctx)
+}
+
+// ReportContent is the resolver for the reportContent field.
+func (r *mutationResolver) ReportContent(ctx context.Context, typeArg model.ReportTargetType, id string, reason string) (*model.Report, error) {
+	user, err := auth.RequireUser(ctx)
+	if err != nil {
+		return nil, errors.NewUnauthenticatedError("Authentication required to report content")
+	}
+
+	reason, err = validateHiddenReason(reason)
+	if err != nil {
+		return nil, err
+	}
+
+	targetID, err := r.reportTarget(ctx, user, typeArg, id)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := r.ReportRepo.Create(ctx, &model.Report{
+		ID:         r.newID(),
+		ReporterID: user.ID,
+		TargetType: typeArg,
+		TargetID:   targetID,
+		Reason:     reason,
+		Status:     model.ReportStatusOpen,
+		CreatedAt:  r.now(),
+	})
+	if err != nil {
+		return nil, errors.WrapDatabaseError(err, "report creation")
+	}
+	return report, nil
+}
+
+// ResolveReport is the resolver for the resolveReport field.
+func (r *mutationResolver) ResolveReport(ctx context.Context, id string, action model.ReportAction) (*model.Report, error) {
+	moderator, err := requireModerator(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !action.IsValid() {
+		return nil, errors.NewValidationError("Invalid report action", "action")
+	}
+
+	reportID, err := relay.ParseID(id, relay.TypeReport)
+	if err != nil {
+		return nil, errors.NewInvalidFormatError("Invalid report ID format", "id")
+	}
+
+	report, err := r.ReportRepo.GetByID(ctx, reportID)
+	if err != nil {
+		return nil, errors.NewNotFoundError("Report")
+	}
+	if report.Status != model.ReportStatusOpen {
+		return nil, errors.NewValidationError("Report is already closed", "id")
+	}
+
+	status := model.ReportStatusDismissed
+	hide := action == model.ReportActionHideContent
+	var resource string
+	var authorID uuid.UUID
+	if hide {
+		status = model.ReportStatusResolved
+		if resource, authorID, err = r.reportedContentAuthor(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+
+	metadata := map[string]interface{}{
+		"action":      action.String(),
+		"target_type": report.TargetType.String(),
+		"target_id":   report.TargetID.String(),
+	}
+	resolvedAt := r.now()
+	// The hide and every open report of the content are closed together
+	closed, err := r.ReportRepo.Resolve(ctx, reportID, status, hide, resolvedAt)
+	if err != nil {
+		r.auditLogger().LogAccessWithMetadata(ctx, moderator, "resolve_report", "report", reportID.String(), false, err, metadata)
+		if stderrors.Is(err, repository.ErrReportNotOpen) {
+			return nil, errors.NewValidationError("Report is already closed", "id")
+		}
+		return nil, errors.WrapDatabaseError(err, "report resolution")
+	}
+	if hide {
+		hideMetadata := map[string]interface{}{
+			"author_id": authorID.String(),
+			"reason":    report.Reason,
+			"report_id": reportID.String(),
+		}
+		r.auditLogger().LogAccessWithMetadata(ctx, moderator, moderationAction(resource, true), resource, report.TargetID.String(), true, nil, hideMetadata)
+		if report.TargetType == model.ReportTargetTypePost {
+			r.invalidatePostVisibility(report.TargetID)
+		}
+	}
+	metadata["closed_reports"] = closed
+	r.auditLogger().LogAccessWithMetadata(ctx, moderator, "resolve_report", "report", reportID.String(), true, nil, metadata)
+
+	report.Status = status
+	report.ResolvedAt = &resolvedAt
+	return report, nil
+}
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
@@ -0,0 +1,39 @@
+package resolver
+
+import (
+	"context"
+
+	"backend/internal/graph/model"
+	"github.com/google/uuid"
+)
+
+// notifyComment tells the author of the post a comment was added to, or of
+// the comment it replies to, about it. Commenters aren't notified of their
+// own comments. Notifications are best effort: a post that fails to load
+// sends none.
+func (r *Resolver) notifyComment(ctx context.Context, comment, parent *model.Comment) {
+	if r.SubManager == nil {
+		return
+	}
+
+	notified := map[uuid.UUID]bool{comment.AuthorID: true}
+	notify := func(userID uuid.UUID, kind model.NotificationKind) {
+		if notified[userID] {
+			return
+		}
+		notified[userID] = true
+		r.SubManager.PublishNotification(&model.Notification{
+			UserID:    userID,
+			Kind:      kind,
+			Comment:   comment,
+			CreatedAt: comment.CreatedAt,
+		})
+	}
+
+	if parent != nil {
+		notify(parent.AuthorID, model.NotificationKindReplyToComment)
+	}
+	if post, err := r.PostRepo.GetByID(ctx, comment.PostID); err == nil {
+		notify(post.AuthorID, model.NotificationKindCommentOnPost)
+	}
+}
@@ -0,0 +1,113 @@
+package resolver
+
+import (
+	"context"
+
+	"backend/internal/auth"
+	"backend/internal/graph/errors"
+	"backend/internal/graph/model"
+	"backend/internal/graph/relay"
+	"backend/internal/security"
+	"github.com/google/uuid"
+)
+
+// canModify reports whether the authenticated user may change a resource owned by ownerID.
+// Roles come from the security user in context, which must be the same account.
+func canModify(ctx context.Context, user *model.User, ownerID uuid.UUID) bool {
+	if user.ID == ownerID {
+		return true
+	}
+
+	secUser := security.GetUserFromContext(ctx)
+	if secUser == nil || secUser.ID != user.ID.String() {
+		return false
+	}
+	return security.CanModify(secUser, ownerID.String())
+}
+
+// isModerator reports whether the authenticated user holds the moderate permission
+func isModerator(ctx context.Context) bool {
+	user, ok := auth.GetUserFromContext(ctx)
+	if !ok || user == nil {
+		return false
+	}
+
+	secUser := security.GetUserFromContext(ctx)
+	return secUser != nil && secUser.ID == user.ID.String() && secUser.HasPermission(security.PermissionModerate)
+}
+
+// isAdmin reports whether the authenticated user holds the admin role
+func isAdmin(ctx context.Context) bool {
+	user, ok := auth.GetUserFromContext(ctx)
+	if !ok || user == nil {
+		return false
+	}
+
+	secUser := security.GetUserFromContext(ctx)
+	return secUser != nil && secUser.ID == user.ID.String() && secUser.HasRole(security.RoleAdmin)
+}
+
+// canSeeHidden reports whether hidden content by authorID is visible to the viewer
+func canSeeHidden(ctx context.Context, authorID uuid.UUID) bool {
+	if user, ok := auth.GetUserFromContext(ctx); ok && user != nil && user.ID == authorID {
+		return true
+	}
+	return isModerator(ctx)
+}
+
+// canSeePost reports whether post is visible to the viewer: drafts only to
+// their author and admins, hidden posts only to their author and moderators
+func canSeePost(ctx context.Context, post *model.Post) bool {
+	if !post.Published && !isAdmin(ctx) {
+		user, ok := auth.GetUserFromContext(ctx)
+		if !ok || user == nil || user.ID != post.AuthorID {
+			return false
+		}
+	}
+	return !post.Hidden || canSeeHidden(ctx, post.AuthorID)
+}
+
+// requireModerator returns the acting moderator or a GraphQL error
+func requireModerator(ctx context.Context) (*security.User, error) {
+	if security.GetUserFromContext(ctx) == nil {
+		return nil, errors.NewUnauthenticatedError("Authentication required to moderate content")
+	}
+
+	moderator, err := security.RequirePermission(ctx, security.PermissionModerate)
+	if err != nil {
+		return nil, errors.NewForbiddenError("Moderator permission required")
+	}
+	return moderator, nil
+}
+
+// requireAdmin returns the acting admin or a GraphQL error
+func requireAdmin(ctx context.Context) (*security.User, error) {
+	if security.GetUserFromContext(ctx) == nil {
+		return nil, errors.NewUnauthenticatedError("Authentication required")
+	}
+
+	admin, err := security.RequireRole(ctx, security.RoleAdmin)
+	if err != nil {
+		return nil, errors.NewForbiddenError("Admin role required")
+	}
+	return admin, nil
+}
+
+// replyParent loads the comment a reply answers, which must be visible to
+// the viewer and belong to the same post
+func (r *mutationResolver) replyParent(ctx context.Context, id string, postID uuid.UUID) (*model.Comment, error) {
+	parentID, err := relay.ParseID(id, relay.TypeComment)
+	if err != nil {
+		return nil, errors.NewInvalidFormatError("Invalid parent comment ID format", "parentId")
+	}
+
+	parent, err := r.CommentRepo.GetByID(ctx, parentID)
+	if err != nil || (parent.Hidden && !canSeeHidden(ctx, parent.AuthorID)) {
+		return nil, errors.NewNotFoundError("Comment")
+	}
+	if parent.PostID != postID {
+		return nil, errors.NewValidationError("Parent comment belongs to a different post", "parentId")
+	}
+
+	return parent, nil
+}
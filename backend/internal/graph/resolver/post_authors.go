@@ -0,0 +1,69 @@
+package resolver
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log"
+
+	"backend/internal/dataloader"
+	"backend/internal/graph/errors"
+	"backend/internal/graph/model"
+	"backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// primePostAuthors fetches the distinct authors of a page of posts in one
+// query and primes the request's UserLoader with them, so each post's author
+// field resolves from cache. It does nothing without request DataLoaders; a
+// failed fetch is logged and authors are then loaded as usual.
+func (r *Resolver) primePostAuthors(ctx context.Context, posts []*model.Post) {
+	loaders := dataloader.For(ctx)
+	if loaders == nil || len(posts) == 0 {
+		return
+	}
+
+	seen := make(map[uuid.UUID]bool, len(posts))
+	authorIDs := make([]uuid.UUID, 0, len(posts))
+	for _, post := range posts {
+		if !seen[post.AuthorID] {
+			seen[post.AuthorID] = true
+			authorIDs = append(authorIDs, post.AuthorID)
+		}
+	}
+
+	authors, err := r.UserRepo.GetByIDs(ctx, authorIDs)
+	if err != nil {
+		log.Printf("Failed to prime post authors: %v", err)
+		return
+	}
+
+	loaders.UserLoader.Prime(ctx, authors)
+}
+
+// authorError describes a failed author load for a post or comment. A
+// deleted comment author is reported as not found (posts show deletedAuthor
+// instead); the field is nullable, so either error nulls only that author
+// rather than the whole list it is in.
+func authorError(err error, owner string) error {
+	if stderrors.Is(err, dataloader.ErrUserNotFound) {
+		return errors.NewNotFoundError("Author")
+	}
+	return fmt.Errorf("failed to get %s author: %w", owner, err)
+}
+
+// DeletedAuthorName is the name shown for the author of a post whose author
+// no longer exists
+const DeletedAuthorName = "Deleted user"
+
+// deletedAuthor is the placeholder returned as the author of a post whose
+// author was deleted. It keeps the author's ID but has no email or avatar.
+func deletedAuthor(id uuid.UUID) *model.User {
+	return &model.User{ID: id, Name: DeletedAuthorName}
+}
+
+// authorMissing reports whether err means the author no longer exists, as
+// opposed to a failure to load it
+func authorMissing(err error) bool {
+	return stderrors.Is(err, dataloader.ErrUserNotFound) || stderrors.Is(err, repository.ErrUserNotFound)
+}
@@ -0,0 +1,61 @@
+package resolver
+
+import (
+	"time"
+
+	"backend/internal/graph/errors"
+	"backend/internal/graph/model"
+	"backend/internal/graph/relay"
+	"backend/internal/repository"
+)
+
+// postSortOrDefault resolves the requested post sort, newest first by default
+func postSortOrDefault(sort *model.PostSort) model.PostSort {
+	if sort == nil || !sort.IsValid() {
+		return model.PostSortNewest
+	}
+	return *sort
+}
+
+// postSortKey returns the value a post is ordered by under sort
+func postSortKey(post *model.Post, sort model.PostSort) string {
+	switch sort {
+	case model.PostSortRecentlyUpdated:
+		return post.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	case model.PostSortTitle:
+		return post.Title
+	default:
+		return post.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+}
+
+// postCursor builds the edge cursor for a post listed under sort
+func postCursor(post *model.Post, sort model.PostSort) string {
+	return relay.EncodeCursor(relay.Cursor{
+		Sort: sort.String(),
+		Key:  postSortKey(post, sort),
+		ID:   post.ID,
+	})
+}
+
+// postKeyset decodes an after cursor into the repository keyset for sort.
+// Cursors from another sort are rejected rather than silently misplacing the page.
+func postKeyset(after string, sort model.PostSort) (*repository.PostKeyset, error) {
+	cursor, err := relay.DecodeCursor(after, sort.String())
+	if err != nil {
+		return nil, errors.NewValidationError(err.Error(), "after")
+	}
+
+	keyset := &repository.PostKeyset{ID: cursor.ID}
+	if sort == model.PostSortTitle {
+		keyset.Value = cursor.Key
+		return keyset, nil
+	}
+
+	value, err := time.Parse(time.RFC3339Nano, cursor.Key)
+	if err != nil {
+		return nil, errors.NewValidationError(relay.ErrInvalidCursor.Error(), "after")
+	}
+	keyset.Value = value
+	return keyset, nil
+}
@@ -0,0 +1,96 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/graph/errors"
+	"backend/internal/graph/model"
+)
+
+// PostEditWindow limits how long a published post's content stays editable.
+// Admins may edit posts of any age.
+type PostEditWindow struct {
+	// Period after publishing during which title and content may change
+	Period time.Duration
+	// AllowTagChanges keeps tags editable once the period has passed
+	AllowTagChanges bool
+}
+
+// PostEditWindowFromEnv returns the window set by POST_EDIT_WINDOW_HOURS and
+// POST_EDIT_WINDOW_ALLOW_TAGS, or nil when the hours are unset, invalid or 0,
+// which leaves published posts editable.
+func PostEditWindowFromEnv() *PostEditWindow {
+	hours, err := strconv.Atoi(os.Getenv("POST_EDIT_WINDOW_HOURS"))
+	if err != nil || hours <= 0 {
+		return nil
+	}
+	allowTags, _ := strconv.ParseBool(os.Getenv("POST_EDIT_WINDOW_ALLOW_TAGS"))
+	return &PostEditWindow{Period: time.Duration(hours) * time.Hour, AllowTagChanges: allowTags}
+}
+
+// checkPostEditWindow rejects changes to a post first published longer than
+// the edit window ago, including while it is unpublished again, so readers'
+// copies can't be rewritten by unpublishing and republishing. Posts published
+// before publish times were recorded count from their creation.
+func (r *Resolver) checkPostEditWindow(ctx context.Context, post *model.Post, input model.UpdatePostInput) error {
+	window := r.PostEditWindow
+	if window == nil || window.Period <= 0 || isAdmin(ctx) {
+		return nil
+	}
+	if !post.Published && post.PublishAt == nil {
+		return nil
+	}
+
+	publishedAt := post.CreatedAt
+	if post.PublishAt != nil {
+		publishedAt = *post.PublishAt
+	}
+	if r.now().Sub(publishedAt) <= window.Period {
+		return nil
+	}
+
+	changesContent := (input.Title != nil && *input.Title != post.Title) ||
+		(input.Content != nil && *input.Content != post.Content)
+	changesTags := input.Tags != nil && !window.AllowTagChanges && !sameTags(input.Tags, post.Tags)
+	if !changesContent && !changesTags {
+		return nil
+	}
+
+	return errors.NewForbiddenError(fmt.Sprintf("Published posts can only be edited for %s after publishing", formatEditPeriod(window.Period)))
+}
+
+// sameTags reports whether two tag lists are equal ignoring case and order
+func sameTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[string]int, len(a))
+	for _, tag := range a {
+		counts[strings.ToLower(tag)]++
+	}
+	for _, tag := range b {
+		key := strings.ToLower(tag)
+		if counts[key] == 0 {
+			return false
+		}
+		counts[key]--
+	}
+	return true
+}
+
+// formatEditPeriod describes the edit window in whole hours where it can
+func formatEditPeriod(period time.Duration) string {
+	if period%time.Hour == 0 {
+		hours := int(period / time.Hour)
+		if hours == 1 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	}
+	return period.String()
+}
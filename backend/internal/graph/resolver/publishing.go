@@ -0,0 +1,62 @@
+package resolver
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/auth"
+	"backend/internal/graph/errors"
+	"backend/internal/graph/model"
+	"backend/internal/graph/relay"
+)
+
+// setPostPublished publishes or unpublishes a post for its author or an
+// admin, changing only the published flag and, on its first publish, the
+// publish time. Posts already in the requested state are returned unchanged.
+func (r *mutationResolver) setPostPublished(ctx context.Context, id string, published bool) (*model.Post, error) {
+	user, err := auth.RequireUser(ctx)
+	if err != nil {
+		return nil, errors.NewUnauthenticatedError("Authentication required to publish posts")
+	}
+
+	postID, err := relay.ParseID(id, relay.TypePost)
+	if err != nil {
+		return nil, errors.NewInvalidFormatError("Invalid post ID format", "id")
+	}
+
+	post, err := r.PostRepo.GetByID(ctx, postID)
+	if err != nil {
+		return nil, errors.NewNotFoundError("Post")
+	}
+
+	// Drafts belong to their author; only admins may see and publish others'
+	if post.AuthorID != user.ID && !isAdmin(ctx) {
+		return nil, errors.NewForbiddenError("You can only publish your own posts")
+	}
+
+	if post.Published == published {
+		return post, nil
+	}
+
+	var publishAt *time.Time
+	if published && post.PublishAt == nil {
+		now := r.now()
+		publishAt = &now
+	}
+
+	if err := r.PostRepo.SetPublished(ctx, postID, published, publishAt); err != nil {
+		return nil, errors.WrapDatabaseError(err, "post publishing")
+	}
+	post.Published = published
+	if post.PublishAt == nil {
+		post.PublishAt = publishAt
+	}
+	r.invalidatePostVisibility(postID)
+
+	// Newly published posts reach postAdded subscribers
+	if published && r.SubManager != nil {
+		r.SubManager.PublishPostAdded(post)
+	}
+
+	return post, nil
+}
@@ -9,14 +9,76 @@ import (
 	"fmt"
 
 	"backend/internal/auth"
+	"backend/internal/dataloader"
 	"backend/internal/graph/errors"
 	"backend/internal/graph/generated"
 	"backend/internal/graph/model"
+	"backend/internal/graph/relay"
 	"backend/internal/graph/validation"
 	"backend/internal/repository"
-	"github.com/google/uuid"
+	"backend/internal/security"
 )
 
+// Node is the resolver for the node field.
+func (r *queryResolver) Node(ctx context.Context, id string) (model.Node, error) {
+	typeName, nodeID, err := relay.FromGlobalID(id)
+	if err != nil {
+		return nil, errors.NewInvalidFormatError("Invalid node ID format", "id")
+	}
+
+	loaders := dataloader.For(ctx)
+
+	switch typeName {
+	case relay.TypeUser:
+		var user *model.User
+		if loaders != nil {
+			user, err = loaders.UserLoader.Load(ctx, nodeID)
+		} else {
+			user, err = r.UserRepo.GetByID(ctx, nodeID)
+		}
+		if err != nil {
+			return nil, errors.WrapDatabaseError(err, "user lookup")
+		}
+		return user, nil
+	case relay.TypePost:
+		var post *model.Post
+		if loaders != nil {
+			post, err = loaders.PostLoader.Load(ctx, nodeID)
+		} else {
+			post, err = r.PostRepo.GetByID(ctx, nodeID)
+		}
+		if err != nil {
+			return nil, errors.WrapDatabaseError(err, "post lookup")
+		}
+		if !canSeePost(ctx, post) {
+			return nil, errors.NewNotFoundError("Post")
+		}
+		return post, nil
+	case relay.TypeComment:
+		comment, err := r.CommentRepo.GetByID(ctx, nodeID)
+		if err != nil {
+			return nil, errors.WrapDatabaseError(err, "comment lookup")
+		}
+		if comment.Hidden && !canSeeHidden(ctx, comment.AuthorID) {
+			return nil, errors.NewNotFoundError("Comment")
+		}
+
+		// Comments on drafts and hidden posts are as missing as the post
+		var post *model.Post
+		if loaders != nil {
+			post, err = loaders.PostLoader.Load(ctx, comment.PostID)
+		} else {
+			post, err = r.PostRepo.GetByID(ctx, comment.PostID)
+		}
+		if err != nil || post == nil || !canSeePost(ctx, post) {
+			return nil, errors.NewNotFoundError("Comment")
+		}
+		return comment, nil
+	default:
+		return nil, errors.NewInvalidFormatError("Unknown node type", "id")
+	}
+}
+
 // Me is the resolver for the me field.
 func (r *queryResolver) Me(ctx context.Context) (*model.User, error) {
 	// Require authentication
@@ -27,6 +89,32 @@ func (r *queryResolver) Me(ctx context.Context) (*model.User, error) {
 	return user, nil
 }
 
+// Viewer is the resolver for the viewer field.
+func (r *queryResolver) Viewer(ctx context.Context) (*model.Viewer, error) {
+	user, err := auth.RequireUser(ctx)
+	if err != nil {
+		return nil, errors.NewUnauthenticatedError("Authentication required to access viewer")
+	}
+
+	// Roles come from the security user in context, which must be the same account;
+	// without one the viewer is a regular user
+	secUser := security.GetUserFromContext(ctx)
+	if secUser == nil || secUser.ID != user.ID.String() {
+		secUser = &security.User{ID: user.ID.String(), Role: security.RoleUser}
+	}
+
+	permissions := r.authorization().EffectivePermissions(secUser)
+	viewer := &model.Viewer{
+		User:        user,
+		Role:        string(secUser.Role),
+		Permissions: make([]string, len(permissions)),
+	}
+	for i, perm := range permissions {
+		viewer.Permissions[i] = string(perm)
+	}
+	return viewer, nil
+}
+
 // User is the resolver for the user field.
 func (r *queryResolver) User(ctx context.Context, id string) (*model.User, error) {
 	// Validate and parse UUID
@@ -34,7 +122,7 @@ func (r *queryResolver) User(ctx context.Context, id string) (*model.User, error
 		return nil, errors.NewValidationError("User ID cannot be empty", "id")
 	}
 	
-	userID, err := uuid.Parse(id)
+	userID, err := relay.ParseID(id, relay.TypeUser)
 	if err != nil {
 		return nil, errors.NewInvalidFormatError("Invalid user ID format", "id")
 	}
@@ -49,31 +137,30 @@ func (r *queryResolver) User(ctx context.Context, id string) (*model.User, error
 }
 
 // Posts is the resolver for the posts field.
-func (r *queryResolver) Posts(ctx context.Context, filters *model.PostFilters, pagination *model.PaginationInput) (*model.PostConnection, error) {
-	// Validate pagination input
-	validator := validation.NewValidator()
-	if err := validator.ValidatePaginationInput(pagination); err != nil {
+func (r *queryResolver) Posts(ctx context.Context, filters *model.PostFilters, pagination *model.PaginationInput, sort *model.PostSort) (*model.PostConnection, error) {
+	// Validate pagination input and resolve page size
+	limit, offset, err := r.paginationPolicy().Resolve(pagination)
+	if err != nil {
 		return nil, err
 	}
-	
-	// Set default pagination
-	limit := 20
-	offset := 0
-	
-	if pagination != nil {
-		if pagination.Limit != nil && *pagination.Limit > 0 {
-			limit = *pagination.Limit
-		}
-		if pagination.Page != nil && *pagination.Page > 1 {
-			offset = (*pagination.Page - 1) * limit
+
+	// A cursor continues the listing it came from, so the page is ignored
+	postSort := postSortOrDefault(sort)
+	repoFilters := &repository.PostFilters{Sort: postSort}
+	if pagination != nil && pagination.After != nil {
+		repoFilters.After, err = postKeyset(*pagination.After, postSort)
+		if err != nil {
+			return nil, err
 		}
+		offset = 0
 	}
+	
+	validator := validation.NewValidator()
 
 	// Convert GraphQL filters to repository filters
-	repoFilters := &repository.PostFilters{}
 	if filters != nil {
 		if filters.AuthorID != nil {
-			authorID, err := uuid.Parse(*filters.AuthorID)
+			authorID, err := relay.ParseID(*filters.AuthorID, relay.TypeUser)
 			if err != nil {
 				return nil, errors.NewInvalidFormatError("Invalid author ID format", "authorId")
 			}
@@ -83,7 +170,7 @@ func (r *queryResolver) Posts(ctx context.Context, filters *model.PostFilters, p
 			repoFilters.Published = filters.Published
 		}
 		if len(filters.Tags) > 0 {
-			if err := validator.ValidateTags(filters.Tags); err != nil {
+			if err := validator.ValidateFilterTags(filters.Tags, r.maxFilterTags()); err != nil {
 				return nil, err
 			}
 			repoFilters.Tags = filters.Tags
@@ -96,8 +183,20 @@ func (r *queryResolver) Posts(ctx context.Context, filters *model.PostFilters, p
 		}
 	}
 
-	// Get posts from repository
-	posts, err := r.PostRepo.List(ctx, repoFilters, limit, offset)
+	// Hidden posts are listed for moderators and drafts for admins;
+	// both are always listed for their own author
+	if user, ok := auth.GetUserFromContext(ctx); ok && user != nil {
+		repoFilters.ViewerID = &user.ID
+	}
+	repoFilters.IncludeHidden = isModerator(ctx)
+	repoFilters.IncludeDrafts = isAdmin(ctx)
+
+	// Get posts from repository, with their authors when joining is enabled
+	list := r.PostRepo.List
+	if r.JoinPostAuthors {
+		list = r.PostRepo.ListWithAuthors
+	}
+	posts, err := list(ctx, repoFilters, limit, offset)
 	if err != nil {
 		return nil, errors.WrapDatabaseError(err, "post listing")
 	}
@@ -108,41 +207,30 @@ func (r *queryResolver) Posts(ctx context.Context, filters *model.PostFilters, p
 		return nil, errors.WrapDatabaseError(err, "post counting")
 	}
 
-	// Create edges
-	edges := make([]*model.PostEdge, len(posts))
-	for i, post := range posts {
-		edges[i] = &model.PostEdge{
-			Node:   post,
-			Cursor: fmt.Sprintf("cursor_%s", post.ID.String()),
-		}
+	if !r.JoinPostAuthors {
+		r.primePostAuthors(ctx, posts)
 	}
 
-	// Calculate pagination info
-	hasNextPage := offset+len(posts) < totalCount
-	hasPreviousPage := offset > 0
-	
-	var startCursor, endCursor *string
-	if len(edges) > 0 {
-		startCursor = &edges[0].Cursor
-		endCursor = &edges[len(edges)-1].Cursor
-	}
-
-	return &model.PostConnection{
-		Edges: edges,
-		PageInfo: &model.PageInfo{
-			HasNextPage:     hasNextPage,
-			HasPreviousPage: hasPreviousPage,
-			StartCursor:     startCursor,
-			EndCursor:       endCursor,
-		},
-		TotalCount: totalCount,
-	}, nil
+	// Calculate pagination info; past a cursor the total no longer gives
+	// the position, so a full page is taken to mean more may follow
+	page := model.Page{
+		HasNextPage:     offset+len(posts) < totalCount,
+		HasPreviousPage: offset > 0,
+		TotalCount:      totalCount,
+	}
+	if repoFilters.After != nil {
+		page.HasNextPage = len(posts) == limit
+		page.HasPreviousPage = true
+	}
+
+	cursor := func(post *model.Post) string { return postCursor(post, postSort) }
+	return model.NewConnection(posts, cursor, page), nil
 }
 
 // Post is the resolver for the post field.
 func (r *queryResolver) Post(ctx context.Context, id string) (*model.Post, error) {
-	// Parse UUID
-	postID, err := uuid.Parse(id)
+	// Parse global or raw ID
+	postID, err := relay.ParseID(id, relay.TypePost)
 	if err != nil {
 		return nil, fmt.Errorf("invalid post ID: %w", err)
 	}
@@ -153,9 +241,141 @@ func (r *queryResolver) Post(ctx context.Context, id string) (*model.Post, error
 		return nil, fmt.Errorf("post not found: %w", err)
 	}
 
+	// Drafts and hidden posts look missing to viewers who can't see them
+	if !canSeePost(ctx, post) {
+		return nil, errors.NewNotFoundError("Post")
+	}
+
 	return post, nil
 }
 
+// PostsByIds is the resolver for the postsByIds field.
+func (r *queryResolver) PostsByIds(ctx context.Context, ids []string) ([]*model.Post, error) {
+	postIDs, err := parseBatchIDs(ids, relay.TypePost)
+	if err != nil {
+		return nil, err
+	}
+	if len(postIDs) == 0 {
+		return []*model.Post{}, nil
+	}
+
+	posts, err := r.PostRepo.GetByIDs(ctx, postIDs)
+	if err != nil {
+		return nil, errors.WrapDatabaseError(err, "post lookup")
+	}
+
+	// Posts the viewer cannot see look missing
+	visible := make([]*model.Post, 0, len(posts))
+	for _, post := range posts {
+		if canSeePost(ctx, post) {
+			visible = append(visible, post)
+		}
+	}
+	r.primePostAuthors(ctx, visible)
+
+	return orderByID(postIDs, visible, postID), nil
+}
+
+// UsersByIds is the resolver for the usersByIds field.
+func (r *queryResolver) UsersByIds(ctx context.Context, ids []string) ([]*model.User, error) {
+	if _, err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	userIDs, err := parseBatchIDs(ids, relay.TypeUser)
+	if err != nil {
+		return nil, err
+	}
+	if len(userIDs) == 0 {
+		return []*model.User{}, nil
+	}
+
+	users, err := r.UserRepo.GetByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, errors.WrapDatabaseError(err, "user lookup")
+	}
+
+	// Password hashes never leave the resolver, even for admins
+	redacted := make([]*model.User, len(users))
+	for i, user := range users {
+		copied := *user
+		copied.PasswordHash = ""
+		redacted[i] = &copied
+	}
+
+	return orderByID(userIDs, redacted, userID), nil
+}
+
+// Stats is the resolver for the stats field.
+func (r *queryResolver) Stats(ctx context.Context) (*model.Stats, error) {
+	if _, err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	return r.dashboardStats(ctx)
+}
+
+// Reports is the resolver for the reports field.
+func (r *queryResolver) Reports(ctx context.Context, status *model.ReportStatus, pagination *model.PaginationInput) ([]*model.Report, error) {
+	if _, err := requireModerator(ctx); err != nil {
+		return nil, err
+	}
+
+	filter := model.ReportStatusOpen
+	if status != nil {
+		if !status.IsValid() {
+			return nil, errors.NewValidationError("Invalid report status", "status")
+		}
+		filter = *status
+	}
+
+	limit, offset, err := r.paginationPolicy().Resolve(pagination)
+	if err != nil {
+		return nil, err
+	}
+
+	reports, err := r.ReportRepo.ListByStatus(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, errors.WrapDatabaseError(err, "report lookup")
+	}
+	return reports, nil
+}
+
+// PopularTags is the resolver for the popularTags field.
+func (r *queryResolver) PopularTags(ctx context.Context, limit *int, offset *int) ([]*model.TagCount, error) {
+	maxTags := r.maxPopularTags()
+
+	// Validate and set limit
+	tagLimit := min(validation.DefaultPopularTagsLimit, maxTags)
+	if limit != nil {
+		if *limit < 1 {
+			return nil, errors.NewValidationError("Tag limit must be at least 1", "limit")
+		}
+		if *limit > maxTags {
+			return nil, errors.NewValidationError(fmt.Sprintf("Tag limit cannot exceed %d", maxTags), "limit")
+		}
+		tagLimit = *limit
+	}
+
+	// Validate and set offset
+	tagOffset := 0
+	if offset != nil {
+		if *offset < 0 {
+			return nil, errors.NewValidationError("Tag offset cannot be negative", "offset")
+		}
+		if *offset > validation.MaxPopularTagsOffset {
+			return nil, errors.NewValidationError(fmt.Sprintf("Tag offset cannot exceed %d", validation.MaxPopularTagsOffset), "offset")
+		}
+		tagOffset = *offset
+	}
+
+	tags, err := r.PostRepo.PopularTags(ctx, tagLimit, tagOffset)
+	if err != nil {
+		return nil, errors.WrapDatabaseError(err, "popular tags")
+	}
+
+	return tags, nil
+}
+
 // SearchPosts is the resolver for the searchPosts field.
 func (r *queryResolver) SearchPosts(ctx context.Context, query string, limit *int) ([]*model.Post, error) {
 	// Validate search query
@@ -185,6 +405,39 @@ func (r *queryResolver) SearchPosts(ctx context.Context, query string, limit *in
 	return posts, nil
 }
 
+// SearchUsers is the resolver for the searchUsers field.
+func (r *queryResolver) SearchUsers(ctx context.Context, query string, limit *int) ([]*model.User, error) {
+	if _, err := auth.RequireUser(ctx); err != nil {
+		return nil, errors.NewUnauthenticatedError("Authentication required to search users")
+	}
+
+	// Validate search query
+	validator := validation.NewValidator()
+	if err := validator.ValidateSearchQuery(query); err != nil {
+		return nil, err
+	}
+
+	// Validate and set limit
+	searchLimit := 10
+	if limit != nil {
+		if *limit < 1 {
+			return nil, errors.NewValidationError("Search limit must be at least 1", "limit")
+		}
+		if *limit > 50 {
+			return nil, errors.NewValidationError("Search limit cannot exceed 50", "limit")
+		}
+		searchLimit = *limit
+	}
+
+	// Email addresses are only matched and returned for admins
+	users, err := r.UserRepo.SearchUsers(ctx, validation.NormalizeText(query), isAdmin(ctx), searchLimit)
+	if err != nil {
+		return nil, errors.WrapDatabaseError(err, "user search")
+	}
+
+	return users, nil
+}
+
 // Query returns generated.QueryResolver implementation.
 func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
 
@@ -0,0 +1,66 @@
+package resolver
+
+import (
+	"context"
+
+	"backend/internal/graph/errors"
+	"backend/internal/graph/model"
+	"backend/internal/graph/relay"
+	"github.com/google/uuid"
+)
+
+// reportTarget finds the content a report is about. Content the reporter
+// cannot see is reported as not found, and their own content is rejected.
+func (r *mutationResolver) reportTarget(ctx context.Context, reporter *model.User, targetType model.ReportTargetType, id string) (uuid.UUID, error) {
+	var targetID, authorID uuid.UUID
+	switch targetType {
+	case model.ReportTargetTypePost:
+		postID, err := relay.ParseID(id, relay.TypePost)
+		if err != nil {
+			return uuid.Nil, errors.NewInvalidFormatError("Invalid post ID format", "id")
+		}
+		post, err := r.PostRepo.GetByID(ctx, postID)
+		if err != nil || !canSeePost(ctx, post) {
+			return uuid.Nil, errors.NewNotFoundError("Post")
+		}
+		targetID, authorID = post.ID, post.AuthorID
+	case model.ReportTargetTypeComment:
+		commentID, err := relay.ParseID(id, relay.TypeComment)
+		if err != nil {
+			return uuid.Nil, errors.NewInvalidFormatError("Invalid comment ID format", "id")
+		}
+		comment, err := r.CommentRepo.GetByID(ctx, commentID)
+		if err != nil || (comment.Hidden && !canSeeHidden(ctx, comment.AuthorID)) {
+			return uuid.Nil, errors.NewNotFoundError("Comment")
+		}
+		targetID, authorID = comment.ID, comment.AuthorID
+	default:
+		return uuid.Nil, errors.NewValidationError("Invalid report type", "type")
+	}
+
+	if authorID == reporter.ID {
+		return uuid.Nil, errors.NewValidationError("You cannot report your own content", "id")
+	}
+	return targetID, nil
+}
+
+// reportedContentAuthor returns the resource name and author of the content
+// a report is about, which the hide it leads to is audited with
+func (r *mutationResolver) reportedContentAuthor(ctx context.Context, report *model.Report) (string, uuid.UUID, error) {
+	switch report.TargetType {
+	case model.ReportTargetTypePost:
+		post, err := r.PostRepo.GetByID(ctx, report.TargetID)
+		if err != nil {
+			return "", uuid.Nil, errors.NewNotFoundError("Post")
+		}
+		return "post", post.AuthorID, nil
+	case model.ReportTargetTypeComment:
+		comment, err := r.CommentRepo.GetByID(ctx, report.TargetID)
+		if err != nil {
+			return "", uuid.Nil, errors.NewNotFoundError("Comment")
+		}
+		return "comment", comment.AuthorID, nil
+	default:
+		return "", uuid.Nil, errors.NewInternalError("Report has an unknown target type")
+	}
+}
@@ -1,21 +1,154 @@
 package resolver
 
 import (
+	"time"
+
 	"backend/internal/auth"
+	"backend/internal/clock"
+	"backend/internal/graph/model"
+	"backend/internal/graph/validation"
+	"backend/internal/ids"
 	"backend/internal/repository"
+	"backend/internal/security"
 	"backend/internal/subscription"
+	"github.com/google/uuid"
 )
 
 // Resolver is the root resolver with service dependencies
 type Resolver struct {
 	// Repository dependencies
-	UserRepo    repository.UserRepository
-	PostRepo    repository.PostRepository
-	CommentRepo repository.CommentRepository
+	UserRepo     repository.UserRepository
+	PostRepo     repository.PostRepository
+	CommentRepo  repository.CommentRepository
+	ReactionRepo repository.ReactionRepository
+	ReportRepo   repository.ReportRepository
 	
 	// Authentication service
 	AuthManager *auth.Manager
 	
+	// Confirmed email change flow
+	EmailChangeService *auth.EmailChangeService
+	
+	// Email verification resends
+	EmailVerificationService *auth.EmailVerificationService
+	
 	// Subscription manager for real-time updates
 	SubManager *subscription.Manager
-}
\ No newline at end of file
+	
+	// Audit trail for moderation actions; a printing logger is used when nil
+	AuditLogger *security.AuditLogger
+	
+	// Role to permission mapping reported to the viewer; the default mapping is used when nil
+	Authorization *security.AuthorizationMiddleware
+	
+	// Page size limits for paginated fields; the default policy is used when nil
+	Pagination *validation.PaginationPolicy
+	
+	// Most tags a posts filter may match against; the default is used when zero
+	MaxFilterTags int
+	
+	// Most tags one popularTags page may return; the default is used when zero
+	MaxPopularTags int
+	
+	// Deepest reply nesting allowed below a top-level comment; the default is used when zero
+	MaxCommentDepth int
+	
+	// Tag limits for posts, shared with the post repository; the defaults are used when nil
+	TagLimits *model.PostTagLimits
+	
+	// Time source for created and updated timestamps; UTC wall time is used when nil
+	Clock clock.Clock
+	
+	// Generates the IDs of created posts, comments and reports; ids.New is used when nil
+	IDs ids.Generator
+	
+	// Load authors in the posts listing query itself instead of a second query
+	JoinPostAuthors bool
+	
+	// Per-user wait between comments; comments are not throttled when nil
+	CommentCooldown *CommentCooldown
+	
+	// Reuses the admin dashboard totals briefly; they are counted on every call when nil
+	StatsCache *StatsCache
+	
+	// How long published posts stay editable by their authors; they always are when nil
+	PostEditWindow *PostEditWindow
+	
+	// Lifetime and rate of admin impersonation; default lifetime and no throttling when nil
+	Impersonation *Impersonation
+	
+	// Reuses the posts subscriptions authorize events against briefly; they are loaded for every event when nil
+	SubscriptionVisibility *PostVisibilityCache
+}
+
+// paginationPolicy returns the configured pagination policy or the default one
+func (r *Resolver) paginationPolicy() validation.PaginationPolicy {
+	if r.Pagination == nil {
+		return validation.DefaultPaginationPolicy()
+	}
+	return *r.Pagination
+}
+
+// maxFilterTags returns the configured filter tag limit or the default one
+func (r *Resolver) maxFilterTags() int {
+	if r.MaxFilterTags <= 0 {
+		return validation.DefaultMaxFilterTags
+	}
+	return r.MaxFilterTags
+}
+
+// maxPopularTags returns the configured popularTags page limit or the default one
+func (r *Resolver) maxPopularTags() int {
+	if r.MaxPopularTags <= 0 {
+		return validation.DefaultMaxPopularTags
+	}
+	return r.MaxPopularTags
+}
+
+// maxCommentDepth returns the configured reply depth limit or the default one
+func (r *Resolver) maxCommentDepth() int {
+	if r.MaxCommentDepth <= 0 {
+		return validation.DefaultMaxCommentDepth
+	}
+	return r.MaxCommentDepth
+}
+
+// now returns the current time from the configured clock, in UTC
+func (r *Resolver) now() time.Time {
+	if r.Clock == nil {
+		return clock.Now()
+	}
+	return r.Clock().UTC()
+}
+
+// newID returns an ID from the configured generator or the default one
+func (r *Resolver) newID() uuid.UUID {
+	if r.IDs == nil {
+		return ids.New()
+	}
+	return r.IDs()
+}
+
+// tagLimits returns the configured post tag limits or the default ones
+func (r *Resolver) tagLimits() model.PostTagLimits {
+	if r.TagLimits == nil {
+		return model.DefaultPostTagLimits()
+	}
+	return *r.TagLimits
+}
+
+// authorization returns the configured authorization mapping or the default one
+func (r *Resolver) authorization() *security.AuthorizationMiddleware {
+	if r.Authorization == nil {
+		return security.NewAuthorizationMiddleware()
+	}
+	return r.Authorization
+}
+
+// auditLogger returns the configured audit logger or a printing one
+func (r *Resolver) auditLogger() *security.AuditLogger {
+	if r.AuditLogger == nil {
+		return security.NewAuditLogger()
+	}
+	return r.AuditLogger
+}
@@ -2,15 +2,26 @@ package resolver
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"backend/internal/auth"
+	"backend/internal/dataloader"
+	"backend/internal/graph/errors"
 	"backend/internal/graph/model"
+	"backend/internal/graph/relay"
+	"backend/internal/graph/validation"
 	"backend/internal/repository"
+	"backend/internal/security"
+	"backend/internal/subscription"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // Mock repositories for testing
@@ -31,6 +42,11 @@ func (m *MockUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.User,
 	return args.Get(0).(*model.User), args.Error(1)
 }
 
+func (m *MockUserRepo) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.User, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).([]*model.User), args.Error(1)
+}
+
 func (m *MockUserRepo) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	args := m.Called(ctx, email)
 	if args.Get(0) == nil {
@@ -44,6 +60,11 @@ func (m *MockUserRepo) Update(ctx context.Context, user *model.User) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepo) UpdateEmail(ctx context.Context, id uuid.UUID, email string) error {
+	args := m.Called(ctx, id, email)
+	return args.Error(0)
+}
+
 func (m *MockUserRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -54,6 +75,46 @@ func (m *MockUserRepo) List(ctx context.Context, limit, offset int) ([]*model.Us
 	return args.Get(0).([]*model.User), args.Error(1)
 }
 
+func (m *MockUserRepo) SearchUsers(ctx context.Context, query string, includeEmail bool, limit int) ([]*model.User, error) {
+	args := m.Called(ctx, query, includeEmail, limit)
+	return args.Get(0).([]*model.User), args.Error(1)
+}
+
+func (m *MockUserRepo) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	args := m.Called(ctx, id, passwordHash)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) RecordLogin(ctx context.Context, id uuid.UUID, at time.Time, ip string) error {
+	args := m.Called(ctx, id, at, ip)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) Count(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockUserRepo) CountCreatedSince(ctx context.Context, since time.Time) (int, error) {
+	args := m.Called(ctx, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockUserRepo) ExistsByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).(map[uuid.UUID]bool), args.Error(1)
+}
+
+func (m *MockUserRepo) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	args := m.Called(ctx, email)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepo) ExistsByEmails(ctx context.Context, emails []string) (map[string]bool, error) {
+	args := m.Called(ctx, emails)
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
 type MockPostRepo struct {
 	mock.Mock
 }
@@ -71,11 +132,29 @@ func (m *MockPostRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Post,
 	return args.Get(0).(*model.Post), args.Error(1)
 }
 
+func (m *MockPostRepo) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Post, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).([]*model.Post), args.Error(1)
+}
+
+func (m *MockPostRepo) Exists(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockPostRepo) GetByAuthorID(ctx context.Context, authorID uuid.UUID, limit, offset int) ([]*model.Post, error) {
 	args := m.Called(ctx, authorID, limit, offset)
 	return args.Get(0).([]*model.Post), args.Error(1)
 }
 
+func (m *MockPostRepo) GetPublishedByAuthorIDs(ctx context.Context, authorIDs []uuid.UUID, limit int) (map[uuid.UUID][]*model.Post, error) {
+	args := m.Called(ctx, authorIDs, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID][]*model.Post), args.Error(1)
+}
+
 func (m *MockPostRepo) Update(ctx context.Context, post *model.Post) error {
 	args := m.Called(ctx, post)
 	return args.Error(0)
@@ -86,11 +165,39 @@ func (m *MockPostRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockPostRepo) DeletePostWithComments(ctx context.Context, id uuid.UUID, dryRun bool) (int, error) {
+	args := m.Called(ctx, id, dryRun)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockPostRepo) MergeTags(ctx context.Context, from, into string, dryRun bool) ([]uuid.UUID, error) {
+	args := m.Called(ctx, from, into, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockPostRepo) SetPublished(ctx context.Context, id uuid.UUID, published bool, publishAt *time.Time) error {
+	args := m.Called(ctx, id, published, publishAt)
+	return args.Error(0)
+}
+
+func (m *MockPostRepo) SetHidden(ctx context.Context, id uuid.UUID, hidden bool, reason *string) error {
+	args := m.Called(ctx, id, hidden, reason)
+	return args.Error(0)
+}
+
 func (m *MockPostRepo) List(ctx context.Context, filters *repository.PostFilters, limit, offset int) ([]*model.Post, error) {
 	args := m.Called(ctx, filters, limit, offset)
 	return args.Get(0).([]*model.Post), args.Error(1)
 }
 
+func (m *MockPostRepo) ListWithAuthors(ctx context.Context, filters *repository.PostFilters, limit, offset int) ([]*model.Post, error) {
+	args := m.Called(ctx, filters, limit, offset)
+	return args.Get(0).([]*model.Post), args.Error(1)
+}
+
 func (m *MockPostRepo) Search(ctx context.Context, query string, limit int) ([]*model.Post, error) {
 	args := m.Called(ctx, query, limit)
 	return args.Get(0).([]*model.Post), args.Error(1)
@@ -101,6 +208,16 @@ func (m *MockPostRepo) Count(ctx context.Context, filters *repository.PostFilter
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockPostRepo) CountByPublished(ctx context.Context) (int, int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockPostRepo) PopularTags(ctx context.Context, limit, offset int) ([]*model.TagCount, error) {
+	args := m.Called(ctx, limit, offset)
+	return args.Get(0).([]*model.TagCount), args.Error(1)
+}
+
 type MockCommentRepo struct {
 	mock.Mock
 }
@@ -138,6 +255,57 @@ func (m *MockCommentRepo) Count(ctx context.Context, postID uuid.UUID) (int, err
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockCommentRepo) CountAll(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockCommentRepo) SetHidden(ctx context.Context, id uuid.UUID, hidden bool, reason *string) error {
+	args := m.Called(ctx, id, hidden, reason)
+	return args.Error(0)
+}
+
+func (m *MockCommentRepo) SummaryByPostIDs(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID]*model.CommentSummary, error) {
+	args := m.Called(ctx, postIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]*model.CommentSummary), args.Error(1)
+}
+
+type MockReportRepo struct {
+	mock.Mock
+}
+
+func (m *MockReportRepo) Create(ctx context.Context, report *model.Report) (*model.Report, error) {
+	args := m.Called(ctx, report)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Report), args.Error(1)
+}
+
+func (m *MockReportRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Report, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Report), args.Error(1)
+}
+
+func (m *MockReportRepo) ListByStatus(ctx context.Context, status model.ReportStatus, limit, offset int) ([]*model.Report, error) {
+	args := m.Called(ctx, status, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Report), args.Error(1)
+}
+
+func (m *MockReportRepo) Resolve(ctx context.Context, id uuid.UUID, status model.ReportStatus, hide bool, at time.Time) (int, error) {
+	args := m.Called(ctx, id, status, hide, at)
+	return args.Int(0), args.Error(1)
+}
+
 // Test setup helper
 func setupTestResolver() (*Resolver, *MockUserRepo, *MockPostRepo, *MockCommentRepo) {
 	mockUserRepo := new(MockUserRepo)
@@ -192,7 +360,7 @@ func TestQueryResolver_Me_RequiresAuth(t *testing.T) {
 	// Test without authentication
 	_, err := queryResolver.Me(context.Background())
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "authentication required")
+	assert.Contains(t, err.Error(), "Authentication required")
 }
 
 func TestQueryResolver_Me_WithAuth(t *testing.T) {
@@ -232,7 +400,7 @@ func TestQueryResolver_Posts(t *testing.T) {
 	mockPostRepo.On("List", mock.Anything, mock.AnythingOfType("*repository.PostFilters"), 20, 0).Return(expectedPosts, nil)
 	mockPostRepo.On("Count", mock.Anything, mock.AnythingOfType("*repository.PostFilters")).Return(1, nil)
 
-	result, err := queryResolver.Posts(context.Background(), nil, nil)
+	result, err := queryResolver.Posts(context.Background(), nil, nil, nil)
 
 	assert.NoError(t, err)
 	assert.Len(t, result.Edges, 1)
@@ -242,6 +410,317 @@ func TestQueryResolver_Posts(t *testing.T) {
 	mockPostRepo.AssertExpectations(t)
 }
 
+func TestQueryResolver_Posts_PrimesAuthorsInOneQuery(t *testing.T) {
+	resolver, mockUserRepo, mockPostRepo, _ := setupTestResolver()
+	queryResolver := &queryResolver{resolver}
+	postResolver := &postResolver{resolver}
+
+	alice := &model.User{ID: uuid.New(), Name: "Alice"}
+	bob := &model.User{ID: uuid.New(), Name: "Bob"}
+	posts := []*model.Post{
+		{ID: uuid.New(), AuthorID: alice.ID},
+		{ID: uuid.New(), AuthorID: bob.ID},
+		{ID: uuid.New(), AuthorID: alice.ID},
+	}
+
+	mockPostRepo.On("List", mock.Anything, mock.AnythingOfType("*repository.PostFilters"), 20, 0).Return(posts, nil)
+	mockPostRepo.On("Count", mock.Anything, mock.AnythingOfType("*repository.PostFilters")).Return(3, nil)
+	mockUserRepo.On("GetByIDs", mock.Anything, []uuid.UUID{alice.ID, bob.ID}).Return([]*model.User{alice, bob}, nil).Once()
+
+	loaders := dataloader.NewLoaders(&repository.Manager{User: mockUserRepo, Post: mockPostRepo})
+	ctx := dataloader.WithLoaders(context.Background(), loaders)
+
+	result, err := queryResolver.Posts(ctx, nil, nil, nil)
+	require.NoError(t, err)
+
+	for i, edge := range result.Edges {
+		author, err := postResolver.Author(ctx, edge.Node)
+		require.NoError(t, err)
+		assert.Equal(t, posts[i].AuthorID, author.ID)
+	}
+
+	mockUserRepo.AssertNumberOfCalls(t, "GetByIDs", 1)
+	mockUserRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestQueryResolver_Posts_MissingAuthorResolvesToPlaceholder(t *testing.T) {
+	resolver, mockUserRepo, mockPostRepo, _ := setupTestResolver()
+	queryResolver := &queryResolver{resolver}
+	postResolver := &postResolver{resolver}
+
+	alice := &model.User{ID: uuid.New(), Name: "Alice"}
+	deletedID := uuid.New()
+	posts := []*model.Post{
+		{ID: uuid.New(), AuthorID: alice.ID},
+		{ID: uuid.New(), AuthorID: deletedID},
+		{ID: uuid.New(), AuthorID: alice.ID},
+	}
+
+	mockPostRepo.On("List", mock.Anything, mock.AnythingOfType("*repository.PostFilters"), 20, 0).Return(posts, nil)
+	mockPostRepo.On("Count", mock.Anything, mock.AnythingOfType("*repository.PostFilters")).Return(3, nil)
+	mockUserRepo.On("GetByIDs", mock.Anything, []uuid.UUID{alice.ID, deletedID}).Return([]*model.User{alice}, nil)
+	mockUserRepo.On("GetByIDs", mock.Anything, []uuid.UUID{deletedID}).Return([]*model.User{}, nil)
+
+	loaders := dataloader.NewLoaders(&repository.Manager{User: mockUserRepo, Post: mockPostRepo})
+	ctx := dataloader.WithLoaders(context.Background(), loaders)
+
+	result, err := queryResolver.Posts(ctx, nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, result.Edges, 3)
+
+	for _, i := range []int{0, 2} {
+		author, err := postResolver.Author(ctx, result.Edges[i].Node)
+		require.NoError(t, err)
+		assert.Same(t, alice, author)
+	}
+
+	author, err := postResolver.Author(ctx, result.Edges[1].Node)
+	require.NoError(t, err)
+	assert.Equal(t, &model.User{ID: deletedID, Name: DeletedAuthorName}, author)
+}
+
+func TestCommentResolver_Author_ErrorsPerComment(t *testing.T) {
+	resolver, mockUserRepo, _, _ := setupTestResolver()
+	commentResolver := &commentResolver{resolver}
+
+	alice := &model.User{ID: uuid.New(), Name: "Alice"}
+	comments := []*model.Comment{
+		{ID: uuid.New(), AuthorID: alice.ID},
+		{ID: uuid.New(), AuthorID: uuid.New()},
+	}
+	mockUserRepo.On("GetByIDs", mock.Anything, mock.Anything).Return([]*model.User{alice}, nil).Once()
+
+	ctx := dataloader.WithLoaders(context.Background(), dataloader.NewLoaders(&repository.Manager{User: mockUserRepo}))
+
+	authors := make([]*model.User, len(comments))
+	errs := make([]error, len(comments))
+	var wg sync.WaitGroup
+	for i, comment := range comments {
+		wg.Add(1)
+		go func(i int, comment *model.Comment) {
+			defer wg.Done()
+			authors[i], errs[i] = commentResolver.Author(ctx, comment)
+		}(i, comment)
+	}
+	wg.Wait()
+
+	require.NoError(t, errs[0])
+	assert.Same(t, alice, authors[0])
+	assert.Nil(t, authors[1])
+	gqlErr, ok := errs[1].(*errors.GraphQLError)
+	require.True(t, ok, "expected GraphQLError, got %T", errs[1])
+	assert.Equal(t, errors.ErrorCodeNotFound, gqlErr.Code)
+	mockUserRepo.AssertNumberOfCalls(t, "GetByIDs", 1)
+}
+
+func TestQueryResolver_Posts_JoinsAuthorsWhenEnabled(t *testing.T) {
+	resolver, mockUserRepo, mockPostRepo, _ := setupTestResolver()
+	resolver.JoinPostAuthors = true
+	queryResolver := &queryResolver{resolver}
+	postResolver := &postResolver{resolver}
+
+	alice := &model.User{ID: uuid.New(), Name: "Alice"}
+	bob := &model.User{ID: uuid.New(), Name: "Bob"}
+	orphan := &model.Post{ID: uuid.New(), AuthorID: uuid.New()}
+	posts := []*model.Post{
+		{ID: uuid.New(), AuthorID: alice.ID, Author: alice},
+		{ID: uuid.New(), AuthorID: bob.ID, Author: bob},
+		orphan,
+	}
+
+	mockPostRepo.On("ListWithAuthors", mock.Anything, mock.AnythingOfType("*repository.PostFilters"), 20, 0).Return(posts, nil)
+	mockPostRepo.On("Count", mock.Anything, mock.AnythingOfType("*repository.PostFilters")).Return(3, nil)
+	mockUserRepo.On("GetByID", mock.Anything, orphan.AuthorID).Return(nil, repository.ErrUserNotFound)
+
+	result, err := queryResolver.Posts(context.Background(), nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, result.Edges, 3)
+
+	for i, expected := range []*model.User{alice, bob} {
+		author, err := postResolver.Author(context.Background(), result.Edges[i].Node)
+		require.NoError(t, err)
+		assert.Same(t, expected, author)
+	}
+
+	// A post whose author row is missing falls back to the usual lookup
+	author, err := postResolver.Author(context.Background(), result.Edges[2].Node)
+	require.NoError(t, err)
+	assert.Equal(t, DeletedAuthorName, author.Name)
+
+	mockPostRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockUserRepo.AssertNotCalled(t, "GetByIDs", mock.Anything, mock.Anything)
+	mockUserRepo.AssertNumberOfCalls(t, "GetByID", 1)
+}
+
+func TestQueryResolver_Posts_RejectsOversizedLimit(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	queryResolver := &queryResolver{resolver}
+
+	limit := 1000
+	_, err := queryResolver.Posts(context.Background(), nil, &model.PaginationInput{Limit: &limit}, nil)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Limit cannot exceed 100")
+	mockPostRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestQueryResolver_Posts_UsesConfiguredPageSizes(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	resolver.Pagination = &validation.PaginationPolicy{DefaultLimit: 5, MaxLimit: 10, MaxPage: 100}
+	queryResolver := &queryResolver{resolver}
+
+	mockPostRepo.On("List", mock.Anything, mock.AnythingOfType("*repository.PostFilters"), 5, 0).Return([]*model.Post{}, nil)
+	mockPostRepo.On("Count", mock.Anything, mock.AnythingOfType("*repository.PostFilters")).Return(0, nil)
+
+	_, err := queryResolver.Posts(context.Background(), nil, &model.PaginationInput{}, nil)
+	assert.NoError(t, err)
+	mockPostRepo.AssertExpectations(t)
+
+	limit := 11
+	_, err = queryResolver.Posts(context.Background(), nil, &model.PaginationInput{Limit: &limit}, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Limit cannot exceed 10")
+}
+
+func TestQueryResolver_Posts_TagFilterLimit(t *testing.T) {
+	tags := func(n int) []string {
+		tags := make([]string, n)
+		for i := range tags {
+			tags[i] = fmt.Sprintf("tag-%d", i)
+		}
+		return tags
+	}
+
+	t.Run("too many tags are rejected", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+
+		_, err := (&queryResolver{resolver}).Posts(context.Background(), &model.PostFilters{Tags: tags(21)}, nil, nil)
+
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeValidation, err.(*errors.GraphQLError).Code)
+		assert.Contains(t, err.Error(), "Cannot filter by more than 20 tags")
+		mockPostRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("configured limit applies", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		resolver.MaxFilterTags = 3
+
+		_, err := (&queryResolver{resolver}).Posts(context.Background(), &model.PostFilters{Tags: tags(4)}, nil, nil)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Cannot filter by more than 3 tags")
+		mockPostRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("filter within the limit passes", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		filterTags := tags(15)
+		hasTags := mock.MatchedBy(func(filters *repository.PostFilters) bool {
+			return assert.ObjectsAreEqual(filterTags, filters.Tags)
+		})
+		mockPostRepo.On("List", mock.Anything, hasTags, 20, 0).Return([]*model.Post{}, nil)
+		mockPostRepo.On("Count", mock.Anything, hasTags).Return(0, nil)
+
+		_, err := (&queryResolver{resolver}).Posts(context.Background(), &model.PostFilters{Tags: filterTags}, nil, nil)
+
+		require.NoError(t, err)
+		mockPostRepo.AssertExpectations(t)
+	})
+}
+
+func TestQueryResolver_Posts_ClampsOversizedLimit(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	policy := validation.DefaultPaginationPolicy()
+	policy.ClampLimit = true
+	resolver.Pagination = &policy
+	queryResolver := &queryResolver{resolver}
+
+	mockPostRepo.On("List", mock.Anything, mock.AnythingOfType("*repository.PostFilters"), 100, 0).Return([]*model.Post{}, nil)
+	mockPostRepo.On("Count", mock.Anything, mock.AnythingOfType("*repository.PostFilters")).Return(0, nil)
+
+	limit := 1000
+	_, err := queryResolver.Posts(context.Background(), nil, &model.PaginationInput{Limit: &limit}, nil)
+
+	assert.NoError(t, err)
+	mockPostRepo.AssertExpectations(t)
+}
+
+func TestQueryResolver_Posts_CursorsEncodeSortKey(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	post := &model.Post{
+		ID:        uuid.New(),
+		Title:     "Hello",
+		CreatedAt: created,
+		UpdatedAt: created.Add(time.Hour),
+	}
+
+	tests := []struct {
+		sort model.PostSort
+		key  string
+	}{
+		{model.PostSortNewest, "2024-01-01T00:00:00Z"},
+		{model.PostSortOldest, "2024-01-01T00:00:00Z"},
+		{model.PostSortRecentlyUpdated, "2024-01-01T01:00:00Z"},
+		{model.PostSortTitle, "Hello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort.String(), func(t *testing.T) {
+			resolver, _, mockPostRepo, _ := setupTestResolver()
+			sort := tt.sort
+			matchSort := mock.MatchedBy(func(f *repository.PostFilters) bool { return f.Sort == sort })
+			mockPostRepo.On("List", mock.Anything, matchSort, 20, 0).Return([]*model.Post{post}, nil)
+			mockPostRepo.On("Count", mock.Anything, matchSort).Return(1, nil)
+
+			result, err := (&queryResolver{resolver}).Posts(context.Background(), nil, nil, &sort)
+
+			require.NoError(t, err)
+			require.Len(t, result.Edges, 1)
+			cursor, err := relay.DecodeCursor(result.Edges[0].Cursor, sort.String())
+			require.NoError(t, err)
+			assert.Equal(t, tt.key, cursor.Key)
+			assert.Equal(t, post.ID, cursor.ID)
+			assert.Equal(t, result.Edges[0].Cursor, *result.PageInfo.StartCursor)
+			assert.Equal(t, result.Edges[0].Cursor, *result.PageInfo.EndCursor)
+		})
+	}
+}
+
+func TestQueryResolver_Posts_AfterCursorContinuesListing(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	after := relay.EncodeCursor(relay.Cursor{Sort: "OLDEST", Key: created.Format(time.RFC3339Nano), ID: uuid.New()})
+	sort := model.PostSortOldest
+
+	matchKeyset := mock.MatchedBy(func(f *repository.PostFilters) bool {
+		return f.After != nil && f.After.Value == created
+	})
+	mockPostRepo.On("List", mock.Anything, matchKeyset, 20, 0).Return([]*model.Post{}, nil)
+	mockPostRepo.On("Count", mock.Anything, matchKeyset).Return(5, nil)
+
+	page := 3
+	result, err := (&queryResolver{resolver}).Posts(context.Background(), nil, &model.PaginationInput{Page: &page, After: &after}, &sort)
+
+	require.NoError(t, err)
+	assert.True(t, result.PageInfo.HasPreviousPage)
+	assert.False(t, result.PageInfo.HasNextPage)
+	mockPostRepo.AssertExpectations(t)
+}
+
+func TestQueryResolver_Posts_RejectsCursorFromOtherSort(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	after := relay.EncodeCursor(relay.Cursor{Sort: "NEWEST", Key: "2024-01-01T00:00:00Z", ID: uuid.New()})
+	sort := model.PostSortTitle
+
+	_, err := (&queryResolver{resolver}).Posts(context.Background(), nil, &model.PaginationInput{After: &after}, &sort)
+
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrorCodeValidation, errors.GetErrorCode(err))
+	assert.Contains(t, err.Error(), "created for NEWEST but TITLE was requested")
+	mockPostRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestMutationResolver_CreatePost_RequiresAuth(t *testing.T) {
 	resolver, _, _, _ := setupTestResolver()
 	mutationResolver := &mutationResolver{resolver}
@@ -253,9 +732,9 @@ func TestMutationResolver_CreatePost_RequiresAuth(t *testing.T) {
 	}
 
 	// Test without authentication
-	_, err := mutationResolver.CreatePost(context.Background(), input)
+	_, err := mutationResolver.CreatePost(context.Background(), input, nil)
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "authentication required")
+	assert.Contains(t, err.Error(), "Authentication required")
 }
 
 func TestMutationResolver_CreatePost_WithAuth(t *testing.T) {
@@ -277,52 +756,2794 @@ func TestMutationResolver_CreatePost_WithAuth(t *testing.T) {
 	mockPostRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Post")).Return(nil)
 
 	ctx := createAuthenticatedContext(user)
-	result, err := mutationResolver.CreatePost(ctx, input)
-
-	assert.NoError(t, err)
-	assert.Equal(t, input.Title, result.Title)
-	assert.Equal(t, input.Content, result.Content)
-	assert.Equal(t, user.ID, result.AuthorID)
-	assert.Equal(t, input.Tags, result.Tags)
+	result, err := mutationResolver.CreatePost(ctx, input, nil)
+
+	require.NoError(t, err)
+	require.IsType(t, &model.Post{}, result)
+	assert.NotEqual(t, uuid.Nil, result.(*model.Post).ID)
+	assert.Equal(t, input.Title, result.(*model.Post).Title)
+	assert.Equal(t, input.Content, result.(*model.Post).Content)
+	assert.Equal(t, user.ID, result.(*model.Post).AuthorID)
+	assert.Equal(t, input.Tags, result.(*model.Post).Tags)
 	mockPostRepo.AssertExpectations(t)
 }
 
-func TestMutationResolver_Register(t *testing.T) {
-	resolver, mockUserRepo, _, _ := setupTestResolver()
+func TestMutationResolver_CreatePost_StoresNormalizedInput(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
 	mutationResolver := &mutationResolver{resolver}
+	user := &model.User{ID: uuid.New()}
 
-	mockUserRepo.On("GetByEmail", mock.Anything, "test@example.com").Return(nil, assert.AnError)
-	mockUserRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.User")).Return(nil)
+	var stored *model.Post
+	mockPostRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Post")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*model.Post) }).
+		Return(nil)
 
-	result, err := mutationResolver.Register(context.Background(), "test@example.com", "password123", "Test User")
+	_, err := mutationResolver.CreatePost(createAuthenticatedContext(user), model.CreatePostInput{
+		Title:   "  Title  ",
+		Content: "\n  Body text here\t",
+		Tags:    []string{"test"},
+	}, nil)
 
-	assert.NoError(t, err)
-	assert.NotEmpty(t, result.Token)
-	assert.Equal(t, "test@example.com", result.User.Email)
-	assert.Equal(t, "Test User", result.User.Name)
-	mockUserRepo.AssertExpectations(t)
+	require.NoError(t, err)
+	assert.Equal(t, "Title", stored.Title)
+	assert.Equal(t, "Body text here", stored.Content)
 }
 
-func TestPostResolver_Author(t *testing.T) {
-	resolver, mockUserRepo, _, _ := setupTestResolver()
-	postResolver := &postResolver{resolver}
+func TestMutationResolver_UpdatePost_StoresNormalizedInput(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	mutationResolver := &mutationResolver{resolver}
+	user := &model.User{ID: uuid.New()}
+	post := &model.Post{ID: uuid.New(), Title: "Old", Content: "Old content", AuthorID: user.ID}
 
-	authorID := uuid.New()
-	post := &model.Post{
-		AuthorID: authorID,
-	}
+	mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+	mockPostRepo.On("Update", mock.Anything, post).Return(nil)
 
-	expectedAuthor := &model.User{
-		ID:    authorID,
-		Email: "author@example.com",
-		Name:  "Post Author",
-	}
+	title := "  New title "
+	result, err := mutationResolver.UpdatePost(createAuthenticatedContext(user), post.ID.String(), model.UpdatePostInput{Title: &title}, nil)
 
-	mockUserRepo.On("GetByID", mock.Anything, authorID).Return(expectedAuthor, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "New title", result.Title)
+	assert.Equal(t, "Old content", result.Content)
+	assert.Equal(t, "  New title ", title, "caller's input was modified")
+}
 
-	author, err := postResolver.Author(context.Background(), post)
+func TestMutationResolver_CreatePost_StoresUTCTimestamps(t *testing.T) {
+	serverZone := time.Local
+	time.Local = time.FixedZone("UTC+9", 9*60*60)
+	defer func() { time.Local = serverZone }()
 
-	assert.NoError(t, err)
-	assert.Equal(t, expectedAuthor, author)
-	mockUserRepo.AssertExpectations(t)
-}
\ No newline at end of file
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	mutationResolver := &mutationResolver{resolver}
+	user := &model.User{ID: uuid.New()}
+
+	var stored *model.Post
+	mockPostRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Post")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*model.Post) }).
+		Return(nil)
+
+	_, err := mutationResolver.CreatePost(createAuthenticatedContext(user), model.CreatePostInput{
+		Title:   "Test Post",
+		Content: "Test content",
+		Tags:    []string{"test"},
+	}, nil)
+	require.NoError(t, err)
+
+	require.NotNil(t, stored)
+	assert.Equal(t, time.UTC, stored.CreatedAt.Location())
+	assert.Equal(t, stored.CreatedAt, stored.UpdatedAt)
+}
+
+func TestMutationResolver_CreatePost_UsesConfiguredClock(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	fixed := time.Date(2024, 3, 10, 18, 30, 0, 0, time.FixedZone("UTC+9", 9*60*60))
+	resolver.Clock = func() time.Time { return fixed }
+	mutationResolver := &mutationResolver{resolver}
+
+	mockPostRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Post")).Return(nil)
+
+	result, err := mutationResolver.CreatePost(createAuthenticatedContext(&model.User{ID: uuid.New()}), model.CreatePostInput{
+		Title:   "Test Post",
+		Content: "Test content",
+		Tags:    []string{"test"},
+	}, nil)
+	require.NoError(t, err)
+	post := result.(*model.Post)
+
+	assert.Equal(t, time.Date(2024, 3, 10, 9, 30, 0, 0, time.UTC), post.CreatedAt)
+	assert.Nil(t, post.PublishAt, "drafts have no publish time")
+}
+
+func TestMutationResolver_CreatePost_PublishedSetsPublishAt(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	now := time.Date(2024, 3, 10, 9, 30, 0, 0, time.UTC)
+	resolver.Clock = func() time.Time { return now }
+	mockPostRepo.On("Create", mock.Anything, mock.MatchedBy(func(post *model.Post) bool {
+		return post.PublishAt != nil && post.PublishAt.Equal(now)
+	})).Return(nil)
+
+	published := true
+	result, err := (&mutationResolver{resolver}).CreatePost(createAuthenticatedContext(&model.User{ID: uuid.New()}), model.CreatePostInput{
+		Title:     "Test Post",
+		Content:   "Test content",
+		Published: &published,
+	}, nil)
+
+	require.NoError(t, err)
+	post := result.(*model.Post)
+	require.NotNil(t, post.PublishAt)
+	assert.Equal(t, now, *post.PublishAt)
+	mockPostRepo.AssertExpectations(t)
+}
+
+// memoryCooldownStore is a CooldownStore whose keys expire against a settable clock
+type memoryCooldownStore struct {
+	now     func() time.Time
+	values  map[string][]byte
+	expires map[string]time.Time
+}
+
+func newMemoryCooldownStore(now func() time.Time) *memoryCooldownStore {
+	return &memoryCooldownStore{now: now, values: map[string][]byte{}, expires: map[string]time.Time{}}
+}
+
+func (s *memoryCooldownStore) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	if expires, ok := s.expires[key]; ok && s.now().Before(expires) {
+		return false, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	s.values[key] = data
+	s.expires[key] = s.now().Add(ttl)
+	return true, nil
+}
+
+func (s *memoryCooldownStore) Delete(ctx context.Context, key string) error {
+	delete(s.values, key)
+	delete(s.expires, key)
+	return nil
+}
+
+func (s *memoryCooldownStore) Get(ctx context.Context, key string, dest interface{}) error {
+	if expires, ok := s.expires[key]; !ok || !s.now().Before(expires) {
+		return fmt.Errorf("cache miss")
+	}
+	return json.Unmarshal(s.values[key], dest)
+}
+
+func TestMutationResolver_AddComment_Cooldown(t *testing.T) {
+	resolver, _, mockPostRepo, mockCommentRepo := setupTestResolver()
+	now := time.Date(2024, 3, 10, 9, 30, 0, 0, time.UTC)
+	resolver.Clock = func() time.Time { return now }
+	resolver.CommentCooldown = &CommentCooldown{
+		Store:  newMemoryCooldownStore(func() time.Time { return now }),
+		Period: 30 * time.Second,
+	}
+	mutationResolver := &mutationResolver{resolver}
+
+	postID := uuid.New()
+	mockPostRepo.On("Exists", mock.Anything, postID).Return(true, nil)
+	mockCommentRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Comment")).Return(nil)
+	ctx := createAuthenticatedContext(&model.User{ID: uuid.New()})
+
+	_, err := mutationResolver.AddComment(ctx, postID.String(), "First comment", nil)
+	require.NoError(t, err)
+
+	// A rapid second comment is rejected with the seconds left
+	now = now.Add(10 * time.Second)
+	_, err = mutationResolver.AddComment(ctx, postID.String(), "Second comment", nil)
+	require.Error(t, err)
+	gqlErr, ok := err.(*errors.GraphQLError)
+	require.True(t, ok)
+	assert.Equal(t, errors.ErrorCodeRateLimit, gqlErr.Code)
+	assert.Equal(t, 20, gqlErr.Extensions["retryAfter"])
+
+	// Other users are not held back
+	_, err = mutationResolver.AddComment(createAuthenticatedContext(&model.User{ID: uuid.New()}), postID.String(), "Another user", nil)
+	require.NoError(t, err)
+
+	// Once the cooldown elapses the user may comment again
+	now = now.Add(20 * time.Second)
+	_, err = mutationResolver.AddComment(ctx, postID.String(), "Second comment", nil)
+	require.NoError(t, err)
+
+	mockCommentRepo.AssertNumberOfCalls(t, "Create", 3)
+}
+
+func TestMutationResolver_AddComment_FailedCommentReleasesCooldown(t *testing.T) {
+	resolver, _, mockPostRepo, mockCommentRepo := setupTestResolver()
+	now := time.Date(2024, 3, 10, 9, 30, 0, 0, time.UTC)
+	resolver.Clock = func() time.Time { return now }
+	resolver.CommentCooldown = &CommentCooldown{
+		Store:  newMemoryCooldownStore(func() time.Time { return now }),
+		Period: 30 * time.Second,
+	}
+	mutationResolver := &mutationResolver{resolver}
+
+	postID := uuid.New()
+	mockPostRepo.On("Exists", mock.Anything, postID).Return(true, nil)
+	mockCommentRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Comment")).Return(fmt.Errorf("connection reset")).Once()
+	mockCommentRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Comment")).Return(nil)
+	ctx := createAuthenticatedContext(&model.User{ID: uuid.New()})
+
+	_, err := mutationResolver.AddComment(ctx, postID.String(), "First comment", nil)
+	require.Error(t, err)
+
+	// The retry is not held back by the comment that was never saved
+	_, err = mutationResolver.AddComment(ctx, postID.String(), "First comment", nil)
+	require.NoError(t, err)
+
+	// The saved comment starts the cooldown
+	_, err = mutationResolver.AddComment(ctx, postID.String(), "Second comment", nil)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrorCodeRateLimit, err.(*errors.GraphQLError).Code)
+}
+
+func TestMutationResolver_AddComment_ReplyDepth(t *testing.T) {
+	postID := uuid.New()
+	author := &model.User{ID: uuid.New()}
+	parentAt := func(depth int) *model.Comment {
+		return &model.Comment{ID: uuid.New(), PostID: postID, AuthorID: uuid.New(), Depth: depth}
+	}
+
+	setup := func(t *testing.T, parent *model.Comment) (*mutationResolver, *MockCommentRepo) {
+		resolver, _, mockPostRepo, mockCommentRepo := setupTestResolver()
+		resolver.MaxCommentDepth = 3
+		mockPostRepo.On("Exists", mock.Anything, postID).Return(true, nil)
+		mockCommentRepo.On("GetByID", mock.Anything, parent.ID).Return(parent, nil)
+		mockCommentRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Comment")).Return(nil)
+		return &mutationResolver{resolver}, mockCommentRepo
+	}
+
+	t.Run("reply at the maximum depth succeeds", func(t *testing.T) {
+		parent := parentAt(2)
+		mutationResolver, _ := setup(t, parent)
+		parentID := relay.ToGlobalID(relay.TypeComment, parent.ID)
+
+		reply, err := mutationResolver.AddComment(createAuthenticatedContext(author), postID.String(), "Deepest reply", &parentID)
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, reply.Depth)
+		assert.Equal(t, &parent.ID, reply.ParentID)
+	})
+
+	t.Run("reply beyond the maximum depth is rejected", func(t *testing.T) {
+		parent := parentAt(3)
+		mutationResolver, mockCommentRepo := setup(t, parent)
+		parentID := parent.ID.String()
+
+		_, err := mutationResolver.AddComment(createAuthenticatedContext(author), postID.String(), "Too deep", &parentID)
+
+		require.Error(t, err)
+		gqlErr, ok := err.(*errors.GraphQLError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeValidation, gqlErr.Code)
+		assert.Equal(t, "parentId", gqlErr.Field)
+		mockCommentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("parent on another post is rejected", func(t *testing.T) {
+		parent := parentAt(0)
+		parent.PostID = uuid.New()
+		mutationResolver, mockCommentRepo := setup(t, parent)
+		parentID := parent.ID.String()
+
+		_, err := mutationResolver.AddComment(createAuthenticatedContext(author), postID.String(), "Wrong thread", &parentID)
+
+		require.Error(t, err)
+		mockCommentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("top-level comments have depth 0", func(t *testing.T) {
+		mutationResolver, _ := setup(t, parentAt(0))
+
+		comment, err := mutationResolver.AddComment(createAuthenticatedContext(author), postID.String(), "Top level", nil)
+
+		require.NoError(t, err)
+		assert.Zero(t, comment.Depth)
+		assert.Nil(t, comment.ParentID)
+	})
+}
+
+func TestCommentCooldownFromEnv(t *testing.T) {
+	t.Setenv("COMMENT_COOLDOWN_SECONDS", "")
+	assert.Equal(t, DefaultCommentCooldown, CommentCooldownFromEnv())
+
+	t.Setenv("COMMENT_COOLDOWN_SECONDS", "45")
+	assert.Equal(t, 45*time.Second, CommentCooldownFromEnv())
+
+	t.Setenv("COMMENT_COOLDOWN_SECONDS", "0")
+	assert.Equal(t, time.Duration(0), CommentCooldownFromEnv())
+
+	t.Setenv("COMMENT_COOLDOWN_SECONDS", "soon")
+	assert.Equal(t, DefaultCommentCooldown, CommentCooldownFromEnv())
+}
+
+func TestMutationResolver_Register(t *testing.T) {
+	resolver, mockUserRepo, _, _ := setupTestResolver()
+	mutationResolver := &mutationResolver{resolver}
+
+	mockUserRepo.On("ExistsByEmail", mock.Anything, "test@example.com").Return(false, nil)
+	mockUserRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.User")).Return(nil)
+
+	result, err := mutationResolver.Register(context.Background(), "test@example.com", "password123", "Test User", nil)
+
+	require.NoError(t, err)
+	require.IsType(t, &model.AuthPayload{}, result)
+	payload := result.(*model.AuthPayload)
+	assert.NotEmpty(t, payload.Token)
+	assert.False(t, payload.ExpiresAt.IsZero())
+	assert.Equal(t, "test@example.com", payload.User.Email)
+	assert.Equal(t, "Test User", payload.User.Name)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestMutationResolver_Register_StoresNormalizedInput(t *testing.T) {
+	resolver, mockUserRepo, _, _ := setupTestResolver()
+	mutationResolver := &mutationResolver{resolver}
+
+	var stored *model.User
+	mockUserRepo.On("ExistsByEmail", mock.Anything, "test@example.com").Return(false, nil)
+	mockUserRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.User")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*model.User) }).
+		Return(nil)
+
+	_, err := mutationResolver.Register(context.Background(), "  Test@Example.COM ", "password123", " Test User ", nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "test@example.com", stored.Email)
+	assert.Equal(t, "Test User", stored.Name)
+	mockUserRepo.AssertExpectations(t)
+}
+
+// createTokenContext authenticates ctx as user with token, as the auth middleware does
+func createTokenContext(user *model.User, token string) context.Context {
+	return context.WithValue(createAuthenticatedContext(user), auth.TokenContextKey, token)
+}
+
+func TestMutationResolver_RefreshToken(t *testing.T) {
+	resolver, mockUserRepo, _, _ := setupTestResolver()
+	mutationResolver := &mutationResolver{resolver}
+
+	user := &model.User{ID: uuid.New(), Email: "test@example.com", Name: "Test User"}
+	token, _, err := resolver.AuthManager.JWTService.GenerateToken(user)
+	require.NoError(t, err)
+	mockUserRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+
+	result, err := mutationResolver.RefreshToken(createTokenContext(user, token), nil)
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Token)
+	assert.Equal(t, user, result.User)
+	assert.True(t, result.ExpiresAt.After(time.Now()))
+	claims, err := resolver.AuthManager.JWTService.ValidateToken(result.Token)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+}
+
+func TestMutationResolver_RefreshToken_Rejected(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Email: "test@example.com"}
+	config := auth.NewConfig()
+	expired := auth.NewJWTServiceWithIssuer(config.JWTSecret, -time.Hour, config.JWTIssuer, config.JWTAudiences)
+	expiredToken, _, err := expired.GenerateToken(user)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{name: "anonymous", ctx: context.Background()},
+		{name: "no token", ctx: createAuthenticatedContext(user)},
+		{name: "expired token", ctx: createTokenContext(user, expiredToken)},
+		{name: "invalid token", ctx: createTokenContext(user, "not-a-jwt")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, mockUserRepo, _, _ := setupTestResolver()
+			mutationResolver := &mutationResolver{resolver}
+
+			result, err := mutationResolver.RefreshToken(tt.ctx, nil)
+
+			require.Error(t, err)
+			assert.Nil(t, result)
+			assert.Equal(t, errors.ErrorCodeUnauthenticated, err.(*errors.GraphQLError).Code)
+			mockUserRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestPostResolver_Author(t *testing.T) {
+	resolver, mockUserRepo, _, _ := setupTestResolver()
+	postResolver := &postResolver{resolver}
+
+	authorID := uuid.New()
+	post := &model.Post{
+		AuthorID: authorID,
+	}
+
+	expectedAuthor := &model.User{
+		ID:    authorID,
+		Email: "author@example.com",
+		Name:  "Post Author",
+	}
+
+	mockUserRepo.On("GetByID", mock.Anything, authorID).Return(expectedAuthor, nil)
+
+	author, err := postResolver.Author(context.Background(), post)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedAuthor, author)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestPostResolver_Author_DeletedAuthor(t *testing.T) {
+	resolver, mockUserRepo, _, _ := setupTestResolver()
+	postResolver := &postResolver{resolver}
+
+	deleted := &model.Post{ID: uuid.New(), AuthorID: uuid.New()}
+	unreachable := &model.Post{ID: uuid.New(), AuthorID: uuid.New()}
+	mockUserRepo.On("GetByID", mock.Anything, deleted.AuthorID).Return(nil, repository.ErrUserNotFound)
+	mockUserRepo.On("GetByID", mock.Anything, unreachable.AuthorID).Return(nil, fmt.Errorf("failed to get user: connection refused"))
+
+	author, err := postResolver.Author(context.Background(), deleted)
+	require.NoError(t, err)
+	assert.Equal(t, &model.User{ID: deleted.AuthorID, Name: DeletedAuthorName}, author)
+
+	// Failures other than a missing author are still reported
+	author, err = postResolver.Author(context.Background(), unreachable)
+	assert.Nil(t, author)
+	assert.ErrorContains(t, err, "connection refused")
+}
+func TestQueryResolver_Node_ResolvesEachType(t *testing.T) {
+	resolver, mockUserRepo, mockPostRepo, mockCommentRepo := setupTestResolver()
+	queryResolver := &queryResolver{resolver}
+	ctx := context.Background()
+
+	user := &model.User{ID: uuid.New(), Name: "Test User"}
+	post := &model.Post{ID: uuid.New(), Title: "Test Post", Published: true}
+	comment := &model.Comment{ID: uuid.New(), Content: "Test Comment", PostID: post.ID}
+
+	mockUserRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+	mockCommentRepo.On("GetByID", mock.Anything, comment.ID).Return(comment, nil)
+
+	node, err := queryResolver.Node(ctx, relay.ToGlobalID(relay.TypeUser, user.ID))
+	require.NoError(t, err)
+	assert.Equal(t, user, node)
+
+	node, err = queryResolver.Node(ctx, relay.ToGlobalID(relay.TypePost, post.ID))
+	require.NoError(t, err)
+	assert.Equal(t, post, node)
+
+	node, err = queryResolver.Node(ctx, relay.ToGlobalID(relay.TypeComment, comment.ID))
+	require.NoError(t, err)
+	assert.Equal(t, comment, node)
+
+	mockUserRepo.AssertExpectations(t)
+	mockPostRepo.AssertExpectations(t)
+	mockCommentRepo.AssertExpectations(t)
+}
+
+func TestQueryResolver_Node_DraftVisibility(t *testing.T) {
+	author := &model.User{ID: uuid.New()}
+	draft := &model.Post{ID: uuid.New(), AuthorID: author.ID}
+	comment := &model.Comment{ID: uuid.New(), PostID: draft.ID, AuthorID: author.ID}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		visible bool
+	}{
+		{"anonymous", context.Background(), false},
+		{"other user", createAuthenticatedContext(&model.User{ID: uuid.New()}), false},
+		{"moderator", createModeratorContext(&model.User{ID: uuid.New()}), false},
+		{"author", createAuthenticatedContext(author), true},
+		{"admin", createRoleContext(&model.User{ID: uuid.New()}, security.RoleAdmin), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, _, mockPostRepo, mockCommentRepo := setupTestResolver()
+			mockPostRepo.On("GetByID", mock.Anything, draft.ID).Return(draft, nil)
+			mockCommentRepo.On("GetByID", mock.Anything, comment.ID).Return(comment, nil)
+			queryResolver := &queryResolver{resolver}
+
+			postNode, postErr := queryResolver.Node(tt.ctx, relay.ToGlobalID(relay.TypePost, draft.ID))
+			commentNode, commentErr := queryResolver.Node(tt.ctx, relay.ToGlobalID(relay.TypeComment, comment.ID))
+
+			if tt.visible {
+				require.NoError(t, postErr)
+				assert.Equal(t, draft, postNode)
+				require.NoError(t, commentErr)
+				assert.Equal(t, comment, commentNode)
+			} else {
+				assert.Nil(t, postNode)
+				assert.Equal(t, errors.ErrorCodeNotFound, postErr.(*errors.GraphQLError).Code)
+				assert.Nil(t, commentNode)
+				assert.Equal(t, errors.ErrorCodeNotFound, commentErr.(*errors.GraphQLError).Code)
+			}
+		})
+	}
+}
+
+func TestQueryResolver_Node_RejectsInvalidIDs(t *testing.T) {
+	resolver, _, _, _ := setupTestResolver()
+	queryResolver := &queryResolver{resolver}
+
+	_, err := queryResolver.Node(context.Background(), uuid.New().String())
+	assert.Error(t, err)
+
+	_, err = queryResolver.Node(context.Background(), relay.ToGlobalID("Tag", uuid.New()))
+	assert.Error(t, err)
+}
+
+func TestFieldResolvers_ExposeGlobalIDs(t *testing.T) {
+	resolver, _, _, _ := setupTestResolver()
+	ctx := context.Background()
+	id := uuid.New()
+
+	userID, err := resolver.User().ID(ctx, &model.User{ID: id})
+	require.NoError(t, err)
+	assert.Equal(t, relay.ToGlobalID(relay.TypeUser, id), userID)
+
+	postID, err := resolver.Post().ID(ctx, &model.Post{ID: id})
+	require.NoError(t, err)
+	assert.Equal(t, relay.ToGlobalID(relay.TypePost, id), postID)
+
+	commentID, err := resolver.Comment().ID(ctx, &model.Comment{ID: id})
+	require.NoError(t, err)
+	assert.Equal(t, relay.ToGlobalID(relay.TypeComment, id), commentID)
+}
+
+func TestQueryResolver_Post_AcceptsGlobalID(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	queryResolver := &queryResolver{resolver}
+
+	post := &model.Post{ID: uuid.New(), Title: "Test Post", Published: true}
+	mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+
+	result, err := queryResolver.Post(context.Background(), relay.ToGlobalID(relay.TypePost, post.ID))
+
+	require.NoError(t, err)
+	assert.Equal(t, post, result)
+}
+
+// createRoleContext authenticates user and attaches a security identity with role
+func createRoleContext(user *model.User, role security.Role) context.Context {
+	ctx := createAuthenticatedContext(user)
+	return security.WithUser(ctx, &security.User{ID: user.ID.String(), Role: role, IsActive: true, IsVerified: true})
+}
+
+func assertForbidden(t *testing.T, err error) {
+	t.Helper()
+	require.Error(t, err)
+	gqlErr, ok := err.(*errors.GraphQLError)
+	require.True(t, ok, "expected GraphQLError, got %T", err)
+	assert.Equal(t, errors.ErrorCodeForbidden, gqlErr.Code)
+}
+
+func TestMutationResolver_UpdatePost_Ownership(t *testing.T) {
+	owner := &model.User{ID: uuid.New()}
+	other := &model.User{ID: uuid.New()}
+	title := "Updated title"
+
+	newPost := func() *model.Post {
+		return &model.Post{ID: uuid.New(), Title: "Original", AuthorID: owner.ID}
+	}
+
+	t.Run("non-owner is forbidden", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		post := newPost()
+		mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+
+		_, err := (&mutationResolver{resolver}).UpdatePost(createAuthenticatedContext(other), post.ID.String(), model.UpdatePostInput{Title: &title}, nil)
+
+		assertForbidden(t, err)
+		mockPostRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("owner succeeds", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		post := newPost()
+		mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+		mockPostRepo.On("Update", mock.Anything, post).Return(nil)
+
+		updated, err := (&mutationResolver{resolver}).UpdatePost(createAuthenticatedContext(owner), post.ID.String(), model.UpdatePostInput{Title: &title}, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, title, updated.Title)
+	})
+
+	t.Run("admin succeeds", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		post := newPost()
+		mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+		mockPostRepo.On("Update", mock.Anything, post).Return(nil)
+
+		_, err := (&mutationResolver{resolver}).UpdatePost(createRoleContext(other, security.RoleAdmin), post.ID.String(), model.UpdatePostInput{Title: &title}, nil)
+
+		require.NoError(t, err)
+		mockPostRepo.AssertExpectations(t)
+	})
+}
+
+func TestMutationResolver_UpdatePost_EditWindow(t *testing.T) {
+	owner := &model.User{ID: uuid.New()}
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	content := "Rewritten body text"
+
+	setup := func(publishedAgo time.Duration, allowTags bool) (*Resolver, *MockPostRepo, *model.Post) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		resolver.Clock = func() time.Time { return now }
+		resolver.PostEditWindow = &PostEditWindow{Period: 24 * time.Hour, AllowTagChanges: allowTags}
+		publishAt := now.Add(-publishedAgo)
+		post := &model.Post{
+			ID: uuid.New(), Title: "Original", Content: "Original body", AuthorID: owner.ID,
+			Tags: []string{"go"}, Published: true, CreatedAt: publishAt.Add(-time.Hour), PublishAt: &publishAt,
+		}
+		mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+		return resolver, mockPostRepo, post
+	}
+
+	t.Run("edit within the window succeeds", func(t *testing.T) {
+		resolver, mockPostRepo, post := setup(23*time.Hour, false)
+		mockPostRepo.On("Update", mock.Anything, post).Return(nil)
+
+		updated, err := (&mutationResolver{resolver}).UpdatePost(createAuthenticatedContext(owner), post.ID.String(), model.UpdatePostInput{Content: &content}, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, content, updated.Content)
+	})
+
+	t.Run("edit beyond the window is rejected", func(t *testing.T) {
+		resolver, mockPostRepo, post := setup(25*time.Hour, false)
+
+		_, err := (&mutationResolver{resolver}).UpdatePost(createAuthenticatedContext(owner), post.ID.String(), model.UpdatePostInput{Content: &content}, nil)
+
+		assertForbidden(t, err)
+		assert.Contains(t, err.Error(), "24 hours after publishing")
+		mockPostRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("admin may edit beyond the window", func(t *testing.T) {
+		resolver, mockPostRepo, post := setup(25*time.Hour, false)
+		mockPostRepo.On("Update", mock.Anything, post).Return(nil)
+
+		_, err := (&mutationResolver{resolver}).UpdatePost(createRoleContext(owner, security.RoleAdmin), post.ID.String(), model.UpdatePostInput{Content: &content}, nil)
+
+		require.NoError(t, err)
+		mockPostRepo.AssertExpectations(t)
+	})
+
+	t.Run("tag changes follow the config", func(t *testing.T) {
+		input := model.UpdatePostInput{Tags: []string{"go", "graphql"}}
+
+		resolver, mockPostRepo, post := setup(25*time.Hour, false)
+		_, err := (&mutationResolver{resolver}).UpdatePost(createAuthenticatedContext(owner), post.ID.String(), input, nil)
+		assertForbidden(t, err)
+		mockPostRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+
+		resolver, mockPostRepo, post = setup(25*time.Hour, true)
+		mockPostRepo.On("Update", mock.Anything, post).Return(nil)
+		updated, err := (&mutationResolver{resolver}).UpdatePost(createAuthenticatedContext(owner), post.ID.String(), input, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go", "graphql"}, updated.Tags)
+	})
+
+	t.Run("unchanged content is allowed beyond the window", func(t *testing.T) {
+		resolver, mockPostRepo, post := setup(25*time.Hour, false)
+		mockPostRepo.On("Update", mock.Anything, post).Return(nil)
+		title := " Original "
+
+		_, err := (&mutationResolver{resolver}).UpdatePost(createAuthenticatedContext(owner), post.ID.String(), model.UpdatePostInput{Title: &title}, nil)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("unpublishing and republishing keeps the window", func(t *testing.T) {
+		resolver, mockPostRepo, post := setup(25*time.Hour, false)
+		mockPostRepo.On("Update", mock.Anything, post).Return(nil)
+		mockPostRepo.On("SetPublished", mock.Anything, post.ID, mock.Anything, (*time.Time)(nil)).Return(nil)
+		firstPublished := *post.PublishAt
+		unpublished, published := false, true
+		ctx := createAuthenticatedContext(owner)
+
+		draft, err := (&mutationResolver{resolver}).UpdatePost(ctx, post.ID.String(), model.UpdatePostInput{Published: &unpublished}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, firstPublished, *draft.PublishAt)
+
+		_, err = (&mutationResolver{resolver}).UpdatePost(ctx, post.ID.String(), model.UpdatePostInput{Content: &content}, nil)
+		assertForbidden(t, err)
+
+		_, err = (&mutationResolver{resolver}).UpdatePost(ctx, post.ID.String(), model.UpdatePostInput{Content: &content, Published: &published}, nil)
+		assertForbidden(t, err)
+
+		republished, err := (&mutationResolver{resolver}).PublishPost(ctx, post.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, firstPublished, *republished.PublishAt)
+
+		_, err = (&mutationResolver{resolver}).UpdatePost(ctx, post.ID.String(), model.UpdatePostInput{Content: &content}, nil)
+		assertForbidden(t, err)
+		assert.Equal(t, "Original body", post.Content)
+	})
+}
+
+func TestMutationResolver_PublishPost_Ownership(t *testing.T) {
+	owner := &model.User{ID: uuid.New()}
+	other := &model.User{ID: uuid.New()}
+
+	newDraft := func() *model.Post {
+		return &model.Post{ID: uuid.New(), Title: "Draft", AuthorID: owner.ID}
+	}
+
+	t.Run("non-owner is forbidden", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		post := newDraft()
+		mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+
+		_, err := (&mutationResolver{resolver}).PublishPost(createRoleContext(other, security.RoleUser), post.ID.String())
+
+		assertForbidden(t, err)
+		mockPostRepo.AssertNotCalled(t, "SetPublished", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("moderator is forbidden", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		post := newDraft()
+		mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+
+		_, err := (&mutationResolver{resolver}).UnpublishPost(createModeratorContext(other), post.ID.String())
+
+		assertForbidden(t, err)
+	})
+
+	t.Run("admin succeeds", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		post := newDraft()
+		mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+		mockPostRepo.On("SetPublished", mock.Anything, post.ID, true, mock.AnythingOfType("*time.Time")).Return(nil)
+
+		published, err := (&mutationResolver{resolver}).PublishPost(createRoleContext(other, security.RoleAdmin), post.ID.String())
+
+		require.NoError(t, err)
+		assert.True(t, published.Published)
+		mockPostRepo.AssertExpectations(t)
+	})
+}
+
+func TestMutationResolver_PublishPost_FlipsOnlyTheFlag(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	now := time.Date(2024, 3, 10, 9, 30, 0, 0, time.UTC)
+	resolver.Clock = func() time.Time { return now }
+	author := &model.User{ID: uuid.New()}
+	updatedAt := now.Add(-time.Hour)
+	post := &model.Post{ID: uuid.New(), Title: "Draft", Content: "Body", Tags: []string{"go"}, AuthorID: author.ID, UpdatedAt: updatedAt}
+	mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+	mockPostRepo.On("SetPublished", mock.Anything, post.ID, true, &now).Return(nil).Once()
+	mockPostRepo.On("SetPublished", mock.Anything, post.ID, false, (*time.Time)(nil)).Return(nil).Once()
+	mockPostRepo.On("SetPublished", mock.Anything, post.ID, true, (*time.Time)(nil)).Return(nil).Once()
+	ctx := createAuthenticatedContext(author)
+
+	published, err := (&mutationResolver{resolver}).PublishPost(ctx, post.ID.String())
+	require.NoError(t, err)
+	assert.True(t, published.Published)
+	assert.Equal(t, "Draft", published.Title)
+	assert.Equal(t, "Body", published.Content)
+	assert.Equal(t, []string{"go"}, published.Tags)
+	assert.Equal(t, updatedAt, published.UpdatedAt)
+	assert.Equal(t, &now, published.PublishAt)
+
+	// Publishing again changes nothing
+	_, err = (&mutationResolver{resolver}).PublishPost(ctx, post.ID.String())
+	require.NoError(t, err)
+
+	unpublished, err := (&mutationResolver{resolver}).UnpublishPost(ctx, post.ID.String())
+	require.NoError(t, err)
+	assert.False(t, unpublished.Published)
+	assert.Equal(t, &now, unpublished.PublishAt, "unpublishing keeps the first publish time")
+
+	// Publishing again keeps it too
+	resolver.Clock = func() time.Time { return now.Add(time.Hour) }
+	republished, err := (&mutationResolver{resolver}).PublishPost(ctx, post.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, &now, republished.PublishAt)
+
+	mockPostRepo.AssertExpectations(t)
+	mockPostRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestMutationResolver_PublishPost_FiresPostAdded(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	resolver.SubManager = subscription.NewManager()
+	events := resolver.SubManager.Subscribe(context.Background(), "test", subscription.PostAddedEvent, nil)
+	author := &model.User{ID: uuid.New()}
+	post := &model.Post{ID: uuid.New(), Title: "Draft", AuthorID: author.ID}
+	mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+	mockPostRepo.On("SetPublished", mock.Anything, post.ID, mock.Anything, mock.Anything).Return(nil)
+	ctx := createAuthenticatedContext(author)
+
+	_, err := (&mutationResolver{resolver}).PublishPost(ctx, post.ID.String())
+	require.NoError(t, err)
+
+	select {
+	case event := <-events:
+		assert.Equal(t, post.ID, event.Post.ID)
+		assert.True(t, event.Post.Published)
+	default:
+		t.Fatal("expected a postAdded event on publish")
+	}
+
+	// Unpublishing announces nothing
+	_, err = (&mutationResolver{resolver}).UnpublishPost(ctx, post.ID.String())
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestMutationResolver_CreatePost_OnlyAnnouncesPublishedPosts(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	resolver.SubManager = subscription.NewManager()
+	events := resolver.SubManager.Subscribe(context.Background(), "test", subscription.PostAddedEvent, nil)
+	mockPostRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Post")).Return(nil)
+	ctx := createAuthenticatedContext(&model.User{ID: uuid.New()})
+	input := model.CreatePostInput{Title: "Test Post", Content: "Test content", Tags: []string{"test"}}
+
+	_, err := (&mutationResolver{resolver}).CreatePost(ctx, input, nil)
+	require.NoError(t, err)
+	assert.Empty(t, events, "drafts are not announced")
+
+	published := true
+	input.Published = &published
+	result, err := (&mutationResolver{resolver}).CreatePost(ctx, input, nil)
+	require.NoError(t, err)
+
+	select {
+	case event := <-events:
+		assert.Equal(t, result.(*model.Post).ID, event.Post.ID)
+	default:
+		t.Fatal("expected a postAdded event for a published post")
+	}
+}
+
+func TestMutationResolver_DeletePost_Ownership(t *testing.T) {
+	owner := &model.User{ID: uuid.New()}
+	other := &model.User{ID: uuid.New()}
+	post := &model.Post{ID: uuid.New(), AuthorID: owner.ID}
+
+	t.Run("non-owner is forbidden", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+
+		_, err := (&mutationResolver{resolver}).DeletePost(createRoleContext(other, security.RoleUser), post.ID.String(), nil)
+
+		assertForbidden(t, err)
+		mockPostRepo.AssertNotCalled(t, "DeletePostWithComments", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("owner succeeds", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+		mockPostRepo.On("DeletePostWithComments", mock.Anything, post.ID, false).Return(2, nil)
+
+		result, err := (&mutationResolver{resolver}).DeletePost(createAuthenticatedContext(owner), post.ID.String(), nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, &model.DeletePostResult{Deleted: true, PostCount: 1, CommentCount: 2}, result)
+	})
+
+	t.Run("moderator succeeds", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+		mockPostRepo.On("DeletePostWithComments", mock.Anything, post.ID, false).Return(0, nil)
+
+		result, err := (&mutationResolver{resolver}).DeletePost(createRoleContext(other, security.RoleModerator), post.ID.String(), nil)
+
+		require.NoError(t, err)
+		assert.True(t, result.Deleted)
+	})
+
+	t.Run("dry run reports without deleting", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		resolver.SubscriptionVisibility = &PostVisibilityCache{TTL: DefaultSubscriptionVisibilityTTL}
+		mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+		mockPostRepo.On("DeletePostWithComments", mock.Anything, post.ID, true).Return(3, nil)
+		_, err := resolver.subscriptionPost(context.Background(), post.ID)
+		require.NoError(t, err)
+
+		dryRun := true
+		result, err := (&mutationResolver{resolver}).DeletePost(createAuthenticatedContext(owner), post.ID.String(), &dryRun)
+
+		require.NoError(t, err)
+		assert.Equal(t, &model.DeletePostResult{DryRun: true, PostCount: 1, CommentCount: 3}, result)
+		assert.Contains(t, resolver.SubscriptionVisibility.entries, post.ID, "a dry run invalidated the post")
+	})
+
+	t.Run("role for a different account is ignored", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+
+		ctx := security.WithUser(createAuthenticatedContext(other), &security.User{ID: uuid.NewString(), Role: security.RoleAdmin})
+		_, err := (&mutationResolver{resolver}).DeletePost(ctx, post.ID.String(), nil)
+
+		assertForbidden(t, err)
+	})
+}
+
+func TestMutationResolver_DeleteComment_Ownership(t *testing.T) {
+	owner := &model.User{ID: uuid.New()}
+	other := &model.User{ID: uuid.New()}
+	comment := &model.Comment{ID: uuid.New(), AuthorID: owner.ID, PostID: uuid.New()}
+
+	t.Run("non-owner is forbidden", func(t *testing.T) {
+		resolver, _, _, mockCommentRepo := setupTestResolver()
+		mockCommentRepo.On("GetByID", mock.Anything, comment.ID).Return(comment, nil)
+
+		_, err := (&mutationResolver{resolver}).DeleteComment(createAuthenticatedContext(other), comment.ID.String())
+
+		assertForbidden(t, err)
+		mockCommentRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("owner succeeds", func(t *testing.T) {
+		resolver, _, _, mockCommentRepo := setupTestResolver()
+		mockCommentRepo.On("GetByID", mock.Anything, comment.ID).Return(comment, nil)
+		mockCommentRepo.On("Delete", mock.Anything, comment.ID).Return(nil)
+
+		ok, err := (&mutationResolver{resolver}).DeleteComment(createAuthenticatedContext(owner), comment.ID.String())
+
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("admin succeeds", func(t *testing.T) {
+		resolver, _, _, mockCommentRepo := setupTestResolver()
+		mockCommentRepo.On("GetByID", mock.Anything, comment.ID).Return(comment, nil)
+		mockCommentRepo.On("Delete", mock.Anything, comment.ID).Return(nil)
+
+		ok, err := (&mutationResolver{resolver}).DeleteComment(createRoleContext(other, security.RoleAdmin), comment.ID.String())
+
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+}
+
+// createModeratorContext authenticates user with the moderate permission
+func createModeratorContext(user *model.User) context.Context {
+	ctx := createAuthenticatedContext(user)
+	return security.WithUser(ctx, &security.User{
+		ID:          user.ID.String(),
+		Role:        security.RoleModerator,
+		Permissions: []string{string(security.PermissionModerate)},
+		IsActive:    true,
+		IsVerified:  true,
+	})
+}
+
+func TestMutationResolver_HidePost_RequiresModeratePermission(t *testing.T) {
+	author := &model.User{ID: uuid.New()}
+	post := &model.Post{ID: uuid.New(), AuthorID: author.ID}
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+
+		_, err := (&mutationResolver{resolver}).HidePost(context.Background(), post.ID.String(), "spam")
+
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeUnauthenticated, err.(*errors.GraphQLError).Code)
+		mockPostRepo.AssertNotCalled(t, "SetHidden", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("regular user", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+
+		_, err := (&mutationResolver{resolver}).HidePost(createRoleContext(author, security.RoleUser), post.ID.String(), "spam")
+
+		assertForbidden(t, err)
+		mockPostRepo.AssertNotCalled(t, "SetHidden", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestMutationResolver_HideAndUnhidePost_Audited(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	var entries []security.AuditLog
+	resolver.AuditLogger = security.NewAuditLoggerWithSink(func(entry security.AuditLog) {
+		entries = append(entries, entry)
+	})
+	moderator := &model.User{ID: uuid.New()}
+	post := &model.Post{ID: uuid.New(), AuthorID: uuid.New()}
+	ctx := createModeratorContext(moderator)
+
+	mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+	mockPostRepo.On("SetHidden", mock.Anything, post.ID, true, mock.AnythingOfType("*string")).Return(nil)
+	mockPostRepo.On("SetHidden", mock.Anything, post.ID, false, (*string)(nil)).Return(nil)
+
+	hidden, err := (&mutationResolver{resolver}).HidePost(ctx, post.ID.String(), "  spam  ")
+	require.NoError(t, err)
+	assert.True(t, hidden.Hidden)
+	require.NotNil(t, hidden.HiddenReason)
+	assert.Equal(t, "spam", *hidden.HiddenReason)
+
+	shown, err := (&mutationResolver{resolver}).UnhidePost(ctx, post.ID.String())
+	require.NoError(t, err)
+	assert.False(t, shown.Hidden)
+	assert.Nil(t, shown.HiddenReason)
+
+	require.Len(t, entries, 2)
+	assert.Equal(t, "hide_post", entries[0].Action)
+	assert.Equal(t, moderator.ID.String(), entries[0].UserID)
+	assert.Equal(t, post.ID.String(), entries[0].ResourceID)
+	assert.Equal(t, "spam", entries[0].Metadata["reason"])
+	assert.True(t, entries[0].Success)
+	assert.Equal(t, "unhide_post", entries[1].Action)
+	mockPostRepo.AssertExpectations(t)
+}
+
+func TestMutationResolver_HideComment_Audited(t *testing.T) {
+	resolver, _, _, mockCommentRepo := setupTestResolver()
+	var entries []security.AuditLog
+	resolver.AuditLogger = security.NewAuditLoggerWithSink(func(entry security.AuditLog) {
+		entries = append(entries, entry)
+	})
+	comment := &model.Comment{ID: uuid.New(), AuthorID: uuid.New(), PostID: uuid.New()}
+
+	mockCommentRepo.On("GetByID", mock.Anything, comment.ID).Return(comment, nil)
+	mockCommentRepo.On("SetHidden", mock.Anything, comment.ID, true, mock.AnythingOfType("*string")).Return(nil)
+
+	hidden, err := (&mutationResolver{resolver}).HideComment(createModeratorContext(&model.User{ID: uuid.New()}), comment.ID.String(), "abusive")
+
+	require.NoError(t, err)
+	assert.True(t, hidden.Hidden)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "hide_comment", entries[0].Action)
+	assert.Equal(t, "comment", entries[0].Resource)
+}
+
+func TestMutationResolver_HidePost_RequiresReason(t *testing.T) {
+	resolver, _, _, _ := setupTestResolver()
+
+	_, err := (&mutationResolver{resolver}).HidePost(createModeratorContext(&model.User{ID: uuid.New()}), uuid.NewString(), "   ")
+
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrorCodeValidation, err.(*errors.GraphQLError).Code)
+}
+
+func TestMutationResolver_MergeTags_RequiresModeratePermission(t *testing.T) {
+	t.Run("unauthenticated", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+
+		_, err := (&mutationResolver{resolver}).MergeTags(context.Background(), "go-lang", "golang", nil)
+
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeUnauthenticated, err.(*errors.GraphQLError).Code)
+		mockPostRepo.AssertNotCalled(t, "MergeTags", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("regular user", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+
+		_, err := (&mutationResolver{resolver}).MergeTags(createRoleContext(&model.User{ID: uuid.New()}, security.RoleUser), "go-lang", "golang", nil)
+
+		assertForbidden(t, err)
+		mockPostRepo.AssertNotCalled(t, "MergeTags", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestMutationResolver_MergeTags_Validation(t *testing.T) {
+	tests := []struct {
+		name  string
+		from  string
+		into  string
+		field string
+	}{
+		{"empty source", "  ", "golang", "from"},
+		{"invalid target", "go-lang", "go lang", "into"},
+		{"same tag", "golang", " golang ", "into"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, _, mockPostRepo, _ := setupTestResolver()
+
+			_, err := (&mutationResolver{resolver}).MergeTags(createModeratorContext(&model.User{ID: uuid.New()}), tt.from, tt.into, nil)
+
+			require.Error(t, err)
+			gqlErr := err.(*errors.GraphQLError)
+			assert.Equal(t, errors.ErrorCodeValidation, gqlErr.Code)
+			assert.Equal(t, tt.field, gqlErr.Field)
+			mockPostRepo.AssertNotCalled(t, "MergeTags", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestMutationResolver_MergeTags_PostBeyondTagLimits(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	limitErr := fmt.Errorf("merging tags on post %s: %w", uuid.New(), repository.ErrInvalidTags)
+	mockPostRepo.On("MergeTags", mock.Anything, "go", "go-programming", false).Return(nil, limitErr)
+
+	_, err := (&mutationResolver{resolver}).MergeTags(createModeratorContext(&model.User{ID: uuid.New()}), "go", "go-programming", nil)
+
+	require.Error(t, err)
+	gqlErr := err.(*errors.GraphQLError)
+	assert.Equal(t, errors.ErrorCodeValidation, gqlErr.Code)
+	assert.Equal(t, "into", gqlErr.Field)
+}
+
+func TestMutationResolver_MergeTags_Audited(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	var entries []security.AuditLog
+	resolver.AuditLogger = security.NewAuditLoggerWithSink(func(entry security.AuditLog) {
+		entries = append(entries, entry)
+	})
+	moderator := &model.User{ID: uuid.New()}
+
+	mockPostRepo.On("MergeTags", mock.Anything, "go-lang", "golang", false).Return([]uuid.UUID{uuid.New(), uuid.New()}, nil)
+
+	merged, err := (&mutationResolver{resolver}).MergeTags(createModeratorContext(moderator), " go-lang ", "golang", nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, merged)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "merge_tags", entries[0].Action)
+	assert.Equal(t, "tag", entries[0].Resource)
+	assert.Equal(t, "go-lang", entries[0].ResourceID)
+	assert.Equal(t, moderator.ID.String(), entries[0].UserID)
+	assert.Equal(t, "golang", entries[0].Metadata["into"])
+	assert.Equal(t, 2, entries[0].Metadata["posts"])
+	assert.True(t, entries[0].Success)
+	mockPostRepo.AssertExpectations(t)
+}
+
+func TestMutationResolver_MergeTags_DryRun(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	var entries []security.AuditLog
+	resolver.AuditLogger = security.NewAuditLoggerWithSink(func(entry security.AuditLog) {
+		entries = append(entries, entry)
+	})
+	dryRun := true
+
+	mockPostRepo.On("MergeTags", mock.Anything, "go-lang", "golang", true).Return([]uuid.UUID{uuid.New(), uuid.New(), uuid.New()}, nil)
+
+	merged, err := (&mutationResolver{resolver}).MergeTags(createModeratorContext(&model.User{ID: uuid.New()}), "go-lang", "golang", &dryRun)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, merged)
+	require.Len(t, entries, 1)
+	assert.Equal(t, true, entries[0].Metadata["dry_run"])
+	mockPostRepo.AssertExpectations(t)
+}
+
+func TestQueryResolver_Post_HiddenVisibility(t *testing.T) {
+	author := &model.User{ID: uuid.New()}
+	reason := "spam"
+	post := &model.Post{ID: uuid.New(), AuthorID: author.ID, Published: true, Hidden: true, HiddenReason: &reason}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		visible bool
+	}{
+		{"anonymous", context.Background(), false},
+		{"other user", createAuthenticatedContext(&model.User{ID: uuid.New()}), false},
+		{"author", createAuthenticatedContext(author), true},
+		{"moderator", createModeratorContext(&model.User{ID: uuid.New()}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, _, mockPostRepo, _ := setupTestResolver()
+			mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+
+			result, err := (&queryResolver{resolver}).Post(tt.ctx, post.ID.String())
+
+			if tt.visible {
+				require.NoError(t, err)
+				assert.Equal(t, post, result)
+			} else {
+				require.Error(t, err)
+				assert.Equal(t, errors.ErrorCodeNotFound, err.(*errors.GraphQLError).Code)
+			}
+		})
+	}
+}
+
+func TestQueryResolver_Post_DraftVisibility(t *testing.T) {
+	author := &model.User{ID: uuid.New()}
+	draft := &model.Post{ID: uuid.New(), AuthorID: author.ID}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		visible bool
+	}{
+		{"anonymous", context.Background(), false},
+		{"other user", createAuthenticatedContext(&model.User{ID: uuid.New()}), false},
+		{"author", createAuthenticatedContext(author), true},
+		{"admin", createRoleContext(&model.User{ID: uuid.New()}, security.RoleAdmin), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, _, mockPostRepo, _ := setupTestResolver()
+			mockPostRepo.On("GetByID", mock.Anything, draft.ID).Return(draft, nil)
+
+			result, err := (&queryResolver{resolver}).Post(tt.ctx, draft.ID.String())
+
+			if tt.visible {
+				require.NoError(t, err)
+				assert.Equal(t, draft, result)
+			} else {
+				assert.Nil(t, result)
+				require.Error(t, err)
+				assert.Equal(t, errors.ErrorCodeNotFound, err.(*errors.GraphQLError).Code)
+			}
+		})
+	}
+}
+
+func TestQueryResolver_Posts_HiddenFilters(t *testing.T) {
+	viewer := &model.User{ID: uuid.New()}
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		viewerID      *uuid.UUID
+		includeHidden bool
+	}{
+		{"anonymous", context.Background(), nil, false},
+		{"signed in", createAuthenticatedContext(viewer), &viewer.ID, false},
+		{"moderator", createModeratorContext(viewer), &viewer.ID, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, _, mockPostRepo, _ := setupTestResolver()
+			matchFilters := mock.MatchedBy(func(f *repository.PostFilters) bool {
+				return f.IncludeHidden == tt.includeHidden && assert.ObjectsAreEqual(tt.viewerID, f.ViewerID)
+			})
+			mockPostRepo.On("List", mock.Anything, matchFilters, 20, 0).Return([]*model.Post{}, nil)
+			mockPostRepo.On("Count", mock.Anything, matchFilters).Return(0, nil)
+
+			_, err := (&queryResolver{resolver}).Posts(tt.ctx, nil, nil, nil)
+
+			require.NoError(t, err)
+			mockPostRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestQueryResolver_Posts_DraftFilters(t *testing.T) {
+	viewer := &model.User{ID: uuid.New()}
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		viewerID      *uuid.UUID
+		includeDrafts bool
+	}{
+		{"anonymous sees published only", context.Background(), nil, false},
+		{"author sees own drafts", createAuthenticatedContext(viewer), &viewer.ID, false},
+		{"moderator sees own drafts", createModeratorContext(viewer), &viewer.ID, false},
+		{"admin sees all drafts", createRoleContext(viewer, security.RoleAdmin), &viewer.ID, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, _, mockPostRepo, _ := setupTestResolver()
+			matchFilters := mock.MatchedBy(func(f *repository.PostFilters) bool {
+				return f.IncludeDrafts == tt.includeDrafts && assert.ObjectsAreEqual(tt.viewerID, f.ViewerID)
+			})
+			mockPostRepo.On("List", mock.Anything, matchFilters, 20, 0).Return([]*model.Post{}, nil)
+			mockPostRepo.On("Count", mock.Anything, matchFilters).Return(0, nil)
+
+			_, err := (&queryResolver{resolver}).Posts(tt.ctx, nil, nil, nil)
+
+			require.NoError(t, err)
+			mockPostRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMutationResolver_RotateJWTKey_RequiresAdmin(t *testing.T) {
+	user := &model.User{ID: uuid.New()}
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		resolver, _, _, _ := setupTestResolver()
+		before := resolver.AuthManager.JWTService.CurrentKeyID()
+
+		_, err := (&mutationResolver{resolver}).RotateJWTKey(context.Background())
+
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeUnauthenticated, err.(*errors.GraphQLError).Code)
+		assert.Equal(t, before, resolver.AuthManager.JWTService.CurrentKeyID())
+	})
+
+	t.Run("moderator", func(t *testing.T) {
+		resolver, _, _, _ := setupTestResolver()
+		before := resolver.AuthManager.JWTService.CurrentKeyID()
+
+		_, err := (&mutationResolver{resolver}).RotateJWTKey(createModeratorContext(user))
+
+		assertForbidden(t, err)
+		assert.Equal(t, before, resolver.AuthManager.JWTService.CurrentKeyID())
+	})
+}
+
+func TestMutationResolver_RotateJWTKey_Audited(t *testing.T) {
+	resolver, _, _, _ := setupTestResolver()
+	var entries []security.AuditLog
+	resolver.AuditLogger = security.NewAuditLoggerWithSink(func(entry security.AuditLog) {
+		entries = append(entries, entry)
+	})
+	admin := &model.User{ID: uuid.New()}
+
+	keyID, err := (&mutationResolver{resolver}).RotateJWTKey(createRoleContext(admin, security.RoleAdmin))
+
+	require.NoError(t, err)
+	assert.Equal(t, keyID, resolver.AuthManager.JWTService.CurrentKeyID())
+	require.Len(t, entries, 1)
+	assert.Equal(t, "rotate_jwt_key", entries[0].Action)
+	assert.Equal(t, keyID, entries[0].ResourceID)
+	assert.True(t, entries[0].Success)
+}
+
+func TestQueryResolver_Viewer_RequiresAuth(t *testing.T) {
+	resolver, _, _, _ := setupTestResolver()
+	queryResolver := &queryResolver{resolver}
+
+	_, err := queryResolver.Viewer(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrorCodeUnauthenticated, err.(*errors.GraphQLError).Code)
+}
+
+func TestQueryResolver_Viewer_RolePermissions(t *testing.T) {
+	resolver, _, _, _ := setupTestResolver()
+	queryResolver := &queryResolver{resolver}
+	user := &model.User{ID: uuid.New(), Email: "viewer@example.com", Name: "Viewer"}
+
+	tests := []struct {
+		role     security.Role
+		expected []string
+	}{
+		{
+			role: security.RoleAdmin,
+			expected: []string{
+				"read:post", "write:post", "delete:post",
+				"read:user", "write:user", "delete:user",
+				"read:comment", "write:comment", "delete:comment",
+				"moderate", "admin",
+			},
+		},
+		{
+			role: security.RoleModerator,
+			expected: []string{
+				"read:post", "write:post", "delete:post",
+				"read:user", "read:comment", "write:comment",
+				"delete:comment", "moderate",
+			},
+		},
+		{
+			role:     security.RoleUser,
+			expected: []string{"read:post", "write:post", "read:user", "read:comment", "write:comment"},
+		},
+		{
+			role:     security.RoleGuest,
+			expected: []string{"read:post", "read:comment"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			viewer, err := queryResolver.Viewer(createRoleContext(user, tt.role))
+			require.NoError(t, err)
+			assert.Equal(t, user, viewer.User)
+			assert.Equal(t, string(tt.role), viewer.Role)
+			assert.Equal(t, tt.expected, viewer.Permissions)
+		})
+	}
+}
+
+func TestQueryResolver_Viewer_DefaultsToUserRole(t *testing.T) {
+	resolver, _, _, _ := setupTestResolver()
+	queryResolver := &queryResolver{resolver}
+	user := &model.User{ID: uuid.New()}
+
+	// A security user for another account must not lend its role
+	ctx := security.WithUser(createAuthenticatedContext(user), &security.User{ID: uuid.New().String(), Role: security.RoleAdmin})
+
+	viewer, err := queryResolver.Viewer(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, string(security.RoleUser), viewer.Role)
+	assert.NotContains(t, viewer.Permissions, "admin")
+}
+
+func TestQueryResolver_PostsByIds_RequestOrder(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	queryResolver := &queryResolver{resolver}
+
+	first := &model.Post{ID: uuid.New(), Published: true}
+	second := &model.Post{ID: uuid.New(), Published: true}
+	missing := uuid.New()
+
+	// The repository returns rows in no particular order
+	mockPostRepo.On("GetByIDs", mock.Anything, []uuid.UUID{second.ID, missing, first.ID}).
+		Return([]*model.Post{first, second}, nil).Once()
+
+	posts, err := queryResolver.PostsByIds(context.Background(), []string{
+		relay.ToGlobalID(relay.TypePost, second.ID),
+		missing.String(),
+		first.ID.String(),
+		second.ID.String(),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Post{second, nil, first}, posts, "repeats are dropped and missing posts are nil")
+	mockPostRepo.AssertExpectations(t)
+}
+
+func TestQueryResolver_PostsByIds_Visibility(t *testing.T) {
+	author := &model.User{ID: uuid.New()}
+	published := &model.Post{ID: uuid.New(), AuthorID: author.ID, Published: true}
+	draft := &model.Post{ID: uuid.New(), AuthorID: author.ID}
+	hidden := &model.Post{ID: uuid.New(), AuthorID: author.ID, Published: true, Hidden: true}
+	ids := []string{published.ID.String(), draft.ID.String(), hidden.ID.String()}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected []*model.Post
+	}{
+		{name: "anonymous", ctx: context.Background(), expected: []*model.Post{published, nil, nil}},
+		{name: "other user", ctx: createAuthenticatedContext(&model.User{ID: uuid.New()}), expected: []*model.Post{published, nil, nil}},
+		{name: "author", ctx: createAuthenticatedContext(author), expected: []*model.Post{published, draft, hidden}},
+		{name: "moderator", ctx: createModeratorContext(&model.User{ID: uuid.New()}), expected: []*model.Post{published, nil, hidden}},
+		{name: "admin", ctx: createRoleContext(&model.User{ID: uuid.New()}, security.RoleAdmin), expected: []*model.Post{published, draft, hidden}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, _, mockPostRepo, _ := setupTestResolver()
+			queryResolver := &queryResolver{resolver}
+			mockPostRepo.On("GetByIDs", mock.Anything, mock.Anything).Return([]*model.Post{published, draft, hidden}, nil)
+
+			posts, err := queryResolver.PostsByIds(tt.ctx, ids)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, posts)
+		})
+	}
+}
+
+func TestQueryResolver_PostsByIds_MaxCount(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	queryResolver := &queryResolver{resolver}
+
+	ids := make([]string, maxBatchIDs+1)
+	for i := range ids {
+		ids[i] = uuid.New().String()
+	}
+
+	_, err := queryResolver.PostsByIds(context.Background(), ids)
+
+	require.Error(t, err)
+	gqlErr := err.(*errors.GraphQLError)
+	assert.Equal(t, errors.ErrorCodeValidation, gqlErr.Code)
+	assert.Equal(t, fmt.Sprintf("Cannot request more than %d posts", maxBatchIDs), gqlErr.Message)
+	mockPostRepo.AssertNotCalled(t, "GetByIDs", mock.Anything, mock.Anything)
+}
+
+func TestQueryResolver_PostsByIds_InvalidID(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	queryResolver := &queryResolver{resolver}
+
+	_, err := queryResolver.PostsByIds(context.Background(), []string{uuid.New().String(), "not-an-id"})
+
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrorCodeInvalidFormat, err.(*errors.GraphQLError).Code)
+	mockPostRepo.AssertNotCalled(t, "GetByIDs", mock.Anything, mock.Anything)
+}
+
+func TestQueryResolver_PostsByIds_Empty(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	queryResolver := &queryResolver{resolver}
+
+	posts, err := queryResolver.PostsByIds(context.Background(), nil)
+
+	require.NoError(t, err)
+	assert.Empty(t, posts)
+	mockPostRepo.AssertNotCalled(t, "GetByIDs", mock.Anything, mock.Anything)
+}
+
+func TestQueryResolver_UsersByIds_RequestOrder(t *testing.T) {
+	resolver, mockUserRepo, _, _ := setupTestResolver()
+	queryResolver := &queryResolver{resolver}
+
+	admin := &model.User{ID: uuid.New()}
+	alice := &model.User{ID: uuid.New(), Name: "Alice", PasswordHash: "hash-a"}
+	bob := &model.User{ID: uuid.New(), Name: "Bob", PasswordHash: "hash-b"}
+	missing := uuid.New()
+
+	mockUserRepo.On("GetByIDs", mock.Anything, []uuid.UUID{bob.ID, missing, alice.ID}).
+		Return([]*model.User{alice, bob}, nil).Once()
+
+	users, err := queryResolver.UsersByIds(createRoleContext(admin, security.RoleAdmin), []string{
+		relay.ToGlobalID(relay.TypeUser, bob.ID),
+		missing.String(),
+		alice.ID.String(),
+		bob.ID.String(),
+	})
+
+	require.NoError(t, err)
+	require.Len(t, users, 3)
+	assert.Equal(t, bob.ID, users[0].ID)
+	assert.Nil(t, users[1], "missing users are nil")
+	assert.Equal(t, alice.ID, users[2].ID)
+	for _, user := range []*model.User{users[0], users[2]} {
+		assert.Empty(t, user.PasswordHash, "password hashes are never returned")
+	}
+	assert.Equal(t, "hash-a", alice.PasswordHash, "repository users are not modified")
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestQueryResolver_UsersByIds_RequiresAdmin(t *testing.T) {
+	user := &model.User{ID: uuid.New()}
+	ids := []string{uuid.New().String()}
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		resolver, mockUserRepo, _, _ := setupTestResolver()
+		queryResolver := &queryResolver{resolver}
+
+		_, err := queryResolver.UsersByIds(context.Background(), ids)
+
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeUnauthenticated, err.(*errors.GraphQLError).Code)
+		mockUserRepo.AssertNotCalled(t, "GetByIDs", mock.Anything, mock.Anything)
+	})
+
+	for _, role := range []security.Role{security.RoleUser, security.RoleModerator} {
+		t.Run(string(role), func(t *testing.T) {
+			resolver, mockUserRepo, _, _ := setupTestResolver()
+			queryResolver := &queryResolver{resolver}
+
+			_, err := queryResolver.UsersByIds(createRoleContext(user, role), ids)
+
+			assertForbidden(t, err)
+			mockUserRepo.AssertNotCalled(t, "GetByIDs", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestQueryResolver_UsersByIds_InvalidID(t *testing.T) {
+	resolver, mockUserRepo, _, _ := setupTestResolver()
+	queryResolver := &queryResolver{resolver}
+
+	_, err := queryResolver.UsersByIds(createRoleContext(&model.User{ID: uuid.New()}, security.RoleAdmin),
+		[]string{relay.ToGlobalID(relay.TypePost, uuid.New())})
+
+	require.Error(t, err)
+	gqlErr := err.(*errors.GraphQLError)
+	assert.Equal(t, errors.ErrorCodeInvalidFormat, gqlErr.Code)
+	assert.Equal(t, "Invalid user ID format", gqlErr.Message)
+	mockUserRepo.AssertNotCalled(t, "GetByIDs", mock.Anything, mock.Anything)
+}
+
+func TestCommentResolver_Post_BatchesThroughLoader(t *testing.T) {
+	resolver, mockUserRepo, mockPostRepo, _ := setupTestResolver()
+	commentResolver := &commentResolver{resolver}
+
+	first := &model.Post{ID: uuid.New(), Published: true}
+	second := &model.Post{ID: uuid.New(), Published: true}
+	comments := []*model.Comment{
+		{ID: uuid.New(), PostID: first.ID},
+		{ID: uuid.New(), PostID: second.ID},
+		{ID: uuid.New(), PostID: first.ID},
+	}
+
+	mockPostRepo.On("GetByIDs", mock.Anything, mock.MatchedBy(func(ids []uuid.UUID) bool {
+		return assert.ElementsMatch(t, []uuid.UUID{first.ID, second.ID}, ids)
+	})).Return([]*model.Post{first, second}, nil).Once()
+
+	loaders := dataloader.NewLoaders(&repository.Manager{User: mockUserRepo, Post: mockPostRepo})
+	ctx := dataloader.WithLoaders(context.Background(), loaders)
+
+	// Fields resolve concurrently, so the loader sees every comment at once
+	posts := make([]*model.Post, len(comments))
+	var wg sync.WaitGroup
+	for i, comment := range comments {
+		wg.Add(1)
+		go func(i int, comment *model.Comment) {
+			defer wg.Done()
+			post, err := commentResolver.Post(ctx, comment)
+			assert.NoError(t, err)
+			posts[i] = post
+		}(i, comment)
+	}
+	wg.Wait()
+
+	assert.Equal(t, []*model.Post{first, second, first}, posts)
+	mockPostRepo.AssertNumberOfCalls(t, "GetByIDs", 1)
+	mockPostRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestCommentResolver_Post_Visibility(t *testing.T) {
+	author := &model.User{ID: uuid.New()}
+	published := &model.Post{ID: uuid.New(), AuthorID: author.ID, Published: true}
+	draft := &model.Post{ID: uuid.New(), AuthorID: author.ID}
+	hidden := &model.Post{ID: uuid.New(), AuthorID: author.ID, Published: true, Hidden: true}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected []*model.Post
+	}{
+		{name: "anonymous", ctx: context.Background(), expected: []*model.Post{published, nil, nil}},
+		{name: "other user", ctx: createAuthenticatedContext(&model.User{ID: uuid.New()}), expected: []*model.Post{published, nil, nil}},
+		{name: "author", ctx: createAuthenticatedContext(author), expected: []*model.Post{published, draft, hidden}},
+		{name: "moderator", ctx: createModeratorContext(&model.User{ID: uuid.New()}), expected: []*model.Post{published, nil, hidden}},
+		{name: "admin", ctx: createRoleContext(&model.User{ID: uuid.New()}, security.RoleAdmin), expected: []*model.Post{published, draft, hidden}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, _, mockPostRepo, _ := setupTestResolver()
+			commentResolver := &commentResolver{resolver}
+
+			posts := make([]*model.Post, 0, 3)
+			for _, post := range []*model.Post{published, draft, hidden} {
+				mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+
+				resolved, err := commentResolver.Post(tt.ctx, &model.Comment{ID: uuid.New(), PostID: post.ID})
+				if resolved == nil {
+					// Posts the viewer cannot see look missing, as the field is non-null
+					var gqlErr *errors.GraphQLError
+					require.ErrorAs(t, err, &gqlErr)
+					assert.Equal(t, errors.ErrorCodeNotFound, gqlErr.Code)
+				} else {
+					require.NoError(t, err)
+				}
+				posts = append(posts, resolved)
+			}
+
+			assert.Equal(t, tt.expected, posts)
+		})
+	}
+}
+
+// resettablePostRepo is a post repository with a cache that counts its resets
+type resettablePostRepo struct {
+	*MockPostRepo
+	resets int
+	err    error
+}
+
+func (r *resettablePostRepo) Reset(ctx context.Context) error {
+	r.resets++
+	return r.err
+}
+
+func TestMutationResolver_ClearCache(t *testing.T) {
+	admin := &model.User{ID: uuid.New()}
+
+	tests := []struct {
+		scope          model.CacheScope
+		expectedResets int
+		loadersCleared bool
+	}{
+		{scope: model.CacheScopeUsers, expectedResets: 0, loadersCleared: false},
+		{scope: model.CacheScopePosts, expectedResets: 1, loadersCleared: false},
+		{scope: model.CacheScopeLoaders, expectedResets: 0, loadersCleared: true},
+		{scope: model.CacheScopeAll, expectedResets: 1, loadersCleared: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scope.String(), func(t *testing.T) {
+			resolver, mockUserRepo, mockPostRepo, _ := setupTestResolver()
+			postRepo := &resettablePostRepo{MockPostRepo: mockPostRepo}
+			resolver.PostRepo = postRepo
+			mutationResolver := &mutationResolver{resolver}
+
+			post := &model.Post{ID: uuid.New()}
+			mockPostRepo.On("GetByIDs", mock.Anything, []uuid.UUID{post.ID}).Return([]*model.Post{post}, nil)
+
+			loaders := dataloader.NewLoaders(&repository.Manager{User: mockUserRepo, Post: mockPostRepo})
+			ctx := dataloader.WithLoaders(createRoleContext(admin, security.RoleAdmin), loaders)
+			_, err := dataloader.GetPost(ctx, post.ID.String())
+			require.NoError(t, err)
+
+			cleared, err := mutationResolver.ClearCache(ctx, tt.scope)
+			require.NoError(t, err)
+			assert.True(t, cleared)
+			assert.Equal(t, tt.expectedResets, postRepo.resets)
+
+			// A cleared loader goes back to the repository
+			_, err = dataloader.GetPost(ctx, post.ID.String())
+			require.NoError(t, err)
+			expectedCalls := 1
+			if tt.loadersCleared {
+				expectedCalls = 2
+			}
+			mockPostRepo.AssertNumberOfCalls(t, "GetByIDs", expectedCalls)
+		})
+	}
+}
+
+func TestMutationResolver_ClearCache_ResetFails(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	resolver.PostRepo = &resettablePostRepo{MockPostRepo: mockPostRepo, err: fmt.Errorf("redis unavailable")}
+	mutationResolver := &mutationResolver{resolver}
+
+	cleared, err := mutationResolver.ClearCache(createRoleContext(&model.User{ID: uuid.New()}, security.RoleAdmin), model.CacheScopePosts)
+
+	require.Error(t, err)
+	assert.False(t, cleared)
+	assert.Equal(t, errors.ErrorCodeInternal, err.(*errors.GraphQLError).Code)
+}
+
+func TestMutationResolver_ClearCache_RequiresAdmin(t *testing.T) {
+	user := &model.User{ID: uuid.New()}
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		postRepo := &resettablePostRepo{MockPostRepo: mockPostRepo}
+		resolver.PostRepo = postRepo
+		mutationResolver := &mutationResolver{resolver}
+
+		_, err := mutationResolver.ClearCache(context.Background(), model.CacheScopeAll)
+
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeUnauthenticated, err.(*errors.GraphQLError).Code)
+		assert.Zero(t, postRepo.resets)
+	})
+
+	for _, role := range []security.Role{security.RoleUser, security.RoleModerator} {
+		t.Run(string(role), func(t *testing.T) {
+			resolver, _, mockPostRepo, _ := setupTestResolver()
+			postRepo := &resettablePostRepo{MockPostRepo: mockPostRepo}
+			resolver.PostRepo = postRepo
+			mutationResolver := &mutationResolver{resolver}
+
+			_, err := mutationResolver.ClearCache(createRoleContext(user, role), model.CacheScopeAll)
+
+			assertForbidden(t, err)
+			assert.Zero(t, postRepo.resets)
+		})
+	}
+}
+
+// seedStats stubs the repository counts behind the stats query
+func seedStats(userRepo *MockUserRepo, postRepo *MockPostRepo, commentRepo *MockCommentRepo) {
+	userRepo.On("Count", mock.Anything).Return(12, nil)
+	userRepo.On("CountCreatedSince", mock.Anything, mock.Anything).Return(3, nil)
+	postRepo.On("CountByPublished", mock.Anything).Return(7, 2, nil)
+	commentRepo.On("CountAll", mock.Anything).Return(40, nil)
+}
+
+func TestQueryResolver_Stats(t *testing.T) {
+	resolver, mockUserRepo, mockPostRepo, mockCommentRepo := setupTestResolver()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	resolver.Clock = func() time.Time { return now }
+	queryResolver := &queryResolver{resolver}
+	seedStats(mockUserRepo, mockPostRepo, mockCommentRepo)
+
+	stats, err := queryResolver.Stats(createRoleContext(&model.User{ID: uuid.New()}, security.RoleAdmin))
+
+	require.NoError(t, err)
+	assert.Equal(t, &model.Stats{
+		TotalUsers:       12,
+		TotalPosts:       9,
+		PublishedPosts:   7,
+		UnpublishedPosts: 2,
+		TotalComments:    40,
+		NewUsersLast24h:  3,
+	}, stats)
+	mockUserRepo.AssertCalled(t, "CountCreatedSince", mock.Anything, now.Add(-24*time.Hour))
+}
+
+func TestQueryResolver_Stats_Cached(t *testing.T) {
+	resolver, mockUserRepo, mockPostRepo, mockCommentRepo := setupTestResolver()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	resolver.Clock = func() time.Time { return now }
+	resolver.StatsCache = &StatsCache{TTL: time.Minute}
+	queryResolver := &queryResolver{resolver}
+	seedStats(mockUserRepo, mockPostRepo, mockCommentRepo)
+	ctx := createRoleContext(&model.User{ID: uuid.New()}, security.RoleAdmin)
+
+	first, err := queryResolver.Stats(ctx)
+	require.NoError(t, err)
+	first.TotalUsers = 0
+
+	now = now.Add(30 * time.Second)
+	second, err := queryResolver.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 12, second.TotalUsers, "callers get their own copy")
+	mockUserRepo.AssertNumberOfCalls(t, "Count", 1)
+	mockPostRepo.AssertNumberOfCalls(t, "CountByPublished", 1)
+	mockCommentRepo.AssertNumberOfCalls(t, "CountAll", 1)
+
+	// Expired totals are counted again
+	now = now.Add(time.Minute)
+	_, err = queryResolver.Stats(ctx)
+	require.NoError(t, err)
+	mockUserRepo.AssertNumberOfCalls(t, "Count", 2)
+	mockPostRepo.AssertNumberOfCalls(t, "CountByPublished", 2)
+	mockCommentRepo.AssertNumberOfCalls(t, "CountAll", 2)
+}
+
+func TestQueryResolver_Stats_CountFails(t *testing.T) {
+	resolver, mockUserRepo, mockPostRepo, _ := setupTestResolver()
+	resolver.StatsCache = &StatsCache{TTL: time.Minute}
+	queryResolver := &queryResolver{resolver}
+	mockUserRepo.On("Count", mock.Anything).Return(12, nil)
+	mockUserRepo.On("CountCreatedSince", mock.Anything, mock.Anything).Return(3, nil)
+	mockPostRepo.On("CountByPublished", mock.Anything).Return(0, 0, fmt.Errorf("connection refused"))
+	ctx := createRoleContext(&model.User{ID: uuid.New()}, security.RoleAdmin)
+
+	_, err := queryResolver.Stats(ctx)
+	require.Error(t, err)
+
+	// Failures are not cached
+	_, err = queryResolver.Stats(ctx)
+	require.Error(t, err)
+	mockPostRepo.AssertNumberOfCalls(t, "CountByPublished", 2)
+}
+
+func TestQueryResolver_Stats_RequiresAdmin(t *testing.T) {
+	user := &model.User{ID: uuid.New()}
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		resolver, mockUserRepo, _, _ := setupTestResolver()
+		queryResolver := &queryResolver{resolver}
+
+		_, err := queryResolver.Stats(context.Background())
+
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeUnauthenticated, err.(*errors.GraphQLError).Code)
+		mockUserRepo.AssertNotCalled(t, "Count", mock.Anything)
+	})
+
+	for _, role := range []security.Role{security.RoleUser, security.RoleModerator} {
+		t.Run(string(role), func(t *testing.T) {
+			resolver, mockUserRepo, _, _ := setupTestResolver()
+			queryResolver := &queryResolver{resolver}
+
+			_, err := queryResolver.Stats(createRoleContext(user, role))
+
+			assertForbidden(t, err)
+			mockUserRepo.AssertNotCalled(t, "Count", mock.Anything)
+		})
+	}
+}
+
+func TestUserResolver_LastLogin_AdminOnly(t *testing.T) {
+	lastLoginAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	lastLoginIP := "203.0.113.7"
+	user := &model.User{ID: uuid.New(), LastLoginAt: &lastLoginAt, LastLoginIP: &lastLoginIP}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		visible bool
+	}{
+		{name: "anonymous", ctx: context.Background()},
+		{name: "the user", ctx: createRoleContext(user, security.RoleUser)},
+		{name: "moderator", ctx: createRoleContext(&model.User{ID: uuid.New()}, security.RoleModerator)},
+		{name: "admin", ctx: createRoleContext(&model.User{ID: uuid.New()}, security.RoleAdmin), visible: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, _, _, _ := setupTestResolver()
+			userResolver := &userResolver{resolver}
+
+			at, err := userResolver.LastLoginAt(tt.ctx, user)
+			require.NoError(t, err)
+			ip, err := userResolver.LastLoginIP(tt.ctx, user)
+			require.NoError(t, err)
+
+			if tt.visible {
+				assert.Equal(t, &lastLoginAt, at)
+				assert.Equal(t, &lastLoginIP, ip)
+			} else {
+				assert.Nil(t, at)
+				assert.Nil(t, ip)
+			}
+		})
+	}
+}
+
+func TestMutationResolver_ReportContent(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	reporter := &model.User{ID: uuid.New()}
+	post := &model.Post{ID: uuid.New(), AuthorID: uuid.New(), Published: true}
+
+	setup := func() (*mutationResolver, *MockPostRepo, *MockReportRepo) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		mockReportRepo := new(MockReportRepo)
+		resolver.ReportRepo = mockReportRepo
+		resolver.Clock = func() time.Time { return now }
+		return &mutationResolver{resolver}, mockPostRepo, mockReportRepo
+	}
+
+	t.Run("creates an open report", func(t *testing.T) {
+		mutationResolver, mockPostRepo, mockReportRepo := setup()
+		mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+		mockReportRepo.On("Create", mock.Anything, mock.MatchedBy(func(report *model.Report) bool {
+			return report.ReporterID == reporter.ID &&
+				report.TargetType == model.ReportTargetTypePost &&
+				report.TargetID == post.ID &&
+				report.Reason == "spam" &&
+				report.Status == model.ReportStatusOpen &&
+				report.CreatedAt.Equal(now)
+		})).Return(&model.Report{ID: uuid.New(), TargetID: post.ID, Status: model.ReportStatusOpen}, nil)
+
+		report, err := mutationResolver.ReportContent(createAuthenticatedContext(reporter), model.ReportTargetTypePost, relay.ToGlobalID(relay.TypePost, post.ID), "  spam ")
+
+		require.NoError(t, err)
+		assert.Equal(t, post.ID, report.TargetID)
+		mockReportRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns the existing open report", func(t *testing.T) {
+		mutationResolver, mockPostRepo, mockReportRepo := setup()
+		existing := &model.Report{ID: uuid.New(), ReporterID: reporter.ID, TargetID: post.ID, Status: model.ReportStatusOpen}
+		mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+		mockReportRepo.On("Create", mock.Anything, mock.Anything).Return(existing, nil)
+
+		report, err := mutationResolver.ReportContent(createAuthenticatedContext(reporter), model.ReportTargetTypePost, post.ID.String(), "spam")
+
+		require.NoError(t, err)
+		assert.Equal(t, existing.ID, report.ID)
+	})
+
+	t.Run("rejects own content", func(t *testing.T) {
+		mutationResolver, mockPostRepo, mockReportRepo := setup()
+		mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+
+		_, err := mutationResolver.ReportContent(createAuthenticatedContext(&model.User{ID: post.AuthorID}), model.ReportTargetTypePost, post.ID.String(), "spam")
+
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeValidation, err.(*errors.GraphQLError).Code)
+		mockReportRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects a missing comment", func(t *testing.T) {
+		mutationResolver, _, mockReportRepo := setup()
+		commentRepo := mutationResolver.CommentRepo.(*MockCommentRepo)
+		commentID := uuid.New()
+		commentRepo.On("GetByID", mock.Anything, commentID).Return(nil, fmt.Errorf("comment not found"))
+
+		_, err := mutationResolver.ReportContent(createAuthenticatedContext(reporter), model.ReportTargetTypeComment, commentID.String(), "spam")
+
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeNotFound, err.(*errors.GraphQLError).Code)
+		mockReportRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		mutationResolver, _, mockReportRepo := setup()
+
+		_, err := mutationResolver.ReportContent(context.Background(), model.ReportTargetTypePost, post.ID.String(), "spam")
+
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeUnauthenticated, err.(*errors.GraphQLError).Code)
+		mockReportRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestQueryResolver_Reports(t *testing.T) {
+	t.Run("regular user", func(t *testing.T) {
+		resolver, _, _, _ := setupTestResolver()
+		mockReportRepo := new(MockReportRepo)
+		resolver.ReportRepo = mockReportRepo
+
+		_, err := (&queryResolver{resolver}).Reports(createRoleContext(&model.User{ID: uuid.New()}, security.RoleUser), nil, nil)
+
+		assertForbidden(t, err)
+		mockReportRepo.AssertNotCalled(t, "ListByStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("defaults to open reports", func(t *testing.T) {
+		resolver, _, _, _ := setupTestResolver()
+		mockReportRepo := new(MockReportRepo)
+		resolver.ReportRepo = mockReportRepo
+		open := []*model.Report{{ID: uuid.New(), Status: model.ReportStatusOpen}}
+		mockReportRepo.On("ListByStatus", mock.Anything, model.ReportStatusOpen, 20, 0).Return(open, nil)
+
+		reports, err := (&queryResolver{resolver}).Reports(createModeratorContext(&model.User{ID: uuid.New()}), nil, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, open, reports)
+	})
+}
+
+func TestMutationResolver_ResolveReport(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	moderator := &model.User{ID: uuid.New()}
+
+	setup := func(report *model.Report) (*mutationResolver, *MockPostRepo, *MockReportRepo) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		mockReportRepo := new(MockReportRepo)
+		resolver.ReportRepo = mockReportRepo
+		resolver.Clock = func() time.Time { return now }
+		mockReportRepo.On("GetByID", mock.Anything, report.ID).Return(report, nil)
+		return &mutationResolver{resolver}, mockPostRepo, mockReportRepo
+	}
+
+	t.Run("hide content resolves the report", func(t *testing.T) {
+		post := &model.Post{ID: uuid.New(), AuthorID: uuid.New()}
+		report := &model.Report{ID: uuid.New(), TargetType: model.ReportTargetTypePost, TargetID: post.ID, Reason: "spam", Status: model.ReportStatusOpen}
+		mutationResolver, mockPostRepo, mockReportRepo := setup(report)
+		var entries []security.AuditLog
+		mutationResolver.AuditLogger = security.NewAuditLoggerWithSink(func(entry security.AuditLog) { entries = append(entries, entry) })
+		mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+		// Hiding the post and closing its other reports happen in the same call
+		mockReportRepo.On("Resolve", mock.Anything, report.ID, model.ReportStatusResolved, true, now).Return(3, nil)
+
+		resolved, err := mutationResolver.ResolveReport(createModeratorContext(moderator), relay.ToGlobalID(relay.TypeReport, report.ID), model.ReportActionHideContent)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.ReportStatusResolved, resolved.Status)
+		require.NotNil(t, resolved.ResolvedAt)
+		assert.True(t, resolved.ResolvedAt.Equal(now))
+		mockPostRepo.AssertNotCalled(t, "SetHidden", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockReportRepo.AssertExpectations(t)
+
+		require.Len(t, entries, 2)
+		assert.Equal(t, "hide_post", entries[0].Action)
+		assert.Equal(t, post.AuthorID.String(), entries[0].Metadata["author_id"])
+		assert.Equal(t, "spam", entries[0].Metadata["reason"])
+		assert.Equal(t, "resolve_report", entries[1].Action)
+		assert.Equal(t, 3, entries[1].Metadata["closed_reports"])
+	})
+
+	t.Run("missing content leaves the report open", func(t *testing.T) {
+		report := &model.Report{ID: uuid.New(), TargetType: model.ReportTargetTypeComment, TargetID: uuid.New(), Reason: "spam", Status: model.ReportStatusOpen}
+		mutationResolver, _, mockReportRepo := setup(report)
+		mutationResolver.CommentRepo.(*MockCommentRepo).On("GetByID", mock.Anything, report.TargetID).Return(nil, fmt.Errorf("comment not found"))
+
+		_, err := mutationResolver.ResolveReport(createModeratorContext(moderator), report.ID.String(), model.ReportActionHideContent)
+
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeNotFound, err.(*errors.GraphQLError).Code)
+		mockReportRepo.AssertNotCalled(t, "Resolve", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("report closed meanwhile", func(t *testing.T) {
+		report := &model.Report{ID: uuid.New(), TargetType: model.ReportTargetTypePost, TargetID: uuid.New(), Status: model.ReportStatusOpen}
+		mutationResolver, _, mockReportRepo := setup(report)
+		mockReportRepo.On("Resolve", mock.Anything, report.ID, model.ReportStatusDismissed, false, now).Return(0, repository.ErrReportNotOpen)
+
+		_, err := mutationResolver.ResolveReport(createModeratorContext(moderator), report.ID.String(), model.ReportActionDismiss)
+
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeValidation, err.(*errors.GraphQLError).Code)
+	})
+
+	t.Run("dismiss leaves the content alone", func(t *testing.T) {
+		report := &model.Report{ID: uuid.New(), TargetType: model.ReportTargetTypePost, TargetID: uuid.New(), Status: model.ReportStatusOpen}
+		mutationResolver, mockPostRepo, mockReportRepo := setup(report)
+		mockReportRepo.On("Resolve", mock.Anything, report.ID, model.ReportStatusDismissed, false, now).Return(1, nil)
+
+		resolved, err := mutationResolver.ResolveReport(createModeratorContext(moderator), report.ID.String(), model.ReportActionDismiss)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.ReportStatusDismissed, resolved.Status)
+		mockPostRepo.AssertNotCalled(t, "SetHidden", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects a closed report", func(t *testing.T) {
+		report := &model.Report{ID: uuid.New(), Status: model.ReportStatusDismissed}
+		mutationResolver, _, mockReportRepo := setup(report)
+
+		_, err := mutationResolver.ResolveReport(createModeratorContext(moderator), report.ID.String(), model.ReportActionDismiss)
+
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeValidation, err.(*errors.GraphQLError).Code)
+		mockReportRepo.AssertNotCalled(t, "Resolve", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("regular user", func(t *testing.T) {
+		report := &model.Report{ID: uuid.New(), Status: model.ReportStatusOpen}
+		mutationResolver, _, mockReportRepo := setup(report)
+
+		_, err := mutationResolver.ResolveReport(createRoleContext(moderator, security.RoleUser), report.ID.String(), model.ReportActionDismiss)
+
+		assertForbidden(t, err)
+		mockReportRepo.AssertNotCalled(t, "Resolve", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserResolver_Posts(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	author := &model.User{ID: uuid.New()}
+	post := &model.Post{ID: uuid.New(), AuthorID: author.ID, Published: true}
+	silent := &model.User{ID: uuid.New()}
+	mockPostRepo.On("GetPublishedByAuthorIDs", mock.Anything, []uuid.UUID{author.ID}, dataloader.DefaultAuthorPostsLimit).
+		Return(map[uuid.UUID][]*model.Post{author.ID: {post}}, nil)
+	mockPostRepo.On("GetPublishedByAuthorIDs", mock.Anything, []uuid.UUID{silent.ID}, dataloader.DefaultAuthorPostsLimit).
+		Return(map[uuid.UUID][]*model.Post{}, nil)
+	userResolver := &userResolver{resolver}
+
+	posts, err := userResolver.Posts(context.Background(), author)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Post{post}, posts)
+
+	posts, err = userResolver.Posts(context.Background(), silent)
+	require.NoError(t, err)
+	assert.NotNil(t, posts)
+	assert.Empty(t, posts)
+}
+
+func TestQueryResolver_SearchUsers(t *testing.T) {
+	viewer := &model.User{ID: uuid.New()}
+	match := &model.User{ID: uuid.New(), Name: "Grace Hopper"}
+
+	t.Run("matches names only for regular users", func(t *testing.T) {
+		resolver, mockUserRepo, _, _ := setupTestResolver()
+		mockUserRepo.On("SearchUsers", mock.Anything, "grace", false, 10).Return([]*model.User{match}, nil)
+
+		users, err := (&queryResolver{resolver}).SearchUsers(createRoleContext(viewer, security.RoleUser), " grace ", nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, []*model.User{match}, users)
+		mockUserRepo.AssertExpectations(t)
+
+		email, err := resolver.User().Email(context.Background(), users[0])
+		require.NoError(t, err)
+		assert.Nil(t, email, "a hidden address resolves to null, not an empty string")
+	})
+
+	t.Run("matches emails for admins", func(t *testing.T) {
+		resolver, mockUserRepo, _, _ := setupTestResolver()
+		limit := 5
+		mockUserRepo.On("SearchUsers", mock.Anything, "navy.mil", true, 5).Return([]*model.User{match}, nil)
+
+		_, err := (&queryResolver{resolver}).SearchUsers(createRoleContext(viewer, security.RoleAdmin), "navy.mil", &limit)
+
+		require.NoError(t, err)
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("requires authentication", func(t *testing.T) {
+		resolver, mockUserRepo, _, _ := setupTestResolver()
+
+		_, err := (&queryResolver{resolver}).SearchUsers(context.Background(), "grace", nil)
+
+		require.Error(t, err)
+		mockUserRepo.AssertNotCalled(t, "SearchUsers", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects queries outside the length guard", func(t *testing.T) {
+		resolver, mockUserRepo, _, _ := setupTestResolver()
+		queryResolver := &queryResolver{resolver}
+		ctx := createRoleContext(viewer, security.RoleUser)
+
+		for _, query := range []string{"", " g ", strings.Repeat("g", 101)} {
+			_, err := queryResolver.SearchUsers(ctx, query, nil)
+			assert.Error(t, err, "query %q", query)
+		}
+		tooMany := 51
+		_, err := queryResolver.SearchUsers(ctx, "grace", &tooMany)
+		assert.Error(t, err)
+		mockUserRepo.AssertNotCalled(t, "SearchUsers", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// engagementReactionRepo serves reaction figures and records each batch query
+type engagementReactionRepo struct {
+	repository.ReactionRepository
+	mu          sync.Mutex
+	typeCounts  map[uuid.UUID][]*model.ReactionTypeCount
+	reacted     map[uuid.UUID]bool
+	typeCalls   int
+	viewerCalls int
+}
+
+func (r *engagementReactionRepo) CountByTypeByPostIDs(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID][]*model.ReactionTypeCount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.typeCalls++
+	counts := make(map[uuid.UUID][]*model.ReactionTypeCount)
+	for _, id := range postIDs {
+		if typeCounts, ok := r.typeCounts[id]; ok {
+			counts[id] = typeCounts
+		}
+	}
+	return counts, nil
+}
+
+func (r *engagementReactionRepo) ViewerReactedByPostIDs(ctx context.Context, viewerID uuid.UUID, postIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.viewerCalls++
+	reacted := make(map[uuid.UUID]bool)
+	for _, id := range postIDs {
+		reacted[id] = r.reacted[id]
+	}
+	return reacted, nil
+}
+
+func TestPostResolver_Engagement_BatchesPostList(t *testing.T) {
+	resolver, _, _, mockCommentRepo := setupTestResolver()
+	viewer := &model.User{ID: uuid.New()}
+	posts := []*model.Post{{ID: uuid.New()}, {ID: uuid.New()}, {ID: uuid.New()}, {ID: uuid.New()}}
+
+	reactions := &engagementReactionRepo{
+		typeCounts: map[uuid.UUID][]*model.ReactionTypeCount{
+			posts[0].ID: {{Type: "heart", Count: 2}, {Type: "like", Count: 3}},
+			posts[2].ID: {{Type: "like", Count: 1}},
+		},
+		reacted: map[uuid.UUID]bool{posts[2].ID: true},
+	}
+	resolver.ReactionRepo = reactions
+	mockCommentRepo.On("SummaryByPostIDs", mock.Anything, mock.Anything).
+		Return(map[uuid.UUID]*model.CommentSummary{posts[0].ID: {Count: 7}, posts[3].ID: {Count: 1}}, nil).Once()
+
+	loaders := dataloader.NewLoaders(&repository.Manager{Reaction: reactions, Comment: mockCommentRepo})
+	ctx := dataloader.WithLoaders(createAuthenticatedContext(viewer), loaders)
+
+	// Resolve every post's engagement concurrently, as sibling fields are
+	engagements := make([]*model.PostEngagement, len(posts))
+	errs := make([]error, len(posts))
+	var wg sync.WaitGroup
+	for i, post := range posts {
+		wg.Add(1)
+		go func(i int, post *model.Post) {
+			defer wg.Done()
+			engagements[i], errs[i] = (&postResolver{resolver}).Engagement(ctx, post)
+		}(i, post)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 5, engagements[0].ReactionCount)
+	assert.Equal(t, []*model.ReactionTypeCount{{Type: "heart", Count: 2}, {Type: "like", Count: 3}}, engagements[0].ReactionsByType)
+	assert.Equal(t, 7, engagements[0].CommentCount)
+	assert.False(t, engagements[0].ViewerHasReacted)
+
+	assert.Zero(t, engagements[1].ReactionCount)
+	assert.Empty(t, engagements[1].ReactionsByType)
+	assert.Zero(t, engagements[1].CommentCount)
+
+	assert.Equal(t, 1, engagements[2].ReactionCount)
+	assert.True(t, engagements[2].ViewerHasReacted)
+	assert.Equal(t, 1, engagements[3].CommentCount)
+
+	assert.Equal(t, 1, reactions.typeCalls)
+	assert.Equal(t, 1, reactions.viewerCalls)
+	mockCommentRepo.AssertNumberOfCalls(t, "SummaryByPostIDs", 1)
+}
+
+func TestMutationResolver_Impersonate(t *testing.T) {
+	admin := &model.User{ID: uuid.New()}
+	target := &model.User{ID: uuid.New(), Email: "user@example.com"}
+	targetID := relay.ToGlobalID(relay.TypeUser, target.ID)
+
+	setup := func() (*Resolver, *MockUserRepo, *[]security.AuditLog) {
+		resolver, mockUserRepo, _, _ := setupTestResolver()
+		entries := &[]security.AuditLog{}
+		resolver.AuditLogger = security.NewAuditLoggerWithSink(func(entry security.AuditLog) {
+			*entries = append(*entries, entry)
+		})
+		mockUserRepo.On("GetByID", mock.Anything, target.ID).Return(target, nil)
+		return resolver, mockUserRepo, entries
+	}
+
+	t.Run("non-admin is forbidden", func(t *testing.T) {
+		resolver, mockUserRepo, _ := setup()
+
+		_, err := (&mutationResolver{resolver}).Impersonate(createRoleContext(admin, security.RoleModerator), targetID)
+
+		assertForbidden(t, err)
+		mockUserRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("admin gets a short-lived token naming them", func(t *testing.T) {
+		resolver, _, entries := setup()
+		resolver.Impersonation = &Impersonation{Duration: 5 * time.Minute}
+
+		payload, err := (&mutationResolver{resolver}).Impersonate(createRoleContext(admin, security.RoleAdmin), targetID)
+		require.NoError(t, err)
+		assert.Same(t, target, payload.User)
+		assert.WithinDuration(t, time.Now().Add(5*time.Minute), payload.ExpiresAt, 2*time.Second)
+
+		claims, err := resolver.AuthManager.JWTService.ValidateToken(payload.Token)
+		require.NoError(t, err)
+		assert.Equal(t, target.ID, claims.UserID)
+		require.NotNil(t, claims.ImpersonatorID)
+		assert.Equal(t, admin.ID, *claims.ImpersonatorID)
+
+		require.Len(t, *entries, 1)
+		entry := (*entries)[0]
+		assert.Equal(t, "impersonate_start", entry.Action)
+		assert.Equal(t, admin.ID.String(), entry.UserID)
+		assert.Equal(t, target.ID.String(), entry.ResourceID)
+		assert.Equal(t, payload.ExpiresAt.Unix(), entry.Metadata["expires_at"])
+		assert.True(t, entry.Success)
+	})
+
+	t.Run("starts are rate limited per admin", func(t *testing.T) {
+		resolver, _, entries := setup()
+		now := time.Now()
+		resolver.Clock = func() time.Time { return now }
+		resolver.Impersonation = &Impersonation{
+			Store:    newMemoryCooldownStore(func() time.Time { return now }),
+			Cooldown: DefaultImpersonationCooldown,
+		}
+		ctx := createRoleContext(admin, security.RoleAdmin)
+
+		_, err := (&mutationResolver{resolver}).Impersonate(ctx, targetID)
+		require.NoError(t, err)
+
+		now = now.Add(20 * time.Second)
+		_, err = (&mutationResolver{resolver}).Impersonate(ctx, targetID)
+		gqlErr, ok := err.(*errors.GraphQLError)
+		require.True(t, ok, "expected GraphQLError, got %T", err)
+		assert.Equal(t, errors.ErrorCodeRateLimit, gqlErr.Code)
+		assert.Equal(t, 40, gqlErr.Extensions["retryAfter"])
+
+		require.Len(t, *entries, 2)
+		assert.False(t, (*entries)[1].Success)
+	})
+
+	t.Run("cannot impersonate from an impersonated session", func(t *testing.T) {
+		resolver, _, _ := setup()
+		otherAdmin := uuid.New()
+		ctx := context.WithValue(createRoleContext(admin, security.RoleAdmin), auth.ClaimsContextKey, &auth.JWTClaims{UserID: admin.ID, ImpersonatorID: &otherAdmin})
+
+		_, err := (&mutationResolver{resolver}).Impersonate(ctx, targetID)
+
+		assertForbidden(t, err)
+	})
+}
+
+func TestMutationResolver_StopImpersonation(t *testing.T) {
+	resolver, _, _, _ := setupTestResolver()
+	var entries []security.AuditLog
+	resolver.AuditLogger = security.NewAuditLoggerWithSink(func(entry security.AuditLog) {
+		entries = append(entries, entry)
+	})
+	adminID := uuid.New()
+	user := &model.User{ID: uuid.New()}
+
+	_, err := (&mutationResolver{resolver}).StopImpersonation(createAuthenticatedContext(user))
+	require.Error(t, err)
+
+	ctx := context.WithValue(createAuthenticatedContext(user), auth.ClaimsContextKey, &auth.JWTClaims{UserID: user.ID, ImpersonatorID: &adminID})
+	ctx = security.WithImpersonator(ctx, adminID.String())
+	stopped, err := (&mutationResolver{resolver}).StopImpersonation(ctx)
+
+	require.NoError(t, err)
+	assert.True(t, stopped)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "impersonate_stop", entries[0].Action)
+	assert.Equal(t, adminID.String(), entries[0].UserID)
+	assert.Equal(t, user.ID.String(), entries[0].ResourceID)
+	assert.Equal(t, adminID.String(), entries[0].Metadata["impersonator_id"])
+}
+
+func TestSubscriptionResolver_PostUpdated_ChecksPostVisibility(t *testing.T) {
+	author := &model.User{ID: uuid.New()}
+	draft := &model.Post{ID: uuid.New(), AuthorID: author.ID, Title: "Draft"}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		received bool
+	}{
+		{"anonymous", context.Background(), false},
+		{"other user", createAuthenticatedContext(&model.User{ID: uuid.New()}), false},
+		{"author", createAuthenticatedContext(author), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, _, mockPostRepo, _ := setupTestResolver()
+			resolver.SubManager = subscription.NewManager()
+			mockPostRepo.On("GetByID", mock.Anything, draft.ID).Return(draft, nil)
+
+			ctx, cancel := context.WithCancel(tt.ctx)
+			defer cancel()
+			updates, err := (&subscriptionResolver{resolver}).PostUpdated(ctx, relay.ToGlobalID(relay.TypePost, draft.ID))
+			require.NoError(t, err)
+
+			resolver.SubManager.PublishPostUpdated(draft)
+
+			select {
+			case post := <-updates:
+				assert.True(t, tt.received, "update of a draft reached a viewer who can't see it")
+				assert.Equal(t, draft.ID, post.ID)
+			case <-time.After(50 * time.Millisecond):
+				assert.False(t, tt.received, "expected the update")
+			}
+		})
+	}
+}
+
+func TestSubscriptionResolver_CommentAdded_CachesPostVisibility(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	now := time.Now()
+	resolver.Clock = func() time.Time { return now }
+	resolver.SubManager = subscription.NewManager()
+	resolver.SubscriptionVisibility = &PostVisibilityCache{TTL: DefaultSubscriptionVisibilityTTL}
+
+	author := &model.User{ID: uuid.New()}
+	viewer := &model.User{ID: uuid.New()}
+	post := &model.Post{ID: uuid.New(), AuthorID: author.ID, Published: true}
+	mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil).Once()
+
+	ctx, cancel := context.WithCancel(createAuthenticatedContext(viewer))
+	defer cancel()
+	comments, err := (&subscriptionResolver{resolver}).CommentAdded(ctx, relay.ToGlobalID(relay.TypePost, post.ID))
+	require.NoError(t, err)
+
+	publish := func() {
+		resolver.SubManager.PublishCommentAdded(&model.Comment{ID: uuid.New(), PostID: post.ID})
+	}
+	received := func() int {
+		count := 0
+		for {
+			select {
+			case <-comments:
+				count++
+			case <-time.After(50 * time.Millisecond):
+				return count
+			}
+		}
+	}
+
+	// Repeated events reuse the first load
+	for i := 0; i < 3; i++ {
+		publish()
+	}
+	assert.Equal(t, 3, received())
+	mockPostRepo.AssertNumberOfCalls(t, "GetByID", 1)
+
+	// Hiding the post drops the cached state, so the next event sees it
+	hidden := &model.Post{ID: post.ID, AuthorID: author.ID, Published: true, Hidden: true}
+	mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(hidden, nil).Once()
+	mockPostRepo.On("SetHidden", mock.Anything, post.ID, true, mock.Anything).Return(nil)
+	_, err = (&mutationResolver{resolver}).HidePost(createModeratorContext(&model.User{ID: uuid.New()}), post.ID.String(), "spam")
+	require.NoError(t, err)
+
+	mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(hidden, nil).Once()
+	publish()
+	publish()
+	assert.Equal(t, 0, received())
+	mockPostRepo.AssertNumberOfCalls(t, "GetByID", 3)
+
+	// Decisions expire after the TTL
+	now = now.Add(DefaultSubscriptionVisibilityTTL)
+	mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil).Once()
+	publish()
+	assert.Equal(t, 1, received())
+	mockPostRepo.AssertNumberOfCalls(t, "GetByID", 4)
+}
+
+func TestMutationResolver_UpdatePost_UnpublishInvalidatesPostVisibility(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	resolver.SubscriptionVisibility = &PostVisibilityCache{TTL: DefaultSubscriptionVisibilityTTL}
+	author := &model.User{ID: uuid.New()}
+	viewer := createAuthenticatedContext(&model.User{ID: uuid.New()})
+	post := &model.Post{ID: uuid.New(), AuthorID: author.ID, Published: true}
+	mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(&model.Post{ID: post.ID, AuthorID: author.ID, Published: true}, nil).Once()
+	require.True(t, resolver.subscriberCanSeePost(viewer, post.ID))
+
+	mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil).Once()
+	mockPostRepo.On("Update", mock.Anything, post).Return(nil)
+	unpublished := false
+	_, err := (&mutationResolver{resolver}).UpdatePost(createAuthenticatedContext(author), post.ID.String(), model.UpdatePostInput{Published: &unpublished}, nil)
+	require.NoError(t, err)
+
+	mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(&model.Post{ID: post.ID, AuthorID: author.ID}, nil).Once()
+	assert.False(t, resolver.subscriberCanSeePost(viewer, post.ID))
+}
+
+func TestPostVisibilityCache_ReturnsCopies(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	resolver.SubscriptionVisibility = &PostVisibilityCache{TTL: DefaultSubscriptionVisibilityTTL}
+	post := &model.Post{ID: uuid.New(), Published: true, Tags: []string{"go"}}
+	mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil).Once()
+
+	first, err := resolver.subscriptionPost(context.Background(), post.ID)
+	require.NoError(t, err)
+	first.Published = false
+	first.Tags[0] = "changed"
+	post.Hidden = true
+
+	second, err := resolver.subscriptionPost(context.Background(), post.ID)
+	require.NoError(t, err)
+	assert.True(t, second.Published)
+	assert.False(t, second.Hidden)
+	assert.Equal(t, []string{"go"}, second.Tags)
+	mockPostRepo.AssertNumberOfCalls(t, "GetByID", 1)
+}
+
+func TestPostVisibilityCache_EvictsWhenFull(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	now := time.Now()
+	resolver.Clock = func() time.Time { return now }
+	cache := &PostVisibilityCache{TTL: time.Minute, MaxEntries: 2}
+	resolver.SubscriptionVisibility = cache
+	load := func() uuid.UUID {
+		id := uuid.New()
+		mockPostRepo.On("GetByID", mock.Anything, id).Return(&model.Post{ID: id, Published: true}, nil).Once()
+		_, err := resolver.subscriptionPost(context.Background(), id)
+		require.NoError(t, err)
+		return id
+	}
+
+	expired := load()
+	now = now.Add(30 * time.Second)
+	fresh := load()
+	now = now.Add(45 * time.Second)
+	latest := load()
+
+	assert.Len(t, cache.entries, 2)
+	assert.NotContains(t, cache.entries, expired, "expired posts are evicted first")
+	assert.Contains(t, cache.entries, fresh)
+	assert.Contains(t, cache.entries, latest)
+
+	for i := 0; i < 3; i++ {
+		load()
+	}
+	assert.Len(t, cache.entries, 2)
+}
+
+func TestPostVisibilityCache_SharesConcurrentLoads(t *testing.T) {
+	resolver, _, mockPostRepo, _ := setupTestResolver()
+	resolver.SubscriptionVisibility = &PostVisibilityCache{TTL: DefaultSubscriptionVisibilityTTL}
+	post := &model.Post{ID: uuid.New(), Published: true}
+	release := make(chan time.Time)
+	mockPostRepo.On("GetByID", mock.Anything, post.ID).WaitUntil(release).Return(post, nil).Once()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := resolver.subscriptionPost(context.Background(), post.ID)
+			assert.NoError(t, err)
+		}()
+	}
+	// Another post loads meanwhile, so the pending load holds no lock
+	other := &model.Post{ID: uuid.New(), Published: true}
+	mockPostRepo.On("GetByID", mock.Anything, other.ID).Return(other, nil).Once()
+	_, err := resolver.subscriptionPost(context.Background(), other.ID)
+	require.NoError(t, err)
+
+	close(release)
+	wg.Wait()
+	mockPostRepo.AssertNumberOfCalls(t, "GetByID", 2)
+}
+
+func TestSubscriptionResolver_NotificationReceived(t *testing.T) {
+	resolver, _, mockPostRepo, mockCommentRepo := setupTestResolver()
+	resolver.SubManager = subscription.NewManager()
+
+	owner := &model.User{ID: uuid.New()}
+	commenter := &model.User{ID: uuid.New()}
+	bystander := &model.User{ID: uuid.New()}
+	post := &model.Post{ID: uuid.New(), AuthorID: owner.ID, Published: true}
+	mockPostRepo.On("Exists", mock.Anything, post.ID).Return(true, nil)
+	mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+	mockCommentRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Comment")).Return(nil)
+
+	subscribe := func(user *model.User) <-chan *model.Notification {
+		ctx, cancel := context.WithCancel(createAuthenticatedContext(user))
+		t.Cleanup(cancel)
+		notifications, err := (&subscriptionResolver{resolver}).NotificationReceived(ctx)
+		require.NoError(t, err)
+		return notifications
+	}
+	received := func(notifications <-chan *model.Notification) []model.NotificationKind {
+		kinds := []model.NotificationKind{}
+		for {
+			select {
+			case notification := <-notifications:
+				kinds = append(kinds, notification.Kind)
+			case <-time.After(50 * time.Millisecond):
+				return kinds
+			}
+		}
+	}
+	ownerCh, commenterCh, bystanderCh := subscribe(owner), subscribe(commenter), subscribe(bystander)
+
+	t.Run("comments notify the post author only", func(t *testing.T) {
+		_, err := (&mutationResolver{resolver}).AddComment(createAuthenticatedContext(commenter), post.ID.String(), "Nice post", nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, []model.NotificationKind{model.NotificationKindCommentOnPost}, received(ownerCh))
+		assert.Empty(t, received(commenterCh))
+		assert.Empty(t, received(bystanderCh))
+	})
+
+	t.Run("replies also notify the parent's author", func(t *testing.T) {
+		parent := &model.Comment{ID: uuid.New(), PostID: post.ID, AuthorID: commenter.ID}
+		mockCommentRepo.On("GetByID", mock.Anything, parent.ID).Return(parent, nil)
+		parentID := relay.ToGlobalID(relay.TypeComment, parent.ID)
+
+		_, err := (&mutationResolver{resolver}).AddComment(createAuthenticatedContext(bystander), post.ID.String(), "Agreed", &parentID)
+		require.NoError(t, err)
+
+		assert.Equal(t, []model.NotificationKind{model.NotificationKindCommentOnPost}, received(ownerCh))
+		assert.Equal(t, []model.NotificationKind{model.NotificationKindReplyToComment}, received(commenterCh))
+		assert.Empty(t, received(bystanderCh))
+	})
+
+	t.Run("misrouted notifications are not delivered", func(t *testing.T) {
+		resolver.SubManager.Publish(&subscription.Event{
+			Type:         subscription.NotificationEvent,
+			UserID:       owner.ID.String(),
+			Notification: &model.Notification{UserID: bystander.ID, Kind: model.NotificationKindCommentOnPost},
+		})
+
+		assert.Empty(t, received(ownerCh))
+		assert.Empty(t, received(bystanderCh))
+	})
+
+	t.Run("requires authentication", func(t *testing.T) {
+		_, err := (&subscriptionResolver{resolver}).NotificationReceived(context.Background())
+
+		require.Error(t, err)
+		gqlErr, ok := err.(*errors.GraphQLError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeUnauthenticated, gqlErr.Code)
+	})
+}
+
+func TestQueryResolver_PopularTags(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+	tags := []*model.TagCount{{Tag: "go", Count: 4}, {Tag: "rust", Count: 2}}
+
+	t.Run("defaults to the first page", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		mockPostRepo.On("PopularTags", mock.Anything, validation.DefaultPopularTagsLimit, 0).Return(tags, nil)
+
+		result, err := (&queryResolver{resolver}).PopularTags(context.Background(), nil, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, tags, result)
+	})
+
+	t.Run("passes limit and offset through", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		mockPostRepo.On("PopularTags", mock.Anything, 5, 10).Return(tags, nil)
+
+		_, err := (&queryResolver{resolver}).PopularTags(context.Background(), intPtr(5), intPtr(10))
+
+		require.NoError(t, err)
+		mockPostRepo.AssertExpectations(t)
+	})
+
+	t.Run("guards limit and offset", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		resolver.MaxPopularTags = 50
+
+		tests := []struct {
+			limit, offset *int
+			field         string
+		}{
+			{limit: intPtr(51), field: "limit"},
+			{limit: intPtr(0), field: "limit"},
+			{offset: intPtr(-1), field: "offset"},
+			{offset: intPtr(validation.MaxPopularTagsOffset + 1), field: "offset"},
+		}
+		for _, tt := range tests {
+			_, err := (&queryResolver{resolver}).PopularTags(context.Background(), tt.limit, tt.offset)
+
+			gqlErr, ok := err.(*errors.GraphQLError)
+			require.True(t, ok, "expected GraphQLError, got %T", err)
+			assert.Equal(t, errors.ErrorCodeValidation, gqlErr.Code)
+			assert.Equal(t, tt.field, gqlErr.Field)
+		}
+		mockPostRepo.AssertNotCalled(t, "PopularTags", mock.Anything, mock.Anything, mock.Anything)
+
+		mockPostRepo.On("PopularTags", mock.Anything, 50, 0).Return(tags, nil)
+		_, err := (&queryResolver{resolver}).PopularTags(context.Background(), intPtr(50), nil)
+		require.NoError(t, err)
+	})
+}
+
+// validationFields returns the fields a combined validation error reports
+func validationFields(t *testing.T, err error) []string {
+	t.Helper()
+	gqlErr, ok := err.(*errors.GraphQLError)
+	require.True(t, ok, "expected GraphQLError, got %T", err)
+	require.Equal(t, errors.ErrorCodeValidation, gqlErr.Code)
+
+	fields := []string{}
+	for _, field := range gqlErr.Extensions["fields"].([]map[string]interface{}) {
+		fields = append(fields, field["field"].(string))
+	}
+	return fields
+}
+
+func TestMutationResolver_ValidateOnly(t *testing.T) {
+	validateOnly := true
+	user := &model.User{ID: uuid.New()}
+
+	t.Run("createPost reports every invalid field", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+
+		_, err := (&mutationResolver{resolver}).CreatePost(createAuthenticatedContext(user), model.CreatePostInput{
+			Title:   "Hi",
+			Content: "Short",
+			Tags:    []string{"bad tag!"},
+		}, &validateOnly)
+
+		assert.Equal(t, []string{"title", "content", "tags"}, validationFields(t, err))
+		mockPostRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("createPost with valid input creates nothing", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		resolver.SubManager = subscription.NewManager()
+		events := resolver.SubManager.Subscribe(context.Background(), "test", subscription.PostAddedEvent, nil)
+
+		result, err := (&mutationResolver{resolver}).CreatePost(createAuthenticatedContext(user), model.CreatePostInput{
+			Title:   " Valid title ",
+			Content: "Content that is long enough",
+			Tags:    []string{"go"},
+		}, &validateOnly)
+
+		require.NoError(t, err)
+		assert.Equal(t, &model.PostPreview{
+			Title:   "Valid title",
+			Content: "Content that is long enough",
+			Tags:    []string{"go"},
+		}, result)
+		mockPostRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		assert.Empty(t, events)
+	})
+
+	t.Run("updatePost checks without saving", func(t *testing.T) {
+		resolver, _, mockPostRepo, _ := setupTestResolver()
+		post := &model.Post{ID: uuid.New(), Title: "Original", Content: "Original body", AuthorID: user.ID}
+		mockPostRepo.On("GetByID", mock.Anything, post.ID).Return(post, nil)
+		short, valid := "No", "Updated title"
+
+		_, err := (&mutationResolver{resolver}).UpdatePost(createAuthenticatedContext(user), post.ID.String(), model.UpdatePostInput{Title: &short}, &validateOnly)
+		gqlErr, ok := err.(*errors.GraphQLError)
+		require.True(t, ok, "expected GraphQLError, got %T", err)
+		assert.Equal(t, "title", gqlErr.Field)
+
+		updated, err := (&mutationResolver{resolver}).UpdatePost(createAuthenticatedContext(user), post.ID.String(), model.UpdatePostInput{Title: &valid}, &validateOnly)
+		require.NoError(t, err)
+		assert.Equal(t, valid, updated.Title)
+		mockPostRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("register reports every invalid field", func(t *testing.T) {
+		resolver, mockUserRepo, _, _ := setupTestResolver()
+
+		_, err := (&mutationResolver{resolver}).Register(context.Background(), "not-an-email", "short", "X", &validateOnly)
+
+		assert.Equal(t, []string{"email", "name", "password"}, validationFields(t, err))
+		mockUserRepo.AssertNotCalled(t, "ExistsByEmail", mock.Anything, mock.Anything)
+	})
+
+	t.Run("register checks the email is free", func(t *testing.T) {
+		resolver, mockUserRepo, _, _ := setupTestResolver()
+		mockUserRepo.On("ExistsByEmail", mock.Anything, "taken@example.com").Return(true, nil)
+
+		_, err := (&mutationResolver{resolver}).Register(context.Background(), "taken@example.com", "password123", "Test User", &validateOnly)
+
+		gqlErr, ok := err.(*errors.GraphQLError)
+		require.True(t, ok, "expected GraphQLError, got %T", err)
+		assert.Equal(t, errors.ErrorCodeAlreadyExists, gqlErr.Code)
+	})
+
+	t.Run("register with valid input creates no user", func(t *testing.T) {
+		resolver, mockUserRepo, _, _ := setupTestResolver()
+		mockUserRepo.On("ExistsByEmail", mock.Anything, "new@example.com").Return(false, nil)
+
+		result, err := (&mutationResolver{resolver}).Register(context.Background(), " New@Example.com ", "password123", "Test User", &validateOnly)
+
+		require.NoError(t, err)
+		assert.Equal(t, &model.RegistrationPreview{Email: "new@example.com", Name: "Test User"}, result)
+		mockUserRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
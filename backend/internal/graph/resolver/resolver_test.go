@@ -18,7 +18,7 @@ func TestQueryResolver_Me(t *testing.T) {
 		t.Fatal("Expected authentication error, got nil")
 	}
 
-	if !strings.Contains(err.Error(), "authentication required") {
+	if !strings.Contains(err.Error(), "Authentication required") {
 		t.Errorf("Expected authentication error, got %v", err)
 	}
 }
@@ -34,12 +34,12 @@ func TestMutationResolver_CreatePost(t *testing.T) {
 	}
 
 	// Test without authentication - should fail
-	_, err := mutationResolver.CreatePost(context.Background(), input)
+	_, err := mutationResolver.CreatePost(context.Background(), input, nil)
 	if err == nil {
 		t.Fatal("Expected authentication error, got nil")
 	}
 
-	if !strings.Contains(err.Error(), "authentication required") {
+	if !strings.Contains(err.Error(), "Authentication required") {
 		t.Errorf("Expected authentication error, got %v", err)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,78 @@
+package resolver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"backend/internal/graph/errors"
+	"backend/internal/graph/model"
+)
+
+// DefaultStatsTTL is how long dashboard totals are reused before counting again
+const DefaultStatsTTL = 30 * time.Second
+
+// StatsCache reuses the last dashboard totals for TTL, so a dashboard polled
+// by several admins doesn't recount every table on each load
+type StatsCache struct {
+	TTL time.Duration
+
+	mu      sync.Mutex
+	stats   *model.Stats
+	expires time.Time
+}
+
+// dashboardStats returns the cached totals while they are fresh, counting
+// them again otherwise. Callers wait on a running count instead of starting
+// their own.
+func (r *Resolver) dashboardStats(ctx context.Context) (*model.Stats, error) {
+	cache := r.StatsCache
+	if cache == nil || cache.TTL <= 0 {
+		return r.countStats(ctx)
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	now := r.now()
+	if cache.stats == nil || !now.Before(cache.expires) {
+		stats, err := r.countStats(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cache.stats = stats
+		cache.expires = now.Add(cache.TTL)
+	}
+
+	stats := *cache.stats
+	return &stats, nil
+}
+
+// countStats counts the dashboard totals
+func (r *Resolver) countStats(ctx context.Context) (*model.Stats, error) {
+	users, err := r.UserRepo.Count(ctx)
+	if err != nil {
+		return nil, errors.WrapDatabaseError(err, "user count")
+	}
+	newUsers, err := r.UserRepo.CountCreatedSince(ctx, r.now().Add(-24*time.Hour))
+	if err != nil {
+		return nil, errors.WrapDatabaseError(err, "user count")
+	}
+	published, unpublished, err := r.PostRepo.CountByPublished(ctx)
+	if err != nil {
+		return nil, errors.WrapDatabaseError(err, "post count")
+	}
+	comments, err := r.CommentRepo.CountAll(ctx)
+	if err != nil {
+		return nil, errors.WrapDatabaseError(err, "comment count")
+	}
+
+	return &model.Stats{
+		TotalUsers:       users,
+		TotalPosts:       published + unpublished,
+		PublishedPosts:   published,
+		UnpublishedPosts: unpublished,
+		TotalComments:    comments,
+		NewUsersLast24h:  newUsers,
+	}, nil
+}
@@ -8,8 +8,11 @@ import (
 	"context"
 	"fmt"
 
+	"backend/internal/auth"
+	"backend/internal/graph/errors"
 	"backend/internal/graph/generated"
 	"backend/internal/graph/model"
+	"backend/internal/graph/relay"
 	"backend/internal/subscription"
 	"github.com/google/uuid"
 )
@@ -19,13 +22,8 @@ func (r *subscriptionResolver) PostAdded(ctx context.Context) (<-chan *model.Pos
 	// Generate unique subscriber ID
 	subscriberID := fmt.Sprintf("post_added_%s", uuid.New().String())
 	
-	// Create filter for post added events
-	filter := func(event *subscription.Event) bool {
-		return event.Type == subscription.PostAddedEvent
-	}
-	
-	// Subscribe to events
-	eventCh := r.SubManager.Subscribe(ctx, subscriberID, filter)
+	// Subscribe to post added events
+	eventCh := r.SubManager.Subscribe(ctx, subscriberID, subscription.PostAddedEvent, nil)
 	
 	// Create output channel
 	postCh := make(chan *model.Post, 10)
@@ -57,16 +55,23 @@ func (r *subscriptionResolver) PostAdded(ctx context.Context) (<-chan *model.Pos
 
 // PostUpdated is the resolver for the postUpdated field.
 func (r *subscriptionResolver) PostUpdated(ctx context.Context, id string) (<-chan *model.Post, error) {
+	// Events carry raw post IDs
+	postID, err := relay.ParseID(id, relay.TypePost)
+	if err != nil {
+		return nil, fmt.Errorf("invalid post ID: %w", err)
+	}
+
 	// Generate unique subscriber ID
-	subscriberID := fmt.Sprintf("post_updated_%s_%s", id, uuid.New().String())
+	subscriberID := fmt.Sprintf("post_updated_%s_%s", postID, uuid.New().String())
 	
-	// Create filter for specific post updates
+	// Create filter for specific post updates, dropping them while the
+	// subscriber can't see the post
 	filter := func(event *subscription.Event) bool {
-		return event.Type == subscription.PostUpdatedEvent && event.PostID == id
+		return event.PostID == postID.String() && r.subscriberCanSeePost(ctx, postID)
 	}
 	
 	// Subscribe to events
-	eventCh := r.SubManager.Subscribe(ctx, subscriberID, filter)
+	eventCh := r.SubManager.Subscribe(ctx, subscriberID, subscription.PostUpdatedEvent, filter)
 	
 	// Create output channel
 	postCh := make(chan *model.Post, 10)
@@ -98,16 +103,23 @@ func (r *subscriptionResolver) PostUpdated(ctx context.Context, id string) (<-ch
 
 // CommentAdded is the resolver for the commentAdded field.
 func (r *subscriptionResolver) CommentAdded(ctx context.Context, postID string) (<-chan *model.Comment, error) {
+	// Events carry raw post IDs
+	postUUID, err := relay.ParseID(postID, relay.TypePost)
+	if err != nil {
+		return nil, fmt.Errorf("invalid post ID: %w", err)
+	}
+
 	// Generate unique subscriber ID
-	subscriberID := fmt.Sprintf("comment_added_%s_%s", postID, uuid.New().String())
+	subscriberID := fmt.Sprintf("comment_added_%s_%s", postUUID, uuid.New().String())
 	
-	// Create filter for comments on specific post
+	// Create filter for comments on specific post, dropping them while the
+	// subscriber can't see the post
 	filter := func(event *subscription.Event) bool {
-		return event.Type == subscription.CommentAddedEvent && event.PostID == postID
+		return event.PostID == postUUID.String() && r.subscriberCanSeePost(ctx, postUUID)
 	}
 	
 	// Subscribe to events
-	eventCh := r.SubManager.Subscribe(ctx, subscriberID, filter)
+	eventCh := r.SubManager.Subscribe(ctx, subscriberID, subscription.CommentAddedEvent, filter)
 	
 	// Create output channel
 	commentCh := make(chan *model.Comment, 10)
@@ -137,6 +149,54 @@ func (r *subscriptionResolver) CommentAdded(ctx context.Context, postID string)
 	return commentCh, nil
 }
 
+// NotificationReceived is the resolver for the notificationReceived field.
+func (r *subscriptionResolver) NotificationReceived(ctx context.Context) (<-chan *model.Notification, error) {
+	// Require authentication, given at connection init over WebSockets
+	user, err := auth.RequireUser(ctx)
+	if err != nil {
+		return nil, errors.NewUnauthenticatedError("Authentication required to receive notifications")
+	}
+
+	// Generate unique subscriber ID
+	subscriberID := fmt.Sprintf("notification_%s_%s", user.ID, uuid.New().String())
+
+	// Create filter for the subscriber's own notifications
+	filter := func(event *subscription.Event) bool {
+		return event.UserID == user.ID.String()
+	}
+
+	// Subscribe to events
+	eventCh := r.SubManager.Subscribe(ctx, subscriberID, subscription.NotificationEvent, filter)
+
+	// Create output channel
+	notificationCh := make(chan *model.Notification, 10)
+
+	// Convert events to notifications, checking the recipient again so a
+	// wrong filter or routing key can't leak another user's notifications
+	go func() {
+		defer close(notificationCh)
+		for {
+			select {
+			case event, ok := <-eventCh:
+				if !ok {
+					return
+				}
+				if event.Notification != nil && event.Notification.UserID == user.ID {
+					select {
+					case notificationCh <- event.Notification:
+					case <-ctx.Done():
+						return
+					}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return notificationCh, nil
+}
+
 // Subscription returns generated.SubscriptionResolver implementation.
 func (r *Resolver) Subscription() generated.SubscriptionResolver { return &subscriptionResolver{r} }
 
@@ -0,0 +1,166 @@
+package resolver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"backend/internal/graph/model"
+	"github.com/google/uuid"
+)
+
+// DefaultSubscriptionVisibilityTTL is how long a post's visibility is trusted
+// by its subscribers before it is loaded again
+const DefaultSubscriptionVisibilityTTL = 30 * time.Second
+
+// DefaultSubscriptionVisibilityMaxEntries bounds how many posts the
+// visibility cache holds when MaxEntries is not set
+const DefaultSubscriptionVisibilityMaxEntries = 10000
+
+// PostVisibilityCache keeps the posts that subscriptions authorize events
+// against for TTL, so each event on a busy post doesn't load the post again.
+// All subscribers to a post share one load, while whether each of them may
+// see it is still decided with their own viewer. Publishing, unpublishing,
+// hiding or deleting a post drops it at once. Expired posts are evicted once
+// the cache reaches MaxEntries.
+type PostVisibilityCache struct {
+	TTL        time.Duration
+	MaxEntries int
+
+	mu      sync.Mutex
+	entries map[uuid.UUID]postVisibility
+	loads   map[uuid.UUID]*postLoad
+	// generation counts invalidations, so a load that raced one is not cached
+	generation uint64
+}
+
+// postVisibility is the state of a post that decides who may see it
+type postVisibility struct {
+	post    *model.Post
+	expires time.Time
+}
+
+// postLoad is one load of a post shared by every subscriber waiting on it
+type postLoad struct {
+	done chan struct{}
+	post *model.Post
+	err  error
+}
+
+// Invalidate drops the cached state of a post
+func (c *PostVisibilityCache) Invalidate(postID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, postID)
+	c.generation++
+}
+
+// store caches post until expires unless an invalidation happened since the
+// load began at generation, evicting expired entries when the cache is full
+func (c *PostVisibilityCache) store(postID uuid.UUID, post *model.Post, generation uint64, now, expires time.Time) {
+	if generation != c.generation {
+		return
+	}
+	if c.entries == nil {
+		c.entries = make(map[uuid.UUID]postVisibility)
+	}
+
+	maxEntries := c.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = DefaultSubscriptionVisibilityMaxEntries
+	}
+	if len(c.entries) >= maxEntries {
+		for id, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, id)
+			}
+		}
+	}
+	// Still full of fresh posts: make room by dropping any one of them
+	for id := range c.entries {
+		if len(c.entries) < maxEntries {
+			break
+		}
+		delete(c.entries, id)
+	}
+
+	c.entries[postID] = postVisibility{post: post, expires: expires}
+}
+
+// subscriberCanSeePost reports whether the subscriber whose context is ctx
+// may receive events about a post. Posts that fail to load count as not
+// visible and are not cached, so the next event tries again.
+func (r *Resolver) subscriberCanSeePost(ctx context.Context, postID uuid.UUID) bool {
+	post, err := r.subscriptionPost(ctx, postID)
+	if err != nil {
+		return false
+	}
+	return canSeePost(ctx, post)
+}
+
+// subscriptionPost loads a post for subscription authorization, reusing the
+// cached copy while it is fresh. Concurrent misses share one load, made
+// without holding the cache lock, and every caller gets its own copy.
+func (r *Resolver) subscriptionPost(ctx context.Context, postID uuid.UUID) (*model.Post, error) {
+	cache := r.SubscriptionVisibility
+	if cache == nil || cache.TTL <= 0 {
+		return r.PostRepo.GetByID(ctx, postID)
+	}
+
+	cache.mu.Lock()
+	now := r.now()
+	if entry, ok := cache.entries[postID]; ok && now.Before(entry.expires) {
+		cache.mu.Unlock()
+		return copyPost(entry.post), nil
+	}
+	if load, ok := cache.loads[postID]; ok {
+		cache.mu.Unlock()
+		<-load.done
+		if load.err != nil {
+			return nil, load.err
+		}
+		return copyPost(load.post), nil
+	}
+
+	load := &postLoad{done: make(chan struct{})}
+	if cache.loads == nil {
+		cache.loads = make(map[uuid.UUID]*postLoad)
+	}
+	cache.loads[postID] = load
+	generation := cache.generation
+	cache.mu.Unlock()
+
+	load.post, load.err = r.PostRepo.GetByID(ctx, postID)
+	if load.err == nil {
+		// Keep a copy, since callers may change the post they were given
+		load.post = copyPost(load.post)
+	}
+
+	cache.mu.Lock()
+	delete(cache.loads, postID)
+	if load.err == nil {
+		cache.store(postID, load.post, generation, now, now.Add(cache.TTL))
+	}
+	cache.mu.Unlock()
+	close(load.done)
+
+	if load.err != nil {
+		return nil, load.err
+	}
+	return copyPost(load.post), nil
+}
+
+// copyPost returns a shallow copy of post with its own tags
+func copyPost(post *model.Post) *model.Post {
+	copied := *post
+	copied.Tags = append([]string(nil), post.Tags...)
+	return &copied
+}
+
+// invalidatePostVisibility drops a post whose visibility changed from the
+// subscription cache
+func (r *Resolver) invalidatePostVisibility(postID uuid.UUID) {
+	if r.SubscriptionVisibility != nil {
+		r.SubscriptionVisibility.Invalidate(postID)
+	}
+}
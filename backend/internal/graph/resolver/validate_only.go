@@ -0,0 +1,34 @@
+package resolver
+
+import (
+	"context"
+
+	"backend/internal/graph/errors"
+	"backend/internal/graph/model"
+)
+
+// validationResult turns the problems found with a mutation's input into its
+// error. A validateOnly request gets every problem at once so a form can flag
+// all its invalid fields; otherwise the first is returned.
+func validationResult(errs []error, validateOnly bool) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	if validateOnly {
+		return errors.WrapValidationErrors(errs)
+	}
+	return errs[0]
+}
+
+// validateRegistration finishes a validateOnly registration by checking that
+// the email is free, and previews the account that would be created
+func (r *mutationResolver) validateRegistration(ctx context.Context, input model.CreateUserInput) (*model.RegistrationPreview, error) {
+	exists, err := r.UserRepo.ExistsByEmail(ctx, input.Email)
+	if err != nil {
+		return nil, errors.WrapDatabaseError(err, "user lookup")
+	}
+	if exists {
+		return nil, errors.NewAlreadyExistsError("User with this email")
+	}
+	return &model.RegistrationPreview{Email: input.Email, Name: input.Name}, nil
+}
@@ -9,18 +9,27 @@ import (
 	"github.com/99designs/gqlgen/graphql"
 )
 
-// MarshalDateTime marshals a time.Time to RFC3339 format
+// MarshalDateTime marshals a time.Time to RFC3339 format in UTC
 func MarshalDateTime(t time.Time) graphql.Marshaler {
 	if t.IsZero() {
 		return graphql.Null
 	}
 	return graphql.WriterFunc(func(w io.Writer) {
-		io.WriteString(w, strconv.Quote(t.Format(time.RFC3339)))
+		io.WriteString(w, strconv.Quote(t.UTC().Format(time.RFC3339)))
 	})
 }
 
-// UnmarshalDateTime unmarshals a datetime string with validation
+// UnmarshalDateTime unmarshals a datetime string with validation, coercing it to UTC
 func UnmarshalDateTime(v interface{}) (time.Time, error) {
+	t, err := unmarshalDateTime(v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
+
+// unmarshalDateTime parses a datetime in whatever zone it was given
+func unmarshalDateTime(v interface{}) (time.Time, error) {
 	switch v := v.(type) {
 	case string:
 		return parseDateTime(v)
@@ -1,6 +1,7 @@
 package scalars
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -295,4 +296,25 @@ func TestValidateDateTimeRange(t *testing.T) {
 // Helper function to create string pointers
 func stringPtr(s string) *string {
 	return &s
-}
\ No newline at end of file
+}
+func TestMarshalDateTime_SerializesUTC(t *testing.T) {
+	local := time.Date(2024, 3, 10, 18, 30, 0, 0, time.FixedZone("UTC+9", 9*60*60))
+
+	var buf strings.Builder
+	MarshalDateTime(local).MarshalGQL(&buf)
+
+	assert.Equal(t, `"2024-03-10T09:30:00Z"`, buf.String())
+}
+
+func TestUnmarshalDateTime_CoercesToUTC(t *testing.T) {
+	serverZone := time.Local
+	time.Local = time.FixedZone("UTC-5", -5*60*60)
+	defer func() { time.Local = serverZone }()
+
+	for _, input := range []interface{}{"2024-03-10T18:30:00+09:00", "2024-03-10 09:30:00", int64(1710063000)} {
+		parsed, err := UnmarshalDateTime(input)
+		assert.NoError(t, err)
+		assert.Equal(t, time.UTC, parsed.Location(), "input %v", input)
+		assert.True(t, parsed.Equal(time.Date(2024, 3, 10, 9, 30, 0, 0, time.UTC)), "input %v parsed as %v", input, parsed)
+	}
+}
@@ -0,0 +1,72 @@
+package validation
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+
+	"backend/internal/envconfig"
+	"backend/internal/graph/errors"
+)
+
+// AvatarImageLimits bounds the dimensions of uploaded avatar images. Small
+// files can declare huge dimensions, so they are checked before any pixels
+// are decoded.
+type AvatarImageLimits struct {
+	MaxWidth  int
+	MaxHeight int
+	MaxPixels int
+}
+
+// DefaultAvatarImageLimits returns the limits used when none are configured
+func DefaultAvatarImageLimits() AvatarImageLimits {
+	return AvatarImageLimits{
+		MaxWidth:  4096,
+		MaxHeight: 4096,
+		MaxPixels: 4096 * 4096,
+	}
+}
+
+// AvatarImageLimitsFromEnv returns the default avatar limits overridden by
+// AVATAR_MAX_WIDTH, AVATAR_MAX_HEIGHT and AVATAR_MAX_PIXELS
+func AvatarImageLimitsFromEnv() AvatarImageLimits {
+	limits := DefaultAvatarImageLimits()
+	limits.MaxWidth = envconfig.PositiveInt("AVATAR_MAX_WIDTH", limits.MaxWidth)
+	limits.MaxHeight = envconfig.PositiveInt("AVATAR_MAX_HEIGHT", limits.MaxHeight)
+	limits.MaxPixels = envconfig.PositiveInt("AVATAR_MAX_PIXELS", limits.MaxPixels)
+	return limits
+}
+
+// WithAvatarLimits makes the validator enforce the given avatar image limits
+func (v *Validator) WithAvatarLimits(limits AvatarImageLimits) *Validator {
+	v.avatars = limits
+	return v
+}
+
+// ValidateAvatarImage checks the dimensions of an uploaded avatar from its
+// header alone and returns the image format. Only PNG, JPEG and GIF images
+// are accepted. The reader is consumed, so callers that keep the upload
+// should pass a copy, e.g. through io.TeeReader.
+func (v *Validator) ValidateAvatarImage(r io.Reader) (string, error) {
+	config, format, err := image.DecodeConfig(r)
+	if err != nil {
+		return "", errors.NewValidationError("Avatar must be a PNG, JPEG or GIF image", "avatar")
+	}
+
+	if config.Width <= 0 || config.Height <= 0 {
+		return "", errors.NewValidationError("Avatar image has invalid dimensions", "avatar")
+	}
+
+	if config.Width > v.avatars.MaxWidth || config.Height > v.avatars.MaxHeight {
+		return "", errors.NewValidationError(fmt.Sprintf("Avatar image cannot exceed %dx%d pixels", v.avatars.MaxWidth, v.avatars.MaxHeight), "avatar")
+	}
+
+	if int64(config.Width)*int64(config.Height) > int64(v.avatars.MaxPixels) {
+		return "", errors.NewValidationError(fmt.Sprintf("Avatar image cannot exceed %d pixels", v.avatars.MaxPixels), "avatar")
+	}
+
+	return format, nil
+}
@@ -0,0 +1,92 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"backend/internal/graph/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pngHeader returns just a PNG signature and IHDR chunk declaring the given
+// dimensions; a few dozen bytes that would decode to a huge image
+func pngHeader(width, height uint32) []byte {
+	data := make([]byte, 13)
+	binary.BigEndian.PutUint32(data[0:4], width)
+	binary.BigEndian.PutUint32(data[4:8], height)
+	data[8] = 8 // bit depth
+	data[9] = 6 // RGBA
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(&buf, binary.BigEndian, uint32(len(data)))
+	chunk := append([]byte("IHDR"), data...)
+	buf.Write(chunk)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func assertAvatarRejected(t *testing.T, err error, message string) {
+	require.Error(t, err)
+	gqlErr, ok := err.(*errors.GraphQLError)
+	require.True(t, ok)
+	assert.Equal(t, errors.ErrorCodeValidation, gqlErr.Code)
+	assert.Equal(t, "avatar", gqlErr.Field)
+	assert.Equal(t, message, gqlErr.Message)
+}
+
+func TestValidator_ValidateAvatarImage_AcceptsNormalImage(t *testing.T) {
+	format, err := NewValidator().ValidateAvatarImage(bytes.NewReader(encodePNG(t, 256, 256)))
+	require.NoError(t, err)
+	assert.Equal(t, "png", format)
+}
+
+func TestValidator_ValidateAvatarImage_RejectsHugeDimensions(t *testing.T) {
+	header := pngHeader(30000, 30000)
+	require.Less(t, len(header), 64)
+
+	_, err := NewValidator().ValidateAvatarImage(bytes.NewReader(header))
+	assertAvatarRejected(t, err, "Avatar image cannot exceed 4096x4096 pixels")
+}
+
+func TestValidator_ValidateAvatarImage_RejectsPixelCount(t *testing.T) {
+	validator := NewValidator().WithAvatarLimits(AvatarImageLimits{MaxWidth: 1000, MaxHeight: 1000, MaxPixels: 100000})
+
+	_, err := validator.ValidateAvatarImage(bytes.NewReader(pngHeader(1000, 200)))
+	assertAvatarRejected(t, err, "Avatar image cannot exceed 100000 pixels")
+
+	_, err = validator.ValidateAvatarImage(bytes.NewReader(pngHeader(500, 200)))
+	assert.NoError(t, err)
+}
+
+func TestValidator_ValidateAvatarImage_RejectsNonImages(t *testing.T) {
+	_, err := NewValidator().ValidateAvatarImage(strings.NewReader("<svg xmlns=\"http://www.w3.org/2000/svg\"/>"))
+	assertAvatarRejected(t, err, "Avatar must be a PNG, JPEG or GIF image")
+
+	_, err = NewValidator().ValidateAvatarImage(bytes.NewReader(pngHeader(0, 10)))
+	require.Error(t, err)
+}
+
+func TestAvatarImageLimitsFromEnv(t *testing.T) {
+	t.Setenv("AVATAR_MAX_WIDTH", "")
+	t.Setenv("AVATAR_MAX_HEIGHT", "")
+	t.Setenv("AVATAR_MAX_PIXELS", "")
+	assert.Equal(t, DefaultAvatarImageLimits(), AvatarImageLimitsFromEnv())
+
+	t.Setenv("AVATAR_MAX_WIDTH", "512")
+	t.Setenv("AVATAR_MAX_HEIGHT", "nope")
+	t.Setenv("AVATAR_MAX_PIXELS", "200000")
+	assert.Equal(t, AvatarImageLimits{MaxWidth: 512, MaxHeight: 4096, MaxPixels: 200000}, AvatarImageLimitsFromEnv())
+}
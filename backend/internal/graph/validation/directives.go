@@ -0,0 +1,167 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+
+	"backend/internal/graph/errors"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Schema directives enforced by InputConstraints:
+//
+//	directive @length(min: Int, max: Int) on ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION
+//	directive @pattern(regex: String!) on ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION
+const (
+	lengthDirective  = "length"
+	patternDirective = "pattern"
+)
+
+// InputConstraints enforces @length and @pattern directives declared on
+// field arguments and input object fields, so simple constraints live in the
+// schema. Lengths count characters; on list types a constraint applies to
+// each element. The Validator remains responsible for cross-field rules.
+type InputConstraints struct {
+	schema   *ast.Schema
+	patterns map[string]*regexp.Regexp
+}
+
+// NewInputConstraints creates a new input constraints extension
+func NewInputConstraints() *InputConstraints {
+	return &InputConstraints{patterns: make(map[string]*regexp.Regexp)}
+}
+
+// ExtensionName returns the name of this extension
+func (c *InputConstraints) ExtensionName() string {
+	return "InputConstraints"
+}
+
+// Validate compiles every @pattern in the schema so a bad regex fails at startup
+func (c *InputConstraints) Validate(schema graphql.ExecutableSchema) error {
+	return c.load(schema.Schema())
+}
+
+// load records the schema and compiles the patterns it declares
+func (c *InputConstraints) load(schema *ast.Schema) error {
+	c.schema = schema
+
+	compile := func(directives ast.DirectiveList) error {
+		for _, directive := range directives.ForNames(patternDirective) {
+			regex, _ := directive.ArgumentMap(nil)["regex"].(string)
+			compiled, err := regexp.Compile(regex)
+			if err != nil {
+				return fmt.Errorf("invalid @pattern %q: %w", regex, err)
+			}
+			c.patterns[regex] = compiled
+		}
+		return nil
+	}
+
+	for _, def := range schema.Types {
+		for _, field := range def.Fields {
+			if err := compile(field.Directives); err != nil {
+				return err
+			}
+			for _, arg := range field.Arguments {
+				if err := compile(arg.Directives); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// InterceptField checks a field's arguments before it is resolved
+func (c *InputConstraints) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || fc.Field.Field == nil || fc.Field.Definition == nil || len(fc.Field.Definition.Arguments) == 0 {
+		return next(ctx)
+	}
+
+	var variables map[string]interface{}
+	if graphql.HasOperationContext(ctx) {
+		variables = graphql.GetOperationContext(ctx).Variables
+	}
+
+	if err := c.checkArguments(fc.Field.Field, variables); err != nil {
+		return nil, err
+	}
+
+	return next(ctx)
+}
+
+// checkArguments validates the raw argument values of field against their definitions
+func (c *InputConstraints) checkArguments(field *ast.Field, variables map[string]interface{}) error {
+	values := field.ArgumentMap(variables)
+	for _, arg := range field.Definition.Arguments {
+		if err := c.check(arg.Name, arg.Directives, arg.Type, values[arg.Name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// check validates value, descending into lists and input objects
+func (c *InputConstraints) check(name string, directives ast.DirectiveList, typ *ast.Type, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	if typ.Elem != nil {
+		items, _ := value.([]interface{})
+		for _, item := range items {
+			if err := c.check(name, directives, typ.Elem, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if s, ok := value.(string); ok {
+		return c.checkString(name, directives, s)
+	}
+
+	def := c.schema.Types[typ.NamedType]
+	fields, ok := value.(map[string]interface{})
+	if def == nil || def.Kind != ast.InputObject || !ok {
+		return nil
+	}
+	for _, field := range def.Fields {
+		if err := c.check(field.Name, field.Directives, field.Type, fields[field.Name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkString applies the @length and @pattern directives to a string value
+func (c *InputConstraints) checkString(name string, directives ast.DirectiveList, value string) error {
+	if length := directives.ForName(lengthDirective); length != nil {
+		args := length.ArgumentMap(nil)
+		count := int64(utf8.RuneCountInString(value))
+		if min, ok := args["min"].(int64); ok && count < min {
+			return errors.NewValidationError(fmt.Sprintf("%s must be at least %d characters long", name, min), name)
+		}
+		if max, ok := args["max"].(int64); ok && count > max {
+			return errors.NewValidationError(fmt.Sprintf("%s cannot exceed %d characters", name, max), name)
+		}
+	}
+
+	if pattern := directives.ForName(patternDirective); pattern != nil {
+		regex, _ := pattern.ArgumentMap(nil)["regex"].(string)
+		compiled, ok := c.patterns[regex]
+		if !ok {
+			return fmt.Errorf("@pattern %q was not compiled", regex)
+		}
+		if !compiled.MatchString(value) {
+			return errors.NewValidationError(fmt.Sprintf("%s has an invalid format", name), name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package validation
+
+import (
+	"testing"
+
+	"backend/internal/graph/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const constrainedSchema = `
+directive @length(min: Int, max: Int) on ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION
+directive @pattern(regex: String!) on ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION
+
+input CreatePostInput {
+  title: String! @length(min: 3, max: 20)
+  summary: String @length(max: 10)
+  tags: [String!]! @pattern(regex: "^[a-z-]+$")
+}
+
+type Query {
+  ok: Boolean
+}
+
+type Mutation {
+  createPost(input: CreatePostInput!): Boolean
+  register(name: String! @length(min: 2), handle: String @pattern(regex: "^@\\w+$")): Boolean
+}
+`
+
+// checkMutation runs the constraints over the first field of a mutation
+func checkMutation(t *testing.T, query string, variables map[string]interface{}) error {
+	schema, err := gqlparser.LoadSchema(&ast.Source{Input: constrainedSchema})
+	require.NoError(t, err)
+
+	constraints := NewInputConstraints()
+	require.NoError(t, constraints.load(schema))
+
+	doc, gqlErr := gqlparser.LoadQuery(schema, query)
+	require.Nil(t, gqlErr)
+
+	field := doc.Operations[0].SelectionSet[0].(*ast.Field)
+	return constraints.checkArguments(field, variables)
+}
+
+// assertFieldError asserts err is a validation error scoped to field
+func assertFieldError(t *testing.T, err error, field string) {
+	var gqlErr *errors.GraphQLError
+	require.ErrorAs(t, err, &gqlErr)
+	assert.Equal(t, errors.ErrorCodeValidation, gqlErr.Code)
+	assert.Equal(t, field, gqlErr.Field)
+}
+
+func TestInputConstraints_AcceptsValidInput(t *testing.T) {
+	err := checkMutation(t, `mutation {
+		createPost(input: {title: "Hello", tags: ["go", "graph-ql"]})
+		register(name: "Al", handle: "@al")
+	}`, nil)
+
+	assert.NoError(t, err)
+}
+
+func TestInputConstraints_LengthOnInputField(t *testing.T) {
+	err := checkMutation(t, `mutation { createPost(input: {title: "Hi", tags: []}) }`, nil)
+
+	assertFieldError(t, err, "title")
+	assert.EqualError(t, err, "title must be at least 3 characters long")
+}
+
+func TestInputConstraints_LengthCountsCharacters(t *testing.T) {
+	err := checkMutation(t, `mutation { createPost(input: {title: "Ünïcødé", summary: "ééééééééé", tags: []}) }`, nil)
+	assert.NoError(t, err)
+
+	err = checkMutation(t, `mutation { createPost(input: {title: "Hello", summary: "ééééééééééé", tags: []}) }`, nil)
+	assertFieldError(t, err, "summary")
+}
+
+func TestInputConstraints_PatternOnListElements(t *testing.T) {
+	err := checkMutation(t, `mutation { createPost(input: {title: "Hello", tags: ["go", "Not Valid"]}) }`, nil)
+
+	assertFieldError(t, err, "tags")
+	assert.EqualError(t, err, "tags has an invalid format")
+}
+
+func TestInputConstraints_ChecksVariables(t *testing.T) {
+	query := `mutation ($input: CreatePostInput!) { createPost(input: $input) }`
+
+	err := checkMutation(t, query, map[string]interface{}{
+		"input": map[string]interface{}{"title": "This title is far too long", "tags": []interface{}{}},
+	})
+
+	assertFieldError(t, err, "title")
+}
+
+func TestInputConstraints_ArgumentDirectives(t *testing.T) {
+	err := checkMutation(t, `mutation { register(name: "A") }`, nil)
+	assertFieldError(t, err, "name")
+
+	err = checkMutation(t, `mutation { register(name: "Alice", handle: "alice") }`, nil)
+	assertFieldError(t, err, "handle")
+}
+
+func TestInputConstraints_RejectsInvalidPattern(t *testing.T) {
+	schema, err := gqlparser.LoadSchema(&ast.Source{Input: `
+directive @pattern(regex: String!) on ARGUMENT_DEFINITION
+type Query { search(term: String @pattern(regex: "([a-z")): Boolean }
+`})
+	require.NoError(t, err)
+
+	err = NewInputConstraints().load(schema)
+
+	assert.ErrorContains(t, err, "invalid @pattern")
+}
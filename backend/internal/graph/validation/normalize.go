@@ -0,0 +1,52 @@
+package validation
+
+import (
+	"strings"
+
+	"backend/internal/graph/model"
+)
+
+// The Normalize functions bring input into the form the validator checks and
+// the resolvers store: surrounding whitespace trimmed and emails lower-cased.
+// Resolvers normalize before validating, so what is validated is what is saved.
+
+// NormalizeEmail trims and lower-cases an email address
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizeText trims surrounding whitespace from a title, name or body
+func NormalizeText(text string) string {
+	return strings.TrimSpace(text)
+}
+
+// NormalizeCreatePostInput returns input with its title and content trimmed
+func NormalizeCreatePostInput(input model.CreatePostInput) model.CreatePostInput {
+	input.Title = NormalizeText(input.Title)
+	input.Content = NormalizeText(input.Content)
+	return input
+}
+
+// NormalizeUpdatePostInput returns input with any given title and content trimmed
+func NormalizeUpdatePostInput(input model.UpdatePostInput) model.UpdatePostInput {
+	input.Title = normalizeOptional(input.Title)
+	input.Content = normalizeOptional(input.Content)
+	return input
+}
+
+// NormalizeCreateUserInput returns input with its email normalized and name
+// trimmed. The password is left exactly as given.
+func NormalizeCreateUserInput(input model.CreateUserInput) model.CreateUserInput {
+	input.Email = NormalizeEmail(input.Email)
+	input.Name = NormalizeText(input.Name)
+	return input
+}
+
+// normalizeOptional trims an optional field, keeping nil as nil
+func normalizeOptional(text *string) *string {
+	if text == nil {
+		return nil
+	}
+	trimmed := NormalizeText(*text)
+	return &trimmed
+}
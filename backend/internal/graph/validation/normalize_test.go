@@ -0,0 +1,32 @@
+package validation
+
+import (
+	"testing"
+
+	"backend/internal/graph/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeCreatePostInput(t *testing.T) {
+	input := NormalizeCreatePostInput(model.CreatePostInput{Title: "  Title  ", Content: "\tBody\n"})
+
+	assert.Equal(t, "Title", input.Title)
+	assert.Equal(t, "Body", input.Content)
+}
+
+func TestNormalizeUpdatePostInput(t *testing.T) {
+	title := " Title "
+	input := NormalizeUpdatePostInput(model.UpdatePostInput{Title: &title})
+
+	assert.Equal(t, "Title", *input.Title)
+	assert.Nil(t, input.Content)
+	assert.Equal(t, " Title ", title)
+}
+
+func TestNormalizeCreateUserInput(t *testing.T) {
+	input := NormalizeCreateUserInput(model.CreateUserInput{Email: " Jane@Example.COM ", Name: " Jane ", Password: " secret pass "})
+
+	assert.Equal(t, "jane@example.com", input.Email)
+	assert.Equal(t, "Jane", input.Name)
+	assert.Equal(t, " secret pass ", input.Password)
+}
@@ -0,0 +1,85 @@
+package validation
+
+import (
+	"backend/internal/envconfig"
+	"backend/internal/graph/errors"
+	"backend/internal/graph/model"
+)
+
+// PaginationPolicy is the single place page sizes are enforced for every
+// paginated field
+type PaginationPolicy struct {
+	DefaultLimit int
+	MaxLimit     int
+	MaxPage      int
+	// ClampLimit lowers oversized limits to MaxLimit instead of rejecting them
+	ClampLimit bool
+}
+
+// DefaultPaginationPolicy returns the policy used when none is configured
+func DefaultPaginationPolicy() PaginationPolicy {
+	return PaginationPolicy{
+		DefaultLimit: 20,
+		MaxLimit:     100,
+		MaxPage:      1000,
+	}
+}
+
+// PaginationPolicyFromEnv returns the default policy with sizes overridden by
+// PAGINATION_DEFAULT_LIMIT and PAGINATION_MAX_LIMIT. A default above the
+// maximum is lowered so the two stay consistent.
+func PaginationPolicyFromEnv() PaginationPolicy {
+	policy := DefaultPaginationPolicy()
+	policy.DefaultLimit = envconfig.PositiveInt("PAGINATION_DEFAULT_LIMIT", policy.DefaultLimit)
+	policy.MaxLimit = envconfig.PositiveInt("PAGINATION_MAX_LIMIT", policy.MaxLimit)
+
+	if policy.DefaultLimit > policy.MaxLimit {
+		policy.DefaultLimit = policy.MaxLimit
+	}
+
+	return policy
+}
+
+// Resolve validates pagination input and returns the limit and offset to query with
+func (p PaginationPolicy) Resolve(input *model.PaginationInput) (limit, offset int, err error) {
+	limit = p.DefaultLimit
+	if input == nil {
+		return limit, 0, nil
+	}
+
+	if input.Page != nil && *input.Page < 1 {
+		return 0, 0, errors.NewLocalizedValidationError(errors.MsgPageTooSmall, "page", nil)
+	}
+
+	if input.Page != nil && *input.Page > p.MaxPage {
+		return 0, 0, errors.NewLocalizedValidationError(errors.MsgPageTooLarge, "page", map[string]interface{}{"max": p.MaxPage})
+	}
+
+	if input.Limit != nil {
+		if limit, err = p.ResolveLimit(*input.Limit); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if input.Page != nil && *input.Page > 1 {
+		offset = (*input.Page - 1) * limit
+	}
+
+	return limit, offset, nil
+}
+
+// ResolveLimit validates a requested page size, clamping it if the policy allows
+func (p PaginationPolicy) ResolveLimit(limit int) (int, error) {
+	if limit < 1 {
+		return 0, errors.NewLocalizedValidationError(errors.MsgLimitTooSmall, "limit", nil)
+	}
+
+	if limit > p.MaxLimit {
+		if p.ClampLimit {
+			return p.MaxLimit, nil
+		}
+		return 0, errors.NewLocalizedValidationError(errors.MsgLimitTooLarge, "limit", map[string]interface{}{"max": p.MaxLimit})
+	}
+
+	return limit, nil
+}
@@ -0,0 +1,124 @@
+package validation
+
+import (
+	"testing"
+
+	"backend/internal/graph/errors"
+	"backend/internal/graph/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginationPolicy_Resolve(t *testing.T) {
+	clamping := DefaultPaginationPolicy()
+	clamping.ClampLimit = true
+
+	tests := []struct {
+		name           string
+		policy         PaginationPolicy
+		input          *model.PaginationInput
+		expectedLimit  int
+		expectedOffset int
+		expectError    bool
+		errorMsg       string
+	}{
+		{
+			name:          "Nil input uses default limit",
+			policy:        DefaultPaginationPolicy(),
+			input:         nil,
+			expectedLimit: 20,
+		},
+		{
+			name:           "Page and limit produce offset",
+			policy:         DefaultPaginationPolicy(),
+			input:          &model.PaginationInput{Page: intPtr(3), Limit: intPtr(10)},
+			expectedLimit:  10,
+			expectedOffset: 20,
+		},
+		{
+			name:        "Oversized limit is rejected",
+			policy:      DefaultPaginationPolicy(),
+			input:       &model.PaginationInput{Limit: intPtr(1000)},
+			expectError: true,
+			errorMsg:    "Limit cannot exceed 100",
+		},
+		{
+			name:           "Oversized limit is clamped when configured",
+			policy:         clamping,
+			input:          &model.PaginationInput{Page: intPtr(2), Limit: intPtr(1000)},
+			expectedLimit:  100,
+			expectedOffset: 100,
+		},
+		{
+			name:        "Zero limit is rejected even when clamping",
+			policy:      clamping,
+			input:       &model.PaginationInput{Limit: intPtr(0)},
+			expectError: true,
+			errorMsg:    "Limit must be at least 1",
+		},
+		{
+			name:        "Custom maximum is enforced",
+			policy:      PaginationPolicy{DefaultLimit: 10, MaxLimit: 25, MaxPage: 50},
+			input:       &model.PaginationInput{Limit: intPtr(26)},
+			expectError: true,
+			errorMsg:    "Limit cannot exceed 25",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, offset, err := tt.policy.Resolve(tt.input)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				if gqlErr, ok := err.(*errors.GraphQLError); ok {
+					assert.Equal(t, errors.ErrorCodeValidation, gqlErr.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedLimit, limit)
+				assert.Equal(t, tt.expectedOffset, offset)
+			}
+		})
+	}
+}
+
+func TestPaginationPolicyFromEnv(t *testing.T) {
+	t.Setenv("PAGINATION_DEFAULT_LIMIT", "15")
+	t.Setenv("PAGINATION_MAX_LIMIT", "40")
+
+	policy := PaginationPolicyFromEnv()
+	assert.Equal(t, 15, policy.DefaultLimit)
+	assert.Equal(t, 40, policy.MaxLimit)
+
+	limit, _, err := policy.Resolve(&model.PaginationInput{Page: intPtr(2)})
+	assert.NoError(t, err)
+	assert.Equal(t, 15, limit)
+
+	_, _, err = policy.Resolve(&model.PaginationInput{Limit: intPtr(41)})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Limit cannot exceed 40")
+}
+
+func TestPaginationPolicyFromEnv_FallsBackOnInvalidValues(t *testing.T) {
+	t.Setenv("PAGINATION_DEFAULT_LIMIT", "many")
+	t.Setenv("PAGINATION_MAX_LIMIT", "-5")
+
+	assert.Equal(t, DefaultPaginationPolicy(), PaginationPolicyFromEnv())
+}
+
+func TestPaginationPolicyFromEnv_DefaultCappedAtMax(t *testing.T) {
+	t.Setenv("PAGINATION_DEFAULT_LIMIT", "50")
+	t.Setenv("PAGINATION_MAX_LIMIT", "30")
+
+	policy := PaginationPolicyFromEnv()
+	assert.Equal(t, 30, policy.DefaultLimit)
+	assert.Equal(t, 30, policy.MaxLimit)
+}
+
+func TestValidator_ValidatePaginationInput_UsesConfiguredPolicy(t *testing.T) {
+	validator := NewValidatorWithPagination(PaginationPolicy{DefaultLimit: 5, MaxLimit: 10, MaxPage: 100})
+
+	assert.NoError(t, validator.ValidatePaginationInput(&model.PaginationInput{Limit: intPtr(10)}))
+	assert.Error(t, validator.ValidatePaginationInput(&model.PaginationInput{Limit: intPtr(11)}))
+}
@@ -1,79 +1,122 @@
 package validation
 
 import (
-	"fmt"
 	"regexp"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 
+	"backend/internal/envconfig"
 	"backend/internal/graph/errors"
 	"backend/internal/graph/model"
 	"backend/internal/graph/scalars"
 )
 
 // Validator provides input validation for GraphQL operations
-type Validator struct{}
+type Validator struct {
+	pagination PaginationPolicy
+	tags       model.PostTagLimits
+	avatars    AvatarImageLimits
+}
 
-// NewValidator creates a new validator instance
+// NewValidator creates a new validator instance using the default pagination policy
 func NewValidator() *Validator {
-	return &Validator{}
+	return NewValidatorWithPagination(DefaultPaginationPolicy())
+}
+
+// NewValidatorWithPagination creates a new validator that enforces the given pagination policy
+func NewValidatorWithPagination(policy PaginationPolicy) *Validator {
+	return &Validator{
+		pagination: policy,
+		tags:       model.DefaultPostTagLimits(),
+		avatars:    DefaultAvatarImageLimits(),
+	}
+}
+
+// WithTagLimits makes the validator enforce the given post tag limits
+func (v *Validator) WithTagLimits(limits model.PostTagLimits) *Validator {
+	v.tags = limits
+	return v
+}
+
+// PostTagLimitsFromEnv returns the default tag limits overridden by
+// POST_MAX_TAGS, POST_MAX_TAG_LENGTH and POST_MAX_TOTAL_TAG_LENGTH
+func PostTagLimitsFromEnv() model.PostTagLimits {
+	limits := model.DefaultPostTagLimits()
+	limits.MaxTags = envconfig.PositiveInt("POST_MAX_TAGS", limits.MaxTags)
+	limits.MaxTagLength = envconfig.PositiveInt("POST_MAX_TAG_LENGTH", limits.MaxTagLength)
+	limits.MaxTotalTagLength = envconfig.PositiveInt("POST_MAX_TOTAL_TAG_LENGTH", limits.MaxTotalTagLength)
+	return limits
 }
 
 // ValidateCreatePostInput validates post creation input
 func (v *Validator) ValidateCreatePostInput(input model.CreatePostInput) error {
-	if err := v.ValidateTitle(input.Title); err != nil {
-		return err
-	}
-	
-	if err := v.ValidateContent(input.Content); err != nil {
-		return err
-	}
-	
-	if err := v.ValidateTags(input.Tags); err != nil {
-		return err
-	}
-	
-	return nil
+	return firstError(v.CreatePostInputErrors(input))
+}
+
+// CreatePostInputErrors returns the problem with each invalid field of post
+// creation input, in field order
+func (v *Validator) CreatePostInputErrors(input model.CreatePostInput) []error {
+	return collectErrors(
+		v.ValidateTitle(input.Title),
+		v.ValidateContent(input.Content),
+		v.ValidateTags(input.Tags),
+	)
 }
 
 // ValidateUpdatePostInput validates post update input
 func (v *Validator) ValidateUpdatePostInput(input model.UpdatePostInput) error {
+	return firstError(v.UpdatePostInputErrors(input))
+}
+
+// UpdatePostInputErrors returns the problem with each invalid field of post
+// update input, in field order. Fields left out are not checked.
+func (v *Validator) UpdatePostInputErrors(input model.UpdatePostInput) []error {
+	var errs []error
 	if input.Title != nil {
-		if err := v.ValidateTitle(*input.Title); err != nil {
-			return err
-		}
+		errs = append(errs, v.ValidateTitle(*input.Title))
 	}
-	
 	if input.Content != nil {
-		if err := v.ValidateContent(*input.Content); err != nil {
-			return err
-		}
+		errs = append(errs, v.ValidateContent(*input.Content))
 	}
-	
 	if input.Tags != nil {
-		if err := v.ValidateTags(input.Tags); err != nil {
-			return err
-		}
+		errs = append(errs, v.ValidateTags(input.Tags))
 	}
-	
-	return nil
+	return collectErrors(errs...)
 }
 
 // ValidateCreateUserInput validates user creation input
 func (v *Validator) ValidateCreateUserInput(input model.CreateUserInput) error {
-	if err := v.ValidateEmail(input.Email); err != nil {
-		return err
-	}
-	
-	if err := v.ValidateName(input.Name); err != nil {
-		return err
+	return firstError(v.CreateUserInputErrors(input))
+}
+
+// CreateUserInputErrors returns the problem with each invalid field of user
+// creation input, in field order
+func (v *Validator) CreateUserInputErrors(input model.CreateUserInput) []error {
+	return collectErrors(
+		v.ValidateEmail(input.Email),
+		v.ValidateName(input.Name),
+		v.ValidatePassword(input.Password),
+	)
+}
+
+// collectErrors returns the non-nil errors of errs
+func collectErrors(errs ...error) []error {
+	var found []error
+	for _, err := range errs {
+		if err != nil {
+			found = append(found, err)
+		}
 	}
-	
-	if err := v.ValidatePassword(input.Password); err != nil {
-		return err
+	return found
+}
+
+// firstError returns the first of errs, or nil when there are none
+func firstError(errs []error) error {
+	if len(errs) == 0 {
+		return nil
 	}
-	
-	return nil
+	return errs[0]
 }
 
 // ValidateUpdateUserInput validates user update input
@@ -98,20 +141,24 @@ func (v *Validator) ValidateTitle(title string) error {
 	title = strings.TrimSpace(title)
 	
 	if title == "" {
-		return errors.NewValidationError("Title cannot be empty", "title")
+		return errors.NewLocalizedValidationError(errors.MsgTitleEmpty, "title", nil)
+	}
+	
+	if hasControlCharacters(title, false) {
+		return errors.NewLocalizedValidationError(errors.MsgTitleControlCharacters, "title", nil)
 	}
 	
 	if utf8.RuneCountInString(title) < 3 {
-		return errors.NewValidationError("Title must be at least 3 characters long", "title")
+		return errors.NewLocalizedValidationError(errors.MsgTitleTooShort, "title", map[string]interface{}{"min": 3})
 	}
 	
 	if utf8.RuneCountInString(title) > 200 {
-		return errors.NewValidationError("Title cannot exceed 200 characters", "title")
+		return errors.NewLocalizedValidationError(errors.MsgTitleTooLong, "title", map[string]interface{}{"max": 200})
 	}
 	
 	// Check for invalid characters
 	if strings.ContainsAny(title, "<>\"'&") {
-		return errors.NewValidationError("Title contains invalid characters", "title")
+		return errors.NewLocalizedValidationError(errors.MsgTitleInvalidCharacters, "title", nil)
 	}
 	
 	return nil
@@ -122,15 +169,19 @@ func (v *Validator) ValidateContent(content string) error {
 	content = strings.TrimSpace(content)
 	
 	if content == "" {
-		return errors.NewValidationError("Content cannot be empty", "content")
+		return errors.NewLocalizedValidationError(errors.MsgContentEmpty, "content", nil)
+	}
+	
+	if hasControlCharacters(content, true) {
+		return errors.NewLocalizedValidationError(errors.MsgContentControlCharacters, "content", nil)
 	}
 	
 	if utf8.RuneCountInString(content) < 10 {
-		return errors.NewValidationError("Content must be at least 10 characters long", "content")
+		return errors.NewLocalizedValidationError(errors.MsgContentTooShort, "content", map[string]interface{}{"min": 10})
 	}
 	
 	if utf8.RuneCountInString(content) > 50000 {
-		return errors.NewValidationError("Content cannot exceed 50,000 characters", "content")
+		return errors.NewLocalizedValidationError(errors.MsgContentTooLong, "content", nil)
 	}
 	
 	return nil
@@ -138,10 +189,63 @@ func (v *Validator) ValidateContent(content string) error {
 
 // ValidateTags validates post tags
 func (v *Validator) ValidateTags(tags []string) error {
-	if len(tags) > 10 {
-		return errors.NewValidationError("Cannot have more than 10 tags", "tags")
+	if len(tags) > v.tags.MaxTags {
+		return errors.NewLocalizedValidationError(errors.MsgTagsTooMany, "tags", map[string]interface{}{"max": v.tags.MaxTags})
+	}
+	
+	if err := v.validateTagList(tags); err != nil {
+		return err
 	}
 	
+	if v.tags.MaxTotalTagLength > 0 {
+		total := 0
+		for _, tag := range tags {
+			total += utf8.RuneCountInString(strings.TrimSpace(tag))
+		}
+		if total > v.tags.MaxTotalTagLength {
+			return errors.NewLocalizedValidationError(errors.MsgTagsTotalTooLong, "tags", map[string]interface{}{"max": v.tags.MaxTotalTagLength})
+		}
+	}
+	
+	return nil
+}
+
+// DefaultMaxFilterTags bounds how many tags one posts filter may match against
+const DefaultMaxFilterTags = 20
+
+// MaxFilterTagsFromEnv returns FILTER_MAX_TAGS, or DefaultMaxFilterTags when
+// it is unset or not a positive integer
+func MaxFilterTagsFromEnv() int {
+	return envconfig.PositiveInt("FILTER_MAX_TAGS", DefaultMaxFilterTags)
+}
+
+// DefaultPopularTagsLimit is how many tags popularTags returns when no limit is given
+const DefaultPopularTagsLimit = 20
+
+// DefaultMaxPopularTags bounds how many tags one popularTags page may return
+const DefaultMaxPopularTags = 100
+
+// MaxPopularTagsOffset bounds how far popularTags may page into the tags
+const MaxPopularTagsOffset = 10000
+
+// MaxPopularTagsFromEnv returns POPULAR_TAGS_MAX_LIMIT, or
+// DefaultMaxPopularTags when it is unset or not a positive integer
+func MaxPopularTagsFromEnv() int {
+	return envconfig.PositiveInt("POPULAR_TAGS_MAX_LIMIT", DefaultMaxPopularTags)
+}
+
+// ValidateFilterTags validates the tags a posts filter matches against,
+// allowing at most max of them
+func (v *Validator) ValidateFilterTags(tags []string, max int) error {
+	if len(tags) > max {
+		return errors.NewLocalizedValidationError(errors.MsgFilterTagsTooMany, "tags", map[string]interface{}{"max": max})
+	}
+	
+	return v.validateTagList(tags)
+}
+
+// validateTagList checks each tag's format and rejects duplicates
+func (v *Validator) validateTagList(tags []string) error {
 	tagMap := make(map[string]bool)
 	tagRegex := regexp.MustCompile(`^[a-zA-Z0-9-_]+$`)
 	
@@ -149,23 +253,23 @@ func (v *Validator) ValidateTags(tags []string) error {
 		tag = strings.TrimSpace(strings.ToLower(tag))
 		
 		if tag == "" {
-			return errors.NewValidationError(fmt.Sprintf("Tag %d cannot be empty", i+1), "tags")
+			return errors.NewLocalizedValidationError(errors.MsgTagEmpty, "tags", map[string]interface{}{"position": i + 1})
 		}
 		
 		if utf8.RuneCountInString(tag) < 2 {
-			return errors.NewValidationError(fmt.Sprintf("Tag '%s' must be at least 2 characters long", tag), "tags")
+			return errors.NewLocalizedValidationError(errors.MsgTagTooShort, "tags", map[string]interface{}{"tag": tag, "min": 2})
 		}
 		
-		if utf8.RuneCountInString(tag) > 30 {
-			return errors.NewValidationError(fmt.Sprintf("Tag '%s' cannot exceed 30 characters", tag), "tags")
+		if utf8.RuneCountInString(tag) > v.tags.MaxTagLength {
+			return errors.NewLocalizedValidationError(errors.MsgTagTooLong, "tags", map[string]interface{}{"tag": tag, "max": v.tags.MaxTagLength})
 		}
 		
 		if !tagRegex.MatchString(tag) {
-			return errors.NewValidationError(fmt.Sprintf("Tag '%s' contains invalid characters (only letters, numbers, hyphens, and underscores allowed)", tag), "tags")
+			return errors.NewLocalizedValidationError(errors.MsgTagInvalidCharacters, "tags", map[string]interface{}{"tag": tag})
 		}
 		
 		if tagMap[tag] {
-			return errors.NewValidationError(fmt.Sprintf("Duplicate tag '%s'", tag), "tags")
+			return errors.NewLocalizedValidationError(errors.MsgTagDuplicate, "tags", map[string]interface{}{"tag": tag})
 		}
 		
 		tagMap[tag] = true
@@ -174,6 +278,21 @@ func (v *Validator) ValidateTags(tags []string) error {
 	return nil
 }
 
+// hasControlCharacters reports whether text contains a null byte or another
+// control character, which Postgres text can't store or which could forge
+// log lines. Tabs and line breaks are allowed in multiline text.
+func hasControlCharacters(text string, multiline bool) bool {
+	for _, r := range text {
+		if multiline && (r == '\t' || r == '\n' || r == '\r') {
+			continue
+		}
+		if unicode.IsControl(r) {
+			return true
+		}
+	}
+	return false
+}
+
 // ValidateEmail validates email address
 func (v *Validator) ValidateEmail(email string) error {
 	email = strings.TrimSpace(strings.ToLower(email))
@@ -190,21 +309,25 @@ func (v *Validator) ValidateName(name string) error {
 	name = strings.TrimSpace(name)
 	
 	if name == "" {
-		return errors.NewValidationError("Name cannot be empty", "name")
+		return errors.NewLocalizedValidationError(errors.MsgNameEmpty, "name", nil)
+	}
+	
+	if hasControlCharacters(name, false) {
+		return errors.NewLocalizedValidationError(errors.MsgNameControlCharacters, "name", nil)
 	}
 	
 	if utf8.RuneCountInString(name) < 2 {
-		return errors.NewValidationError("Name must be at least 2 characters long", "name")
+		return errors.NewLocalizedValidationError(errors.MsgNameTooShort, "name", map[string]interface{}{"min": 2})
 	}
 	
 	if utf8.RuneCountInString(name) > 100 {
-		return errors.NewValidationError("Name cannot exceed 100 characters", "name")
+		return errors.NewLocalizedValidationError(errors.MsgNameTooLong, "name", map[string]interface{}{"max": 100})
 	}
 	
 	// Check for invalid characters (allow letters, spaces, hyphens, apostrophes)
 	nameRegex := regexp.MustCompile(`^[a-zA-Z\s\-'\.]+$`)
 	if !nameRegex.MatchString(name) {
-		return errors.NewValidationError("Name contains invalid characters", "name")
+		return errors.NewLocalizedValidationError(errors.MsgNameInvalidCharacters, "name", nil)
 	}
 	
 	return nil
@@ -213,11 +336,11 @@ func (v *Validator) ValidateName(name string) error {
 // ValidatePassword validates password strength
 func (v *Validator) ValidatePassword(password string) error {
 	if len(password) < 8 {
-		return errors.NewValidationError("Password must be at least 8 characters long", "password")
+		return errors.NewLocalizedValidationError(errors.MsgPasswordTooShort, "password", map[string]interface{}{"min": 8})
 	}
 	
 	if len(password) > 128 {
-		return errors.NewValidationError("Password cannot exceed 128 characters", "password")
+		return errors.NewLocalizedValidationError(errors.MsgPasswordTooLong, "password", map[string]interface{}{"max": 128})
 	}
 	
 	// Check for at least one letter and one number
@@ -233,11 +356,11 @@ func (v *Validator) ValidatePassword(password string) error {
 	}
 	
 	if !hasLetter {
-		return errors.NewValidationError("Password must contain at least one letter", "password")
+		return errors.NewLocalizedValidationError(errors.MsgPasswordMissingLetter, "password", nil)
 	}
 	
 	if !hasNumber {
-		return errors.NewValidationError("Password must contain at least one number", "password")
+		return errors.NewLocalizedValidationError(errors.MsgPasswordMissingNumber, "password", nil)
 	}
 	
 	// Optional: require special character for stronger passwords
@@ -257,13 +380,13 @@ func (v *Validator) ValidateAvatarURL(url string) error {
 	}
 	
 	if len(url) > 2048 {
-		return errors.NewValidationError("Avatar URL cannot exceed 2048 characters", "avatar")
+		return errors.NewLocalizedValidationError(errors.MsgAvatarURLTooLong, "avatar", map[string]interface{}{"max": 2048})
 	}
 	
 	// Simple URL validation
 	urlRegex := regexp.MustCompile(`^https?://[^\s/$.?#].[^\s]*$`)
 	if !urlRegex.MatchString(url) {
-		return errors.NewValidationError("Invalid avatar URL format", "avatar")
+		return errors.NewLocalizedValidationError(errors.MsgAvatarURLInvalidFormat, "avatar", nil)
 	}
 	
 	// Check for image file extensions
@@ -279,7 +402,7 @@ func (v *Validator) ValidateAvatarURL(url string) error {
 	}
 	
 	if !hasValidExtension {
-		return errors.NewValidationError("Avatar URL must point to an image file", "avatar")
+		return errors.NewLocalizedValidationError(errors.MsgAvatarURLNotImage, "avatar", nil)
 	}
 	
 	return nil
@@ -290,15 +413,38 @@ func (v *Validator) ValidateCommentContent(content string) error {
 	content = strings.TrimSpace(content)
 	
 	if content == "" {
-		return errors.NewValidationError("Comment content cannot be empty", "content")
+		return errors.NewLocalizedValidationError(errors.MsgCommentEmpty, "content", nil)
+	}
+	
+	if hasControlCharacters(content, true) {
+		return errors.NewLocalizedValidationError(errors.MsgCommentControlCharacters, "content", nil)
 	}
 	
 	if utf8.RuneCountInString(content) < 1 {
-		return errors.NewValidationError("Comment must have at least 1 character", "content")
+		return errors.NewLocalizedValidationError(errors.MsgCommentTooShort, "content", map[string]interface{}{"min": 1})
 	}
 	
 	if utf8.RuneCountInString(content) > 2000 {
-		return errors.NewValidationError("Comment cannot exceed 2000 characters", "content")
+		return errors.NewLocalizedValidationError(errors.MsgCommentTooLong, "content", map[string]interface{}{"max": 2000})
+	}
+	
+	return nil
+}
+
+// DefaultMaxCommentDepth bounds how deeply replies may nest below a top-level comment
+const DefaultMaxCommentDepth = 5
+
+// MaxCommentDepthFromEnv returns COMMENT_MAX_DEPTH, or DefaultMaxCommentDepth
+// when it is unset or not a positive integer
+func MaxCommentDepthFromEnv() int {
+	return envconfig.PositiveInt("COMMENT_MAX_DEPTH", DefaultMaxCommentDepth)
+}
+
+// ValidateReplyDepth rejects a reply nested deeper than max; top-level
+// comments have depth 0
+func (v *Validator) ValidateReplyDepth(depth, max int) error {
+	if depth > max {
+		return errors.NewLocalizedValidationError(errors.MsgReplyTooDeep, "parentId", map[string]interface{}{"max": max})
 	}
 	
 	return nil
@@ -309,41 +455,26 @@ func (v *Validator) ValidateSearchQuery(query string) error {
 	query = strings.TrimSpace(query)
 	
 	if query == "" {
-		return errors.NewValidationError("Search query cannot be empty", "query")
+		return errors.NewLocalizedValidationError(errors.MsgSearchQueryEmpty, "query", nil)
+	}
+	
+	if hasControlCharacters(query, false) {
+		return errors.NewLocalizedValidationError(errors.MsgSearchQueryControlCharacters, "query", nil)
 	}
 	
 	if utf8.RuneCountInString(query) < 2 {
-		return errors.NewValidationError("Search query must be at least 2 characters long", "query")
+		return errors.NewLocalizedValidationError(errors.MsgSearchQueryTooShort, "query", map[string]interface{}{"min": 2})
 	}
 	
 	if utf8.RuneCountInString(query) > 100 {
-		return errors.NewValidationError("Search query cannot exceed 100 characters", "query")
+		return errors.NewLocalizedValidationError(errors.MsgSearchQueryTooLong, "query", map[string]interface{}{"max": 100})
 	}
 	
 	return nil
 }
 
-// ValidatePaginationInput validates pagination parameters
+// ValidatePaginationInput validates pagination parameters against the validator's policy
 func (v *Validator) ValidatePaginationInput(input *model.PaginationInput) error {
-	if input == nil {
-		return nil
-	}
-	
-	if input.Page != nil && *input.Page < 1 {
-		return errors.NewValidationError("Page must be at least 1", "page")
-	}
-	
-	if input.Page != nil && *input.Page > 1000 {
-		return errors.NewValidationError("Page cannot exceed 1000", "page")
-	}
-	
-	if input.Limit != nil && *input.Limit < 1 {
-		return errors.NewValidationError("Limit must be at least 1", "limit")
-	}
-	
-	if input.Limit != nil && *input.Limit > 100 {
-		return errors.NewValidationError("Limit cannot exceed 100", "limit")
-	}
-	
-	return nil
-}
\ No newline at end of file
+	_, _, err := v.pagination.Resolve(input)
+	return err
+}
@@ -1,11 +1,17 @@
 package validation
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
 	"testing"
 
 	"backend/internal/graph/errors"
 	"backend/internal/graph/model"
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestValidator_ValidateTitle(t *testing.T) {
@@ -280,6 +286,83 @@ func TestValidator_ValidateTags(t *testing.T) {
 	}
 }
 
+// numberedTags returns n distinct valid tags
+func numberedTags(n int) []string {
+	tags := make([]string, n)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("tag-%d", i)
+	}
+	return tags
+}
+
+func TestValidator_ValidateFilterTags(t *testing.T) {
+	validator := NewValidator()
+
+	assert.NoError(t, validator.ValidateFilterTags(numberedTags(DefaultMaxFilterTags), DefaultMaxFilterTags))
+
+	err := validator.ValidateFilterTags(numberedTags(DefaultMaxFilterTags+1), DefaultMaxFilterTags)
+	require.Error(t, err)
+	gqlErr := err.(*errors.GraphQLError)
+	assert.Equal(t, errors.ErrorCodeValidation, gqlErr.Code)
+	assert.Equal(t, "tags", gqlErr.Field)
+	assert.Equal(t, "Cannot filter by more than 20 tags", gqlErr.Message)
+
+	assert.Error(t, validator.ValidateFilterTags([]string{"go lang"}, DefaultMaxFilterTags), "tag format still applies")
+}
+
+func TestMaxFilterTagsFromEnv(t *testing.T) {
+	t.Setenv("FILTER_MAX_TAGS", "")
+	assert.Equal(t, DefaultMaxFilterTags, MaxFilterTagsFromEnv())
+
+	t.Setenv("FILTER_MAX_TAGS", "5")
+	assert.Equal(t, 5, MaxFilterTagsFromEnv())
+
+	t.Setenv("FILTER_MAX_TAGS", "-1")
+	assert.Equal(t, DefaultMaxFilterTags, MaxFilterTagsFromEnv())
+}
+
+func TestValidator_WithTagLimits(t *testing.T) {
+	validator := NewValidator().WithTagLimits(model.PostTagLimits{MaxTags: 2, MaxTagLength: 5})
+
+	assert.NoError(t, validator.ValidateTags([]string{"go", "sql"}))
+	err := validator.ValidateTags(numberedTags(3))
+	require.Error(t, err)
+	assert.Equal(t, "Cannot have more than 2 tags", err.(*errors.GraphQLError).Message)
+	assert.Error(t, validator.ValidateTags([]string{"golang"}))
+}
+
+func TestValidator_ValidateTags_TotalLength(t *testing.T) {
+	validator := NewValidator().WithTagLimits(model.PostTagLimits{MaxTags: 10, MaxTagLength: 30, MaxTotalTagLength: 100})
+
+	// Each tag is within the count and length limits; together they are too long
+	tags := make([]string, 4)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("%s%d", strings.Repeat("x", 25), i)
+	}
+	err := validator.ValidateTags(tags)
+	require.Error(t, err)
+	gqlErr := err.(*errors.GraphQLError)
+	assert.Equal(t, "Tags cannot exceed 100 characters in total", gqlErr.Message)
+	assert.Equal(t, "tags", gqlErr.Field)
+
+	assert.NoError(t, validator.ValidateTags(tags[:3]))
+	assert.NoError(t, NewValidator().WithTagLimits(model.PostTagLimits{MaxTags: 10, MaxTagLength: 30}).ValidateTags(tags),
+		"no combined limit when unset")
+}
+
+func TestPostTagLimitsFromEnv(t *testing.T) {
+	t.Setenv("POST_MAX_TAGS", "")
+	t.Setenv("POST_MAX_TAG_LENGTH", "")
+	t.Setenv("POST_MAX_TOTAL_TAG_LENGTH", "")
+	assert.Equal(t, model.DefaultPostTagLimits(), PostTagLimitsFromEnv())
+
+	t.Setenv("POST_MAX_TAGS", "4")
+	t.Setenv("POST_MAX_TAG_LENGTH", "-3")
+	t.Setenv("POST_MAX_TOTAL_TAG_LENGTH", "80")
+	defaults := model.DefaultPostTagLimits()
+	assert.Equal(t, model.PostTagLimits{MaxTags: 4, MaxTagLength: defaults.MaxTagLength, MaxTotalTagLength: 80}, PostTagLimitsFromEnv())
+}
+
 func TestValidator_ValidateCreatePostInput(t *testing.T) {
 	validator := NewValidator()
 
@@ -414,4 +497,110 @@ func TestValidator_ValidatePaginationInput(t *testing.T) {
 // Helper function to create int pointers
 func intPtr(i int) *int {
 	return &i
-}
\ No newline at end of file
+}
+func TestValidator_ValidateReplyDepth(t *testing.T) {
+	validator := NewValidator()
+
+	assert.NoError(t, validator.ValidateReplyDepth(0, 3))
+	assert.NoError(t, validator.ValidateReplyDepth(3, 3))
+
+	err := validator.ValidateReplyDepth(4, 3)
+	require.Error(t, err)
+	gqlErr, ok := err.(*errors.GraphQLError)
+	require.True(t, ok)
+	assert.Equal(t, errors.ErrorCodeValidation, gqlErr.Code)
+	assert.Equal(t, "Replies cannot be nested more than 3 levels deep", gqlErr.Message)
+}
+
+func TestMaxCommentDepthFromEnv(t *testing.T) {
+	t.Setenv("COMMENT_MAX_DEPTH", "")
+	assert.Equal(t, DefaultMaxCommentDepth, MaxCommentDepthFromEnv())
+
+	t.Setenv("COMMENT_MAX_DEPTH", "8")
+	assert.Equal(t, 8, MaxCommentDepthFromEnv())
+
+	t.Setenv("COMMENT_MAX_DEPTH", "0")
+	assert.Equal(t, DefaultMaxCommentDepth, MaxCommentDepthFromEnv())
+}
+
+// operationWithLanguage is a GraphQL request context carrying an Accept-Language header
+func operationWithLanguage(header string) context.Context {
+	return graphql.WithOperationContext(context.Background(), &graphql.OperationContext{
+		Headers: http.Header{"Accept-Language": []string{header}},
+	})
+}
+
+func TestValidator_LocalizedMessages(t *testing.T) {
+	validator := NewValidator().WithTagLimits(model.PostTagLimits{MaxTags: 2, MaxTagLength: 20})
+	handler := errors.NewErrorHandler(nil)
+
+	tests := []struct {
+		acceptLanguage string
+		message        string
+	}{
+		{"", "Cannot have more than 2 tags"},
+		{"en-US,en;q=0.9", "Cannot have more than 2 tags"},
+		{"es-ES,es;q=0.9,en;q=0.8", "No puede haber más de 2 etiquetas"},
+		{"de;q=0.9,fr;q=0.8", "Impossible d'avoir plus de 2 tags"},
+		{"ja", "Cannot have more than 2 tags"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			err := validator.ValidateTags(numberedTags(3))
+			require.Error(t, err)
+
+			gqlErr := handler.HandleError(operationWithLanguage(tt.acceptLanguage), err)
+
+			assert.Equal(t, tt.message, gqlErr.Message)
+			assert.Equal(t, string(errors.ErrorCodeValidation), gqlErr.Extensions["code"])
+			assert.Equal(t, string(errors.MsgTagsTooMany), gqlErr.Extensions["messageKey"])
+			assert.Equal(t, "tags", gqlErr.Extensions["field"])
+		})
+	}
+}
+
+func TestValidator_RejectsControlCharacters(t *testing.T) {
+	validator := NewValidator()
+
+	tests := []struct {
+		name      string
+		validate  func(string) error
+		valid     string
+		multiline bool
+		field     string
+		key       errors.MessageKey
+	}{
+		{"title", validator.ValidateTitle, "A valid title", false, "title", errors.MsgTitleControlCharacters},
+		{"content", validator.ValidateContent, "Some valid post content", true, "content", errors.MsgContentControlCharacters},
+		{"name", validator.ValidateName, "Jane Doe", false, "name", errors.MsgNameControlCharacters},
+		{"comment", validator.ValidateCommentContent, "A valid comment", true, "content", errors.MsgCommentControlCharacters},
+		{"search", validator.ValidateSearchQuery, "graphql tips", false, "query", errors.MsgSearchQueryControlCharacters},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.validate(tt.valid))
+
+			for _, bad := range []string{"\x00", "\x1b[31m", "\x07", "\x7f", "\u0085"} {
+				err := tt.validate(tt.valid[:4] + bad + tt.valid[4:])
+
+				gqlErr, ok := err.(*errors.GraphQLError)
+				require.True(t, ok, "%q was accepted", bad)
+				assert.Equal(t, errors.ErrorCodeValidation, gqlErr.Code)
+				assert.Equal(t, tt.key, gqlErr.MessageKey, "%q", bad)
+				assert.Equal(t, tt.field, gqlErr.Field)
+			}
+
+			// Line breaks and tabs are only allowed in multiline text
+			err := tt.validate(tt.valid[:4] + "\n\t" + tt.valid[4:])
+			if tt.multiline {
+				assert.NoError(t, err)
+			} else {
+				gqlErr, ok := err.(*errors.GraphQLError)
+				require.True(t, ok)
+				assert.Equal(t, tt.key, gqlErr.MessageKey)
+			}
+		})
+	}
+}
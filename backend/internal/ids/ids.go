@@ -0,0 +1,69 @@
+// Package ids generates the primary keys of the rows the application creates.
+// By default keys are UUIDv7, which start with their creation time, so new
+// rows cluster at the end of primary key indexes and sort by creation time.
+package ids
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// ID generation strategies
+const (
+	// StrategyV7 generates time-ordered UUIDv7 keys
+	StrategyV7 = "v7"
+	// StrategyV4 generates random UUIDv4 keys
+	StrategyV4 = "v4"
+)
+
+// Generator returns a new ID. Services take one so tests can fix the IDs.
+type Generator func() uuid.UUID
+
+// defaultGenerator is what New uses; nil means NewV7
+var defaultGenerator atomic.Pointer[Generator]
+
+// New returns an ID from the default generator, UUIDv7 unless SetDefault
+// chose another
+func New() uuid.UUID {
+	if generator := defaultGenerator.Load(); generator != nil {
+		return (*generator)()
+	}
+	return NewV7()
+}
+
+// SetDefault makes New use generator. Call it at startup, before any IDs are generated.
+func SetDefault(generator Generator) {
+	defaultGenerator.Store(&generator)
+}
+
+// NewV7 returns a UUIDv7. IDs generated by one process increase
+// strictly, even within the same millisecond.
+func NewV7() uuid.UUID {
+	return uuid.Must(uuid.NewV7())
+}
+
+// NewV4 returns a random UUIDv4
+func NewV4() uuid.UUID {
+	return uuid.New()
+}
+
+// ForStrategy returns the generator for a strategy name; empty means StrategyV7
+func ForStrategy(strategy string) (Generator, error) {
+	switch strings.ToLower(strings.TrimSpace(strategy)) {
+	case "", StrategyV7:
+		return NewV7, nil
+	case StrategyV4:
+		return NewV4, nil
+	default:
+		return nil, fmt.Errorf("unknown ID strategy %q, expected %s or %s", strategy, StrategyV7, StrategyV4)
+	}
+}
+
+// FromEnv returns the generator for the ID_STRATEGY environment variable
+func FromEnv() (Generator, error) {
+	return ForStrategy(os.Getenv("ID_STRATEGY"))
+}
@@ -0,0 +1,65 @@
+package ids
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewV7_IsTimeOrdered(t *testing.T) {
+	start := time.Now()
+
+	previous := NewV7()
+	for i := 0; i < 10000; i++ {
+		id := NewV7()
+		require.Equal(t, 1, bytes.Compare(id[:], previous[:]), "%s generated after %s sorts before it", id, previous)
+		require.Less(t, previous.String(), id.String())
+		previous = id
+	}
+
+	// The leading 48 bits are the creation time in Unix milliseconds
+	sec, nsec := previous.Time().UnixTime()
+	created := time.Unix(sec, nsec)
+	assert.WithinDuration(t, time.Now(), created, time.Since(start)+time.Second)
+}
+
+func TestNewV7_Parses(t *testing.T) {
+	id := NewV7()
+
+	parsed, err := uuid.Parse(id.String())
+	require.NoError(t, err)
+	assert.Equal(t, id, parsed)
+	assert.Equal(t, uuid.Version(7), parsed.Version())
+	assert.Equal(t, uuid.RFC4122, parsed.Variant())
+}
+
+func TestForStrategy(t *testing.T) {
+	for strategy, version := range map[string]uuid.Version{"": 7, "v7": 7, "V7": 7, "v4": 4} {
+		generator, err := ForStrategy(strategy)
+		require.NoError(t, err, strategy)
+		assert.Equal(t, version, generator().Version(), strategy)
+	}
+
+	_, err := ForStrategy("v1")
+	assert.Error(t, err)
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("ID_STRATEGY", "v4")
+
+	generator, err := FromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(4), generator().Version())
+}
+
+func TestNew_UsesDefault(t *testing.T) {
+	assert.Equal(t, uuid.Version(7), New().Version())
+
+	SetDefault(NewV4)
+	t.Cleanup(func() { defaultGenerator.Store(nil) })
+	assert.Equal(t, uuid.Version(4), New().Version())
+}
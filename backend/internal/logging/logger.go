@@ -3,6 +3,7 @@ package logging
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"runtime"
@@ -33,7 +34,8 @@ type Config struct {
 	Level       LogLevel
 	Service     string
 	Environment string
-	Format      string // "json" or "text"
+	Format      string    // "json" or "text"
+	Output      io.Writer // defaults to stdout; see OutputConfig.Open
 }
 
 // NewLogger creates a new structured logger
@@ -76,12 +78,17 @@ func NewLogger(config Config) *Logger {
 		},
 	}
 
+	output := config.Output
+	if output == nil {
+		output = os.Stdout
+	}
+
 	// Create handler based on format
 	var handler slog.Handler
 	if config.Format == "json" {
-		handler = slog.NewJSONHandler(os.Stdout, opts)
+		handler = slog.NewJSONHandler(output, opts)
 	} else {
-		handler = slog.NewTextHandler(os.Stdout, opts)
+		handler = slog.NewTextHandler(output, opts)
 	}
 
 	// Create logger with service context
@@ -98,7 +105,7 @@ func NewLogger(config Config) *Logger {
 
 // WithContext adds context information to the logger
 func (l *Logger) WithContext(ctx context.Context) *Logger {
-	attrs := []slog.Attr{}
+	attrs := []any{}
 
 	// Add request ID if available
 	if requestID := GetRequestID(ctx); requestID != "" {
@@ -127,7 +134,7 @@ func (l *Logger) WithContext(ctx context.Context) *Logger {
 
 // WithFields adds structured fields to the logger
 func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
-	attrs := make([]slog.Attr, 0, len(fields))
+	attrs := make([]any, 0, len(fields))
 	for key, value := range fields {
 		attrs = append(attrs, slog.Any(key, value))
 	}
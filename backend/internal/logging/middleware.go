@@ -3,20 +3,73 @@ package logging
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"math/rand/v2"
+	"runtime/debug"
 	"time"
 
+	"backend/internal/envconfig"
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/gin-gonic/gin"
 	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
-// GraphQLMiddleware creates a GraphQL logging middleware
+// GraphQLMiddleware creates a GraphQL logging middleware that logs every operation
 func GraphQLMiddleware(logger *Logger) graphql.HandlerExtension {
 	return &graphqlLogger{logger: logger}
 }
 
+// GraphQLMiddlewareWithSampling creates a GraphQL logging middleware that
+// logs errors and slow operations in full but only a sample of the rest
+func GraphQLMiddlewareWithSampling(logger *Logger, sampling SamplingConfig) graphql.HandlerExtension {
+	return &graphqlLogger{logger: logger, sampling: sampling}
+}
+
+// SamplingConfig controls which successful operations the GraphQL logger records
+type SamplingConfig struct {
+	// Log the operations of one in Rate requests; 0 or 1 logs them all
+	Rate int
+
+	// Operations taking at least this long are always logged; 0 means none
+	// count as slow
+	SlowThreshold time.Duration
+}
+
+// SamplingConfigFromEnv returns the sampling set by LOG_SAMPLE_RATE and
+// LOG_SLOW_OPERATION_MS; with neither set every operation is logged
+func SamplingConfigFromEnv() SamplingConfig {
+	return SamplingConfig{
+		Rate:          envconfig.NonNegativeInt("LOG_SAMPLE_RATE", 0),
+		SlowThreshold: time.Duration(envconfig.NonNegativeInt("LOG_SLOW_OPERATION_MS", 0)) * time.Millisecond,
+	}
+}
+
+// sampled reports whether the operations of the request with requestID are
+// logged when they succeed quickly. The decision hashes the request ID, so a
+// request's records are kept or dropped together. Requests without an ID are
+// sampled at random.
+func (s SamplingConfig) sampled(requestID string) bool {
+	if s.Rate <= 1 {
+		return true
+	}
+	if requestID == "" {
+		return rand.IntN(s.Rate) == 0
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(requestID))
+	return hash.Sum32()%uint32(s.Rate) == 0
+}
+
+// slow reports whether an operation that took duration is always logged
+func (s SamplingConfig) slow(duration time.Duration) bool {
+	return s.SlowThreshold > 0 && duration >= s.SlowThreshold
+}
+
 type graphqlLogger struct {
-	logger *Logger
+	logger   *Logger
+	sampling SamplingConfig
 }
 
 func (g *graphqlLogger) ExtensionName() string {
@@ -30,7 +83,6 @@ func (g *graphqlLogger) Validate(schema graphql.ExecutableSchema) error {
 // InterceptOperation logs GraphQL operations
 func (g *graphqlLogger) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
 	oc := graphql.GetOperationContext(ctx)
-	start := time.Now()
 
 	// Extract operation details
 	operationName := "unknown"
@@ -42,12 +94,17 @@ func (g *graphqlLogger) InterceptOperation(ctx context.Context, next graphql.Ope
 		operationType = string(oc.Operation.Operation)
 	}
 
-	// Add operation context
+	// Add operation context; lower layers record their work into the stats
 	ctx = WithOperationName(ctx, operationName)
+	ctx, _ = WithOperationStats(ctx)
 	logger := g.logger.WithContext(ctx)
 
-	// Log operation start
-	logger.Info("GraphQL operation started",
+	// Log operation start; the outcome isn't known yet, so only sampled
+	// requests log it
+	if !g.sampling.sampled(GetRequestID(ctx)) {
+		return next(ctx)
+	}
+	logger.Logger.Info("GraphQL operation started",
 		"operation_name", operationName,
 		"operation_type", operationType,
 		"query", oc.RawQuery,
@@ -77,23 +134,23 @@ func (g *graphqlLogger) InterceptResponse(ctx context.Context, next graphql.Resp
 		operationType = string(oc.Operation.Operation)
 	}
 
+	// Errors and slow operations are always logged; the rest only when sampled
+	failed := len(resp.Errors) > 0
+	if !failed && !g.sampling.slow(duration) && !g.sampling.sampled(GetRequestID(ctx)) {
+		return resp
+	}
+
 	// Log based on response status
-	if len(resp.Errors) > 0 {
-		// Log errors
+	if failed {
+		// One self-contained record per error so aggregators can group them
 		for _, err := range resp.Errors {
-			logger.Error("GraphQL operation error",
-				"operation_name", operationName,
-				"operation_type", operationType,
-				"duration_ms", duration.Milliseconds(),
-				"error_message", err.Message,
-				"error_path", err.Path,
-				"error_locations", err.Locations,
-				"error_extensions", err.Extensions,
+			g.logger.Logger.Error("GraphQL operation error",
+				operationErrorAttrs(ctx, operationName, operationType, duration, err)...,
 			)
 		}
 	} else {
 		// Log successful operation
-		logger.Info("GraphQL operation completed",
+		logger.Logger.Info("GraphQL operation completed",
 			"operation_name", operationName,
 			"operation_type", operationType,
 			"duration_ms", duration.Milliseconds(),
@@ -101,14 +158,58 @@ func (g *graphqlLogger) InterceptResponse(ctx context.Context, next graphql.Resp
 		)
 	}
 
+	if stats := StatsFromContext(ctx); stats != nil {
+		g.logger.Logger.Info("GraphQL operation summary",
+			operationSummaryAttrs(ctx, operationName, operationType, duration, stats)...,
+		)
+	}
+
 	return resp
 }
 
+// operationSummaryAttrs builds the per-operation cost summary
+func operationSummaryAttrs(ctx context.Context, operationName, operationType string, duration time.Duration, stats *OperationStats) []any {
+	return []any{
+		slog.String("operation_name", operationName),
+		slog.String("operation_type", operationType),
+		slog.String("request_id", GetRequestID(ctx)),
+		slog.Int64("db_queries", stats.DBQueries()),
+		slog.Int64("cache_hits", stats.CacheHits()),
+		slog.Int64("cache_misses", stats.CacheMisses()),
+		slog.Int64("resolvers", stats.Resolvers()),
+		slog.Int64("duration_ms", duration.Milliseconds()),
+	}
+}
+
+// operationErrorAttrs builds the fields logged for each error in a response.
+// Every field is always present, empty when unknown, so records group consistently.
+func operationErrorAttrs(ctx context.Context, operationName, operationType string, duration time.Duration, err *gqlerror.Error) []any {
+	errorCode := ""
+	if code, ok := err.Extensions["code"]; ok {
+		errorCode = fmt.Sprintf("%v", code)
+	}
+
+	return []any{
+		slog.String("operation_name", operationName),
+		slog.String("operation_type", operationType),
+		slog.String("user_id", GetUserID(ctx)),
+		slog.String("request_id", GetRequestID(ctx)),
+		slog.String("error_code", errorCode),
+		slog.String("error_path", err.Path.String()),
+		slog.String("error_message", err.Message),
+		slog.Int64("duration_ms", duration.Milliseconds()),
+	}
+}
+
 // InterceptField logs field resolution (optional, can be verbose)
 func (g *graphqlLogger) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
 	fc := graphql.GetFieldContext(ctx)
 	logger := g.logger.WithContext(ctx)
 
+	if fc.IsResolver {
+		StatsFromContext(ctx).AddResolver()
+	}
+
 	// Only log slow fields or errors
 	start := time.Now()
 	res, err := next(ctx)
@@ -117,14 +218,14 @@ func (g *graphqlLogger) InterceptField(ctx context.Context, next graphql.Resolve
 	// Log slow fields (> 100ms) or errors
 	if duration > 100*time.Millisecond || err != nil {
 		if err != nil {
-			logger.Error("Field resolution error",
+			logger.Logger.Error("Field resolution error",
 				"field", fc.Field.Name,
 				"path", fc.Path(),
 				"duration_ms", duration.Milliseconds(),
 				"error", err.Error(),
 			)
 		} else {
-			logger.Warn("Slow field resolution",
+			logger.Logger.Warn("Slow field resolution",
 				"field", fc.Field.Name,
 				"path", fc.Path(),
 				"duration_ms", duration.Milliseconds(),
@@ -150,7 +251,7 @@ func GinMiddleware(logger *Logger) gin.HandlerFunc {
 		c.Header("X-Request-ID", requestID)
 
 		// Log request start
-		logger.WithContext(ctx).Info("HTTP request started",
+		logger.WithContext(ctx).Logger.Info("HTTP request started",
 			"method", c.Request.Method,
 			"path", c.Request.URL.Path,
 			"query", c.Request.URL.RawQuery,
@@ -209,7 +310,7 @@ func ErrorLogger(logger *Logger) graphql.ErrorPresenterFunc {
 		}
 
 		// Log the error with context
-		logger.Error("GraphQL error occurred",
+		logger.Logger.Error("GraphQL error occurred",
 			"error_message", gqlErr.Message,
 			"error_path", gqlErr.Path,
 			"error_locations", gqlErr.Locations,
@@ -220,15 +321,43 @@ func ErrorLogger(logger *Logger) graphql.ErrorPresenterFunc {
 	}
 }
 
-// RecoveryLogger logs panics and recovers
+// internalErrorCode matches the INTERNAL_ERROR code the GraphQL error
+// handler reports for unexpected failures
+const internalErrorCode = "INTERNAL_ERROR"
+
+// RecoveryLogger logs panics with the operation name, request ID, field
+// path and stack trace, and returns a generic internal error so panic
+// details never reach the client
 func RecoveryLogger(logger *Logger) graphql.RecoverFunc {
 	return func(ctx context.Context, err interface{}) error {
-		logger := logger.WithContext(ctx)
+		operationName := "unknown"
+		if graphql.HasOperationContext(ctx) {
+			if oc := graphql.GetOperationContext(ctx); oc.Operation != nil && oc.Operation.Name != "" {
+				operationName = oc.Operation.Name
+			}
+		}
 
-		logger.Error("GraphQL panic recovered",
-			"panic", fmt.Sprintf("%v", err),
+		path := ""
+		if fc := graphql.GetFieldContext(ctx); fc != nil {
+			path = fc.Path().String()
+		}
+
+		logger.Logger.Error("GraphQL panic recovered",
+			slog.String("panic", fmt.Sprintf("%v", err)),
+			slog.String("operation_name", operationName),
+			slog.String("request_id", GetRequestID(ctx)),
+			slog.String("user_id", GetUserID(ctx)),
+			slog.String("path", path),
+			slog.String("stack", string(debug.Stack())),
 		)
 
-		return fmt.Errorf("internal server error")
+		extensions := map[string]interface{}{"code": internalErrorCode}
+		if requestID := GetRequestID(ctx); requestID != "" {
+			extensions["requestId"] = requestID
+		}
+		return &gqlerror.Error{
+			Message:    "Internal server error",
+			Extensions: extensions,
+		}
 	}
-}
\ No newline at end of file
+}
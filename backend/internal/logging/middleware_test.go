@@ -0,0 +1,295 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// logRecord returns the first JSON log record in buf with the given message
+func logRecord(t *testing.T, buf *bytes.Buffer, msg string) map[string]interface{} {
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(line, &record), string(line))
+		if record["msg"] == msg {
+			return record
+		}
+	}
+	t.Fatalf("no %q record in log output:\n%s", msg, buf.String())
+	return nil
+}
+
+func TestGraphQLLogger_ErrorRecordHasCorrelationFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: LevelInfo, Service: "test", Format: "json", Output: &buf})
+	extension := GraphQLMiddleware(logger).(*graphqlLogger)
+
+	ctx := WithRequestID(context.Background(), "req-123")
+	ctx = WithUserID(ctx, "user-456")
+	ctx = graphql.WithOperationContext(ctx, &graphql.OperationContext{
+		Operation: &ast.OperationDefinition{Name: "DeletePost", Operation: ast.Mutation},
+	})
+
+	resp := extension.InterceptResponse(ctx, func(ctx context.Context) *graphql.Response {
+		return &graphql.Response{
+			Errors: gqlerror.List{{
+				Message:    "You can only delete your own posts",
+				Path:       ast.Path{ast.PathName("deletePost")},
+				Extensions: map[string]interface{}{"code": "FORBIDDEN"},
+			}},
+		}
+	})
+	require.Len(t, resp.Errors, 1)
+
+	record := logRecord(t, &buf, "GraphQL operation error")
+	assert.Equal(t, "DeletePost", record["operation_name"])
+	assert.Equal(t, "mutation", record["operation_type"])
+	assert.Equal(t, "user-456", record["user_id"])
+	assert.Equal(t, "req-123", record["request_id"])
+	assert.Equal(t, "FORBIDDEN", record["error_code"])
+	assert.Equal(t, "deletePost", record["error_path"])
+	assert.Equal(t, "You can only delete your own posts", record["error_message"])
+	assert.Contains(t, record, "duration_ms")
+}
+
+func TestGraphQLLogger_SummarizesOperationCost(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: LevelInfo, Service: "test", Format: "json", Output: &buf})
+	extension := GraphQLMiddleware(logger).(*graphqlLogger)
+
+	ctx := WithRequestID(context.Background(), "req-123")
+	ctx = graphql.WithOperationContext(ctx, &graphql.OperationContext{
+		Operation: &ast.OperationDefinition{Name: "FeedPage", Operation: ast.Query},
+	})
+
+	// Two resolvers: one reads through the cache to the database, one is served from cache
+	resolveField := func(ctx context.Context, record func(*OperationStats)) {
+		fieldCtx := graphql.WithFieldContext(ctx, &graphql.FieldContext{
+			Field:      graphql.CollectedField{Field: &ast.Field{Name: "posts"}},
+			IsResolver: true,
+		})
+		_, err := extension.InterceptField(fieldCtx, func(ctx context.Context) (interface{}, error) {
+			record(StatsFromContext(ctx))
+			return nil, nil
+		})
+		require.NoError(t, err)
+	}
+
+	// Like the executor, run the response handler with the operation's inner context
+	var operationCtx context.Context
+	handler := extension.InterceptOperation(ctx, func(ctx context.Context) graphql.ResponseHandler {
+		operationCtx = ctx
+		return func(ctx context.Context) *graphql.Response {
+			return extension.InterceptResponse(ctx, func(ctx context.Context) *graphql.Response {
+				resolveField(ctx, func(stats *OperationStats) {
+					stats.AddCacheMisses(1)
+					stats.AddDBQuery()
+					stats.AddDBQuery()
+				})
+				resolveField(ctx, func(stats *OperationStats) {
+					stats.AddCacheHits(1)
+				})
+				return &graphql.Response{Data: []byte(`{}`)}
+			})
+		}
+	})
+	handler(operationCtx)
+
+	record := logRecord(t, &buf, "GraphQL operation summary")
+	assert.Equal(t, "FeedPage", record["operation_name"])
+	assert.Equal(t, "query", record["operation_type"])
+	assert.Equal(t, "req-123", record["request_id"])
+	assert.Equal(t, float64(2), record["db_queries"])
+	assert.Equal(t, float64(1), record["cache_hits"])
+	assert.Equal(t, float64(1), record["cache_misses"])
+	assert.Equal(t, float64(2), record["resolvers"])
+	assert.Contains(t, record, "duration_ms")
+}
+
+// panickingSchema serves `query { explode }`, recovering the resolver's
+// panic the way generated field code does
+func panickingSchema() graphql.ExecutableSchema {
+	schema := gqlparser.MustLoadSchema(&ast.Source{Input: `type Query { explode: String }`})
+
+	return &graphql.ExecutableSchemaMock{
+		SchemaFunc: func() *ast.Schema { return schema },
+		ComplexityFunc: func(ctx context.Context, typeName, fieldName string, childComplexity int, args map[string]any) (int, bool) {
+			return 1, true
+		},
+		ExecFunc: func(ctx context.Context) graphql.ResponseHandler {
+			ctx = graphql.WithFieldContext(ctx, &graphql.FieldContext{
+				Object:     "Query",
+				Field:      graphql.CollectedField{Field: &ast.Field{Name: "explode", Alias: "explode"}},
+				IsResolver: true,
+			})
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						graphql.AddError(ctx, graphql.GetOperationContext(ctx).Recover(ctx, r))
+					}
+				}()
+				var post *struct{ Title string }
+				_ = post.Title // nil dereference in the resolver
+			}()
+			return graphql.OneShot(&graphql.Response{Data: []byte(`{"explode":null}`)})
+		},
+	}
+}
+
+// countRecords counts the JSON log records in buf whose message starts with
+// msg; Logger's printf-style methods append their arguments to the message
+func countRecords(t *testing.T, buf *bytes.Buffer, msg string) int {
+	t.Helper()
+	count := 0
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(line, &record), string(line))
+		if message, _ := record["msg"].(string); strings.HasPrefix(message, msg) {
+			count++
+		}
+	}
+	return count
+}
+
+// runSampledOperation runs one query for requestID through extension,
+// answering with resp
+func runSampledOperation(extension *graphqlLogger, requestID string, resp *graphql.Response) {
+	ctx := WithRequestID(context.Background(), requestID)
+	ctx = graphql.WithOperationContext(ctx, &graphql.OperationContext{
+		Operation: &ast.OperationDefinition{Name: "FeedPage", Operation: ast.Query},
+	})
+
+	var operationCtx context.Context
+	handler := extension.InterceptOperation(ctx, func(ctx context.Context) graphql.ResponseHandler {
+		operationCtx = ctx
+		return func(ctx context.Context) *graphql.Response {
+			return extension.InterceptResponse(ctx, func(ctx context.Context) *graphql.Response {
+				return resp
+			})
+		}
+	})
+	handler(operationCtx)
+}
+
+func TestGraphQLLogger_SamplingAlwaysLogsErrors(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: LevelInfo, Service: "test", Format: "json", Output: &buf})
+	extension := GraphQLMiddlewareWithSampling(logger, SamplingConfig{Rate: 1000}).(*graphqlLogger)
+
+	for i := 0; i < 50; i++ {
+		runSampledOperation(extension, fmt.Sprintf("req-%d", i), &graphql.Response{
+			Errors: gqlerror.List{{Message: "boom"}},
+		})
+	}
+
+	assert.Equal(t, 50, countRecords(t, &buf, "GraphQL operation error"))
+	assert.Equal(t, 50, countRecords(t, &buf, "GraphQL operation summary"))
+}
+
+func TestGraphQLLogger_SamplingAlwaysLogsSlowOperations(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: LevelInfo, Service: "test", Format: "json", Output: &buf})
+	extension := GraphQLMiddlewareWithSampling(logger, SamplingConfig{Rate: 1000, SlowThreshold: time.Nanosecond}).(*graphqlLogger)
+
+	for i := 0; i < 50; i++ {
+		runSampledOperation(extension, fmt.Sprintf("req-%d", i), &graphql.Response{Data: []byte(`{}`)})
+	}
+
+	assert.Equal(t, 50, countRecords(t, &buf, "GraphQL operation completed"))
+}
+
+func TestGraphQLLogger_SamplesSuccessfulOperations(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: LevelInfo, Service: "test", Format: "json", Output: &buf})
+	sampling := SamplingConfig{Rate: 100, SlowThreshold: time.Hour}
+	extension := GraphQLMiddlewareWithSampling(logger, sampling).(*graphqlLogger)
+
+	const requests = 10000
+	for i := 0; i < requests; i++ {
+		runSampledOperation(extension, fmt.Sprintf("req-%d", i), &graphql.Response{Data: []byte(`{}`)})
+	}
+
+	completed := countRecords(t, &buf, "GraphQL operation completed")
+	assert.InDelta(t, requests/sampling.Rate, completed, 30, "about one in %d requests is logged", sampling.Rate)
+
+	// A sampled request logs all of its records, a dropped one none
+	assert.Equal(t, completed, countRecords(t, &buf, "GraphQL operation started"))
+	assert.Equal(t, completed, countRecords(t, &buf, "GraphQL operation summary"))
+}
+
+func TestSamplingConfig_DeterministicPerRequestID(t *testing.T) {
+	sampling := SamplingConfig{Rate: 10}
+	for i := 0; i < 100; i++ {
+		requestID := fmt.Sprintf("req-%d", i)
+		assert.Equal(t, sampling.sampled(requestID), sampling.sampled(requestID))
+	}
+
+	assert.True(t, SamplingConfig{}.sampled("req-1"), "no rate logs everything")
+	assert.True(t, SamplingConfig{Rate: 1}.sampled(""), "a rate of 1 logs everything")
+}
+
+func TestSamplingConfigFromEnv(t *testing.T) {
+	t.Setenv("LOG_SAMPLE_RATE", "20")
+	t.Setenv("LOG_SLOW_OPERATION_MS", "250")
+	assert.Equal(t, SamplingConfig{Rate: 20, SlowThreshold: 250 * time.Millisecond}, SamplingConfigFromEnv())
+
+	t.Setenv("LOG_SAMPLE_RATE", "-1")
+	t.Setenv("LOG_SLOW_OPERATION_MS", "")
+	assert.Equal(t, SamplingConfig{}, SamplingConfigFromEnv())
+}
+
+func TestRecoveryLogger_ResolverPanic(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: LevelInfo, Service: "test", Format: "json", Output: &buf})
+
+	srv := handler.New(panickingSchema())
+	srv.AddTransport(transport.POST{})
+	srv.SetRecoverFunc(RecoveryLogger(logger))
+	withRequestID := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.ServeHTTP(w, r.WithContext(WithRequestID(r.Context(), "req-789")))
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"query Detonate { explode }"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	withRequestID.ServeHTTP(rec, req)
+
+	var resp struct {
+		Errors []struct {
+			Message    string                 `json:"message"`
+			Path       []interface{}          `json:"path"`
+			Extensions map[string]interface{} `json:"extensions"`
+		} `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp), rec.Body.String())
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "Internal server error", resp.Errors[0].Message)
+	assert.Equal(t, []interface{}{"explode"}, resp.Errors[0].Path)
+	assert.Equal(t, map[string]interface{}{"code": "INTERNAL_ERROR", "requestId": "req-789"}, resp.Errors[0].Extensions)
+	assert.NotContains(t, rec.Body.String(), "nil pointer")
+
+	record := logRecord(t, &buf, "GraphQL panic recovered")
+	assert.Contains(t, record["panic"], "nil pointer dereference")
+	assert.Equal(t, "Detonate", record["operation_name"])
+	assert.Equal(t, "req-789", record["request_id"])
+	assert.Equal(t, "explode", record["path"])
+	assert.Contains(t, record["stack"], "panickingSchema")
+}
@@ -0,0 +1,195 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/envconfig"
+)
+
+// OutputConfig selects where logs are written and how log files are rotated
+type OutputConfig struct {
+	// "stdout" (the default), "stderr" or the path of a log file
+	Target string
+
+	// Rotate the log file once it would grow past this many megabytes; 0
+	// never rotates
+	MaxSizeMB int
+
+	// Remove rotated files older than this many days; 0 keeps them regardless of age
+	MaxAgeDays int
+
+	// Keep at most this many rotated files; 0 keeps them all
+	MaxBackups int
+}
+
+// DefaultOutputConfig returns the default output: stdout, with 100MB files
+// when a file target is chosen
+func DefaultOutputConfig() OutputConfig {
+	return OutputConfig{Target: "stdout", MaxSizeMB: 100}
+}
+
+// OutputConfigFromEnv returns the default output overridden by LOG_OUTPUT,
+// LOG_MAX_SIZE_MB, LOG_MAX_AGE_DAYS and LOG_MAX_BACKUPS
+func OutputConfigFromEnv() OutputConfig {
+	config := DefaultOutputConfig()
+	if target := strings.TrimSpace(os.Getenv("LOG_OUTPUT")); target != "" {
+		config.Target = target
+	}
+	config.MaxSizeMB = envconfig.NonNegativeInt("LOG_MAX_SIZE_MB", config.MaxSizeMB)
+	config.MaxAgeDays = envconfig.NonNegativeInt("LOG_MAX_AGE_DAYS", config.MaxAgeDays)
+	config.MaxBackups = envconfig.NonNegativeInt("LOG_MAX_BACKUPS", config.MaxBackups)
+	return config
+}
+
+// Open returns the writer for the configured target, for use as Config.Output.
+// File targets are opened for appending and rotated as configured; callers
+// should close the writer when it is an io.Closer.
+func (c OutputConfig) Open() (io.Writer, error) {
+	switch strings.ToLower(c.Target) {
+	case "", "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	default:
+		return OpenRotatingFile(c.Target, c)
+	}
+}
+
+// rotatedTimeFormat names rotated files so they sort by the time they were rotated
+const rotatedTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotatingFile is a log file that is renamed aside once it reaches its size
+// limit, with old rotated files pruned by age and count
+type RotatingFile struct {
+	path   string
+	config OutputConfig
+	now    func() time.Time
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenRotatingFile opens path for appending, creating it and its directory if needed
+func OpenRotatingFile(path string, config OutputConfig) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, config: config, now: time.Now}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write appends p to the file, rotating first if p would take it over the size limit
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	maxSize := int64(f.config.MaxSizeMB) * 1024 * 1024
+	if maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// open opens the log file and records its current size
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotate renames the current file aside, starts a new one and prunes old files
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	f.file = nil
+
+	rotated := f.path + "." + f.now().UTC().Format(rotatedTimeFormat)
+	if err := os.Rename(f.path, rotated); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.prune()
+	return nil
+}
+
+// prune removes rotated files past the age and count limits. Failures are
+// ignored so that pruning never stops logging.
+func (f *RotatingFile) prune() {
+	if f.config.MaxAgeDays == 0 && f.config.MaxBackups == 0 {
+		return
+	}
+
+	rotated, err := filepath.Glob(f.path + ".*")
+	if err != nil {
+		return
+	}
+	// Newest first
+	sort.Sort(sort.Reverse(sort.StringSlice(rotated)))
+
+	cutoff := f.now().Add(-time.Duration(f.config.MaxAgeDays) * 24 * time.Hour)
+	kept := 0
+	for _, name := range rotated {
+		stamp := strings.TrimPrefix(name, f.path+".")
+		rotatedAt, err := time.Parse(rotatedTimeFormat, stamp)
+		if err != nil {
+			// Not one of ours
+			continue
+		}
+
+		tooMany := f.config.MaxBackups > 0 && kept >= f.config.MaxBackups
+		tooOld := f.config.MaxAgeDays > 0 && rotatedAt.Before(cutoff)
+		if tooMany || tooOld {
+			os.Remove(name)
+			continue
+		}
+		kept++
+	}
+}
@@ -0,0 +1,135 @@
+package logging
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogger_WritesToOutput(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: LevelInfo, Service: "test", Format: "json", Output: &buf})
+
+	logger.Info("hello %d", 42)
+
+	record := logRecord(t, &buf, "hello 42")
+	assert.Equal(t, "test", record["service"])
+	assert.Equal(t, "INFO", record["level"])
+}
+
+func TestOutputConfigFromEnv(t *testing.T) {
+	t.Run("defaults to stdout", func(t *testing.T) {
+		config := OutputConfigFromEnv()
+		assert.Equal(t, DefaultOutputConfig(), config)
+
+		output, err := config.Open()
+		require.NoError(t, err)
+		assert.Same(t, os.Stdout, output)
+	})
+
+	t.Run("file with rotation", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "logs", "app.log")
+		t.Setenv("LOG_OUTPUT", path)
+		t.Setenv("LOG_MAX_SIZE_MB", "5")
+		t.Setenv("LOG_MAX_AGE_DAYS", "7")
+		t.Setenv("LOG_MAX_BACKUPS", "3")
+
+		config := OutputConfigFromEnv()
+		assert.Equal(t, OutputConfig{Target: path, MaxSizeMB: 5, MaxAgeDays: 7, MaxBackups: 3}, config)
+
+		output, err := config.Open()
+		require.NoError(t, err)
+		file, ok := output.(*RotatingFile)
+		require.True(t, ok, "expected *RotatingFile, got %T", output)
+		defer file.Close()
+		assert.FileExists(t, path)
+	})
+
+	t.Run("stderr and invalid limits", func(t *testing.T) {
+		t.Setenv("LOG_OUTPUT", "STDERR")
+		t.Setenv("LOG_MAX_SIZE_MB", "-1")
+		t.Setenv("LOG_MAX_BACKUPS", "many")
+
+		config := OutputConfigFromEnv()
+		assert.Equal(t, 100, config.MaxSizeMB)
+		assert.Zero(t, config.MaxBackups)
+
+		output, err := config.Open()
+		require.NoError(t, err)
+		assert.Same(t, os.Stderr, output)
+	})
+}
+
+// rotatedFiles lists the rotated copies of path
+func rotatedFiles(t *testing.T, path string) []string {
+	t.Helper()
+	names, err := filepath.Glob(path + ".*")
+	require.NoError(t, err)
+	return names
+}
+
+func TestRotatingFile_RotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	file, err := OpenRotatingFile(path, OutputConfig{MaxSizeMB: 1})
+	require.NoError(t, err)
+	defer file.Close()
+
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	file.now = func() time.Time { clock = clock.Add(time.Second); return clock }
+
+	line := []byte(strings.Repeat("x", 600*1024))
+	for i := 0; i < 3; i++ {
+		_, err := file.Write(line)
+		require.NoError(t, err)
+	}
+
+	// Each write past the first would exceed 1MB, so each starts a new file
+	assert.Len(t, rotatedFiles(t, path), 2)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(line)), info.Size())
+}
+
+func TestRotatingFile_PrunesRotatedFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Leftovers from earlier runs: one well past the age limit, and a file
+	// that isn't a rotated log
+	old := path + "." + clock.AddDate(0, 0, -30).Format(rotatedTimeFormat)
+	other := path + ".keep"
+	for _, name := range []string{old, other} {
+		require.NoError(t, os.WriteFile(name, []byte("old"), 0o644))
+	}
+
+	file, err := OpenRotatingFile(path, OutputConfig{MaxSizeMB: 1, MaxAgeDays: 7, MaxBackups: 2})
+	require.NoError(t, err)
+	defer file.Close()
+	file.now = func() time.Time { clock = clock.Add(time.Minute); return clock }
+
+	line := []byte(strings.Repeat("x", 600*1024))
+	for i := 0; i < 5; i++ {
+		_, err := file.Write(line)
+		require.NoError(t, err)
+	}
+
+	rotated := rotatedFiles(t, path)
+	assert.Len(t, rotated, 3, "two newest rotated files plus the unrelated one")
+	assert.Contains(t, rotated, other)
+	assert.NotContains(t, rotated, old)
+}
+
+func TestRotatingFile_WriteAfterClose(t *testing.T) {
+	file, err := OpenRotatingFile(filepath.Join(t.TempDir(), "app.log"), DefaultOutputConfig())
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	_, err = file.Write([]byte("late"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
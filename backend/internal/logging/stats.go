@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// OperationStats counts the work done while executing one GraphQL operation.
+// The repository and cache layers record into the stats carried by the
+// request context; all methods are safe on a nil receiver and for
+// concurrent resolvers.
+type OperationStats struct {
+	dbQueries   atomic.Int64
+	cacheHits   atomic.Int64
+	cacheMisses atomic.Int64
+	resolvers   atomic.Int64
+}
+
+const statsKey contextKey = "operation_stats"
+
+// WithOperationStats returns a context carrying fresh operation stats
+func WithOperationStats(ctx context.Context) (context.Context, *OperationStats) {
+	stats := &OperationStats{}
+	return context.WithValue(ctx, statsKey, stats), stats
+}
+
+// StatsFromContext returns the operation stats in ctx, or nil when there are none
+func StatsFromContext(ctx context.Context) *OperationStats {
+	stats, _ := ctx.Value(statsKey).(*OperationStats)
+	return stats
+}
+
+// AddDBQuery records one database round trip
+func (s *OperationStats) AddDBQuery() {
+	if s != nil {
+		s.dbQueries.Add(1)
+	}
+}
+
+// AddCacheHits records n keys served from cache
+func (s *OperationStats) AddCacheHits(n int) {
+	if s != nil {
+		s.cacheHits.Add(int64(n))
+	}
+}
+
+// AddCacheMisses records n keys not found in cache
+func (s *OperationStats) AddCacheMisses(n int) {
+	if s != nil {
+		s.cacheMisses.Add(int64(n))
+	}
+}
+
+// AddResolver records one resolver invocation
+func (s *OperationStats) AddResolver() {
+	if s != nil {
+		s.resolvers.Add(1)
+	}
+}
+
+// DBQueries returns the number of database round trips recorded
+func (s *OperationStats) DBQueries() int64 { return s.dbQueries.Load() }
+
+// CacheHits returns the number of cache hits recorded
+func (s *OperationStats) CacheHits() int64 { return s.cacheHits.Load() }
+
+// CacheMisses returns the number of cache misses recorded
+func (s *OperationStats) CacheMisses() int64 { return s.cacheMisses.Load() }
+
+// Resolvers returns the number of resolver invocations recorded
+func (s *OperationStats) Resolvers() int64 { return s.resolvers.Load() }
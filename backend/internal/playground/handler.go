@@ -3,6 +3,12 @@ package playground
 import (
 	"html/template"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
 )
 
 // Config holds playground configuration
@@ -304,47 +310,181 @@ func shouldAllowIntrospection(r *http.Request) bool {
 	return !isProduction()
 }
 
+// CORSConfig selects which origins may call the server from a browser
+type CORSConfig struct {
+	// AllowedOrigins lists the origins allowed to make requests; "*" allows any
+	AllowedOrigins []string
+	// AllowedHeaders lists the request headers browsers may send
+	AllowedHeaders []string
+	// AllowCredentials lets the listed origins send cookies. It is never
+	// granted through "*".
+	AllowCredentials bool
+}
+
+// DefaultCORSConfig allows any origin without credentials
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedHeaders: []string{"Content-Type", "Authorization", "X-Requested-With"},
+	}
+}
+
+// CORSConfigFromEnv returns the default config with the origins in the
+// comma-separated CORS_ALLOWED_ORIGINS. Credentials are allowed when origins
+// are listed, so cookie authentication works from them.
+func CORSConfigFromEnv() CORSConfig {
+	config := DefaultCORSConfig()
+	var origins []string
+	for _, origin := range strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	if len(origins) > 0 {
+		config.AllowedOrigins = origins
+		config.AllowCredentials = true
+	}
+	return config
+}
+
+// CORS returns middleware adding the CORS headers config allows and
+// answering preflight requests
+func CORS(config CORSConfig) func(http.Handler) http.Handler {
+	allowedHeaders := strings.Join(config.AllowedHeaders, ", ")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			listed, wildcard := false, false
+			for _, allowed := range config.AllowedOrigins {
+				if allowed == "*" {
+					wildcard = true
+				} else if origin != "" && strings.EqualFold(allowed, origin) {
+					listed = true
+				}
+			}
+
+			switch {
+			case listed:
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Add("Vary", "Origin")
+				if config.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+			case wildcard:
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			}
+			if listed || wildcard {
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
+			}
+
+			// Handle preflight requests
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WebSocketOriginCheck returns the origin check for WebSocket upgrades, which
+// carry the token cookie and are not covered by CORS. Browsers must come from
+// one of the listed AllowedOrigins; under the wildcard only the server's own
+// origin is accepted. Upgrades without an Origin header don't come from a
+// browser and are allowed.
+func WebSocketOriginCheck(config CORSConfig) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		for _, allowed := range config.AllowedOrigins {
+			if allowed != "*" && strings.EqualFold(allowed, origin) {
+				return true
+			}
+		}
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+}
+
 // CORSMiddleware adds CORS headers for playground
 func CORSMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Set CORS headers
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-
-		// Handle preflight requests
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
+	return CORS(DefaultCORSConfig())(next)
+}
+
+// GraphQLContentSecurityPolicy suits responses that are only ever read as
+// data: nothing may load from them and no page may frame them
+const GraphQLContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+
+// playgroundContentSecurityPolicy lets the playground page load its assets from the CDN
+const playgroundContentSecurityPolicy = "default-src 'self'; " +
+	"script-src 'self' 'unsafe-inline' 'unsafe-eval' cdn.jsdelivr.net; " +
+	"style-src 'self' 'unsafe-inline' cdn.jsdelivr.net; " +
+	"img-src 'self' data: cdn.jsdelivr.net; " +
+	"connect-src 'self' ws: wss:; " +
+	"font-src 'self' cdn.jsdelivr.net;"
+
+// SecurityHeadersConfig holds the security headers set on every response
+type SecurityHeadersConfig struct {
+	// ContentSecurityPolicy is sent on every response but the playground page
+	ContentSecurityPolicy string
+	// PlaygroundPath is served with a policy allowing the playground's CDN assets
+	PlaygroundPath string
+	FrameOptions   string
+	ReferrerPolicy string
+}
+
+// DefaultSecurityHeadersConfig returns the headers used for the playground server
+func DefaultSecurityHeadersConfig() SecurityHeadersConfig {
+	return SecurityHeadersConfig{
+		ContentSecurityPolicy: "default-src 'self'",
+		PlaygroundPath:        "/playground",
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "strict-origin-when-cross-origin",
+	}
+}
+
+// SecurityHeaders returns middleware adding the headers in config
+func SecurityHeaders(config SecurityHeadersConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+			w.Header().Set("X-Frame-Options", config.FrameOptions)
+			w.Header().Set("X-XSS-Protection", "1; mode=block")
+			w.Header().Set("Referrer-Policy", config.ReferrerPolicy)
 
-		next.ServeHTTP(w, r)
-	})
+			if config.PlaygroundPath != "" && r.URL.Path == config.PlaygroundPath {
+				w.Header().Set("Content-Security-Policy", playgroundContentSecurityPolicy)
+			} else {
+				w.Header().Set("Content-Security-Policy", config.ContentSecurityPolicy)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
 // SecurityHeadersMiddleware adds security headers
 func SecurityHeadersMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Security headers
-		w.Header().Set("X-Content-Type-Options", "nosniff")
-		w.Header().Set("X-Frame-Options", "DENY")
-		w.Header().Set("X-XSS-Protection", "1; mode=block")
-		w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
-		
-		// CSP for playground
-		if r.URL.Path == "/playground" {
-			w.Header().Set("Content-Security-Policy", 
-				"default-src 'self'; "+
-				"script-src 'self' 'unsafe-inline' 'unsafe-eval' cdn.jsdelivr.net; "+
-				"style-src 'self' 'unsafe-inline' cdn.jsdelivr.net; "+
-				"img-src 'self' data: cdn.jsdelivr.net; "+
-				"connect-src 'self' ws: wss:; "+
-				"font-src 'self' cdn.jsdelivr.net;")
-		} else {
-			w.Header().Set("Content-Security-Policy", "default-src 'self'")
-		}
+	return SecurityHeaders(DefaultSecurityHeadersConfig())(next)
+}
 
-		next.ServeHTTP(w, r)
-	})
-}
\ No newline at end of file
+// GinMiddleware adapts middleware such as CORS and SecurityHeaders to gin.
+// The rest of the gin chain runs only when the middleware calls its next
+// handler.
+func GinMiddleware(middleware func(http.Handler) http.Handler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		called := false
+		middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			c.Request = r
+			c.Next()
+		})).ServeHTTP(c.Writer, c.Request)
+		if !called {
+			c.Abort()
+		}
+	}
+}
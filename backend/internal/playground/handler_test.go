@@ -0,0 +1,111 @@
+package playground
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// serveWith sends a request from origin through middleware and returns the response
+func serveWith(middleware func(http.Handler) http.Handler, method, path, origin string) *httptest.ResponseRecorder {
+	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest(method, path, nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORS(t *testing.T) {
+	listed := CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedHeaders:   []string{"Content-Type"},
+		AllowCredentials: true,
+	}
+
+	tests := []struct {
+		name                string
+		config              CORSConfig
+		origin              string
+		expectedOrigin      string
+		expectedCredentials string
+	}{
+		{name: "any origin", config: DefaultCORSConfig(), origin: "https://evil.example.com", expectedOrigin: "*"},
+		{name: "listed origin", config: listed, origin: "https://app.example.com", expectedOrigin: "https://app.example.com", expectedCredentials: "true"},
+		{name: "unlisted origin", config: listed, origin: "https://evil.example.com"},
+		{
+			name:           "wildcard never grants credentials",
+			config:         CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+			origin:         "https://evil.example.com",
+			expectedOrigin: "*",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveWith(CORS(tt.config), http.MethodPost, "/graphql", tt.origin)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.expectedOrigin, rec.Header().Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, tt.expectedCredentials, rec.Header().Get("Access-Control-Allow-Credentials"))
+		})
+	}
+}
+
+func TestCORS_AnswersPreflight(t *testing.T) {
+	rec := serveWith(CORS(DefaultCORSConfig()), http.MethodOptions, "/graphql", "https://app.example.com")
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "GET, POST, OPTIONS", rec.Header().Get("Access-Control-Allow-Methods"))
+}
+
+func TestCORSConfigFromEnv(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://app.example.com, https://admin.example.com,")
+
+	config := CORSConfigFromEnv()
+
+	assert.Equal(t, []string{"https://app.example.com", "https://admin.example.com"}, config.AllowedOrigins)
+	assert.True(t, config.AllowCredentials)
+
+	t.Setenv("CORS_ALLOWED_ORIGINS", "")
+	assert.Equal(t, DefaultCORSConfig(), CORSConfigFromEnv())
+}
+
+func TestWebSocketOriginCheck(t *testing.T) {
+	upgrade := func(origin string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "http://api.example.com/graphql", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		return req
+	}
+
+	listed := WebSocketOriginCheck(CORSConfig{AllowedOrigins: []string{"https://app.example.com"}})
+	assert.True(t, listed(upgrade("https://app.example.com")))
+	assert.True(t, listed(upgrade("")), "non-browser clients send no Origin")
+	assert.False(t, listed(upgrade("https://evil.example.net")))
+
+	wildcard := WebSocketOriginCheck(DefaultCORSConfig())
+	assert.True(t, wildcard(upgrade("http://api.example.com")), "same origin")
+	assert.False(t, wildcard(upgrade("https://evil.example.net")), "the wildcard must not admit cross-site cookies")
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	config := DefaultSecurityHeadersConfig()
+	config.ContentSecurityPolicy = GraphQLContentSecurityPolicy
+
+	api := serveWith(SecurityHeaders(config), http.MethodPost, "/graphql", "")
+	assert.Equal(t, "nosniff", api.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "DENY", api.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "strict-origin-when-cross-origin", api.Header().Get("Referrer-Policy"))
+	assert.Equal(t, GraphQLContentSecurityPolicy, api.Header().Get("Content-Security-Policy"))
+
+	page := serveWith(SecurityHeaders(config), http.MethodGet, "/playground", "")
+	assert.Contains(t, page.Header().Get("Content-Security-Policy"), "cdn.jsdelivr.net")
+}
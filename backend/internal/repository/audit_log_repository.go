@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"backend/internal/database"
+	"backend/internal/graph/model"
+)
+
+// auditLogRepository implements AuditLogRepository interface
+type auditLogRepository struct {
+	db *database.DB
+}
+
+// NewAuditLogRepository creates a new audit log repository
+func NewAuditLogRepository(db *database.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+// Create stores an audit entry
+func (r *auditLogRepository) Create(ctx context.Context, entry *model.AuditLog) error {
+	query := `
+		INSERT INTO audit_logs (id, user_id, action, resource, resource_id, ip_address, user_agent, success, error, metadata, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	_, err := r.db.Writer().Exec(ctx, query,
+		entry.ID, entry.UserID, entry.Action, entry.Resource, entry.ResourceID,
+		entry.IPAddress, entry.UserAgent, entry.Success, entry.Error, entry.Metadata, entry.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	return nil
+}
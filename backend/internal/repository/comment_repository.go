@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"backend/internal/clock"
 	"backend/internal/database"
 	"backend/internal/graph/model"
 	"github.com/google/uuid"
@@ -20,16 +21,24 @@ func NewCommentRepository(db *database.DB) CommentRepository {
 	return &commentRepository{db: db}
 }
 
-// Create creates a new comment
+// Create creates a new comment; an unset UpdatedAt defaults to CreatedAt.
+// The comment's visibility is stored as given, so a comment created hidden
+// stays hidden.
 func (r *commentRepository) Create(ctx context.Context, comment *model.Comment) error {
+	if comment.UpdatedAt.IsZero() {
+		comment.UpdatedAt = comment.CreatedAt
+	}
+
 	query := `
-		INSERT INTO comments (id, content, author_id, post_id, created_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO comments (id, content, author_id, post_id, created_at, updated_at, parent_id, depth, hidden, hidden_reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 	
-	_, err := r.db.Pool.Exec(ctx, query,
+	_, err := r.db.Writer().Exec(ctx, query,
 		comment.ID, comment.Content, comment.AuthorID, 
-		comment.PostID, comment.CreatedAt,
+		comment.PostID, comment.CreatedAt, comment.UpdatedAt,
+		comment.ParentID, comment.Depth,
+		comment.Hidden, comment.HiddenReason,
 	)
 	
 	if err != nil {
@@ -42,15 +51,17 @@ func (r *commentRepository) Create(ctx context.Context, comment *model.Comment)
 // GetByID retrieves a comment by ID
 func (r *commentRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Comment, error) {
 	query := `
-		SELECT id, content, author_id, post_id, created_at
+		SELECT id, content, author_id, post_id, created_at, updated_at, parent_id, depth, hidden, hidden_reason
 		FROM comments 
 		WHERE id = $1
 	`
 	
 	var comment model.Comment
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.Reader().QueryRow(ctx, query, id).Scan(
 		&comment.ID, &comment.Content, &comment.AuthorID,
-		&comment.PostID, &comment.CreatedAt,
+		&comment.PostID, &comment.CreatedAt, &comment.UpdatedAt,
+		&comment.ParentID, &comment.Depth,
+		&comment.Hidden, &comment.HiddenReason,
 	)
 	
 	if err != nil {
@@ -65,15 +76,20 @@ func (r *commentRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.C
 
 // GetByPostID retrieves comments by post ID with pagination
 func (r *commentRepository) GetByPostID(ctx context.Context, postID uuid.UUID, limit, offset int) ([]*model.Comment, error) {
+	limit, err := checkPage(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
-		SELECT id, content, author_id, post_id, created_at
+		SELECT id, content, author_id, post_id, created_at, updated_at, parent_id, depth, hidden, hidden_reason
 		FROM comments 
-		WHERE post_id = $1
-		ORDER BY created_at ASC
+		WHERE post_id = $1 AND hidden = false
+		ORDER BY created_at ASC, id ASC
 		LIMIT $2 OFFSET $3
 	`
 	
-	rows, err := r.db.Pool.Query(ctx, query, postID, limit, offset)
+	rows, err := r.db.Reader().Query(ctx, query, postID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get comments by post: %w", err)
 	}
@@ -82,15 +98,17 @@ func (r *commentRepository) GetByPostID(ctx context.Context, postID uuid.UUID, l
 	return r.scanComments(rows)
 }
 
-// Update updates an existing comment
+// Update updates an existing comment's content and stamps its UpdatedAt
 func (r *commentRepository) Update(ctx context.Context, comment *model.Comment) error {
+	comment.UpdatedAt = clock.Now()
+
 	query := `
 		UPDATE comments 
-		SET content = $2
+		SET content = $2, updated_at = $3
 		WHERE id = $1
 	`
 	
-	result, err := r.db.Pool.Exec(ctx, query, comment.ID, comment.Content)
+	result, err := r.db.Writer().Exec(ctx, query, comment.ID, comment.Content, comment.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to update comment: %w", err)
 	}
@@ -106,7 +124,7 @@ func (r *commentRepository) Update(ctx context.Context, comment *model.Comment)
 func (r *commentRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM comments WHERE id = $1`
 	
-	result, err := r.db.Pool.Exec(ctx, query, id)
+	result, err := r.db.Writer().Exec(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete comment: %w", err)
 	}
@@ -118,12 +136,47 @@ func (r *commentRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-// Count counts comments for a post
+// SetHidden hides or unhides a comment; the reason is cleared when unhiding
+func (r *commentRepository) SetHidden(ctx context.Context, id uuid.UUID, hidden bool, reason *string) error {
+	if !hidden {
+		reason = nil
+	}
+
+	query := `
+		UPDATE comments 
+		SET hidden = $2, hidden_reason = $3
+		WHERE id = $1
+	`
+	
+	result, err := r.db.Writer().Exec(ctx, query, id, hidden, reason)
+	if err != nil {
+		return fmt.Errorf("failed to update comment visibility: %w", err)
+	}
+	
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("comment not found")
+	}
+	
+	return nil
+}
+
+// Count counts visible comments for a post
 func (r *commentRepository) Count(ctx context.Context, postID uuid.UUID) (int, error) {
-	query := `SELECT COUNT(*) FROM comments WHERE post_id = $1`
+	query := `SELECT COUNT(*) FROM comments WHERE post_id = $1 AND hidden = false`
+	
+	var count int
+	err := r.db.Reader().QueryRow(ctx, query, postID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count comments: %w", err)
+	}
 	
+	return count, nil
+}
+
+// CountAll returns the number of comments on every post, hidden ones included
+func (r *commentRepository) CountAll(ctx context.Context) (int, error) {
 	var count int
-	err := r.db.Pool.QueryRow(ctx, query, postID).Scan(&count)
+	err := r.db.Reader().QueryRow(ctx, `SELECT COUNT(*) FROM comments`).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count comments: %w", err)
 	}
@@ -131,15 +184,66 @@ func (r *commentRepository) Count(ctx context.Context, postID uuid.UUID) (int, e
 	return count, nil
 }
 
-// scanComments is a helper function to scan comment rows
+// SummaryByPostIDs returns the visible comment count and newest visible comment of
+// each post in one query (for DataLoader). Posts without comments are absent from the result.
+func (r *commentRepository) SummaryByPostIDs(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID]*model.CommentSummary, error) {
+	summaries := make(map[uuid.UUID]*model.CommentSummary)
+	if len(postIDs) == 0 {
+		return summaries, nil
+	}
+
+	query := `
+		SELECT id, content, author_id, post_id, created_at, updated_at, parent_id, depth, hidden, hidden_reason, total
+		FROM (
+			SELECT id, content, author_id, post_id, created_at, updated_at, parent_id, depth, hidden, hidden_reason,
+				ROW_NUMBER() OVER (PARTITION BY post_id ORDER BY created_at DESC, id DESC) AS position,
+				COUNT(*) OVER (PARTITION BY post_id) AS total
+			FROM comments
+			WHERE post_id = ANY($1) AND hidden = false
+		) ranked
+		WHERE position = 1
+	`
+	
+	rows, err := r.db.Reader().Query(ctx, query, postIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment summaries: %w", err)
+	}
+	defer rows.Close()
+	
+	for rows.Next() {
+		var comment model.Comment
+		var total int
+		err := rows.Scan(
+			&comment.ID, &comment.Content, &comment.AuthorID,
+			&comment.PostID, &comment.CreatedAt, &comment.UpdatedAt,
+			&comment.ParentID, &comment.Depth,
+			&comment.Hidden, &comment.HiddenReason, &total,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan comment summary: %w", err)
+		}
+		summaries[comment.PostID] = &model.CommentSummary{Count: total, Latest: &comment}
+	}
+	
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating comment summaries: %w", err)
+	}
+	
+	return summaries, nil
+}
+
+// scanComments is a helper function to scan comment rows. It returns an empty
+// slice rather than nil when there are no rows.
 func (r *commentRepository) scanComments(rows pgx.Rows) ([]*model.Comment, error) {
-	var comments []*model.Comment
+	comments := []*model.Comment{}
 	
 	for rows.Next() {
 		var comment model.Comment
 		err := rows.Scan(
 			&comment.ID, &comment.Content, &comment.AuthorID,
-			&comment.PostID, &comment.CreatedAt,
+			&comment.PostID, &comment.CreatedAt, &comment.UpdatedAt,
+			&comment.ParentID, &comment.Depth,
+			&comment.Hidden, &comment.HiddenReason,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan comment: %w", err)
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"backend/internal/database"
+	"backend/internal/graph/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommentRepository_NoRowsReturnEmptySlice(t *testing.T) {
+	querier := &existenceQuerier{}
+	repo := NewCommentRepository(database.NewDBWithQueriers(querier, querier))
+
+	comments, err := repo.GetByPostID(context.Background(), uuid.New(), 10, 0)
+
+	require.NoError(t, err)
+	assert.NotNil(t, comments)
+	assert.Empty(t, comments)
+}
+
+// execQuerier records the statements and arguments passed to Exec
+type execQuerier struct {
+	recordingQuerier
+	rowsAffected string
+	sql          []string
+	args         [][]any
+}
+
+func (q *execQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	q.execs++
+	q.sql = append(q.sql, sql)
+	q.args = append(q.args, args)
+	return pgconn.NewCommandTag(q.rowsAffected), nil
+}
+
+func TestCommentRepository_UpdateSetsUpdatedAt(t *testing.T) {
+	querier := &execQuerier{rowsAffected: "UPDATE 1"}
+	repo := NewCommentRepository(database.NewDBWithQueriers(querier, querier))
+	created := time.Now().Add(-time.Hour)
+	comment := &model.Comment{ID: uuid.New(), Content: "Edited", CreatedAt: created, UpdatedAt: created}
+
+	before := time.Now()
+	err := repo.Update(context.Background(), comment)
+
+	require.NoError(t, err)
+	assert.False(t, comment.UpdatedAt.Before(before))
+	require.Len(t, querier.sql, 1)
+	assert.Contains(t, querier.sql[0], "updated_at = $3")
+	assert.Equal(t, []any{comment.ID, "Edited", comment.UpdatedAt}, querier.args[0])
+}
+
+func TestCommentRepository_UpdateMissingComment(t *testing.T) {
+	querier := &execQuerier{rowsAffected: "UPDATE 0"}
+	repo := NewCommentRepository(database.NewDBWithQueriers(querier, querier))
+
+	err := repo.Update(context.Background(), &model.Comment{ID: uuid.New(), Content: "Edited"})
+
+	assert.EqualError(t, err, "comment not found")
+}
+
+func TestCommentRepository_CreateDefaultsUpdatedAtToCreatedAt(t *testing.T) {
+	querier := &execQuerier{rowsAffected: "INSERT 0 1"}
+	repo := NewCommentRepository(database.NewDBWithQueriers(querier, querier))
+	comment := &model.Comment{ID: uuid.New(), Content: "First", CreatedAt: time.Now()}
+
+	err := repo.Create(context.Background(), comment)
+
+	require.NoError(t, err)
+	assert.Equal(t, comment.CreatedAt, comment.UpdatedAt)
+	assert.Equal(t, comment.UpdatedAt, querier.args[0][5])
+}
+
+func TestCommentRepository_CreateStoresParentAndDepth(t *testing.T) {
+	querier := &execQuerier{rowsAffected: "INSERT 0 1"}
+	repo := NewCommentRepository(database.NewDBWithQueriers(querier, querier))
+	parentID := uuid.New()
+	reply := &model.Comment{ID: uuid.New(), Content: "Reply", ParentID: &parentID, Depth: 2, CreatedAt: time.Now()}
+
+	require.NoError(t, repo.Create(context.Background(), reply))
+
+	assert.Contains(t, querier.sql[0], "parent_id, depth")
+	assert.Equal(t, &parentID, querier.args[0][6])
+	assert.Equal(t, 2, querier.args[0][7])
+}
+
+func TestCommentRepository_CreateStoresVisibility(t *testing.T) {
+	querier := &execQuerier{rowsAffected: "INSERT 0 1"}
+	repo := NewCommentRepository(database.NewDBWithQueriers(querier, querier))
+	reason := "spam"
+	comment := &model.Comment{ID: uuid.New(), Content: "Buy now", Hidden: true, HiddenReason: &reason, CreatedAt: time.Now()}
+
+	require.NoError(t, repo.Create(context.Background(), comment))
+
+	assert.Contains(t, querier.sql[0], "hidden, hidden_reason")
+	assert.Equal(t, true, querier.args[0][8])
+	assert.Equal(t, &reason, querier.args[0][9])
+}
+
+// tableRows is a pgx.Rows over in-memory rows of column values
+type tableRows struct {
+	pgx.Rows
+	rows [][]any
+	pos  int
+}
+
+func (r *tableRows) Next() bool { r.pos++; return r.pos < len(r.rows) }
+func (r *tableRows) Err() error { return nil }
+func (r *tableRows) Close()     {}
+
+func (r *tableRows) Scan(dest ...any) error {
+	for i, value := range r.rows[r.pos] {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(value))
+	}
+	return nil
+}
+
+// summaryQuerier answers the comment summary query with fixed rows
+type summaryQuerier struct {
+	recordingQuerier
+	rows [][]any
+	sql  []string
+	args [][]any
+}
+
+func (q *summaryQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	q.queries++
+	q.sql = append(q.sql, sql)
+	q.args = append(q.args, args)
+	return &tableRows{rows: q.rows, pos: -1}, nil
+}
+
+// summaryRow is a latest comment row followed by the post's comment total
+func summaryRow(comment *model.Comment, total int) []any {
+	return []any{
+		comment.ID, comment.Content, comment.AuthorID, comment.PostID,
+		comment.CreatedAt, comment.UpdatedAt, comment.ParentID, comment.Depth,
+		comment.Hidden, comment.HiddenReason, total,
+	}
+}
+
+func TestCommentRepository_SummaryByPostIDs(t *testing.T) {
+	busyPost, quietPost, emptyPost := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+	busyLatest := &model.Comment{ID: uuid.New(), Content: "Newest", AuthorID: uuid.New(), PostID: busyPost, CreatedAt: now, UpdatedAt: now}
+	quietLatest := &model.Comment{ID: uuid.New(), Content: "Only", AuthorID: uuid.New(), PostID: quietPost, CreatedAt: now, UpdatedAt: now}
+
+	querier := &summaryQuerier{rows: [][]any{summaryRow(busyLatest, 3), summaryRow(quietLatest, 1)}}
+	repo := NewCommentRepository(database.NewDBWithQueriers(querier, querier))
+	postIDs := []uuid.UUID{busyPost, quietPost, emptyPost}
+
+	summaries, err := repo.SummaryByPostIDs(context.Background(), postIDs)
+
+	require.NoError(t, err)
+	require.Len(t, querier.sql, 1)
+	assert.Contains(t, querier.sql[0], "ROW_NUMBER() OVER (PARTITION BY post_id ORDER BY created_at DESC, id DESC)")
+	assert.Contains(t, querier.sql[0], "hidden = false")
+	assert.Equal(t, []any{postIDs}, querier.args[0])
+
+	require.Len(t, summaries, 2)
+	assert.Equal(t, 3, summaries[busyPost].Count)
+	assert.Equal(t, busyLatest, summaries[busyPost].Latest)
+	assert.Equal(t, 1, summaries[quietPost].Count)
+	assert.Equal(t, quietLatest, summaries[quietPost].Latest)
+	assert.NotContains(t, summaries, emptyPost)
+}
+
+func TestCommentRepository_SummaryByPostIDsWithoutPosts(t *testing.T) {
+	querier := &summaryQuerier{}
+	repo := NewCommentRepository(database.NewDBWithQueriers(querier, querier))
+
+	summaries, err := repo.SummaryByPostIDs(context.Background(), nil)
+
+	require.NoError(t, err)
+	assert.Empty(t, summaries)
+	assert.Zero(t, querier.queries)
+}
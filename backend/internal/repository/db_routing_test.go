@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend/internal/database"
+	"backend/internal/graph/model"
+	"backend/internal/logging"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+var errQuerierStub = errors.New("querier stub")
+
+// recordingQuerier records which operations reached it and fails each one
+type recordingQuerier struct {
+	execs   int
+	queries int
+}
+
+func (q *recordingQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	q.execs++
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (q *recordingQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	q.queries++
+	return nil, errQuerierStub
+}
+
+func (q *recordingQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	q.queries++
+	return stubRow{}
+}
+
+// stubRow is a pgx.Row whose Scan always fails
+type stubRow struct{}
+
+func (stubRow) Scan(dest ...any) error { return errQuerierStub }
+
+func setupRoutedRepos() (*Manager, *recordingQuerier, *recordingQuerier) {
+	primary := &recordingQuerier{}
+	replica := &recordingQuerier{}
+	return NewManager(database.NewDBWithQueriers(primary, replica)), primary, replica
+}
+
+func TestRouting_ReadsHitReplica(t *testing.T) {
+	repos, primary, replica := setupRoutedRepos()
+	ctx := context.Background()
+
+	_, _ = repos.User.GetByID(ctx, uuid.New())
+	_, _ = repos.User.List(ctx, 10, 0)
+	_, _ = repos.Post.GetByID(ctx, uuid.New())
+	_, _ = repos.Post.List(ctx, &PostFilters{}, 10, 0)
+	_, _ = repos.Post.Search(ctx, "golang", 10)
+	_, _ = repos.Post.Count(ctx, &PostFilters{})
+	_, _ = repos.Comment.GetByPostID(ctx, uuid.New(), 10, 0)
+	_, _ = repos.Comment.Count(ctx, uuid.New())
+	_, _ = repos.User.Count(ctx)
+	_, _ = repos.User.CountCreatedSince(ctx, time.Now())
+	_, _, _ = repos.Post.CountByPublished(ctx)
+	_, _ = repos.Comment.CountAll(ctx)
+
+	assert.Equal(t, 12, replica.queries)
+	assert.Zero(t, replica.execs)
+	assert.Zero(t, primary.queries)
+	assert.Zero(t, primary.execs)
+}
+
+func TestRouting_WritesHitPrimary(t *testing.T) {
+	repos, primary, replica := setupRoutedRepos()
+	ctx := context.Background()
+
+	assert.NoError(t, repos.User.Create(ctx, &model.User{ID: uuid.New()}))
+	assert.NoError(t, repos.User.Update(ctx, &model.User{ID: uuid.New()}))
+	assert.NoError(t, repos.Post.Create(ctx, &model.Post{ID: uuid.New()}))
+	assert.NoError(t, repos.Post.Delete(ctx, uuid.New()))
+	assert.NoError(t, repos.Comment.Delete(ctx, uuid.New()))
+	assert.NoError(t, repos.User.RecordLogin(ctx, uuid.New(), time.Now(), "203.0.113.7"))
+
+	assert.Equal(t, 6, primary.execs)
+	assert.Zero(t, replica.execs)
+	assert.Zero(t, replica.queries)
+}
+
+func TestRouting_EmailLookupHitsPrimary(t *testing.T) {
+	repos, primary, replica := setupRoutedRepos()
+
+	_, err := repos.User.GetByEmail(context.Background(), "test@example.com")
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, primary.queries)
+	assert.Zero(t, replica.queries)
+}
+
+func TestRouting_FallsBackToPrimaryWithoutReplica(t *testing.T) {
+	primary := &recordingQuerier{}
+	repos := NewManager(database.NewDBWithQueriers(primary, nil))
+	ctx := context.Background()
+
+	_, _ = repos.Post.GetByID(ctx, uuid.New())
+	assert.NoError(t, repos.Post.Delete(ctx, uuid.New()))
+
+	assert.Equal(t, 1, primary.queries)
+	assert.Equal(t, 1, primary.execs)
+}
+
+func TestRouting_RecordsQueriesInOperationStats(t *testing.T) {
+	repos, _, _ := setupRoutedRepos()
+	ctx, stats := logging.WithOperationStats(context.Background())
+
+	_, _ = repos.User.GetByID(ctx, uuid.New())
+	assert.NoError(t, repos.Post.Create(ctx, &model.Post{ID: uuid.New()}))
+
+	assert.Equal(t, int64(2), stats.DBQueries())
+}
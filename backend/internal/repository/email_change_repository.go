@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"backend/internal/database"
+	"backend/internal/graph/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// emailChangeRepository implements EmailChangeRepository interface
+type emailChangeRepository struct {
+	db *database.DB
+}
+
+// NewEmailChangeRepository creates a new email change request repository
+func NewEmailChangeRepository(db *database.DB) EmailChangeRepository {
+	return &emailChangeRepository{db: db}
+}
+
+// Create stores a new pending email change request
+func (r *emailChangeRepository) Create(ctx context.Context, req *model.EmailChangeRequest) error {
+	query := `
+		INSERT INTO email_change_requests (id, user_id, new_email, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.Writer().Exec(ctx, query,
+		req.ID, req.UserID, req.NewEmail, req.TokenHash, req.ExpiresAt, req.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create email change request: %w", err)
+	}
+
+	return nil
+}
+
+// GetByTokenHash retrieves a pending email change request by its token hash
+func (r *emailChangeRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*model.EmailChangeRequest, error) {
+	query := `
+		SELECT id, user_id, new_email, token_hash, expires_at, created_at
+		FROM email_change_requests
+		WHERE token_hash = $1
+	`
+
+	// Read from the primary: the token may have been created moments ago
+	var req model.EmailChangeRequest
+	err := r.db.Writer().QueryRow(ctx, query, tokenHash).Scan(
+		&req.ID, &req.UserID, &req.NewEmail, &req.TokenHash, &req.ExpiresAt, &req.CreatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("email change request not found")
+		}
+		return nil, fmt.Errorf("failed to get email change request: %w", err)
+	}
+
+	return &req, nil
+}
+
+// DeleteByUserID removes every pending email change request for a user
+func (r *emailChangeRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	query := `DELETE FROM email_change_requests WHERE user_id = $1`
+
+	if _, err := r.db.Writer().Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to delete email change requests: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/database"
+	"backend/internal/graph/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrInvalidEmailVerificationToken is returned when a verification token is
+// unknown, expired or already used
+var ErrInvalidEmailVerificationToken = errors.New("invalid or expired email verification token")
+
+// emailVerificationRepository implements EmailVerificationRepository interface
+type emailVerificationRepository struct {
+	db *database.DB
+}
+
+// NewEmailVerificationRepository creates a new email verification token repository
+func NewEmailVerificationRepository(db *database.DB) EmailVerificationRepository {
+	return &emailVerificationRepository{db: db}
+}
+
+// Create stores a new verification token
+func (r *emailVerificationRepository) Create(ctx context.Context, token *model.EmailVerificationToken) error {
+	query := `
+		INSERT INTO email_verification_tokens (id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.Writer().Exec(ctx, query,
+		token.ID, token.UserID, token.TokenHash, token.ExpiresAt, token.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create email verification token: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteByUserID removes every outstanding verification token for a user
+func (r *emailVerificationRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	query := `DELETE FROM email_verification_tokens WHERE user_id = $1`
+
+	if _, err := r.db.Writer().Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to delete email verification tokens: %w", err)
+	}
+
+	return nil
+}
+
+// IsVerified reports whether a user's email address has been verified
+func (r *emailVerificationRepository) IsVerified(ctx context.Context, userID uuid.UUID) (bool, error) {
+	query := `SELECT email_verified_at IS NOT NULL FROM users WHERE id = $1`
+
+	// Read from the primary: verification may have happened moments ago
+	var verified bool
+	err := r.db.Writer().QueryRow(ctx, query, userID).Scan(&verified)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, fmt.Errorf("user not found")
+		}
+		return false, fmt.Errorf("failed to check email verification: %w", err)
+	}
+
+	return verified, nil
+}
+
+// Verify claims an unexpired verification token and marks its user's email
+// address as verified. Claiming deletes the token, so each one works once.
+func (r *emailVerificationRepository) Verify(ctx context.Context, tokenHash string, now time.Time) (uuid.UUID, error) {
+	claimQuery := `
+		DELETE FROM email_verification_tokens
+		WHERE token_hash = $1 AND expires_at > $2
+		RETURNING user_id
+	`
+	verifyQuery := `
+		UPDATE users SET email_verified_at = COALESCE(email_verified_at, $1)
+		WHERE id = $2
+	`
+	cleanupQuery := `DELETE FROM email_verification_tokens WHERE user_id = $1`
+
+	var userID uuid.UUID
+	err := r.db.WithTx(ctx, func(tx database.Querier) error {
+		if err := tx.QueryRow(ctx, claimQuery, tokenHash, now).Scan(&userID); err != nil {
+			if err == pgx.ErrNoRows {
+				return ErrInvalidEmailVerificationToken
+			}
+			return fmt.Errorf("failed to claim email verification token: %w", err)
+		}
+
+		result, err := tx.Exec(ctx, verifyQuery, now, userID)
+		if err != nil {
+			return fmt.Errorf("failed to verify email address: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return ErrInvalidEmailVerificationToken
+		}
+
+		if _, err := tx.Exec(ctx, cleanupQuery, userID); err != nil {
+			return fmt.Errorf("failed to delete email verification tokens: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return userID, nil
+}
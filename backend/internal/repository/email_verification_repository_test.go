@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// verificationStore is an in-memory email_verification_tokens table with
+// the users' verification times, changed only when a transaction commits
+type verificationStore struct {
+	recordingQuerier
+	tokens   map[uuid.UUID]string // user ID -> token hash
+	expired  map[string]bool      // token hashes past their expiry
+	verified map[uuid.UUID]time.Time
+}
+
+func (s *verificationStore) Begin(ctx context.Context) (pgx.Tx, error) {
+	return &verificationTx{store: s, verified: map[uuid.UUID]time.Time{}}, nil
+}
+
+// verificationTx stages token deletions and verifications until Commit
+type verificationTx struct {
+	pgx.Tx
+	store    *verificationStore
+	deleted  []uuid.UUID
+	verified map[uuid.UUID]time.Time
+}
+
+func (tx *verificationTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if strings.Contains(sql, "DELETE FROM email_verification_tokens") {
+		hash := args[0].(string)
+		for userID, stored := range tx.store.tokens {
+			if stored == hash && !tx.store.expired[hash] {
+				tx.deleted = append(tx.deleted, userID)
+				return userIDRow{id: userID}
+			}
+		}
+		return userIDRow{}
+	}
+	return stubRow{}
+}
+
+func (tx *verificationTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	switch {
+	case strings.Contains(sql, "UPDATE users SET email_verified_at"):
+		userID := args[1].(uuid.UUID)
+		if _, ok := tx.store.verified[userID]; !ok {
+			tx.verified[userID] = args[0].(time.Time)
+		}
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	case strings.Contains(sql, "DELETE FROM email_verification_tokens WHERE user_id"):
+		tx.deleted = append(tx.deleted, args[0].(uuid.UUID))
+		return pgconn.NewCommandTag("DELETE 1"), nil
+	}
+	return pgconn.CommandTag{}, errQuerierStub
+}
+
+func (tx *verificationTx) Commit(ctx context.Context) error {
+	for userID, at := range tx.verified {
+		tx.store.verified[userID] = at
+	}
+	for _, userID := range tx.deleted {
+		delete(tx.store.tokens, userID)
+	}
+	return nil
+}
+
+func (tx *verificationTx) Rollback(ctx context.Context) error {
+	return nil
+}
+
+func TestEmailVerificationRepository_Verify(t *testing.T) {
+	userID := uuid.New()
+	store := &verificationStore{tokens: map[uuid.UUID]string{userID: "hash"}, verified: map[uuid.UUID]time.Time{}}
+	repo := NewEmailVerificationRepository(database.NewDBWithQueriers(store, nil))
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	verified, err := repo.Verify(context.Background(), "hash", now)
+
+	require.NoError(t, err)
+	assert.Equal(t, userID, verified)
+	assert.Equal(t, now, store.verified[userID])
+	assert.Empty(t, store.tokens, "used token can be used again")
+
+	_, err = repo.Verify(context.Background(), "hash", now)
+	assert.ErrorIs(t, err, ErrInvalidEmailVerificationToken)
+}
+
+func TestEmailVerificationRepository_VerifyRejectsExpiredToken(t *testing.T) {
+	userID := uuid.New()
+	store := &verificationStore{
+		tokens:   map[uuid.UUID]string{userID: "hash"},
+		expired:  map[string]bool{"hash": true},
+		verified: map[uuid.UUID]time.Time{},
+	}
+	repo := NewEmailVerificationRepository(database.NewDBWithQueriers(store, nil))
+
+	_, err := repo.Verify(context.Background(), "hash", time.Now())
+
+	assert.ErrorIs(t, err, ErrInvalidEmailVerificationToken)
+	assert.Empty(t, store.verified)
+}
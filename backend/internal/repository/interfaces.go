@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"backend/internal/graph/model"
 	"github.com/google/uuid"
@@ -13,9 +14,18 @@ type UserRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*model.User, error)
 	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.User, error)
 	GetByEmail(ctx context.Context, email string) (*model.User, error)
+	ExistsByEmail(ctx context.Context, email string) (bool, error)
+	ExistsByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]bool, error)
+	ExistsByEmails(ctx context.Context, emails []string) (map[string]bool, error)
 	Update(ctx context.Context, user *model.User) error
+	UpdateEmail(ctx context.Context, id uuid.UUID, email string) error
+	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error
+	RecordLogin(ctx context.Context, id uuid.UUID, at time.Time, ip string) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	List(ctx context.Context, limit, offset int) ([]*model.User, error)
+	SearchUsers(ctx context.Context, query string, includeEmail bool, limit int) ([]*model.User, error)
+	Count(ctx context.Context) (int, error)
+	CountCreatedSince(ctx context.Context, since time.Time) (int, error)
 }
 
 // PostRepository defines the interface for post data operations
@@ -24,11 +34,20 @@ type PostRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*model.Post, error)
 	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Post, error)
 	GetByAuthorID(ctx context.Context, authorID uuid.UUID, limit, offset int) ([]*model.Post, error)
+	GetPublishedByAuthorIDs(ctx context.Context, authorIDs []uuid.UUID, limit int) (map[uuid.UUID][]*model.Post, error)
+	Exists(ctx context.Context, id uuid.UUID) (bool, error)
+	SetHidden(ctx context.Context, id uuid.UUID, hidden bool, reason *string) error
+	SetPublished(ctx context.Context, id uuid.UUID, published bool, publishAt *time.Time) error
 	Update(ctx context.Context, post *model.Post) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	DeletePostWithComments(ctx context.Context, id uuid.UUID, dryRun bool) (int, error)
+	MergeTags(ctx context.Context, from, into string, dryRun bool) ([]uuid.UUID, error)
 	List(ctx context.Context, filters *PostFilters, limit, offset int) ([]*model.Post, error)
+	ListWithAuthors(ctx context.Context, filters *PostFilters, limit, offset int) ([]*model.Post, error)
 	Search(ctx context.Context, query string, limit int) ([]*model.Post, error)
 	Count(ctx context.Context, filters *PostFilters) (int, error)
+	CountByPublished(ctx context.Context) (published, unpublished int, err error)
+	PopularTags(ctx context.Context, limit, offset int) ([]*model.TagCount, error)
 }
 
 // CommentRepository defines the interface for comment data operations
@@ -36,9 +55,111 @@ type CommentRepository interface {
 	Create(ctx context.Context, comment *model.Comment) error
 	GetByID(ctx context.Context, id uuid.UUID) (*model.Comment, error)
 	GetByPostID(ctx context.Context, postID uuid.UUID, limit, offset int) ([]*model.Comment, error)
+	SetHidden(ctx context.Context, id uuid.UUID, hidden bool, reason *string) error
 	Update(ctx context.Context, comment *model.Comment) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	Count(ctx context.Context, postID uuid.UUID) (int, error)
+	CountAll(ctx context.Context) (int, error)
+	SummaryByPostIDs(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID]*model.CommentSummary, error)
+}
+
+// ReactionRepository defines the interface for post reaction data operations
+type ReactionRepository interface {
+	Add(ctx context.Context, reaction *model.Reaction) error
+	Remove(ctx context.Context, postID, userID uuid.UUID, reactionType string) error
+	CountByPostIDs(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID]int, error)
+	CountByTypeByPostIDs(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID][]*model.ReactionTypeCount, error)
+	ViewerReactedByPostIDs(ctx context.Context, viewerID uuid.UUID, postIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+}
+
+// ReportRepository defines the interface for content report operations
+type ReportRepository interface {
+	// Create stores a report, or returns the reporter's open report of the
+	// same content instead when there is one
+	Create(ctx context.Context, report *model.Report) (*model.Report, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Report, error)
+	ListByStatus(ctx context.Context, status model.ReportStatus, limit, offset int) ([]*model.Report, error)
+	// Resolve closes an open report with the given status, together with
+	// every other open report of the same content, in one transaction. With
+	// hide, the content is hidden with the report's reason as the moderation
+	// note in the same transaction. It returns how many reports were closed,
+	// or ErrReportNotOpen.
+	Resolve(ctx context.Context, id uuid.UUID, status model.ReportStatus, hide bool, at time.Time) (int, error)
+}
+
+// PasswordHistoryRepository defines the interface for previously used password hashes
+type PasswordHistoryRepository interface {
+	Add(ctx context.Context, userID uuid.UUID, passwordHash string) error
+	GetRecent(ctx context.Context, userID uuid.UUID, limit int) ([]string, error)
+	Prune(ctx context.Context, userID uuid.UUID, keep int) error
+}
+
+// EmailChangeRepository defines the interface for pending email change requests
+type EmailChangeRepository interface {
+	Create(ctx context.Context, req *model.EmailChangeRequest) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*model.EmailChangeRequest, error)
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+// EmailVerificationRepository defines the interface for email verification tokens
+type EmailVerificationRepository interface {
+	Create(ctx context.Context, token *model.EmailVerificationToken) error
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+	IsVerified(ctx context.Context, userID uuid.UUID) (bool, error)
+
+	// Verify marks the email address of the user holding the unexpired token
+	// with tokenHash as verified and deletes their verification tokens, in
+	// one transaction. It returns the user's ID, or
+	// ErrInvalidEmailVerificationToken.
+	Verify(ctx context.Context, tokenHash string, now time.Time) (uuid.UUID, error)
+}
+
+// ImportedUser is a user to create without a password, together with the
+// token they will set their password with
+type ImportedUser struct {
+	User  *model.User
+	Token *model.PasswordSetupToken
+}
+
+// PasswordSetupRepository creates users without passwords along with their
+// password setup tokens
+type PasswordSetupRepository interface {
+	// CreateUsers inserts users and their tokens in one transaction. A user
+	// whose email is already registered is skipped along with their token;
+	// created reports, per user, whether they were inserted.
+	CreateUsers(ctx context.Context, users []ImportedUser) (created []bool, err error)
+
+	// Redeem sets the password of the user holding the unexpired token with
+	// tokenHash and deletes their setup tokens, in one transaction. It returns
+	// the user's ID, or ErrInvalidPasswordSetupToken.
+	Redeem(ctx context.Context, tokenHash, passwordHash string, now time.Time) (uuid.UUID, error)
+}
+
+// RefreshTokenRepository stores issued refresh tokens so they can be rotated
+// and revoked
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *model.RefreshToken) error
+	// Use marks the unused, unrevoked and unexpired token with tokenHash as
+	// used and returns it. A token that was already used is returned with
+	// ErrRefreshTokenReused; any other token gives ErrRefreshTokenInvalid.
+	Use(ctx context.Context, tokenHash string, now time.Time) (*model.RefreshToken, error)
+	// RevokeFamily revokes the token with tokenHash and every token in its family
+	RevokeFamily(ctx context.Context, tokenHash string, at time.Time) error
+	// RevokeByUserID revokes every token of the user
+	RevokeByUserID(ctx context.Context, userID uuid.UUID, at time.Time) error
+}
+
+// AuditLogRepository defines the interface for audit log data operations
+type AuditLogRepository interface {
+	Create(ctx context.Context, entry *model.AuditLog) error
+}
+
+// JWTKeyRepository stores rotated JWT signing keys
+type JWTKeyRepository interface {
+	Create(ctx context.Context, key *model.JWTSigningKey) error
+	// ListSince returns, oldest first, the keys that were current at or
+	// after since: those created since then and the one current at since
+	ListSince(ctx context.Context, since time.Time) ([]*model.JWTSigningKey, error)
 }
 
 // PostFilters represents filters for post queries
@@ -47,4 +168,22 @@ type PostFilters struct {
 	Published  *bool
 	Tags       []string
 	SearchTerm *string
+	// IncludeHidden shows hidden posts to moderators; otherwise hidden posts
+	// are only listed for their author, identified by ViewerID
+	IncludeHidden bool
+	ViewerID      *uuid.UUID
+	// IncludeDrafts lists unpublished posts by anyone; otherwise only
+	// published posts and the viewer's own drafts are listed
+	IncludeDrafts bool
+	// Sort orders List results, newest first when empty; After continues
+	// a listing past a post. Neither affects Count.
+	Sort  model.PostSort
+	After *PostKeyset
+}
+
+// PostKeyset positions a listing after a previously returned post
+type PostKeyset struct {
+	// Value is the post's sort column: a time.Time, or the title for TITLE
+	Value interface{}
+	ID    uuid.UUID
 }
\ No newline at end of file
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend/internal/database"
+	"backend/internal/graph/model"
+)
+
+// jwtKeyRepository implements JWTKeyRepository interface
+type jwtKeyRepository struct {
+	db *database.DB
+}
+
+// NewJWTKeyRepository creates a new JWT signing key repository
+func NewJWTKeyRepository(db *database.DB) JWTKeyRepository {
+	return &jwtKeyRepository{db: db}
+}
+
+// Create stores a rotated signing key
+func (r *jwtKeyRepository) Create(ctx context.Context, key *model.JWTSigningKey) error {
+	query := `INSERT INTO jwt_signing_keys (id, secret, created_at) VALUES ($1, $2, $3)`
+
+	if _, err := r.db.Writer().Exec(ctx, query, key.ID, key.Secret, key.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create JWT signing key: %w", err)
+	}
+
+	return nil
+}
+
+// ListSince returns, oldest first, the keys created at or after since and
+// the newest key created before it, which was still current at since
+func (r *jwtKeyRepository) ListSince(ctx context.Context, since time.Time) ([]*model.JWTSigningKey, error) {
+	query := `
+		SELECT id, secret, created_at
+		FROM jwt_signing_keys
+		WHERE created_at >= (
+			SELECT COALESCE(MAX(created_at), '-infinity')
+			FROM jwt_signing_keys
+			WHERE created_at < $1
+		)
+		ORDER BY created_at ASC, id ASC
+	`
+
+	// Read from the primary: another instance may have rotated moments ago
+	rows, err := r.db.Writer().Query(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list JWT signing keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*model.JWTSigningKey{}
+	for rows.Next() {
+		var key model.JWTSigningKey
+		if err := rows.Scan(&key.ID, &key.Secret, &key.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan JWT signing key: %w", err)
+		}
+		keys = append(keys, &key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating JWT signing keys: %w", err)
+	}
+
+	return keys, nil
+}
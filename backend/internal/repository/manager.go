@@ -1,19 +1,48 @@
 package repository
 
-import "backend/internal/database"
+import (
+	"backend/internal/database"
+	"backend/internal/graph/model"
+)
 
 // Manager holds all repository instances
 type Manager struct {
 	User    UserRepository
 	Post    PostRepository
 	Comment CommentRepository
+
+	Reaction          ReactionRepository
+	Report            ReportRepository
+	EmailChange       EmailChangeRepository
+	EmailVerification EmailVerificationRepository
+	PasswordHistory   PasswordHistoryRepository
+	PasswordSetup     PasswordSetupRepository
+	RefreshToken      RefreshTokenRepository
+	AuditLog          AuditLogRepository
+	JWTKey            JWTKeyRepository
 }
 
 // NewManager creates a new repository manager with all repositories
 func NewManager(db *database.DB) *Manager {
+	return NewManagerWithTagLimits(db, model.DefaultPostTagLimits())
+}
+
+// NewManagerWithTagLimits creates a new repository manager whose post
+// repository enforces the given tag limits
+func NewManagerWithTagLimits(db *database.DB, tagLimits model.PostTagLimits) *Manager {
 	return &Manager{
 		User:    NewUserRepository(db),
-		Post:    NewPostRepository(db),
+		Post:    NewPostRepositoryWithTagLimits(db, tagLimits),
 		Comment: NewCommentRepository(db),
+
+		Reaction:          NewReactionRepository(db),
+		Report:            NewReportRepository(db),
+		EmailChange:       NewEmailChangeRepository(db),
+		EmailVerification: NewEmailVerificationRepository(db),
+		PasswordHistory:   NewPasswordHistoryRepository(db),
+		PasswordSetup:     NewPasswordSetupRepository(db),
+		RefreshToken:      NewRefreshTokenRepository(db),
+		AuditLog:          NewAuditLogRepository(db),
+		JWTKey:            NewJWTKeyRepository(db),
 	}
 }
\ No newline at end of file
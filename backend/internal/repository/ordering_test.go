@@ -0,0 +1,302 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// orderedRow is a stored row with the columns list queries sort by
+type orderedRow struct {
+	id        uuid.UUID
+	createdAt time.Time
+	values    []any
+}
+
+// orderingQuerier returns its rows in a fresh random order on every query,
+// as a database may for rows that tie, then applies the query's ORDER BY
+// on created_at and id
+type orderingQuerier struct {
+	recordingQuerier
+	rows []orderedRow
+	rng  *rand.Rand
+	sql  []string
+}
+
+var orderByPattern = regexp.MustCompile(`(?s)ORDER BY (.+?)\s+LIMIT`)
+
+func (q *orderingQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	q.queries++
+	q.sql = append(q.sql, sql)
+
+	rows := append([]orderedRow(nil), q.rows...)
+	q.rng.Shuffle(len(rows), func(i, j int) { rows[i], rows[j] = rows[j], rows[i] })
+
+	var keys []string
+	if match := orderByPattern.FindStringSubmatch(sql); match != nil {
+		for _, key := range strings.Split(match[1], ",") {
+			keys = append(keys, strings.TrimSpace(key))
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, key := range keys {
+			var cmp int
+			switch strings.Fields(key)[0] {
+			case "created_at":
+				cmp = rows[i].createdAt.Compare(rows[j].createdAt)
+			case "id":
+				cmp = bytes.Compare(rows[i].id[:], rows[j].id[:])
+			}
+			if strings.HasSuffix(key, "DESC") {
+				cmp = -cmp
+			}
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+
+	values := make([][]any, len(rows))
+	for i, row := range rows {
+		values[i] = row.values
+	}
+	return &tableRows{rows: values, pos: -1}, nil
+}
+
+// tiedRows builds n rows sharing one timestamp, with values built per id
+func tiedRows(n int, build func(id uuid.UUID, createdAt time.Time) []any) []orderedRow {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	rows := make([]orderedRow, n)
+	for i := range rows {
+		id := uuid.New()
+		rows[i] = orderedRow{id: id, createdAt: createdAt, values: build(id, createdAt)}
+	}
+	return rows
+}
+
+// sortedIDs returns the ids of rows in ascending or descending order
+func sortedIDs(rows []orderedRow, descending bool) []uuid.UUID {
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.id
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if descending {
+			return bytes.Compare(ids[i][:], ids[j][:]) > 0
+		}
+		return bytes.Compare(ids[i][:], ids[j][:]) < 0
+	})
+	return ids
+}
+
+func TestListQueries_TiedTimestampsOrderStably(t *testing.T) {
+	postRow := func(id uuid.UUID, createdAt time.Time) []any {
+		return []any{id, "Same time", "Content", uuid.New(), []string{}, true, createdAt, createdAt, false, (*string)(nil)}
+	}
+	commentRow := func(id uuid.UUID, createdAt time.Time) []any {
+		return []any{id, "Same time", uuid.New(), uuid.New(), createdAt, createdAt, (*uuid.UUID)(nil), 0, false, (*string)(nil)}
+	}
+	userRow := func(id uuid.UUID, createdAt time.Time) []any {
+		return []any{id, id.String() + "@example.com", "Same time", "hash", (*string)(nil), createdAt, createdAt}
+	}
+	hashRow := func(id uuid.UUID, createdAt time.Time) []any {
+		return []any{id.String()}
+	}
+
+	tests := []struct {
+		name       string
+		rows       []orderedRow
+		descending bool
+		list       func(ctx context.Context, db *database.DB) ([]uuid.UUID, error)
+	}{
+		{
+			name:       "posts by author",
+			rows:       tiedRows(6, postRow),
+			descending: true,
+			list: func(ctx context.Context, db *database.DB) ([]uuid.UUID, error) {
+				posts, err := NewPostRepository(db).GetByAuthorID(ctx, uuid.New(), 10, 0)
+				ids := []uuid.UUID{}
+				for _, post := range posts {
+					ids = append(ids, post.ID)
+				}
+				return ids, err
+			},
+		},
+		{
+			name:       "post list",
+			rows:       tiedRows(6, postRow),
+			descending: true,
+			list: func(ctx context.Context, db *database.DB) ([]uuid.UUID, error) {
+				posts, err := NewPostRepository(db).List(ctx, nil, 10, 0)
+				ids := []uuid.UUID{}
+				for _, post := range posts {
+					ids = append(ids, post.ID)
+				}
+				return ids, err
+			},
+		},
+		{
+			name:       "post search",
+			rows:       tiedRows(6, postRow),
+			descending: true,
+			list: func(ctx context.Context, db *database.DB) ([]uuid.UUID, error) {
+				posts, err := NewPostRepository(db).Search(ctx, "same", 10)
+				ids := []uuid.UUID{}
+				for _, post := range posts {
+					ids = append(ids, post.ID)
+				}
+				return ids, err
+			},
+		},
+		{
+			name: "comments on a post",
+			rows: tiedRows(6, commentRow),
+			list: func(ctx context.Context, db *database.DB) ([]uuid.UUID, error) {
+				comments, err := NewCommentRepository(db).GetByPostID(ctx, uuid.New(), 10, 0)
+				ids := []uuid.UUID{}
+				for _, comment := range comments {
+					ids = append(ids, comment.ID)
+				}
+				return ids, err
+			},
+		},
+		{
+			name:       "users",
+			rows:       tiedRows(6, userRow),
+			descending: true,
+			list: func(ctx context.Context, db *database.DB) ([]uuid.UUID, error) {
+				users, err := NewUserRepository(db).List(ctx, 10, 0)
+				ids := []uuid.UUID{}
+				for _, user := range users {
+					ids = append(ids, user.ID)
+				}
+				return ids, err
+			},
+		},
+		{
+			name:       "password history",
+			rows:       tiedRows(6, hashRow),
+			descending: true,
+			list: func(ctx context.Context, db *database.DB) ([]uuid.UUID, error) {
+				hashes, err := NewPasswordHistoryRepository(db).GetRecent(ctx, uuid.New(), 10)
+				ids := []uuid.UUID{}
+				for _, hash := range hashes {
+					ids = append(ids, uuid.MustParse(hash))
+				}
+				return ids, err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			querier := &orderingQuerier{rows: tt.rows, rng: rand.New(rand.NewSource(1))}
+			db := database.NewDBWithQueriers(querier, querier)
+			expected := sortedIDs(tt.rows, tt.descending)
+
+			for i := 0; i < 5; i++ {
+				ids, err := tt.list(context.Background(), db)
+
+				require.NoError(t, err)
+				assert.Equal(t, expected, ids, "query %d returned tied rows in a different order", i+1)
+			}
+		})
+	}
+}
+
+// tagCountQuerier returns tag counts in a fresh random order on every query,
+// then applies the query's ORDER BY on post_count and tag
+type tagCountQuerier struct {
+	recordingQuerier
+	counts map[string]int
+	rng    *rand.Rand
+	sql    []string
+	args   [][]any
+}
+
+func (q *tagCountQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	q.queries++
+	q.sql = append(q.sql, sql)
+	q.args = append(q.args, args)
+
+	tags := make([]string, 0, len(q.counts))
+	for tag := range q.counts {
+		tags = append(tags, tag)
+	}
+	q.rng.Shuffle(len(tags), func(i, j int) { tags[i], tags[j] = tags[j], tags[i] })
+
+	var keys []string
+	if match := orderByPattern.FindStringSubmatch(sql); match != nil {
+		for _, key := range strings.Split(match[1], ",") {
+			keys = append(keys, strings.TrimSpace(key))
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		for _, key := range keys {
+			var cmp int
+			switch strings.Fields(key)[0] {
+			case "post_count":
+				cmp = q.counts[tags[i]] - q.counts[tags[j]]
+			case "tag":
+				cmp = strings.Compare(tags[i], tags[j])
+			}
+			if strings.HasSuffix(key, "DESC") {
+				cmp = -cmp
+			}
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+
+	limit, offset := args[0].(int), args[1].(int)
+	tags = tags[min(offset, len(tags)):min(offset+limit, len(tags))]
+	values := make([][]any, len(tags))
+	for i, tag := range tags {
+		values[i] = []any{tag, q.counts[tag]}
+	}
+	return &tableRows{rows: values, pos: -1}, nil
+}
+
+func TestPostRepository_PopularTagsOrderStably(t *testing.T) {
+	querier := &tagCountQuerier{
+		counts: map[string]int{"go": 5, "rust": 3, "zig": 3, "c": 3, "elm": 1, "ada": 1},
+		rng:    rand.New(rand.NewSource(1)),
+	}
+	repo := NewPostRepository(database.NewDBWithQueriers(querier, querier))
+
+	for i := 0; i < 5; i++ {
+		var pages [][]string
+		for offset := 0; offset < 6; offset += 4 {
+			tags, err := repo.PopularTags(context.Background(), 4, offset)
+			require.NoError(t, err)
+
+			page := []string{}
+			for _, tag := range tags {
+				assert.Equal(t, querier.counts[tag.Tag], tag.Count)
+				page = append(page, tag.Tag)
+			}
+			pages = append(pages, page)
+		}
+
+		assert.Equal(t, [][]string{{"go", "c", "rust", "zig"}, {"ada", "elm"}}, pages, "query %d paged tied tags differently", i+1)
+	}
+
+	assert.Contains(t, querier.sql[0], "unnest(tags)")
+	assert.Contains(t, querier.sql[0], "GROUP BY tag")
+	assert.Contains(t, querier.sql[0], "published = true AND hidden = false")
+	assert.Equal(t, []any{4, 4}, querier.args[1])
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxListLimit caps the rows one list call returns, whatever the caller asks for
+const MaxListLimit = 1000
+
+// MaxListOffset is the deepest offset list calls accept, bounding how many
+// rows one query scans past
+const MaxListOffset = 100000
+
+// ErrInvalidPagination is returned for a negative limit or offset, or an
+// offset beyond MaxListOffset
+var ErrInvalidPagination = errors.New("invalid pagination")
+
+// checkPage validates the limit and offset of a list call, lowering a limit
+// above MaxListLimit to it. The GraphQL layer applies its own, tighter
+// policy; this protects every other caller.
+func checkPage(limit, offset int) (int, error) {
+	if limit < 0 {
+		return 0, fmt.Errorf("%w: limit %d is negative", ErrInvalidPagination, limit)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("%w: offset %d is negative", ErrInvalidPagination, offset)
+	}
+	if offset > MaxListOffset {
+		return 0, fmt.Errorf("%w: offset %d exceeds %d", ErrInvalidPagination, offset, MaxListOffset)
+	}
+	return min(limit, MaxListLimit), nil
+}
+
+// checkLimit is checkPage for list calls without an offset
+func checkLimit(limit int) (int, error) {
+	return checkPage(limit, 0)
+}
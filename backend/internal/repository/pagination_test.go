@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"backend/internal/database"
+	"backend/internal/graph/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// argsQuerier records the arguments of each query and returns no rows
+type argsQuerier struct {
+	recordingQuerier
+	args [][]any
+}
+
+func (q *argsQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	q.queries++
+	q.args = append(q.args, args)
+	return &tableRows{pos: -1}, nil
+}
+
+func TestListMethods_CheckPagination(t *testing.T) {
+	querier := &argsQuerier{}
+	repos := NewManager(database.NewDBWithQueriers(querier, querier))
+
+	tests := []struct {
+		name string
+		list func(ctx context.Context, limit, offset int) error
+		// limitArg is the position of the limit among the query arguments
+		limitArg int
+		// withOffset is false for methods that only take a limit
+		withOffset bool
+	}{
+		{
+			name: "posts by author",
+			list: func(ctx context.Context, limit, offset int) error {
+				_, err := repos.Post.GetByAuthorID(ctx, uuid.New(), limit, offset)
+				return err
+			},
+			limitArg:   1,
+			withOffset: true,
+		},
+		{
+			name: "post list",
+			list: func(ctx context.Context, limit, offset int) error {
+				_, err := repos.Post.List(ctx, nil, limit, offset)
+				return err
+			},
+			limitArg:   0,
+			withOffset: true,
+		},
+		{
+			name: "post list with authors",
+			list: func(ctx context.Context, limit, offset int) error {
+				_, err := repos.Post.ListWithAuthors(ctx, nil, limit, offset)
+				return err
+			},
+			limitArg:   0,
+			withOffset: true,
+		},
+		{
+			name: "popular tags",
+			list: func(ctx context.Context, limit, offset int) error {
+				_, err := repos.Post.PopularTags(ctx, limit, offset)
+				return err
+			},
+			limitArg:   0,
+			withOffset: true,
+		},
+		{
+			name: "comments on a post",
+			list: func(ctx context.Context, limit, offset int) error {
+				_, err := repos.Comment.GetByPostID(ctx, uuid.New(), limit, offset)
+				return err
+			},
+			limitArg:   1,
+			withOffset: true,
+		},
+		{
+			name: "reports",
+			list: func(ctx context.Context, limit, offset int) error {
+				_, err := repos.Report.ListByStatus(ctx, model.ReportStatusOpen, limit, offset)
+				return err
+			},
+			limitArg:   1,
+			withOffset: true,
+		},
+		{
+			name: "users",
+			list: func(ctx context.Context, limit, offset int) error {
+				_, err := repos.User.List(ctx, limit, offset)
+				return err
+			},
+			limitArg:   0,
+			withOffset: true,
+		},
+		{
+			name: "post search",
+			list: func(ctx context.Context, limit, offset int) error {
+				_, err := repos.Post.Search(ctx, "go", limit)
+				return err
+			},
+			limitArg: 1,
+		},
+		{
+			name: "user search",
+			list: func(ctx context.Context, limit, offset int) error {
+				_, err := repos.User.SearchUsers(ctx, "ann", false, limit)
+				return err
+			},
+			limitArg: 2,
+		},
+		{
+			name: "posts by authors",
+			list: func(ctx context.Context, limit, offset int) error {
+				_, err := repos.Post.GetPublishedByAuthorIDs(ctx, []uuid.UUID{uuid.New()}, limit)
+				return err
+			},
+			limitArg: 1,
+		},
+		{
+			name: "password history",
+			list: func(ctx context.Context, limit, offset int) error {
+				_, err := repos.PasswordHistory.GetRecent(ctx, uuid.New(), limit)
+				return err
+			},
+			limitArg: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			t.Run("negative values are rejected before querying", func(t *testing.T) {
+				querier.queries = 0
+				assert.ErrorIs(t, tt.list(ctx, -1, 0), ErrInvalidPagination)
+				if tt.withOffset {
+					assert.ErrorIs(t, tt.list(ctx, 10, -1), ErrInvalidPagination)
+					assert.ErrorIs(t, tt.list(ctx, 10, MaxListOffset+1), ErrInvalidPagination)
+				}
+				assert.Zero(t, querier.queries)
+			})
+
+			t.Run("oversized limits are capped", func(t *testing.T) {
+				querier.args = nil
+				require.NoError(t, tt.list(ctx, MaxListLimit*10, 0))
+				require.NoError(t, tt.list(ctx, 25, 0))
+
+				require.Len(t, querier.args, 2)
+				assert.Equal(t, MaxListLimit, querier.args[0][tt.limitArg])
+				assert.Equal(t, 25, querier.args[1][tt.limitArg])
+			})
+		})
+	}
+}
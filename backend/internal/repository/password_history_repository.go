@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"backend/internal/database"
+	"backend/internal/ids"
+	"github.com/google/uuid"
+)
+
+// passwordHistoryRepository implements PasswordHistoryRepository interface
+type passwordHistoryRepository struct {
+	db *database.DB
+}
+
+// NewPasswordHistoryRepository creates a new password history repository
+func NewPasswordHistoryRepository(db *database.DB) PasswordHistoryRepository {
+	return &passwordHistoryRepository{db: db}
+}
+
+// Add records a password hash the user has used
+func (r *passwordHistoryRepository) Add(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	query := `
+		INSERT INTO password_history (id, user_id, password_hash)
+		VALUES ($1, $2, $3)
+	`
+
+	if _, err := r.db.Writer().Exec(ctx, query, ids.New(), userID, passwordHash); err != nil {
+		return fmt.Errorf("failed to add password history: %w", err)
+	}
+
+	return nil
+}
+
+// GetRecent retrieves a user's most recent password hashes, newest first
+func (r *passwordHistoryRepository) GetRecent(ctx context.Context, userID uuid.UUID, limit int) ([]string, error) {
+	limit, err := checkLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT password_hash
+		FROM password_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`
+
+	// Read from the primary: the check must see the latest change
+	rows, err := r.db.Writer().Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get password history: %w", err)
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("failed to scan password history: %w", err)
+		}
+		hashes = append(hashes, hash)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating password history: %w", err)
+	}
+
+	return hashes, nil
+}
+
+// Prune deletes all but the newest keep entries for a user
+func (r *passwordHistoryRepository) Prune(ctx context.Context, userID uuid.UUID, keep int) error {
+	query := `
+		DELETE FROM password_history
+		WHERE user_id = $1 AND id NOT IN (
+			SELECT id FROM password_history
+			WHERE user_id = $1
+			ORDER BY created_at DESC, id DESC
+			LIMIT $2
+		)
+	`
+
+	if _, err := r.db.Writer().Exec(ctx, query, userID, keep); err != nil {
+		return fmt.Errorf("failed to prune password history: %w", err)
+	}
+
+	return nil
+}
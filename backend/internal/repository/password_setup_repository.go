@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrInvalidPasswordSetupToken is returned when a password setup token is
+// unknown, expired or already used
+var ErrInvalidPasswordSetupToken = errors.New("invalid or expired password setup token")
+
+// passwordSetupRepository implements PasswordSetupRepository interface
+type passwordSetupRepository struct {
+	db *database.DB
+}
+
+// NewPasswordSetupRepository creates a new password setup token repository
+func NewPasswordSetupRepository(db *database.DB) PasswordSetupRepository {
+	return &passwordSetupRepository{db: db}
+}
+
+// CreateUsers inserts users without passwords and their setup tokens in one
+// transaction, skipping users whose email is already registered
+func (r *passwordSetupRepository) CreateUsers(ctx context.Context, users []ImportedUser) ([]bool, error) {
+	created := make([]bool, len(users))
+	if len(users) == 0 {
+		return created, nil
+	}
+
+	userQuery := `
+		INSERT INTO users (id, email, name, password_hash, avatar, created_at, updated_at)
+		VALUES ($1, $2, $3, '', NULL, $4, $5)
+		ON CONFLICT (email) DO NOTHING
+	`
+	tokenQuery := `
+		INSERT INTO password_setup_tokens (id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	err := r.db.WithTx(ctx, func(tx database.Querier) error {
+		for i, imported := range users {
+			user, token := imported.User, imported.Token
+			result, err := tx.Exec(ctx, userQuery, user.ID, user.Email, user.Name, user.CreatedAt, user.UpdatedAt)
+			if err != nil {
+				return fmt.Errorf("failed to create user %s: %w", user.Email, err)
+			}
+			if result.RowsAffected() == 0 {
+				continue
+			}
+
+			_, err = tx.Exec(ctx, tokenQuery, token.ID, user.ID, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+			if err != nil {
+				return fmt.Errorf("failed to create password setup token for %s: %w", user.Email, err)
+			}
+			created[i] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// Redeem sets the password of the user holding the unexpired token with
+// tokenHash and deletes all of their setup tokens in one transaction, so a
+// token can be used once. Only users still without a password are updated.
+func (r *passwordSetupRepository) Redeem(ctx context.Context, tokenHash, passwordHash string, now time.Time) (uuid.UUID, error) {
+	claimQuery := `
+		DELETE FROM password_setup_tokens
+		WHERE token_hash = $1 AND expires_at > $2
+		RETURNING user_id
+	`
+	passwordQuery := `
+		UPDATE users SET password_hash = $1, updated_at = $2
+		WHERE id = $3 AND password_hash = ''
+	`
+	cleanupQuery := `DELETE FROM password_setup_tokens WHERE user_id = $1`
+
+	var userID uuid.UUID
+	err := r.db.WithTx(ctx, func(tx database.Querier) error {
+		if err := tx.QueryRow(ctx, claimQuery, tokenHash, now).Scan(&userID); err != nil {
+			if err == pgx.ErrNoRows {
+				return ErrInvalidPasswordSetupToken
+			}
+			return fmt.Errorf("failed to claim password setup token: %w", err)
+		}
+
+		result, err := tx.Exec(ctx, passwordQuery, passwordHash, now, userID)
+		if err != nil {
+			return fmt.Errorf("failed to set password: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return ErrInvalidPasswordSetupToken
+		}
+
+		if _, err := tx.Exec(ctx, cleanupQuery, userID); err != nil {
+			return fmt.Errorf("failed to delete password setup tokens: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return userID, nil
+}
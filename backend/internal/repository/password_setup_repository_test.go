@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/database"
+	"backend/internal/graph/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupStore is an in-memory users and password_setup_tokens table pair
+// that only applies a transaction's inserts when it commits
+type setupStore struct {
+	recordingQuerier
+	emails     map[string]bool
+	tokens     map[uuid.UUID]string // user ID -> token hash
+	expired    map[string]bool      // token hashes past their expiry
+	passwords  map[uuid.UUID]string // user ID -> password hash
+	failTokens bool
+}
+
+func (s *setupStore) Begin(ctx context.Context) (pgx.Tx, error) {
+	return &setupTx{store: s, emails: map[string]bool{}, tokens: map[uuid.UUID]string{}, passwords: map[uuid.UUID]string{}}, nil
+}
+
+// setupTx stages inserts, password updates and token deletions until Commit
+type setupTx struct {
+	pgx.Tx
+	store     *setupStore
+	emails    map[string]bool
+	tokens    map[uuid.UUID]string
+	passwords map[uuid.UUID]string
+	deleted   []uuid.UUID
+}
+
+func (tx *setupTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if strings.Contains(sql, "DELETE FROM password_setup_tokens") {
+		hash := args[0].(string)
+		for userID, stored := range tx.store.tokens {
+			if stored == hash && !tx.store.expired[hash] {
+				tx.deleted = append(tx.deleted, userID)
+				return userIDRow{id: userID}
+			}
+		}
+		return userIDRow{}
+	}
+	return stubRow{}
+}
+
+// userIDRow is a RETURNING user_id row; the zero value has no rows
+type userIDRow struct {
+	id uuid.UUID
+}
+
+func (r userIDRow) Scan(dest ...any) error {
+	if r.id == uuid.Nil {
+		return pgx.ErrNoRows
+	}
+	*dest[0].(*uuid.UUID) = r.id
+	return nil
+}
+
+func (tx *setupTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	switch {
+	case strings.Contains(sql, "INSERT INTO users"):
+		email := args[1].(string)
+		if tx.store.emails[email] || tx.emails[email] {
+			return pgconn.NewCommandTag("INSERT 0 0"), nil
+		}
+		tx.emails[email] = true
+		return pgconn.NewCommandTag("INSERT 0 1"), nil
+	case strings.Contains(sql, "INSERT INTO password_setup_tokens"):
+		if tx.store.failTokens {
+			return pgconn.CommandTag{}, errQuerierStub
+		}
+		tx.tokens[args[1].(uuid.UUID)] = args[2].(string)
+		return pgconn.NewCommandTag("INSERT 0 1"), nil
+	case strings.Contains(sql, "UPDATE users SET password_hash"):
+		userID := args[2].(uuid.UUID)
+		if tx.store.passwords[userID] != "" {
+			return pgconn.NewCommandTag("UPDATE 0"), nil
+		}
+		tx.passwords[userID] = args[0].(string)
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	case strings.Contains(sql, "DELETE FROM password_setup_tokens WHERE user_id"):
+		tx.deleted = append(tx.deleted, args[0].(uuid.UUID))
+		return pgconn.NewCommandTag("DELETE 1"), nil
+	}
+	return pgconn.CommandTag{}, errQuerierStub
+}
+
+func (tx *setupTx) Commit(ctx context.Context) error {
+	for email := range tx.emails {
+		tx.store.emails[email] = true
+	}
+	for userID, hash := range tx.tokens {
+		tx.store.tokens[userID] = hash
+	}
+	for userID, hash := range tx.passwords {
+		tx.store.passwords[userID] = hash
+	}
+	for _, userID := range tx.deleted {
+		delete(tx.store.tokens, userID)
+	}
+	return nil
+}
+
+func (tx *setupTx) Rollback(ctx context.Context) error {
+	return nil
+}
+
+func importedUser(email string) ImportedUser {
+	now := time.Now()
+	user := &model.User{ID: uuid.New(), Email: email, Name: "Imported", CreatedAt: now, UpdatedAt: now}
+	token := &model.PasswordSetupToken{ID: uuid.New(), UserID: user.ID, TokenHash: "hash-" + email, ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+	return ImportedUser{User: user, Token: token}
+}
+
+func TestPasswordSetupRepository_CreateUsers(t *testing.T) {
+	store := &setupStore{emails: map[string]bool{"existing@example.com": true}, tokens: map[uuid.UUID]string{}}
+	repo := NewPasswordSetupRepository(database.NewDBWithQueriers(store, nil))
+
+	users := []ImportedUser{importedUser("new@example.com"), importedUser("existing@example.com")}
+	created, err := repo.CreateUsers(context.Background(), users)
+
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false}, created)
+	assert.True(t, store.emails["new@example.com"])
+	assert.Equal(t, "hash-new@example.com", store.tokens[users[0].User.ID])
+	assert.NotContains(t, store.tokens, users[1].User.ID, "existing user got a setup token")
+}
+
+func TestPasswordSetupRepository_CreateUsersRollsBack(t *testing.T) {
+	store := &setupStore{emails: map[string]bool{}, tokens: map[uuid.UUID]string{}, failTokens: true}
+	repo := NewPasswordSetupRepository(database.NewDBWithQueriers(store, nil))
+
+	created, err := repo.CreateUsers(context.Background(), []ImportedUser{importedUser("new@example.com")})
+
+	require.Error(t, err)
+	assert.Nil(t, created)
+	assert.Empty(t, store.emails, "user without a setup token was committed")
+}
+
+func TestPasswordSetupRepository_Redeem(t *testing.T) {
+	userID := uuid.New()
+	store := &setupStore{tokens: map[uuid.UUID]string{userID: "hash"}, passwords: map[uuid.UUID]string{}}
+	repo := NewPasswordSetupRepository(database.NewDBWithQueriers(store, nil))
+
+	redeemed, err := repo.Redeem(context.Background(), "hash", "password-hash", time.Now())
+
+	require.NoError(t, err)
+	assert.Equal(t, userID, redeemed)
+	assert.Equal(t, "password-hash", store.passwords[userID])
+	assert.Empty(t, store.tokens, "redeemed token can be used again")
+
+	_, err = repo.Redeem(context.Background(), "hash", "other-hash", time.Now())
+	assert.ErrorIs(t, err, ErrInvalidPasswordSetupToken)
+	assert.Equal(t, "password-hash", store.passwords[userID])
+}
+
+func TestPasswordSetupRepository_RedeemRejectsExpiredToken(t *testing.T) {
+	userID := uuid.New()
+	store := &setupStore{
+		tokens:    map[uuid.UUID]string{userID: "hash"},
+		expired:   map[string]bool{"hash": true},
+		passwords: map[uuid.UUID]string{},
+	}
+	repo := NewPasswordSetupRepository(database.NewDBWithQueriers(store, nil))
+
+	_, err := repo.Redeem(context.Background(), "hash", "password-hash", time.Now())
+
+	assert.ErrorIs(t, err, ErrInvalidPasswordSetupToken)
+	assert.Empty(t, store.passwords)
+}
+
+func TestPasswordSetupRepository_RedeemKeepsExistingPassword(t *testing.T) {
+	userID := uuid.New()
+	store := &setupStore{
+		tokens:    map[uuid.UUID]string{userID: "hash"},
+		passwords: map[uuid.UUID]string{userID: "chosen-hash"},
+	}
+	repo := NewPasswordSetupRepository(database.NewDBWithQueriers(store, nil))
+
+	_, err := repo.Redeem(context.Background(), "hash", "password-hash", time.Now())
+
+	assert.ErrorIs(t, err, ErrInvalidPasswordSetupToken)
+	assert.Equal(t, "chosen-hash", store.passwords[userID])
+	assert.Contains(t, store.tokens, userID, "rolled back redemption consumed the token")
+}
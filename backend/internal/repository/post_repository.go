@@ -2,8 +2,11 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"backend/internal/database"
 	"backend/internal/graph/model"
@@ -11,26 +14,44 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
+// ErrInvalidTags is returned when a post's tags exceed the configured limits
+var ErrInvalidTags = errors.New("invalid post tags")
+
+// errDryRun rolls back the transaction of a dry run after it has counted
+// the rows it would change
+var errDryRun = errors.New("dry run")
+
 // postRepository implements PostRepository interface
 type postRepository struct {
-	db *database.DB
+	db        *database.DB
+	tagLimits model.PostTagLimits
 }
 
-// NewPostRepository creates a new post repository
+// NewPostRepository creates a new post repository with the default tag limits
 func NewPostRepository(db *database.DB) PostRepository {
-	return &postRepository{db: db}
+	return NewPostRepositoryWithTagLimits(db, model.DefaultPostTagLimits())
+}
+
+// NewPostRepositoryWithTagLimits creates a new post repository that refuses
+// to store tags beyond the given limits
+func NewPostRepositoryWithTagLimits(db *database.DB, limits model.PostTagLimits) PostRepository {
+	return &postRepository{db: db, tagLimits: limits}
 }
 
 // Create creates a new post
 func (r *postRepository) Create(ctx context.Context, post *model.Post) error {
+	if err := r.checkTags(post.Tags); err != nil {
+		return err
+	}
+	
 	query := `
-		INSERT INTO posts (id, title, content, author_id, tags, published, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO posts (id, title, content, author_id, tags, published, created_at, updated_at, publish_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 	
-	_, err := r.db.Pool.Exec(ctx, query,
+	_, err := r.db.Writer().Exec(ctx, query,
 		post.ID, post.Title, post.Content, post.AuthorID,
-		post.Tags, post.Published, post.CreatedAt, post.UpdatedAt,
+		post.Tags, post.Published, post.CreatedAt, post.UpdatedAt, post.PublishAt,
 	)
 	
 	if err != nil {
@@ -43,15 +64,16 @@ func (r *postRepository) Create(ctx context.Context, post *model.Post) error {
 // GetByID retrieves a post by ID
 func (r *postRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Post, error) {
 	query := `
-		SELECT id, title, content, author_id, tags, published, created_at, updated_at
+		SELECT id, title, content, author_id, tags, published, created_at, updated_at, hidden, hidden_reason, publish_at
 		FROM posts 
 		WHERE id = $1
 	`
 	
 	var post model.Post
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.Reader().QueryRow(ctx, query, id).Scan(
 		&post.ID, &post.Title, &post.Content, &post.AuthorID,
 		&post.Tags, &post.Published, &post.CreatedAt, &post.UpdatedAt,
+		&post.Hidden, &post.HiddenReason, &post.PublishAt,
 	)
 	
 	if err != nil {
@@ -64,6 +86,18 @@ func (r *postRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Post
 	return &post, nil
 }
 
+// Exists reports whether a post exists without loading the row
+func (r *postRepository) Exists(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM posts WHERE id = $1)`
+
+	var exists bool
+	if err := r.db.Reader().QueryRow(ctx, query, id).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check post: %w", err)
+	}
+
+	return exists, nil
+}
+
 // GetByIDs retrieves multiple posts by their IDs (for DataLoader)
 func (r *postRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Post, error) {
 	if len(ids) == 0 {
@@ -79,12 +113,12 @@ func (r *postRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*mode
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, title, content, author_id, tags, published, created_at, updated_at
+		SELECT id, title, content, author_id, tags, published, created_at, updated_at, hidden, hidden_reason, publish_at
 		FROM posts 
 		WHERE id IN (%s)
 	`, strings.Join(placeholders, ","))
 
-	rows, err := r.db.Pool.Query(ctx, query, args...)
+	rows, err := r.db.Reader().Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get posts: %w", err)
 	}
@@ -95,15 +129,20 @@ func (r *postRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*mode
 
 // GetByAuthorID retrieves posts by author ID with pagination
 func (r *postRepository) GetByAuthorID(ctx context.Context, authorID uuid.UUID, limit, offset int) ([]*model.Post, error) {
+	limit, err := checkPage(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
-		SELECT id, title, content, author_id, tags, published, created_at, updated_at
+		SELECT id, title, content, author_id, tags, published, created_at, updated_at, hidden, hidden_reason, publish_at
 		FROM posts 
-		WHERE author_id = $1
-		ORDER BY created_at DESC
+		WHERE author_id = $1 AND hidden = false
+		ORDER BY created_at DESC, id DESC
 		LIMIT $2 OFFSET $3
 	`
 	
-	rows, err := r.db.Pool.Query(ctx, query, authorID, limit, offset)
+	rows, err := r.db.Reader().Query(ctx, query, authorID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get posts by author: %w", err)
 	}
@@ -112,17 +151,64 @@ func (r *postRepository) GetByAuthorID(ctx context.Context, authorID uuid.UUID,
 	return r.scanPosts(rows)
 }
 
+// GetPublishedByAuthorIDs returns up to limit of each author's newest published,
+// visible posts in one query (for DataLoader). Authors without posts are absent
+// from the result.
+func (r *postRepository) GetPublishedByAuthorIDs(ctx context.Context, authorIDs []uuid.UUID, limit int) (map[uuid.UUID][]*model.Post, error) {
+	limit, err := checkLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	postsByAuthor := make(map[uuid.UUID][]*model.Post)
+	if len(authorIDs) == 0 {
+		return postsByAuthor, nil
+	}
+
+	query := `
+		SELECT id, title, content, author_id, tags, published, created_at, updated_at, hidden, hidden_reason, publish_at
+		FROM (
+			SELECT id, title, content, author_id, tags, published, created_at, updated_at, hidden, hidden_reason, publish_at,
+				ROW_NUMBER() OVER (PARTITION BY author_id ORDER BY created_at DESC, id DESC) AS position
+			FROM posts
+			WHERE author_id = ANY($1) AND published = true AND hidden = false
+		) ranked
+		WHERE position <= $2
+		ORDER BY author_id, position
+	`
+
+	rows, err := r.db.Reader().Query(ctx, query, authorIDs, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts by authors: %w", err)
+	}
+	defer rows.Close()
+
+	posts, err := r.scanPosts(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, post := range posts {
+		postsByAuthor[post.AuthorID] = append(postsByAuthor[post.AuthorID], post)
+	}
+
+	return postsByAuthor, nil
+}
+
 // Update updates an existing post
 func (r *postRepository) Update(ctx context.Context, post *model.Post) error {
+	if err := r.checkTags(post.Tags); err != nil {
+		return err
+	}
+	
 	query := `
 		UPDATE posts 
-		SET title = $2, content = $3, tags = $4, published = $5, updated_at = $6
+		SET title = $2, content = $3, tags = $4, published = $5, updated_at = $6, author_id = $7, publish_at = $8
 		WHERE id = $1
 	`
 	
-	result, err := r.db.Pool.Exec(ctx, query,
+	result, err := r.db.Writer().Exec(ctx, query,
 		post.ID, post.Title, post.Content, post.Tags, 
-		post.Published, post.UpdatedAt,
+		post.Published, post.UpdatedAt, post.AuthorID, post.PublishAt,
 	)
 	
 	if err != nil {
@@ -140,7 +226,7 @@ func (r *postRepository) Update(ctx context.Context, post *model.Post) error {
 func (r *postRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM posts WHERE id = $1`
 	
-	result, err := r.db.Pool.Exec(ctx, query, id)
+	result, err := r.db.Writer().Exec(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete post: %w", err)
 	}
@@ -152,10 +238,182 @@ func (r *postRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// DeletePostWithComments deletes a post and its comments in one transaction,
+// so a failure leaves both in place rather than orphaning comments. It
+// returns how many comments were deleted. A dry run makes the same deletes
+// and rolls them back, so it returns the count a real delete would remove.
+func (r *postRepository) DeletePostWithComments(ctx context.Context, id uuid.UUID, dryRun bool) (int, error) {
+	deletedComments := 0
+	err := r.db.WithTx(ctx, func(tx database.Querier) error {
+		comments, err := tx.Exec(ctx, `DELETE FROM comments WHERE post_id = $1`, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete post comments: %w", err)
+		}
+		deletedComments = int(comments.RowsAffected())
+		
+		result, err := tx.Exec(ctx, `DELETE FROM posts WHERE id = $1`, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete post: %w", err)
+		}
+		
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("post not found")
+		}
+		if dryRun {
+			return errDryRun
+		}
+		
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return 0, err
+	}
+	
+	return deletedComments, nil
+}
+
+// MergeTags replaces the tag from with into on every post carrying it, in
+// one transaction. A post that already had both keeps a single into tag in
+// the position of its first occurrence. It returns the IDs of the posts
+// that changed. A dry run makes the same changes and rolls them back, so it
+// returns the posts a real merge would change while leaving them as they are.
+// The merge fails, changing nothing, if any merged post would break the tag
+// limits, for instance because into is longer than from.
+func (r *postRepository) MergeTags(ctx context.Context, from, into string, dryRun bool) ([]uuid.UUID, error) {
+	if err := r.checkTags([]string{into}); err != nil {
+		return nil, err
+	}
+	
+	query := `
+		UPDATE posts
+		SET tags = ARRAY(
+			SELECT tag
+			FROM unnest(array_replace(tags, $1, $2)) WITH ORDINALITY AS t(tag, position)
+			GROUP BY tag
+			ORDER BY MIN(position)
+		)
+		WHERE $1 = ANY(tags)
+		RETURNING id, tags
+	`
+	
+	ids := []uuid.UUID{}
+	err := r.db.WithTx(ctx, func(tx database.Querier) error {
+		rows, err := tx.Query(ctx, query, from, into)
+		if err != nil {
+			return fmt.Errorf("failed to merge tags: %w", err)
+		}
+		defer rows.Close()
+		
+		for rows.Next() {
+			var id uuid.UUID
+			var tags []string
+			if err := rows.Scan(&id, &tags); err != nil {
+				return fmt.Errorf("failed to scan merged post: %w", err)
+			}
+			if err := r.checkTags(tags); err != nil {
+				return fmt.Errorf("merging tags on post %s: %w", id, err)
+			}
+			ids = append(ids, id)
+		}
+		
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating merged posts: %w", err)
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	
+	return ids, nil
+}
+
 // List retrieves posts with filters and pagination
 func (r *postRepository) List(ctx context.Context, filters *PostFilters, limit, offset int) ([]*model.Post, error) {
+	limit, err := checkPage(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args := listQuery(filters, limit, offset)
+	
+	rows, err := r.db.Reader().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list posts: %w", err)
+	}
+	defer rows.Close()
+	
+	return r.scanPosts(rows)
+}
+
+// ListWithAuthors retrieves the same page as List with each post's author
+// joined in, so callers needing authors avoid a second query. A post whose
+// author row is missing is returned with a nil Author.
+func (r *postRepository) ListWithAuthors(ctx context.Context, filters *PostFilters, limit, offset int) ([]*model.Post, error) {
+	limit, err := checkPage(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	page, args := listQuery(filters, limit, offset)
+	column, direction := postOrder(filters)
+	query := fmt.Sprintf(`
+		SELECT p.id, p.title, p.content, p.author_id, p.tags, p.published, p.created_at, p.updated_at, p.hidden, p.hidden_reason, p.publish_at,
+			u.id, u.email, u.name, u.avatar, u.created_at, u.updated_at
+		FROM (%s) p
+		LEFT JOIN users u ON u.id = p.author_id
+		ORDER BY p.%s %s, p.id %s
+	`, page, column, direction, direction)
+	
+	rows, err := r.db.Reader().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list posts with authors: %w", err)
+	}
+	defer rows.Close()
+	
+	posts := []*model.Post{}
+	for rows.Next() {
+		var post model.Post
+		var authorID *uuid.UUID
+		var email, name, avatar *string
+		var createdAt, updatedAt *time.Time
+		err := rows.Scan(
+			&post.ID, &post.Title, &post.Content, &post.AuthorID,
+			&post.Tags, &post.Published, &post.CreatedAt, &post.UpdatedAt,
+			&post.Hidden, &post.HiddenReason, &post.PublishAt,
+			&authorID, &email, &name, &avatar, &createdAt, &updatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post with author: %w", err)
+		}
+		if authorID != nil {
+			post.Author = &model.User{
+				ID:        *authorID,
+				Email:     *email,
+				Name:      *name,
+				Avatar:    avatar,
+				CreatedAt: *createdAt,
+				UpdatedAt: *updatedAt,
+			}
+		}
+		posts = append(posts, &post)
+	}
+	
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating posts with authors: %w", err)
+	}
+	
+	return posts, nil
+}
+
+// listQuery builds the filtered, ordered and paginated post query shared by
+// List and ListWithAuthors
+func listQuery(filters *PostFilters, limit, offset int) (string, []interface{}) {
 	query := `
-		SELECT id, title, content, author_id, tags, published, created_at, updated_at
+		SELECT id, title, content, author_id, tags, published, created_at, updated_at, hidden, hidden_reason, publish_at
 		FROM posts 
 		WHERE 1=1
 	`
@@ -184,39 +442,103 @@ func (r *postRepository) List(ctx context.Context, filters *PostFilters, limit,
 		}
 		
 		if filters.SearchTerm != nil && *filters.SearchTerm != "" {
-			query += fmt.Sprintf(" AND (title ILIKE $%d OR content ILIKE $%d)", argIndex, argIndex+1)
-			searchPattern := "%" + *filters.SearchTerm + "%"
+			query += fmt.Sprintf(" AND (title ILIKE $%d ESCAPE '\\' OR content ILIKE $%d ESCAPE '\\')", argIndex, argIndex+1)
+			searchPattern := containsPattern(*filters.SearchTerm)
 			args = append(args, searchPattern, searchPattern)
 			argIndex += 2
 		}
 	}
 	
-	query += " ORDER BY created_at DESC"
+	// Hidden posts are only listed for moderators and their author
+	query, args, argIndex = applyHiddenFilter(query, args, argIndex, filters)
+	
+	// Drafts are only listed for admins and their author
+	query, args, argIndex = applyDraftFilter(query, args, argIndex, filters)
+	
+	// Keyset pagination; id breaks ties between equal sort values
+	column, direction := postOrder(filters)
+	if filters != nil && filters.After != nil {
+		operator := "<"
+		if direction == "ASC" {
+			operator = ">"
+		}
+		query += fmt.Sprintf(" AND (%s, id) %s ($%d, $%d)", column, operator, argIndex, argIndex+1)
+		args = append(args, filters.After.Value, filters.After.ID)
+		argIndex += 2
+	}
+	
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s", column, direction, direction)
 	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
 	args = append(args, limit, offset)
 	
-	rows, err := r.db.Pool.Query(ctx, query, args...)
+	return query, args
+}
+
+// SetHidden hides or unhides a post; the reason is cleared when unhiding
+func (r *postRepository) SetHidden(ctx context.Context, id uuid.UUID, hidden bool, reason *string) error {
+	if !hidden {
+		reason = nil
+	}
+
+	query := `
+		UPDATE posts 
+		SET hidden = $2, hidden_reason = $3
+		WHERE id = $1
+	`
+	
+	result, err := r.db.Writer().Exec(ctx, query, id, hidden, reason)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list posts: %w", err)
+		return fmt.Errorf("failed to update post visibility: %w", err)
 	}
-	defer rows.Close()
 	
-	return r.scanPosts(rows)
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("post not found")
+	}
+	
+	return nil
+}
+
+// SetPublished changes only a post's published flag, recording publishAt as
+// its publish time when it has none. The first publish time is kept when the
+// post is unpublished and published again.
+func (r *postRepository) SetPublished(ctx context.Context, id uuid.UUID, published bool, publishAt *time.Time) error {
+	query := `
+		UPDATE posts 
+		SET published = $2, publish_at = COALESCE(publish_at, $3)
+		WHERE id = $1
+	`
+	
+	result, err := r.db.Writer().Exec(ctx, query, id, published, publishAt)
+	if err != nil {
+		return fmt.Errorf("failed to update post publication: %w", err)
+	}
+	
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("post not found")
+	}
+	
+	return nil
 }
 
 // Search searches posts by title and content
 func (r *postRepository) Search(ctx context.Context, query string, limit int) ([]*model.Post, error) {
+	limit, err := checkLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+
 	searchQuery := `
-		SELECT id, title, content, author_id, tags, published, created_at, updated_at
+		SELECT id, title, content, author_id, tags, published, created_at, updated_at, hidden, hidden_reason, publish_at
 		FROM posts 
 		WHERE published = true 
-		AND (title ILIKE $1 OR content ILIKE $1)
-		ORDER BY created_at DESC
+		AND hidden = false
+		AND (title ILIKE $1 ESCAPE '\' OR content ILIKE $1 ESCAPE '\')
+		ORDER BY created_at DESC, id DESC
 		LIMIT $2
 	`
 	
-	searchPattern := "%" + query + "%"
-	rows, err := r.db.Pool.Query(ctx, searchQuery, searchPattern, limit)
+	searchPattern := containsPattern(query)
+	rows, err := r.db.Reader().Query(ctx, searchQuery, searchPattern, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search posts: %w", err)
 	}
@@ -252,15 +574,21 @@ func (r *postRepository) Count(ctx context.Context, filters *PostFilters) (int,
 		}
 		
 		if filters.SearchTerm != nil && *filters.SearchTerm != "" {
-			query += fmt.Sprintf(" AND (title ILIKE $%d OR content ILIKE $%d)", argIndex, argIndex+1)
-			searchPattern := "%" + *filters.SearchTerm + "%"
+			query += fmt.Sprintf(" AND (title ILIKE $%d ESCAPE '\\' OR content ILIKE $%d ESCAPE '\\')", argIndex, argIndex+1)
+			searchPattern := containsPattern(*filters.SearchTerm)
 			args = append(args, searchPattern, searchPattern)
 			argIndex += 2
 		}
 	}
 	
+	// Hidden posts are only listed for moderators and their author
+	query, args, argIndex = applyHiddenFilter(query, args, argIndex, filters)
+	
+	// Drafts are only listed for admins and their author
+	query, args, _ = applyDraftFilter(query, args, argIndex, filters)
+	
 	var count int
-	err := r.db.Pool.QueryRow(ctx, query, args...).Scan(&count)
+	err := r.db.Reader().QueryRow(ctx, query, args...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count posts: %w", err)
 	}
@@ -268,15 +596,153 @@ func (r *postRepository) Count(ctx context.Context, filters *PostFilters) (int,
 	return count, nil
 }
 
-// scanPosts is a helper function to scan post rows
+// CountByPublished returns the number of published and unpublished posts,
+// hidden ones included, in one query
+func (r *postRepository) CountByPublished(ctx context.Context) (published, unpublished int, err error) {
+	query := `
+		SELECT COUNT(*) FILTER (WHERE published), COUNT(*) FILTER (WHERE NOT published)
+		FROM posts
+	`
+	
+	err = r.db.Reader().QueryRow(ctx, query).Scan(&published, &unpublished)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count posts: %w", err)
+	}
+	
+	return published, unpublished, nil
+}
+
+// PopularTags returns a page of the tags on published, visible posts with
+// the number of posts carrying each, most used first. Tags used equally
+// often are ordered by name, so pages don't overlap or skip tags.
+func (r *postRepository) PopularTags(ctx context.Context, limit, offset int) ([]*model.TagCount, error) {
+	limit, err := checkPage(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT tag, COUNT(*) AS post_count
+		FROM posts, unnest(tags) AS tag
+		WHERE published = true AND hidden = false
+		GROUP BY tag
+		ORDER BY post_count DESC, tag ASC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.Reader().Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get popular tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []*model.TagCount{}
+	for rows.Next() {
+		tag := &model.TagCount{}
+		if err := rows.Scan(&tag.Tag, &tag.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan tag count: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get popular tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// postOrder returns the column and direction List sorts by
+func postOrder(filters *PostFilters) (string, string) {
+	if filters == nil {
+		return "created_at", "DESC"
+	}
+	
+	switch filters.Sort {
+	case model.PostSortOldest:
+		return "created_at", "ASC"
+	case model.PostSortRecentlyUpdated:
+		return "updated_at", "DESC"
+	case model.PostSortTitle:
+		return "title", "ASC"
+	default:
+		return "created_at", "DESC"
+	}
+}
+
+// applyHiddenFilter restricts a post query to rows the viewer may see
+func applyHiddenFilter(query string, args []interface{}, argIndex int, filters *PostFilters) (string, []interface{}, int) {
+	if filters != nil && filters.IncludeHidden {
+		return query, args, argIndex
+	}
+	
+	if filters != nil && filters.ViewerID != nil {
+		query += fmt.Sprintf(" AND (hidden = false OR author_id = $%d)", argIndex)
+		args = append(args, *filters.ViewerID)
+		return query, args, argIndex + 1
+	}
+	
+	return query + " AND hidden = false", args, argIndex
+}
+
+// applyDraftFilter restricts a post query to published posts and the viewer's own drafts
+func applyDraftFilter(query string, args []interface{}, argIndex int, filters *PostFilters) (string, []interface{}, int) {
+	if filters != nil && filters.IncludeDrafts {
+		return query, args, argIndex
+	}
+	
+	if filters != nil && filters.ViewerID != nil {
+		query += fmt.Sprintf(" AND (published = true OR author_id = $%d)", argIndex)
+		args = append(args, *filters.ViewerID)
+		return query, args, argIndex + 1
+	}
+	
+	return query + " AND published = true", args, argIndex
+}
+
+// checkTags enforces the tag limits independently of the GraphQL validator,
+// so callers writing posts directly cannot store unbounded tag arrays
+func (r *postRepository) checkTags(tags []string) error {
+	if len(tags) > r.tagLimits.MaxTags {
+		return fmt.Errorf("%w: %d tags exceeds the limit of %d", ErrInvalidTags, len(tags), r.tagLimits.MaxTags)
+	}
+	
+	total := 0
+	for _, tag := range tags {
+		length := utf8.RuneCountInString(tag)
+		if length > r.tagLimits.MaxTagLength {
+			return fmt.Errorf("%w: tag %q exceeds %d characters", ErrInvalidTags, tag, r.tagLimits.MaxTagLength)
+		}
+		total += length
+	}
+	
+	if r.tagLimits.MaxTotalTagLength > 0 && total > r.tagLimits.MaxTotalTagLength {
+		return fmt.Errorf("%w: tags total %d characters, exceeding %d", ErrInvalidTags, total, r.tagLimits.MaxTotalTagLength)
+	}
+	
+	return nil
+}
+
+// likeEscaper escapes the backslash and the LIKE wildcards % and _
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// containsPattern returns an ILIKE pattern matching term anywhere in a
+// column. Wildcards in term are escaped so they match literally; queries
+// using it must declare ESCAPE '\'.
+func containsPattern(term string) string {
+	return "%" + likeEscaper.Replace(term) + "%"
+}
+
+// scanPosts is a helper function to scan post rows. It returns an empty
+// slice rather than nil when there are no rows.
 func (r *postRepository) scanPosts(rows pgx.Rows) ([]*model.Post, error) {
-	var posts []*model.Post
+	posts := []*model.Post{}
 	
 	for rows.Next() {
 		var post model.Post
 		err := rows.Scan(
 			&post.ID, &post.Title, &post.Content, &post.AuthorID,
 			&post.Tags, &post.Published, &post.CreatedAt, &post.UpdatedAt,
+			&post.Hidden, &post.HiddenReason, &post.PublishAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan post: %w", err)
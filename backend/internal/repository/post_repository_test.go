@@ -0,0 +1,844 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/database"
+	"backend/internal/graph/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostRepository_ListHiddenVisibility(t *testing.T) {
+	viewerID := uuid.New()
+
+	tests := []struct {
+		name     string
+		filters  *PostFilters
+		contains string
+		excludes string
+	}{
+		{
+			name:     "nil filters hide hidden posts",
+			filters:  nil,
+			contains: "AND hidden = false",
+		},
+		{
+			name:     "viewer sees own hidden posts",
+			filters:  &PostFilters{ViewerID: &viewerID},
+			contains: "AND (hidden = false OR author_id = $1)",
+		},
+		{
+			name:     "moderators see everything",
+			filters:  &PostFilters{IncludeHidden: true, ViewerID: &viewerID},
+			excludes: "hidden = false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			querier := &existenceQuerier{}
+			repo := NewPostRepository(database.NewDBWithQueriers(querier, querier))
+
+			_, err := repo.List(context.Background(), tt.filters, 10, 0)
+
+			require.NoError(t, err)
+			require.Len(t, querier.sql, 1)
+			if tt.contains != "" {
+				assert.Contains(t, querier.sql[0], tt.contains)
+			}
+			if tt.excludes != "" {
+				assert.NotContains(t, querier.sql[0], tt.excludes)
+			}
+		})
+	}
+}
+
+func TestPostRepository_ListDraftVisibility(t *testing.T) {
+	viewerID := uuid.New()
+	unpublished := false
+
+	tests := []struct {
+		name     string
+		filters  *PostFilters
+		contains string
+		excludes string
+	}{
+		{
+			name:     "anonymous listing is published only",
+			filters:  nil,
+			contains: "AND published = true",
+		},
+		{
+			name:     "anonymous asking for drafts still gets published only",
+			filters:  &PostFilters{Published: &unpublished},
+			contains: "AND published = $1 AND hidden = false AND published = true",
+		},
+		{
+			name:     "author sees own drafts",
+			filters:  &PostFilters{ViewerID: &viewerID, IncludeHidden: true},
+			contains: "AND (published = true OR author_id = $1)",
+		},
+		{
+			name:     "admins see every draft",
+			filters:  &PostFilters{IncludeDrafts: true},
+			excludes: "published = true",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, count := range []bool{false, true} {
+				querier := &existenceQuerier{}
+				repo := NewPostRepository(database.NewDBWithQueriers(querier, querier))
+
+				if count {
+					_, _ = repo.Count(context.Background(), tt.filters)
+				} else {
+					_, _ = repo.List(context.Background(), tt.filters, 10, 0)
+				}
+
+				require.Len(t, querier.sql, 1)
+				if tt.contains != "" {
+					assert.Contains(t, querier.sql[0], tt.contains)
+				}
+				if tt.excludes != "" {
+					assert.NotContains(t, querier.sql[0], tt.excludes)
+				}
+			}
+		})
+	}
+}
+
+func TestPostRepository_SetHiddenHitsPrimary(t *testing.T) {
+	repos, primary, replica := setupRoutedRepos()
+	reason := "spam"
+
+	require.NoError(t, repos.Post.SetHidden(context.Background(), uuid.New(), true, &reason))
+	require.NoError(t, repos.Comment.SetHidden(context.Background(), uuid.New(), false, nil))
+
+	assert.Equal(t, 2, primary.execs)
+	assert.Zero(t, replica.execs)
+}
+
+func TestPostRepository_ListSortAndKeyset(t *testing.T) {
+	afterID := uuid.New()
+	after := &PostKeyset{Value: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), ID: afterID}
+
+	tests := []struct {
+		name     string
+		filters  *PostFilters
+		contains []string
+	}{
+		{
+			name:     "defaults to newest first",
+			filters:  nil,
+			contains: []string{"ORDER BY created_at DESC, id DESC"},
+		},
+		{
+			name:     "oldest continues forwards",
+			filters:  &PostFilters{Sort: model.PostSortOldest, After: after},
+			contains: []string{"AND (created_at, id) > ($1, $2)", "ORDER BY created_at ASC, id ASC"},
+		},
+		{
+			name:     "recently updated continues backwards",
+			filters:  &PostFilters{Sort: model.PostSortRecentlyUpdated, After: after},
+			contains: []string{"AND (updated_at, id) < ($1, $2)", "ORDER BY updated_at DESC, id DESC"},
+		},
+		{
+			name:     "title sorts alphabetically",
+			filters:  &PostFilters{Sort: model.PostSortTitle},
+			contains: []string{"ORDER BY title ASC, id ASC"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			querier := &existenceQuerier{}
+			repo := NewPostRepository(database.NewDBWithQueriers(querier, querier))
+
+			_, err := repo.List(context.Background(), tt.filters, 10, 0)
+
+			require.NoError(t, err)
+			require.Len(t, querier.sql, 1)
+			for _, fragment := range tt.contains {
+				assert.Contains(t, querier.sql[0], fragment)
+			}
+		})
+	}
+}
+
+// txStore is an in-memory posts and comments table set that only applies
+// a transaction's deletes when it commits
+type txStore struct {
+	recordingQuerier
+	posts     map[uuid.UUID]bool
+	comments  map[uuid.UUID]uuid.UUID // comment ID -> post ID
+	failPosts bool
+}
+
+func (s *txStore) Begin(ctx context.Context) (pgx.Tx, error) {
+	return &storeTx{store: s}, nil
+}
+
+// storeTx stages deletes until Commit
+type storeTx struct {
+	pgx.Tx
+	store      *txStore
+	posts      []uuid.UUID
+	comments   []uuid.UUID
+	committed  bool
+	rolledBack bool
+}
+
+func (tx *storeTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	id := args[0].(uuid.UUID)
+	switch {
+	case strings.Contains(sql, "DELETE FROM comments"):
+		deleted := 0
+		for commentID, postID := range tx.store.comments {
+			if postID == id {
+				tx.comments = append(tx.comments, commentID)
+				deleted++
+			}
+		}
+		return pgconn.NewCommandTag(fmt.Sprintf("DELETE %d", deleted)), nil
+	case strings.Contains(sql, "DELETE FROM posts"):
+		if tx.store.failPosts {
+			return pgconn.CommandTag{}, errQuerierStub
+		}
+		if !tx.store.posts[id] {
+			return pgconn.NewCommandTag("DELETE 0"), nil
+		}
+		tx.posts = append(tx.posts, id)
+		return pgconn.NewCommandTag("DELETE 1"), nil
+	}
+	return pgconn.CommandTag{}, fmt.Errorf("unexpected statement: %s", sql)
+}
+
+func (tx *storeTx) Commit(ctx context.Context) error {
+	for _, id := range tx.comments {
+		delete(tx.store.comments, id)
+	}
+	for _, id := range tx.posts {
+		delete(tx.store.posts, id)
+	}
+	tx.committed = true
+	return nil
+}
+
+func (tx *storeTx) Rollback(ctx context.Context) error {
+	if !tx.committed {
+		tx.rolledBack = true
+	}
+	return nil
+}
+
+func newTxStore(postID uuid.UUID, comments int) *txStore {
+	store := &txStore{posts: map[uuid.UUID]bool{postID: true}, comments: map[uuid.UUID]uuid.UUID{}}
+	for i := 0; i < comments; i++ {
+		store.comments[uuid.New()] = postID
+	}
+	// A comment on another post must survive
+	store.comments[uuid.New()] = uuid.New()
+	return store
+}
+
+func countComments(store *txStore, postID uuid.UUID) int {
+	count := 0
+	for _, id := range store.comments {
+		if id == postID {
+			count++
+		}
+	}
+	return count
+}
+
+func TestPostRepository_DeletePostWithComments(t *testing.T) {
+	postID := uuid.New()
+	store := newTxStore(postID, 3)
+	repo := NewPostRepository(database.NewDBWithQueriers(store, nil))
+
+	deletedComments, err := repo.DeletePostWithComments(context.Background(), postID, false)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, deletedComments)
+	assert.False(t, store.posts[postID])
+	assert.Zero(t, countComments(store, postID), "orphaned comments remain")
+	assert.Len(t, store.comments, 1)
+}
+
+func TestPostRepository_DeletePostWithCommentsDryRun(t *testing.T) {
+	postID := uuid.New()
+	store := newTxStore(postID, 3)
+	repo := NewPostRepository(database.NewDBWithQueriers(store, nil))
+
+	deletedComments, err := repo.DeletePostWithComments(context.Background(), postID, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, deletedComments)
+	assert.True(t, store.posts[postID], "dry run deleted the post")
+	assert.Equal(t, 3, countComments(store, postID))
+}
+
+func TestPostRepository_DeletePostWithCommentsRollsBack(t *testing.T) {
+	t.Run("post delete fails", func(t *testing.T) {
+		postID := uuid.New()
+		store := newTxStore(postID, 3)
+		store.failPosts = true
+		repo := NewPostRepository(database.NewDBWithQueriers(store, nil))
+
+		_, err := repo.DeletePostWithComments(context.Background(), postID, false)
+
+		require.Error(t, err)
+		assert.True(t, store.posts[postID])
+		assert.Equal(t, 3, countComments(store, postID))
+	})
+
+	t.Run("post does not exist", func(t *testing.T) {
+		postID := uuid.New()
+		store := newTxStore(postID, 2)
+		delete(store.posts, postID)
+		repo := NewPostRepository(database.NewDBWithQueriers(store, nil))
+
+		_, err := repo.DeletePostWithComments(context.Background(), postID, false)
+
+		assert.EqualError(t, err, "post not found")
+		assert.Equal(t, 2, countComments(store, postID))
+	})
+}
+
+func TestPostRepository_Exists(t *testing.T) {
+	postID := uuid.New()
+	querier := &existenceQuerier{stored: map[any]bool{postID: true}}
+	repo := NewPostRepository(database.NewDBWithQueriers(querier, querier))
+	ctx := context.Background()
+
+	present, err := repo.Exists(ctx, postID)
+	require.NoError(t, err)
+	assert.True(t, present)
+
+	absent, err := repo.Exists(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.False(t, absent)
+
+	require.Len(t, querier.sql, 2)
+	assert.Contains(t, querier.sql[0], "SELECT EXISTS(SELECT 1 FROM posts WHERE id = $1)")
+}
+
+// countQuerier answers every QueryRow with fixed integer columns
+type countQuerier struct {
+	recordingQuerier
+	counts []int
+	sql    []string
+}
+
+func (q *countQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	q.queries++
+	q.sql = append(q.sql, sql)
+	return countRow(q.counts)
+}
+
+// countRow is a pgx.Row of integer columns
+type countRow []int
+
+func (r countRow) Scan(dest ...any) error {
+	for i, d := range dest {
+		*d.(*int) = r[i]
+	}
+	return nil
+}
+
+func TestPostRepository_CountByPublished(t *testing.T) {
+	querier := &countQuerier{counts: []int{7, 2}}
+	repo := NewPostRepository(database.NewDBWithQueriers(&recordingQuerier{}, querier))
+
+	published, unpublished, err := repo.CountByPublished(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 7, published)
+	assert.Equal(t, 2, unpublished)
+	require.Len(t, querier.sql, 1, "both counts come from one query")
+	assert.Contains(t, querier.sql[0], "COUNT(*) FILTER (WHERE published)")
+}
+
+// postRow is a posts row in the column order scanPosts reads
+func postRow(post *model.Post) []any {
+	return []any{
+		post.ID, post.Title, post.Content, post.AuthorID, post.Tags, post.Published,
+		post.CreatedAt, post.UpdatedAt, post.Hidden, post.HiddenReason, post.PublishAt,
+	}
+}
+
+func TestPostRepository_GetPublishedByAuthorIDs(t *testing.T) {
+	prolific, occasional, silent := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+	newest := &model.Post{ID: uuid.New(), Title: "Newest", AuthorID: prolific, Tags: []string{}, Published: true, CreatedAt: now, UpdatedAt: now, PublishAt: &now}
+	older := &model.Post{ID: uuid.New(), Title: "Older", AuthorID: prolific, Tags: []string{}, Published: true, CreatedAt: now.Add(-time.Hour), UpdatedAt: now}
+	only := &model.Post{ID: uuid.New(), Title: "Only", AuthorID: occasional, Tags: []string{}, Published: true, CreatedAt: now, UpdatedAt: now}
+
+	querier := &summaryQuerier{rows: [][]any{postRow(newest), postRow(older), postRow(only)}}
+	repo := NewPostRepository(database.NewDBWithQueriers(&recordingQuerier{}, querier))
+	authorIDs := []uuid.UUID{prolific, occasional, silent}
+
+	postsByAuthor, err := repo.GetPublishedByAuthorIDs(context.Background(), authorIDs, 2)
+
+	require.NoError(t, err)
+	require.Len(t, querier.sql, 1, "all authors come from one query")
+	assert.Contains(t, querier.sql[0], "author_id = ANY($1)")
+	assert.Contains(t, querier.sql[0], "ROW_NUMBER() OVER (PARTITION BY author_id ORDER BY created_at DESC, id DESC)")
+	assert.Contains(t, querier.sql[0], "WHERE position <= $2")
+	assert.Contains(t, querier.sql[0], "published = true AND hidden = false")
+	assert.Equal(t, []any{authorIDs, 2}, querier.args[0])
+
+	require.Len(t, postsByAuthor, 2)
+	assert.Equal(t, []*model.Post{newest, older}, postsByAuthor[prolific])
+	assert.Equal(t, []*model.Post{only}, postsByAuthor[occasional])
+	assert.NotContains(t, postsByAuthor, silent)
+}
+
+func TestPostRepository_GetPublishedByAuthorIDsWithoutAuthors(t *testing.T) {
+	querier := &summaryQuerier{}
+	repo := NewPostRepository(database.NewDBWithQueriers(querier, querier))
+
+	postsByAuthor, err := repo.GetPublishedByAuthorIDs(context.Background(), nil, 10)
+
+	require.NoError(t, err)
+	assert.Empty(t, postsByAuthor)
+	assert.Zero(t, querier.queries)
+}
+
+func TestPostRepository_NoRowsReturnEmptySlices(t *testing.T) {
+	querier := &existenceQuerier{}
+	repo := NewPostRepository(database.NewDBWithQueriers(querier, querier))
+	ctx := context.Background()
+
+	results := map[string]func() ([]*model.Post, error){
+		"GetByIDs":        func() ([]*model.Post, error) { return repo.GetByIDs(ctx, []uuid.UUID{uuid.New()}) },
+		"GetByAuthorID":   func() ([]*model.Post, error) { return repo.GetByAuthorID(ctx, uuid.New(), 10, 0) },
+		"List":            func() ([]*model.Post, error) { return repo.List(ctx, nil, 10, 0) },
+		"ListWithAuthors": func() ([]*model.Post, error) { return repo.ListWithAuthors(ctx, nil, 10, 0) },
+		"Search":          func() ([]*model.Post, error) { return repo.Search(ctx, "nothing", 10) },
+	}
+
+	for name, query := range results {
+		t.Run(name, func(t *testing.T) {
+			posts, err := query()
+
+			require.NoError(t, err)
+			assert.NotNil(t, posts)
+			assert.Empty(t, posts)
+		})
+	}
+}
+
+// searchQuerier answers post queries from in-memory posts, applying the
+// first string argument as an ILIKE pattern with a backslash escape
+type searchQuerier struct {
+	recordingQuerier
+	posts []*model.Post
+	sql   []string
+	args  [][]any
+}
+
+func (q *searchQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	q.queries++
+	q.sql = append(q.sql, sql)
+	q.args = append(q.args, args)
+
+	var pattern *regexp.Regexp
+	for _, arg := range args {
+		if like, ok := arg.(string); ok {
+			pattern = ilikeRegexp(like)
+			break
+		}
+	}
+
+	rows := [][]any{}
+	for _, post := range q.posts {
+		if pattern != nil && !pattern.MatchString(post.Title) && !pattern.MatchString(post.Content) {
+			continue
+		}
+		rows = append(rows, []any{
+			post.ID, post.Title, post.Content, post.AuthorID, post.Tags, post.Published,
+			post.CreatedAt, post.UpdatedAt, post.Hidden, post.HiddenReason, post.PublishAt,
+		})
+	}
+	return &tableRows{rows: rows, pos: -1}, nil
+}
+
+func (q *searchQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	q.queries++
+	q.sql = append(q.sql, sql)
+	q.args = append(q.args, args)
+	return stubRow{}
+}
+
+// ilikeRegexp translates an ILIKE pattern using ESCAPE '\' to a regexp
+func ilikeRegexp(like string) *regexp.Regexp {
+	var expr strings.Builder
+	expr.WriteString("(?is)^")
+	runes := []rune(like)
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+			if i < len(runes) {
+				expr.WriteString(regexp.QuoteMeta(string(runes[i])))
+			}
+		case '%':
+			expr.WriteString(".*")
+		case '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(runes[i])))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String())
+}
+
+// postWithAuthorRow is a post row followed by its joined author columns,
+// all nil when the author row is missing
+func postWithAuthorRow(post *model.Post, author *model.User) []any {
+	row := []any{
+		post.ID, post.Title, post.Content, post.AuthorID, post.Tags, post.Published,
+		post.CreatedAt, post.UpdatedAt, post.Hidden, post.HiddenReason, post.PublishAt,
+	}
+	if author == nil {
+		return append(row, (*uuid.UUID)(nil), (*string)(nil), (*string)(nil), (*string)(nil), (*time.Time)(nil), (*time.Time)(nil))
+	}
+	return append(row, &author.ID, &author.Email, &author.Name, author.Avatar, &author.CreatedAt, &author.UpdatedAt)
+}
+
+func TestPostRepository_ListWithAuthors(t *testing.T) {
+	now := time.Now()
+	avatar := "https://example.com/ada.png"
+	ada := &model.User{ID: uuid.New(), Email: "ada@example.com", Name: "Ada", Avatar: &avatar, CreatedAt: now, UpdatedAt: now}
+	grace := &model.User{ID: uuid.New(), Email: "grace@example.com", Name: "Grace", CreatedAt: now, UpdatedAt: now}
+	byAda := &model.Post{ID: uuid.New(), Title: "By Ada", AuthorID: ada.ID, Published: true, CreatedAt: now, PublishAt: &now}
+	byGrace := &model.Post{ID: uuid.New(), Title: "By Grace", AuthorID: grace.ID, Published: true, CreatedAt: now}
+	alsoByAda := &model.Post{ID: uuid.New(), Title: "Also by Ada", AuthorID: ada.ID, Published: true, CreatedAt: now}
+
+	querier := &summaryQuerier{rows: [][]any{
+		postWithAuthorRow(byAda, ada),
+		postWithAuthorRow(byGrace, grace),
+		postWithAuthorRow(alsoByAda, ada),
+	}}
+	repo := NewPostRepository(database.NewDBWithQueriers(querier, querier))
+	tag := "go"
+
+	posts, err := repo.ListWithAuthors(context.Background(), &PostFilters{Tags: []string{tag}, Sort: model.PostSortTitle}, 10, 0)
+
+	require.NoError(t, err)
+	require.Len(t, querier.sql, 1)
+	assert.Contains(t, querier.sql[0], "LEFT JOIN users u ON u.id = p.author_id")
+	assert.Contains(t, querier.sql[0], "ORDER BY p.title ASC, p.id ASC")
+	assert.Equal(t, []any{[]string{tag}, 10, 0}, querier.args[0])
+
+	require.Len(t, posts, 3)
+	for i, expected := range []*model.User{ada, grace, ada} {
+		require.NotNil(t, posts[i].Author, "post %d", i)
+		assert.Equal(t, *expected, *posts[i].Author)
+		assert.Equal(t, posts[i].AuthorID, posts[i].Author.ID)
+	}
+	assert.Equal(t, byAda.PublishAt, posts[0].PublishAt)
+}
+
+func TestPostRepository_ListWithAuthorsMissingAuthor(t *testing.T) {
+	orphan := &model.Post{ID: uuid.New(), Title: "Orphan", AuthorID: uuid.New(), Published: true, CreatedAt: time.Now()}
+	querier := &summaryQuerier{rows: [][]any{postWithAuthorRow(orphan, nil)}}
+	repo := NewPostRepository(database.NewDBWithQueriers(querier, querier))
+
+	posts, err := repo.ListWithAuthors(context.Background(), nil, 10, 0)
+
+	require.NoError(t, err)
+	require.Len(t, posts, 1)
+	assert.Equal(t, orphan.ID, posts[0].ID)
+	assert.Equal(t, orphan.AuthorID, posts[0].AuthorID)
+	assert.Nil(t, posts[0].Author)
+}
+
+func TestContainsPattern(t *testing.T) {
+	tests := []struct {
+		term     string
+		expected string
+	}{
+		{"golang", "%golang%"},
+		{"100%", `%100\%%`},
+		{"snake_case", `%snake\_case%`},
+		{`C:\temp`, `%C:\\temp%`},
+		{"%%", `%\%\%%`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			assert.Equal(t, tt.expected, containsPattern(tt.term))
+		})
+	}
+}
+
+func TestPostRepository_SearchTreatsWildcardsLiterally(t *testing.T) {
+	discount := &model.Post{ID: uuid.New(), Title: "Get 100% off", Content: "Limited offer", Published: true}
+	unrelated := &model.Post{ID: uuid.New(), Title: "Release notes", Content: "Version 1000 is out", Published: true}
+	snake := &model.Post{ID: uuid.New(), Title: "Naming", Content: "Prefer snake_case in SQL", Published: true}
+	querier := &searchQuerier{posts: []*model.Post{discount, unrelated, snake}}
+	repo := NewPostRepository(database.NewDBWithQueriers(querier, querier))
+	ctx := context.Background()
+
+	tests := []struct {
+		term     string
+		expected []*model.Post
+	}{
+		{"100%", []*model.Post{discount}},
+		{"%%", []*model.Post{}},
+		{"e_c", []*model.Post{snake}},
+		{"__", []*model.Post{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			posts, err := repo.Search(ctx, tt.term, 10)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, posts)
+		})
+	}
+
+	assert.Contains(t, querier.sql[0], `title ILIKE $1 ESCAPE '\'`)
+	assert.Equal(t, []any{`%100\%%`, 10}, querier.args[0])
+}
+
+func TestPostRepository_SearchTermFilterTreatsWildcardsLiterally(t *testing.T) {
+	discount := &model.Post{ID: uuid.New(), Title: "Get 100% off", Content: "Limited offer", Published: true}
+	unrelated := &model.Post{ID: uuid.New(), Title: "Release notes", Content: "Version 1000 is out", Published: true}
+	term := "100%"
+
+	for _, count := range []bool{false, true} {
+		querier := &searchQuerier{posts: []*model.Post{discount, unrelated}}
+		repo := NewPostRepository(database.NewDBWithQueriers(querier, querier))
+		filters := &PostFilters{SearchTerm: &term}
+
+		if count {
+			_, _ = repo.Count(context.Background(), filters)
+		} else {
+			posts, err := repo.List(context.Background(), filters, 10, 0)
+			require.NoError(t, err)
+			assert.Equal(t, []*model.Post{discount}, posts)
+		}
+
+		require.Len(t, querier.sql, 1)
+		assert.Contains(t, querier.sql[0], `(title ILIKE $1 ESCAPE '\' OR content ILIKE $2 ESCAPE '\')`)
+		assert.Equal(t, `%100\%%`, querier.args[0][0])
+		assert.Equal(t, `%100\%%`, querier.args[0][1])
+	}
+}
+
+// tagStore holds post tags in memory and applies a transaction's tag merge
+// when it commits
+type tagStore struct {
+	recordingQuerier
+	tags      map[uuid.UUID][]string
+	sql       []string
+	commits   int
+	failQuery bool
+}
+
+func (s *tagStore) Begin(ctx context.Context) (pgx.Tx, error) {
+	return &tagTx{store: s}, nil
+}
+
+// tagTx stages merged tag arrays until Commit
+type tagTx struct {
+	pgx.Tx
+	store  *tagStore
+	staged map[uuid.UUID][]string
+}
+
+// Query emulates the merge UPDATE: array_replace followed by keeping each
+// tag at its first position
+func (tx *tagTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	tx.store.sql = append(tx.store.sql, sql)
+	if tx.store.failQuery {
+		return nil, errQuerierStub
+	}
+	from, into := args[0].(string), args[1].(string)
+
+	tx.staged = map[uuid.UUID][]string{}
+	var changed [][]any
+	for id, tags := range tx.store.tags {
+		if !slices.Contains(tags, from) {
+			continue
+		}
+		merged := []string{}
+		for _, tag := range tags {
+			if tag == from {
+				tag = into
+			}
+			if !slices.Contains(merged, tag) {
+				merged = append(merged, tag)
+			}
+		}
+		tx.staged[id] = merged
+		changed = append(changed, []any{id, merged})
+	}
+	return &tableRows{rows: changed, pos: -1}, nil
+}
+
+func (tx *tagTx) Commit(ctx context.Context) error {
+	for id, tags := range tx.staged {
+		tx.store.tags[id] = tags
+	}
+	tx.store.commits++
+	return nil
+}
+
+func (tx *tagTx) Rollback(ctx context.Context) error { return nil }
+
+func TestPostRepository_MergeTags(t *testing.T) {
+	drifted, both, unrelated := uuid.New(), uuid.New(), uuid.New()
+	store := &tagStore{tags: map[uuid.UUID][]string{
+		drifted:   {"go-lang", "web"},
+		both:      {"golang", "tips", "go-lang"},
+		unrelated: {"rust", "web"},
+	}}
+	repo := NewPostRepository(database.NewDBWithQueriers(store, nil))
+
+	ids, err := repo.MergeTags(context.Background(), "go-lang", "golang", false)
+
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{drifted, both}, ids)
+	assert.Equal(t, 1, store.commits)
+	assert.Equal(t, []string{"golang", "web"}, store.tags[drifted])
+	assert.Equal(t, []string{"golang", "tips"}, store.tags[both], "merged tag is duplicated")
+	assert.Equal(t, []string{"rust", "web"}, store.tags[unrelated])
+
+	require.Len(t, store.sql, 1)
+	assert.Contains(t, store.sql[0], "array_replace(tags, $1, $2)")
+	assert.Contains(t, store.sql[0], "WHERE $1 = ANY(tags)")
+}
+
+func TestPostRepository_MergeTagsDryRun(t *testing.T) {
+	drifted, both, unrelated := uuid.New(), uuid.New(), uuid.New()
+	store := &tagStore{tags: map[uuid.UUID][]string{
+		drifted:   {"go-lang", "web"},
+		both:      {"golang", "go-lang"},
+		unrelated: {"rust"},
+	}}
+	repo := NewPostRepository(database.NewDBWithQueriers(store, nil))
+
+	ids, err := repo.MergeTags(context.Background(), "go-lang", "golang", true)
+
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{drifted, both}, ids)
+	assert.Zero(t, store.commits, "dry run committed")
+	assert.Equal(t, []string{"go-lang", "web"}, store.tags[drifted])
+	assert.Equal(t, []string{"golang", "go-lang"}, store.tags[both])
+	require.Len(t, store.sql, 1)
+}
+
+func TestPostRepository_MergeTagsNoMatches(t *testing.T) {
+	store := &tagStore{tags: map[uuid.UUID][]string{uuid.New(): {"rust"}}}
+	repo := NewPostRepository(database.NewDBWithQueriers(store, nil))
+
+	ids, err := repo.MergeTags(context.Background(), "go-lang", "golang", false)
+
+	require.NoError(t, err)
+	assert.NotNil(t, ids)
+	assert.Empty(t, ids)
+}
+
+func TestPostRepository_MergeTagsFailureLeavesTags(t *testing.T) {
+	postID := uuid.New()
+	store := &tagStore{tags: map[uuid.UUID][]string{postID: {"go-lang"}}, failQuery: true}
+	repo := NewPostRepository(database.NewDBWithQueriers(store, nil))
+
+	_, err := repo.MergeTags(context.Background(), "go-lang", "golang", false)
+
+	require.ErrorIs(t, err, errQuerierStub)
+	assert.Zero(t, store.commits)
+	assert.Equal(t, []string{"go-lang"}, store.tags[postID])
+}
+
+func TestPostRepository_MergeTagsRejectsPostsBeyondLimits(t *testing.T) {
+	within, tooLong := uuid.New(), uuid.New()
+	store := &tagStore{tags: map[uuid.UUID][]string{
+		within:  {"go"},
+		tooLong: {"go", "golang", "tips"},
+	}}
+	repo := NewPostRepositoryWithTagLimits(database.NewDBWithQueriers(store, nil), model.PostTagLimits{MaxTags: 5, MaxTagLength: 20, MaxTotalTagLength: 20})
+
+	_, err := repo.MergeTags(context.Background(), "go", "go-programming", false)
+
+	require.ErrorIs(t, err, ErrInvalidTags)
+	assert.Contains(t, err.Error(), tooLong.String())
+	assert.Zero(t, store.commits)
+	assert.Equal(t, []string{"go"}, store.tags[within], "the merge was applied to other posts")
+	assert.Equal(t, []string{"go", "golang", "tips"}, store.tags[tooLong])
+}
+
+// tooLongTogether returns tags within the default count and per-tag limits
+// whose combined length exceeds the default total
+func tooLongTogether() []string {
+	limits := model.DefaultPostTagLimits()
+	tags := make([]string, limits.MaxTags)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("%s%d", strings.Repeat("x", limits.MaxTagLength-1), i)
+	}
+	return tags
+}
+
+func TestPostRepository_RejectsTagsBeyondLimits(t *testing.T) {
+	tooMany := make([]string, model.DefaultPostTagLimits().MaxTags+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("tag%d", i)
+	}
+
+	tests := []struct {
+		name string
+		tags []string
+	}{
+		{name: "too many tags", tags: tooMany},
+		{name: "tag too long", tags: []string{"go", strings.Repeat("x", model.DefaultPostTagLimits().MaxTagLength+1)}},
+		{name: "tags too long together", tags: tooLongTogether()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &recordingQuerier{}
+			repo := NewPostRepository(database.NewDBWithQueriers(primary, primary))
+			post := &model.Post{ID: uuid.New(), Title: "Title", Content: "Content", AuthorID: uuid.New(), Tags: tt.tags}
+
+			assert.ErrorIs(t, repo.Create(context.Background(), post), ErrInvalidTags)
+			assert.ErrorIs(t, repo.Update(context.Background(), post), ErrInvalidTags)
+			assert.Zero(t, primary.execs)
+			assert.Zero(t, primary.queries)
+		})
+	}
+}
+
+func TestPostRepository_CustomTagLimits(t *testing.T) {
+	primary := &recordingQuerier{}
+	repo := NewPostRepositoryWithTagLimits(database.NewDBWithQueriers(primary, primary), model.PostTagLimits{MaxTags: 2, MaxTagLength: 5})
+	post := &model.Post{ID: uuid.New(), Title: "Title", Content: "Content", AuthorID: uuid.New()}
+
+	post.Tags = []string{"go", "sql", "web"}
+	assert.ErrorIs(t, repo.Update(context.Background(), post), ErrInvalidTags)
+
+	post.Tags = []string{"golang"}
+	assert.ErrorIs(t, repo.Update(context.Background(), post), ErrInvalidTags)
+	assert.Zero(t, primary.execs)
+
+	post.Tags = []string{"go", "sql"}
+	require.NoError(t, repo.Update(context.Background(), post))
+	assert.Equal(t, 1, primary.execs)
+}
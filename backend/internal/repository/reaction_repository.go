@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"backend/internal/database"
+	"backend/internal/graph/model"
+	"github.com/google/uuid"
+)
+
+// reactionRepository implements ReactionRepository interface
+type reactionRepository struct {
+	db *database.DB
+}
+
+// NewReactionRepository creates a new reaction repository
+func NewReactionRepository(db *database.DB) ReactionRepository {
+	return &reactionRepository{db: db}
+}
+
+// Add records a reaction; adding the same reaction twice is a no-op
+func (r *reactionRepository) Add(ctx context.Context, reaction *model.Reaction) error {
+	query := `
+		INSERT INTO post_reactions (post_id, user_id, reaction, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (post_id, user_id, reaction) DO NOTHING
+	`
+
+	_, err := r.db.Writer().Exec(ctx, query,
+		reaction.PostID, reaction.UserID, reaction.Type, reaction.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to add reaction: %w", err)
+	}
+
+	return nil
+}
+
+// Remove deletes a user's reaction from a post
+func (r *reactionRepository) Remove(ctx context.Context, postID, userID uuid.UUID, reactionType string) error {
+	query := `DELETE FROM post_reactions WHERE post_id = $1 AND user_id = $2 AND reaction = $3`
+
+	result, err := r.db.Writer().Exec(ctx, query, postID, userID, reactionType)
+	if err != nil {
+		return fmt.Errorf("failed to remove reaction: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("reaction not found")
+	}
+
+	return nil
+}
+
+// CountByPostIDs returns the number of reactions on each post in one query (for DataLoader).
+// Posts without reactions are absent from the result.
+func (r *reactionRepository) CountByPostIDs(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	counts := make(map[uuid.UUID]int)
+	if len(postIDs) == 0 {
+		return counts, nil
+	}
+
+	query := `
+		SELECT post_id, COUNT(*)
+		FROM post_reactions
+		WHERE post_id = ANY($1)
+		GROUP BY post_id
+	`
+
+	rows, err := r.db.Reader().Query(ctx, query, postIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count reactions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID uuid.UUID
+		var count int
+		if err := rows.Scan(&postID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan reaction count: %w", err)
+		}
+		counts[postID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reaction counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// CountByTypeByPostIDs returns each post's reaction counts per reaction type,
+// ordered by type, in one query (for DataLoader). Posts without reactions are
+// absent from the result.
+func (r *reactionRepository) CountByTypeByPostIDs(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID][]*model.ReactionTypeCount, error) {
+	counts := make(map[uuid.UUID][]*model.ReactionTypeCount)
+	if len(postIDs) == 0 {
+		return counts, nil
+	}
+
+	query := `
+		SELECT post_id, reaction, COUNT(*)
+		FROM post_reactions
+		WHERE post_id = ANY($1)
+		GROUP BY post_id, reaction
+		ORDER BY post_id, reaction
+	`
+
+	rows, err := r.db.Reader().Query(ctx, query, postIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count reactions by type: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID uuid.UUID
+		count := &model.ReactionTypeCount{}
+		if err := rows.Scan(&postID, &count.Type, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan reaction type count: %w", err)
+		}
+		counts[postID] = append(counts[postID], count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reaction type counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// ViewerReactedByPostIDs reports which of the posts the viewer has reacted to in one query (for DataLoader)
+func (r *reactionRepository) ViewerReactedByPostIDs(ctx context.Context, viewerID uuid.UUID, postIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	reacted := make(map[uuid.UUID]bool)
+	if len(postIDs) == 0 {
+		return reacted, nil
+	}
+
+	query := `
+		SELECT DISTINCT post_id
+		FROM post_reactions
+		WHERE user_id = $1 AND post_id = ANY($2)
+	`
+
+	rows, err := r.db.Reader().Query(ctx, query, viewerID, postIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get viewer reactions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID uuid.UUID
+		if err := rows.Scan(&postID); err != nil {
+			return nil, fmt.Errorf("failed to scan viewer reaction: %w", err)
+		}
+		reacted[postID] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating viewer reactions: %w", err)
+	}
+
+	return reacted, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/database"
+	"backend/internal/graph/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrRefreshTokenInvalid is returned for unknown, revoked or expired refresh tokens
+	ErrRefreshTokenInvalid = errors.New("invalid or expired refresh token")
+	// ErrRefreshTokenReused is returned when an already used refresh token is presented again
+	ErrRefreshTokenReused = errors.New("refresh token was already used")
+)
+
+// refreshTokenRepository implements RefreshTokenRepository interface
+type refreshTokenRepository struct {
+	db *database.DB
+}
+
+// NewRefreshTokenRepository creates a new refresh token repository
+func NewRefreshTokenRepository(db *database.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+// refreshTokenColumns are the columns scanned by scanRefreshToken
+const refreshTokenColumns = `id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at`
+
+// scanRefreshToken scans a row of refreshTokenColumns
+func scanRefreshToken(row pgx.Row) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := row.Scan(
+		&token.ID, &token.UserID, &token.FamilyID, &token.TokenHash,
+		&token.ExpiresAt, &token.UsedAt, &token.RevokedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Create stores a newly issued refresh token
+func (r *refreshTokenRepository) Create(ctx context.Context, token *model.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.Writer().Exec(ctx, query,
+		token.ID, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt, token.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// Use marks a usable token as used in a single statement, so concurrent
+// refreshes with one token cannot both succeed
+func (r *refreshTokenRepository) Use(ctx context.Context, tokenHash string, now time.Time) (*model.RefreshToken, error) {
+	query := `
+		UPDATE refresh_tokens SET used_at = $2
+		WHERE token_hash = $1 AND used_at IS NULL AND revoked_at IS NULL AND expires_at > $2
+		RETURNING ` + refreshTokenColumns
+
+	token, err := scanRefreshToken(r.db.Writer().QueryRow(ctx, query, tokenHash, now))
+	if err == nil {
+		return token, nil
+	}
+	if err != pgx.ErrNoRows {
+		return nil, fmt.Errorf("failed to use refresh token: %w", err)
+	}
+
+	// Tell a replayed token apart from an unknown, revoked or expired one
+	lookup := `SELECT ` + refreshTokenColumns + ` FROM refresh_tokens WHERE token_hash = $1`
+	token, err = scanRefreshToken(r.db.Writer().QueryRow(ctx, lookup, tokenHash))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrRefreshTokenInvalid
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	if token.UsedAt != nil {
+		return token, ErrRefreshTokenReused
+	}
+	return nil, ErrRefreshTokenInvalid
+}
+
+// RevokeFamily revokes every unrevoked token in the family of the token with tokenHash
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, tokenHash string, at time.Time) error {
+	query := `
+		UPDATE refresh_tokens SET revoked_at = $2
+		WHERE family_id = (SELECT family_id FROM refresh_tokens WHERE token_hash = $1)
+		AND revoked_at IS NULL
+	`
+
+	if _, err := r.db.Writer().Exec(ctx, query, tokenHash, at); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeByUserID revokes every unrevoked token of a user
+func (r *refreshTokenRepository) RevokeByUserID(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	query := `UPDATE refresh_tokens SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL`
+
+	if _, err := r.db.Writer().Exec(ctx, query, userID, at); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/database"
+	"backend/internal/graph/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// refreshQuerier is an in-memory refresh_tokens table keyed by token hash
+type refreshQuerier struct {
+	recordingQuerier
+	tokens map[string]*model.RefreshToken
+}
+
+func (q *refreshQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	token, ok := q.tokens[args[0].(string)]
+	if strings.Contains(sql, "UPDATE refresh_tokens SET used_at") {
+		now := args[1].(time.Time)
+		if !ok || token.UsedAt != nil || token.RevokedAt != nil || !token.ExpiresAt.After(now) {
+			return userIDRow{}
+		}
+		token.UsedAt = &now
+	} else if !ok {
+		return userIDRow{}
+	}
+	return &tableRows{rows: [][]any{{
+		token.ID, token.UserID, token.FamilyID, token.TokenHash,
+		token.ExpiresAt, token.UsedAt, token.RevokedAt, token.CreatedAt,
+	}}}
+}
+
+func newRefreshQuerier(tokens ...*model.RefreshToken) *refreshQuerier {
+	q := &refreshQuerier{tokens: map[string]*model.RefreshToken{}}
+	for _, token := range tokens {
+		q.tokens[token.TokenHash] = token
+	}
+	return q
+}
+
+func storedRefreshToken(hash string, expiresAt time.Time) *model.RefreshToken {
+	return &model.RefreshToken{ID: uuid.New(), UserID: uuid.New(), FamilyID: uuid.New(), TokenHash: hash, ExpiresAt: expiresAt, CreatedAt: time.Now()}
+}
+
+func TestRefreshTokenRepository_UseMarksTokenUsed(t *testing.T) {
+	stored := storedRefreshToken("hash", time.Now().Add(time.Hour))
+	querier := newRefreshQuerier(stored)
+	repo := NewRefreshTokenRepository(database.NewDBWithQueriers(querier, nil))
+
+	token, err := repo.Use(context.Background(), "hash", time.Now())
+
+	require.NoError(t, err)
+	assert.Equal(t, stored.FamilyID, token.FamilyID)
+	assert.NotNil(t, token.UsedAt)
+}
+
+func TestRefreshTokenRepository_UseDetectsReuse(t *testing.T) {
+	stored := storedRefreshToken("hash", time.Now().Add(time.Hour))
+	repo := NewRefreshTokenRepository(database.NewDBWithQueriers(newRefreshQuerier(stored), nil))
+
+	_, err := repo.Use(context.Background(), "hash", time.Now())
+	require.NoError(t, err)
+
+	token, err := repo.Use(context.Background(), "hash", time.Now())
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	require.NotNil(t, token)
+	assert.Equal(t, stored.FamilyID, token.FamilyID)
+}
+
+func TestRefreshTokenRepository_UseRejectsUnusableTokens(t *testing.T) {
+	revokedAt := time.Now()
+	revoked := storedRefreshToken("revoked", time.Now().Add(time.Hour))
+	revoked.RevokedAt = &revokedAt
+	expired := storedRefreshToken("expired", time.Now().Add(-time.Minute))
+	repo := NewRefreshTokenRepository(database.NewDBWithQueriers(newRefreshQuerier(revoked, expired), nil))
+
+	for _, hash := range []string{"revoked", "expired", "unknown"} {
+		token, err := repo.Use(context.Background(), hash, time.Now())
+		assert.ErrorIs(t, err, ErrRefreshTokenInvalid, hash)
+		assert.Nil(t, token, hash)
+	}
+}
+
+func TestRefreshTokenRepository_RevokeByUserID(t *testing.T) {
+	querier := &execQuerier{rowsAffected: "UPDATE 2"}
+	repo := NewRefreshTokenRepository(database.NewDBWithQueriers(querier, nil))
+	userID := uuid.New()
+	at := time.Now()
+
+	require.NoError(t, repo.RevokeByUserID(context.Background(), userID, at))
+
+	require.Len(t, querier.sql, 1)
+	assert.Contains(t, querier.sql[0], "revoked_at IS NULL")
+	assert.Equal(t, []any{userID, at}, querier.args[0])
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/database"
+	"backend/internal/graph/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrReportNotOpen is returned when resolving a report that is missing or
+// already closed
+var ErrReportNotOpen = errors.New("report not found")
+
+// reportRepository implements ReportRepository interface
+type reportRepository struct {
+	db *database.DB
+}
+
+// NewReportRepository creates a new report repository
+func NewReportRepository(db *database.DB) ReportRepository {
+	return &reportRepository{db: db}
+}
+
+// reportColumns lists the columns scanReport reads, in order
+const reportColumns = `id, reporter_id, target_type, target_id, reason, status, created_at, resolved_at`
+
+// Create stores a report. A reporter's repeat report of content they already
+// have an open report on returns that report unchanged.
+func (r *reportRepository) Create(ctx context.Context, report *model.Report) (*model.Report, error) {
+	query := `
+		INSERT INTO reports (id, reporter_id, target_type, target_id, reason, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (reporter_id, target_type, target_id) WHERE status = 'OPEN' DO NOTHING
+		RETURNING ` + reportColumns
+
+	created, err := scanReport(r.db.Writer().QueryRow(ctx, query,
+		report.ID, report.ReporterID, string(report.TargetType), report.TargetID,
+		report.Reason, string(report.Status), report.CreatedAt,
+	))
+	if err == nil {
+		return created, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to create report: %w", err)
+	}
+
+	// Read the open report from the primary, where the conflicting row is
+	query = `
+		SELECT ` + reportColumns + `
+		FROM reports
+		WHERE reporter_id = $1 AND target_type = $2 AND target_id = $3 AND status = 'OPEN'
+	`
+	existing, err := scanReport(r.db.Writer().QueryRow(ctx, query,
+		report.ReporterID, string(report.TargetType), report.TargetID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open report: %w", err)
+	}
+
+	return existing, nil
+}
+
+// GetByID retrieves a report by ID
+func (r *reportRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Report, error) {
+	query := `SELECT ` + reportColumns + ` FROM reports WHERE id = $1`
+
+	report, err := scanReport(r.db.Reader().QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("report not found")
+		}
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+
+	return report, nil
+}
+
+// ListByStatus lists reports with the given status, oldest first so the
+// moderation queue is worked in order
+func (r *reportRepository) ListByStatus(ctx context.Context, status model.ReportStatus, limit, offset int) ([]*model.Report, error) {
+	limit, err := checkPage(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + reportColumns + `
+		FROM reports
+		WHERE status = $1
+		ORDER BY created_at ASC, id ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Reader().Query(ctx, query, string(status), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []*model.Report{}
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan report: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reports: %w", err)
+	}
+
+	return reports, nil
+}
+
+// Resolve closes an open report and the other open reports of the same
+// content in one transaction, hiding the content first when asked to. A
+// report that is missing or already closed is ErrReportNotOpen.
+func (r *reportRepository) Resolve(ctx context.Context, id uuid.UUID, status model.ReportStatus, hide bool, at time.Time) (int, error) {
+	claimQuery := `
+		UPDATE reports SET status = $2, resolved_at = $3
+		WHERE id = $1 AND status = 'OPEN'
+		RETURNING target_type, target_id, reason
+	`
+	siblingsQuery := `
+		UPDATE reports SET status = $3, resolved_at = $4
+		WHERE target_type = $1 AND target_id = $2 AND status = 'OPEN'
+	`
+
+	closed := 0
+	err := r.db.WithTx(ctx, func(tx database.Querier) error {
+		var targetType, reason string
+		var targetID uuid.UUID
+		if err := tx.QueryRow(ctx, claimQuery, id, string(status), at).Scan(&targetType, &targetID, &reason); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrReportNotOpen
+			}
+			return fmt.Errorf("failed to resolve report: %w", err)
+		}
+
+		if hide {
+			if err := hideReportedContent(ctx, tx, model.ReportTargetType(targetType), targetID, reason); err != nil {
+				return err
+			}
+		}
+
+		result, err := tx.Exec(ctx, siblingsQuery, targetType, targetID, string(status), at)
+		if err != nil {
+			return fmt.Errorf("failed to resolve reports of the same content: %w", err)
+		}
+		closed = 1 + int(result.RowsAffected())
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return closed, nil
+}
+
+// hideReportedContent hides the post or comment a report is about, with the
+// report's reason as the moderation note
+func hideReportedContent(ctx context.Context, tx database.Querier, targetType model.ReportTargetType, targetID uuid.UUID, reason string) error {
+	var query, kind string
+	switch targetType {
+	case model.ReportTargetTypePost:
+		query, kind = `UPDATE posts SET hidden = true, hidden_reason = $2 WHERE id = $1`, "post"
+	case model.ReportTargetTypeComment:
+		query, kind = `UPDATE comments SET hidden = true, hidden_reason = $2 WHERE id = $1`, "comment"
+	default:
+		return fmt.Errorf("unknown report target type %q", targetType)
+	}
+
+	result, err := tx.Exec(ctx, query, targetID, reason)
+	if err != nil {
+		return fmt.Errorf("failed to hide reported %s: %w", kind, err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s not found", kind)
+	}
+	return nil
+}
+
+// scanReport scans the reportColumns of one row
+func scanReport(row pgx.Row) (*model.Report, error) {
+	var report model.Report
+	var targetType, status string
+	err := row.Scan(
+		&report.ID, &report.ReporterID, &targetType, &report.TargetID,
+		&report.Reason, &status, &report.CreatedAt, &report.ResolvedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	report.TargetType = model.ReportTargetType(targetType)
+	report.Status = model.ReportStatus(status)
+	return &report, nil
+}
@@ -0,0 +1,304 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/database"
+	"backend/internal/graph/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reportQuerier keeps reports and the hidden state of their targets in
+// memory and answers the report repository's statements, enforcing one open
+// report per reporter and target
+type reportQuerier struct {
+	recordingQuerier
+	reports []*model.Report
+	// targets holds the existing posts and comments, with their hide reasons
+	targets map[uuid.UUID]string
+	hidden  map[uuid.UUID]bool
+}
+
+// Begin starts a transaction that restores the reports and targets it
+// changed unless it commits
+func (q *reportQuerier) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx := &reportTx{q: q, targets: map[uuid.UUID]string{}, hidden: map[uuid.UUID]bool{}}
+	for _, report := range q.reports {
+		tx.reports = append(tx.reports, *report)
+	}
+	for id, reason := range q.targets {
+		tx.targets[id] = reason
+	}
+	for id, hidden := range q.hidden {
+		tx.hidden[id] = hidden
+	}
+	return tx, nil
+}
+
+// reportTx runs statements on its querier, keeping a snapshot to roll back to
+type reportTx struct {
+	pgx.Tx
+	q         *reportQuerier
+	reports   []model.Report
+	targets   map[uuid.UUID]string
+	hidden    map[uuid.UUID]bool
+	committed bool
+}
+
+func (tx *reportTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return tx.q.QueryRow(ctx, sql, args...)
+}
+
+func (tx *reportTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return tx.q.Exec(ctx, sql, args...)
+}
+
+func (tx *reportTx) Commit(ctx context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *reportTx) Rollback(ctx context.Context) error {
+	if tx.committed {
+		return nil
+	}
+	for i := range tx.reports {
+		*tx.q.reports[i] = tx.reports[i]
+	}
+	tx.q.targets, tx.q.hidden = tx.targets, tx.hidden
+	return nil
+}
+
+func (q *reportQuerier) open(reporterID any, targetType any, targetID any) *model.Report {
+	for _, report := range q.reports {
+		if report.ReporterID == reporterID && string(report.TargetType) == targetType &&
+			report.TargetID == targetID && report.Status == model.ReportStatusOpen {
+			return report
+		}
+	}
+	return nil
+}
+
+func (q *reportQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	q.queries++
+	switch {
+	case strings.Contains(sql, "INSERT INTO reports"):
+		if q.open(args[1], args[2], args[3]) != nil {
+			return reportRow{}
+		}
+		report := &model.Report{
+			ID:         args[0].(uuid.UUID),
+			ReporterID: args[1].(uuid.UUID),
+			TargetType: model.ReportTargetType(args[2].(string)),
+			TargetID:   args[3].(uuid.UUID),
+			Reason:     args[4].(string),
+			Status:     model.ReportStatus(args[5].(string)),
+			CreatedAt:  args[6].(time.Time),
+		}
+		q.reports = append(q.reports, report)
+		return reportRow{report}
+	case strings.Contains(sql, "reporter_id = $1"):
+		return reportRow{q.open(args[0], args[1], args[2])}
+	case strings.Contains(sql, "RETURNING target_type"):
+		for _, report := range q.reports {
+			if report.ID == args[0] && report.Status == model.ReportStatusOpen {
+				at := args[2].(time.Time)
+				report.Status = model.ReportStatus(args[1].(string))
+				report.ResolvedAt = &at
+				return claimedReportRow{report}
+			}
+		}
+		return claimedReportRow{}
+	default:
+		for _, report := range q.reports {
+			if report.ID == args[0] {
+				return reportRow{report}
+			}
+		}
+		return reportRow{}
+	}
+}
+
+func (q *reportQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	q.execs++
+	if strings.Contains(sql, "SET hidden = true") {
+		targetID := args[0].(uuid.UUID)
+		if _, ok := q.targets[targetID]; !ok {
+			return pgconn.NewCommandTag("UPDATE 0"), nil
+		}
+		q.targets[targetID] = args[1].(string)
+		q.hidden[targetID] = true
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	}
+
+	closed := 0
+	for _, report := range q.reports {
+		if string(report.TargetType) == args[0] && report.TargetID == args[1] && report.Status == model.ReportStatusOpen {
+			at := args[3].(time.Time)
+			report.Status = model.ReportStatus(args[2].(string))
+			report.ResolvedAt = &at
+			closed++
+		}
+	}
+	return pgconn.NewCommandTag(fmt.Sprintf("UPDATE %d", closed)), nil
+}
+
+// claimedReportRow is the RETURNING row of a claimed report, or no rows when nil
+type claimedReportRow struct {
+	report *model.Report
+}
+
+func (r claimedReportRow) Scan(dest ...any) error {
+	if r.report == nil {
+		return pgx.ErrNoRows
+	}
+	*dest[0].(*string) = string(r.report.TargetType)
+	*dest[1].(*uuid.UUID) = r.report.TargetID
+	*dest[2].(*string) = r.report.Reason
+	return nil
+}
+
+// reportRow is a pgx.Row holding one report, or no rows when nil
+type reportRow struct {
+	report *model.Report
+}
+
+func (r reportRow) Scan(dest ...any) error {
+	if r.report == nil {
+		return pgx.ErrNoRows
+	}
+	*dest[0].(*uuid.UUID) = r.report.ID
+	*dest[1].(*uuid.UUID) = r.report.ReporterID
+	*dest[2].(*string) = string(r.report.TargetType)
+	*dest[3].(*uuid.UUID) = r.report.TargetID
+	*dest[4].(*string) = r.report.Reason
+	*dest[5].(*string) = string(r.report.Status)
+	*dest[6].(*time.Time) = r.report.CreatedAt
+	*dest[7].(**time.Time) = r.report.ResolvedAt
+	return nil
+}
+
+func newReport(reporterID, targetID uuid.UUID, reason string) *model.Report {
+	return &model.Report{
+		ID:         uuid.New(),
+		ReporterID: reporterID,
+		TargetType: model.ReportTargetTypePost,
+		TargetID:   targetID,
+		Reason:     reason,
+		Status:     model.ReportStatusOpen,
+		CreatedAt:  time.Now(),
+	}
+}
+
+func TestReportRepository_CreateDeduplicatesOpenReports(t *testing.T) {
+	querier := &reportQuerier{}
+	repo := NewReportRepository(database.NewDBWithQueriers(querier, querier))
+	ctx := context.Background()
+	reporter, other, postID := uuid.New(), uuid.New(), uuid.New()
+
+	first, err := repo.Create(ctx, newReport(reporter, postID, "Spam"))
+	require.NoError(t, err)
+
+	repeat, err := repo.Create(ctx, newReport(reporter, postID, "Still spam"))
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, repeat.ID)
+	assert.Equal(t, "Spam", repeat.Reason, "the open report is returned unchanged")
+
+	fromOther, err := repo.Create(ctx, newReport(other, postID, "Spam"))
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, fromOther.ID)
+	assert.Len(t, querier.reports, 2)
+}
+
+func TestReportRepository_ReportAgainOnceResolved(t *testing.T) {
+	querier := &reportQuerier{}
+	repo := NewReportRepository(database.NewDBWithQueriers(querier, querier))
+	ctx := context.Background()
+	reporter, postID := uuid.New(), uuid.New()
+
+	first, err := repo.Create(ctx, newReport(reporter, postID, "Spam"))
+	require.NoError(t, err)
+	_, err = repo.Resolve(ctx, first.ID, model.ReportStatusDismissed, false, time.Now())
+	require.NoError(t, err)
+
+	second, err := repo.Create(ctx, newReport(reporter, postID, "Spam again"))
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, second.ID)
+
+	stored, err := repo.GetByID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.ReportStatusDismissed, stored.Status)
+	assert.NotNil(t, stored.ResolvedAt)
+}
+
+func TestReportRepository_ResolveOnlyOpenReports(t *testing.T) {
+	querier := &reportQuerier{}
+	repo := NewReportRepository(database.NewDBWithQueriers(querier, querier))
+	ctx := context.Background()
+
+	report, err := repo.Create(ctx, newReport(uuid.New(), uuid.New(), "Spam"))
+	require.NoError(t, err)
+
+	_, err = repo.Resolve(ctx, report.ID, model.ReportStatusResolved, false, time.Now())
+	require.NoError(t, err)
+	_, err = repo.Resolve(ctx, report.ID, model.ReportStatusDismissed, false, time.Now())
+	assert.ErrorIs(t, err, ErrReportNotOpen)
+	_, err = repo.Resolve(ctx, uuid.New(), model.ReportStatusResolved, false, time.Now())
+	assert.ErrorIs(t, err, ErrReportNotOpen)
+
+	_, err = repo.GetByID(ctx, uuid.New())
+	assert.EqualError(t, err, "report not found")
+}
+
+func TestReportRepository_ResolveClosesReportsOfTheSameContent(t *testing.T) {
+	postID, otherPostID := uuid.New(), uuid.New()
+	querier := &reportQuerier{targets: map[uuid.UUID]string{postID: ""}, hidden: map[uuid.UUID]bool{}}
+	repo := NewReportRepository(database.NewDBWithQueriers(querier, querier))
+	ctx := context.Background()
+
+	report, err := repo.Create(ctx, newReport(uuid.New(), postID, "Spam"))
+	require.NoError(t, err)
+	sibling, err := repo.Create(ctx, newReport(uuid.New(), postID, "Also spam"))
+	require.NoError(t, err)
+	unrelated, err := repo.Create(ctx, newReport(uuid.New(), otherPostID, "Spam"))
+	require.NoError(t, err)
+
+	closed, err := repo.Resolve(ctx, report.ID, model.ReportStatusResolved, true, time.Now())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, closed)
+	assert.True(t, querier.hidden[postID])
+	assert.Equal(t, "Spam", querier.targets[postID], "the report's reason is the moderation note")
+	stored, err := repo.GetByID(ctx, sibling.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.ReportStatusResolved, stored.Status)
+	stored, err = repo.GetByID(ctx, unrelated.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.ReportStatusOpen, stored.Status)
+}
+
+func TestReportRepository_ResolveRollsBackWhenHidingFails(t *testing.T) {
+	querier := &reportQuerier{targets: map[uuid.UUID]string{}, hidden: map[uuid.UUID]bool{}}
+	repo := NewReportRepository(database.NewDBWithQueriers(querier, querier))
+	ctx := context.Background()
+
+	// The reported post no longer exists
+	report, err := repo.Create(ctx, newReport(uuid.New(), uuid.New(), "Spam"))
+	require.NoError(t, err)
+
+	_, err = repo.Resolve(ctx, report.ID, model.ReportStatusResolved, true, time.Now())
+
+	assert.EqualError(t, err, "post not found")
+	stored, err := repo.GetByID(ctx, report.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.ReportStatusOpen, stored.Status, "the report was closed without hiding its content")
+	assert.Nil(t, stored.ResolvedAt)
+}
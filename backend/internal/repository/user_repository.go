@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"backend/internal/database"
 	"backend/internal/graph/model"
@@ -11,6 +13,9 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
+// ErrUserNotFound is returned by GetByID when no user has the ID
+var ErrUserNotFound = errors.New("user not found")
+
 // userRepository implements UserRepository interface
 type userRepository struct {
 	db *database.DB
@@ -24,13 +29,13 @@ func NewUserRepository(db *database.DB) UserRepository {
 // Create creates a new user
 func (r *userRepository) Create(ctx context.Context, user *model.User) error {
 	query := `
-		INSERT INTO users (id, email, name, password_hash, avatar, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO users (id, email, name, password_hash, avatar, created_at, updated_at, role)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE(NULLIF($8, ''), 'user'))
 	`
 	
-	_, err := r.db.Pool.Exec(ctx, query,
+	_, err := r.db.Writer().Exec(ctx, query,
 		user.ID, user.Email, user.Name, user.PasswordHash, 
-		user.Avatar, user.CreatedAt, user.UpdatedAt,
+		user.Avatar, user.CreatedAt, user.UpdatedAt, user.Role,
 	)
 	
 	if err != nil {
@@ -43,20 +48,22 @@ func (r *userRepository) Create(ctx context.Context, user *model.User) error {
 // GetByID retrieves a user by ID
 func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
 	query := `
-		SELECT id, email, name, password_hash, avatar, created_at, updated_at
+		SELECT id, email, name, password_hash, avatar, created_at, updated_at,
+		       last_login_at, last_login_ip, role
 		FROM users 
 		WHERE id = $1
 	`
 	
 	var user model.User
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.Reader().QueryRow(ctx, query, id).Scan(
 		&user.ID, &user.Email, &user.Name, &user.PasswordHash,
 		&user.Avatar, &user.CreatedAt, &user.UpdatedAt,
+		&user.LastLoginAt, &user.LastLoginIP, &user.Role,
 	)
 	
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -67,15 +74,18 @@ func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.User
 // GetByEmail retrieves a user by email
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	query := `
-		SELECT id, email, name, password_hash, avatar, created_at, updated_at
+		SELECT id, email, name, password_hash, avatar, created_at, updated_at,
+		       last_login_at, last_login_ip, role
 		FROM users 
-		WHERE email = $1
+		WHERE lower(email) = lower($1)
 	`
 	
+	// Read from the primary: uniqueness checks must not see replica lag
 	var user model.User
-	err := r.db.Pool.QueryRow(ctx, query, email).Scan(
+	err := r.db.Writer().QueryRow(ctx, query, email).Scan(
 		&user.ID, &user.Email, &user.Name, &user.PasswordHash,
 		&user.Avatar, &user.CreatedAt, &user.UpdatedAt,
+		&user.LastLoginAt, &user.LastLoginIP, &user.Role,
 	)
 	
 	if err != nil {
@@ -88,6 +98,19 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.U
 	return &user, nil
 }
 
+// ExistsByEmail reports whether a user is registered with email without loading the row
+func (r *userRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE lower(email) = lower($1))`
+
+	// Read from the primary: uniqueness checks must not see replica lag
+	var exists bool
+	if err := r.db.Writer().QueryRow(ctx, query, email).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check user: %w", err)
+	}
+
+	return exists, nil
+}
+
 // GetByIDs retrieves multiple users by their IDs (for DataLoader)
 func (r *userRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.User, error) {
 	if len(ids) == 0 {
@@ -103,35 +126,115 @@ func (r *userRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*mode
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, email, name, password_hash, avatar, created_at, updated_at
+		SELECT id, email, name, password_hash, avatar, created_at, updated_at,
+		       last_login_at, last_login_ip, role
 		FROM users 
 		WHERE id IN (%s)
 	`, strings.Join(placeholders, ","))
 
-	rows, err := r.db.Pool.Query(ctx, query, args...)
+	rows, err := r.db.Reader().Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get users: %w", err)
 	}
 	defer rows.Close()
 
-	var users []*model.User
+	return r.scanUsers(rows)
+}
+
+// ExistsByIDs reports which of the given user IDs exist, in a single query.
+// Every requested ID is present in the result.
+func (r *userRepository) ExistsByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	exists := make(map[uuid.UUID]bool, len(ids))
+	if len(ids) == 0 {
+		return exists, nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+		exists[id] = false
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id
+		FROM users
+		WHERE id IN (%s)
+	`, inPlaceholders(len(ids)))
+
+	rows, err := r.db.Reader().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check users: %w", err)
+	}
+	defer rows.Close()
+
 	for rows.Next() {
-		var user model.User
-		err := rows.Scan(
-			&user.ID, &user.Email, &user.Name, &user.PasswordHash,
-			&user.Avatar, &user.CreatedAt, &user.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan user: %w", err)
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user id: %w", err)
 		}
-		users = append(users, &user)
+		exists[id] = true
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating users: %w", err)
 	}
 
-	return users, nil
+	return exists, nil
+}
+
+// ExistsByEmails reports which of the given emails belong to a user, in a single
+// query and ignoring case. Every requested email is present in the result, keyed as given.
+func (r *userRepository) ExistsByEmails(ctx context.Context, emails []string) (map[string]bool, error) {
+	exists := make(map[string]bool, len(emails))
+	if len(emails) == 0 {
+		return exists, nil
+	}
+
+	args := make([]interface{}, len(emails))
+	requested := make(map[string][]string, len(emails))
+	for i, email := range emails {
+		lower := strings.ToLower(email)
+		args[i] = lower
+		requested[lower] = append(requested[lower], email)
+		exists[email] = false
+	}
+
+	query := fmt.Sprintf(`
+		SELECT lower(email)
+		FROM users
+		WHERE lower(email) IN (%s)
+	`, inPlaceholders(len(emails)))
+
+	rows, err := r.db.Reader().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, fmt.Errorf("failed to scan user email: %w", err)
+		}
+		for _, given := range requested[email] {
+			exists[given] = true
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return exists, nil
+}
+
+// inPlaceholders returns "$1,$2,...,$n" for an IN clause
+func inPlaceholders(n int) string {
+	placeholders := make([]string, n)
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	return strings.Join(placeholders, ",")
 }
 
 // Update updates an existing user
@@ -142,7 +245,7 @@ func (r *userRepository) Update(ctx context.Context, user *model.User) error {
 		WHERE id = $1
 	`
 	
-	result, err := r.db.Pool.Exec(ctx, query,
+	result, err := r.db.Writer().Exec(ctx, query,
 		user.ID, user.Name, user.Avatar, user.UpdatedAt,
 	)
 	
@@ -157,11 +260,64 @@ func (r *userRepository) Update(ctx context.Context, user *model.User) error {
 	return nil
 }
 
+// UpdateEmail replaces a user's email address
+func (r *userRepository) UpdateEmail(ctx context.Context, id uuid.UUID, email string) error {
+	query := `
+		UPDATE users 
+		SET email = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	
+	result, err := r.db.Writer().Exec(ctx, query, id, email)
+	if err != nil {
+		return fmt.Errorf("failed to update user email: %w", err)
+	}
+	
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user not found")
+	}
+	
+	return nil
+}
+
+// UpdatePassword replaces a user's password hash. Update leaves the hash
+// alone, so every password change goes through here.
+func (r *userRepository) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	query := `
+		UPDATE users 
+		SET password_hash = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	
+	result, err := r.db.Writer().Exec(ctx, query, id, passwordHash)
+	if err != nil {
+		return fmt.Errorf("failed to update user password: %w", err)
+	}
+	
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user not found")
+	}
+	
+	return nil
+}
+
+// RecordLogin stores the time and client IP of the user's latest successful
+// login. It leaves updated_at alone, as a login doesn't change the profile.
+func (r *userRepository) RecordLogin(ctx context.Context, id uuid.UUID, at time.Time, ip string) error {
+	query := `UPDATE users SET last_login_at = $2, last_login_ip = NULLIF($3, '') WHERE id = $1`
+	
+	if _, err := r.db.Writer().Exec(ctx, query, id, at, ip); err != nil {
+		return fmt.Errorf("failed to record login: %w", err)
+	}
+	
+	return nil
+}
+
 // Delete deletes a user by ID
 func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM users WHERE id = $1`
 	
-	result, err := r.db.Pool.Exec(ctx, query, id)
+	result, err := r.db.Writer().Exec(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
@@ -175,25 +331,99 @@ func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 // List retrieves a list of users with pagination
 func (r *userRepository) List(ctx context.Context, limit, offset int) ([]*model.User, error) {
+	limit, err := checkPage(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
-		SELECT id, email, name, password_hash, avatar, created_at, updated_at
+		SELECT id, email, name, password_hash, avatar, created_at, updated_at,
+		       last_login_at, last_login_ip, role
 		FROM users 
-		ORDER BY created_at DESC
+		ORDER BY created_at DESC, id DESC
 		LIMIT $1 OFFSET $2
 	`
 	
-	rows, err := r.db.Pool.Query(ctx, query, limit, offset)
+	rows, err := r.db.Reader().Query(ctx, query, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
 	defer rows.Close()
 	
-	var users []*model.User
+	return r.scanUsers(rows)
+}
+
+// SearchUsers finds users whose name contains query, case-insensitively.
+// Email addresses are private: only with includeEmail are they matched
+// against and returned; otherwise each result's Email is empty. Password
+// hashes, roles and login details are never selected. Names starting with the
+// query come first.
+func (r *userRepository) SearchUsers(ctx context.Context, query string, includeEmail bool, limit int) ([]*model.User, error) {
+	limit, err := checkLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	searchQuery := `
+		SELECT id, '', name, '', avatar, created_at, updated_at, NULL, NULL, ''
+		FROM users
+		WHERE name ILIKE $1 ESCAPE '\'
+		ORDER BY name ILIKE $2 ESCAPE '\' DESC, name, id
+		LIMIT $3
+	`
+	if includeEmail {
+		searchQuery = `
+			SELECT id, email, name, '', avatar, created_at, updated_at, NULL, NULL, ''
+			FROM users
+			WHERE name ILIKE $1 ESCAPE '\' OR email ILIKE $1 ESCAPE '\'
+			ORDER BY name ILIKE $2 ESCAPE '\' DESC, name, id
+			LIMIT $3
+		`
+	}
+
+	prefix := likeEscaper.Replace(query) + "%"
+	rows, err := r.db.Reader().Query(ctx, searchQuery, containsPattern(query), prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanUsers(rows)
+}
+
+// Count returns the number of users
+func (r *userRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.Reader().QueryRow(ctx, `SELECT COUNT(*) FROM users`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	
+	return count, nil
+}
+
+// CountCreatedSince returns the number of users who signed up at or after since
+func (r *userRepository) CountCreatedSince(ctx context.Context, since time.Time) (int, error) {
+	var count int
+	err := r.db.Reader().QueryRow(ctx, `SELECT COUNT(*) FROM users WHERE created_at >= $1`, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count new users: %w", err)
+	}
+	
+	return count, nil
+}
+
+// scanUsers is a helper function to scan user rows. It returns an empty
+// slice rather than nil when there are no rows.
+func (r *userRepository) scanUsers(rows pgx.Rows) ([]*model.User, error) {
+	users := []*model.User{}
+	
 	for rows.Next() {
 		var user model.User
 		err := rows.Scan(
 			&user.ID, &user.Email, &user.Name, &user.PasswordHash,
 			&user.Avatar, &user.CreatedAt, &user.UpdatedAt,
+			&user.LastLoginAt, &user.LastLoginIP, &user.Role,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
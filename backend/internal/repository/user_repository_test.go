@@ -2,13 +2,18 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"backend/internal/database"
 	"backend/internal/graph/model"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockDB is a mock database for testing
@@ -39,11 +44,31 @@ func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*mod
 	return args.Get(0).(*model.User), args.Error(1)
 }
 
+func (m *MockUserRepository) ExistsByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).(map[uuid.UUID]bool), args.Error(1)
+}
+
+func (m *MockUserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	args := m.Called(ctx, email)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) ExistsByEmails(ctx context.Context, emails []string) (map[string]bool, error) {
+	args := m.Called(ctx, emails)
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
 func (m *MockUserRepository) Update(ctx context.Context, user *model.User) error {
 	args := m.Called(ctx, user)
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) UpdateEmail(ctx context.Context, id uuid.UUID, email string) error {
+	args := m.Called(ctx, id, email)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -173,4 +198,281 @@ func TestUserRepository_List(t *testing.T) {
 	assert.Equal(t, expectedUsers, users)
 	assert.Len(t, users, 2)
 	mockRepo.AssertExpectations(t)
-}
\ No newline at end of file
+}
+
+// existenceQuerier answers IN and EXISTS queries from a fixed set of stored values
+type existenceQuerier struct {
+	recordingQuerier
+	stored map[any]bool
+	sql    []string
+}
+
+func (q *existenceQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	q.queries++
+	q.sql = append(q.sql, sql)
+	var found []any
+	for _, arg := range args {
+		if q.stored[arg] {
+			found = append(found, arg)
+		}
+	}
+	return &sliceRows{values: found, pos: -1}, nil
+}
+
+func (q *existenceQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	q.queries++
+	q.sql = append(q.sql, sql)
+	if strings.Contains(sql, "SELECT EXISTS") {
+		return boolRow(q.stored[args[0]])
+	}
+	return stubRow{}
+}
+
+// boolRow is a pgx.Row holding a single boolean column
+type boolRow bool
+
+func (r boolRow) Scan(dest ...any) error {
+	*dest[0].(*bool) = bool(r)
+	return nil
+}
+
+// sliceRows is a single-column pgx.Rows over in-memory values
+type sliceRows struct {
+	pgx.Rows
+	values []any
+	pos    int
+}
+
+func (r *sliceRows) Next() bool { r.pos++; return r.pos < len(r.values) }
+func (r *sliceRows) Err() error { return nil }
+func (r *sliceRows) Close()     {}
+
+func (r *sliceRows) Scan(dest ...any) error {
+	switch d := dest[0].(type) {
+	case *uuid.UUID:
+		*d = r.values[r.pos].(uuid.UUID)
+	case *string:
+		*d = r.values[r.pos].(string)
+	default:
+		return fmt.Errorf("unsupported scan destination %T", dest[0])
+	}
+	return nil
+}
+
+func TestUserRepository_ExistsByIDs(t *testing.T) {
+	existing1, existing2, missing := uuid.New(), uuid.New(), uuid.New()
+	querier := &existenceQuerier{stored: map[any]bool{existing1: true, existing2: true}}
+	repo := NewUserRepository(database.NewDBWithQueriers(querier, querier))
+
+	exists, err := repo.ExistsByIDs(context.Background(), []uuid.UUID{existing1, missing, existing2})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]bool{existing1: true, existing2: true, missing: false}, exists)
+	require.Len(t, querier.sql, 1)
+	assert.Contains(t, querier.sql[0], "IN ($1,$2,$3)")
+}
+
+func TestUserRepository_ExistsByEmails(t *testing.T) {
+	querier := &existenceQuerier{stored: map[any]bool{"alice@example.com": true}}
+	repo := NewUserRepository(database.NewDBWithQueriers(querier, querier))
+
+	exists, err := repo.ExistsByEmails(context.Background(), []string{"alice@example.com", "nobody@example.com"})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"alice@example.com": true, "nobody@example.com": false}, exists)
+	assert.Equal(t, 1, querier.queries)
+	assert.Contains(t, querier.sql[0], "IN ($1,$2)")
+}
+
+func TestUserRepository_ExistsByEmails_IgnoresCase(t *testing.T) {
+	querier := &existenceQuerier{stored: map[any]bool{"alice@example.com": true}}
+	repo := NewUserRepository(database.NewDBWithQueriers(querier, querier))
+
+	exists, err := repo.ExistsByEmails(context.Background(), []string{"Alice@Example.com"})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"Alice@Example.com": true}, exists)
+	assert.Contains(t, querier.sql[0], "lower(email)")
+}
+
+func TestUserRepository_ExistsByEmail(t *testing.T) {
+	querier := &existenceQuerier{stored: map[any]bool{"alice@example.com": true}}
+	repo := NewUserRepository(database.NewDBWithQueriers(querier, querier))
+	ctx := context.Background()
+
+	present, err := repo.ExistsByEmail(ctx, "alice@example.com")
+	require.NoError(t, err)
+	assert.True(t, present)
+
+	absent, err := repo.ExistsByEmail(ctx, "nobody@example.com")
+	require.NoError(t, err)
+	assert.False(t, absent)
+
+	require.Len(t, querier.sql, 2)
+	assert.Contains(t, querier.sql[0], "SELECT EXISTS")
+	assert.Contains(t, querier.sql[0], "lower(email) = lower($1)")
+	assert.NotContains(t, querier.sql[0], "password_hash")
+}
+
+func TestUserRepository_ExistsByEmail_ReadsPrimary(t *testing.T) {
+	primary := &existenceQuerier{}
+	replica := &existenceQuerier{}
+	repo := NewUserRepository(database.NewDBWithQueriers(primary, replica))
+
+	_, err := repo.ExistsByEmail(context.Background(), "alice@example.com")
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, primary.queries)
+	assert.Zero(t, replica.queries)
+}
+
+func TestUserRepository_ExistsByIDs_EmptySkipsQuery(t *testing.T) {
+	querier := &existenceQuerier{}
+	repo := NewUserRepository(database.NewDBWithQueriers(querier, querier))
+
+	exists, err := repo.ExistsByIDs(context.Background(), nil)
+
+	require.NoError(t, err)
+	assert.Empty(t, exists)
+	assert.Zero(t, querier.queries)
+}
+
+func TestUserRepository_NoRowsReturnEmptySlices(t *testing.T) {
+	querier := &existenceQuerier{}
+	repo := NewUserRepository(database.NewDBWithQueriers(querier, querier))
+	ctx := context.Background()
+
+	byIDs, err := repo.GetByIDs(ctx, []uuid.UUID{uuid.New()})
+	require.NoError(t, err)
+	assert.NotNil(t, byIDs)
+	assert.Empty(t, byIDs)
+
+	listed, err := repo.List(ctx, 10, 0)
+	require.NoError(t, err)
+	assert.NotNil(t, listed)
+	assert.Empty(t, listed)
+}
+
+// userSearchQuerier answers user searches from in-memory users. Like the
+// database it matches the pattern against email only when the query does,
+// and returns only the columns the query selects.
+type userSearchQuerier struct {
+	recordingQuerier
+	users []*model.User
+	sql   []string
+	args  [][]any
+}
+
+func (q *userSearchQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	q.queries++
+	q.sql = append(q.sql, sql)
+	q.args = append(q.args, args)
+
+	pattern := ilikeRegexp(args[0].(string))
+	matchEmail := strings.Contains(sql, "email ILIKE")
+	selectsEmail := strings.Contains(sql, "SELECT id, email")
+
+	rows := [][]any{}
+	for _, user := range q.users {
+		if !pattern.MatchString(user.Name) && !(matchEmail && pattern.MatchString(user.Email)) {
+			continue
+		}
+		email := ""
+		if selectsEmail {
+			email = user.Email
+		}
+		rows = append(rows, []any{
+			user.ID, email, user.Name, "", user.Avatar, user.CreatedAt, user.UpdatedAt,
+			(*time.Time)(nil), (*string)(nil),
+		})
+	}
+	return &tableRows{rows: rows, pos: -1}, nil
+}
+
+func searchableUsers() []*model.User {
+	lastLogin := time.Now()
+	ip := "10.0.0.1"
+	return []*model.User{
+		{ID: uuid.New(), Email: "ada@example.com", Name: "Ada Lovelace", PasswordHash: "hash", LastLoginAt: &lastLogin, LastLoginIP: &ip},
+		{ID: uuid.New(), Email: "grace@navy.mil", Name: "Grace Hopper", PasswordHash: "hash"},
+		{ID: uuid.New(), Email: "alan@example.com", Name: "Alan Turing", PasswordHash: "hash"},
+	}
+}
+
+func TestUserRepository_SearchUsersMatchesName(t *testing.T) {
+	querier := &userSearchQuerier{users: searchableUsers()}
+	repo := NewUserRepository(database.NewDBWithQueriers(&recordingQuerier{}, querier))
+
+	users, err := repo.SearchUsers(context.Background(), "hop", false, 5)
+
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "Grace Hopper", users[0].Name)
+	assert.Equal(t, []any{"%hop%", "hop%", 5}, querier.args[0])
+}
+
+func TestUserRepository_SearchUsersKeepsEmailPrivate(t *testing.T) {
+	querier := &userSearchQuerier{users: searchableUsers()}
+	repo := NewUserRepository(database.NewDBWithQueriers(querier, querier))
+	ctx := context.Background()
+
+	byEmail, err := repo.SearchUsers(ctx, "navy.mil", false, 5)
+	require.NoError(t, err)
+	assert.Empty(t, byEmail, "email matched without includeEmail")
+
+	byName, err := repo.SearchUsers(ctx, "ada", false, 5)
+	require.NoError(t, err)
+	require.Len(t, byName, 1)
+	assert.Empty(t, byName[0].Email)
+	assert.Empty(t, byName[0].PasswordHash)
+	assert.Nil(t, byName[0].LastLoginAt)
+	assert.NotContains(t, querier.sql[0], "email ILIKE")
+	assert.NotContains(t, querier.sql[0], "password_hash")
+}
+
+func TestUserRepository_SearchUsersMatchesEmailForAdmins(t *testing.T) {
+	querier := &userSearchQuerier{users: searchableUsers()}
+	repo := NewUserRepository(database.NewDBWithQueriers(querier, querier))
+
+	users, err := repo.SearchUsers(context.Background(), "navy.mil", true, 5)
+
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "grace@navy.mil", users[0].Email)
+	assert.Empty(t, users[0].PasswordHash)
+	assert.Contains(t, querier.sql[0], `email ILIKE $1 ESCAPE '\'`)
+}
+
+func TestUserRepository_SearchUsersTreatsWildcardsLiterally(t *testing.T) {
+	querier := &userSearchQuerier{users: searchableUsers()}
+	repo := NewUserRepository(database.NewDBWithQueriers(querier, querier))
+
+	users, err := repo.SearchUsers(context.Background(), "a_a", false, 5)
+
+	require.NoError(t, err)
+	assert.Empty(t, users)
+	assert.Equal(t, `%a\_a%`, querier.args[0][0])
+}
+
+func TestUserRepository_UpdatePasswordWritesHash(t *testing.T) {
+	primary := &execQuerier{rowsAffected: "UPDATE 1"}
+	replica := &recordingQuerier{}
+	repo := NewUserRepository(database.NewDBWithQueriers(primary, replica))
+	userID := uuid.New()
+
+	require.NoError(t, repo.UpdatePassword(context.Background(), userID, "new-hash"))
+
+	require.Len(t, primary.sql, 1)
+	assert.Contains(t, primary.sql[0], "password_hash = $2")
+	assert.Equal(t, []any{userID, "new-hash"}, primary.args[0])
+	assert.Zero(t, replica.execs+replica.queries)
+}
+
+func TestUserRepository_UpdatePasswordUnknownUser(t *testing.T) {
+	querier := &execQuerier{rowsAffected: "UPDATE 0"}
+	repo := NewUserRepository(database.NewDBWithQueriers(querier, querier))
+
+	err := repo.UpdatePassword(context.Background(), uuid.New(), "new-hash")
+
+	assert.EqualError(t, err, "user not found")
+}
@@ -0,0 +1,124 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// DefaultAnonymousMutations are the mutations callers may run without signing
+// in: the sign-in flows, including refreshing an expired session, and the ones
+// authenticated by a refresh token, an emailed token or an address rather
+// than a session
+var DefaultAnonymousMutations = []string{
+	"login",
+	"register",
+	"refreshToken",
+	"resendVerificationEmail",
+	"verifyEmail",
+	"confirmEmailChange",
+	"setPassword",
+	"logout",
+}
+
+// AnonymousMutationsFromEnv returns the comma-separated mutation names in
+// ANONYMOUS_MUTATIONS, or DefaultAnonymousMutations when it is unset
+func AnonymousMutationsFromEnv() []string {
+	value, ok := os.LookupEnv("ANONYMOUS_MUTATIONS")
+	if !ok {
+		return DefaultAnonymousMutations
+	}
+
+	names := []string{}
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// AnonymousMutationGuard denies mutations to anonymous callers unless every
+// mutation field they select is on its allowlist. New mutations are
+// therefore protected without being registered anywhere.
+type AnonymousMutationGuard struct {
+	allowed       map[string]bool
+	authenticated func(ctx context.Context) bool
+}
+
+// NewAnonymousMutationGuard creates a guard allowing the named mutations to
+// anonymous callers. authenticated reports whether a request is signed in;
+// a security.User in context counts when it is nil.
+func NewAnonymousMutationGuard(authenticated func(ctx context.Context) bool, allowed []string) *AnonymousMutationGuard {
+	if authenticated == nil {
+		authenticated = func(ctx context.Context) bool { return GetUserFromContext(ctx) != nil }
+	}
+
+	names := make(map[string]bool, len(allowed))
+	for _, name := range allowed {
+		names[name] = true
+	}
+	return &AnonymousMutationGuard{allowed: names, authenticated: authenticated}
+}
+
+// ExtensionName returns the name of this extension
+func (g *AnonymousMutationGuard) ExtensionName() string {
+	return "AnonymousMutationGuard"
+}
+
+// Validate validates the schema (no-op for this extension)
+func (g *AnonymousMutationGuard) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// MutateOperationContext rejects an anonymous mutation before any of its
+// fields run when it selects a mutation that is not allowed
+func (g *AnonymousMutationGuard) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	if rc.Operation == nil || rc.Operation.Operation != ast.Mutation || g.authenticated(ctx) {
+		return nil
+	}
+
+	if name, ok := g.firstDenied(rc.Doc, rc.Operation.SelectionSet); ok {
+		return &gqlerror.Error{
+			Message: fmt.Sprintf("Authentication required to run %s", name),
+			Extensions: map[string]interface{}{
+				"code": "UNAUTHENTICATED",
+			},
+		}
+	}
+	return nil
+}
+
+// firstDenied returns the first selected mutation field not on the allowlist,
+// looking through fragments. A fragment that can't be resolved is denied.
+func (g *AnonymousMutationGuard) firstDenied(doc *ast.QueryDocument, selectionSet ast.SelectionSet) (string, bool) {
+	for _, selection := range selectionSet {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			if sel.Name != "__typename" && !g.allowed[sel.Name] {
+				return sel.Name, true
+			}
+		case *ast.InlineFragment:
+			if name, ok := g.firstDenied(doc, sel.SelectionSet); ok {
+				return name, true
+			}
+		case *ast.FragmentSpread:
+			definition := sel.Definition
+			if definition == nil && doc != nil {
+				definition = doc.Fragments.ForName(sel.Name)
+			}
+			if definition == nil {
+				return "..." + sel.Name, true
+			}
+			if name, ok := g.firstDenied(doc, definition.SelectionSet); ok {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
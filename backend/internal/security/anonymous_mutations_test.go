@@ -0,0 +1,62 @@
+package security
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnonymousMutationGuard(t *testing.T) {
+	guard := NewAnonymousMutationGuard(nil, DefaultAnonymousMutations)
+
+	t.Run("anonymous callers may run allowed mutations", func(t *testing.T) {
+		for _, query := range []string{
+			`mutation { login(email: "a@example.com", password: "secret123") { token } }`,
+			`mutation { signIn: register(email: "a@example.com", password: "secret123", name: "Ann") { token } __typename }`,
+			`mutation { ... on Mutation { refreshToken(refreshToken: "token") { token } } }`,
+		} {
+			assert.Nil(t, guard.MutateOperationContext(context.Background(), operationContext(t, query)), query)
+		}
+	})
+
+	t.Run("anonymous callers are denied everything else", func(t *testing.T) {
+		for query, denied := range map[string]string{
+			`mutation { deletePost(id: "1") }`:                                                       "deletePost",
+			`mutation { someMutationAddedLater }`:                                                    "someMutationAddedLater",
+			`mutation { login(email: "a", password: "b") { token } clearCache(scope: ALL) }`:         "clearCache",
+			`mutation { ... on Mutation { rotateJWTKey } }`:                                          "rotateJWTKey",
+			`mutation { ...Sneaky } fragment Sneaky on Mutation { mergeTags(from: "a", into: "b") }`: "mergeTags",
+		} {
+			gqlErr := guard.MutateOperationContext(context.Background(), operationContext(t, query))
+
+			require.NotNil(t, gqlErr, query)
+			assert.Equal(t, "UNAUTHENTICATED", gqlErr.Extensions["code"])
+			assert.Equal(t, "Authentication required to run "+denied, gqlErr.Message)
+		}
+	})
+
+	t.Run("signed-in callers and queries are not checked", func(t *testing.T) {
+		ctx := WithUser(context.Background(), &User{ID: "1", Role: RoleUser, IsActive: true})
+
+		assert.Nil(t, guard.MutateOperationContext(ctx, operationContext(t, `mutation { deletePost(id: "1") }`)))
+		assert.Nil(t, guard.MutateOperationContext(context.Background(), operationContext(t, `{ posts { totalCount } }`)))
+	})
+
+	t.Run("authentication check is configurable", func(t *testing.T) {
+		signedIn := NewAnonymousMutationGuard(func(ctx context.Context) bool { return true }, nil)
+
+		assert.Nil(t, signedIn.MutateOperationContext(context.Background(), operationContext(t, `mutation { deletePost(id: "1") }`)))
+	})
+}
+
+func TestAnonymousMutationsFromEnv(t *testing.T) {
+	assert.Equal(t, DefaultAnonymousMutations, AnonymousMutationsFromEnv())
+
+	t.Setenv("ANONYMOUS_MUTATIONS", " login, register ,,")
+	assert.Equal(t, []string{"login", "register"}, AnonymousMutationsFromEnv())
+
+	t.Setenv("ANONYMOUS_MUTATIONS", "")
+	assert.Empty(t, AnonymousMutationsFromEnv())
+}
@@ -3,9 +3,13 @@ package security
 import (
 	"context"
 	"fmt"
-	"strings"
+	"slices"
+	"time"
 
+	"backend/internal/graph/model"
+	"backend/internal/ids"
 	"github.com/99designs/gqlgen/graphql"
+	"github.com/google/uuid"
 )
 
 // Role represents user roles in the system
@@ -172,6 +176,23 @@ func NewAuthorizationMiddleware() *AuthorizationMiddleware {
 	}
 }
 
+// RolePermissions returns the permissions granted to role, or nil for an unknown role
+func (a *AuthorizationMiddleware) RolePermissions(role Role) []Permission {
+	return slices.Clone(a.rolePermissions[role])
+}
+
+// EffectivePermissions returns the permissions of user's role followed by any
+// permissions granted to the user directly, without duplicates
+func (a *AuthorizationMiddleware) EffectivePermissions(user *User) []Permission {
+	permissions := a.RolePermissions(user.Role)
+	for _, perm := range user.Permissions {
+		if !slices.Contains(permissions, Permission(perm)) {
+			permissions = append(permissions, Permission(perm))
+		}
+	}
+	return permissions
+}
+
 // ExtensionName returns the name of this extension
 func (a *AuthorizationMiddleware) ExtensionName() string {
 	return "AuthorizationMiddleware"
@@ -277,6 +298,18 @@ func WithUser(ctx context.Context, user *User) context.Context {
 	return context.WithValue(ctx, "user", user)
 }
 
+// WithImpersonator records the admin impersonating the request's user
+func WithImpersonator(ctx context.Context, adminID string) context.Context {
+	return context.WithValue(ctx, "impersonator_id", adminID)
+}
+
+// ImpersonatorFromContext returns the admin impersonating the request's
+// user, or "" when the request is not impersonated
+func ImpersonatorFromContext(ctx context.Context) string {
+	adminID, _ := ctx.Value("impersonator_id").(string)
+	return adminID
+}
+
 // RequireAuth is a helper function for resolvers to require authentication
 func RequireAuth(ctx context.Context) (*User, error) {
 	user := GetUserFromContext(ctx)
@@ -316,32 +349,25 @@ func RequirePermission(ctx context.Context, permission Permission) (*User, error
 	return user, nil
 }
 
-// RequireOwnership is a helper function to require resource ownership
-func RequireOwnership(ctx context.Context, resourceType, resourceID string) (*User, error) {
+// CanModify reports whether user may change a resource owned by ownerID.
+// Owners always may; admins and moderators may change any resource.
+func CanModify(user *User, ownerID string) bool {
+	if user == nil {
+		return false
+	}
+	return user.ID == ownerID || user.HasRole(RoleModerator)
+}
+
+// RequireOwnership is a helper function to require resource ownership.
+// Callers load the resource and pass its owner's ID.
+func RequireOwnership(ctx context.Context, resourceType, ownerID string) (*User, error) {
 	user, err := RequireAuth(ctx)
 	if err != nil {
 		return nil, err
 	}
 	
-	// Admin can access everything
-	if user.HasRole(RoleAdmin) {
-		return user, nil
-	}
-	
-	// Check ownership based on resource type
-	switch resourceType {
-	case "post":
-		// This would typically involve a database lookup
-		// For now, we'll assume the resourceID format includes owner info
-		if !strings.HasPrefix(resourceID, user.ID+":") {
-			return nil, fmt.Errorf("access denied: not the owner of this %s", resourceType)
-		}
-	case "user":
-		if resourceID != user.ID {
-			return nil, fmt.Errorf("access denied: can only access own user data")
-		}
-	default:
-		return nil, fmt.Errorf("unknown resource type: %s", resourceType)
+	if !CanModify(user, ownerID) {
+		return nil, fmt.Errorf("access denied: not the owner of this %s", resourceType)
 	}
 	
 	return user, nil
@@ -361,24 +387,51 @@ type AuditLog struct {
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// AuditStore persists audit entries
+type AuditStore interface {
+	Create(ctx context.Context, entry *model.AuditLog) error
+}
+
+// auditWriteTimeout bounds the write of one audit entry
+const auditWriteTimeout = 5 * time.Second
+
 // AuditLogger logs security-related events
 type AuditLogger struct {
-	// In a real implementation, this would write to a database or log service
+	// sink receives each entry, or else store persists it; entries are
+	// printed when neither is set or the store fails
+	sink  func(AuditLog)
+	store AuditStore
 }
 
-// NewAuditLogger creates a new audit logger
+// NewAuditLogger creates a new audit logger that prints entries
 func NewAuditLogger() *AuditLogger {
 	return &AuditLogger{}
 }
 
+// NewAuditLoggerWithSink creates an audit logger that hands entries to sink
+func NewAuditLoggerWithSink(sink func(AuditLog)) *AuditLogger {
+	return &AuditLogger{sink: sink}
+}
+
+// NewAuditLoggerWithStore creates an audit logger that writes entries to store
+func NewAuditLoggerWithStore(store AuditStore) *AuditLogger {
+	return &AuditLogger{store: store}
+}
+
 // LogAccess logs access attempts
 func (a *AuditLogger) LogAccess(ctx context.Context, user *User, action, resource, resourceID string, success bool, err error) {
+	a.LogAccessWithMetadata(ctx, user, action, resource, resourceID, success, err, nil)
+}
+
+// LogAccessWithMetadata logs access attempts with extra context such as a moderation reason
+func (a *AuditLogger) LogAccessWithMetadata(ctx context.Context, user *User, action, resource, resourceID string, success bool, err error, metadata map[string]interface{}) {
 	log := AuditLog{
 		Action:     action,
 		Resource:   resource,
 		ResourceID: resourceID,
 		Timestamp:  time.Now().Unix(),
 		Success:    success,
+		Metadata:   metadata,
 	}
 	
 	if user != nil {
@@ -389,14 +442,55 @@ func (a *AuditLogger) LogAccess(ctx context.Context, user *User, action, resourc
 		log.Error = err.Error()
 	}
 	
+	// Entries made under impersonation also name the admin behind them
+	if impersonator := ImpersonatorFromContext(ctx); impersonator != "" {
+		log.Metadata = make(map[string]interface{}, len(metadata)+1)
+		for key, value := range metadata {
+			log.Metadata[key] = value
+		}
+		log.Metadata["impersonator_id"] = impersonator
+	}
+	
 	// Extract IP and User-Agent from context
-	if ip, ok := ctx.Value("client_ip").(string); ok {
-		log.IPAddress = ip
+	log.IPAddress = ClientIPFromContext(ctx)
+	log.UserAgent = UserAgentFromContext(ctx)
+	
+	if a.sink != nil {
+		a.sink(log)
+		return
 	}
-	if ua, ok := ctx.Value("user_agent").(string); ok {
-		log.UserAgent = ua
+	
+	if a.store != nil {
+		err := a.persist(ctx, log)
+		if err == nil {
+			return
+		}
+		fmt.Printf("AUDIT: failed to store entry: %v\n", err)
 	}
 	
-	// In a real implementation, this would be written to a persistent store
 	fmt.Printf("AUDIT: %+v\n", log)
+}
+
+// persist writes entry to the store. The write is not cut short by the
+// request ending, since refused requests are often abandoned at once.
+func (a *AuditLogger) persist(ctx context.Context, entry AuditLog) error {
+	record := &model.AuditLog{
+		ID:         ids.New(),
+		Action:     entry.Action,
+		Resource:   entry.Resource,
+		ResourceID: entry.ResourceID,
+		IPAddress:  entry.IPAddress,
+		UserAgent:  entry.UserAgent,
+		Success:    entry.Success,
+		Error:      entry.Error,
+		Metadata:   entry.Metadata,
+		CreatedAt:  time.Unix(entry.Timestamp, 0).UTC(),
+	}
+	if userID, err := uuid.Parse(entry.UserID); err == nil {
+		record.UserID = &userID
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditWriteTimeout)
+	defer cancel()
+	return a.store.Create(ctx, record)
 }
\ No newline at end of file
@@ -0,0 +1,74 @@
+package security
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorizationMiddleware_RolePermissions(t *testing.T) {
+	authz := NewAuthorizationMiddleware()
+
+	tests := []struct {
+		role     Role
+		expected []Permission
+	}{
+		{
+			role: RoleAdmin,
+			expected: []Permission{
+				PermissionReadPost, PermissionWritePost, PermissionDeletePost,
+				PermissionReadUser, PermissionWriteUser, PermissionDeleteUser,
+				PermissionReadComment, PermissionWriteComment, PermissionDeleteComment,
+				PermissionModerate, PermissionAdmin,
+			},
+		},
+		{
+			role: RoleModerator,
+			expected: []Permission{
+				PermissionReadPost, PermissionWritePost, PermissionDeletePost,
+				PermissionReadUser, PermissionReadComment, PermissionWriteComment,
+				PermissionDeleteComment, PermissionModerate,
+			},
+		},
+		{
+			role: RoleUser,
+			expected: []Permission{
+				PermissionReadPost, PermissionWritePost,
+				PermissionReadUser, PermissionReadComment, PermissionWriteComment,
+			},
+		},
+		{
+			role:     RoleGuest,
+			expected: []Permission{PermissionReadPost, PermissionReadComment},
+		},
+		{
+			role:     Role("unknown"),
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			assert.Equal(t, tt.expected, authz.RolePermissions(tt.role))
+		})
+	}
+}
+
+func TestAuthorizationMiddleware_RolePermissionsReturnsCopy(t *testing.T) {
+	authz := NewAuthorizationMiddleware()
+
+	permissions := authz.RolePermissions(RoleGuest)
+	permissions[0] = PermissionAdmin
+
+	assert.Equal(t, PermissionReadPost, authz.RolePermissions(RoleGuest)[0])
+}
+
+func TestAuthorizationMiddleware_EffectivePermissions(t *testing.T) {
+	authz := NewAuthorizationMiddleware()
+	user := &User{
+		Role:        RoleGuest,
+		Permissions: []string{string(PermissionReadPost), string(PermissionModerate)},
+	}
+
+	assert.Equal(t, []Permission{PermissionReadPost, PermissionReadComment, PermissionModerate}, authz.EffectivePermissions(user))
+}
@@ -0,0 +1,168 @@
+package security
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"backend/internal/complexity"
+	"backend/internal/envconfig"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// BatchConfig bounds batched GraphQL requests, which POST a JSON array of operations
+type BatchConfig struct {
+	// Maximum operations in one batch; 0 rejects batched requests
+	MaxBatchSize int
+
+	// Maximum size of the request body in bytes; 0 reads bodies of any size
+	MaxBodyBytes int
+}
+
+// DefaultBatchConfig returns the default batch limits
+func DefaultBatchConfig() BatchConfig {
+	return BatchConfig{
+		MaxBatchSize: 10,
+		MaxBodyBytes: DefaultRequestSizeConfig().MaxBodyBytes,
+	}
+}
+
+// BatchConfigFromEnv returns the default limits overridden by
+// GRAPHQL_MAX_BATCH_SIZE and GRAPHQL_MAX_BODY_BYTES
+func BatchConfigFromEnv() BatchConfig {
+	config := DefaultBatchConfig()
+	config.MaxBatchSize = envconfig.NonNegativeInt("GRAPHQL_MAX_BATCH_SIZE", config.MaxBatchSize)
+	config.MaxBodyBytes = envconfig.NonNegativeInt("GRAPHQL_MAX_BODY_BYTES", config.MaxBodyBytes)
+	return config
+}
+
+// BatchHandler serves POSTed arrays of operations by running each through
+// next in turn and answering with the array of their responses. Batches over
+// the size limit are rejected before any operation runs. The operations share
+// one context marked with complexity.WithBatch, so the complexity limit applies
+// to the batch as a whole; per-operation extensions such as rate limits and
+// query budgets are charged once per operation, and the headers each operation
+// sets, such as cookies and rate limit headers, are copied to the batch
+// response. Bodies are read up to MaxBodyBytes. Other requests pass through.
+func BatchHandler(next http.Handler, config BatchConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Body == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if config.MaxBodyBytes > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, int64(config.MaxBodyBytes))
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				writeRequestError(w, http.StatusRequestEntityTooLarge, requestTooLargeError(
+					fmt.Sprintf("Request body exceeds maximum allowed size %d bytes", maxBytesErr.Limit),
+					"body", int(maxBytesErr.Limit), len(body),
+				))
+				return
+			}
+			writeRequestError(w, http.StatusBadRequest, gqlerror.Errorf("could not read request body: %v", err))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		trimmed := bytes.TrimSpace(body)
+		if len(trimmed) == 0 || trimmed[0] != '[' {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var operations []json.RawMessage
+		if err := json.Unmarshal(trimmed, &operations); err != nil {
+			writeRequestError(w, http.StatusBadRequest, gqlerror.Errorf("batched request body could not be decoded: %v", err))
+			return
+		}
+		if len(operations) == 0 {
+			writeRequestError(w, http.StatusBadRequest, gqlerror.Errorf("batched request contains no operations"))
+			return
+		}
+		if len(operations) > config.MaxBatchSize {
+			writeRequestError(w, http.StatusRequestEntityTooLarge, batchTooLargeError(len(operations), config.MaxBatchSize))
+			return
+		}
+
+		ctx := complexity.WithBatch(r.Context())
+		responses := make([]json.RawMessage, len(operations))
+		for i, operation := range operations {
+			sub := r.Clone(ctx)
+			sub.Body = io.NopCloser(bytes.NewReader(operation))
+			sub.ContentLength = int64(len(operation))
+
+			rec := &batchResponseWriter{header: make(http.Header)}
+			next.ServeHTTP(rec, sub)
+			copyOperationHeaders(w.Header(), rec.header)
+			if rec.body.Len() == 0 {
+				responses[i], _ = json.Marshal(&graphql.Response{Errors: gqlerror.List{gqlerror.Errorf("operation produced no response")}})
+				continue
+			}
+			responses[i] = rec.body.Bytes()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(responses)
+	})
+}
+
+// batchTooLargeError reports a batch over the size limit
+func batchTooLargeError(size, limit int) *gqlerror.Error {
+	message := fmt.Sprintf("Batch of %d operations exceeds maximum allowed batch size %d", size, limit)
+	if limit == 0 {
+		message = "Batched requests are disabled"
+	}
+	return &gqlerror.Error{
+		Message: message,
+		Extensions: map[string]interface{}{
+			"code":   "BATCH_TOO_LARGE",
+			"limit":  limit,
+			"actual": size,
+		},
+	}
+}
+
+// writeRequestError answers a whole request or batch with a single GraphQL error
+func writeRequestError(w http.ResponseWriter, status int, err *gqlerror.Error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(&graphql.Response{Errors: gqlerror.List{err}})
+}
+
+// copyOperationHeaders copies the headers one operation of a batch set onto
+// the batch response. Cookies from every operation are kept; for other headers
+// the last operation to set them wins. The content headers describe only the
+// operation's own body, so they are skipped.
+func copyOperationHeaders(dst, src http.Header) {
+	for key, values := range src {
+		switch key {
+		case "Content-Type", "Content-Length":
+			continue
+		case "Set-Cookie":
+			for _, value := range values {
+				dst.Add(key, value)
+			}
+		default:
+			dst[key] = values
+		}
+	}
+}
+
+// batchResponseWriter captures the response to one operation of a batch
+type batchResponseWriter struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (b *batchResponseWriter) Header() http.Header         { return b.header }
+func (b *batchResponseWriter) Write(p []byte) (int, error) { return b.body.Write(p) }
+func (b *batchResponseWriter) WriteHeader(statusCode int)  {}
@@ -0,0 +1,179 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend/internal/complexity"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// newBatchServer serves `{ hello }` behind the batch handler with the given
+// complexity limit, counting executed operations
+func newBatchServer(maxBatchSize, maxComplexity int) (http.Handler, *int) {
+	schema := gqlparser.MustLoadSchema(&ast.Source{Input: `type Query { hello: String }`})
+	executed := 0
+
+	srv := handler.New(&graphql.ExecutableSchemaMock{
+		SchemaFunc: func() *ast.Schema { return schema },
+		ComplexityFunc: func(ctx context.Context, typeName, fieldName string, childComplexity int, args map[string]any) (int, bool) {
+			return 1, true
+		},
+		ExecFunc: func(ctx context.Context) graphql.ResponseHandler {
+			executed++
+			return graphql.OneShot(&graphql.Response{Data: []byte(`{"hello":"world"}`)})
+		},
+	})
+	srv.AddTransport(transport.POST{})
+
+	config := complexity.DefaultConfig()
+	config.MaxComplexity = maxComplexity
+	srv.Use(complexity.NewAnalyzer(config).ComplexityMiddleware())
+
+	return BatchHandler(srv, BatchConfig{MaxBatchSize: maxBatchSize}), &executed
+}
+
+func postBatch(t *testing.T, h http.Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// helloBatch returns a batch of n `{ hello }` operations
+func helloBatch(n int) string {
+	operations := make([]string, n)
+	for i := range operations {
+		operations[i] = `{"query":"{ hello }"}`
+	}
+	return "[" + strings.Join(operations, ",") + "]"
+}
+
+// batchResponses decodes the array of responses to a batch
+func batchResponses(t *testing.T, rec *httptest.ResponseRecorder) []graphql.Response {
+	t.Helper()
+	var responses []graphql.Response
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responses), rec.Body.String())
+	return responses
+}
+
+func TestBatchHandler_RunsEachOperation(t *testing.T) {
+	h, executed := newBatchServer(5, 100)
+
+	rec := postBatch(t, h, helloBatch(3))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	responses := batchResponses(t, rec)
+	require.Len(t, responses, 3)
+	for _, resp := range responses {
+		assert.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"hello":"world"}`, string(resp.Data))
+	}
+	assert.Equal(t, 3, *executed)
+}
+
+func TestBatchHandler_RejectsOversizedBatch(t *testing.T) {
+	h, executed := newBatchServer(5, 100)
+
+	rec := postBatch(t, h, helloBatch(6))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	var resp graphql.Response
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "Batch of 6 operations exceeds maximum allowed batch size 5", resp.Errors[0].Message)
+	assert.Equal(t, "BATCH_TOO_LARGE", resp.Errors[0].Extensions["code"])
+	assert.Zero(t, *executed, "no operation may run from a rejected batch")
+}
+
+func TestBatchHandler_ZeroSizeDisablesBatching(t *testing.T) {
+	h, executed := newBatchServer(0, 100)
+
+	rec := postBatch(t, h, helloBatch(1))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Batched requests are disabled")
+	assert.Zero(t, *executed)
+}
+
+func TestBatchHandler_CountsComplexityAcrossBatch(t *testing.T) {
+	// Each operation costs 1, so only two fit under the limit together
+	h, executed := newBatchServer(5, 2)
+
+	responses := batchResponses(t, postBatch(t, h, helloBatch(3)))
+
+	require.Len(t, responses, 3)
+	assert.Empty(t, responses[0].Errors)
+	assert.Empty(t, responses[1].Errors)
+	require.Len(t, responses[2].Errors, 1)
+	assert.Equal(t, "batch complexity 3 exceeds maximum allowed complexity 2", responses[2].Errors[0].Message)
+	assert.Equal(t, 2, *executed)
+}
+
+func TestBatchHandler_PassesSingleOperationsThrough(t *testing.T) {
+	h, executed := newBatchServer(5, 2)
+
+	// Outside a batch each operation only has to fit the limit on its own
+	for i := 0; i < 3; i++ {
+		rec := postBatch(t, h, `{"query":"{ hello }"}`)
+		assert.JSONEq(t, `{"data":{"hello":"world"}}`, rec.Body.String())
+	}
+	assert.Equal(t, 3, *executed)
+}
+
+func TestBatchConfigFromEnv(t *testing.T) {
+	t.Setenv("GRAPHQL_MAX_BATCH_SIZE", "")
+	assert.Equal(t, DefaultBatchConfig(), BatchConfigFromEnv())
+
+	t.Setenv("GRAPHQL_MAX_BATCH_SIZE", "0")
+	assert.Equal(t, 0, BatchConfigFromEnv().MaxBatchSize)
+
+	t.Setenv("GRAPHQL_MAX_BATCH_SIZE", "-1")
+	assert.Equal(t, DefaultBatchConfig(), BatchConfigFromEnv())
+
+	t.Setenv("GRAPHQL_MAX_BODY_BYTES", "1024")
+	assert.Equal(t, 1024, BatchConfigFromEnv().MaxBodyBytes)
+}
+
+func TestBatchHandler_RejectsOversizedBody(t *testing.T) {
+	executed := 0
+	h := BatchHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		executed++
+	}), BatchConfig{MaxBatchSize: 100, MaxBodyBytes: 50})
+
+	rec := postBatch(t, h, helloBatch(10))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Contains(t, rec.Body.String(), "REQUEST_TOO_LARGE")
+	assert.Zero(t, executed)
+}
+
+func TestBatchHandler_CopiesOperationHeaders(t *testing.T) {
+	operation := 0
+	h := BatchHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operation++
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("X-RateLimit-Remaining", strings.Repeat("9", 3-operation))
+		w.Header().Add("Set-Cookie", "op"+strings.Repeat("x", operation)+"=1")
+		w.Write([]byte(`{"data":{"hello":"world"}}`))
+	}), BatchConfig{MaxBatchSize: 5})
+
+	rec := postBatch(t, h, helloBatch(2))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "9", rec.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, []string{"opx=1", "opxx=1"}, rec.Header().Values("Set-Cookie"))
+}
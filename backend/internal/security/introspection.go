@@ -0,0 +1,127 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// IntrospectionMode controls who may introspect the schema
+type IntrospectionMode string
+
+const (
+	// IntrospectionEnabled allows introspection for everyone
+	IntrospectionEnabled IntrospectionMode = "enabled"
+	// IntrospectionDisabled blocks introspection for everyone
+	IntrospectionDisabled IntrospectionMode = "disabled"
+	// IntrospectionAdminOnly allows introspection for authenticated admins only
+	IntrospectionAdminOnly IntrospectionMode = "admin"
+)
+
+// ParseIntrospectionMode parses a mode name, case-insensitively
+func ParseIntrospectionMode(value string) (IntrospectionMode, error) {
+	mode := IntrospectionMode(strings.ToLower(strings.TrimSpace(value)))
+	switch mode {
+	case IntrospectionEnabled, IntrospectionDisabled, IntrospectionAdminOnly:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown introspection mode %q", value)
+	}
+}
+
+// IntrospectionModeFromEnv reads GRAPHQL_INTROSPECTION, defaulting to enabled.
+// An unrecognised value disables introspection rather than exposing the schema.
+func IntrospectionModeFromEnv() IntrospectionMode {
+	value := os.Getenv("GRAPHQL_INTROSPECTION")
+	if value == "" {
+		return IntrospectionEnabled
+	}
+
+	mode, err := ParseIntrospectionMode(value)
+	if err != nil {
+		log.Printf("Invalid GRAPHQL_INTROSPECTION, disabling introspection: %v", err)
+		return IntrospectionDisabled
+	}
+	return mode
+}
+
+// IntrospectionGuard decides per operation whether introspection is allowed.
+// Register it after the server's introspection extension so its decision wins.
+type IntrospectionGuard struct {
+	mode IntrospectionMode
+}
+
+// NewIntrospectionGuard creates a new introspection guard
+func NewIntrospectionGuard(mode IntrospectionMode) *IntrospectionGuard {
+	return &IntrospectionGuard{mode: mode}
+}
+
+// ExtensionName returns the name of this extension
+func (g *IntrospectionGuard) ExtensionName() string {
+	return "IntrospectionGuard"
+}
+
+// Validate validates the schema (no-op for this extension)
+func (g *IntrospectionGuard) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// MutateOperationContext disables introspection for callers the mode excludes
+// and rejects operations that ask for it
+func (g *IntrospectionGuard) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	if g.allows(ctx) {
+		return nil
+	}
+
+	rc.DisableIntrospection = true
+	if rc.Operation != nil && selectsIntrospection(rc.Operation.SelectionSet) {
+		return &gqlerror.Error{
+			Message: "Schema introspection is disabled",
+			Extensions: map[string]interface{}{
+				"code": "INTROSPECTION_DISABLED",
+			},
+		}
+	}
+
+	return nil
+}
+
+// allows reports whether the caller in ctx may introspect under the mode
+func (g *IntrospectionGuard) allows(ctx context.Context) bool {
+	switch g.mode {
+	case IntrospectionEnabled:
+		return true
+	case IntrospectionAdminOnly:
+		user := GetUserFromContext(ctx)
+		return user != nil && user.IsActive && user.HasRole(RoleAdmin)
+	default:
+		return false
+	}
+}
+
+// selectsIntrospection reports whether a selection set asks for __schema or __type
+func selectsIntrospection(selectionSet ast.SelectionSet) bool {
+	for _, selection := range selectionSet {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			if sel.Name == "__schema" || sel.Name == "__type" {
+				return true
+			}
+		case *ast.InlineFragment:
+			if selectsIntrospection(sel.SelectionSet) {
+				return true
+			}
+		case *ast.FragmentSpread:
+			if sel.Definition != nil && selectsIntrospection(sel.Definition.SelectionSet) {
+				return true
+			}
+		}
+	}
+	return false
+}
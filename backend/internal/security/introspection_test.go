@@ -0,0 +1,83 @@
+package security
+
+import (
+	"context"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// operationContext parses a query into an operation context
+func operationContext(t *testing.T, query string) *graphql.OperationContext {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	require.NoError(t, err)
+	return &graphql.OperationContext{Doc: doc, Operation: doc.Operations[0]}
+}
+
+const schemaQuery = `query IntrospectionQuery { __schema { types { name } } }`
+
+func TestIntrospectionGuard_AdminOnly(t *testing.T) {
+	guard := NewIntrospectionGuard(IntrospectionAdminOnly)
+
+	t.Run("admin can introspect", func(t *testing.T) {
+		ctx := WithUser(context.Background(), &User{ID: "1", Role: RoleAdmin, IsActive: true})
+		rc := operationContext(t, schemaQuery)
+
+		assert.Nil(t, guard.MutateOperationContext(ctx, rc))
+		assert.False(t, rc.DisableIntrospection)
+	})
+
+	t.Run("regular user cannot", func(t *testing.T) {
+		ctx := WithUser(context.Background(), &User{ID: "2", Role: RoleUser, IsActive: true})
+		rc := operationContext(t, `{ posts { totalCount } ... on Query { __type(name: "Post") { name } } }`)
+
+		gqlErr := guard.MutateOperationContext(ctx, rc)
+		require.NotNil(t, gqlErr)
+		assert.Equal(t, "INTROSPECTION_DISABLED", gqlErr.Extensions["code"])
+		assert.True(t, rc.DisableIntrospection)
+	})
+
+	t.Run("anonymous cannot", func(t *testing.T) {
+		assert.NotNil(t, guard.MutateOperationContext(context.Background(), operationContext(t, schemaQuery)))
+	})
+
+	t.Run("inactive admin cannot", func(t *testing.T) {
+		ctx := WithUser(context.Background(), &User{ID: "3", Role: RoleAdmin})
+		assert.NotNil(t, guard.MutateOperationContext(ctx, operationContext(t, schemaQuery)))
+	})
+
+	t.Run("ordinary queries still run", func(t *testing.T) {
+		rc := operationContext(t, `{ posts { totalCount } }`)
+
+		assert.Nil(t, guard.MutateOperationContext(context.Background(), rc))
+		assert.True(t, rc.DisableIntrospection)
+	})
+}
+
+func TestIntrospectionGuard_Modes(t *testing.T) {
+	admin := WithUser(context.Background(), &User{ID: "1", Role: RoleAdmin, IsActive: true})
+
+	assert.Nil(t, NewIntrospectionGuard(IntrospectionEnabled).MutateOperationContext(context.Background(), operationContext(t, schemaQuery)))
+	assert.NotNil(t, NewIntrospectionGuard(IntrospectionDisabled).MutateOperationContext(admin, operationContext(t, schemaQuery)))
+}
+
+func TestIntrospectionModeFromEnv(t *testing.T) {
+	tests := []struct {
+		value string
+		want  IntrospectionMode
+	}{
+		{"", IntrospectionEnabled},
+		{"admin", IntrospectionAdminOnly},
+		{"DISABLED", IntrospectionDisabled},
+		{"sometimes", IntrospectionDisabled},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("GRAPHQL_INTROSPECTION", tt.value)
+		assert.Equal(t, tt.want, IntrospectionModeFromEnv(), tt.value)
+	}
+}
@@ -0,0 +1,82 @@
+package security
+
+import (
+	"context"
+
+	"backend/internal/complexity"
+	"github.com/gin-gonic/gin"
+)
+
+// Audit action and resource recorded for operations refused by a limit
+const (
+	ActionOperationRejected = "operation_rejected"
+	ResourceOperation       = "graphql_operation"
+)
+
+// clientInfoKey is the context key type for the caller's IP and user agent
+type clientInfoKey int
+
+const (
+	clientIPKey clientInfoKey = iota
+	userAgentKey
+)
+
+// WithClientInfo stores the caller's IP and user agent where the audit
+// logger and rate limiter read them
+func WithClientInfo(ctx context.Context, clientIP, userAgent string) context.Context {
+	ctx = context.WithValue(ctx, clientIPKey, clientIP)
+	return context.WithValue(ctx, userAgentKey, userAgent)
+}
+
+// ClientIPFromContext returns the client IP stored by WithClientInfo, or ""
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey).(string)
+	return ip
+}
+
+// UserAgentFromContext returns the user agent stored by WithClientInfo, or ""
+func UserAgentFromContext(ctx context.Context) string {
+	userAgent, _ := ctx.Value(userAgentKey).(string)
+	return userAgent
+}
+
+// ClientInfoMiddleware records each request's client IP and user agent in
+// its context, so audit entries can say who sent an operation
+func ClientInfoMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(WithClientInfo(c.Request.Context(), c.ClientIP(), c.Request.UserAgent()))
+		c.Next()
+	}
+}
+
+// LogRejection records an operation refused for exceeding limit, with
+// details such as the computed cost and the limit's value in metadata
+func (a *AuditLogger) LogRejection(ctx context.Context, user *User, operation, limit string, err error, metadata map[string]interface{}) {
+	entry := map[string]interface{}{"limit": limit}
+	for key, value := range metadata {
+		entry[key] = value
+	}
+	a.LogAccessWithMetadata(ctx, user, ActionOperationRejected, ResourceOperation, operation, false, err, entry)
+}
+
+// LogComplexityRejection records an operation the complexity analyzer
+// refused, with its computed complexity and depth and the limit's value
+func (a *AuditLogger) LogComplexityRejection(ctx context.Context, user *User, rejection complexity.Rejection) {
+	a.LogRejection(ctx, user, rejection.Operation, rejection.Limit, rejection.Err, map[string]interface{}{
+		"complexity": rejection.Complexity,
+		"depth":      rejection.Depth,
+		"max":        rejection.Max,
+	})
+}
+
+// auditUser returns the user to record for ctx: the security user when one
+// is set, otherwise one carrying just the ID, or nil for anonymous callers
+func auditUser(ctx context.Context) *User {
+	if user := GetUserFromContext(ctx); user != nil {
+		return user
+	}
+	if userID := userIDFromContext(ctx); userID != "" {
+		return &User{ID: userID}
+	}
+	return nil
+}
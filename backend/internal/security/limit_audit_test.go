@@ -0,0 +1,141 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"backend/internal/complexity"
+	"backend/internal/graph/model"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+func TestComplexityRejectionIsAudited(t *testing.T) {
+	var entries []AuditLog
+	auditLogger := NewAuditLoggerWithSink(func(entry AuditLog) { entries = append(entries, entry) })
+
+	config := complexity.DefaultConfig()
+	config.MaxComplexity = 3
+	config.OnReject = func(ctx context.Context, rejection complexity.Rejection) {
+		auditLogger.LogComplexityRejection(ctx, GetUserFromContext(ctx), rejection)
+	}
+	extension := complexity.NewAnalyzer(config).ComplexityMiddleware().(graphql.OperationInterceptor)
+
+	doc, err := parser.ParseQuery(&ast.Source{Input: `query Expensive { posts { edges { node { id title } } } }`})
+	require.NoError(t, err)
+	ctx := WithClientInfo(context.Background(), "203.0.113.7", "curl/8.0")
+	ctx = WithUser(ctx, &User{ID: "user-1", Role: RoleUser})
+	ctx = graphql.WithOperationContext(ctx, &graphql.OperationContext{
+		Operation:     doc.Operations[0],
+		OperationName: "Expensive",
+	})
+
+	nextCalled := false
+	response := extension.InterceptOperation(ctx, func(ctx context.Context) graphql.ResponseHandler {
+		nextCalled = true
+		return nil
+	})(ctx)
+
+	assert.False(t, nextCalled)
+	require.Len(t, response.Errors, 1)
+
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, ActionOperationRejected, entry.Action)
+	assert.Equal(t, ResourceOperation, entry.Resource)
+	assert.Equal(t, "Expensive", entry.ResourceID)
+	assert.Equal(t, "user-1", entry.UserID)
+	assert.Equal(t, "203.0.113.7", entry.IPAddress)
+	assert.Equal(t, "curl/8.0", entry.UserAgent)
+	assert.False(t, entry.Success)
+	assert.Contains(t, entry.Error, "exceeds maximum allowed complexity 3")
+	assert.Equal(t, complexity.LimitComplexity, entry.Metadata["limit"])
+	assert.Equal(t, 5, entry.Metadata["complexity"])
+	assert.Equal(t, 4, entry.Metadata["depth"])
+	assert.Equal(t, 3, entry.Metadata["max"])
+}
+
+func TestQueryBudgetRejectionIsAudited(t *testing.T) {
+	var entries []AuditLog
+	budget, _ := newTestQueryBudget(QueryBudgetConfig{Capacity: 1, RefillPerMinute: 1})
+	budget.SetAuditLogger(NewAuditLoggerWithSink(func(entry AuditLog) { entries = append(entries, entry) }))
+
+	doc, err := parser.ParseQuery(&ast.Source{Input: `query Listing { posts { edges { node { id } } } }`})
+	require.NoError(t, err)
+	ctx := WithClientInfo(context.Background(), "198.51.100.2", "")
+	ctx = graphql.WithOperationContext(ctx, &graphql.OperationContext{Operation: doc.Operations[0], OperationName: "Listing"})
+
+	budget.InterceptOperation(ctx, func(ctx context.Context) graphql.ResponseHandler { return nil })
+
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Listing", entries[0].ResourceID)
+	assert.Empty(t, entries[0].UserID)
+	assert.Equal(t, "198.51.100.2", entries[0].IPAddress)
+	assert.Equal(t, "query_budget", entries[0].Metadata["limit"])
+	assert.Equal(t, 1, entries[0].Metadata["capacity"])
+}
+
+// memoryAuditStore keeps stored audit entries, or fails every write with err
+type memoryAuditStore struct {
+	entries []*model.AuditLog
+	err     error
+	ctxErr  error
+}
+
+func (s *memoryAuditStore) Create(ctx context.Context, entry *model.AuditLog) error {
+	s.ctxErr = ctx.Err()
+	if s.err != nil {
+		return s.err
+	}
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func TestAuditLoggerWithStore_PersistsEntries(t *testing.T) {
+	store := &memoryAuditStore{}
+	auditLogger := NewAuditLoggerWithStore(store)
+	userID := uuid.New()
+
+	// Entries are stored even when the request was already abandoned
+	ctx, cancel := context.WithCancel(WithClientInfo(context.Background(), "203.0.113.7", "curl/8.0"))
+	cancel()
+	auditLogger.LogRejection(ctx, &User{ID: userID.String()}, "Expensive", "rate_limit", errors.New("too many requests"), nil)
+	auditLogger.LogAccess(context.Background(), nil, "resend_verification", "user", "", true, nil)
+
+	require.Len(t, store.entries, 2)
+	assert.NoError(t, store.ctxErr)
+	entry := store.entries[0]
+	assert.NotEqual(t, uuid.Nil, entry.ID)
+	require.NotNil(t, entry.UserID)
+	assert.Equal(t, userID, *entry.UserID)
+	assert.Equal(t, ActionOperationRejected, entry.Action)
+	assert.Equal(t, "Expensive", entry.ResourceID)
+	assert.Equal(t, "203.0.113.7", entry.IPAddress)
+	assert.Equal(t, "curl/8.0", entry.UserAgent)
+	assert.Equal(t, "too many requests", entry.Error)
+	assert.Equal(t, "rate_limit", entry.Metadata["limit"])
+	assert.False(t, entry.CreatedAt.IsZero())
+	assert.Nil(t, store.entries[1].UserID, "anonymous entries have no user")
+}
+
+func TestAuditLoggerWithStore_SurvivesStoreFailure(t *testing.T) {
+	store := &memoryAuditStore{err: errors.New("database unavailable")}
+
+	assert.NotPanics(t, func() {
+		NewAuditLoggerWithStore(store).LogAccess(context.Background(), nil, "hide_post", "post", "1", true, nil)
+	})
+}
+
+func TestClientInfoIgnoresStringKeys(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "client_ip", "192.0.2.1")
+	assert.Empty(t, ClientIPFromContext(ctx))
+
+	ctx = WithClientInfo(ctx, "203.0.113.7", "curl/8.0")
+	assert.Equal(t, "203.0.113.7", ClientIPFromContext(ctx))
+	assert.Equal(t, "curl/8.0", UserAgentFromContext(ctx))
+}
@@ -0,0 +1,81 @@
+package security
+
+import (
+	"sync"
+	"time"
+)
+
+// maxLocalBuckets bounds the fallback limiter's memory; full buckets are
+// dropped first since they carry no state worth keeping
+const maxLocalBuckets = 10000
+
+// tokenBucket holds the state for one client key
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// localLimiter is an in-memory token bucket limiter used while Redis is
+// unreachable. It only sees this instance's traffic, so its limit is coarse.
+type localLimiter struct {
+	capacity     float64
+	refillPerSec float64
+	now          func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// newLocalLimiter creates a limiter allowing requestsPerMinute per key, with bursts up to the same amount
+func newLocalLimiter(requestsPerMinute int) *localLimiter {
+	return &localLimiter{
+		capacity:     float64(requestsPerMinute),
+		refillPerSec: float64(requestsPerMinute) / 60,
+		now:          time.Now,
+		buckets:      make(map[string]*tokenBucket),
+	}
+}
+
+// Allow takes a token for key and reports whether one was available
+func (l *localLimiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	bucket, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxLocalBuckets {
+			l.evictFull(now)
+		}
+		bucket = &tokenBucket{tokens: l.capacity, lastSeen: now}
+		l.buckets[key] = bucket
+	} else {
+		l.refill(bucket, now)
+	}
+
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// refill adds the tokens earned since the bucket was last seen
+func (l *localLimiter) refill(bucket *tokenBucket, now time.Time) {
+	elapsed := now.Sub(bucket.lastSeen).Seconds()
+	bucket.tokens += elapsed * l.refillPerSec
+	if bucket.tokens > l.capacity {
+		bucket.tokens = l.capacity
+	}
+	bucket.lastSeen = now
+}
+
+// evictFull drops buckets that have refilled completely
+func (l *localLimiter) evictFull(now time.Time) {
+	for key, bucket := range l.buckets {
+		l.refill(bucket, now)
+		if bucket.tokens >= l.capacity {
+			delete(l.buckets, key)
+		}
+	}
+}
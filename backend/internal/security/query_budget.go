@@ -0,0 +1,203 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"backend/internal/complexity"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/redis/go-redis/v9"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// ErrQueryBudgetExceeded is returned when an operation costs more than the remaining budget
+var ErrQueryBudgetExceeded = errors.New("query cost budget exceeded")
+
+// QueryBudgetConfig holds per-client query cost budget settings
+type QueryBudgetConfig struct {
+	// Maximum complexity a client can spend in a burst
+	Capacity int
+
+	// Complexity restored to the budget every minute
+	RefillPerMinute int
+}
+
+// DefaultQueryBudgetConfig returns default query budget configuration
+func DefaultQueryBudgetConfig() QueryBudgetConfig {
+	return QueryBudgetConfig{
+		Capacity:        10000,
+		RefillPerMinute: 5000,
+	}
+}
+
+// budgetStore atomically refills a token bucket and takes cost from it
+type budgetStore interface {
+	take(ctx context.Context, key string, cost, capacity, refillPerSec float64, now time.Time) (bool, float64, error)
+}
+
+// QueryBudget deducts each operation's complexity from a rolling per-client
+// budget, limiting sustained expensive usage that per-request limits miss.
+// Authenticated users are keyed by ID; anonymous clients share a budget per IP.
+type QueryBudget struct {
+	analyzer *complexity.Analyzer
+	store    budgetStore
+	config   QueryBudgetConfig
+	now      func() time.Time
+	audit    *AuditLogger
+}
+
+// NewQueryBudget creates a new query budget backed by Redis
+func NewQueryBudget(redisClient redis.UniversalClient, analyzer *complexity.Analyzer, config QueryBudgetConfig) *QueryBudget {
+	return &QueryBudget{
+		analyzer: analyzer,
+		store:    &redisBudgetStore{client: redisClient},
+		config:   config,
+		now:      time.Now,
+	}
+}
+
+// SetAuditLogger records every operation refused for its budget in logger
+func (q *QueryBudget) SetAuditLogger(logger *AuditLogger) {
+	q.audit = logger
+}
+
+// ExtensionName returns the name of this extension
+func (q *QueryBudget) ExtensionName() string {
+	return "QueryBudget"
+}
+
+// Validate validates the schema (no-op for this extension)
+func (q *QueryBudget) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation charges the operation's complexity to the client's budget
+func (q *QueryBudget) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	oc := graphql.GetOperationContext(ctx)
+
+	cost, err := q.analyzer.AnalyzeComplexity(ctx, oc)
+	if err != nil {
+		return graphql.OneShot(graphql.ErrorResponse(ctx, err.Error()))
+	}
+
+	remaining, err := q.Spend(ctx, budgetKey(clientIPFromContext(ctx), userIDFromContext(ctx)), cost)
+	if errors.Is(err, ErrQueryBudgetExceeded) {
+		if q.audit != nil {
+			q.audit.LogRejection(ctx, auditUser(ctx), oc.OperationName, "query_budget", err, map[string]interface{}{
+				"complexity": cost,
+				"remaining":  int(remaining),
+				"capacity":   q.config.Capacity,
+			})
+		}
+		return func(ctx context.Context) *graphql.Response {
+			return &graphql.Response{
+				Errors: gqlerror.List{
+					{
+						Message: err.Error(),
+						Extensions: map[string]interface{}{
+							"code":      "QUERY_BUDGET_EXCEEDED",
+							"cost":      cost,
+							"remaining": int(remaining),
+						},
+					},
+				},
+			}
+		}
+	}
+	if err != nil {
+		// Budgets are a soft limit; don't fail requests when Redis is down
+		log.Printf("Failed to charge query budget: %v", err)
+	}
+
+	return next(ctx)
+}
+
+// Spend takes cost from the budget stored under key and returns what is left.
+// It returns ErrQueryBudgetExceeded, leaving the budget untouched, if cost would overdraw it.
+func (q *QueryBudget) Spend(ctx context.Context, key string, cost int) (float64, error) {
+	refillPerSec := float64(q.config.RefillPerMinute) / 60
+	allowed, remaining, err := q.store.take(ctx, key, float64(cost), float64(q.config.Capacity), refillPerSec, q.now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to charge query budget: %w", err)
+	}
+	if !allowed {
+		return remaining, fmt.Errorf("%w: cost %d, remaining %.0f", ErrQueryBudgetExceeded, cost, math.Floor(remaining))
+	}
+	return remaining, nil
+}
+
+// budgetKey picks the Redis key a client's budget is stored under
+func budgetKey(clientIP, userID string) string {
+	if userID != "" {
+		return fmt.Sprintf("query_budget:user:%s", userID)
+	}
+	if clientIP != "" {
+		return fmt.Sprintf("query_budget:ip:%s", clientIP)
+	}
+	return "query_budget:anonymous"
+}
+
+// queryBudgetScript refills the bucket for the time elapsed since it was last
+// touched, then takes the cost if enough tokens remain
+var queryBudgetScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local cost = tonumber(ARGV[4])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+if tokens >= cost then
+	tokens = tokens - cost
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('EXPIRE', KEYS[1], ARGV[5])
+return {allowed, tostring(tokens)}
+`)
+
+// redisBudgetStore keeps token buckets in Redis hashes
+type redisBudgetStore struct {
+	client redis.UniversalClient
+}
+
+func (s *redisBudgetStore) take(ctx context.Context, key string, cost, capacity, refillPerSec float64, now time.Time) (bool, float64, error) {
+	// A bucket left alone until it is full again carries no state
+	ttl := int((24 * time.Hour) / time.Second)
+	if refillPerSec > 0 {
+		ttl = int(math.Ceil(capacity/refillPerSec)) + 1
+	}
+	nowSeconds := float64(now.UnixNano()) / float64(time.Second)
+
+	result, err := queryBudgetScript.Run(ctx, s.client, []string{key}, capacity, refillPerSec, nowSeconds, cost, ttl).Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	if len(result) != 2 {
+		return false, 0, fmt.Errorf("unexpected query budget script result: %v", result)
+	}
+
+	allowed, _ := result[0].(int64)
+	tokensRaw, _ := result[1].(string)
+	tokens, err := strconv.ParseFloat(tokensRaw, 64)
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid query budget tokens %q: %w", tokensRaw, err)
+	}
+
+	return allowed == 1, tokens, nil
+}
@@ -0,0 +1,135 @@
+package security
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"backend/internal/complexity"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// memoryBudgetStore mirrors the Redis script's bucket arithmetic in memory
+type memoryBudgetStore struct {
+	tokens map[string]float64
+	seen   map[string]time.Time
+}
+
+func newMemoryBudgetStore() *memoryBudgetStore {
+	return &memoryBudgetStore{tokens: map[string]float64{}, seen: map[string]time.Time{}}
+}
+
+func (s *memoryBudgetStore) take(ctx context.Context, key string, cost, capacity, refillPerSec float64, now time.Time) (bool, float64, error) {
+	tokens, ok := s.tokens[key]
+	if !ok {
+		tokens = capacity
+	} else {
+		elapsed := math.Max(0, now.Sub(s.seen[key]).Seconds())
+		tokens = math.Min(capacity, tokens+elapsed*refillPerSec)
+	}
+	s.seen[key] = now
+
+	allowed := tokens >= cost
+	if allowed {
+		tokens -= cost
+	}
+	s.tokens[key] = tokens
+	return allowed, tokens, nil
+}
+
+func newTestQueryBudget(config QueryBudgetConfig) (*QueryBudget, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	budget := &QueryBudget{
+		analyzer: complexity.NewAnalyzer(complexity.DefaultConfig()),
+		store:    newMemoryBudgetStore(),
+		config:   config,
+		now:      func() time.Time { return now },
+	}
+	return budget, &now
+}
+
+// queryCost runs a query through the complexity analyzer
+func queryCost(t *testing.T, analyzer *complexity.Analyzer, query string) int {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	require.NoError(t, err)
+	cost, err := analyzer.AnalyzeComplexity(context.Background(), &graphql.OperationContext{Operation: doc.Operations[0]})
+	require.NoError(t, err)
+	return cost
+}
+
+const moderateQuery = `{
+	posts {
+		edges {
+			node {
+				id
+				title
+				author { id name }
+				comments { edges { node { id content author { id name } } } }
+			}
+		}
+	}
+}`
+
+func TestQueryBudget_ExhaustsUnderSustainedLoad(t *testing.T) {
+	budget, _ := newTestQueryBudget(QueryBudgetConfig{Capacity: 100, RefillPerMinute: 60})
+	cost := queryCost(t, budget.analyzer, moderateQuery)
+	require.Greater(t, cost, 10)
+
+	key := budgetKey("10.0.0.1", "user-1")
+	allowed := 0
+	var err error
+	for i := 0; i < 100 && err == nil; i++ {
+		if _, err = budget.Spend(context.Background(), key, cost); err == nil {
+			allowed++
+		}
+	}
+
+	assert.ErrorIs(t, err, ErrQueryBudgetExceeded)
+	assert.Equal(t, 100/cost, allowed)
+}
+
+func TestQueryBudget_RefillsOverTime(t *testing.T) {
+	budget, now := newTestQueryBudget(QueryBudgetConfig{Capacity: 100, RefillPerMinute: 60})
+	key := budgetKey("", "user-1")
+	ctx := context.Background()
+
+	_, err := budget.Spend(ctx, key, 100)
+	require.NoError(t, err)
+	_, err = budget.Spend(ctx, key, 30)
+	require.ErrorIs(t, err, ErrQueryBudgetExceeded)
+
+	// One unit per second at 60 per minute
+	*now = now.Add(30 * time.Second)
+	remaining, err := budget.Spend(ctx, key, 30)
+	require.NoError(t, err)
+	assert.InDelta(t, 0, remaining, 0.001)
+
+	// Refill never exceeds capacity
+	*now = now.Add(time.Hour)
+	remaining, err = budget.Spend(ctx, key, 1)
+	require.NoError(t, err)
+	assert.InDelta(t, 99, remaining, 0.001)
+}
+
+func TestQueryBudget_KeysByUserThenIP(t *testing.T) {
+	assert.Equal(t, "query_budget:user:user-1", budgetKey("10.0.0.1", "user-1"))
+	assert.Equal(t, "query_budget:ip:10.0.0.1", budgetKey("10.0.0.1", ""))
+	assert.Equal(t, "query_budget:anonymous", budgetKey("", ""))
+
+	budget, _ := newTestQueryBudget(QueryBudgetConfig{Capacity: 50, RefillPerMinute: 0})
+	ctx := context.Background()
+
+	_, err := budget.Spend(ctx, budgetKey("10.0.0.1", ""), 50)
+	require.NoError(t, err)
+	_, err = budget.Spend(ctx, budgetKey("10.0.0.1", ""), 1)
+	assert.ErrorIs(t, err, ErrQueryBudgetExceeded)
+
+	// A signed-in user from the same IP has their own budget
+	_, err = budget.Spend(ctx, budgetKey("10.0.0.1", "user-1"), 50)
+	assert.NoError(t, err)
+}
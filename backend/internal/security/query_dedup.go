@@ -0,0 +1,172 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// QueryDedupEnabledFromEnv reports whether GRAPHQL_DEDUPLICATE_QUERIES turns
+// on query deduplication; it is off by default
+func QueryDedupEnabledFromEnv() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("GRAPHQL_DEDUPLICATE_QUERIES"))
+	return enabled
+}
+
+// QueryDeduplicator lets concurrent identical queries share one execution.
+// Queries are identical when they have the same normalized document, operation
+// name, variables and viewer, so a response is never shared between viewers.
+// Only queries are deduplicated; mutations and subscriptions always run. The
+// shared execution runs with the first caller's context, so its cancellation
+// is seen by every caller waiting on it.
+type QueryDeduplicator struct {
+	// Viewer identifies the caller; anonymous callers return "" and are told
+	// apart by client IP
+	viewer func(ctx context.Context) string
+
+	mu      sync.Mutex
+	flights map[string]*queryFlight
+}
+
+// queryFlight is one execution shared by every caller with the same key
+type queryFlight struct {
+	done    chan struct{}
+	waiters int
+	resp    *graphql.Response
+}
+
+// NewQueryDeduplicator creates a query deduplicator. When viewer is nil
+// callers are identified by the security user in their context.
+func NewQueryDeduplicator(viewer func(ctx context.Context) string) *QueryDeduplicator {
+	if viewer == nil {
+		viewer = securityViewer
+	}
+	return &QueryDeduplicator{viewer: viewer, flights: make(map[string]*queryFlight)}
+}
+
+// securityViewer identifies callers by their security user or user ID
+func securityViewer(ctx context.Context) string {
+	if user := GetUserFromContext(ctx); user != nil {
+		return user.ID
+	}
+	return userIDFromContext(ctx)
+}
+
+// ExtensionName returns the name of this extension
+func (d *QueryDeduplicator) ExtensionName() string {
+	return "QueryDeduplicator"
+}
+
+// Validate validates the schema (no-op for this extension)
+func (d *QueryDeduplicator) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation runs the operation once for all concurrent callers with
+// the same key and hands each of them a copy of the response
+func (d *QueryDeduplicator) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	oc := graphql.GetOperationContext(ctx)
+	if oc.Operation == nil || oc.Operation.Operation != ast.Query {
+		return next(ctx)
+	}
+
+	key, err := d.key(ctx, oc)
+	if err != nil {
+		return next(ctx)
+	}
+
+	// The executor needs next called now; resolvers only run once the
+	// response handler is, so callers that join a flight never run theirs
+	responses := next(ctx)
+	served := false
+	return func(ctx context.Context) *graphql.Response {
+		if served {
+			return nil
+		}
+		served = true
+		return d.do(ctx, key, responses)
+	}
+}
+
+// do joins the flight running under key, or starts one if there is none
+func (d *QueryDeduplicator) do(ctx context.Context, key string, responses graphql.ResponseHandler) *graphql.Response {
+	d.mu.Lock()
+	if flight, ok := d.flights[key]; ok {
+		flight.waiters++
+		d.mu.Unlock()
+		<-flight.done
+		return copyResponse(flight.resp)
+	}
+	flight := &queryFlight{done: make(chan struct{})}
+	d.flights[key] = flight
+	d.mu.Unlock()
+
+	defer func() {
+		if flight.resp == nil {
+			// The execution panicked; callers waiting on it still get an answer
+			flight.resp = graphql.ErrorResponse(ctx, "query execution failed")
+		}
+		d.mu.Lock()
+		delete(d.flights, key)
+		d.mu.Unlock()
+		close(flight.done)
+	}()
+
+	flight.resp = responses(ctx)
+	return copyResponse(flight.resp)
+}
+
+// key hashes the viewer, operation name, normalized document and variables
+func (d *QueryDeduplicator) key(ctx context.Context, oc *graphql.OperationContext) (string, error) {
+	variables, err := json.Marshal(oc.Variables)
+	if err != nil {
+		return "", err
+	}
+
+	viewer := d.viewer(ctx)
+	if viewer == "" {
+		viewer = "anonymous:" + clientIPFromContext(ctx)
+	}
+
+	var document bytes.Buffer
+	if oc.Doc != nil {
+		formatter.NewFormatter(&document).FormatQueryDocument(oc.Doc)
+	} else {
+		document.WriteString(oc.RawQuery)
+	}
+
+	hash := sha256.New()
+	for _, part := range [][]byte{[]byte(viewer), []byte(oc.OperationName), document.Bytes(), variables} {
+		hash.Write([]byte(strconv.Itoa(len(part))))
+		hash.Write([]byte{':'})
+		hash.Write(part)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// copyResponse copies a shared response so callers can't alter each other's
+func copyResponse(resp *graphql.Response) *graphql.Response {
+	if resp == nil {
+		return nil
+	}
+
+	copied := *resp
+	copied.Errors = append(gqlerror.List(nil), resp.Errors...)
+	if resp.Extensions != nil {
+		copied.Extensions = make(map[string]interface{}, len(resp.Extensions))
+		for name, value := range resp.Extensions {
+			copied.Extensions[name] = value
+		}
+	}
+	return &copied
+}
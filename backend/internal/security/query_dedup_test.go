@@ -0,0 +1,164 @@
+package security
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// dedupServer serves a schema whose executions block until release is closed,
+// taking the viewer from the X-Viewer header. Like generated executors, the
+// mock only runs resolvers once its response handler is called.
+type dedupServer struct {
+	handler  http.Handler
+	dedup    *QueryDeduplicator
+	executed atomic.Int32
+	release  chan struct{}
+}
+
+func newDedupServer() *dedupServer {
+	schema := gqlparser.MustLoadSchema(&ast.Source{Input: `
+		type Query { hello(name: String): String }
+		type Mutation { touch: String }
+	`})
+	s := &dedupServer{dedup: NewQueryDeduplicator(nil), release: make(chan struct{})}
+
+	srv := handler.New(&graphql.ExecutableSchemaMock{
+		SchemaFunc: func() *ast.Schema { return schema },
+		ExecFunc: func(ctx context.Context) graphql.ResponseHandler {
+			return func(ctx context.Context) *graphql.Response {
+				s.executed.Add(1)
+				<-s.release
+				return &graphql.Response{Data: []byte(`{"hello":"world"}`)}
+			}
+		},
+	})
+	srv.AddTransport(transport.POST{})
+	srv.Use(s.dedup)
+
+	s.handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if viewer := r.Header.Get("X-Viewer"); viewer != "" {
+			ctx = WithUser(ctx, &User{ID: viewer, Role: RoleUser, IsActive: true})
+		}
+		srv.ServeHTTP(w, r.WithContext(ctx))
+	})
+	return s
+}
+
+// post sends body as viewer and returns the response
+func (s *dedupServer) post(viewer, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Viewer", viewer)
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// waiters counts the callers waiting on a running execution
+func (s *dedupServer) waiters() int {
+	s.dedup.mu.Lock()
+	defer s.dedup.mu.Unlock()
+	total := 0
+	for _, flight := range s.dedup.flights {
+		total += flight.waiters
+	}
+	return total
+}
+
+// run sends each request concurrently and returns the responses once
+// release is closed
+func (s *dedupServer) run(t *testing.T, requests [][2]string, wait func() bool) []*httptest.ResponseRecorder {
+	t.Helper()
+	responses := make([]*httptest.ResponseRecorder, len(requests))
+	var wg sync.WaitGroup
+	for i, request := range requests {
+		wg.Add(1)
+		go func(i int, viewer, body string) {
+			defer wg.Done()
+			responses[i] = s.post(viewer, body)
+		}(i, request[0], request[1])
+	}
+
+	require.Eventually(t, wait, time.Second, time.Millisecond)
+	close(s.release)
+	wg.Wait()
+	return responses
+}
+
+func TestQueryDeduplicator_SharesIdenticalQueries(t *testing.T) {
+	s := newDedupServer()
+	requests := [][2]string{
+		{"alice", `{"query":"{ hello(name: \"x\") }"}`},
+		{"alice", `{"query":"{\n  hello(name: \"x\")\n}"}`},
+		{"alice", `{"query":"query($n: String) { hello(name: $n) }","variables":{"n":"x"}}`},
+		{"alice", `{"query":"query($n: String) { hello(name: $n) }","variables":{"n":"x"}}`},
+	}
+
+	// Whitespace differences normalize away; the variable form is its own key
+	responses := s.run(t, requests, func() bool {
+		return s.executed.Load() == 2 && s.waiters() == 2
+	})
+
+	assert.Equal(t, int32(2), s.executed.Load())
+	for _, rec := range responses {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"data":{"hello":"world"}}`, rec.Body.String())
+	}
+}
+
+func TestQueryDeduplicator_SeparatesViewers(t *testing.T) {
+	s := newDedupServer()
+	query := `{"query":"{ hello }"}`
+	requests := [][2]string{{"alice", query}, {"bob", query}, {"", query}}
+
+	s.run(t, requests, func() bool { return s.executed.Load() == 3 })
+
+	assert.Equal(t, int32(3), s.executed.Load())
+	assert.Zero(t, s.waiters())
+}
+
+func TestQueryDeduplicator_SkipsMutations(t *testing.T) {
+	s := newDedupServer()
+	mutation := `{"query":"mutation { touch }"}`
+
+	s.run(t, [][2]string{{"alice", mutation}, {"alice", mutation}}, func() bool {
+		return s.executed.Load() == 2
+	})
+
+	assert.Equal(t, int32(2), s.executed.Load())
+}
+
+func TestQueryDeduplicator_RunsAgainOnceFinished(t *testing.T) {
+	s := newDedupServer()
+	close(s.release)
+
+	s.post("alice", `{"query":"{ hello }"}`)
+	s.post("alice", `{"query":"{ hello }"}`)
+
+	assert.Equal(t, int32(2), s.executed.Load(), "only concurrent queries are shared")
+	assert.Empty(t, s.dedup.flights)
+}
+
+func TestCopyResponse(t *testing.T) {
+	resp := &graphql.Response{Data: []byte(`{}`), Extensions: map[string]interface{}{"cost": 1}}
+
+	copied := copyResponse(resp)
+	copied.Extensions["cost"] = 2
+
+	assert.Equal(t, 1, resp.Extensions["cost"])
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
 // QueryDepthLimiter limits the depth of GraphQL queries to prevent abuse
@@ -40,7 +41,7 @@ func (q *QueryDepthLimiter) InterceptOperation(ctx context.Context, next graphql
 	if depth > q.maxDepth {
 		return func(ctx context.Context) *graphql.Response {
 			return &graphql.Response{
-				Errors: []*graphql.Error{
+				Errors: gqlerror.List{
 					{
 						Message: fmt.Sprintf("Query depth %d exceeds maximum allowed depth %d", depth, q.maxDepth),
 						Extensions: map[string]interface{}{
@@ -145,7 +146,7 @@ func (q *QueryComplexityAnalyzer) InterceptOperation(ctx context.Context, next g
 	if complexity > q.maxComplexity {
 		return func(ctx context.Context) *graphql.Response {
 			return &graphql.Response{
-				Errors: []*graphql.Error{
+				Errors: gqlerror.List{
 					{
 						Message: fmt.Sprintf("Query complexity %d exceeds maximum allowed complexity %d", complexity, q.maxComplexity),
 						Extensions: map[string]interface{}{
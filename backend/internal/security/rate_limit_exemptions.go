@@ -0,0 +1,112 @@
+package security
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"log"
+	"net/netip"
+	"strings"
+
+	"backend/internal/envconfig"
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// APIKeyHeader carries the key trusted clients send to skip rate limiting
+const APIKeyHeader = "X-API-Key"
+
+// rateLimitExemptions holds the clients that bypass every rate limit,
+// parsed once so the per-request check is a few digest comparisons and a
+// prefix scan. API keys are kept as SHA-256 digests so every comparison
+// takes the same time whatever the key's length.
+type rateLimitExemptions struct {
+	prefixes []netip.Prefix
+	apiKeys  [][sha256.Size]byte
+}
+
+// newRateLimitExemptions parses exempt addresses, single IPs or CIDR ranges,
+// and API keys. Invalid addresses are logged and skipped.
+func newRateLimitExemptions(ips, apiKeys []string) *rateLimitExemptions {
+	exemptions := &rateLimitExemptions{}
+	for _, entry := range ips {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		prefix, err := parseExemptPrefix(entry)
+		if err != nil {
+			log.Printf("Ignoring invalid rate limit exemption %q: %v", entry, err)
+			continue
+		}
+		exemptions.prefixes = append(exemptions.prefixes, prefix)
+	}
+	for _, key := range apiKeys {
+		if key = strings.TrimSpace(key); key != "" {
+			exemptions.apiKeys = append(exemptions.apiKeys, sha256.Sum256([]byte(key)))
+		}
+	}
+	return exemptions
+}
+
+// parseExemptPrefix parses a CIDR range, or a single IP as a range holding only it
+func parseExemptPrefix(entry string) (netip.Prefix, error) {
+	if strings.Contains(entry, "/") {
+		prefix, err := netip.ParsePrefix(entry)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(entry)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), nil
+}
+
+// exempt reports whether the request in ctx, from clientIP, bypasses rate
+// limiting. It runs before any Redis call.
+func (e *rateLimitExemptions) exempt(ctx context.Context, clientIP string) bool {
+	if len(e.apiKeys) > 0 {
+		if key := apiKeyFromContext(ctx); key != "" && e.exemptAPIKey(key) {
+			return true
+		}
+	}
+	if len(e.prefixes) > 0 && clientIP != "" {
+		addr, err := netip.ParseAddr(clientIP)
+		if err != nil {
+			return false
+		}
+		addr = addr.Unmap()
+		for _, prefix := range e.prefixes {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// exemptAPIKey reports whether key is an exempt API key. It compares the
+// key's digest against every exempt key in constant time, so the time taken
+// reveals neither which key matched nor how much of one did.
+func (e *rateLimitExemptions) exemptAPIKey(key string) bool {
+	digest := sha256.Sum256([]byte(key))
+	match := 0
+	for _, exempt := range e.apiKeys {
+		match |= subtle.ConstantTimeCompare(digest[:], exempt[:])
+	}
+	return match == 1
+}
+
+// apiKeyFromContext returns the API key header of the operation in ctx
+func apiKeyFromContext(ctx context.Context) string {
+	if !graphql.HasOperationContext(ctx) {
+		return ""
+	}
+	return graphql.GetOperationContext(ctx).Headers.Get(APIKeyHeader)
+}
+
+// RateLimitExemptionsFromEnv returns the comma-separated addresses or CIDR
+// ranges in RATE_LIMIT_EXEMPT_IPS and API keys in RATE_LIMIT_EXEMPT_API_KEYS,
+// for RateLimitConfig.ExemptIPs and ExemptAPIKeys
+func RateLimitExemptionsFromEnv() (ips, apiKeys []string) {
+	return envconfig.List("RATE_LIMIT_EXEMPT_IPS"), envconfig.List("RATE_LIMIT_EXEMPT_API_KEYS")
+}
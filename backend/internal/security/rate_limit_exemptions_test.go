@@ -0,0 +1,76 @@
+package security
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withAPIKey returns a context for an operation sent with key in the API key header
+func withAPIKey(key string) context.Context {
+	headers := http.Header{}
+	headers.Set(APIKeyHeader, key)
+	return graphql.WithOperationContext(context.Background(), &graphql.OperationContext{Headers: headers})
+}
+
+func exemptConfig() RateLimitConfig {
+	config := DefaultRateLimitConfig()
+	config.IPRequestsPerMinute = 2
+	config.WarningThreshold = 0
+	config.ExemptIPs = []string{"10.1.0.0/16", "192.168.0.7", "not-an-ip"}
+	config.ExemptAPIKeys = []string{"health-checker-key"}
+	return config
+}
+
+func TestRateLimiter_ExemptClientsAreNeverLimited(t *testing.T) {
+	limiter := NewRateLimiter(newCountingRedis(t), exemptConfig())
+
+	t.Run("exempt addresses and keys", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			require.NoError(t, limiter.checkRateLimits(context.Background(), "10.1.200.3", "", "query"))
+			require.NoError(t, limiter.checkRateLimits(context.Background(), "192.168.0.7", "", "query"))
+			require.NoError(t, limiter.checkRateLimits(context.Background(), "::ffff:192.168.0.7", "", "query"))
+			require.NoError(t, limiter.checkRateLimits(withAPIKey("health-checker-key"), "10.0.0.9", "", "query"))
+		}
+	})
+
+	t.Run("other clients are limited", func(t *testing.T) {
+		clients := map[string]context.Context{
+			"10.2.0.1": context.Background(),
+			"10.2.0.2": withAPIKey("wrong-key"),
+		}
+		for clientIP, ctx := range clients {
+			require.NoError(t, limiter.checkRateLimits(ctx, clientIP, "", "query"))
+			require.NoError(t, limiter.checkRateLimits(ctx, clientIP, "", "query"))
+			assert.Error(t, limiter.checkRateLimits(ctx, clientIP, "", "query"), clientIP)
+		}
+	})
+}
+
+func TestRateLimiter_ExemptionSkipsRedis(t *testing.T) {
+	config := exemptConfig()
+	config.FailureMode = FailClosed
+	limiter := NewRateLimiter(unreachableRedis(t), config)
+
+	assert.NoError(t, limiter.checkRateLimits(context.Background(), "10.1.0.1", "", "query"))
+	assert.NoError(t, limiter.checkRateLimits(withAPIKey("health-checker-key"), "", "", "mutation"))
+
+	err := limiter.checkRateLimits(context.Background(), "10.0.0.1", "", "query")
+	assert.ErrorIs(t, err, ErrRateLimiterUnavailable)
+}
+
+func TestRateLimitExemptionsFromEnv(t *testing.T) {
+	ips, keys := RateLimitExemptionsFromEnv()
+	assert.Empty(t, ips)
+	assert.Empty(t, keys)
+
+	t.Setenv("RATE_LIMIT_EXEMPT_IPS", "10.0.0.0/8, 127.0.0.1,")
+	t.Setenv("RATE_LIMIT_EXEMPT_API_KEYS", "internal-key")
+	ips, keys = RateLimitExemptionsFromEnv()
+	assert.Equal(t, []string{"10.0.0.0/8", "127.0.0.1"}, ips)
+	assert.Equal(t, []string{"internal-key"}, keys)
+}
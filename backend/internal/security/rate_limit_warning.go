@@ -0,0 +1,64 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitWarningHeader is set on responses once a client has used most of a limit
+const RateLimitWarningHeader = "X-RateLimit-Warning"
+
+// rateLimitWarningKey is the context key for the response headers of the current request
+type rateLimitWarningKey struct{}
+
+// rateLimitWarning keeps the most used limit seen for one request
+type rateLimitWarning struct {
+	mu     sync.Mutex
+	header http.Header
+	usage  float64
+}
+
+// WithRateLimitWarnings lets the rate limiter further down the request set
+// the warning header on header
+func WithRateLimitWarnings(ctx context.Context, header http.Header) context.Context {
+	return context.WithValue(ctx, rateLimitWarningKey{}, &rateLimitWarning{header: header})
+}
+
+// RateLimitWarningMiddleware carries the response headers to the rate limiter
+// so it can warn clients approaching a limit before they are blocked
+func RateLimitWarningMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(WithRateLimitWarnings(c.Request.Context(), c.Writer.Header()))
+		c.Next()
+	}
+}
+
+// warnIfNearLimit sets the warning header when used requests, counting the
+// current one, reach the warning threshold of limit. With several limits
+// near their threshold the header describes the most used one.
+func (r *RateLimiter) warnIfNearLimit(ctx context.Context, used int64, limit int, window time.Duration) {
+	if r.config.WarningThreshold <= 0 || limit <= 0 {
+		return
+	}
+	usage := float64(used) / float64(limit)
+	if usage < r.config.WarningThreshold {
+		return
+	}
+
+	warning, ok := ctx.Value(rateLimitWarningKey{}).(*rateLimitWarning)
+	if !ok {
+		return
+	}
+	warning.mu.Lock()
+	defer warning.mu.Unlock()
+	if usage <= warning.usage {
+		return
+	}
+	warning.usage = usage
+	warning.header.Set(RateLimitWarningHeader, fmt.Sprintf("%d of %d requests per %v used", used, limit, window))
+}
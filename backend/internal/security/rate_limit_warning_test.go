@@ -0,0 +1,123 @@
+package security
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingRedis answers the rate limiter's pipelines from in-memory counters
+// instead of a server, counting every added request against its key
+type countingRedis struct {
+	counts map[string]int64
+}
+
+func (c *countingRedis) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (c *countingRedis) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return next
+}
+
+func (c *countingRedis) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			key, _ := cmd.Args()[1].(string)
+			switch cmd.Name() {
+			case "zadd":
+				c.counts[key]++
+			case "zcount":
+				cmd.(*redis.IntCmd).SetVal(c.counts[key])
+			case "incr":
+				c.counts[key]++
+				cmd.(*redis.IntCmd).SetVal(c.counts[key])
+			case "get":
+				cmd.SetErr(redis.Nil)
+			}
+		}
+		return nil
+	}
+}
+
+func newCountingRedis(t *testing.T) redis.UniversalClient {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1"})
+	client.AddHook(&countingRedis{counts: map[string]int64{}})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// newRateLimitedRouter serves a route checking the limiter for every request
+func newRateLimitedRouter(limiter *RateLimiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RateLimitWarningMiddleware())
+	r.POST("/graphql", func(c *gin.Context) {
+		if err := limiter.checkRateLimits(c.Request.Context(), "10.0.0.1", "", "query"); err != nil {
+			c.String(http.StatusTooManyRequests, err.Error())
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func TestRateLimiter_WarnsBeforeBlocking(t *testing.T) {
+	for _, algorithm := range []RateLimitAlgorithm{SlidingWindowLog, SlidingWindowCounter} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			config := DefaultRateLimitConfig()
+			config.Algorithm = algorithm
+			config.QueryRequestsPerMinute = 5
+			config.WarningThreshold = 0.8
+			router := newRateLimitedRouter(NewRateLimiter(newCountingRedis(t), config))
+
+			serve := func() *httptest.ResponseRecorder {
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", nil))
+				return w
+			}
+
+			for i := 1; i <= 3; i++ {
+				w := serve()
+				require.Equal(t, http.StatusOK, w.Code)
+				assert.Empty(t, w.Header().Get(RateLimitWarningHeader), "request %d", i)
+			}
+
+			// The fourth and fifth requests cross 80% but stay within the limit
+			w := serve()
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "4 of 5 requests per 1m0s used", w.Header().Get(RateLimitWarningHeader))
+
+			w = serve()
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "5 of 5 requests per 1m0s used", w.Header().Get(RateLimitWarningHeader))
+
+			w = serve()
+			assert.Equal(t, http.StatusTooManyRequests, w.Code)
+			assert.Contains(t, w.Body.String(), "query rate limit exceeded")
+		})
+	}
+}
+
+func TestRateLimiter_WarningDisabled(t *testing.T) {
+	config := DefaultRateLimitConfig()
+	config.QueryRequestsPerMinute = 2
+	config.WarningThreshold = 0
+	router := newRateLimitedRouter(NewRateLimiter(newCountingRedis(t), config))
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get(RateLimitWarningHeader))
+	}
+}
@@ -2,18 +2,47 @@ package security
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strconv"
+	"math"
 	"time"
 
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/redis/go-redis/v9"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// ErrRateLimiterUnavailable is wrapped by errors caused by Redis rather than by a client exceeding a limit
+var ErrRateLimiterUnavailable = errors.New("rate limiter backend unavailable")
+
+// RateLimitFailureMode decides what happens to requests while Redis is unreachable
+type RateLimitFailureMode string
+
+const (
+	// FailOpen allows requests, subject to the local fallback limit when one is configured
+	FailOpen RateLimitFailureMode = "open"
+	// FailClosed rejects every request until Redis is reachable again
+	FailClosed RateLimitFailureMode = "closed"
+)
+
+// RateLimitAlgorithm selects how request counts are kept in Redis
+type RateLimitAlgorithm string
+
+const (
+	// SlidingWindowLog stores one sorted-set member per request and counts exactly
+	SlidingWindowLog RateLimitAlgorithm = "sliding_window_log"
+	// SlidingWindowCounter keeps one counter per fixed window and weights the
+	// previous window by its overlap, using far less memory at a small accuracy cost
+	SlidingWindowCounter RateLimitAlgorithm = "sliding_window_counter"
 )
 
 // RateLimiter implements rate limiting for GraphQL operations
 type RateLimiter struct {
-	redis  *redis.Client
-	config RateLimitConfig
+	redis    redis.UniversalClient
+	config   RateLimitConfig
+	fallback *localLimiter
+	audit    *AuditLogger
+	exempt   *rateLimitExemptions
 }
 
 // RateLimitConfig holds rate limiting configuration
@@ -36,6 +65,25 @@ type RateLimitConfig struct {
 	
 	// Burst allowance
 	BurstSize int
+	
+	// Counting algorithm; an empty value uses SlidingWindowLog
+	Algorithm RateLimitAlgorithm
+	
+	// Behaviour while Redis is unreachable; an empty mode fails open
+	FailureMode RateLimitFailureMode
+	
+	// Per-client limit enforced in memory while failing open; 0 disables it.
+	// Each instance counts on its own, so keep it coarser than the Redis limits
+	FallbackRequestsPerMinute int
+	
+	// Fraction of a limit at which requests still pass but carry an
+	// X-RateLimit-Warning header; 0 disables the warning
+	WarningThreshold float64
+	
+	// Trusted clients that bypass every limit: addresses or CIDR ranges, and
+	// API keys sent in the X-API-Key header
+	ExemptIPs     []string
+	ExemptAPIKeys []string
 }
 
 // DefaultRateLimitConfig returns default rate limiting configuration
@@ -50,15 +98,29 @@ func DefaultRateLimitConfig() RateLimitConfig {
 		MutationRequestsPerMinute: 20,
 		QueryRequestsPerMinute:    200,
 		BurstSize:                5,
+		Algorithm:                 SlidingWindowLog,
+		FailureMode:               FailOpen,
+		FallbackRequestsPerMinute: 60,
+		WarningThreshold:          0.8,
 	}
 }
 
 // NewRateLimiter creates a new rate limiter
-func NewRateLimiter(redisClient *redis.Client, config RateLimitConfig) *RateLimiter {
-	return &RateLimiter{
+func NewRateLimiter(redisClient redis.UniversalClient, config RateLimitConfig) *RateLimiter {
+	limiter := &RateLimiter{
 		redis:  redisClient,
 		config: config,
+		exempt: newRateLimitExemptions(config.ExemptIPs, config.ExemptAPIKeys),
+	}
+	if config.FallbackRequestsPerMinute > 0 {
+		limiter.fallback = newLocalLimiter(config.FallbackRequestsPerMinute)
 	}
+	return limiter
+}
+
+// SetAuditLogger records every rate-limited operation in logger
+func (r *RateLimiter) SetAuditLogger(logger *AuditLogger) {
+	r.audit = logger
 }
 
 // ExtensionName returns the name of this extension
@@ -76,15 +138,20 @@ func (r *RateLimiter) InterceptOperation(ctx context.Context, next graphql.Opera
 	oc := graphql.GetOperationContext(ctx)
 	
 	// Extract client information
-	clientIP := r.getClientIP(ctx)
-	userID := r.getUserID(ctx)
+	clientIP := clientIPFromContext(ctx)
+	userID := userIDFromContext(ctx)
 	operationType := string(oc.Operation.Operation)
 	
 	// Check various rate limits
 	if err := r.checkRateLimits(ctx, clientIP, userID, operationType); err != nil {
+		if r.audit != nil {
+			r.audit.LogRejection(ctx, auditUser(ctx), oc.OperationName, "rate_limit", err, map[string]interface{}{
+				"operation_type": operationType,
+			})
+		}
 		return func(ctx context.Context) *graphql.Response {
 			return &graphql.Response{
-				Errors: []*graphql.Error{
+				Errors: gqlerror.List{
 					{
 						Message: err.Error(),
 						Extensions: map[string]interface{}{
@@ -100,8 +167,42 @@ func (r *RateLimiter) InterceptOperation(ctx context.Context, next graphql.Opera
 	return next(ctx)
 }
 
-// checkRateLimits checks all applicable rate limits
+// checkRateLimits checks all applicable rate limits, applying the failure
+// mode when Redis cannot be reached. Exempt clients skip every check.
 func (r *RateLimiter) checkRateLimits(ctx context.Context, clientIP, userID, operationType string) error {
+	if r.exempt.exempt(ctx, clientIP) {
+		return nil
+	}
+	
+	err := r.checkRedisLimits(ctx, clientIP, userID, operationType)
+	if err == nil || !errors.Is(err, ErrRateLimiterUnavailable) {
+		return err
+	}
+	
+	if r.config.FailureMode == FailClosed {
+		return fmt.Errorf("rate limiting unavailable, rejecting request: %w", err)
+	}
+	
+	if r.fallback != nil && !r.fallback.Allow(fallbackKey(clientIP, userID)) {
+		return fmt.Errorf("local rate limit of %d requests per minute exceeded", r.config.FallbackRequestsPerMinute)
+	}
+	
+	return nil
+}
+
+// fallbackKey picks the client identity the local limiter counts against
+func fallbackKey(clientIP, userID string) string {
+	if userID != "" {
+		return "user:" + userID
+	}
+	if clientIP != "" {
+		return "ip:" + clientIP
+	}
+	return "global"
+}
+
+// checkRedisLimits checks all applicable rate limits in Redis
+func (r *RateLimiter) checkRedisLimits(ctx context.Context, clientIP, userID, operationType string) error {
 	now := time.Now()
 	
 	// Check global rate limits
@@ -155,8 +256,52 @@ func (r *RateLimiter) checkRateLimits(ctx context.Context, clientIP, userID, ope
 	return nil
 }
 
-// checkLimit checks a specific rate limit using sliding window algorithm
+// checkLimit checks a specific rate limit using the configured algorithm
 func (r *RateLimiter) checkLimit(ctx context.Context, key string, limit int, window time.Duration, now time.Time) error {
+	if r.config.Algorithm == SlidingWindowCounter {
+		return r.checkLimitCounter(ctx, key, limit, window, now)
+	}
+	return r.checkLimitLog(ctx, key, limit, window, now)
+}
+
+// checkLimitCounter checks a rate limit with two fixed-window counters,
+// weighting the previous window by how much of it still overlaps
+func (r *RateLimiter) checkLimitCounter(ctx context.Context, key string, limit int, window time.Duration, now time.Time) error {
+	windowStart := now.Truncate(window)
+	currentKey := fmt.Sprintf("%s:%d", key, windowStart.Unix())
+	previousKey := fmt.Sprintf("%s:%d", key, windowStart.Add(-window).Unix())
+	
+	pipe := r.redis.Pipeline()
+	currentCmd := pipe.Incr(ctx, currentKey)
+	pipe.Expire(ctx, currentKey, 2*window)
+	previousCmd := pipe.Get(ctx, previousKey)
+	
+	// A missing previous window is not a failure
+	_, err := pipe.Exec(ctx)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("%w: %v", ErrRateLimiterUnavailable, err)
+	}
+	
+	previous, _ := previousCmd.Int64()
+	// Exclude the request just counted, matching the log algorithm
+	count := slidingWindowCount(previous, currentCmd.Val()-1, now.Sub(windowStart), window)
+	if count >= float64(limit) {
+		return fmt.Errorf("rate limit of %d requests per %v exceeded", limit, window)
+	}
+	
+	r.warnIfNearLimit(ctx, int64(math.Ceil(count))+1, limit, window)
+	return nil
+}
+
+// slidingWindowCount estimates the requests in the window ending now from the
+// previous and current fixed-window counts
+func slidingWindowCount(previous, current int64, elapsed, window time.Duration) float64 {
+	overlap := 1 - float64(elapsed)/float64(window)
+	return float64(previous)*overlap + float64(current)
+}
+
+// checkLimitLog checks a rate limit with a sliding window log
+func (r *RateLimiter) checkLimitLog(ctx context.Context, key string, limit int, window time.Duration, now time.Time) error {
 	// Use Redis sorted sets for sliding window rate limiting
 	windowStart := now.Add(-window)
 	windowStartScore := float64(windowStart.UnixNano())
@@ -181,7 +326,7 @@ func (r *RateLimiter) checkLimit(ctx context.Context, key string, limit int, win
 	
 	_, err := pipe.Exec(ctx)
 	if err != nil {
-		return fmt.Errorf("rate limit check failed: %w", err)
+		return fmt.Errorf("%w: %v", ErrRateLimiterUnavailable, err)
 	}
 	
 	count := countCmd.Val()
@@ -189,13 +334,14 @@ func (r *RateLimiter) checkLimit(ctx context.Context, key string, limit int, win
 		return fmt.Errorf("rate limit of %d requests per %v exceeded", limit, window)
 	}
 	
+	r.warnIfNearLimit(ctx, count+1, limit, window)
 	return nil
 }
 
-// getClientIP extracts client IP from context
-func (r *RateLimiter) getClientIP(ctx context.Context) string {
+// clientIPFromContext extracts client IP from context
+func clientIPFromContext(ctx context.Context) string {
 	// Try to get IP from various context keys
-	if ip, ok := ctx.Value("client_ip").(string); ok {
+	if ip := ClientIPFromContext(ctx); ip != "" {
 		return ip
 	}
 	if ip, ok := ctx.Value("remote_addr").(string); ok {
@@ -204,8 +350,11 @@ func (r *RateLimiter) getClientIP(ctx context.Context) string {
 	return ""
 }
 
-// getUserID extracts user ID from context
-func (r *RateLimiter) getUserID(ctx context.Context) string {
+// userIDFromContext extracts user ID from context
+func userIDFromContext(ctx context.Context) string {
+	if user := GetUserFromContext(ctx); user != nil {
+		return user.ID
+	}
 	if userID, ok := ctx.Value("user_id").(string); ok {
 		return userID
 	}
@@ -305,11 +454,19 @@ func (r *RateLimiter) InterceptField(ctx context.Context, next graphql.Resolver)
 	
 	// Apply field-specific rate limiting for expensive operations
 	if r.isExpensiveField(fc.Field.Name) {
-		clientIP := r.getClientIP(ctx)
-		userID := r.getUserID(ctx)
+		clientIP := clientIPFromContext(ctx)
+		userID := userIDFromContext(ctx)
+		if r.exempt.exempt(ctx, clientIP) {
+			return next(ctx)
+		}
 		
 		fieldKey := fmt.Sprintf("field:%s:%s:%s", fc.Field.Name, clientIP, userID)
-		if err := r.checkLimit(ctx, fieldKey, 10, time.Minute, time.Now()); err != nil {
+		err := r.checkLimit(ctx, fieldKey, 10, time.Minute, time.Now())
+		// The operation-level check already applied the local fallback
+		if errors.Is(err, ErrRateLimiterUnavailable) && r.config.FailureMode != FailClosed {
+			err = nil
+		}
+		if err != nil {
 			return nil, fmt.Errorf("field rate limit exceeded for %s: %w", fc.Field.Name, err)
 		}
 	}
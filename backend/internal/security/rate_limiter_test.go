@@ -0,0 +1,130 @@
+package security
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unreachableRedis returns a client whose commands all fail fast
+func unreachableRedis(t *testing.T) redis.UniversalClient {
+	client := redis.NewClient(&redis.Options{
+		Addr:        "127.0.0.1:1",
+		DialTimeout: 50 * time.Millisecond,
+		MaxRetries:  -1,
+	})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestRateLimiter_RedisDown_FailOpenAllows(t *testing.T) {
+	config := DefaultRateLimitConfig()
+	config.FailureMode = FailOpen
+	config.FallbackRequestsPerMinute = 0
+	limiter := NewRateLimiter(unreachableRedis(t), config)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, limiter.checkRateLimits(context.Background(), "10.0.0.1", "", "query"))
+	}
+}
+
+func TestRateLimiter_RedisDown_FailClosedRejects(t *testing.T) {
+	config := DefaultRateLimitConfig()
+	config.FailureMode = FailClosed
+	limiter := NewRateLimiter(unreachableRedis(t), config)
+
+	err := limiter.checkRateLimits(context.Background(), "10.0.0.1", "", "query")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrRateLimiterUnavailable)
+}
+
+func TestRateLimiter_RedisDown_LocalFallbackEnforcesLimit(t *testing.T) {
+	config := DefaultRateLimitConfig()
+	config.FailureMode = FailOpen
+	config.FallbackRequestsPerMinute = 2
+	limiter := NewRateLimiter(unreachableRedis(t), config)
+	ctx := context.Background()
+
+	require.NoError(t, limiter.checkRateLimits(ctx, "10.0.0.1", "", "query"))
+	require.NoError(t, limiter.checkRateLimits(ctx, "10.0.0.1", "", "query"))
+
+	err := limiter.checkRateLimits(ctx, "10.0.0.1", "", "query")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrRateLimiterUnavailable)
+
+	// Other clients have their own bucket
+	assert.NoError(t, limiter.checkRateLimits(ctx, "10.0.0.2", "", "query"))
+}
+
+func TestLocalLimiter_Refills(t *testing.T) {
+	now := time.Now()
+	limiter := newLocalLimiter(60)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 60; i++ {
+		require.True(t, limiter.Allow("ip:10.0.0.1"))
+	}
+	assert.False(t, limiter.Allow("ip:10.0.0.1"))
+
+	// One token per second at 60 per minute
+	now = now.Add(time.Second)
+	assert.True(t, limiter.Allow("ip:10.0.0.1"))
+	assert.False(t, limiter.Allow("ip:10.0.0.1"))
+}
+
+func TestSlidingWindowCount(t *testing.T) {
+	window := time.Minute
+
+	assert.Equal(t, 10.0, slidingWindowCount(20, 0, 30*time.Second, window))
+	assert.Equal(t, 25.0, slidingWindowCount(20, 5, 0, window))
+	assert.Equal(t, 5.0, slidingWindowCount(20, 5, window, window))
+}
+
+// TestSlidingWindowCounter_StaysCloseToLog replays a request pattern through
+// exact log counting and the two-counter estimate and compares them
+func TestSlidingWindowCounter_StaysCloseToLog(t *testing.T) {
+	window := time.Minute
+	rng := rand.New(rand.NewSource(1))
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Roughly 3 requests per second for 10 minutes with jittered spacing
+	var times []time.Time
+	for at := start; at.Before(start.Add(10 * time.Minute)); {
+		times = append(times, at)
+		at = at.Add(time.Duration(200+rng.Intn(267)) * time.Millisecond)
+	}
+
+	counters := map[int64]int64{}
+	maxError := 0.0
+	for i, now := range times {
+		windowStart := now.Truncate(window)
+
+		// Exact count of earlier requests in (now-window, now]
+		exact := 0
+		for j := i - 1; j >= 0 && now.Sub(times[j]) < window; j-- {
+			exact++
+		}
+
+		estimate := slidingWindowCount(
+			counters[windowStart.Add(-window).Unix()],
+			counters[windowStart.Unix()],
+			now.Sub(windowStart), window,
+		)
+		counters[windowStart.Unix()]++
+
+		// Skip the first window, where the log has no history to compare against
+		if now.Sub(start) < window {
+			continue
+		}
+		relative := math.Abs(estimate-float64(exact)) / float64(exact)
+		maxError = math.Max(maxError, relative)
+	}
+
+	assert.Less(t, maxError, 0.1, "counter estimate drifted %.1f%% from the exact count", maxError*100)
+}
@@ -0,0 +1,90 @@
+package security
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestSizeLimiter_RejectsLongQuery(t *testing.T) {
+	limiter := NewRequestSizeLimiter(RequestSizeConfig{MaxQueryLength: 50, MaxVariablesBytes: 1024})
+
+	assert.Nil(t, limiter.MutateOperationParameters(context.Background(), &graphql.RawParams{Query: "{ me { id } }"}))
+
+	query := "{ me { id " + strings.Repeat("name ", 20) + "} }"
+	gqlErr := limiter.MutateOperationParameters(context.Background(), &graphql.RawParams{Query: query})
+	require.NotNil(t, gqlErr)
+	assert.Equal(t, "REQUEST_TOO_LARGE", gqlErr.Extensions["code"])
+	assert.Equal(t, "query", gqlErr.Extensions["part"])
+}
+
+func TestRequestSizeLimiter_RejectsLargeVariables(t *testing.T) {
+	limiter := NewRequestSizeLimiter(RequestSizeConfig{MaxQueryLength: 1000, MaxVariablesBytes: 64})
+	query := "mutation($content: String!) { addComment(postId: \"1\", content: $content) { id } }"
+
+	small := &graphql.RawParams{Query: query, Variables: map[string]interface{}{"content": "hi"}}
+	assert.Nil(t, limiter.MutateOperationParameters(context.Background(), small))
+
+	large := &graphql.RawParams{Query: query, Variables: map[string]interface{}{"content": strings.Repeat("x", 100)}}
+	gqlErr := limiter.MutateOperationParameters(context.Background(), large)
+	require.NotNil(t, gqlErr)
+	assert.Equal(t, "REQUEST_TOO_LARGE", gqlErr.Extensions["code"])
+	assert.Equal(t, "variables", gqlErr.Extensions["part"])
+}
+
+func TestRequestSizeLimiter_ZeroDisables(t *testing.T) {
+	limiter := NewRequestSizeLimiter(RequestSizeConfig{})
+	params := &graphql.RawParams{
+		Query:     strings.Repeat(" ", 100000) + "{ me { id } }",
+		Variables: map[string]interface{}{"blob": strings.Repeat("x", 100000)},
+	}
+
+	assert.Nil(t, limiter.MutateOperationParameters(context.Background(), params))
+}
+
+func TestRequestSizeConfigFromEnv(t *testing.T) {
+	t.Setenv("GRAPHQL_MAX_QUERY_LENGTH", "500")
+	t.Setenv("GRAPHQL_MAX_VARIABLES_BYTES", "not-a-number")
+	t.Setenv("GRAPHQL_MAX_BODY_BYTES", "0")
+
+	config := RequestSizeConfigFromEnv()
+	assert.Equal(t, 500, config.MaxQueryLength)
+	assert.Equal(t, DefaultRequestSizeConfig().MaxVariablesBytes, config.MaxVariablesBytes)
+	assert.Equal(t, 0, config.MaxBodyBytes)
+}
+
+func TestLimitRequestBody_RejectsDeclaredLengthBeforeReading(t *testing.T) {
+	called := false
+	handler := LimitRequestBody(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}), RequestSizeConfig{MaxBodyBytes: 10})
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{ me { id } }"}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Contains(t, rec.Body.String(), "REQUEST_TOO_LARGE")
+}
+
+func TestLimitRequestBody_StopsReadingUndeclaredBodyAtLimit(t *testing.T) {
+	var readErr error
+	handler := LimitRequestBody(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, readErr = io.ReadAll(r.Body)
+	}), RequestSizeConfig{MaxBodyBytes: 10})
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(strings.Repeat("x", 100)))
+	req.ContentLength = -1
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var maxBytesErr *http.MaxBytesError
+	assert.ErrorAs(t, readErr, &maxBytesErr)
+}
@@ -14,6 +14,7 @@ const (
 	PostAddedEvent    EventType = "POST_ADDED"
 	PostUpdatedEvent  EventType = "POST_UPDATED"
 	CommentAddedEvent EventType = "COMMENT_ADDED"
+	NotificationEvent EventType = "NOTIFICATION"
 )
 
 // Event represents a subscription event
@@ -22,41 +23,55 @@ type Event struct {
 	PostID  string
 	Post    *model.Post
 	Comment *model.Comment
+	// UserID routes notification events to their recipient
+	UserID       string
+	Notification *model.Notification
 }
 
 // Subscriber represents a subscription channel
 type Subscriber struct {
-	ID      string
-	Channel chan *Event
-	Filter  func(*Event) bool
+	ID        string
+	EventType EventType
+	Channel   chan *Event
+	Filter    func(*Event) bool
 }
 
 // Manager handles GraphQL subscriptions
 type Manager struct {
 	subscribers map[string]*Subscriber
 	mutex       sync.RWMutex
+	metrics     *Metrics
 }
 
 // NewManager creates a new subscription manager
 func NewManager() *Manager {
 	return &Manager{
 		subscribers: make(map[string]*Subscriber),
+		metrics:     NewMetrics(),
 	}
 }
 
-// Subscribe adds a new subscriber
-func (m *Manager) Subscribe(ctx context.Context, id string, filter func(*Event) bool) <-chan *Event {
+// Subscribe adds a new subscriber for events of the given type
+func (m *Manager) Subscribe(ctx context.Context, id string, eventType EventType, filter func(*Event) bool) <-chan *Event {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
 	ch := make(chan *Event, 10) // Buffer to prevent blocking
 	subscriber := &Subscriber{
-		ID:      id,
-		Channel: ch,
-		Filter:  filter,
+		ID:        id,
+		EventType: eventType,
+		Channel:   ch,
+		Filter:    filter,
+	}
+
+	// Replacing a subscriber with the same ID must not leak its gauge
+	if existing, exists := m.subscribers[id]; exists {
+		close(existing.Channel)
+		m.metrics.add(m.metrics.active, existing.EventType, -1)
 	}
 
 	m.subscribers[id] = subscriber
+	m.metrics.add(m.metrics.active, eventType, 1)
 
 	// Clean up when context is cancelled
 	go func() {
@@ -73,8 +88,9 @@ func (m *Manager) Unsubscribe(id string) {
 	defer m.mutex.Unlock()
 
 	if subscriber, exists := m.subscribers[id]; exists {
-		close(subscriber.Channel)
+		m.metrics.add(m.metrics.active, subscriber.EventType, -1)
 		delete(m.subscribers, id)
+		close(subscriber.Channel)
 	}
 }
 
@@ -83,7 +99,13 @@ func (m *Manager) Publish(event *Event) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
+	m.metrics.add(m.metrics.published, event.Type, 1)
+
 	for _, subscriber := range m.subscribers {
+		if subscriber.EventType != event.Type {
+			continue
+		}
+
 		// Apply filter if provided
 		if subscriber.Filter != nil && !subscriber.Filter(event) {
 			continue
@@ -92,9 +114,10 @@ func (m *Manager) Publish(event *Event) {
 		// Non-blocking send
 		select {
 		case subscriber.Channel <- event:
+			m.metrics.add(m.metrics.delivered, event.Type, 1)
 		default:
-			// Channel is full, skip this subscriber
-			// In production, you might want to log this or handle it differently
+			// Channel is full, skip this slow subscriber
+			m.metrics.add(m.metrics.dropped, event.Type, 1)
 		}
 	}
 }
@@ -125,9 +148,23 @@ func (m *Manager) PublishCommentAdded(comment *model.Comment) {
 	})
 }
 
+// PublishNotification publishes a notification event for its recipient
+func (m *Manager) PublishNotification(notification *model.Notification) {
+	m.Publish(&Event{
+		Type:         NotificationEvent,
+		UserID:       notification.UserID.String(),
+		Notification: notification,
+	})
+}
+
 // GetSubscriberCount returns the number of active subscribers
 func (m *Manager) GetSubscriberCount() int {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 	return len(m.subscribers)
+}
+
+// Metrics returns a snapshot of the subscription metrics
+func (m *Manager) Metrics() MetricsSnapshot {
+	return m.metrics.Snapshot()
 }
\ No newline at end of file
@@ -0,0 +1,95 @@
+package subscription
+
+import (
+	"context"
+	"testing"
+
+	"backend/internal/graph/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager_SubscribeAndUnsubscribeMoveGauges(t *testing.T) {
+	m := NewManager()
+	ctx := context.Background()
+
+	m.Subscribe(ctx, "a", PostAddedEvent, nil)
+	m.Subscribe(ctx, "b", PostAddedEvent, nil)
+	m.Subscribe(ctx, "c", CommentAddedEvent, nil)
+
+	metrics := m.Metrics()
+	assert.Equal(t, int64(3), metrics.ActiveSubscriptions)
+	assert.Equal(t, int64(2), metrics.ActiveByEventType[PostAddedEvent])
+	assert.Equal(t, int64(1), metrics.ActiveByEventType[CommentAddedEvent])
+
+	m.Unsubscribe("a")
+	m.Unsubscribe("c")
+	m.Unsubscribe("c") // already gone
+
+	metrics = m.Metrics()
+	assert.Equal(t, int64(1), metrics.ActiveSubscriptions)
+	assert.Equal(t, int64(1), metrics.ActiveByEventType[PostAddedEvent])
+	assert.Equal(t, int64(0), metrics.ActiveByEventType[CommentAddedEvent])
+}
+
+func TestManager_ReplacingSubscriberKeepsGaugeAccurate(t *testing.T) {
+	m := NewManager()
+	ctx := context.Background()
+
+	m.Subscribe(ctx, "a", PostAddedEvent, nil)
+	m.Subscribe(ctx, "a", PostUpdatedEvent, nil)
+
+	metrics := m.Metrics()
+	assert.Equal(t, int64(1), metrics.ActiveSubscriptions)
+	assert.Equal(t, int64(0), metrics.ActiveByEventType[PostAddedEvent])
+	assert.Equal(t, int64(1), metrics.ActiveByEventType[PostUpdatedEvent])
+}
+
+func TestManager_PublishCountsDeliveredEvents(t *testing.T) {
+	m := NewManager()
+	ctx := context.Background()
+	post := &model.Post{ID: uuid.New()}
+
+	added := m.Subscribe(ctx, "added", PostAddedEvent, nil)
+	m.Subscribe(ctx, "other-post", PostUpdatedEvent, func(e *Event) bool { return e.PostID == "elsewhere" })
+	m.Subscribe(ctx, "comments", CommentAddedEvent, nil)
+
+	m.PublishPostAdded(post)
+	m.PublishPostUpdated(post)
+
+	metrics := m.Metrics()
+	assert.Equal(t, int64(2), metrics.EventsPublished)
+	assert.Equal(t, int64(1), metrics.EventsDelivered)
+	assert.Equal(t, int64(0), metrics.EventsDropped)
+	assert.Equal(t, post, (<-added).Post)
+}
+
+func TestManager_PublishCountsDroppedEventsForSlowConsumers(t *testing.T) {
+	m := NewManager()
+	ctx := context.Background()
+
+	m.Subscribe(ctx, "slow", PostAddedEvent, nil)
+
+	// The subscriber buffers 10 events and never reads
+	for i := 0; i < 15; i++ {
+		m.PublishPostAdded(&model.Post{ID: uuid.New()})
+	}
+
+	metrics := m.Metrics()
+	assert.Equal(t, int64(15), metrics.EventsPublished)
+	assert.Equal(t, int64(10), metrics.EventsDelivered)
+	assert.Equal(t, int64(5), metrics.EventsDropped)
+}
+
+func TestManager_ContextCancellationUnsubscribes(t *testing.T) {
+	m := NewManager()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := m.Subscribe(ctx, "a", PostAddedEvent, nil)
+	cancel()
+
+	// The channel is closed once the cleanup goroutine unsubscribes
+	for range ch {
+	}
+	assert.Equal(t, int64(0), m.Metrics().ActiveSubscriptions)
+}
@@ -0,0 +1,62 @@
+package subscription
+
+import "sync"
+
+// Metrics tracks subscription load for operators
+type Metrics struct {
+	mu        sync.Mutex
+	active    map[EventType]int64
+	published map[EventType]int64
+	delivered map[EventType]int64
+	dropped   map[EventType]int64
+}
+
+// MetricsSnapshot is a point-in-time copy of the subscription metrics
+type MetricsSnapshot struct {
+	ActiveSubscriptions int64               `json:"activeSubscriptions"`
+	ActiveByEventType   map[EventType]int64 `json:"activeByEventType"`
+	EventsPublished     int64               `json:"eventsPublished"`
+	EventsDelivered     int64               `json:"eventsDelivered"`
+	EventsDropped       int64               `json:"eventsDropped"`
+}
+
+// NewMetrics creates an empty metrics set
+func NewMetrics() *Metrics {
+	return &Metrics{
+		active:    make(map[EventType]int64),
+		published: make(map[EventType]int64),
+		delivered: make(map[EventType]int64),
+		dropped:   make(map[EventType]int64),
+	}
+}
+
+// add increments a counter or gauge for an event type
+func (m *Metrics) add(values map[EventType]int64, eventType EventType, delta int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	values[eventType] += delta
+}
+
+// Snapshot returns the current gauge and counter values
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := MetricsSnapshot{
+		ActiveByEventType: make(map[EventType]int64, len(m.active)),
+	}
+	for eventType, n := range m.active {
+		snapshot.ActiveSubscriptions += n
+		snapshot.ActiveByEventType[eventType] = n
+	}
+	for _, n := range m.published {
+		snapshot.EventsPublished += n
+	}
+	for _, n := range m.delivered {
+		snapshot.EventsDelivered += n
+	}
+	for _, n := range m.dropped {
+		snapshot.EventsDropped += n
+	}
+	return snapshot
+}
@@ -0,0 +1,217 @@
+// Package userimport creates users in bulk from a CSV file. Imported users
+// have no password; each is mailed a one-time password setup token instead.
+package userimport
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"backend/internal/auth"
+	"backend/internal/clock"
+	"backend/internal/graph/model"
+	"backend/internal/graph/validation"
+	"backend/internal/ids"
+	"backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// DefaultBatchSize is how many users are created per transaction
+const DefaultBatchSize = 100
+
+// Row is one user read from the CSV file
+type Row struct {
+	Line  int
+	Email string
+	Name  string
+}
+
+// Status is the outcome of importing one row
+type Status string
+
+const (
+	StatusCreated Status = "created"
+	StatusSkipped Status = "skipped"
+	StatusFailed  Status = "failed"
+)
+
+// Result records what happened to one row
+type Result struct {
+	Line   int
+	Email  string
+	Status Status
+	Reason string
+}
+
+// Summary collects the result of every row
+type Summary struct {
+	Results []Result
+}
+
+// Count returns how many rows ended with status
+func (s *Summary) Count(status Status) int {
+	count := 0
+	for _, result := range s.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *Summary) add(line int, email string, status Status, reason string) {
+	s.Results = append(s.Results, Result{Line: line, Email: email, Status: status, Reason: reason})
+}
+
+// ParseCSV reads email,name rows. A leading header row naming those columns
+// is skipped. Rows that fail validation are returned as failed results, and
+// repeats of an email already read are returned as skipped, so only rows
+// worth importing come back as Rows.
+func ParseCSV(r io.Reader) ([]Row, []Result, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	validator := validation.NewValidator()
+	seen := make(map[string]int)
+	var rows []Row
+	var rejected []Result
+
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		if line == 1 && isHeader(record) {
+			continue
+		}
+		if len(record) != 2 {
+			rejected = append(rejected, Result{Line: line, Status: StatusFailed, Reason: fmt.Sprintf("expected 2 columns, got %d", len(record))})
+			continue
+		}
+
+		email := validation.NormalizeEmail(record[0])
+		name := strings.TrimSpace(record[1])
+		if err := validator.ValidateEmail(email); err != nil {
+			rejected = append(rejected, Result{Line: line, Email: email, Status: StatusFailed, Reason: err.Error()})
+			continue
+		}
+		if err := validator.ValidateName(name); err != nil {
+			rejected = append(rejected, Result{Line: line, Email: email, Status: StatusFailed, Reason: err.Error()})
+			continue
+		}
+		if first, ok := seen[email]; ok {
+			rejected = append(rejected, Result{Line: line, Email: email, Status: StatusSkipped, Reason: fmt.Sprintf("duplicate of line %d", first)})
+			continue
+		}
+
+		seen[email] = line
+		rows = append(rows, Row{Line: line, Email: email, Name: name})
+	}
+
+	return rows, rejected, nil
+}
+
+// isHeader reports whether record is an email,name header row
+func isHeader(record []string) bool {
+	return len(record) == 2 &&
+		strings.EqualFold(strings.TrimSpace(record[0]), "email") &&
+		strings.EqualFold(strings.TrimSpace(record[1]), "name")
+}
+
+// Importer creates users in batches, one transaction per batch, and mails
+// each created user their password setup token
+type Importer struct {
+	Users     repository.PasswordSetupRepository
+	Mailer    auth.Mailer
+	TokenTTL  time.Duration
+	BatchSize int
+	Now       clock.Clock
+	// NewID generates the IDs of created users; ids.New is used when nil
+	NewID ids.Generator
+}
+
+// Import creates the users in rows and records each outcome in summary.
+// Users whose email is already registered are skipped. When a batch fails
+// none of its users are created and all of them are reported as failed.
+func (im *Importer) Import(ctx context.Context, rows []Row, summary *Summary) {
+	batchSize := im.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	for start := 0; start < len(rows); start += batchSize {
+		end := min(start+batchSize, len(rows))
+		im.importBatch(ctx, rows[start:end], summary)
+	}
+}
+
+// importBatch creates one batch of users in a single transaction
+func (im *Importer) importBatch(ctx context.Context, rows []Row, summary *Summary) {
+	now := im.now()
+	users := make([]repository.ImportedUser, len(rows))
+	tokens := make([]string, len(rows))
+
+	for i, row := range rows {
+		user := &model.User{ID: im.newID(), Email: row.Email, Name: row.Name, CreatedAt: now, UpdatedAt: now}
+		token, record, err := auth.NewPasswordSetupToken(user.ID, now, im.TokenTTL)
+		if err != nil {
+			im.failBatch(rows, summary, err)
+			return
+		}
+		users[i] = repository.ImportedUser{User: user, Token: record}
+		tokens[i] = token
+	}
+
+	created, err := im.Users.CreateUsers(ctx, users)
+	if err != nil {
+		im.failBatch(rows, summary, err)
+		return
+	}
+
+	for i, row := range rows {
+		if !created[i] {
+			summary.add(row.Line, row.Email, StatusSkipped, "email already registered")
+			continue
+		}
+
+		data := map[string]string{
+			"Token":     tokens[i],
+			"ExpiresAt": users[i].Token.ExpiresAt.UTC().Format(time.RFC3339),
+		}
+		if err := im.Mailer.Send(ctx, row.Email, auth.TemplatePasswordSetup, data); err != nil {
+			// The user exists, so a rerun would skip them; say so rather than hide it
+			summary.add(row.Line, row.Email, StatusFailed, fmt.Sprintf("user created but setup email failed: %v", err))
+			continue
+		}
+		summary.add(row.Line, row.Email, StatusCreated, "")
+	}
+}
+
+// failBatch reports every row of a batch that was not created
+func (im *Importer) failBatch(rows []Row, summary *Summary, err error) {
+	for _, row := range rows {
+		summary.add(row.Line, row.Email, StatusFailed, err.Error())
+	}
+}
+
+func (im *Importer) now() time.Time {
+	if im.Now != nil {
+		return im.Now()
+	}
+	return clock.Now()
+}
+
+func (im *Importer) newID() uuid.UUID {
+	if im.NewID != nil {
+		return im.NewID()
+	}
+	return ids.New()
+}
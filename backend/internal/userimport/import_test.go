@@ -0,0 +1,173 @@
+package userimport
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/auth"
+	"backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUsers struct {
+	repository.PasswordSetupRepository
+	existing map[string]bool
+	err      error
+	batches  [][]repository.ImportedUser
+}
+
+func (f *fakeUsers) CreateUsers(ctx context.Context, users []repository.ImportedUser) ([]bool, error) {
+	f.batches = append(f.batches, users)
+	if f.err != nil {
+		return nil, f.err
+	}
+	created := make([]bool, len(users))
+	for i, user := range users {
+		created[i] = !f.existing[user.User.Email]
+	}
+	return created, nil
+}
+
+type sentMail struct {
+	to   string
+	data map[string]string
+}
+
+type fakeMailer struct {
+	sent   []sentMail
+	failTo string
+}
+
+func (m *fakeMailer) Send(ctx context.Context, to, template string, data map[string]string) error {
+	if to == m.failTo {
+		return errors.New("smtp unavailable")
+	}
+	m.sent = append(m.sent, sentMail{to: to, data: data})
+	return nil
+}
+
+func TestParseCSV(t *testing.T) {
+	input := strings.Join([]string{
+		"email,name",
+		"Alice@Example.com, Alice",
+		"bob@example.com",
+		"not-an-email,Bob",
+		"carol@example.com,",
+		"alice@example.com,Alice Again",
+		"dave@example.com,Dave",
+	}, "\n")
+
+	rows, rejected, err := ParseCSV(strings.NewReader(input))
+	require.NoError(t, err)
+
+	assert.Equal(t, []Row{
+		{Line: 2, Email: "alice@example.com", Name: "Alice"},
+		{Line: 7, Email: "dave@example.com", Name: "Dave"},
+	}, rows)
+
+	require.Len(t, rejected, 4)
+	assert.Equal(t, 3, rejected[0].Line)
+	assert.Equal(t, StatusFailed, rejected[0].Status)
+	assert.Equal(t, 4, rejected[1].Line)
+	assert.Equal(t, StatusFailed, rejected[1].Status)
+	assert.Equal(t, 5, rejected[2].Line)
+	assert.Equal(t, StatusFailed, rejected[2].Status)
+	assert.Equal(t, Result{Line: 6, Email: "alice@example.com", Status: StatusSkipped, Reason: "duplicate of line 2"}, rejected[3])
+}
+
+func TestParseCSV_WithoutHeader(t *testing.T) {
+	rows, rejected, err := ParseCSV(strings.NewReader("alice@example.com,Alice\n"))
+	require.NoError(t, err)
+	assert.Empty(t, rejected)
+	assert.Equal(t, []Row{{Line: 1, Email: "alice@example.com", Name: "Alice"}}, rows)
+}
+
+func TestImporter_CreatesUsersAndMailsTokens(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	users := &fakeUsers{}
+	mailer := &fakeMailer{}
+	importer := &Importer{Users: users, Mailer: mailer, TokenTTL: time.Hour, Now: func() time.Time { return now }}
+
+	summary := &Summary{}
+	importer.Import(context.Background(), []Row{{Line: 1, Email: "alice@example.com", Name: "Alice"}}, summary)
+
+	require.Len(t, users.batches, 1)
+	imported := users.batches[0][0]
+	assert.Equal(t, "alice@example.com", imported.User.Email)
+	assert.Empty(t, imported.User.PasswordHash)
+	assert.Equal(t, imported.User.ID, imported.Token.UserID)
+	assert.Equal(t, now.Add(time.Hour), imported.Token.ExpiresAt)
+
+	require.Len(t, mailer.sent, 1)
+	token := mailer.sent[0].data["Token"]
+	assert.NotEmpty(t, token)
+	assert.NotEqual(t, token, imported.Token.TokenHash)
+	assert.Equal(t, auth.HashPasswordSetupToken(token), imported.Token.TokenHash)
+
+	assert.Equal(t, []Result{{Line: 1, Email: "alice@example.com", Status: StatusCreated}}, summary.Results)
+}
+
+func TestImporter_SkipsExistingEmails(t *testing.T) {
+	users := &fakeUsers{existing: map[string]bool{"bob@example.com": true}}
+	mailer := &fakeMailer{}
+	importer := &Importer{Users: users, Mailer: mailer, TokenTTL: time.Hour}
+
+	summary := &Summary{}
+	importer.Import(context.Background(), []Row{
+		{Line: 1, Email: "alice@example.com", Name: "Alice"},
+		{Line: 2, Email: "bob@example.com", Name: "Bob"},
+	}, summary)
+
+	assert.Equal(t, 1, summary.Count(StatusCreated))
+	assert.Equal(t, 1, summary.Count(StatusSkipped))
+	require.Len(t, mailer.sent, 1)
+	assert.Equal(t, "alice@example.com", mailer.sent[0].to)
+}
+
+func TestImporter_Batches(t *testing.T) {
+	users := &fakeUsers{}
+	importer := &Importer{Users: users, Mailer: &fakeMailer{}, TokenTTL: time.Hour, BatchSize: 2}
+
+	rows := []Row{
+		{Line: 1, Email: "a@example.com", Name: "A"},
+		{Line: 2, Email: "b@example.com", Name: "B"},
+		{Line: 3, Email: "c@example.com", Name: "C"},
+	}
+	summary := &Summary{}
+	importer.Import(context.Background(), rows, summary)
+
+	require.Len(t, users.batches, 2)
+	assert.Len(t, users.batches[0], 2)
+	assert.Len(t, users.batches[1], 1)
+	assert.Equal(t, 3, summary.Count(StatusCreated))
+}
+
+func TestImporter_FailedBatchMarksAllRowsFailed(t *testing.T) {
+	users := &fakeUsers{err: errors.New("connection reset")}
+	mailer := &fakeMailer{}
+	importer := &Importer{Users: users, Mailer: mailer, TokenTTL: time.Hour}
+
+	summary := &Summary{}
+	importer.Import(context.Background(), []Row{
+		{Line: 1, Email: "a@example.com", Name: "A"},
+		{Line: 2, Email: "b@example.com", Name: "B"},
+	}, summary)
+
+	assert.Equal(t, 2, summary.Count(StatusFailed))
+	assert.Empty(t, mailer.sent)
+}
+
+func TestImporter_MailFailureIsReported(t *testing.T) {
+	importer := &Importer{Users: &fakeUsers{}, Mailer: &fakeMailer{failTo: "a@example.com"}, TokenTTL: time.Hour}
+
+	summary := &Summary{}
+	importer.Import(context.Background(), []Row{{Line: 1, Email: "a@example.com", Name: "A"}}, summary)
+
+	require.Len(t, summary.Results, 1)
+	assert.Equal(t, StatusFailed, summary.Results[0].Status)
+	assert.Contains(t, summary.Results[0].Reason, "user created but setup email failed")
+}
@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_post_reactions_user_id;
+
+-- Drop post_reactions table
+DROP TABLE IF EXISTS post_reactions;
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- Create post_reactions table
+CREATE TABLE IF NOT EXISTS post_reactions (
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reaction VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (post_id, user_id, reaction)
+);
+
+-- Create index for looking up a viewer's reactions
+CREATE INDEX IF NOT EXISTS idx_post_reactions_user_id ON post_reactions(user_id, post_id);
\ No newline at end of file
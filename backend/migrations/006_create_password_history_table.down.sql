@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_password_history_user_id_created_at;
+
+-- Drop password_history table
+DROP TABLE IF EXISTS password_history;
\ No newline at end of file
@@ -0,0 +1,12 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_comments_hidden;
+DROP INDEX IF EXISTS idx_posts_hidden;
+
+-- Drop moderation columns
+ALTER TABLE comments
+    DROP COLUMN IF EXISTS hidden_reason,
+    DROP COLUMN IF EXISTS hidden;
+
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS hidden_reason,
+    DROP COLUMN IF EXISTS hidden;
\ No newline at end of file
@@ -0,0 +1,13 @@
+-- Add moderation columns to posts
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS hidden BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS hidden_reason TEXT;
+
+-- Add moderation columns to comments
+ALTER TABLE comments
+    ADD COLUMN IF NOT EXISTS hidden BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS hidden_reason TEXT;
+
+-- Create partial indexes for moderation queues
+CREATE INDEX IF NOT EXISTS idx_posts_hidden ON posts(id) WHERE hidden;
+CREATE INDEX IF NOT EXISTS idx_comments_hidden ON comments(id) WHERE hidden;
\ No newline at end of file
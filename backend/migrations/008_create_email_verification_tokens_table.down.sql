@@ -0,0 +1,8 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_email_verification_tokens_user_id;
+
+-- Drop email_verification_tokens table
+DROP TABLE IF EXISTS email_verification_tokens;
+
+-- Drop verification timestamp
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
\ No newline at end of file
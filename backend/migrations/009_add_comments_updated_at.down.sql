@@ -0,0 +1,2 @@
+-- Drop comment edit timestamp
+ALTER TABLE comments DROP COLUMN IF EXISTS updated_at;
\ No newline at end of file
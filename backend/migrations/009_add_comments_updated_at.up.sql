@@ -0,0 +1,10 @@
+-- Track when comments were last edited
+ALTER TABLE comments
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE;
+
+-- Existing comments have never been edited
+UPDATE comments SET updated_at = created_at WHERE updated_at IS NULL;
+
+ALTER TABLE comments
+    ALTER COLUMN updated_at SET DEFAULT CURRENT_TIMESTAMP,
+    ALTER COLUMN updated_at SET NOT NULL;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- Drop post publish time
+ALTER TABLE posts DROP COLUMN IF EXISTS publish_at;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- Record when each post was last published
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP WITH TIME ZONE;
+
+-- Published posts so far went live when they were created
+UPDATE posts SET publish_at = created_at WHERE published AND publish_at IS NULL;
\ No newline at end of file
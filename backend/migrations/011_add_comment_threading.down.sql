@@ -0,0 +1,6 @@
+-- Drop comment threading
+DROP INDEX IF EXISTS idx_comments_parent_id;
+
+ALTER TABLE comments
+    DROP COLUMN IF EXISTS depth,
+    DROP COLUMN IF EXISTS parent_id;
\ No newline at end of file
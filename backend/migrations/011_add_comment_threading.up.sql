@@ -0,0 +1,7 @@
+-- Thread comments; depth is stored so reply limits need no recursive query.
+-- Deleting a comment keeps its replies, which then have no parent.
+ALTER TABLE comments
+    ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES comments(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS depth INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments(parent_id);
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- Drop user login tracking
+ALTER TABLE users
+    DROP COLUMN IF EXISTS last_login_ip,
+    DROP COLUMN IF EXISTS last_login_at;
\ No newline at end of file
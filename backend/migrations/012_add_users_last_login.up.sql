@@ -0,0 +1,4 @@
+-- Record each user's latest successful login
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS last_login_ip TEXT;
\ No newline at end of file
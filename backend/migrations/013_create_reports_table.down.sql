@@ -0,0 +1,6 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_reports_status_created_at;
+DROP INDEX IF EXISTS idx_reports_open_reporter_target;
+
+-- Drop reports table
+DROP TABLE IF EXISTS reports;
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- Create reports table
+CREATE TABLE IF NOT EXISTS reports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_type VARCHAR(16) NOT NULL,
+    target_id UUID NOT NULL,
+    reason TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'OPEN',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP WITH TIME ZONE
+);
+
+-- A user has at most one open report of the same content
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reports_open_reporter_target
+    ON reports(reporter_id, target_type, target_id) WHERE status = 'OPEN';
+
+-- Create index for the moderation queue, oldest first
+CREATE INDEX IF NOT EXISTS idx_reports_status_created_at ON reports(status, created_at);
\ No newline at end of file
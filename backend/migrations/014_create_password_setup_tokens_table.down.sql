@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_password_setup_tokens_user_id;
+
+-- Drop password_setup_tokens table
+DROP TABLE IF EXISTS password_setup_tokens;
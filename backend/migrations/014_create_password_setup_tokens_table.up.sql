@@ -0,0 +1,12 @@
+-- Create password_setup_tokens table: one-time tokens for users created
+-- without a password, such as by a bulk import, to set their first password
+CREATE TABLE IF NOT EXISTS password_setup_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index on user_id for finding a user's outstanding token
+CREATE INDEX IF NOT EXISTS idx_password_setup_tokens_user_id ON password_setup_tokens(user_id);
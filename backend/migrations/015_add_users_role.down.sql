@@ -0,0 +1,3 @@
+-- Drop user roles
+ALTER TABLE users
+    DROP COLUMN IF EXISTS role;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- Give every user a role; existing and new accounts are regular users until promoted
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user'
+        CHECK (role IN ('admin', 'moderator', 'user'));
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- The original casing of backfilled emails is not recoverable
+DROP INDEX IF EXISTS idx_users_email_lower;
@@ -0,0 +1,7 @@
+-- Store every email trimmed and lower-cased, as the application now writes
+-- them. Accounts whose addresses differ only in case make this fail; merge or
+-- rename them first.
+UPDATE users SET email = lower(trim(email)) WHERE email <> lower(trim(email));
+
+-- Enforce case-insensitive uniqueness and serve lookups by lower(email)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (lower(email));
@@ -0,0 +1,6 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_audit_logs_action_created_at;
+DROP INDEX IF EXISTS idx_audit_logs_user_id;
+
+-- Drop audit_logs table
+DROP TABLE IF EXISTS audit_logs;
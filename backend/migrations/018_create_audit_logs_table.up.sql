@@ -0,0 +1,20 @@
+-- Create audit_logs table: security events such as moderation actions,
+-- impersonation and operations refused by a limit. user_id has no foreign
+-- key so entries outlive the users they name; it is NULL for anonymous callers.
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID,
+    action VARCHAR(64) NOT NULL,
+    resource VARCHAR(64) NOT NULL,
+    resource_id VARCHAR(255) NOT NULL DEFAULT '',
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    success BOOLEAN NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    metadata JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for reviewing a user's entries and recent entries by action
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user_id ON audit_logs(user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action_created_at ON audit_logs(action, created_at);
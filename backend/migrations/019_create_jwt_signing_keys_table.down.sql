@@ -0,0 +1,2 @@
+-- Drop jwt_signing_keys table
+DROP TABLE IF EXISTS jwt_signing_keys;
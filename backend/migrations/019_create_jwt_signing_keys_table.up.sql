@@ -0,0 +1,12 @@
+-- Create jwt_signing_keys table: keys made current by rotateJWTKey, so
+-- every server instance and later starts use them. The newest key signs.
+-- secret holds the signing secret encrypted with AES-GCM under a key derived
+-- from JWT_KEY_ENCRYPTION_KEY (or JWT_SECRET), which never enters the database.
+CREATE TABLE IF NOT EXISTS jwt_signing_keys (
+    id VARCHAR(64) PRIMARY KEY,
+    secret BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index for loading the keys that may still verify tokens
+CREATE INDEX IF NOT EXISTS idx_jwt_signing_keys_created_at ON jwt_signing_keys(created_at);
@@ -23,7 +23,7 @@ export const CreatePostForm: React.FC<CreatePostFormProps> = ({
     refetchQueries: ['GetPosts'],
     // Update cache optimistically
     update: (cache, { data }) => {
-      if (data?.createPost) {
+      if (data?.createPost?.__typename === 'Post') {
         // Add the new post to the cache
         const newPost = data.createPost;
         
@@ -24,7 +24,7 @@ export const RegisterForm: React.FC<RegisterFormProps> = ({
   const [register, { loading, error }] = useRegisterMutation({
     errorPolicy: 'all',
     onCompleted: (data) => {
-      if (data.register) {
+      if (data.register?.__typename === 'AuthPayload') {
         onSuccess?.(data.register.token, data.register.user);
       }
     },
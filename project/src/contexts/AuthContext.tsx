@@ -169,7 +169,7 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
         variables: { email, password, name },
       });
 
-      if (result.data?.register) {
+      if (result.data?.register.__typename === 'AuthPayload') {
         const { token, user } = result.data.register;
         
         // Store in localStorage
@@ -227,7 +227,7 @@ export type RegisterMutationVariables = Exact<{
 }>;
 
 
-export type RegisterMutation = { __typename?: 'Mutation', register: { __typename?: 'AuthPayload', token: string, expiresAt: string, user: { __typename?: 'User', id: string, email: string, name: string, avatar?: string | null | undefined, createdAt: string, updatedAt: string } } };
+export type RegisterMutation = { __typename?: 'Mutation', register: { __typename?: 'AuthPayload', token: string, expiresAt: string, user: { __typename?: 'User', id: string, email: string, name: string, avatar?: string | null | undefined, createdAt: string, updatedAt: string } } | { __typename?: 'RegistrationPreview' } };
 
 export type RefreshTokenMutationVariables = Exact<{ [key: string]: never; }>;
 
@@ -254,7 +254,7 @@ export type CreatePostMutationVariables = Exact<{
 }>;
 
 
-export type CreatePostMutation = { __typename?: 'Mutation', createPost: { __typename?: 'Post', id: string, title: string, content: string, tags: Array<string>, published: boolean, createdAt: string, updatedAt: string, author: { __typename?: 'User', id: string, email: string, name: string, avatar?: string | null | undefined, createdAt: string, updatedAt: string } } };
+export type CreatePostMutation = { __typename?: 'Mutation', createPost: { __typename?: 'Post', id: string, title: string, content: string, tags: Array<string>, published: boolean, createdAt: string, updatedAt: string, author: { __typename?: 'User', id: string, email: string, name: string, avatar?: string | null | undefined, createdAt: string, updatedAt: string } } | { __typename?: 'PostPreview' } };
 
 export type UpdatePostMutationVariables = Exact<{
   id: Scalars['ID']['input'];
@@ -422,11 +422,13 @@ export type LoginMutationOptions = ApolloReactCommon.BaseMutationOptions<LoginMu
 export const RegisterDocument = gql`
     mutation Register($email: String!, $password: String!, $name: String!) {
   register(email: $email, password: $password, name: $name) {
-    token
-    user {
-      ...UserInfo
+    ... on AuthPayload {
+      token
+      user {
+        ...UserInfo
+      }
+      expiresAt
     }
-    expiresAt
   }
 }
     ${UserInfoFragmentDoc}`;
@@ -562,7 +564,9 @@ export type DeleteCommentMutationOptions = ApolloReactCommon.BaseMutationOptions
 export const CreatePostDocument = gql`
     mutation CreatePost($input: CreatePostInput!) {
   createPost(input: $input) {
-    ...PostInfo
+    ... on Post {
+      ...PostInfo
+    }
   }
 }
     ${PostInfoFragmentDoc}`;